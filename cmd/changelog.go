@@ -0,0 +1,103 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// runChangelog prints what changed between the bundle the last successful
+// run applied and the current one, without writing anything
+func runChangelog(ctx context.Context, c client.Client, opts *deploy.Options) {
+	changelog, err := deploy.BundleChangelog(ctx, c, opts)
+	if err != nil {
+		fatal(err, "failed to compute the bundle changelog", exitCodeFailure)
+	}
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(changelog)
+	} else {
+		err = printChangelog(os.Stdout, changelog)
+	}
+	if err != nil {
+		fatal(err, "failed to write the bundle changelog", exitCodeFailure)
+	}
+}
+
+// printChangelog writes changelog as a table, one CRD per line
+func printChangelog(w io.Writer, changelog *deploy.Changelog) error {
+	if changelog.FirstRun {
+		fmt.Fprintf(w, "no previous run recorded: the %d CRDs of bundle %s are all new\n",
+			len(changelog.Added), changelog.BundleDigest)
+	} else {
+		fmt.Fprintf(w, "bundle %s (version %q) -> %s (version %q)\n", changelog.PreviousBundleDigest,
+			changelog.PreviousBundleVersion, changelog.BundleDigest, changelog.BundleVersion)
+	}
+	if !changelog.HasChanges() {
+		_, err := fmt.Fprintln(w, "no CRD changed")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGE\tCRD\tVERSIONS")
+	if !changelog.FirstRun {
+		for _, name := range changelog.Added {
+			fmt.Fprintf(tw, "added\t%s\t\n", name)
+		}
+	}
+	for _, name := range changelog.Removed {
+		fmt.Fprintf(tw, "removed\t%s\t\n", name)
+	}
+	for i := range changelog.Modified {
+		change := &changelog.Modified[i]
+		fmt.Fprintf(tw, "modified\t%s\t%s\n", change.Name, versionChanges(change))
+	}
+	return tw.Flush()
+}
+
+// versionChanges summarizes the versions change added, removed and changed
+func versionChanges(change *deploy.CRDChange) string {
+	var parts []string
+	for _, versions := range []struct {
+		name     string
+		versions []string
+	}{
+		{"added", change.AddedVersions},
+		{"removed", change.RemovedVersions},
+		{"changed", change.ChangedVersions},
+	} {
+		if len(versions.versions) > 0 {
+			parts = append(parts, versions.name+": "+strings.Join(versions.versions, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return "none (other spec fields changed)"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// runApplyClusterProfile creates or updates the ClusterProfile deploying the
+// CRDs through Sveltos, and its ConfigMaps, in the management cluster
+func runApplyClusterProfile(ctx context.Context, c client.Client, opts *deploy.Options) {
+	if err := deploy.ApplyClusterProfile(ctx, c, opts, &cli.clusterProfileOptions, setupLog); err != nil {
+		fatal(err, "failed to apply ClusterProfile", exitCodeFailure)
+	}
+	setupLog.V(logs.LogInfo).Info(fmt.Sprintf("applied ClusterProfile %s", cli.clusterProfileOptions.Name))
+}
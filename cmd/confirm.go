@@ -0,0 +1,146 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	planCreate = "create"
+	planUpdate = "update"
+	planSkip   = "skip"
+	planHeld   = "no change (held)"
+
+	// planChangedPaths is the number of changed paths listed per updated CRD
+	planChangedPaths = 3
+)
+
+// confirmPlan computes, with an observe-only run, what deploying the CRDs
+// would change and prints it. Unless --yes is set, or nothing would change,
+// as when crd-manager is paused, it then asks for confirmation on stdin,
+// exiting when anything but "yes" is entered.
+func confirmPlan(ctx context.Context, c client.Client, opts *deploy.Options) {
+	report, err := deploy.Deploy(ctx, c, planOptions(opts), setupLog)
+	if err != nil {
+		fatal(err, "failed to compute the plan", exitCodeFailure)
+	}
+
+	// with --output=json, stdout only carries the run result
+	w := os.Stdout
+	if cli.output == outputJSON {
+		w = os.Stderr
+	}
+	changes, err := printPlan(w, report)
+	if err != nil {
+		fatal(err, "failed to write the plan", exitCodeFailure)
+	}
+	if changes == 0 || cli.assumeYes {
+		return
+	}
+
+	fmt.Fprint(w, "\nApply these changes? Only 'yes' will be accepted: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if (err != nil && !errors.Is(err, io.EOF)) || strings.TrimSpace(answer) != "yes" {
+		writeTerminationMessage("apply cancelled")
+		setupLog.Info("apply cancelled: no CRD was written")
+		exit(exitCodeCancelled)
+	}
+}
+
+// planOptions returns a copy of opts for an observe-only run computing what
+// deploying the CRDs would change, without side effects: no Event, audit log
+// entry or progress output is produced
+func planOptions(opts *deploy.Options) *deploy.Options {
+	planOpts := *opts
+	planOpts.ObserveOnly = true
+	planOpts.CreateMissing = false
+	planOpts.EventRecorder = nil
+	planOpts.AuditLog = nil
+	planOpts.Progress = nil
+	return &planOpts
+}
+
+// printPlan writes, one CRD per line, what deploying the CRDs report
+// describes would do and returns the number of CRDs it would write
+func printPlan(w io.Writer, report *deploy.Report) (int, error) {
+	if report.Status == deploy.RunStatusPaused {
+		_, err := fmt.Fprintln(w, pausedMessage)
+		return 0, err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tCRD\tCHANGES")
+	counts := map[string]int{}
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		action, summary := planAction(result)
+		counts[action]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", action, result.Name, summary)
+	}
+	if err := tw.Flush(); err != nil {
+		return 0, err
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %d to create, %d to update, %d held, %d to skip (bundle %s)\n",
+		counts[planCreate], counts[planUpdate], counts[planHeld], counts[planSkip], report.BundleDigest)
+	return counts[planCreate] + counts[planUpdate], err
+}
+
+// planAction returns what deploying the CRD result describes would do, with
+// a short summary of the changes. Paused, pinned and terminating CRDs are
+// held: a run leaves them as they are.
+func planAction(result *deploy.CRDResult) (action, summary string) {
+	switch {
+	case result.Action == deploy.ActionPaused:
+		return planHeld, deploy.PausedAnnotation + " annotation"
+	case result.Action == deploy.ActionPinned:
+		return planHeld, "pinned at " + result.PinnedVersion
+	case result.Action == deploy.ActionTerminating:
+		return planHeld, "being deleted"
+	case result.Action != deploy.ActionObserved:
+		return planSkip, string(result.Action)
+	case result.Drift == deploy.DriftStatusMissing:
+		return planCreate, "new CRD"
+	case result.Drift == deploy.DriftStatusDrifted:
+		return planUpdate, changedPathsSummary(result.ChangedPaths)
+	default:
+		return planSkip, "in sync"
+	}
+}
+
+// changedPathsSummary lists the first changed paths and counts the others
+func changedPathsSummary(paths []string) string {
+	if len(paths) == 0 {
+		return "spec differs"
+	}
+	if len(paths) <= planChangedPaths {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:planChangedPaths], ", "), len(paths)-planChangedPaths)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Confirm", func() {
+	DescribeTable("planAction",
+		func(result deploy.CRDResult, action, summary string) {
+			gotAction, gotSummary := planAction(&result)
+			Expect(gotAction).To(Equal(action))
+			Expect(gotSummary).To(Equal(summary))
+		},
+		Entry("missing CRD", deploy.CRDResult{Action: deploy.ActionObserved, Drift: deploy.DriftStatusMissing},
+			planCreate, "new CRD"),
+		Entry("drifted CRD", deploy.CRDResult{Action: deploy.ActionObserved, Drift: deploy.DriftStatusDrifted,
+			ChangedPaths: []string{"spec.names.shortNames"}}, planUpdate, "spec.names.shortNames"),
+		Entry("paused CRD", deploy.CRDResult{Action: deploy.ActionPaused, Drift: deploy.DriftStatusDrifted},
+			planHeld, deploy.PausedAnnotation+" annotation"),
+		Entry("pinned CRD", deploy.CRDResult{Action: deploy.ActionPinned, Drift: deploy.DriftStatusDrifted,
+			PinnedVersion: "v0.38.0"}, planHeld, "pinned at v0.38.0"),
+		Entry("terminating CRD", deploy.CRDResult{Action: deploy.ActionTerminating},
+			planHeld, "being deleted"),
+		Entry("skipped CRD", deploy.CRDResult{Action: deploy.ActionSkippedHelm},
+			planSkip, string(deploy.ActionSkippedHelm)),
+	)
+
+	It("printPlan shows held CRDs as not changing and does not count them as changes", func() {
+		report := &deploy.Report{
+			BundleDigest: "sha256:abc",
+			CRDs: []deploy.CRDResult{
+				{Name: "clusterprofiles.config.projectsveltos.io", Action: deploy.ActionObserved,
+					Drift: deploy.DriftStatusMissing},
+				{Name: "sveltosclusters.lib.projectsveltos.io", Action: deploy.ActionPinned,
+					Drift: deploy.DriftStatusDrifted, PinnedVersion: "v0.38.0"},
+			},
+		}
+
+		var out bytes.Buffer
+		changes, err := printPlan(&out, report)
+		Expect(err).To(BeNil())
+		Expect(changes).To(Equal(1))
+		Expect(out.String()).To(Equal(strings.Join([]string{
+			"ACTION            CRD                                       CHANGES",
+			"create            clusterprofiles.config.projectsveltos.io  new CRD",
+			"no change (held)  sveltosclusters.lib.projectsveltos.io     pinned at v0.38.0",
+			"",
+			"Plan: 1 to create, 0 to update, 1 held, 0 to skip (bundle sha256:abc)",
+			"",
+		}, "\n")))
+	})
+
+	It("printPlan tells nothing would be applied while paused", func() {
+		var out bytes.Buffer
+		changes, err := printPlan(&out, &deploy.Report{Status: deploy.RunStatusPaused})
+		Expect(err).To(BeNil())
+		Expect(changes).To(BeZero())
+		Expect(out.String()).To(Equal(pausedMessage + "\n"))
+	})
+})
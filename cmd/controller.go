@@ -0,0 +1,172 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	// --maintenance-window-timezone must not depend on the image shipping the time zone database
+	_ "time/tzdata"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/projectsveltos/crd-manager/pkg/config"
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// runController keeps deploying the Sveltos CRDs, every resync period,
+// whenever the configuration file changes and whenever a managed CRD is
+// edited or deleted, until ctx is cancelled. CRDs are read from an informer
+// cache, writes go straight to the API server.
+func runController(ctx context.Context, restConfig *rest.Config, c client.Client, opts *deploy.Options) error {
+	windows, err := controller.ParseMaintenanceWindows(cli.maintenanceWindows, cli.maintenanceWindowTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid --maintenance-window: %w", err)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:  c.Scheme(),
+		Metrics: metricsserver.Options{BindAddress: cli.metricsBindAddress},
+		Cache:   controller.CacheOptions(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	cachedClient := controller.NewCachedClient(c, mgr.GetCache(), ctrl.Log.WithName("cache"))
+	if err := mgr.Add(cachedClient); err != nil {
+		return err
+	}
+
+	opts.EventRecorder = mgr.GetEventRecorder("crd-manager")
+
+	logger := ctrl.Log.WithName("controller")
+	runner := controller.NewRunner(cachedClient, opts, cli.resyncPeriod, func(report *deploy.Report, err error) {
+		report.TargetCluster = restConfig.Host
+		passLogger := logger
+		if report.Trigger != "" {
+			passLogger = logger.WithValues("trigger", report.Trigger)
+		}
+		printReport(report, cli.output, passLogger)
+	}, logger)
+	if cli.deleteOnShutdown {
+		if cli.confirmDeleteCRDs {
+			runner.DeleteOnShutdown(cli.shutdownTimeout)
+		} else {
+			setupLog.Info("WARNING: --delete-on-shutdown ignored: --i-know-this-deletes-crds is not set")
+		}
+	}
+	if windows != nil {
+		runner.SetMaintenanceWindows(windows, cli.alwaysCreateMissing)
+	}
+	if cli.reconcileEndpoint {
+		handler := controller.NewReconcileHandler(runner, cli.reconcileEndpointOptions, ctrl.Log.WithName("reconcile-endpoint"))
+		for _, path := range []string{controller.ReconcileEndpointPath, controller.ReconcileEndpointPath + "/"} {
+			if err := mgr.AddMetricsServerExtraHandler(path, handler); err != nil {
+				return err
+			}
+		}
+	}
+	if err := mgr.Add(runner); err != nil {
+		return err
+	}
+	if err := mgr.Add(controller.NewCRDWatcher(mgr.GetCache(), runner, ctrl.Log.WithName("watch"))); err != nil {
+		return err
+	}
+
+	if cli.configFile != "" {
+		watcher := config.NewWatcher(cli.configFile, cli.configReloadInterval, func() error {
+			return reloadConfig(ctx, runner, logger)
+		}, logger)
+		if err := mgr.Add(watcher); err != nil {
+			return err
+		}
+	}
+
+	setupLog.V(logs.LogInfo).Info(fmt.Sprintf("starting controller (resync period %s)", cli.resyncPeriod))
+	return mgr.Start(ctx)
+}
+
+// validateReconcileEndpoint checks the --reconcile-endpoint flags
+func (f *cliFlags) validateReconcileEndpoint() error {
+	if !f.reconcileEndpoint {
+		return nil
+	}
+	if f.mode != modeController {
+		setupLog.Info("WARNING: --reconcile-endpoint ignored: it only applies to --mode=controller")
+		return nil
+	}
+	if f.metricsBindAddress == "0" {
+		return errors.New("--reconcile-endpoint requires the metrics endpoint: --metrics-bind-address must not be 0")
+	}
+	if err := f.reconcileEndpointOptions.Validate(); err != nil {
+		return fmt.Errorf("invalid --reconcile-token-file: %w", err)
+	}
+	return nil
+}
+
+// reloadConfig builds the options from the command line, the environment and
+// the configuration file again and, if valid, hands them to the runner.
+// Invalid configurations are rejected and the current one stays active.
+// Settings used only at startup (mode, resync period, metrics address...)
+// require a restart to change.
+func reloadConfig(ctx context.Context, runner *controller.Runner, logger logr.Logger) error {
+	opts, err := buildReloadedOptions(ctx, runner.Options())
+	controller.RecordConfigReload(err)
+	if err != nil {
+		logger.Error(err, "configuration reload rejected, keeping the previous configuration")
+		return err
+	}
+
+	logger.V(logs.LogInfo).Info("configuration reloaded, triggering a resync")
+	runner.SetOptions(opts)
+	return nil
+}
+
+// buildReloadedOptions builds the options from flags parsed again into new
+// cliFlags: the ones the process was started with are read while the runner
+// runs, and are left untouched
+func buildReloadedOptions(ctx context.Context, current *deploy.Options) (*deploy.Options, error) {
+	reloaded := &cliFlags{}
+	fs := pflag.NewFlagSet(os.Args[0], pflag.ContinueOnError)
+	if err := reloaded.parseFlags(fs, os.Args[1:]); err != nil {
+		return nil, err
+	}
+
+	opts, err := reloaded.getOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Bundle, err = reloaded.loadBundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.ServerVersion = current.ServerVersion
+	opts.EventRecorder = current.EventRecorder
+	opts.AuditLog = current.AuditLog
+	return opts, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Configuration reload", func() {
+	// run with -race: the flags the process was started with are read while
+	// the configuration is reloaded
+	It("builds the options from the reloaded configuration, leaving the flags in use untouched", func() {
+		configPath := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(configPath, []byte("history-namespace: reloaded\nhistory-runs: 5\n"), 0o600)).To(Succeed())
+		args := os.Args
+		os.Args = []string{"crd-manager", "--config=" + configPath}
+		DeferCleanup(func() { os.Args = args })
+
+		report := &deploy.Report{CRDs: []deploy.CRDResult{
+			{Name: "sveltosclusters.lib.projectsveltos.io", Action: deploy.ActionQuarantined},
+		}}
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					printReport(report, cli.output, logr.Discard())
+				}
+			}
+		}()
+
+		opts, err := buildReloadedOptions(context.TODO(), &deploy.Options{})
+		close(done)
+		<-stopped
+		Expect(err).To(BeNil())
+		Expect(opts.History).To(Equal(&deploy.History{Namespace: "reloaded", Runs: 5}))
+		Expect(opts.Bundle).ToNot(BeNil())
+		Expect(cli.historyNamespace).To(BeEmpty())
+		Expect(cli.configFile).To(BeEmpty())
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// runDoctor runs the doctor checks but the --doctor-skip ones and prints the
+// findings, without writing anything. It exits non-zero if a finding reaches
+// the --doctor-fail-on severity.
+func runDoctor(ctx context.Context, c client.Client, opts *deploy.Options) {
+	skip, err := deploy.ParseDoctorChecks(cli.doctorSkip)
+	if err != nil {
+		fatal(err, "invalid configuration", exitCodeFailure)
+	}
+	threshold, err := deploy.ParseSeverity(cli.doctorFailOn)
+	if err != nil {
+		fatal(err, "invalid configuration", exitCodeFailure)
+	}
+
+	report, err := deploy.Diagnose(ctx, c, opts, skip, setupLog)
+	if err != nil {
+		fatal(err, "doctor failed", exitCodeFailure)
+	}
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = printDoctorReport(os.Stdout, report)
+	}
+	if err != nil {
+		fatal(err, "failed to write doctor findings", exitCodeFailure)
+	}
+
+	if report.Reaches(threshold) {
+		writeTerminationMessage(fmt.Sprintf("doctor: %d findings", len(report.Findings)))
+		exit(exitCodeDoctorFindings)
+	}
+	writeTerminationMessage(fmt.Sprintf("doctor: %d findings, none reaching %s", len(report.Findings), threshold))
+}
+
+// printDoctorReport writes the findings as a table, one finding per line
+func printDoctorReport(w io.Writer, report *deploy.DoctorReport) error {
+	if len(report.Findings) == 0 {
+		_, err := fmt.Fprintf(w, "No problem found by the %d checks run\n", len(report.Checks))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tCHECK\tCRD\tEXPLANATION\tREMEDIATION")
+	for i := range report.Findings {
+		finding := &report.Findings[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", finding.Severity, finding.Check, finding.CRD,
+			finding.Explanation, finding.Remediation)
+	}
+	return tw.Flush()
+}
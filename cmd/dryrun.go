@@ -0,0 +1,130 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// pausedMessage explains why a dry run or a plan lists no CRD
+	pausedMessage = "crd-manager is paused by the " + deploy.PausedAnnotation + " annotation on ConfigMap " +
+		deploy.ConfigMapNamespace + "/" + deploy.ConfigMapName + ": nothing would be applied."
+
+	diffNoChange = "no-change"
+	diffFailed   = "failed"
+
+	// diffValueLength is the length beyond which the values of a changed
+	// field are truncated
+	diffValueLength = 100
+)
+
+// runDryRun evaluates every bundle CRD against the cluster, without writing
+// anything, and prints what a run would do with the changed fields of the
+// CRDs it would update. It exits non-zero if CRDs could not be evaluated.
+func runDryRun(ctx context.Context, c client.Client, opts *deploy.Options) {
+	dryRunOpts := planOptions(opts)
+	dryRunOpts.Diff = true
+	report, err := deploy.Deploy(ctx, c, dryRunOpts, setupLog)
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			fatal(encodeErr, "failed to write the dry run result", exitCodeFailure)
+		}
+	} else if printErr := printDiff(os.Stdout, report); printErr != nil {
+		fatal(printErr, "failed to write the dry run result", exitCodeFailure)
+	}
+	if err != nil {
+		fatal(err, "dry run failed", exitCodeFailure)
+	}
+}
+
+// printDiff writes what deploying the CRDs report describes would do: one
+// line per CRD, followed for the updated ones by a line per changed field
+func printDiff(w io.Writer, report *deploy.Report) error {
+	if report.Status == deploy.RunStatusPaused {
+		_, err := fmt.Fprintln(w, pausedMessage)
+		return err
+	}
+
+	counts := map[string]int{}
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		action, marker, detail := diffAction(result)
+		counts[action]++
+		if detail != "" {
+			detail = " (" + detail + ")"
+		}
+		if _, err := fmt.Fprintf(w, "%s %s %s%s\n", marker, action, result.Name, detail); err != nil {
+			return err
+		}
+		for j := range result.Changes {
+			change := &result.Changes[j]
+			if _, err := fmt.Fprintf(w, "    %s: %s -> %s\n", change.Path, diffValue(change.Live),
+				diffValue(change.Desired)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nDry run: %d to create, %d to update, %d unchanged, %d skipped, %d failed "+
+		"(bundle %s). Nothing was written.\n", counts[planCreate], counts[planUpdate], counts[diffNoChange],
+		counts[planSkip], counts[diffFailed], report.BundleDigest)
+	return err
+}
+
+// diffAction returns what deploying the CRD result describes would do, the
+// marker of its line and, for CRDs skipped or failing, why
+func diffAction(result *deploy.CRDResult) (action, marker, detail string) {
+	switch {
+	case result.Action == deploy.ActionFailed:
+		return diffFailed, "!", result.Error
+	case result.Action != deploy.ActionObserved:
+		return planSkip, "-", string(result.Action)
+	case result.Drift == deploy.DriftStatusMissing:
+		return planCreate, "+", ""
+	case result.Drift == deploy.DriftStatusDrifted:
+		return planUpdate, "~", ""
+	default:
+		return diffNoChange, "=", ""
+	}
+}
+
+// diffValue returns the compact JSON form of value, truncated, or (unset)
+func diffValue(value interface{}) string {
+	if value == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(data) > diffValueLength {
+		return string(data[:diffValueLength]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Dry run", func() {
+	DescribeTable("diffAction",
+		func(result deploy.CRDResult, action, marker, detail string) {
+			gotAction, gotMarker, gotDetail := diffAction(&result)
+			Expect(gotAction).To(Equal(action))
+			Expect(gotMarker).To(Equal(marker))
+			Expect(gotDetail).To(Equal(detail))
+		},
+		Entry("missing CRD", deploy.CRDResult{Action: deploy.ActionObserved, Drift: deploy.DriftStatusMissing},
+			planCreate, "+", ""),
+		Entry("drifted CRD", deploy.CRDResult{Action: deploy.ActionObserved, Drift: deploy.DriftStatusDrifted},
+			planUpdate, "~", ""),
+		Entry("CRD in sync", deploy.CRDResult{Action: deploy.ActionObserved, Drift: deploy.DriftStatusInSync},
+			diffNoChange, "=", ""),
+		Entry("skipped CRD", deploy.CRDResult{Action: deploy.ActionSkippedHelm},
+			planSkip, "-", string(deploy.ActionSkippedHelm)),
+		Entry("paused CRD", deploy.CRDResult{Action: deploy.ActionPaused, Drift: deploy.DriftStatusDrifted},
+			planSkip, "-", string(deploy.ActionPaused)),
+		Entry("failed CRD", deploy.CRDResult{Action: deploy.ActionFailed, Error: "forbidden"},
+			diffFailed, "!", "forbidden"),
+	)
+
+	It("printDiff lists the CRDs to create, update and leave unchanged, with the changed fields", func() {
+		report := &deploy.Report{
+			BundleDigest: "sha256:abc",
+			CRDs: []deploy.CRDResult{
+				{Name: "clusterprofiles.config.projectsveltos.io", Action: deploy.ActionObserved,
+					Drift: deploy.DriftStatusMissing},
+				{Name: "sveltosclusters.lib.projectsveltos.io", Action: deploy.ActionObserved,
+					Drift: deploy.DriftStatusDrifted, Changes: []deploy.FieldChange{
+						{Path: "spec.names.shortNames", Live: []interface{}{"sc"}, Desired: []interface{}{"sc", "svc"}},
+						{Path: "spec.versions[v1beta1].schema.openAPIV3Schema.properties.spec.description",
+							Desired: "SveltosClusterSpec"},
+						{Path: "spec.versions[v1alpha1].deprecated", Live: true},
+					}},
+				{Name: "eventsources.lib.projectsveltos.io", Action: deploy.ActionObserved,
+					Drift: deploy.DriftStatusInSync},
+			},
+		}
+
+		var out bytes.Buffer
+		Expect(printDiff(&out, report)).To(Succeed())
+		Expect(out.String()).To(Equal(strings.Join([]string{
+			"+ create clusterprofiles.config.projectsveltos.io",
+			"~ update sveltosclusters.lib.projectsveltos.io",
+			`    spec.names.shortNames: ["sc"] -> ["sc","svc"]`,
+			`    spec.versions[v1beta1].schema.openAPIV3Schema.properties.spec.description: (unset) -> "SveltosClusterSpec"`,
+			"    spec.versions[v1alpha1].deprecated: true -> (unset)",
+			"= no-change eventsources.lib.projectsveltos.io",
+			"",
+			"Dry run: 1 to create, 1 to update, 1 unchanged, 0 skipped, 0 failed (bundle sha256:abc). " +
+				"Nothing was written.",
+			"",
+		}, "\n")))
+	})
+
+	It("printDiff tells nothing would be applied while paused", func() {
+		var out bytes.Buffer
+		Expect(printDiff(&out, &deploy.Report{Status: deploy.RunStatusPaused})).To(Succeed())
+		Expect(out.String()).To(Equal(pausedMessage + "\n"))
+	})
+
+	It("printDiff truncates long values", func() {
+		report := &deploy.Report{CRDs: []deploy.CRDResult{{
+			Name: "sveltosclusters.lib.projectsveltos.io", Action: deploy.ActionObserved,
+			Drift: deploy.DriftStatusDrifted, Changes: []deploy.FieldChange{
+				{Path: "spec.group", Live: strings.Repeat("a", 200), Desired: "lib.projectsveltos.io"},
+			}}}}
+
+		var out bytes.Buffer
+		Expect(printDiff(&out, report)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`spec.group: "` + strings.Repeat("a", diffValueLength-1) +
+			`... -> "lib.projectsveltos.io"`))
+	})
+})
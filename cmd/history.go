@@ -0,0 +1,107 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// newHistory returns the run history configured by --history-namespace and
+// --history-runs, or nil when recording is disabled
+func (f *cliFlags) newHistory() *deploy.History {
+	if f.historyRuns <= 0 && !f.showHistory && !f.changelog {
+		return nil
+	}
+	return &deploy.History{Namespace: f.historyNamespace, Runs: f.historyRuns}
+}
+
+// newQuarantine returns the quarantine configured by --quarantine-threshold
+// and --clear-quarantine, or nil when it is disabled
+func (f *cliFlags) newQuarantine() *deploy.Quarantine {
+	if f.quarantineThreshold <= 0 {
+		return nil
+	}
+	return &deploy.Quarantine{Threshold: f.quarantineThreshold, Clear: f.clearQuarantine}
+}
+
+// runHistory prints the runs recorded in the history, without writing anything
+func runHistory(ctx context.Context, c client.Client) {
+	entries, err := deploy.ReadHistory(ctx, c, cli.newHistory())
+	if err != nil {
+		fatal(err, "failed to read run history", exitCodeFailure)
+	}
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(entries)
+	} else {
+		err = printHistory(os.Stdout, entries)
+	}
+	if err != nil {
+		fatal(err, "failed to write run history", exitCodeFailure)
+	}
+}
+
+// printHistory writes entries as a table, one run per line
+func printHistory(w io.Writer, entries []deploy.HistoryEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSTATUS\tBUNDLE VERSION\tBUNDLE DIGEST\tCHANGES")
+	for i := range entries {
+		entry := &entries[i]
+		digest := entry.BundleDigest
+		if len(digest) > len("sha256:")+12 {
+			digest = digest[:len("sha256:")+12]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", entry.Time.UTC().Format(time.RFC3339), entry.Status,
+			entry.BundleVersion, digest, historyChanges(entry))
+	}
+	return tw.Flush()
+}
+
+// historyChanges summarizes, per action, the CRDs not left unchanged by a run
+func historyChanges(entry *deploy.HistoryEntry) string {
+	counts := map[deploy.Action]int{}
+	for _, action := range entry.CRDs {
+		if action != deploy.ActionUnchanged {
+			counts[action]++
+		}
+	}
+	if len(counts) == 0 {
+		return "none"
+	}
+
+	actions := slices.Sorted(maps.Keys(counts))
+	changes := make([]string, len(actions))
+	for i, action := range actions {
+		changes[i] = fmt.Sprintf("%d %s", counts[action], action)
+	}
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// serviceAccountNamespaceFile contains, in a pod, the namespace of the pod
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	// defaultLockHostname prefixes the lock identity when the hostname is unknown
+	defaultLockHostname = "crd-manager"
+)
+
+// newLock returns the Lease lock configured by --lock-name, or nil. Its
+// identity is unique to this process. In a pod, whose hostname is the pod
+// name, the pod is recorded so that other runs take the lock over once it
+// is gone.
+func (f *cliFlags) newLock() *deploy.Lock {
+	if f.lockName == "" {
+		return nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = defaultLockHostname
+	}
+
+	lock := &deploy.Lock{
+		Name:      f.lockName,
+		Namespace: f.lockNamespace,
+		Identity:  fmt.Sprintf("%s_%s", hostname, uuid.NewUUID()),
+		Wait:      f.lockWait,
+	}
+	if namespace, err := os.ReadFile(serviceAccountNamespaceFile); err == nil && hostname != defaultLockHostname {
+		lock.HolderPod = strings.TrimSpace(string(namespace)) + "/" + hostname
+	}
+	return lock
+}
@@ -18,68 +18,831 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/pflag"
+	"golang.org/x/term"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	sveltoscrds "github.com/projectsveltos/crd-manager/pkg/crds"
-	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/config"
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/notify"
+	"github.com/projectsveltos/crd-manager/pkg/pushgateway"
+	"github.com/projectsveltos/crd-manager/pkg/version"
 	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
 const (
-	appManagedByLabel = "app.kubernetes.io/managed-by"
+	outputText = "text"
+	outputJSON = "json"
+)
+
+const (
+	// modeOneShot deploys the CRDs once and exits
+	modeOneShot = "oneshot"
+
+	// modeController keeps deploying the CRDs until terminated
+	modeController = "controller"
+)
+
+const (
+	// exitCodeFailure is used when the run fails
+	exitCodeFailure = 1
+
+	// exitCodeVerificationFailure is used when the bundle signature cannot be verified
+	exitCodeVerificationFailure = 2
+
+	// exitCodeWaitTimeout is used when, with --wait-only or --wait, CRDs are
+	// still not established once --wait-timeout expires
+	exitCodeWaitTimeout = 3
+
+	// exitCodeDriftDetected is used when, with --observe-only, CRDs are
+	// missing or differ from the bundle or, with --provenance, CRDs do not
+	// come from the source they record
+	exitCodeDriftDetected = 4
+
+	// exitCodeLockTimeout is used when, with --lock-name, another run still
+	// holds the lock once --lock-wait expires
+	exitCodeLockTimeout = 5
+
+	// exitCodeDoctorFindings is used when, with --doctor, a finding reaches
+	// the --doctor-fail-on severity
+	exitCodeDoctorFindings = 6
+
+	// exitCodeVerifyMissing is used when, with --verify-install or --check,
+	// CRDs are missing
+	exitCodeVerifyMissing = 7
+
+	// exitCodeVerifyNotEstablished is used when, with --verify-install, no CRD
+	// is missing but some are not established
+	exitCodeVerifyNotEstablished = 8
+
+	// exitCodeVerifyDrifted is used when, with --verify-install, every CRD is
+	// established but some differ from the bundle or, with --check, no CRD is
+	// missing but some are outdated
+	exitCodeVerifyDrifted = 9
+
+	// exitCodeCancelled is used when, with --confirm, the plan is not confirmed
+	exitCodeCancelled = 10
 )
 
 var (
 	setupLog = ctrl.Log.WithName("setup")
+
+	// cli holds the flags the process was started with
+	cli = &cliFlags{}
 )
 
+// cliFlags holds the values of the command line flags, completed from the
+// environment and the configuration file. A configuration reload parses them
+// into new cliFlags, leaving the ones in use untouched.
+type cliFlags struct {
+	configFile string
+	mode       string
+
+	resyncPeriod         time.Duration
+	configReloadInterval time.Duration
+	metricsBindAddress   string
+	deleteOnShutdown     bool
+	confirmDeleteCRDs    bool
+	shutdownTimeout      time.Duration
+
+	maintenanceWindows        []string
+	maintenanceWindowTimezone string
+	alwaysCreateMissing       bool
+
+	reconcileEndpoint        bool
+	reconcileEndpointOptions controller.ReconcileEndpointOptions
+
+	certificateAuthority  string
+	tlsServerName         string
+	insecureSkipTLSVerify bool
+
+	observeOnly bool
+	dryRun      bool
+	confirm     bool
+	assumeYes   bool
+	waitOnly    bool
+	waitApplied bool
+	waitTimeout time.Duration
+
+	otelEndpoint string
+
+	showVersion bool
+
+	auditLogPath     string
+	auditLogFailure  string
+	auditLogObserved bool
+
+	terminationMessagePath string
+
+	output          string
+	template        bool
+	printRBAC       bool
+	forceOwnership  bool
+	ownershipPolicy string
+	adoptExisting   string
+	terminating     string
+	terminatingWait time.Duration
+	applyStrategy   string
+	fieldManager    string
+	forceConflicts  bool
+	setLastApplied  bool
+	failOnWarnings  bool
+	maxObjectSize   int64
+	ownerRef        string
+	sizeWarnPercent int
+	helmRelease     deploy.HelmReleaseOptions
+
+	conversionWebhook              deploy.ConversionWebhookOptions
+	disableConversionWebhooks      bool
+	allowUnsafeConversionDowngrade bool
+	skipConversionCheck            bool
+	conversionCheckRead            bool
+	injectCAFrom                   string
+	injectCAFromPerCRD             map[string]string
+	stripCEL                       bool
+	disabledVersions               []string
+	storageVersions                []string
+	allowUnstoredStorageVersion    bool
+	category                       string
+	printerColumns                 []string
+	crdLabels                      map[string]string
+	crdAnnotations                 map[string]string
+	failOnNameConflicts            bool
+	failFast                       bool
+	strictParse                    bool
+	components                     []string
+	includeCRDs                    []string
+	excludeCRDs                    []string
+	applySet                       string
+	removeObsolete                 bool
+	smokeTest                      bool
+	rollbackOnFailure              bool
+	rollbackTimeout                time.Duration
+	checkExistingCRs               bool
+	checkExistingCRsLimit          int64
+	failOnIncompatibleCRs          bool
+	protectCRDs                    bool
+	protectServiceAccount          string
+	mergeVersions                  bool
+	preserve                       []string
+	forceRemoveObsolete            bool
+	prune                          bool
+	pruneForce                     bool
+	cascade                        bool
+	cascadeTimeout                 time.Duration
+	applySetNamespace              string
+	lockName                       string
+	lockNamespace                  string
+	lockWait                       time.Duration
+	showHistory                    bool
+	historyNamespace               string
+	historyRuns                    int
+	quarantineThreshold            int
+	clearQuarantine                []string
+	circuitBreakerThreshold        int
+	retries                        int
+	retryInterval                  time.Duration
+	versionMarker                  string
+	circuitBreakerCoolDown         time.Duration
+	doctor                         bool
+	doctorFailOn                   string
+	doctorSkip                     []string
+	verifyInstall                  bool
+	checkInstalled                 bool
+	showProvenance                 bool
+	changelog                      bool
+	fieldValidation                string
+	patchFile                      string
+
+	bundleURLOptions bundle.URLOptions
+	bundleArchive    string
+	crdDir           string
+	crdFile          string
+	bundleEmbedded   bool
+	strictSources    bool
+	bundleVerifyKey  string
+
+	// bundleSources are the sources the bundle is merged from
+	bundleSources []*bundle.Bundle
+
+	allowedGroups         []string
+	allowedGroupsWarnOnly bool
+
+	notifyOptions notify.Options
+
+	pushgatewayOptions pushgateway.Options
+
+	asClusterProfile      bool
+	applyClusterProfile   bool
+	clusterProfileOptions deploy.ClusterProfileOptions
+}
+
 func main() {
 	klog.InitFlags(nil)
 
-	initFlags(pflag.CommandLine)
-	pflag.CommandLine.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
-	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
-	pflag.Parse()
-
 	ctrl.SetLogger(klog.Background())
 
+	if err := cli.parseFlags(pflag.CommandLine, os.Args[1:]); err != nil {
+		fatal(err, "invalid configuration", exitCodeFailure)
+	}
+
+	if cli.showVersion {
+		fmt.Fprintln(os.Stdout, version.Get())
+		return
+	}
+
+	opts, err := cli.getOptions()
+	if err != nil {
+		fatal(err, "invalid configuration", exitCodeFailure)
+	}
+
+	// Opened once: a configuration reload keeps using the same audit log
+	if opts.AuditLog, err = openAuditLog(); err != nil {
+		fatal(err, "invalid configuration", exitCodeFailure)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	opts.Bundle, err = cli.loadBundle(ctx)
+	if err != nil {
+		code := exitCodeFailure
+		var verificationErr *bundle.VerificationError
+		if errors.As(err, &verificationErr) {
+			code = exitCodeVerificationFailure
+		}
+		fatal(err, "failed to load CRD bundle", code)
+	}
+
+	if cli.template || cli.printRBAC || (cli.asClusterProfile && !cli.applyClusterProfile) {
+		runOffline(opts)
+		return
+	}
+
 	scheme, err := initScheme()
 	if err != nil {
-		os.Exit(1)
+		fatal(err, "failed to initialize scheme", exitCodeFailure)
 	}
 
 	restConfig := ctrl.GetConfigOrDie()
+	restConfig.WarningHandlerWithContext = deploy.WarningHandler{}
+	if err := applyTLSOverrides(restConfig); err != nil {
+		fatal(err, "invalid TLS configuration", exitCodeFailure)
+	}
 
 	var c client.Client
 	c, err = client.New(restConfig, client.Options{Scheme: scheme})
 	if err != nil {
-		werr := fmt.Errorf("failed to connect: %w", err)
-		log.Fatal(werr)
+		fatal(err, fmt.Sprintf("failed to connect (%s)", describeTLS(restConfig)), exitCodeFailure)
 	}
 
-	ctx := ctrl.SetupSignalHandler()
+	ctx = initTracing(ctx)
+	defer stopTracing()
+
+	if runWithoutServerVersion(ctx, c, opts) {
+		return
+	}
+
+	opts.ServerVersion, err = k8s_utils.GetKubernetesVersion(ctx, restConfig, setupLog)
+	if err != nil {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to detect server version (%s), "+
+			"version dependent defaults are not applied: %v", describeTLS(restConfig), err))
+	}
+
+	run(ctx, restConfig, c, opts)
+}
+
+// runWithoutServerVersion runs the modes which do not need the server
+// version, and returns whether one ran
+func runWithoutServerVersion(ctx context.Context, c client.Client, opts *deploy.Options) bool {
+	switch {
+	case cli.showHistory:
+		runHistory(ctx, c)
+	case cli.waitOnly:
+		runWaitOnly(ctx, c, opts)
+	case cli.asClusterProfile:
+		runApplyClusterProfile(ctx, c, opts)
+	case cli.checkInstalled:
+		runCheckInstalled(ctx, c, opts)
+	case cli.showProvenance:
+		runProvenance(ctx, c)
+	default:
+		return false
+	}
+	return true
+}
+
+// runOffline runs the modes which do not contact any cluster
+func runOffline(opts *deploy.Options) {
+	if cli.printRBAC {
+		runPrintRBAC(opts)
+		return
+	}
+	if cli.asClusterProfile {
+		if err := deploy.WriteClusterProfile(os.Stdout, opts, &cli.clusterProfileOptions, setupLog); err != nil {
+			fatal(err, "failed to render ClusterProfile", exitCodeFailure)
+		}
+		return
+	}
+	if err := deploy.Template(os.Stdout, opts, setupLog); err != nil {
+		fatal(err, "failed to render CRDs", exitCodeFailure)
+	}
+}
+
+// run runs the mode the flags select, once connected to the cluster
+func run(ctx context.Context, restConfig *rest.Config, c client.Client, opts *deploy.Options) {
+	switch {
+	case cli.doctor:
+		runDoctor(ctx, c, opts)
+	case cli.verifyInstall:
+		runVerifyInstall(ctx, c, opts)
+	case cli.changelog:
+		runChangelog(ctx, c, opts)
+	case cli.dryRun:
+		runDryRun(ctx, c, opts)
+	case cli.mode == modeController:
+		if err := runController(ctx, restConfig, c, opts); err != nil {
+			fatal(err, "controller failed", exitCodeFailure)
+		}
+	default:
+		runOneShot(ctx, restConfig, c, opts)
+	}
+}
+
+// runOneShot deploys the CRDs once, then exits non-zero if the run failed,
+// with --wait, the CRDs did not get established or, in observe-only mode,
+// drift was detected
+func runOneShot(ctx context.Context, restConfig *rest.Config, c client.Client, opts *deploy.Options) {
+	if cli.confirm {
+		confirmPlan(ctx, c, opts)
+	}
+	opts.Progress = printOnCompletion(restConfig.Host)
+	start := time.Now()
+	report, err := deploy.Deploy(ctx, c, opts, setupLog)
+	reportTermination(report, err)
+	notifyRun(ctx, report, err)
+	pushMetrics(ctx, report, time.Since(start))
+	if err != nil {
+		var lockErr *deploy.LockError
+		if errors.As(err, &lockErr) {
+			exit(exitCodeLockTimeout)
+		}
+		var waitErr *deploy.WaitError
+		if errors.As(err, &waitErr) {
+			exit(exitCodeWaitTimeout)
+		}
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("run failed, API server connection settings: %s",
+			describeTLS(restConfig)))
+		exit(exitCodeFailure)
+	}
+	if cli.observeOnly && report.HasDrift() {
+		exit(exitCodeDriftDetected)
+	}
+}
+
+// printOnCompletion returns the deploy.Options Progress callback printing,
+// once the run completes, its summary. The summary is built from the report
+// the RunCompleted event carries, the one embedders of the deploy library get.
+func printOnCompletion(host string) func(event deploy.ProgressEvent) {
+	return func(event deploy.ProgressEvent) {
+		if event.Phase != deploy.PhaseRunCompleted {
+			return
+		}
+		event.Report.TargetCluster = host
+		printReport(event.Report, cli.output, setupLog)
+	}
+}
+
+// openAuditLog opens the --audit-log file, returning nil when not set
+func openAuditLog() (*deploy.AuditLog, error) {
+	if cli.auditLogPath == "" {
+		return nil, nil
+	}
+	return deploy.OpenAuditLog(cli.auditLogPath, deploy.AuditFailurePolicy(cli.auditLogFailure), cli.auditLogObserved)
+}
+
+// runWaitOnly waits for the bundle CRDs to be established, without writing
+// anything, and exits non-zero if they are not once --wait-timeout expires
+func runWaitOnly(ctx context.Context, c client.Client, opts *deploy.Options) {
+	err := deploy.WaitForCRDs(ctx, c, opts, cli.waitTimeout, setupLog)
+	if err == nil {
+		writeTerminationMessage("CRDs established")
+		return
+	}
+
+	code := exitCodeFailure
+	var waitErr *deploy.WaitError
+	if errors.As(err, &waitErr) {
+		code = exitCodeWaitTimeout
+	}
+	fatal(err, "CRDs are not established", code)
+}
+
+// parseFlags parses args, then sets the flags not given in args from the
+// environment and, lowest precedence, from the configuration file
+func (f *cliFlags) parseFlags(fs *pflag.FlagSet, args []string) error {
+	f.initFlags(fs)
+	fs.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
+	fs.AddGoFlagSet(flag.CommandLine)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fromEnv, err := config.ApplyEnv(fs)
+	if err != nil {
+		return err
+	}
+	for _, name := range fromEnv {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("flag --%s set from environment variable %s",
+			name, config.EnvVarName(name)))
+	}
+
+	if f.configFile != "" {
+		fromFile, err := config.ApplyFile(fs, f.configFile)
+		if err != nil {
+			return err
+		}
+		for _, name := range fromFile {
+			setupLog.V(logs.LogInfo).Info(fmt.Sprintf("flag --%s set from config file %s", name, f.configFile))
+		}
+	}
+	setupLog.V(logs.LogInfo).Info(fmt.Sprintf("effective configuration: %s",
+		strings.Join(config.Describe(fs), " ")))
+
+	if f.output != outputText && f.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q", f.output)
+	}
+	if f.mode != modeOneShot && f.mode != modeController {
+		return fmt.Errorf("unsupported mode %q", f.mode)
+	}
+	if err := f.validateModes(); err != nil {
+		return err
+	}
+	if len(f.maintenanceWindows) > 0 && f.mode != modeController {
+		setupLog.Info("WARNING: --maintenance-window ignored: it only applies to --mode=controller")
+	}
+	if err := f.validateReconcileEndpoint(); err != nil {
+		return err
+	}
+	if f.asClusterProfile {
+		if err := f.clusterProfileOptions.Validate(); err != nil {
+			return fmt.Errorf("--clusterprofile-cluster-selector: %w", err)
+		}
+	}
+	if err := f.notifyOptions.Validate(); err != nil {
+		return err
+	}
+	return f.pushgatewayOptions.Validate()
+}
+
+// validateModes returns an error if flags selecting incompatible modes are set
+func (f *cliFlags) validateModes() error {
+	selected := 0
+	for _, set := range []bool{f.waitOnly, f.showHistory, f.doctor, f.verifyInstall, f.checkInstalled, f.changelog, f.showProvenance,
+		f.dryRun, f.asClusterProfile} {
+		if set {
+			selected++
+		}
+	}
+	if f.template && f.printRBAC {
+		return errors.New("--template and --print-rbac cannot be combined")
+	}
+	if selected > 1 || (selected == 1 && (f.template || f.mode == modeController)) {
+		return errors.New("--wait-only, --history, --doctor, --verify-install, --check, --changelog, --provenance, " +
+			"--dry-run and --as-clusterprofile cannot be combined with each other, with --template or with " +
+			"--mode=controller")
+	}
+	if f.applyClusterProfile && !f.asClusterProfile {
+		return errors.New("--apply-clusterprofile requires --as-clusterprofile")
+	}
+	return f.validateConfirm()
+}
+
+// validateConfirm returns an error if --confirm or --yes is set for a run not
+// writing CRDs, or if --confirm cannot ask for confirmation
+func (f *cliFlags) validateConfirm() error {
+	if f.confirm && (f.observeOnly || f.dryRun || f.template || f.printRBAC || f.runMode() != deploy.RunModeOneShot) {
+		return errors.New("--confirm only applies to oneshot runs writing CRDs: it cannot be combined with " +
+			"--observe-only, --dry-run, --template, --print-rbac, --mode=controller or the other modes")
+	}
+	if f.assumeYes && !f.confirm {
+		return errors.New("--yes requires --confirm")
+	}
+	if f.confirm && !f.assumeYes && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("--confirm cannot ask for confirmation, stdin is not a terminal: set --yes to apply " +
+			"the plan without asking")
+	}
+	return nil
+}
+
+// getOptions builds the deploy options from the command line flags
+func (f *cliFlags) getOptions() (*deploy.Options, error) {
+	opts := &deploy.Options{
+		ForceOwnership:    f.forceOwnership,
+		AdoptExisting:     deploy.AdoptionMode(f.adoptExisting),
+		HelmRelease:       f.helmRelease,
+		ConversionWebhook: f.conversionWebhook,
+
+		Terminating:        deploy.TerminatingPolicy(f.terminating),
+		TerminatingTimeout: f.terminatingWait,
+		ApplyStrategy:      deploy.ApplyStrategy(f.applyStrategy),
+		FieldManager:       f.fieldManager,
+		ForceConflicts:     f.forceConflicts,
+		SetLastApplied:     f.setLastApplied,
+
+		DisableConversionWebhooks:      f.disableConversionWebhooks,
+		AllowUnsafeConversionDowngrade: f.allowUnsafeConversionDowngrade,
+		SkipConversionCheck:            f.skipConversionCheck,
+		ConversionCheckRead:            f.conversionCheckRead,
+
+		InjectCAFrom:       f.injectCAFrom,
+		InjectCAFromPerCRD: f.injectCAFromPerCRD,
+
+		StripCEL:                    f.stripCEL,
+		AllowUnstoredStorageVersion: f.allowUnstoredStorageVersion,
+
+		Category:    f.category,
+		Labels:      f.crdLabels,
+		Annotations: f.crdAnnotations,
+
+		FailOnNameConflicts: f.failOnNameConflicts,
+		FailOnWarnings:      f.failOnWarnings,
+		MaxObjectSize:       f.maxObjectSize,
+		SizeWarningPercent:  f.sizeWarnPercent,
+		FailFast:            f.failFast,
+		StrictParse:         f.strictParse,
+
+		Components:  f.components,
+		IncludeCRDs: f.includeCRDs,
+		ExcludeCRDs: f.excludeCRDs,
+
+		Lock:       f.newLock(),
+		History:    f.newHistory(),
+		Quarantine: f.newQuarantine(),
+
+		CircuitBreaker: f.newCircuitBreaker(),
+
+		RemoveObsolete: f.removeObsolete,
+		SmokeTest:      f.smokeTest,
+
+		RollbackOnFailure: f.rollbackOnFailure,
+		RollbackTimeout:   f.rollbackTimeout,
+
+		CheckExistingCRs:      f.checkExistingCRs,
+		CheckExistingCRsLimit: f.checkExistingCRsLimit,
+		FailOnIncompatibleCRs: f.failOnIncompatibleCRs,
+
+		ProtectCRDs:           f.protectCRDs,
+		ProtectServiceAccount: f.protectServiceAccount,
+
+		MergeVersions:       f.mergeVersions,
+		ForceRemoveObsolete: f.forceRemoveObsolete,
+
+		Prune:      f.prune,
+		PruneForce: f.pruneForce,
+
+		Cascade:        f.cascade,
+		CascadeTimeout: f.cascadeTimeout,
+
+		WaitTimeout: f.postApplyWaitTimeout(),
+
+		Retries:       f.retries,
+		RetryInterval: f.retryInterval,
+
+		FieldValidation: f.fieldValidation,
+
+		ObserveOnly: f.observeOnly || f.dryRun,
+		Diff:        f.dryRun,
+	}
+
+	if err := f.parseOptionFlags(opts); err != nil {
+		return nil, err
+	}
+	return opts, opts.Validate()
+}
+
+// newCircuitBreaker returns the circuit breaker configured by
+// --circuit-breaker-threshold and --circuit-breaker-cooldown, or nil when it
+// is disabled
+func (f *cliFlags) newCircuitBreaker() *deploy.CircuitBreaker {
+	if f.circuitBreakerThreshold <= 0 {
+		return nil
+	}
+	return &deploy.CircuitBreaker{Threshold: f.circuitBreakerThreshold, CoolDown: f.circuitBreakerCoolDown}
+}
+
+// postApplyWaitTimeout returns how long, with --wait, runs wait for the CRDs
+// they applied to be established, 0 otherwise
+func (f *cliFlags) postApplyWaitTimeout() time.Duration {
+	if !f.waitApplied {
+		return 0
+	}
+	return f.waitTimeout
+}
 
-	err = deploySveltosCRDs(ctx, c, setupLog)
+// parseOptionFlags sets the deploy options parsed, or loaded from files, from
+// the command line flags
+func (f *cliFlags) parseOptionFlags(opts *deploy.Options) error {
+	var err error
+	opts.DisabledVersions, err = deploy.ParseCRDVersions(f.disabledVersions)
 	if err != nil {
-		os.Exit(1)
+		return fmt.Errorf("invalid --disable-version: %w", err)
 	}
+
+	opts.StorageVersions, err = deploy.ParseCRDVersions(f.storageVersions)
+	if err != nil {
+		return fmt.Errorf("invalid --storage-version: %w", err)
+	}
+
+	opts.PrinterColumns, err = deploy.ParsePrinterColumns(f.printerColumns)
+	if err != nil {
+		return fmt.Errorf("invalid --printer-column: %w", err)
+	}
+
+	if f.ownershipPolicy != "" {
+		opts.OwnershipPolicy, err = deploy.LoadOwnershipPolicy(f.ownershipPolicy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.applySet != "" {
+		opts.ApplySet, err = deploy.ParseApplySet(f.applySet, f.applySetNamespace)
+		if err != nil {
+			return fmt.Errorf("invalid --applyset: %w", err)
+		}
+	}
+
+	if f.versionMarker != "" {
+		opts.VersionMarker, err = deploy.ParseVersionMarker(f.versionMarker)
+		if err != nil {
+			return fmt.Errorf("invalid --version-marker: %w", err)
+		}
+	}
+
+	if f.ownerRef != "" {
+		opts.OwnerRef, err = deploy.ParseOwnerRef(f.ownerRef)
+		if err != nil {
+			return fmt.Errorf("invalid --owner-ref: %w", err)
+		}
+	}
+
+	opts.Preserve, err = deploy.ParsePreservePaths(f.preserve)
+	if err != nil {
+		return fmt.Errorf("invalid --preserve: %w", err)
+	}
+
+	if f.patchFile != "" {
+		opts.Patches, err = deploy.LoadPatches(f.patchFile)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBundle returns the CRD bundle to deploy, merged from the configured
+// sources by increasing precedence: embedded, --bundle-archive, --crd-dir,
+// --crd-file, --bundle-url.
+// The sources are kept in bundleSources.
+func (f *cliFlags) loadBundle(ctx context.Context) (*bundle.Bundle, error) {
+	var sources []*bundle.Bundle
+	if f.bundleEmbedded {
+		sources = append(sources, bundle.Embedded())
+	}
+	if f.bundleArchive != "" {
+		b, err := f.loadBundleArchive()
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, b)
+	}
+	local, err := f.loadLocalBundles()
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, local...)
+	if f.bundleURLOptions.URL != "" {
+		b, err := f.loadBundleURL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, b)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no CRD bundle source: --bundle-embedded=false requires --bundle-archive, --crd-dir, " +
+			"--crd-file or --bundle-url")
+	}
+	if err := f.checkAllowedGroups(sources); err != nil {
+		return nil, err
+	}
+
+	b, overrides, err := bundle.Merge(sources, f.strictSources)
+	if err != nil {
+		return nil, err
+	}
+	f.bundleSources = sources
+	for i := range overrides {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("bundle source override: %s", overrides[i].String()))
+	}
+	if len(sources) > 1 {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("using CRD bundle merged from %s (%s, %d overrides)",
+			b.Source, b.Digest(), len(overrides)))
+	}
+	return b, nil
+}
+
+// checkAllowedGroups returns an error, unless --allowed-groups-warn-only is
+// set, if a CRD of a source other than the embedded bundle belongs to an API
+// group outside --allowed-groups
+func (f *cliFlags) checkAllowedGroups(sources []*bundle.Bundle) error {
+	if err := bundle.ValidateGroupPatterns(f.allowedGroups); err != nil {
+		return fmt.Errorf("invalid --allowed-groups: %w", err)
+	}
+	var rejected error
+	for _, source := range sources {
+		err := bundle.CheckGroups(source, f.allowedGroups)
+		var policyErr *bundle.GroupPolicyError
+		if !errors.As(err, &policyErr) || !f.allowedGroupsWarnOnly {
+			rejected = errors.Join(rejected, err)
+			continue
+		}
+		setupLog.Info(fmt.Sprintf("WARNING: %v", err))
+	}
+	return rejected
+}
+
+// loadBundleURL returns the bundle fetched from --bundle-url
+func (f *cliFlags) loadBundleURL(ctx context.Context) (*bundle.Bundle, error) {
+	urlOptions := f.bundleURLOptions
+	if f.bundleVerifyKey != "" {
+		verifier, err := bundle.NewVerifierFromFile(f.bundleVerifyKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --bundle-verify-key: %w", err)
+		}
+		urlOptions.Verifier = verifier
+	}
+
+	b, err := bundle.FromURL(ctx, &urlOptions)
+	if err != nil {
+		return nil, err
+	}
+	setupLog.V(logs.LogInfo).Info(fmt.Sprintf("using CRD bundle from %s (%s, signature verified: %t)",
+		b.Source, b.Digest(), b.SignatureVerified))
+	return b, nil
+}
+
+// loadBundleArchive returns the bundle extracted from --bundle-archive
+func (f *cliFlags) loadBundleArchive() (*bundle.Bundle, error) {
+	b, err := bundle.FromArchive(f.bundleArchive, f.bundleURLOptions.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	setupLog.V(logs.LogInfo).Info(fmt.Sprintf("using CRD bundle from archive %s (archive %s, bundle %s)",
+		b.Source, b.ArchiveDigest, b.Digest()))
+	return b, nil
+}
+
+// loadLocalBundles returns the bundles read from --crd-dir and --crd-file
+func (f *cliFlags) loadLocalBundles() ([]*bundle.Bundle, error) {
+	var sources []*bundle.Bundle
+	if f.crdDir != "" {
+		b, err := bundle.FromDirectory(f.crdDir, f.bundleURLOptions.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("using CRD bundle from directory %s (%s)", b.Source, b.Digest()))
+		sources = append(sources, b)
+	}
+	if f.crdFile != "" {
+		b, err := bundle.FromFile(f.crdFile, f.bundleURLOptions.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("using CRD bundle from file %s (%s)", b.Source, b.Digest()))
+		sources = append(sources, b)
+	}
+	return sources, nil
 }
 
 func initScheme() (*runtime.Scheme, error) {
@@ -93,67 +856,718 @@ func initScheme() (*runtime.Scheme, error) {
 	return s, nil
 }
 
-func initFlags(fs *pflag.FlagSet) {}
+func (f *cliFlags) initFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.configFile, "config", "",
+		"YAML file whose keys are flag names (optionally nested, nested keys being joined by a dash). "+
+			"Environment variables and command line flags take precedence over it")
 
-func deploySveltosCRDs(ctx context.Context, c client.Client, logger logr.Logger) error {
-	objs, err := deployer.CustomSplit(string(sveltoscrds.GetSveltosCRDYAML()))
-	if err != nil {
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get Sveltos CRD instances: %v", err))
-		return err
+	f.initControllerFlags(fs)
+	f.initInspectFlags(fs)
+	f.initRenderFlags(fs)
+	f.initWriteFlags(fs)
+	f.initMutationFlags(fs)
+	f.initStateFlags(fs)
+	f.initSafetyFlags(fs)
+	f.initPruneFlags(fs)
+	f.initSelectionFlags(fs)
+	f.initBundleFlags(fs)
+	f.initReportingFlags(fs)
+
+	fs.StringVar(&f.certificateAuthority, "certificate-authority", "",
+		"PEM file with an additional CA trusted when connecting to the API server")
+	fs.StringVar(&f.tlsServerName, "tls-server-name", "",
+		"Server name used to verify the API server certificate, when it differs from the host name")
+	fs.BoolVar(&f.insecureSkipTLSVerify, "insecure-skip-tls-verify", false,
+		"Do not verify the API server certificate. Insecure, for test environments only")
+
+	fs.StringVarP(&f.output, "output", "o", outputText,
+		"Format of the run result. Either text (log lines) or json (a single JSON document on stdout)")
+
+	fs.BoolVar(&f.showVersion, "version", false,
+		"Print the crd-manager version, git SHA and build date, then exit")
+
+	fs.StringVar(&f.otelEndpoint, "otel-endpoint", "",
+		"OTLP/HTTP endpoint URL (e.g. http://collector:4318) traces are exported to. The standard OTEL_* "+
+			"environment variables are honored and, when TRACEPARENT is set, runs nest under that trace. "+
+			"Tracing is disabled unless this flag or OTEL_EXPORTER_OTLP_ENDPOINT is set")
+}
+
+// initControllerFlags registers the flags of the controller mode
+func (f *cliFlags) initControllerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.mode, "mode", modeOneShot,
+		"Either oneshot (deploy the CRDs once and exit) or controller (keep deploying them every "+
+			"--resync-period, whenever --config changes and whenever a managed CRD is edited or deleted)")
+	fs.DurationVar(&f.resyncPeriod, "resync-period", controller.DefaultResyncPeriod,
+		"Interval between two reconciliation passes in controller mode")
+	fs.DurationVar(&f.configReloadInterval, "config-reload-interval", config.DefaultWatchInterval,
+		"How often, in controller mode, --config is checked for changes")
+	fs.StringVar(&f.metricsBindAddress, "metrics-bind-address", ":8080",
+		"Address the metrics endpoint binds to in controller mode. 0 disables it")
+	fs.BoolVar(&f.deleteOnShutdown, "delete-on-shutdown", false,
+		"In controller mode, delete on termination the CRDs this process created, with all their instances. "+
+			"CRDs which existed before or are managed by another tool are kept. Meant for ephemeral test "+
+			"environments: ignored unless --i-know-this-deletes-crds is set. Requires delete on "+
+			"customresourcedefinitions")
+	fs.BoolVar(&f.confirmDeleteCRDs, "i-know-this-deletes-crds", false,
+		"Confirm --delete-on-shutdown")
+	fs.DurationVar(&f.shutdownTimeout, "shutdown-timeout", controller.DefaultShutdownTimeout,
+		"How long --delete-on-shutdown may spend deleting CRDs on termination")
+	fs.StringArrayVar(&f.maintenanceWindows, "maintenance-window", nil,
+		"In controller mode, only apply changes inside this maintenance window, read at startup. Either "+
+			"[weekdays] HH:MM-HH:MM, such as \"Mon-Fri 22:00-06:00\" (the weekdays, all by default, being the ones the "+
+			"window starts on), or a cron schedule of the window starts followed by the window duration, such as "+
+			"\"0 2 * * 6 4h\". Can be repeated. Outside the windows, passes only observe the CRDs, reporting drift "+
+			"through metrics and events")
+	fs.StringVar(&f.maintenanceWindowTimezone, "maintenance-window-timezone", "UTC",
+		"IANA time zone, such as Europe/Paris, of --maintenance-window")
+	fs.BoolVar(&f.alwaysCreateMissing, "always-create-missing", false,
+		"Create the missing CRDs even outside --maintenance-window. Requires create on customresourcedefinitions")
+	fs.BoolVar(&f.reconcileEndpoint, "reconcile-endpoint", false,
+		"In controller mode, serve on the metrics endpoint POST "+controller.ReconcileEndpointPath+", which triggers an "+
+			"immediate full reconciliation pass and answers 202 with its run ID, and GET "+
+			controller.ReconcileEndpointPath+"/<id>, which returns the result of that pass. Requests must present "+
+			"the bearer token of --reconcile-token-file")
+	fs.StringVar(&f.reconcileEndpointOptions.TokenFile, "reconcile-token-file", "",
+		"File containing the bearer token required by --reconcile-endpoint. Read at each request")
+	fs.DurationVar(&f.reconcileEndpointOptions.MinInterval, "reconcile-min-interval", controller.DefaultReconcileMinInterval,
+		"Minimum interval between two passes requested through --reconcile-endpoint. Earlier requests are "+
+			"rejected with 429")
+}
+
+// initInspectFlags registers the flags of the modes which never write CRDs
+func (f *cliFlags) initInspectFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.observeOnly, "observe-only", false,
+		"Do not write anything: compare the CRDs with the bundle and report missing, drifted and extra "+
+			"managed CRDs. In oneshot mode, exits with code 4 when drift is detected. Only needs read access to "+
+			"customresourcedefinitions (and, in controller mode, create on events)")
+	fs.BoolVar(&f.dryRun, "dry-run", false,
+		"Do not write anything: evaluate every bundle CRD against the cluster and print what a run would do, "+
+			"create, update or leave unchanged, with, for every update, the changed fields and their live and bundle "+
+			"values, then exit: 0, or 1 when CRDs could not be evaluated. With --output=json, prints the run result, "+
+			"whose crds[].changes list the changed fields. Only needs read access to customresourcedefinitions")
+	fs.BoolVar(&f.confirm, "confirm", false,
+		"In oneshot mode, first print the plan: the CRDs the run would create, update or skip, with a short "+
+			"summary of the changes. Then apply it only once \"yes\" is entered on stdin; any other answer exits "+
+			"with code 10 without writing anything. Fails when stdin is not a terminal, unless --yes is set. "+
+			"Removals of obsolete or pruned CRDs are not part of the plan")
+	fs.BoolVar(&f.assumeYes, "yes", false,
+		"With --confirm, print the plan and apply it without asking")
+	fs.BoolVar(&f.waitOnly, "wait-only", false,
+		"Do not write anything: wait until every CRD of the bundle exists and is Established, then exit. "+
+			"Exits non-zero, listing the CRDs not ready, after --wait-timeout. Only needs get on customresourcedefinitions")
+	fs.BoolVar(&f.waitApplied, "wait", false,
+		"Once the CRDs are applied, wait until they are Established and their names accepted before exiting, "+
+			"so that the Sveltos controllers started next can use them. Exits non-zero, listing the CRDs not "+
+			"ready, after --wait-timeout. In controller mode, every pass waits. Only needs get on "+
+			"customresourcedefinitions")
+	fs.DurationVar(&f.waitTimeout, "wait-timeout", deploy.DefaultWaitTimeout,
+		"How long --wait-only and --wait wait for the CRDs to be established")
+
+	fs.BoolVar(&f.showHistory, "history", false,
+		"Print, newest first, what the last runs recorded in the history did, then exit without writing "+
+			"anything. Requires get on configmaps in --history-namespace")
+	fs.BoolVar(&f.doctor, "doctor", false,
+		"Run read-only checks of the bundle CRDs (established, stored-versions, conversion-webhook, terminating, "+
+			"orphaned, drift), print the findings with their remediation, then exit without writing anything. "+
+			"Requires get and list on customresourcedefinitions, get on services and list on endpointslices")
+	fs.BoolVar(&f.verifyInstall, "verify-install", false,
+		"Check once, without waiting or writing anything, that every bundle CRD exists, is established and "+
+			"matches the bundle spec hash, print the offending CRDs per problem, then exit: 0 when installed, "+
+			"7 when CRDs are missing, 8 when CRDs are not established, 9 when CRDs drifted. "+
+			"Requires get on customresourcedefinitions")
+	fs.BoolVar(&f.checkInstalled, "check", false,
+		"Check once, reading the CRDs metadata only, which bundle CRDs are current, outdated (not carrying the "+
+			"projectsveltos.io/bundle-hash annotation of the bundle, e.g. because another bundle was applied or "+
+			"another tool wrote them) or missing, print the CRDs per category, then exit: 0 when all are current, "+
+			"7 when CRDs are missing, 9 when CRDs are outdated. Requires get on customresourcedefinitions")
+	fs.BoolVar(&f.showProvenance, "provenance", false,
+		"Print, reading the CRDs metadata only, the bundle source every CRD managed by crd-manager records (its "+
+			"type and location, recorded when the bundle is merged from several sources) and whether the CRD bundle "+
+			"hash is the one of that source, among the configured sources and the embedded bundle, then exit: 0, "+
+			"or 4 when CRDs do not come from the source they record or, recording none, match no known source. "+
+			"Requires list on customresourcedefinitions")
+	fs.BoolVar(&f.changelog, "changelog", false,
+		"Print which CRDs were added, removed or modified, with their changed versions, between the bundle the last "+
+			"successful run recorded in the history applied and the current one, then exit without writing anything. "+
+			"Runs record the applied bundle, and log the changelog summary, unless --history-runs is 0. "+
+			"Requires get on configmaps in --history-namespace")
+	fs.StringVar(&f.doctorFailOn, "doctor-fail-on", string(deploy.SeverityError),
+		"With --doctor, exit non-zero when a finding is at least this severe: warning or error")
+	fs.StringSliceVar(&f.doctorSkip, "doctor-skip", nil,
+		"With --doctor, checks not to run")
+}
+
+// initRenderFlags registers the flags printing the CRDs, their RBAC or a
+// ClusterProfile instead of deploying them
+func (f *cliFlags) initRenderFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.template, "template", false,
+		"Print to stdout, as multi-document YAML, the CRDs with all mutations applied, in apply order, "+
+			"instead of deploying them. No cluster is contacted")
+	fs.BoolVar(&f.printRBAC, "print-rbac", false,
+		"Print to stdout, as multi-document YAML, the least privilege ClusterRole, Roles and bindings the other "+
+			"flags (mode, observe-only, applyset, lock, history...) require, bound to --protect-crds-service-account, "+
+			"instead of running. No cluster is contacted")
+
+	fs.BoolVar(&f.asClusterProfile, "as-clusterprofile", false,
+		"Print to stdout, as multi-document YAML, a Sveltos ClusterProfile deploying the CRDs, with all mutations "+
+			"applied, to the clusters matching --clusterprofile-cluster-selector, and the ConfigMaps, one per CRD, "+
+			"it references, instead of deploying them. The objects carry the bundle digest in the "+
+			deploy.BundleDigestAnnotation+" annotation. No cluster is contacted unless --apply-clusterprofile is set")
+	fs.BoolVar(&f.applyClusterProfile, "apply-clusterprofile", false,
+		"With --as-clusterprofile, create or update in place the ClusterProfile and its ConfigMaps in the "+
+			"management cluster instead of printing them, deleting the ConfigMaps of CRDs no longer in the bundle. "+
+			"Requires get, create and update on the clusterprofile and get, create, update, list and delete on "+
+			"configmaps in --clusterprofile-namespace")
+	fs.StringVar(&f.clusterProfileOptions.Name, "clusterprofile-name", deploy.DefaultClusterProfileName,
+		"Name of the --as-clusterprofile ClusterProfile, and prefix of its ConfigMaps")
+	fs.StringVar(&f.clusterProfileOptions.ClusterSelector, "clusterprofile-cluster-selector", "",
+		"Label selector (e.g. env=prod) of the managed clusters the --as-clusterprofile ClusterProfile deploys "+
+			"the CRDs to. Required with --as-clusterprofile")
+	fs.StringVar(&f.clusterProfileOptions.Namespace, "clusterprofile-namespace", deploy.ConfigMapNamespace,
+		"Namespace of the ConfigMaps the --as-clusterprofile ClusterProfile references")
+}
+
+// initWriteFlags registers the flags deciding how and which Sveltos CRDs are written
+func (f *cliFlags) initWriteFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.forceOwnership, "force-ownership", false,
+		"Update Sveltos CRDs even when they are managed by another tool (e.g. Helm or Argo CD)")
+	fs.StringVar(&f.adoptExisting, "adopt-existing", "",
+		"Adopt Sveltos CRDs already present without the crd-manager ownership label. two-phase (the "+
+			"default when the flag is given without value) only adds the ownership markers, the spec being "+
+			"updated from the next run on; auto adopts and updates in the same run")
+	fs.Lookup("adopt-existing").NoOptDefVal = string(deploy.AdoptionTwoPhase)
+	fs.StringVar(&f.terminating, "terminating-crds", string(deploy.TerminatingReport),
+		"What happens to Sveltos CRDs found terminating (deleted, their deletion blocked by the finalizers of "+
+			"their instances): report (leave them untouched) or recreate (wait for the deletion to complete, "+
+			"up to --terminating-timeout, then create them again)")
+	fs.DurationVar(&f.terminatingWait, "terminating-timeout", deploy.DefaultTerminatingTimeout,
+		"How long --terminating-crds=recreate waits for the deletion of a CRD to complete")
+	fs.StringVar(&f.applyStrategy, "apply-strategy", string(deploy.ApplyStrategyServerSide),
+		"How outdated Sveltos CRDs are written: server-side (create and update CRDs with server-side apply, "+
+			"leaving the fields of other field managers untouched and failing the CRDs whose fields they set to "+
+			"different values, unless --force-conflicts is set), patch (send a merge patch restricted to the spec "+
+			"and the labels/annotations crd-manager sets, leaving out unchanged CRDs) or update (replace the whole "+
+			"CRD, overwriting the fields of other field managers). server-side and patch require the patch verb on "+
+			"customresourcedefinitions")
+	fs.StringVar(&f.fieldManager, "field-manager", deploy.DefaultFieldManager,
+		"Field manager of the Sveltos CRD creates and updates, owning, with --apply-strategy=server-side, the "+
+			"fields crd-manager sets")
+	fs.BoolVar(&f.forceConflicts, "force-conflicts", false,
+		"With --apply-strategy=server-side, take over the fields other field managers, such as Helm or Argo CD, "+
+			"set to different values instead of failing the CRDs")
+	fs.BoolVar(&f.setLastApplied, "set-last-applied", false,
+		"Record, on create and update, the applied CRD in the kubectl.kubernetes.io/last-applied-configuration "+
+			"annotation, so that kubectl apply and kubectl diff behave as if kubectl had applied it. CRDs too "+
+			"large for the annotation size limit are written without it, with a warning")
+	fs.BoolVar(&f.failOnWarnings, "fail-on-warnings", false,
+		"Fail Sveltos CRDs for which the API server returns warnings (for instance for deprecated schema "+
+			"constructs or from admission webhooks). The CRDs are still written; warnings are always logged "+
+			"and reported")
+	fs.StringVar(&f.ownerRef, "owner-ref", "",
+		"Existing cluster-scoped object, as <apiVersion>/<kind>/<name>, set as non-controller owner of every "+
+			"Sveltos CRD created, so that deleting it garbage collects them. Owner references of existing CRDs "+
+			"are preserved. Requires get on the owner")
+	fs.Int64Var(&f.maxObjectSize, "max-object-size", deploy.DefaultMaxObjectSize,
+		"Size, in bytes, the serialized size of each Sveltos CRD is compared with before it is applied. The "+
+			"default is the default etcd request size limit. Sizes are reported and, when the API server rejects "+
+			"a CRD as too large, included in the error")
+	fs.IntVar(&f.sizeWarnPercent, "size-warning-percent", deploy.DefaultSizeWarningPercent,
+		"Percentage of --max-object-size above which a warning is logged for a Sveltos CRD")
+	fs.StringVar(&f.ownershipPolicy, "ownership-policy", "",
+		"YAML file deciding, per CRD, whether crd-manager manages, skips or adopts it and which "+
+			"labels/annotations indicate foreign ownership. Takes precedence over the built-in heuristics")
+
+	fs.StringVar(&f.helmRelease.Name, "helm-release-name", "",
+		"Helm release (typically run as its pre-install/pre-upgrade hook) whose meta.helm.sh annotations and "+
+			"app.kubernetes.io/managed-by: Helm label are stamped on applied CRDs. Requires --helm-release-namespace")
+	fs.StringVar(&f.helmRelease.Namespace, "helm-release-namespace", "",
+		"Namespace of the --helm-release-name release")
+	fs.BoolVar(&f.helmRelease.ResourcePolicyKeep, "helm-resource-policy-keep", false,
+		"With --helm-release-name, also add helm.sh/resource-policy: keep so that uninstalling the release keeps the CRDs")
+
+	fs.StringVar(&f.fieldValidation, "field-validation", "",
+		"Field validation (Strict, Warn or Ignore) used when creating and updating CRDs. "+
+			"Defaults to Strict, or Warn on API servers older than v1.25")
+}
+
+// initMutationFlags registers the flags changing the CRDs before they are applied
+func (f *cliFlags) initMutationFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.conversionWebhook.Namespace, "conversion-webhook-namespace", "",
+		"Namespace of the service CRDs with a Webhook conversion strategy send conversion requests to. "+
+			"Empty keeps the bundle value")
+	fs.StringVar(&f.conversionWebhook.Service, "conversion-webhook-service", "",
+		"Name of the service CRDs with a Webhook conversion strategy send conversion requests to. "+
+			"Empty keeps the bundle value")
+	fs.Int32Var(&f.conversionWebhook.Port, "conversion-webhook-port", 0,
+		"Port of the service CRDs with a Webhook conversion strategy send conversion requests to. "+
+			"Zero keeps the bundle value")
+
+	fs.BoolVar(&f.disableConversionWebhooks, "disable-conversion-webhooks", false,
+		"Switch CRDs with a Webhook conversion strategy to the None strategy. "+
+			"Use on clusters where no conversion webhook is deployed")
+	fs.BoolVar(&f.allowUnsafeConversionDowngrade, "allow-unsafe-conversion-downgrade", false,
+		"With --disable-conversion-webhooks, also downgrade CRDs serving multiple versions with different schemas")
+	fs.BoolVar(&f.skipConversionCheck, "skip-conversion-check", false,
+		"Do not check, before an update changes the storage version of a CRD using webhook conversion, that "+
+			"its conversion webhook Service exists and has ready endpoints. The check requires get on services "+
+			"and list on endpointslices")
+	fs.BoolVar(&f.conversionCheckRead, "conversion-check-read", false,
+		"Also check the conversion webhook by reading an existing instance in the new storage version, which "+
+			"requires a conversion. Requires list on the CRD resources")
+
+	fs.StringVar(&f.injectCAFrom, "inject-ca-from", "",
+		"cert-manager Certificate (namespace/name) whose CA is injected into CRDs using a conversion webhook")
+	fs.StringToStringVar(&f.injectCAFromPerCRD, "inject-ca-from-crd", nil,
+		"Per CRD override of --inject-ca-from, as crd-name=namespace/name pairs")
+
+	fs.BoolVar(&f.stripCEL, "strip-cel", false,
+		"Remove CEL validation rules (x-kubernetes-validations) from CRDs. "+
+			"Always done when the API server is older than v1.25")
+
+	fs.StringArrayVar(&f.disabledVersions, "disable-version", nil,
+		"Stop serving a CRD version, in the <crdName>:<version> format. Can be repeated")
+
+	fs.StringArrayVar(&f.storageVersions, "storage-version", nil,
+		"Make a served CRD version, in the <crdName>:<version> format, the only storage version "+
+			"in place of the bundle one. Can be repeated, once per CRD")
+	fs.BoolVar(&f.allowUnstoredStorageVersion, "allow-unstored-storage-version", false,
+		"Allow --storage-version to select a version the live CRD has never stored")
+
+	fs.StringVar(&f.category, "add-category", "sveltos",
+		"Category added to every CRD, so that kubectl get <category> lists all Sveltos resources. Empty disables it")
+	fs.StringArrayVar(&f.printerColumns, "printer-column", nil,
+		"Additional printer column, in the <crdName>:<name>:<type>:<jsonPath> format. "+
+			"Use * as crdName to target all CRDs. Can be repeated")
+	fs.StringToStringVar(&f.crdLabels, "crd-label", nil,
+		"Label added to every CRD, in the key=value format. Can be repeated. Labels a previous run added "+
+			"and no longer given are removed; labels set by other actors are left untouched")
+	fs.StringToStringVar(&f.crdAnnotations, "crd-annotation", nil,
+		"Annotation added to every CRD, in the key=value format. Can be repeated. Annotations a previous run "+
+			"added and no longer given are removed; annotations set by other actors are left untouched")
+
+	fs.BoolVar(&f.failOnNameConflicts, "fail-on-name-conflicts", false,
+		"Abort, before any write, if a name (plural, singular, shortName, kind) of a Sveltos CRD "+
+			"is already claimed by another CRD. By default conflicts are only reported")
+
+	fs.BoolVar(&f.mergeVersions, "merge-versions", false,
+		"Never remove versions from live CRDs: versions the bundle dropped are kept, with their served state, "+
+			"while bundle versions are added or updated. The bundle storage version stays the only storage version")
+	fs.StringSliceVar(&f.preserve, "preserve", nil,
+		"Paths within the CRD spec, e.g. spec.names.shortNames or spec.versions[*].additionalPrinterColumns, "+
+			"whose live values are merged with the bundle ones instead of being replaced: lists get the union of "+
+			"both (named objects matched by name, the bundle definition winning), objects the live keys the bundle "+
+			"does not set and scalars keep the live value. [*] descends into the same-named elements of a list, e.g. versions")
+
+	fs.StringVar(&f.patchFile, "patch-file", "",
+		"YAML file with a list of patches, each naming a bundle CRD (crd) and either a strategic-merge "+
+			"patch body (patch) or, with type json6902, a list of RFC 6902 operations (ops), applied before deploying")
+}
+
+// initStateFlags registers the flags of the objects recording the runs state in the cluster
+func (f *cliFlags) initStateFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.applySet, "applyset", "",
+		"ApplySet parent object, in the <secret|configmap>/<name> format. Deployed CRDs are labelled as "+
+			"its members and members no longer part of the bundle are deleted. Requires get, create and update "+
+			"on the parent and delete on customresourcedefinitions")
+	fs.StringVar(&f.applySetNamespace, "applyset-namespace", deploy.ConfigMapNamespace,
+		"Namespace of the --applyset parent object")
+
+	fs.StringVar(&f.versionMarker, "version-marker", "",
+		"namespace/name of a ConfigMap recording the version, digest and completion time of the bundle, advanced "+
+			"only by runs in which every CRD was applied and is in sync with the bundle, for other components to "+
+			"gate on. Requires get, create and update on that ConfigMap")
+
+	fs.StringVar(&f.lockName, "lock-name", "",
+		"coordination.k8s.io Lease held while CRDs are written, so that concurrent runs (for instance a Helm hook "+
+			"and a CronJob) do not race. Expired leases, and leases whose holder pod is gone, are taken over. "+
+			"Requires get, create and update on leases and get on pods")
+	fs.StringVar(&f.lockNamespace, "lock-namespace", deploy.ConfigMapNamespace,
+		"Namespace of the --lock-name Lease")
+	fs.DurationVar(&f.lockWait, "lock-wait", deploy.DefaultLockWait,
+		"How long to wait for the --lock-name Lease held by another run. The exit code is 5 once it expires")
+
+	fs.StringVar(&f.historyNamespace, "history-namespace", deploy.ConfigMapNamespace,
+		"Namespace of the "+deploy.HistoryConfigMapName+" ConfigMap recording what each run did. Recording "+
+			"requires get, create and update on configmaps in that namespace")
+	fs.IntVar(&f.historyRuns, "history-runs", deploy.DefaultHistoryRuns,
+		"Number of runs kept in the history, the oldest being trimmed first. 0 disables recording")
+	fs.IntVar(&f.quarantineThreshold, "quarantine-threshold", 0,
+		"Quarantine the CRDs failing this many runs in a row with the same error class (API error reason or "+
+			"denying admission webhook): they are reported as quarantined, and not attempted, until their definition "+
+			"in the bundle changes or their quarantine is cleared. The quarantine is kept in the history ConfigMap, "+
+			"which --history-runs must not disable. 0 disables it")
+	fs.StringSliceVar(&f.clearQuarantine, "clear-quarantine", nil,
+		"CRDs whose quarantine is cleared, or * for all of them. The "+deploy.ClearQuarantineAnnotation+
+			" annotation on the history ConfigMap, listing them, clears them once")
+}
+
+// initSafetyFlags registers the flags handling failures and guarding the applied CRDs
+func (f *cliFlags) initSafetyFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&f.retries, "retries", 0,
+		"How many times, within a run, a CRD failing with a server-side unavailability error (5xx, 429 Too Many "+
+			"Requests or a timeout) is attempted again before giving up. The other CRDs are processed either way "+
+			"and the run lists every CRD which failed")
+	fs.DurationVar(&f.retryInterval, "retry-interval", deploy.DefaultRetryInterval,
+		"Delay before the first retry of a CRD, doubling after every attempt")
+	fs.IntVar(&f.circuitBreakerThreshold, "circuit-breaker-threshold", 0,
+		"Stop processing CRDs once this many distinct CRDs failed in a row with a server-side unavailability "+
+			"error (5xx, 429 Too Many Requests or a timeout): the remaining CRDs are reported as not attempted, as "+
+			"are those of the controller passes starting before --circuit-breaker-cooldown expires. The first pass "+
+			"after it processes a single CRD as a probe before resuming. Validation, forbidden and other "+
+			"deterministic errors do not count. In controller mode, an "+
+			"Event is recorded when it opens, which requires create and patch on events.events.k8s.io in the "+
+			"default namespace. 0 disables it")
+	fs.DurationVar(&f.circuitBreakerCoolDown, "circuit-breaker-cooldown", deploy.DefaultCircuitBreakerCoolDown,
+		"How long, once open, the circuit breaker stops processing CRDs for")
+
+	fs.BoolVar(&f.failFast, "fail-fast", false,
+		"Stop at the first CRD which fails, reporting the following ones as not attempted. "+
+			"By default all CRDs are processed and the run fails at the end")
+	fs.BoolVar(&f.strictParse, "strict-parse", false,
+		"Abort the run, before any write, when a bundle document cannot be parsed. By default malformed "+
+			"documents are skipped and reported, the valid ones are applied and the run fails at the end")
+
+	fs.BoolVar(&f.smokeTest, "smoke-test", false,
+		"Once all CRDs are applied, create with server-side dry-run a sample object for every served CRD version, "+
+			"to verify admission, conversion and defaulting. No object is persisted. Requires create on the Sveltos resources")
+	fs.BoolVar(&f.rollbackOnFailure, "rollback-on-failure", false,
+		"Once all CRDs are applied, verify each one is established, has its names accepted and, with --smoke-test, "+
+			"accepts its samples. CRDs updated by the run failing it are restored to their spec before the update, "+
+			"CRDs created by the run are deleted unless they have instances. The run fails either way. "+
+			"Requires delete on customresourcedefinitions and list on the Sveltos resources")
+	fs.DurationVar(&f.rollbackTimeout, "rollback-timeout", deploy.DefaultRollbackTimeout,
+		"How long --rollback-on-failure waits for the CRDs applied, then for the ones restored, to be established")
+
+	fs.BoolVar(&f.checkExistingCRs, "check-existing-crs", false,
+		"Before updating a CRD whose schema changes, validate client-side (CEL rules excluded) its existing objects "+
+			"against the new schema and warn about the ones failing it. Requires list on the Sveltos resources")
+	fs.Int64Var(&f.checkExistingCRsLimit, "check-existing-crs-limit", deploy.DefaultCheckExistingCRsLimit,
+		"Maximum number of objects --check-existing-crs validates per CRD version. 0 checks them all")
+	fs.BoolVar(&f.failOnIncompatibleCRs, "fail-on-incompatible-crs", false,
+		"With --check-existing-crs, fail the update of CRDs with existing objects not valid against the new schema")
+
+	fs.BoolVar(&f.protectCRDs, "protect-crds", false,
+		"Install a ValidatingAdmissionPolicy denying the deletion of managed CRDs, and the updates removing or "+
+			"unserving their versions, to anyone but --protect-crds-service-account. Requires Kubernetes v1.30 "+
+			"and write access to validatingadmissionpolicies and validatingadmissionpolicybindings")
+	fs.StringVar(&f.protectServiceAccount, "protect-crds-service-account", deploy.DefaultProtectionServiceAccount,
+		"ServiceAccount (namespace/name) crd-manager runs as, the only one --protect-crds lets modify managed CRDs")
+}
+
+// initPruneFlags registers the flags deleting retired and pruned CRDs
+func (f *cliFlags) initPruneFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.removeObsolete, "remove-obsolete", false,
+		"Delete the CRDs retired across Sveltos releases which are still present. CRDs with remaining "+
+			"instances are kept unless --force-remove-obsolete is set. Requires delete on customresourcedefinitions")
+	fs.BoolVar(&f.forceRemoveObsolete, "force-remove-obsolete", false,
+		"With --remove-obsolete, also delete retired CRDs which still have instances, deleting the instances")
+	fs.BoolVar(&f.prune, "prune", false,
+		"At the end of a run without failures, delete the CRDs labelled as managed by crd-manager which are no "+
+			"longer part of the embedded bundle, such as the ones a Sveltos release dropped. CRDs with remaining "+
+			"instances are kept unless --prune-force is set. Requires delete on customresourcedefinitions and list "+
+			"on the resources of the bundle API groups")
+	fs.BoolVar(&f.pruneForce, "prune-force", false,
+		"With --prune, also delete CRDs which still have instances, deleting the instances")
+	fs.BoolVar(&f.cascade, "cascade", false,
+		"Before deleting a CRD (retired with --remove-obsolete, or pruned with --prune or from --applyset), delete its instances "+
+			"and wait for them to be gone, so that their finalizers run. CRDs whose instances remain after "+
+			"--cascade-timeout are not deleted. Implies --force-remove-obsolete and --prune-force. Requires list and delete on those instances")
+	fs.DurationVar(&f.cascadeTimeout, "cascade-timeout", deploy.DefaultCascadeTimeout,
+		"How long --cascade waits for the instances of a CRD to be gone")
+}
+
+// initSelectionFlags registers the flags selecting the CRDs deployed
+func (f *cliFlags) initSelectionFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&f.components, "components", nil,
+		"Comma separated Sveltos components ("+strings.Join(crds.Components(), ", ")+") whose CRDs are deployed. "+
+			"The CRDs of other components are left untouched. By default all CRDs are deployed")
+	fs.StringSliceVar(&f.includeCRDs, "include-crds", nil,
+		"Comma separated names, or glob patterns such as *.lib.projectsveltos.io, of the CRDs to deploy. "+
+			"The other CRDs are left untouched. By default all CRDs are deployed")
+	fs.StringSliceVar(&f.excludeCRDs, "exclude-crds", nil,
+		"Comma separated names, or glob patterns such as eventtriggers.lib.projectsveltos.io, of CRDs not to "+
+			"deploy, taking precedence over --include-crds and --components. They are left untouched: neither "+
+			"created, updated nor pruned")
+}
+
+// initBundleFlags registers the flags of the bundle sources
+func (f *cliFlags) initBundleFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.bundleEmbedded, "bundle-embedded", true,
+		"Deploy the bundle embedded in the binary, the lowest precedence bundle source. Disable it to deploy "+
+			"only the content of --bundle-archive, --crd-dir, --crd-file and --bundle-url")
+	fs.StringVar(&f.bundleURLOptions.URL, "bundle-url", "",
+		"HTTPS URL of a CRD bundle, the highest precedence bundle source: its objects replace the same-named "+
+			"ones of all other sources. Requires --bundle-sha256")
+	fs.StringVar(&f.bundleURLOptions.SHA256, "bundle-sha256", "",
+		"Expected sha256 digest of the bundle fetched from --bundle-url")
+	fs.StringVar(&f.bundleURLOptions.CAFile, "bundle-ca-file", "",
+		"PEM file with additional CAs trusted when fetching the bundle from --bundle-url")
+	fs.StringVar(&f.bundleURLOptions.CertFile, "bundle-client-cert", "",
+		"PEM client certificate presented when fetching the bundle from a --bundle-url requiring mutual TLS. "+
+			"Requires --bundle-client-key")
+	fs.StringVar(&f.bundleURLOptions.KeyFile, "bundle-client-key", "",
+		"PEM key of --bundle-client-cert")
+	fs.StringVar(&f.bundleURLOptions.Proxy, "bundle-proxy", "",
+		"URL (http, https or socks5, optionally with credentials) of the proxy used to fetch the bundle from "+
+			"--bundle-url, in place of HTTPS_PROXY. NO_PROXY is honored either way")
+	fs.DurationVar(&f.bundleURLOptions.Timeout, "bundle-fetch-timeout", bundle.DefaultFetchTimeout,
+		"Timeout for fetching the bundle from --bundle-url")
+	fs.DurationVar(&f.bundleURLOptions.ConnectTimeout, "bundle-connect-timeout", bundle.DefaultConnectTimeout,
+		"Timeout for connecting, TLS handshake included, to --bundle-url or to its proxy")
+	fs.DurationVar(&f.bundleURLOptions.ReadTimeout, "bundle-read-timeout", bundle.DefaultReadTimeout,
+		"Timeout waiting for the response headers of --bundle-url once the request is sent")
+	fs.Int64Var(&f.bundleURLOptions.MaxSize, "bundle-max-size", bundle.DefaultMaxSize,
+		"Maximum size, in bytes, of the bundle fetched from --bundle-url, of --crd-file, in total of the YAML "+
+			"files of --crd-dir, or of --bundle-archive and, in total, of the files it contains")
+
+	fs.StringVar(&f.bundleArchive, "bundle-archive", "",
+		"tar.gz archive whose YAML files (.yaml or .yml), concatenated in file name order, form a bundle "+
+			"source: its objects replace the same-named ones of the embedded bundle. Its sha256 digest is logged and reported")
+	fs.StringVar(&f.crdDir, "crd-dir", "",
+		"Local directory whose YAML files (.yaml or .yml), in it and its subdirectories, concatenated in path "+
+			"order, form a bundle source, e.g. for air-gapped installs. Hidden files and directories are skipped. "+
+			"Its objects replace the same-named ones of the embedded bundle and of --bundle-archive")
+	fs.StringVar(&f.crdFile, "crd-file", "",
+		"Local multi-document YAML file forming a bundle source. Its objects replace the same-named ones of "+
+			"the embedded bundle, of --bundle-archive and of --crd-dir. Use --bundle-embedded=false to deploy "+
+			"only its CRDs")
+	fs.BoolVar(&f.strictSources, "strict-sources", false,
+		"Fail when several bundle sources define the same object, instead of taking it from the highest precedence source")
+	fs.StringSliceVar(&f.allowedGroups, "allowed-groups", bundle.DefaultAllowedGroups,
+		"API group patterns (for instance *.projectsveltos.io) the CRDs of --bundle-archive, --crd-dir, --crd-file "+
+			"and --bundle-url must belong to. The run fails, listing every source and group rejected, when a CRD matches none of them. "+
+			"The embedded bundle is exempt")
+	fs.BoolVar(&f.allowedGroupsWarnOnly, "allowed-groups-warn-only", false,
+		"Only log a warning, instead of failing, when a CRD of a source other than the embedded bundle is outside "+
+			"--allowed-groups")
+	fs.StringVar(&f.bundleVerifyKey, "bundle-verify-key", "",
+		"Cosign public key used to verify the detached signature (<bundle-url>.sig) of the bundle "+
+			"fetched from --bundle-url. The embedded bundle is never verified")
+}
+
+// initReportingFlags registers the flags reporting the run outcome
+func (f *cliFlags) initReportingFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.notifyOptions.URL, "notify-url", "",
+		"HTTPS URL the JSON run result (cluster, status, bundle version and digest, failed CRDs) is POSTed to "+
+			"at the end of a one-shot run. Delivery failures are logged and never change the exit code")
+	fs.StringVar(&f.notifyOptions.TokenFile, "notify-token-file", "",
+		"File containing the bearer token sent to --notify-url. Read at each delivery")
+	fs.StringVar(&f.notifyOptions.CAFile, "notify-ca-file", "",
+		"PEM file with additional CAs trusted when posting to --notify-url")
+	fs.DurationVar(&f.notifyOptions.Timeout, "notify-timeout", notify.DefaultTimeout,
+		"Timeout for posting the run result to --notify-url")
+	fs.StringVar((*string)(&f.notifyOptions.On), "notify-on", string(notify.OnFailure),
+		"Which runs are posted to --notify-url: failure (failed runs only) or always")
+	fs.StringVar(&f.pushgatewayOptions.URL, "pushgateway-url", "",
+		"URL of a Prometheus Pushgateway the metrics, the same ones the controller mode serves, are pushed to "+
+			"at the end of a one-shot run. Push failures are logged and never change the exit code")
+	fs.StringVar(&f.pushgatewayOptions.Job, "pushgateway-job", pushgateway.DefaultJob,
+		"Job label the metrics pushed to --pushgateway-url are grouped by. Each push replaces the metrics of the group")
+	fs.StringVar(&f.pushgatewayOptions.Instance, "pushgateway-instance", "",
+		"Optional instance label the metrics pushed to --pushgateway-url are also grouped by, e.g. the cluster name")
+	fs.StringVar(&f.pushgatewayOptions.UsernameFile, "pushgateway-username-file", "",
+		"File containing the basic authentication username sent to --pushgateway-url. Read at each push. "+
+			"Requires --pushgateway-password-file")
+	fs.StringVar(&f.pushgatewayOptions.PasswordFile, "pushgateway-password-file", "",
+		"File containing the basic authentication password sent to --pushgateway-url. Read at each push")
+	fs.StringVar(&f.pushgatewayOptions.CAFile, "pushgateway-ca-file", "",
+		"PEM file with additional CAs trusted when pushing to --pushgateway-url")
+	fs.DurationVar(&f.pushgatewayOptions.Timeout, "pushgateway-timeout", pushgateway.DefaultTimeout,
+		"Timeout for pushing the metrics to --pushgateway-url")
+
+	fs.StringVar(&f.auditLogPath, "audit-log", "",
+		"File every CRD create, update and delete is appended to, as one JSON line per write. "+
+			"Read at startup only")
+	fs.StringVar(&f.auditLogFailure, "audit-log-failure", string(deploy.AuditFailureFatal),
+		"What happens when a --audit-log entry cannot be written: fatal (the run fails) or warn")
+	fs.BoolVar(&f.auditLogObserved, "audit-log-observed", false,
+		"With --observe-only, also record in --audit-log the writes which would have been performed, marked as such")
+	fs.StringVar(&f.terminationMessagePath, "termination-message-path", defaultTerminationMessagePath,
+		"File a summary of the run (status, counts, failed CRDs and first error) is written to before exiting, "+
+			"for the pod termination message. Must match the container terminationMessagePath. Empty disables it")
+}
+
+// printReport outputs the run result. With json output, the report is the only
+// thing written to stdout; logs keep going to stderr.
+func printReport(report *deploy.Report, format string, logger logr.Logger) {
+	if format == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			logger.Error(err, "failed to write run result")
+		}
+		return
 	}
 
-	var detectedErrors error
-	for _, obj := range objs {
-		u, err := k8s_utils.GetUnstructured([]byte(obj))
-		if err != nil {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get default Sveltos CRD instance: %v", err))
-			detectedErrors = err
+	if report.ObserveOnly {
+		printObserveSummary(report, logger)
+		return
+	}
+
+	if report.Status == deploy.RunStatusPaused {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("run %s: no CRD was written (bundle %s)",
+			report.Status, report.BundleDigest))
+		return
+	}
+
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		switch {
+		case result.Action == deploy.ActionDeferred:
+			continue
+		case result.ConsecutiveFailures > 0 && result.NextRetry != nil:
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s (%s), %d consecutive failures, next retry at %s",
+				result.Name, result.Action, result.Error, result.ConsecutiveFailures,
+				result.NextRetry.Format(time.RFC3339)))
+			continue
+		case result.Error != "":
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s (%s)", result.Name, result.Action, result.Error))
+			continue
+		case result.PinnedVersion != "":
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s (pinned at %s, bundle %s)", result.Name,
+				result.Action, result.PinnedVersion, report.BundleVersion))
+			continue
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s", result.Name, result.Action))
+	}
+	printIncompatibleCRs(report, logger)
+	printParseFailures(report, logger)
+	printQuarantined(report, logger)
+	for i := range report.Removed {
+		result := &report.Removed[i]
+		if result.Error != "" {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s (%s)", result.Name, result.Action, result.Error))
+			if len(result.RemainingInstances) > 0 {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: remaining instances: %s", result.Name,
+					strings.Join(result.RemainingInstances, ", ")))
+			}
 			continue
 		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s", result.Name, result.Action))
+	}
+	for i := range report.SmokeTest {
+		result := &report.SmokeTest[i]
+		if result.Error != "" {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("smoke test CRD %s version %s: rejected (%s)",
+				result.CRD, result.Version, result.Error))
+			continue
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("smoke test CRD %s version %s: accepted", result.CRD, result.Version))
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("run %s: %d created, %d updated, %d unchanged, %d adopted, "+
+		"%d skipped-helm (drifted), %d skipped-helm (in sync), %d skipped-argocd (drifted), "+
+		"%d skipped-argocd (in sync), %d skipped-policy, %d paused, %d pinned, %d terminating, %d recreated, "+
+		"%d pruned, %d removed-obsolete, %d rolled-back, %d failed (%d denied by admission webhooks), %d not attempted, "+
+		"%d deferred (bundle %s)",
+		report.Status, report.Count(deploy.ActionCreated), report.Count(deploy.ActionUpdated),
+		report.Count(deploy.ActionUnchanged), report.Count(deploy.ActionAdopted),
+		report.CountDrift(deploy.ActionSkippedHelm, deploy.DriftStatusDrifted),
+		report.CountDrift(deploy.ActionSkippedHelm, deploy.DriftStatusInSync),
+		report.CountDrift(deploy.ActionSkippedArgoCD, deploy.DriftStatusDrifted),
+		report.CountDrift(deploy.ActionSkippedArgoCD, deploy.DriftStatusInSync),
+		report.Count(deploy.ActionSkippedPolicy), report.Count(deploy.ActionPaused), report.Count(deploy.ActionPinned),
+		report.Count(deploy.ActionTerminating), report.Count(deploy.ActionRecreated), report.Count(deploy.ActionPruned),
+		report.Count(deploy.ActionRemovedObsolete), report.Count(deploy.ActionRolledBack),
+		report.Count(deploy.ActionFailed), report.CountAdmissionDenied(), report.Count(deploy.ActionNotAttempted),
+		report.Count(deploy.ActionDeferred), report.BundleDigest))
+	printComponentSummary(report, logger)
+}
 
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("considering Sveltos CRD %s", u.GetName()))
-		err = processCustomResourceDefinition(ctx, c, u, logger)
-		if err != nil {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update Sveltos CRD %s instance: %v",
-				u.GetName(), err))
-			detectedErrors = err
+// printComponentSummary logs, per component, how many CRDs each action was
+// taken on
+func printComponentSummary(report *deploy.Report, logger logr.Logger) {
+	var names []string
+	counts := map[string]map[deploy.Action]int{}
+	var actions []deploy.Action
+	seen := map[deploy.Action]bool{}
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		if result.Component == "" {
+			continue
+		}
+		if counts[result.Component] == nil {
+			names = append(names, result.Component)
+			counts[result.Component] = map[deploy.Action]int{}
+		}
+		counts[result.Component][result.Action]++
+		if !seen[result.Action] {
+			seen[result.Action] = true
+			actions = append(actions, result.Action)
 		}
 	}
 
-	return detectedErrors
+	sort.Strings(names)
+	for _, component := range names {
+		var parts []string
+		for _, action := range actions {
+			if count := counts[component][action]; count > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", count, action))
+			}
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("component %s: %s", component, strings.Join(parts, ", ")))
+	}
 }
 
-func processCustomResourceDefinition(ctx context.Context, c client.Client, u *unstructured.Unstructured,
-	logger logr.Logger) error {
-
-	customResourceDefinition := &apiextensionsv1.CustomResourceDefinition{}
-	err := c.Get(ctx,
-		types.NamespacedName{Name: u.GetName()},
-		customResourceDefinition)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("creating Sveltos CRD %s", u.GetName()))
-			return c.Create(ctx, u)
+// printIncompatibleCRs logs the existing objects found not valid against the
+// schema of the CRDs they belong to
+func printIncompatibleCRs(report *deploy.Report, logger logr.Logger) {
+	for i := range report.CRDs {
+		for _, finding := range report.CRDs[i].IncompatibleCRs {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s version %s: %d of %d existing objects not valid "+
+				"against the new schema (%s)", report.CRDs[i].Name, finding.Version, finding.Failed, finding.Checked,
+				strings.Join(finding.Examples, "; ")))
 		}
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get default Sveltos CRD instance: %v", err))
-		return err
 	}
+}
 
-	u.SetResourceVersion(customResourceDefinition.GetResourceVersion())
-	if !isManagedByHelm(u) {
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("updating Sveltos CRD %s", u.GetName()))
-		return c.Update(ctx, u)
+// printParseFailures logs the bundle documents which could not be parsed
+func printParseFailures(report *deploy.Report, logger logr.Logger) {
+	for i := range report.ParseFailures {
+		failure := &report.ParseFailures[i]
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("bundle document %d: not parsed (%s): %q", failure.Document,
+			failure.Error, failure.Snippet))
 	}
+}
 
-	return nil
+// printQuarantined logs, prominently, the CRDs the quarantine kept from being attempted
+func printQuarantined(report *deploy.Report, logger logr.Logger) {
+	var names []string
+	for i := range report.CRDs {
+		if report.CRDs[i].Action == deploy.ActionQuarantined {
+			names = append(names, report.CRDs[i].Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	logger.Info(fmt.Sprintf("WARNING: %d CRDs are QUARANTINED and were not attempted: %s. Clear the quarantine with "+
+		"--clear-quarantine or the %s annotation on ConfigMap %s/%s", len(names), strings.Join(names, ", "),
+		deploy.ClearQuarantineAnnotation, cli.historyNamespace, deploy.HistoryConfigMapName))
 }
 
-func isManagedByHelm(u *unstructured.Unstructured) bool {
-	lbls := u.GetLabels()
-	if lbls == nil {
-		return false
+// printObserveSummary logs the outcome of an observe-only run, naming the
+// CRDs which are not in sync
+func printObserveSummary(report *deploy.Report, logger logr.Logger) {
+	printParseFailures(report, logger)
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		if result.Error != "" {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s: %s (%s)", result.Name, result.Action, result.Error))
+		}
 	}
 
-	_, ok := lbls[appManagedByLabel]
-	return ok
+	missing := report.Drifted(deploy.DriftStatusMissing)
+	drifted := report.Drifted(deploy.DriftStatusDrifted)
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("observe run %s: %d in sync, %d drifted, %d missing, %d extra, "+
+		"%d failed (bundle %s)", report.Status, len(report.Drifted(deploy.DriftStatusInSync)), len(drifted),
+		len(missing), len(report.ExtraCRDs), report.Count(deploy.ActionFailed), report.BundleDigest))
+	if len(drifted) > 0 {
+		logger.V(logs.LogInfo).Info("drifted CRDs: " + strings.Join(drifted, ", "))
+	}
+	if len(missing) > 0 {
+		logger.V(logs.LogInfo).Info("missing CRDs: " + strings.Join(missing, ", "))
+	}
+	if len(report.ExtraCRDs) > 0 {
+		logger.V(logs.LogInfo).Info("extra CRDs managed by crd-manager: " + strings.Join(report.ExtraCRDs, ", "))
+	}
 }
@@ -0,0 +1,40 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/notify"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// notifyRun posts the run result to --notify-url, if the --notify-on policy
+// says so. A delivery failure is only logged: it never changes the exit code.
+func notifyRun(ctx context.Context, report *deploy.Report, runErr error) {
+	summary := notify.NewSummary(report, runErr)
+	if !cli.notifyOptions.ShouldNotify(summary) {
+		return
+	}
+	if err := notify.Send(ctx, &cli.notifyOptions, summary); err != nil {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to notify run result: %v", err))
+		return
+	}
+	setupLog.V(logs.LogDebug).Info(fmt.Sprintf("run result posted to %s", cli.notifyOptions.URL))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// provenanceStatus is the JSON document --provenance prints
+type provenanceStatus struct {
+	CRDs []deploy.CRDProvenance `json:"crds"`
+}
+
+// runProvenance prints the source every managed CRD records and whether it
+// comes from it, and exits non-zero when some do not. The embedded bundle is
+// always a known source.
+func runProvenance(ctx context.Context, c client.Client) {
+	sources := cli.bundleSources
+	if !cli.bundleEmbedded {
+		sources = append([]*bundle.Bundle{bundle.Embedded()}, sources...)
+	}
+	provenances, err := deploy.CheckProvenance(ctx, c, sources, setupLog)
+	if err != nil {
+		fatal(err, "failed to check the CRDs provenance", exitCodeFailure)
+	}
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(&provenanceStatus{CRDs: provenances})
+	} else {
+		err = printProvenance(os.Stdout, provenances)
+	}
+	if err != nil {
+		fatal(err, "failed to write the CRDs provenance", exitCodeFailure)
+	}
+
+	var drifted []string
+	for i := range provenances {
+		if provenances[i].IsDrift() {
+			drifted = append(drifted, provenances[i].Name)
+		}
+	}
+	if len(drifted) > 0 {
+		writeTerminationMessage("CRDs not coming from their recorded source: " + strings.Join(drifted, ", "))
+		exit(exitCodeDriftDetected)
+	}
+	writeTerminationMessage("CRDs provenance verified")
+}
+
+// printProvenance writes provenances as a table, one CRD per line
+func printProvenance(w io.Writer, provenances []deploy.CRDProvenance) error {
+	if len(provenances) == 0 {
+		_, err := fmt.Fprintln(w, "no CRD managed by crd-manager")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CRD\tRECORDED SOURCE\tSTATUS\tMATCHING SOURCES")
+	for i := range provenances {
+		provenance := &provenances[i]
+		recorded := "none"
+		if provenance.Source != nil {
+			recorded = provenance.Source.String()
+		}
+		matching := make([]string, len(provenance.Matching))
+		for j := range provenance.Matching {
+			matching[j] = provenance.Matching[j].String()
+		}
+		status := string(provenance.Status)
+		if provenance.IsDrift() {
+			status += " (drift)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", provenance.Name, recorded, status, strings.Join(matching, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/pushgateway"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// pushMetrics pushes the metrics of the one-shot run to --pushgateway-url.
+// They are gathered from the registry the controller mode serves, so both
+// expose the same metrics. A push failure is only logged: it never changes
+// the exit code.
+func pushMetrics(ctx context.Context, report *deploy.Report, duration time.Duration) {
+	if !cli.pushgatewayOptions.IsEnabled() {
+		return
+	}
+	controller.RecordRun(report, duration)
+	if err := pushgateway.Push(ctx, &cli.pushgatewayOptions, metrics.Registry); err != nil {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to push metrics: %v", err))
+		return
+	}
+	setupLog.V(logs.LogDebug).Info(fmt.Sprintf("metrics pushed to %s", cli.pushgatewayOptions.URL))
+}
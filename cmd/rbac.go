@@ -0,0 +1,64 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// runPrintRBAC prints the least privilege RBAC the other flags need, without
+// contacting any cluster
+func runPrintRBAC(opts *deploy.Options) {
+	config := &deploy.RBACConfig{
+		Mode:             cli.runMode(),
+		Options:          opts,
+		DeleteOnShutdown: cli.deleteOnShutdown && cli.confirmDeleteCRDs,
+		ServiceAccount:   cli.protectServiceAccount,
+		ClusterProfile:   &cli.clusterProfileOptions,
+	}
+	if err := deploy.PrintRBAC(os.Stdout, config); err != nil {
+		fatal(err, "failed to generate RBAC", exitCodeFailure)
+	}
+}
+
+// runMode returns what the flags make crd-manager do
+func (f *cliFlags) runMode() deploy.RunMode {
+	switch {
+	case f.waitOnly:
+		return deploy.RunModeWaitOnly
+	case f.showHistory:
+		return deploy.RunModeHistory
+	case f.doctor:
+		return deploy.RunModeDoctor
+	case f.verifyInstall:
+		return deploy.RunModeVerifyInstall
+	case f.checkInstalled:
+		return deploy.RunModeCheck
+	case f.changelog:
+		return deploy.RunModeChangelog
+	case f.showProvenance:
+		return deploy.RunModeProvenance
+	case f.asClusterProfile:
+		return deploy.RunModeClusterProfile
+	case f.mode == modeController:
+		return deploy.RunModeController
+	default:
+		return deploy.RunModeOneShot
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// defaultTerminationMessagePath is where the kubelet reads the
+	// termination message of a container from, unless its
+	// terminationMessagePath says otherwise
+	defaultTerminationMessagePath = "/dev/termination-log"
+
+	// maxTerminationMessageSize is the size the kubelet truncates
+	// termination messages to
+	maxTerminationMessageSize = 4096
+
+	// maxTerminationFailedCRDs bounds how many failed CRD names are listed
+	maxTerminationFailedCRDs = 20
+)
+
+// fatal logs err, records it as the termination message and exits with code
+func fatal(err error, msg string, code int) {
+	setupLog.Error(err, msg)
+	writeTerminationMessage(fmt.Sprintf("%s: %v", msg, err))
+	exit(code)
+}
+
+// reportTermination records the outcome of a run as the termination message
+func reportTermination(report *deploy.Report, runErr error) {
+	writeTerminationMessage(runSummary(report, runErr))
+}
+
+// runSummary returns the overall status of the run, how many CRDs each
+// action was taken on, the failed CRDs and the first error
+func runSummary(report *deploy.Report, runErr error) string {
+	results := make([]deploy.CRDResult, 0, len(report.CRDs)+len(report.Removed))
+	results = append(results, report.CRDs...)
+	results = append(results, report.Removed...)
+
+	var actions []deploy.Action
+	counts := make(map[deploy.Action]int)
+	var failed []string
+	firstErr := ""
+	for i := range results {
+		result := &results[i]
+		if counts[result.Action] == 0 {
+			actions = append(actions, result.Action)
+		}
+		counts[result.Action]++
+		if result.Error == "" {
+			continue
+		}
+		failed = append(failed, result.Name)
+		if firstErr == "" {
+			firstErr = fmt.Sprintf("%s: %s", result.Name, result.Error)
+		}
+	}
+
+	status := string(report.Status)
+	if status == "" || (runErr != nil && report.Status == deploy.RunStatusSuccess) {
+		status = string(deploy.RunStatusFailed)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "run %s", status)
+	for i, action := range actions {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&sb, "%s%d %s", sep, counts[action], action)
+	}
+	if report.BundleDigest != "" {
+		fmt.Fprintf(&sb, " (bundle %s)", report.BundleDigest)
+	}
+	if len(failed) > 0 {
+		more := ""
+		if len(failed) > maxTerminationFailedCRDs {
+			more = fmt.Sprintf(" and %d more", len(failed)-maxTerminationFailedCRDs)
+			failed = failed[:maxTerminationFailedCRDs]
+		}
+		fmt.Fprintf(&sb, "\nfailed CRDs: %s%s", strings.Join(failed, ", "), more)
+	}
+	if firstErr == "" && runErr != nil {
+		firstErr = runErr.Error()
+	}
+	if firstErr != "" {
+		fmt.Fprintf(&sb, "\nerror: %s", firstErr)
+	}
+	return sb.String()
+}
+
+// writeTerminationMessage writes msg, truncated to what the kubelet keeps,
+// to --termination-message-path. Outside a container the default path does
+// not exist, and nothing is written.
+func writeTerminationMessage(msg string) {
+	if cli.terminationMessagePath == "" {
+		return
+	}
+
+	flags := os.O_WRONLY | os.O_TRUNC
+	if cli.terminationMessagePath != defaultTerminationMessagePath {
+		flags |= os.O_CREATE
+	}
+	f, err := os.OpenFile(cli.terminationMessagePath, flags, 0o600)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to open termination message file: %v", err))
+		}
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(truncateMessage(msg, maxTerminationMessageSize)); err != nil {
+		setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to write termination message: %v", err))
+	}
+}
+
+// truncateMessage shortens msg to at most size bytes, without splitting a
+// UTF-8 sequence, marking it as truncated
+func truncateMessage(msg string, size int) string {
+	if len(msg) <= size {
+		return msg
+	}
+	const marker = "..."
+	end := size - len(marker)
+	for end > 0 && !utf8.RuneStart(msg[end]) {
+		end--
+	}
+	return msg[:end] + marker
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Termination message", func() {
+	DescribeTable("truncateMessage",
+		func(msg, expected string) {
+			truncated := truncateMessage(msg, maxTerminationMessageSize)
+			Expect(truncated).To(Equal(expected))
+			Expect(len(truncated)).To(BeNumerically("<=", maxTerminationMessageSize))
+			Expect(utf8.ValidString(truncated)).To(BeTrue())
+		},
+		Entry("short message", "run success", "run success"),
+		Entry("message of exactly the maximum size",
+			strings.Repeat("a", maxTerminationMessageSize), strings.Repeat("a", maxTerminationMessageSize)),
+		Entry("message one byte too long",
+			strings.Repeat("a", maxTerminationMessageSize+1), strings.Repeat("a", maxTerminationMessageSize-3)+"..."),
+		// "é" is 2 bytes: the one starting at the cut is dropped as a whole
+		Entry("rune cut at the boundary",
+			strings.Repeat("a", maxTerminationMessageSize-4)+"ééé", strings.Repeat("a", maxTerminationMessageSize-4)+"..."),
+		Entry("rune ending at the boundary",
+			strings.Repeat("a", maxTerminationMessageSize-5)+"ééé", strings.Repeat("a", maxTerminationMessageSize-5)+"é..."),
+	)
+
+	It("runSummary reports the status, the counts, the failed CRDs and the first error", func() {
+		report := &deploy.Report{
+			Status:       deploy.RunStatusFailed,
+			BundleDigest: "sha256:abc",
+			CRDs: []deploy.CRDResult{
+				{Name: "clusterprofiles.config.projectsveltos.io", Action: deploy.ActionCreated},
+				{Name: "sveltosclusters.lib.projectsveltos.io", Action: deploy.ActionFailed, Error: "forbidden"},
+				{Name: "eventsources.lib.projectsveltos.io", Action: deploy.ActionCreated},
+			},
+			Removed: []deploy.CRDResult{{Name: "addoncompliances.lib.projectsveltos.io", Action: deploy.ActionPruned}},
+		}
+
+		Expect(runSummary(report, errors.New("1 Sveltos CRDs failed"))).To(Equal(
+			"run failed: 2 created, 1 failed, 1 pruned (bundle sha256:abc)\n" +
+				"failed CRDs: sveltosclusters.lib.projectsveltos.io\n" +
+				"error: sveltosclusters.lib.projectsveltos.io: forbidden"))
+	})
+
+	It("runSummary reports a run failing without CRD results as failed, with its error", func() {
+		Expect(runSummary(&deploy.Report{}, errors.New("failed to read bundle"))).To(Equal(
+			"run failed\nerror: failed to read bundle"))
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// applyTLSOverrides overrides, according to the TLS flags, the TLS settings of
+// restConfig, however it was built (in-cluster or from a kubeconfig)
+func applyTLSOverrides(restConfig *rest.Config) error {
+	if cli.certificateAuthority != "" {
+		extra, err := os.ReadFile(cli.certificateAuthority)
+		if err != nil {
+			return fmt.Errorf("failed to read --certificate-authority: %w", err)
+		}
+		// The additional CA is trusted on top of the ones already configured
+		current := restConfig.CAData
+		if len(current) == 0 && restConfig.CAFile != "" {
+			current, err = os.ReadFile(restConfig.CAFile)
+			if err != nil {
+				return fmt.Errorf("failed to read CA file %s: %w", restConfig.CAFile, err)
+			}
+		}
+		restConfig.CAData = append(append(current, '\n'), extra...)
+		restConfig.CAFile = ""
+	}
+
+	if cli.tlsServerName != "" {
+		restConfig.ServerName = cli.tlsServerName
+	}
+
+	if cli.insecureSkipTLSVerify {
+		setupLog.Info("WARNING: --insecure-skip-tls-verify is set: the API server certificate is NOT verified. " +
+			"Never use it outside of test environments")
+		restConfig.Insecure = true
+		// client-go refuses root certificates together with the insecure flag
+		restConfig.CAData = nil
+		restConfig.CAFile = ""
+	}
+	return nil
+}
+
+// describeTLS returns the TLS settings used to connect to the API server, so
+// that connection failures can be diagnosed
+func describeTLS(restConfig *rest.Config) string {
+	settings := []string{fmt.Sprintf("host=%s", restConfig.Host)}
+	switch {
+	case restConfig.Insecure:
+		settings = append(settings, "insecure-skip-tls-verify=true")
+	case len(restConfig.CAData) != 0:
+		settings = append(settings, "ca=inline")
+	case restConfig.CAFile != "":
+		settings = append(settings, "ca="+restConfig.CAFile)
+	default:
+		settings = append(settings, "ca=system")
+	}
+	if cli.certificateAuthority != "" {
+		settings = append(settings, "certificate-authority="+cli.certificateAuthority)
+	}
+	if restConfig.ServerName != "" {
+		settings = append(settings, "tls-server-name="+restConfig.ServerName)
+	}
+	return strings.Join(settings, " ")
+}
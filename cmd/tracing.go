@@ -0,0 +1,72 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/projectsveltos/crd-manager/pkg/tracing"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// tracingShutdownTimeout bounds how long exiting waits for pending spans
+	tracingShutdownTimeout = 5 * time.Second
+)
+
+// stopTracing flushes the spans not exported yet. It does nothing unless
+// tracing is enabled.
+var stopTracing = func() {}
+
+// initTracing enables OpenTelemetry tracing when an OTLP endpoint is
+// configured and returns, unless in controller mode where every pass is a
+// trace of its own, ctx carrying the trace context of the caller found in the
+// environment. Tracing failing to start never fails the run.
+func initTracing(ctx context.Context) context.Context {
+	if !tracing.Enabled(cli.otelEndpoint) {
+		return ctx
+	}
+
+	shutdown, err := tracing.Setup(ctx, cli.otelEndpoint)
+	if err != nil {
+		setupLog.Error(err, "failed to enable tracing, continuing without")
+		return ctx
+	}
+	setupLog.V(logs.LogInfo).Info("OpenTelemetry tracing enabled")
+
+	stopTracing = func() {
+		// ctx is cancelled already when exiting on a signal
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdown(shutdownCtx); err != nil {
+			setupLog.V(logs.LogInfo).Info(fmt.Sprintf("failed to flush traces: %v", err))
+		}
+	}
+	if cli.mode == modeController {
+		return ctx
+	}
+	return tracing.ContextFromEnvironment(ctx)
+}
+
+// exit flushes pending spans, then exits with code
+func exit(code int) {
+	stopTracing()
+	os.Exit(code)
+}
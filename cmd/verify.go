@@ -0,0 +1,145 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// runVerifyInstall checks once, without writing anything, that the bundle
+// CRDs are correctly installed, prints the offending CRDs per problem and
+// exits with the code of the most serious problem found
+func runVerifyInstall(ctx context.Context, c client.Client, opts *deploy.Options) {
+	status, err := deploy.VerifyInstall(ctx, c, opts, setupLog)
+	if err != nil {
+		fatal(err, "failed to verify the CRDs installation", exitCodeFailure)
+	}
+
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(status)
+	} else {
+		err = printInstallStatus(os.Stdout, status)
+	}
+	if err != nil {
+		fatal(err, "failed to write the CRDs installation status", exitCodeFailure)
+	}
+
+	switch {
+	case len(status.Missing) > 0:
+		writeTerminationMessage("CRDs missing: " + strings.Join(status.Missing, ", "))
+		exit(exitCodeVerifyMissing)
+	case len(status.NotEstablished) > 0:
+		writeTerminationMessage("CRDs not established: " + strings.Join(status.NotEstablished, ", "))
+		exit(exitCodeVerifyNotEstablished)
+	case len(status.Drifted) > 0:
+		writeTerminationMessage("CRDs drifted: " + strings.Join(status.Drifted, ", "))
+		exit(exitCodeVerifyDrifted)
+	}
+	writeTerminationMessage("CRDs installed")
+}
+
+// printInstallStatus writes, per problem, the CRDs affected
+func printInstallStatus(w io.Writer, status *deploy.InstallStatus) error {
+	if status.IsInstalled() {
+		_, err := fmt.Fprintln(w, "installed: all CRDs are established and match the bundle")
+		return err
+	}
+	for _, category := range []struct {
+		name string
+		crds []string
+	}{
+		{"missing", status.Missing},
+		{"not established", status.NotEstablished},
+		{"drifted", status.Drifted},
+	} {
+		if len(category.crds) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", category.name, strings.Join(category.crds, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatus is the --check classification of the bundle CRDs
+type checkStatus struct {
+	Missing  []string `json:"missing"`
+	Outdated []string `json:"outdated"`
+}
+
+// runCheckInstalled classifies once, reading the CRDs metadata only, the
+// bundle CRDs as current, outdated or missing, prints the CRDs per category
+// and exits with the code of the most serious one
+func runCheckInstalled(ctx context.Context, c client.Client, opts *deploy.Options) {
+	missing, outdated, err := deploy.CheckInstalled(ctx, c, opts, setupLog)
+	if err != nil {
+		fatal(err, "failed to check the CRDs installation", exitCodeFailure)
+	}
+
+	status := &checkStatus{Missing: missing, Outdated: outdated}
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(status)
+	} else {
+		err = printCheckStatus(os.Stdout, status)
+	}
+	if err != nil {
+		fatal(err, "failed to write the CRDs installation status", exitCodeFailure)
+	}
+
+	switch {
+	case len(missing) > 0:
+		writeTerminationMessage("CRDs missing: " + strings.Join(missing, ", "))
+		exit(exitCodeVerifyMissing)
+	case len(outdated) > 0:
+		writeTerminationMessage("CRDs outdated: " + strings.Join(outdated, ", "))
+		exit(exitCodeVerifyDrifted)
+	}
+	writeTerminationMessage("CRDs current")
+}
+
+// printCheckStatus writes, per category, the CRDs not current
+func printCheckStatus(w io.Writer, status *checkStatus) error {
+	if len(status.Missing) == 0 && len(status.Outdated) == 0 {
+		_, err := fmt.Fprintln(w, "current: all CRDs carry the bundle hash")
+		return err
+	}
+	if len(status.Missing) > 0 {
+		if _, err := fmt.Fprintf(w, "missing: %s\n", strings.Join(status.Missing, ", ")); err != nil {
+			return err
+		}
+	}
+	if len(status.Outdated) > 0 {
+		if _, err := fmt.Fprintf(w, "outdated: %s\n", strings.Join(status.Outdated, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
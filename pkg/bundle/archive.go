@@ -0,0 +1,238 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ArchiveError is returned when a bundle archive cannot be used
+type ArchiveError struct {
+	// Path of the archive
+	Path string
+
+	// Reason why the archive cannot be used
+	Reason error
+}
+
+func (e *ArchiveError) Error() string {
+	return fmt.Sprintf("invalid bundle archive %s: %v", e.Path, e.Reason)
+}
+
+func (e *ArchiveError) Unwrap() error {
+	return e.Reason
+}
+
+// FromArchive returns the bundle made of the YAML files (.yaml or .yml)
+// contained in the tar.gz archive at archivePath, concatenated in file name
+// order. The archive is extracted in memory. It is rejected if it, or the
+// files it contains, exceed maxSize bytes (DefaultMaxSize when not positive),
+// if an entry escapes the archive root or if a YAML document is not a
+// Kubernetes object.
+func FromArchive(archivePath string, maxSize int64) (*Bundle, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	archive, err := readArchive(archivePath, maxSize)
+	if err != nil {
+		return nil, &ArchiveError{Path: archivePath, Reason: err}
+	}
+
+	files, err := extractYAMLFiles(archive, maxSize)
+	if err != nil {
+		return nil, &ArchiveError{Path: archivePath, Reason: err}
+	}
+
+	content, err := joinYAMLFiles(files)
+	if err != nil {
+		return nil, &ArchiveError{Path: archivePath, Reason: err}
+	}
+
+	return &Bundle{Content: content, Source: archivePath, Type: SourceTypeArchive, ArchiveDigest: Digest(archive)}, nil
+}
+
+// readArchive returns the content of the archive, which must not exceed maxSize bytes
+func readArchive(archivePath string, maxSize int64) ([]byte, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	archive, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(archive)) > maxSize {
+		return nil, fmt.Errorf("archive exceeds maximum size of %d bytes", maxSize)
+	}
+	return archive, nil
+}
+
+// extractYAMLFiles returns, by file name, the YAML files contained in the
+// tar.gz archive. The size of every regular file, YAML or not, counts
+// toward maxSize.
+func extractYAMLFiles(archive []byte, maxSize int64) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a tar archive: %w", err)
+		}
+
+		name, err := entryName(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("entry %s is not a regular file", hdr.Name)
+		}
+
+		total += hdr.Size
+		if total > maxSize {
+			return nil, fmt.Errorf("extracted files exceed maximum size of %d bytes", maxSize)
+		}
+		if !isYAMLFile(name) {
+			continue
+		}
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("duplicate entry %s", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read entry %s: %w", hdr.Name, err)
+		}
+		files[name] = data
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no YAML file found")
+	}
+	return files, nil
+}
+
+// entryName returns the cleaned name of an archive entry, rejecting absolute
+// names and names escaping the archive root
+func entryName(name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("entry %s escapes the archive root", name)
+	}
+	return cleaned, nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// joinYAMLFiles splits the files in YAML documents, which must all be
+// Kubernetes objects, and returns them, in file name order, as a single
+// multi-document YAML
+func joinYAMLFiles(files map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var content bytes.Buffer
+	for _, name := range names {
+		docs, err := splitDocuments(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", name, err)
+		}
+		for _, doc := range docs {
+			if err := validateDocument(doc); err != nil {
+				return nil, fmt.Errorf("file %s: %w", name, err)
+			}
+			writeDocument(&content, doc)
+		}
+	}
+
+	if content.Len() == 0 {
+		return nil, errors.New("no YAML document found")
+	}
+	return content.Bytes(), nil
+}
+
+// splitDocuments returns the non empty documents of a multi-document YAML
+func splitDocuments(content []byte) ([][]byte, error) {
+	var docs [][]byte
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) != 0 {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// writeDocument appends doc, as a document of its own, to a multi-document YAML
+func writeDocument(content *bytes.Buffer, doc []byte) {
+	content.WriteString("---\n")
+	content.Write(bytes.TrimRight(doc, "\n"))
+	content.WriteString("\n")
+}
+
+// validateDocument returns an error unless doc is a Kubernetes object
+func validateDocument(doc []byte) error {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+		return fmt.Errorf("invalid YAML document: %w", err)
+	}
+	if typeMeta.APIVersion == "" || typeMeta.Kind == "" {
+		return errors.New("YAML document without apiVersion or kind")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	gadgetsCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.lib.projectsveltos.io
+`
+)
+
+type archiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+// writeArchive writes a tar.gz archive with entries and returns its path
+// and content
+func writeArchive(entries ...archiveEntry) (string, []byte) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		hdr := &tar.Header{Name: entry.name, Mode: 0o600, Typeflag: entry.typeflag}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(entry.content))
+		}
+		if hdr.Typeflag == tar.TypeSymlink {
+			hdr.Linkname = entry.content
+		}
+		Expect(tw.WriteHeader(hdr)).To(Succeed())
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(entry.content))
+			Expect(err).To(BeNil())
+		}
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+
+	path := filepath.Join(GinkgoT().TempDir(), "bundle.tar.gz")
+	Expect(os.WriteFile(path, buf.Bytes(), 0o600)).To(Succeed())
+	return path, buf.Bytes()
+}
+
+func expectArchiveError(path string, maxSize int64, reason string) {
+	_, err := bundle.FromArchive(path, maxSize)
+	Expect(err).ToNot(BeNil())
+	var archiveErr *bundle.ArchiveError
+	Expect(errors.As(err, &archiveErr)).To(BeTrue())
+	Expect(archiveErr.Path).To(Equal(path))
+	Expect(err.Error()).To(ContainSubstring(reason))
+}
+
+var _ = Describe("Archive", func() {
+	It("concatenates the YAML files in file name order", func() {
+		path, archive := writeArchive(
+			archiveEntry{name: "crds/", typeflag: tar.TypeDir},
+			archiveEntry{name: "crds/b.yml", content: gadgetsCRD},
+			archiveEntry{name: "README.md", content: "not a manifest"},
+			archiveEntry{name: "./crds/a.yaml", content: remoteBundle + "---\n\n---\n" + remoteBundle},
+		)
+
+		b, err := bundle.FromArchive(path, 0)
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal("---\n" + remoteBundle + "---\n" + remoteBundle + "---\n" + gadgetsCRD))
+		Expect(b.Source).To(Equal(path))
+		Expect(b.ArchiveDigest).To(Equal(bundle.Digest(archive)))
+		Expect(b.IsEmbedded()).To(BeFalse())
+		Expect(b.SignatureVerified).To(BeFalse())
+	})
+
+	It("rejects entries escaping the archive root", func() {
+		path, _ := writeArchive(archiveEntry{name: "crds/../../etc/crd.yaml", content: remoteBundle})
+		expectArchiveError(path, 0, "escapes the archive root")
+
+		path, _ = writeArchive(archiveEntry{name: "/etc/crd.yaml", content: remoteBundle})
+		expectArchiveError(path, 0, "escapes the archive root")
+	})
+
+	It("rejects links", func() {
+		path, _ := writeArchive(
+			archiveEntry{name: "crd.yaml", content: remoteBundle},
+			archiveEntry{name: "link.yaml", content: "/etc/passwd", typeflag: tar.TypeSymlink},
+		)
+		expectArchiveError(path, 0, "is not a regular file")
+	})
+
+	It("rejects archives whose files exceed the maximum size", func() {
+		path, archive := writeArchive(
+			archiveEntry{name: "crd.yaml", content: remoteBundle},
+			archiveEntry{name: "padding.txt", content: string(bytes.Repeat([]byte{'a'}, 4096))},
+		)
+		Expect(int64(len(archive))).To(BeNumerically("<", 1024))
+		expectArchiveError(path, 1024, "extracted files exceed maximum size")
+	})
+
+	It("rejects archives exceeding the maximum size", func() {
+		path, archive := writeArchive(archiveEntry{name: "crd.yaml", content: remoteBundle})
+		expectArchiveError(path, int64(len(archive))-1, "archive exceeds maximum size")
+	})
+
+	It("rejects malformed archives", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "bundle.tar.gz")
+		Expect(os.WriteFile(path, []byte(remoteBundle), 0o600)).To(Succeed())
+		expectArchiveError(path, 0, "not a gzip archive")
+
+		path, _ = writeArchive(archiveEntry{name: "crd.yaml", content: "kind: [unterminated"})
+		expectArchiveError(path, 0, "file crd.yaml")
+
+		path, _ = writeArchive(archiveEntry{name: "crd.yaml", content: "metadata:\n  name: foo\n"})
+		expectArchiveError(path, 0, "without apiVersion or kind")
+
+		path, _ = writeArchive(archiveEntry{name: "README.md", content: remoteBundle})
+		expectArchiveError(path, 0, "no YAML file found")
+
+		expectArchiveError(filepath.Join(GinkgoT().TempDir(), "missing.tar.gz"), 0, "no such file")
+	})
+})
@@ -0,0 +1,209 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle loads the CRD bundle crd-manager deploys. By default the bundle
+// embedded in the binary is used. Other sources can replace it.
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	sveltoscrds "github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+const (
+	// EmbeddedSource is the Source of the bundle embedded in the binary
+	EmbeddedSource = "embedded"
+
+	// UnknownVersion is the Version of bundles whose Sveltos version is not known
+	UnknownVersion = "unknown"
+
+	digestPrefix = "sha256:"
+
+	customResourceDefinitionKind = "CustomResourceDefinition"
+
+	// libsveltosModule is the module the embedded CRDs are generated from
+	libsveltosModule = "github.com/projectsveltos/libsveltos"
+)
+
+// SourceType is the type of a bundle source
+type SourceType string
+
+const (
+	// SourceTypeEmbedded is the type of the bundle embedded in the binary
+	SourceTypeEmbedded = SourceType("embedded")
+
+	// SourceTypeArchive is the type of bundles extracted from an archive
+	SourceTypeArchive = SourceType("archive")
+
+	// SourceTypeURL is the type of bundles fetched from a URL
+	SourceTypeURL = SourceType("url")
+
+	// SourceTypeFile is the type of bundles read from a local YAML file
+	SourceTypeFile = SourceType("file")
+
+	// SourceTypeDirectory is the type of bundles read from the YAML files of
+	// a local directory
+	SourceTypeDirectory = SourceType("directory")
+)
+
+// Provenance identifies the bundle source a CRD is taken from
+type Provenance struct {
+	// Type is the type of the source
+	Type SourceType `json:"type,omitempty"`
+
+	// Location is the path or the URL of the source. It is not set for the
+	// embedded bundle.
+	Location string `json:"location,omitempty"`
+
+	// Digest is the sha256 digest of the archive or, for the other sources,
+	// of the bundle content. It is not recorded on the CRDs, for a new
+	// version of a source alone not to update every CRD taken from it.
+	Digest string `json:"digest,omitempty"`
+}
+
+func (p *Provenance) String() string {
+	if p.Location == "" {
+		return string(p.Type)
+	}
+	return fmt.Sprintf("%s %s", p.Type, p.Location)
+}
+
+// Bundle is a multi-document YAML containing CRDs
+type Bundle struct {
+	// Content is the multi-document YAML
+	Content []byte
+
+	// Source identifies where Content comes from
+	Source string
+
+	// Type is the type of the source. Merged bundles have none.
+	Type SourceType
+
+	// SignatureVerified is true if the signature of Content has been verified
+	SignatureVerified bool
+
+	// ArchiveDigest is the sha256 digest of the archive Content was extracted
+	// from, if any
+	ArchiveDigest string
+
+	// Origins is, for a bundle merged from several sources, the source each
+	// CRD is taken from
+	Origins map[string]string
+
+	// provenances is, for a bundle merged from several sources, the
+	// provenance of each CRD
+	provenances map[string]Provenance
+}
+
+// Embedded returns the bundle embedded in the binary
+func Embedded() *Bundle {
+	return &Bundle{
+		Content: sveltoscrds.GetSveltosCRDYAML(),
+		Source:  EmbeddedSource,
+		Type:    SourceTypeEmbedded,
+	}
+}
+
+// IsEmbedded returns true if b is the bundle embedded in the binary
+func (b *Bundle) IsEmbedded() bool {
+	return b.Source == EmbeddedSource
+}
+
+// SourceOf returns the source the CRD named name is taken from
+func (b *Bundle) SourceOf(name string) string {
+	if source, ok := b.Origins[name]; ok {
+		return source
+	}
+	return b.Source
+}
+
+// ProvenanceOf returns the provenance of the CRD named name
+func (b *Bundle) ProvenanceOf(name string) Provenance {
+	if provenance, ok := b.provenances[name]; ok {
+		return provenance
+	}
+	return b.Provenance()
+}
+
+// Provenance returns the provenance of the CRDs of a single source bundle
+func (b *Bundle) Provenance() Provenance {
+	if b.IsEmbedded() {
+		return Provenance{Type: SourceTypeEmbedded, Digest: b.Digest()}
+	}
+	provenance := Provenance{Type: b.Type, Location: b.Source, Digest: b.ArchiveDigest}
+	if provenance.Digest == "" {
+		provenance.Digest = b.Digest()
+	}
+	return provenance
+}
+
+// Version returns the Sveltos version the bundle CRDs come from. Only the
+// version of the embedded bundle, the one of the libsveltos module it is
+// generated from, is known.
+func (b *Bundle) Version() string {
+	if !b.IsEmbedded() {
+		return UnknownVersion
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return UnknownVersion
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == libsveltosModule {
+			return dep.Version
+		}
+	}
+	return UnknownVersion
+}
+
+// Digest returns the sha256 digest, in the sha256:<hex> format, of the bundle content
+func (b *Bundle) Digest() string {
+	return Digest(b.Content)
+}
+
+// Digest returns the sha256 digest, in the sha256:<hex> format, of content
+func Digest(content []byte) string {
+	h := sha256.Sum256(content)
+	return digestPrefix + hex.EncodeToString(h[:])
+}
+
+// NormalizeDigest returns digest, which can be either hex encoded or in the
+// sha256:<hex> format, in the sha256:<hex> format
+func NormalizeDigest(digest string) (string, error) {
+	value := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), digestPrefix))
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 digest %q", digest)
+	}
+	return digestPrefix + value, nil
+}
+
+// VerifyDigest returns an error if the sha256 digest of content is not expected
+func VerifyDigest(content []byte, expected string) error {
+	normalized, err := NormalizeDigest(expected)
+	if err != nil {
+		return err
+	}
+	if actual := Digest(content); actual != normalized {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", normalized, actual)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultAllowedGroups are the API groups the CRDs of sources other than the
+// embedded bundle may belong to by default
+var DefaultAllowedGroups = []string{"*.projectsveltos.io"}
+
+// GroupViolation is a CRD whose API group is not allowed
+type GroupViolation struct {
+	// Source is the bundle source defining the CRD
+	Source string
+
+	// CRD is the name of the CRD
+	CRD string
+
+	// Group is the API group of the CRD
+	Group string
+}
+
+// GroupPolicyError is returned by CheckGroups when some CRDs belong to API
+// groups not allowed
+type GroupPolicyError struct {
+	// Allowed are the allowed API group patterns
+	Allowed []string
+
+	// Violations are the CRDs not allowed, in bundle order
+	Violations []GroupViolation
+}
+
+func (e *GroupPolicyError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i := range e.Violations {
+		violations[i] = fmt.Sprintf("bundle %s: CRD %s has group %q", e.Violations[i].Source, e.Violations[i].CRD,
+			e.Violations[i].Group)
+	}
+	return fmt.Sprintf("CRDs outside the allowed groups (%s): %s", strings.Join(e.Allowed, ", "),
+		strings.Join(violations, "; "))
+}
+
+// ValidateGroupPatterns returns an error if a pattern is not a valid
+// path.Match pattern, such as "*.projectsveltos.io"
+func ValidateGroupPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid API group pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CheckGroups returns a *GroupPolicyError listing the CRDs of b whose API
+// group matches none of the allowed patterns. The embedded bundle is exempt:
+// only the CRDs of other sources, which can bring any CRD in, are checked.
+func CheckGroups(b *Bundle, allowed []string) error {
+	if b.IsEmbedded() {
+		return nil
+	}
+
+	docs, err := splitDocuments(b.Content)
+	if err != nil {
+		return fmt.Errorf("bundle %s: %w", b.Source, err)
+	}
+	var violations []GroupViolation
+	for _, doc := range docs {
+		var obj struct {
+			metav1.TypeMeta   `json:",inline"`
+			metav1.ObjectMeta `json:"metadata,omitempty"`
+			Spec              struct {
+				Group string `json:"group"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return fmt.Errorf("bundle %s: invalid YAML document: %w", b.Source, err)
+		}
+		if obj.Kind != customResourceDefinitionKind || groupAllowed(obj.Spec.Group, allowed) {
+			continue
+		}
+		violations = append(violations, GroupViolation{Source: b.Source, CRD: obj.Name, Group: obj.Spec.Group})
+	}
+	if len(violations) > 0 {
+		return &GroupPolicyError{Allowed: allowed, Violations: violations}
+	}
+	return nil
+}
+
+// groupAllowed returns true if group matches one of the allowed patterns
+func groupAllowed(group string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if matched, _ := path.Match(pattern, group); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	sveltosGroupCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+`
+	foreignGroupCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: miners.crypto.example.com
+spec:
+  group: crypto.example.com
+`
+	sveltosConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`
+)
+
+var _ = Describe("CheckGroups", func() {
+	It("allows the CRDs of the allowed groups", func() {
+		b := &bundle.Bundle{Content: []byte(sveltosGroupCRD + "---\n" + sveltosConfigMap), Source: "archive.tgz"}
+		Expect(bundle.CheckGroups(b, bundle.DefaultAllowedGroups)).To(Succeed())
+	})
+
+	It("rejects, naming the source and group, the CRDs of other groups", func() {
+		b := &bundle.Bundle{Content: []byte(sveltosGroupCRD + "---\n" + foreignGroupCRD), Source: "https://example.com/b.yaml"}
+		err := bundle.CheckGroups(b, bundle.DefaultAllowedGroups)
+
+		var policyErr *bundle.GroupPolicyError
+		Expect(errors.As(err, &policyErr)).To(BeTrue())
+		Expect(policyErr.Violations).To(ConsistOf(bundle.GroupViolation{
+			Source: "https://example.com/b.yaml", CRD: "miners.crypto.example.com", Group: "crypto.example.com",
+		}))
+		Expect(err).To(MatchError(ContainSubstring(`bundle https://example.com/b.yaml: CRD miners.crypto.example.com has group "crypto.example.com"`)))
+
+		Expect(bundle.CheckGroups(b, []string{"*.projectsveltos.io", "*.example.com"})).To(Succeed())
+	})
+
+	It("exempts the embedded bundle", func() {
+		Expect(bundle.CheckGroups(bundle.Embedded(), []string{"none.example.com"})).To(Succeed())
+	})
+
+	It("validates the group patterns", func() {
+		Expect(bundle.ValidateGroupPatterns([]string{"*.projectsveltos.io", "example.com"})).To(Succeed())
+		Expect(bundle.ValidateGroupPatterns([]string{"[.example.com"})).To(MatchError(ContainSubstring("invalid API group pattern")))
+	})
+})
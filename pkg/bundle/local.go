@@ -0,0 +1,161 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalError is returned when a local bundle file or directory cannot be used
+type LocalError struct {
+	// Path of the file or directory
+	Path string
+
+	// Reason why the file or directory cannot be used
+	Reason error
+}
+
+func (e *LocalError) Error() string {
+	return fmt.Sprintf("invalid bundle source %s: %v", e.Path, e.Reason)
+}
+
+func (e *LocalError) Unwrap() error {
+	return e.Reason
+}
+
+// FromFile returns the bundle contained in the multi-document YAML file at
+// filePath. It is rejected if it exceeds maxSize bytes (DefaultMaxSize when
+// not positive) or if a YAML document is not a Kubernetes object.
+func FromFile(filePath string, maxSize int64) (*Bundle, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	data, err := readFile(filePath, maxSize)
+	if err != nil {
+		return nil, &LocalError{Path: filePath, Reason: err}
+	}
+
+	content, err := joinYAMLFiles(map[string][]byte{filepath.Base(filePath): data})
+	if err != nil {
+		return nil, &LocalError{Path: filePath, Reason: err}
+	}
+	return &Bundle{Content: content, Source: filePath, Type: SourceTypeFile}, nil
+}
+
+// FromDirectory returns the bundle made of the YAML files (.yaml or .yml)
+// found in dir and its subdirectories, concatenated in path order. Hidden
+// files and directories, such as the ..data ones of a mounted ConfigMap, are
+// skipped. It is rejected if the YAML files exceed, in total, maxSize bytes
+// (DefaultMaxSize when not positive) or if a YAML document is not a
+// Kubernetes object.
+func FromDirectory(dir string, maxSize int64) (*Bundle, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	files, err := readYAMLFiles(dir, maxSize)
+	if err != nil {
+		return nil, &LocalError{Path: dir, Reason: err}
+	}
+
+	content, err := joinYAMLFiles(files)
+	if err != nil {
+		return nil, &LocalError{Path: dir, Reason: err}
+	}
+	return &Bundle{Content: content, Source: dir, Type: SourceTypeDirectory}, nil
+}
+
+// readYAMLFiles returns, by slash separated path relative to dir, the YAML
+// files found in dir, which must not exceed maxSize bytes in total
+func readYAMLFiles(dir string, maxSize int64) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	remaining := maxSize
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isYAMLFile(d.Name()) {
+			return nil
+		}
+
+		// Symbolic links, such as the files of a mounted ConfigMap, are followed
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", filePath)
+		}
+		data, err := readFile(filePath, remaining)
+		if err != nil {
+			if errors.Is(err, errTooLarge) {
+				return fmt.Errorf("YAML files exceed maximum size of %d bytes", maxSize)
+			}
+			return err
+		}
+		remaining -= int64(len(data))
+
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no YAML file found")
+	}
+	return files, nil
+}
+
+// errTooLarge is returned by readFile for files exceeding the maximum size
+var errTooLarge = errors.New("exceeds maximum size")
+
+// readFile returns the content of the file, which must not exceed maxSize bytes
+func readFile(filePath string, maxSize int64) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w of %d bytes", errTooLarge, maxSize)
+	}
+	return data, nil
+}
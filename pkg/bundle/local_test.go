@@ -0,0 +1,112 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+// writeFiles writes, under a new directory whose path is returned, files
+// by relative path
+func writeFiles(files map[string]string) string {
+	dir := GinkgoT().TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(filePath), 0o700)).To(Succeed())
+		Expect(os.WriteFile(filePath, []byte(content), 0o600)).To(Succeed())
+	}
+	return dir
+}
+
+func expectLocalError(err error, path, reason string) {
+	Expect(err).ToNot(BeNil())
+	var localErr *bundle.LocalError
+	Expect(errors.As(err, &localErr)).To(BeTrue())
+	Expect(localErr.Path).To(Equal(path))
+	Expect(err.Error()).To(ContainSubstring(reason))
+}
+
+var _ = Describe("Local sources", func() {
+	It("reads a bundle from a YAML file", func() {
+		filePath := filepath.Join(writeFiles(map[string]string{"bundle.yaml": remoteBundle + "---\n" + gadgetsCRD}),
+			"bundle.yaml")
+
+		b, err := bundle.FromFile(filePath, 0)
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal("---\n" + remoteBundle + "---\n" + gadgetsCRD))
+		Expect(b.Source).To(Equal(filePath))
+		Expect(b.Type).To(Equal(bundle.SourceTypeFile))
+		Expect(b.Provenance()).To(Equal(bundle.Provenance{Type: bundle.SourceTypeFile, Location: filePath,
+			Digest: b.Digest()}))
+	})
+
+	It("rejects files exceeding the maximum size or not made of Kubernetes objects", func() {
+		dir := writeFiles(map[string]string{"bundle.yaml": remoteBundle, "invalid.yaml": "metadata:\n  name: foo\n"})
+
+		_, err := bundle.FromFile(filepath.Join(dir, "bundle.yaml"), 10)
+		expectLocalError(err, filepath.Join(dir, "bundle.yaml"), "exceeds maximum size of 10 bytes")
+
+		_, err = bundle.FromFile(filepath.Join(dir, "invalid.yaml"), 0)
+		expectLocalError(err, filepath.Join(dir, "invalid.yaml"), "without apiVersion or kind")
+
+		_, err = bundle.FromFile(filepath.Join(dir, "missing.yaml"), 0)
+		expectLocalError(err, filepath.Join(dir, "missing.yaml"), "no such file")
+	})
+
+	It("concatenates the YAML files of a directory in path order", func() {
+		dir := writeFiles(map[string]string{
+			"b.yml":             gadgetsCRD,
+			"a/widgets.yaml":    remoteBundle,
+			"README.md":         "not a manifest",
+			".hidden/crd.yaml":  updatedWidgetsCRD,
+			".ignored-crd.yaml": updatedWidgetsCRD,
+		})
+
+		b, err := bundle.FromDirectory(dir, 0)
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal("---\n" + remoteBundle + "---\n" + gadgetsCRD))
+		Expect(b.Source).To(Equal(dir))
+		Expect(b.Type).To(Equal(bundle.SourceTypeDirectory))
+	})
+
+	It("reads the files of a mounted ConfigMap once", func() {
+		dir := writeFiles(map[string]string{"..2026_10_14_00_00_00.000000000/crds.yaml": remoteBundle})
+		Expect(os.Symlink("..2026_10_14_00_00_00.000000000", filepath.Join(dir, "..data"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join("..data", "crds.yaml"), filepath.Join(dir, "crds.yaml"))).To(Succeed())
+
+		b, err := bundle.FromDirectory(dir, 0)
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal("---\n" + remoteBundle))
+	})
+
+	It("rejects directories without YAML files or whose files exceed the maximum size", func() {
+		dir := writeFiles(map[string]string{"README.md": "not a manifest"})
+		_, err := bundle.FromDirectory(dir, 0)
+		expectLocalError(err, dir, "no YAML file found")
+
+		dir = writeFiles(map[string]string{"a.yaml": remoteBundle, "b.yaml": remoteBundle})
+		_, err = bundle.FromDirectory(dir, int64(len(remoteBundle))+1)
+		expectLocalError(err, dir, "YAML files exceed maximum size")
+	})
+})
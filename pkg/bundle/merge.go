@@ -0,0 +1,160 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// sourceSeparator separates the sources of a merged bundle in its Source
+	sourceSeparator = ", "
+)
+
+// Override records that a source replaced an object of a lower precedence source
+type Override struct {
+	// Kind of the object
+	Kind string
+
+	// Name of the object
+	Name string
+
+	// Replaced is the source the object is no longer taken from
+	Replaced string
+
+	// By is the source the object is now taken from
+	By string
+}
+
+func (o *Override) String() string {
+	return fmt.Sprintf("%s %s from %s overrides the one from %s", o.Kind, o.Name, o.By, o.Replaced)
+}
+
+// ConflictError is returned when, in strict mode, several sources define the
+// same object
+type ConflictError struct {
+	Overrides []Override
+}
+
+func (e *ConflictError) Error() string {
+	conflicts := make([]string, len(e.Overrides))
+	for i := range e.Overrides {
+		conflicts[i] = fmt.Sprintf("%s %s is defined by both %s and %s",
+			e.Overrides[i].Kind, e.Overrides[i].Name, e.Overrides[i].Replaced, e.Overrides[i].By)
+	}
+	return "conflicting bundle sources: " + strings.Join(conflicts, "; ")
+}
+
+// mergedObject is an object of a merged bundle
+type mergedObject struct {
+	kind       string
+	name       string
+	doc        []byte
+	source     string
+	provenance Provenance
+}
+
+// Merge returns the bundle made of the objects of sources, given by increasing
+// precedence: an object defined by several sources, identified by its kind
+// and name, is taken from the last one, at the position it has in the first
+// one. The overrides are returned. With strict, any override is an error.
+// A single source is returned as is.
+func Merge(sources []*Bundle, strict bool) (*Bundle, []Override, error) {
+	if len(sources) == 1 {
+		return sources[0], nil, nil
+	}
+
+	var objects []*mergedObject
+	index := make(map[string]*mergedObject)
+	var overrides []Override
+	for _, source := range sources {
+		docs, err := splitDocuments(source.Content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bundle %s: %w", source.Source, err)
+		}
+		provenance := source.Provenance()
+		for _, doc := range docs {
+			obj, err := newMergedObject(doc, source.Source)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bundle %s: %w", source.Source, err)
+			}
+			obj.provenance = provenance
+			key := obj.kind + "/" + obj.name
+			previous, ok := index[key]
+			if !ok {
+				index[key] = obj
+				objects = append(objects, obj)
+				continue
+			}
+			overrides = append(overrides, Override{Kind: obj.kind, Name: obj.name, Replaced: previous.source, By: obj.source})
+			*previous = *obj
+		}
+	}
+	if strict && len(overrides) > 0 {
+		return nil, overrides, &ConflictError{Overrides: overrides}
+	}
+
+	return newMergedBundle(sources, objects), overrides, nil
+}
+
+func newMergedObject(doc []byte, source string) (*mergedObject, error) {
+	var obj struct {
+		metav1.TypeMeta   `json:",inline"`
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := yaml.Unmarshal(doc, &obj); err != nil {
+		return nil, fmt.Errorf("invalid YAML document: %w", err)
+	}
+	if obj.Kind == "" || obj.Name == "" {
+		return nil, fmt.Errorf("YAML document without kind or name")
+	}
+	return &mergedObject{kind: obj.Kind, name: obj.Name, doc: doc, source: source}, nil
+}
+
+// newMergedBundle returns the bundle made of objects. Its signature is verified
+// only if the signatures of all its sources not exempt from verification are.
+func newMergedBundle(sources []*Bundle, objects []*mergedObject) *Bundle {
+	b := &Bundle{SignatureVerified: true, Origins: make(map[string]string), provenances: make(map[string]Provenance)}
+
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.Source
+		if !source.IsEmbedded() && !source.SignatureVerified {
+			b.SignatureVerified = false
+		}
+		if source.ArchiveDigest != "" {
+			b.ArchiveDigest = source.ArchiveDigest
+		}
+	}
+	b.Source = strings.Join(names, sourceSeparator)
+
+	var content bytes.Buffer
+	for _, obj := range objects {
+		writeDocument(&content, obj.doc)
+		if obj.kind == customResourceDefinitionKind {
+			b.Origins[obj.name] = obj.source
+			b.provenances[obj.name] = obj.provenance
+		}
+	}
+	b.Content = content.Bytes()
+	return b
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	updatedWidgetsCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+  labels:
+    updated: "true"
+`
+)
+
+var _ = Describe("Merge", func() {
+	var base, override *bundle.Bundle
+
+	BeforeEach(func() {
+		base = &bundle.Bundle{Content: []byte(remoteBundle + "---\n" + gadgetsCRD), Source: "base"}
+		override = &bundle.Bundle{Content: []byte(updatedWidgetsCRD), Source: "override", SignatureVerified: true}
+	})
+
+	It("returns a single source as is", func() {
+		b, overrides, err := bundle.Merge([]*bundle.Bundle{base}, true)
+		Expect(err).To(BeNil())
+		Expect(overrides).To(BeEmpty())
+		Expect(b).To(BeIdenticalTo(base))
+		Expect(b.SourceOf("widgets.lib.projectsveltos.io")).To(Equal("base"))
+	})
+
+	It("takes objects from the highest precedence source, keeping their position", func() {
+		b, overrides, err := bundle.Merge([]*bundle.Bundle{base, override}, false)
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal("---\n" + updatedWidgetsCRD + "---\n" + gadgetsCRD))
+		Expect(b.Source).To(Equal("base, override"))
+		Expect(b.SourceOf("widgets.lib.projectsveltos.io")).To(Equal("override"))
+		Expect(b.SourceOf("gadgets.lib.projectsveltos.io")).To(Equal("base"))
+		Expect(overrides).To(ConsistOf(bundle.Override{
+			Kind: "CustomResourceDefinition", Name: "widgets.lib.projectsveltos.io", Replaced: "base", By: "override",
+		}))
+		Expect(overrides[0].String()).To(ContainSubstring("from override overrides the one from base"))
+	})
+
+	It("is verified only if all sources not exempt from verification are", func() {
+		b, _, err := bundle.Merge([]*bundle.Bundle{bundle.Embedded(), override}, false)
+		Expect(err).To(BeNil())
+		Expect(b.SignatureVerified).To(BeTrue())
+		Expect(b.IsEmbedded()).To(BeFalse())
+
+		b, _, err = bundle.Merge([]*bundle.Bundle{bundle.Embedded(), base, override}, false)
+		Expect(err).To(BeNil())
+		Expect(b.SignatureVerified).To(BeFalse())
+	})
+
+	It("carries the archive digest", func() {
+		base.ArchiveDigest = bundle.Digest([]byte("archive"))
+		b, _, err := bundle.Merge([]*bundle.Bundle{base, override}, false)
+		Expect(err).To(BeNil())
+		Expect(b.ArchiveDigest).To(Equal(base.ArchiveDigest))
+	})
+
+	It("records the provenance of every CRD", func() {
+		base.Type = bundle.SourceTypeArchive
+		base.ArchiveDigest = bundle.Digest([]byte("archive"))
+		override.Type = bundle.SourceTypeURL
+		b, _, err := bundle.Merge([]*bundle.Bundle{base, override}, false)
+		Expect(err).To(BeNil())
+		Expect(b.ProvenanceOf("gadgets.lib.projectsveltos.io")).To(Equal(bundle.Provenance{
+			Type: bundle.SourceTypeArchive, Location: "base", Digest: base.ArchiveDigest}))
+		Expect(b.ProvenanceOf("widgets.lib.projectsveltos.io")).To(Equal(bundle.Provenance{
+			Type: bundle.SourceTypeURL, Location: "override", Digest: override.Digest()}))
+
+		embedded := bundle.Embedded()
+		Expect(embedded.ProvenanceOf("widgets.lib.projectsveltos.io")).To(Equal(bundle.Provenance{
+			Type: bundle.SourceTypeEmbedded, Digest: embedded.Digest()}))
+	})
+
+	It("fails on overrides in strict mode", func() {
+		_, overrides, err := bundle.Merge([]*bundle.Bundle{base, override}, true)
+		Expect(err).ToNot(BeNil())
+		Expect(overrides).To(HaveLen(1))
+		var conflictErr *bundle.ConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("widgets.lib.projectsveltos.io is defined by both base and override"))
+
+		_, _, err = bundle.Merge([]*bundle.Bundle{base, {Content: []byte(gadgetsCRD + "---\n"), Source: "other"}}, true)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects documents without kind or name", func() {
+		invalid := &bundle.Bundle{Content: []byte("kind: ConfigMap\n"), Source: "invalid"}
+		_, _, err := bundle.Merge([]*bundle.Bundle{base, invalid}, false)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("bundle invalid"))
+	})
+})
@@ -0,0 +1,384 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// DefaultFetchTimeout is the default timeout for fetching a remote bundle
+	DefaultFetchTimeout = 30 * time.Second
+
+	// DefaultConnectTimeout is the default timeout for establishing the
+	// connection, TLS handshake included, to the server or to the proxy
+	DefaultConnectTimeout = 10 * time.Second
+
+	// DefaultReadTimeout is the default time waited for the response headers
+	// once the request is sent
+	DefaultReadTimeout = 15 * time.Second
+
+	// DefaultMaxSize is the default maximum size, in bytes, of a remote bundle
+	DefaultMaxSize = 32 * 1024 * 1024
+
+	// signatureMaxSize is the maximum size, in bytes, of a detached signature
+	signatureMaxSize = 64 * 1024
+
+	// signatureSuffix is appended to the bundle URL to get its detached signature
+	signatureSuffix = ".sig"
+)
+
+// URLOptions configures how a bundle is fetched from an HTTPS URL
+type URLOptions struct {
+	// URL of the bundle. Only https is supported.
+	URL string
+
+	// SHA256 is the expected digest of the bundle. It is mandatory.
+	SHA256 string
+
+	// CAFile is an optional PEM file with the CAs trusted, in addition to the
+	// system ones, to verify the server certificate
+	CAFile string
+
+	// CertFile and KeyFile are an optional PEM encoded client certificate and
+	// key presented to servers requiring mutual TLS. Both or none must be set.
+	CertFile string
+	KeyFile  string
+
+	// Proxy is an optional URL (http, https or socks5) of the proxy used in place
+	// of HTTPS_PROXY. It may carry credentials. NO_PROXY is honored either way.
+	Proxy string
+
+	// Timeout for the whole download. Defaults to DefaultFetchTimeout
+	Timeout time.Duration
+
+	// ConnectTimeout bounds the connection, TLS handshake included, to the server
+	// or to the proxy. Defaults to DefaultConnectTimeout
+	ConnectTimeout time.Duration
+
+	// ReadTimeout bounds the wait for the response headers once the request is
+	// sent. Defaults to DefaultReadTimeout
+	ReadTimeout time.Duration
+
+	// MaxSize is the maximum size, in bytes, of the bundle. Defaults to DefaultMaxSize
+	MaxSize int64
+
+	// Verifier, when set, verifies the bundle against the detached signature
+	// found at URL + ".sig". Bundles failing verification are never returned.
+	Verifier *Verifier
+}
+
+// FromURL downloads a bundle from an HTTPS URL and verifies its digest.
+// Content whose digest does not match is never returned.
+func FromURL(ctx context.Context, opts *URLOptions) (*Bundle, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid bundle URL %s: only https is supported", opts.URL)
+	}
+	if opts.SHA256 == "" {
+		return nil, errors.New("a sha256 digest is required to fetch a bundle from an URL")
+	}
+	if _, err := NormalizeDigest(opts.SHA256); err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	content, err := fetch(ctx, httpClient, opts.URL, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyDigest(content, opts.SHA256); err != nil {
+		return nil, fmt.Errorf("bundle from %s: %w", opts.URL, err)
+	}
+
+	b := &Bundle{Content: content, Source: opts.URL, Type: SourceTypeURL}
+	if opts.Verifier != nil {
+		if err := verifyDetachedSignature(ctx, httpClient, b, opts.Verifier); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// verifyDetachedSignature fetches the detached signature published next to the
+// bundle and verifies it
+func verifyDetachedSignature(ctx context.Context, httpClient *http.Client, b *Bundle, verifier *Verifier) error {
+	signature, err := fetch(ctx, httpClient, b.Source+signatureSuffix, signatureMaxSize)
+	if err != nil {
+		return &VerificationError{Source: b.Source, Reason: err}
+	}
+	if err := verifier.Verify(b.Content, signature); err != nil {
+		return &VerificationError{Source: b.Source, Reason: err}
+	}
+	b.SignatureVerified = true
+	return nil
+}
+
+// fetch returns the content found at rawURL, which must not exceed maxSize bytes
+func fetch(ctx context.Context, httpClient *http.Client, rawURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &FetchError{URL: rawURL, Reason: fetchErrorReason(err), Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &FetchError{URL: rawURL, Reason: FetchErrorHTTP, StatusCode: resp.StatusCode,
+			Err: errors.New(resp.Status)}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%s exceeds maximum size of %d bytes", rawURL, maxSize)
+	}
+	return content, nil
+}
+
+func newHTTPClient(opts *URLOptions) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout := durationOrDefault(opts.ConnectTimeout, DefaultConnectTimeout)
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	transport.OnProxyConnectResponse = checkProxyConnectResponse
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = durationOrDefault(opts.ReadTimeout, DefaultReadTimeout)
+
+	return &http.Client{Timeout: durationOrDefault(opts.Timeout, DefaultFetchTimeout), Transport: transport}, nil
+}
+
+func newTLSConfig(opts *URLOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("a client certificate requires both a certificate and a key file")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// proxyFunc returns the proxy selection of the environment (HTTPS_PROXY,
+// NO_PROXY), with override, when set, replacing HTTPS_PROXY
+func proxyFunc(override string) (func(*http.Request) (*url.URL, error), error) {
+	config := httpproxy.FromEnvironment()
+	if override != "" {
+		u, err := url.Parse(override)
+		if err != nil {
+			return nil, errors.New("invalid proxy URL")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("invalid proxy URL %s: scheme must be http, https or socks5", u.Redacted())
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %s: missing host", u.Redacted())
+		}
+		config.HTTPSProxy = override
+	}
+
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// checkProxyConnectResponse turns a proxy refusing to open the tunnel into an
+// error identifying the proxy. The transport only reports the status text.
+func checkProxyConnectResponse(_ context.Context, proxyURL *url.URL, _ *http.Request, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	return &proxyConnectError{proxy: proxyURL.Redacted(), status: resp.Status}
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// FetchErrorReason classifies why a remote bundle could not be fetched
+type FetchErrorReason string
+
+const (
+	// FetchErrorProxy is a failure to reach the proxy, or the proxy refusing to
+	// open the tunnel to the server
+	FetchErrorProxy = FetchErrorReason("proxy")
+
+	// FetchErrorTLS is a TLS failure: the server certificate is not trusted
+	// or does not match the host, or the server rejected the client certificate
+	FetchErrorTLS = FetchErrorReason("tls")
+
+	// FetchErrorHTTP is the server answering with a status other than 200
+	FetchErrorHTTP = FetchErrorReason("http")
+
+	// FetchErrorTimeout is the connection, read or whole download timing out
+	FetchErrorTimeout = FetchErrorReason("timeout")
+
+	// FetchErrorConnection is any other failure reaching the server
+	FetchErrorConnection = FetchErrorReason("connection")
+)
+
+// FetchError is returned when a remote bundle, or its signature, cannot be fetched
+type FetchError struct {
+	// URL fetched
+	URL string
+
+	// Reason classifies the failure
+	Reason FetchErrorReason
+
+	// StatusCode is the HTTP status returned by the server, for FetchErrorHTTP
+	StatusCode int
+
+	// Err is the underlying error
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	switch e.Reason {
+	case FetchErrorProxy:
+		return fmt.Sprintf("failed to fetch %s: proxy error: %v", e.URL, e.Err)
+	case FetchErrorTLS:
+		return fmt.Sprintf("failed to fetch %s: TLS error (untrusted server certificate or rejected client certificate): %v",
+			e.URL, e.Err)
+	case FetchErrorHTTP:
+		return fmt.Sprintf("failed to fetch %s: server returned %v", e.URL, e.Err)
+	case FetchErrorTimeout:
+		return fmt.Sprintf("failed to fetch %s: timed out: %v", e.URL, e.Err)
+	default:
+		return fmt.Sprintf("failed to fetch %s: %v", e.URL, e.Err)
+	}
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// proxyConnectError is a proxy answering the CONNECT request with a status
+// other than 200
+type proxyConnectError struct {
+	proxy  string
+	status string
+}
+
+func (e *proxyConnectError) Error() string {
+	return fmt.Sprintf("proxy %s refused to connect: %s", e.proxy, e.status)
+}
+
+// fetchErrorReason classifies an error returned by the HTTP client
+func fetchErrorReason(err error) FetchErrorReason {
+	var connectErr *proxyConnectError
+	if errors.As(err, &connectErr) {
+		return FetchErrorProxy
+	}
+	// The transport wraps every failure reaching the proxy, TLS handshake with
+	// an https proxy included, in a proxyconnect OpError
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+		return FetchErrorProxy
+	}
+	if isTLSError(err) {
+		return FetchErrorTLS
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return FetchErrorTimeout
+	}
+	return FetchErrorConnection
+}
+
+func isTLSError(err error) bool {
+	// TLS alerts sent by the server, e.g. rejecting the client certificate, are
+	// only reported as a remote error OpError
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "remote error" {
+		return true
+	}
+	var verificationErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &verificationErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &hostnameErr) || errors.As(err, &recordHeaderErr)
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	remoteBundle = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+`
+)
+
+var _ = Describe("URL", func() {
+	var server *httptest.Server
+	var caFile string
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow.yaml" {
+				select {
+				case <-time.After(2 * time.Second):
+				case <-r.Context().Done():
+				}
+			}
+			if r.URL.Path != "/bundle.yaml" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(remoteBundle))
+		}))
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("downloads the bundle and verifies its digest", func() {
+		b, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    server.URL + "/bundle.yaml",
+			SHA256: bundle.Digest([]byte(remoteBundle)),
+			CAFile: caFile,
+		})
+		Expect(err).To(BeNil())
+		Expect(string(b.Content)).To(Equal(remoteBundle))
+		Expect(b.Source).To(Equal(server.URL + "/bundle.yaml"))
+		Expect(b.IsEmbedded()).To(BeFalse())
+	})
+
+	It("accepts hex encoded digests without prefix", func() {
+		digest := bundle.Digest([]byte(remoteBundle))
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    server.URL + "/bundle.yaml",
+			SHA256: digest[len("sha256:"):],
+			CAFile: caFile,
+		})
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects content with a different digest", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    server.URL + "/bundle.yaml",
+			SHA256: bundle.Digest([]byte("something else")),
+			CAFile: caFile,
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("digest mismatch"))
+	})
+
+	It("rejects content exceeding the maximum size", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:     server.URL + "/bundle.yaml",
+			SHA256:  bundle.Digest([]byte(remoteBundle)),
+			CAFile:  caFile,
+			MaxSize: 10,
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("maximum size"))
+	})
+
+	It("fails when server certificate is not trusted", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    server.URL + "/bundle.yaml",
+			SHA256: bundle.Digest([]byte(remoteBundle)),
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(fetchErrorReason(err)).To(Equal(bundle.FetchErrorTLS))
+	})
+
+	It("fails on HTTP errors", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    server.URL + "/missing.yaml",
+			SHA256: bundle.Digest([]byte(remoteBundle)),
+			CAFile: caFile,
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("404"))
+		var fetchErr *bundle.FetchError
+		Expect(errors.As(err, &fetchErr)).To(BeTrue())
+		Expect(fetchErr.Reason).To(Equal(bundle.FetchErrorHTTP))
+		Expect(fetchErr.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("times out waiting for the response", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:         server.URL + "/slow.yaml",
+			SHA256:      bundle.Digest([]byte(remoteBundle)),
+			CAFile:      caFile,
+			ReadTimeout: 100 * time.Millisecond,
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(fetchErrorReason(err)).To(Equal(bundle.FetchErrorTimeout))
+	})
+
+	Context("through a proxy", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv("HTTPS_PROXY", "")
+			GinkgoT().Setenv("https_proxy", "")
+			GinkgoT().Setenv("NO_PROXY", "")
+			GinkgoT().Setenv("no_proxy", "")
+		})
+
+		// The server certificate is valid for example.com: the proxy tunnels
+		// every request to the test server whatever the host
+		const proxiedURL = "https://example.com/bundle.yaml"
+
+		It("fetches the bundle through the proxy", func() {
+			proxy, tunnels := newConnectProxy(server.Listener.Addr().String(), http.StatusOK)
+			defer proxy.Close()
+
+			b, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    proxiedURL,
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				CAFile: caFile,
+				Proxy:  proxy.URL,
+			})
+			Expect(err).To(BeNil())
+			Expect(string(b.Content)).To(Equal(remoteBundle))
+			Expect(tunnels.Load()).To(Equal(int32(1)))
+		})
+
+		It("uses HTTPS_PROXY when no proxy is set", func() {
+			proxy, tunnels := newConnectProxy(server.Listener.Addr().String(), http.StatusOK)
+			defer proxy.Close()
+			GinkgoT().Setenv("HTTPS_PROXY", proxy.URL)
+
+			_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    proxiedURL,
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				CAFile: caFile,
+			})
+			Expect(err).To(BeNil())
+			Expect(tunnels.Load()).To(Equal(int32(1)))
+		})
+
+		It("reports proxies refusing the tunnel without leaking credentials", func() {
+			proxy, _ := newConnectProxy(server.Listener.Addr().String(), http.StatusProxyAuthRequired)
+			defer proxy.Close()
+
+			_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    proxiedURL,
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				CAFile: caFile,
+				Proxy:  strings.Replace(proxy.URL, "http://", "http://user:secret@", 1),
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(fetchErrorReason(err)).To(Equal(bundle.FetchErrorProxy))
+			Expect(err.Error()).To(ContainSubstring("407"))
+			Expect(err.Error()).ToNot(ContainSubstring("secret"))
+		})
+
+		It("reports unreachable proxies", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			unreachable := "http://" + listener.Addr().String()
+			Expect(listener.Close()).To(Succeed())
+
+			_, err = bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    proxiedURL,
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				CAFile: caFile,
+				Proxy:  unreachable,
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(fetchErrorReason(err)).To(Equal(bundle.FetchErrorProxy))
+		})
+
+		It("honors NO_PROXY", func() {
+			GinkgoT().Setenv("NO_PROXY", "internal.example.com")
+			proxyFunc, err := bundle.ProxyFunc("http://proxy.example.com:3128")
+			Expect(err).To(BeNil())
+
+			req, err := http.NewRequest(http.MethodGet, "https://internal.example.com/bundle.yaml", http.NoBody)
+			Expect(err).To(BeNil())
+			proxyURL, err := proxyFunc(req)
+			Expect(err).To(BeNil())
+			Expect(proxyURL).To(BeNil())
+
+			req, err = http.NewRequest(http.MethodGet, proxiedURL, http.NoBody)
+			Expect(err).To(BeNil())
+			proxyURL, err = proxyFunc(req)
+			Expect(err).To(BeNil())
+			Expect(proxyURL.String()).To(Equal("http://proxy.example.com:3128"))
+		})
+
+		It("rejects invalid proxy URLs", func() {
+			_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    proxiedURL,
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				Proxy:  "ftp://proxy.example.com",
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("scheme"))
+		})
+	})
+
+	Context("with a server requiring a client certificate", func() {
+		var mtlsServer *httptest.Server
+		var certFile, keyFile string
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			var clientCert *x509.Certificate
+			certFile, keyFile, clientCert = writeClientCertificate(dir)
+
+			clientCAs := x509.NewCertPool()
+			clientCAs.AddCert(clientCert)
+			mtlsServer = httptest.NewUnstartedServer(server.Config.Handler)
+			mtlsServer.TLS = &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				ClientAuth: tls.RequireAndVerifyClientCert,
+				ClientCAs:  clientCAs,
+			}
+			mtlsServer.StartTLS()
+		})
+
+		AfterEach(func() {
+			mtlsServer.Close()
+		})
+
+		It("presents the client certificate", func() {
+			b, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:      mtlsServer.URL + "/bundle.yaml",
+				SHA256:   bundle.Digest([]byte(remoteBundle)),
+				CAFile:   caFile,
+				CertFile: certFile,
+				KeyFile:  keyFile,
+			})
+			Expect(err).To(BeNil())
+			Expect(string(b.Content)).To(Equal(remoteBundle))
+		})
+
+		It("fails without the client certificate", func() {
+			_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:    mtlsServer.URL + "/bundle.yaml",
+				SHA256: bundle.Digest([]byte(remoteBundle)),
+				CAFile: caFile,
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(fetchErrorReason(err)).To(Equal(bundle.FetchErrorTLS))
+		})
+
+		It("requires both the certificate and the key", func() {
+			_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:      mtlsServer.URL + "/bundle.yaml",
+				SHA256:   bundle.Digest([]byte(remoteBundle)),
+				CAFile:   caFile,
+				CertFile: certFile,
+			})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	It("requires https and a digest", func() {
+		_, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL:    "http://example.com/bundle.yaml",
+			SHA256: bundle.Digest([]byte(remoteBundle)),
+		})
+		Expect(err).ToNot(BeNil())
+
+		_, err = bundle.FromURL(context.TODO(), &bundle.URLOptions{
+			URL: server.URL + "/bundle.yaml",
+		})
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+func fetchErrorReason(err error) bundle.FetchErrorReason {
+	var fetchErr *bundle.FetchError
+	if !errors.As(err, &fetchErr) {
+		return ""
+	}
+	return fetchErr.Reason
+}
+
+// newConnectProxy returns a proxy answering CONNECT requests with status and,
+// when it is 200, tunneling them to target whatever the host requested.
+// It counts the CONNECT requests received.
+func newConnectProxy(target string, status int) (*httptest.Server, *atomic.Int32) {
+	tunnels := &atomic.Int32{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tunnels.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		upstream, err := net.Dial("tcp", target)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+		}()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	return proxy, tunnels
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key
+// in dir
+func writeClientCertificate(dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "crd-manager"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).To(BeNil())
+	cert, err = x509.ParseCertificate(der)
+	Expect(err).To(BeNil())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+	return certFile, keyFile, cert
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VerificationError is returned when a bundle signature cannot be verified
+type VerificationError struct {
+	// Source of the bundle
+	Source string
+
+	// Reason why the verification failed
+	Reason error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("signature verification of bundle %s failed: %v", e.Source, e.Reason)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Reason
+}
+
+// Verifier verifies signatures produced by cosign sign-blob --key
+type Verifier struct {
+	publicKey crypto.PublicKey
+}
+
+// NewVerifierFromFile returns a Verifier using the PEM encoded public key
+// (as produced by cosign generate-key-pair) contained in path
+func NewVerifierFromFile(path string) (*Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	return NewVerifier(data)
+}
+
+// NewVerifier returns a Verifier using a PEM encoded public key. ECDSA, RSA
+// and Ed25519 keys are supported.
+func NewVerifier(publicKeyPEM []byte) (*Verifier, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return &Verifier{publicKey: publicKey}, nil
+}
+
+// Verify verifies signature, base64 encoded as written by cosign, is a valid
+// signature of content
+func (v *Verifier) Verify(content, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	digest := sha256.Sum256(content)
+	switch key := v.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, content, sig) {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+func publicKeyPEM(publicKey crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	Expect(err).To(BeNil())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func signECDSA(privateKey *ecdsa.PrivateKey, content []byte) []byte {
+	digest := sha256.Sum256(content)
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	Expect(err).To(BeNil())
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+var _ = Describe("Verify", func() {
+	var privateKey *ecdsa.PrivateKey
+
+	BeforeEach(func() {
+		var err error
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+	})
+
+	It("accepts a valid ECDSA signature", func() {
+		verifier, err := bundle.NewVerifier(publicKeyPEM(privateKey.Public()))
+		Expect(err).To(BeNil())
+		Expect(verifier.Verify([]byte(remoteBundle), signECDSA(privateKey, []byte(remoteBundle)))).To(Succeed())
+	})
+
+	It("rejects a signature of different content", func() {
+		verifier, err := bundle.NewVerifier(publicKeyPEM(privateKey.Public()))
+		Expect(err).To(BeNil())
+		Expect(verifier.Verify([]byte(remoteBundle), signECDSA(privateKey, []byte("tampered")))).ToNot(Succeed())
+	})
+
+	It("accepts a valid Ed25519 signature", func() {
+		publicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).To(BeNil())
+		verifier, err := bundle.NewVerifier(publicKeyPEM(publicKey))
+		Expect(err).To(BeNil())
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivateKey, []byte(remoteBundle)))
+		Expect(verifier.Verify([]byte(remoteBundle), []byte(sig))).To(Succeed())
+	})
+
+	It("rejects invalid public keys", func() {
+		_, err := bundle.NewVerifier([]byte("not a key"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	Context("bundle from URL", func() {
+		var server *httptest.Server
+		var caFile string
+		var signature []byte
+
+		BeforeEach(func() {
+			signature = signECDSA(privateKey, []byte(remoteBundle))
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/bundle.yaml":
+					_, _ = w.Write([]byte(remoteBundle))
+				case "/bundle.yaml.sig":
+					if signature == nil {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write(signature)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			caFile = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+			caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("verifies the detached signature", func() {
+			verifier, err := bundle.NewVerifier(publicKeyPEM(privateKey.Public()))
+			Expect(err).To(BeNil())
+
+			b, err := bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:      server.URL + "/bundle.yaml",
+				SHA256:   bundle.Digest([]byte(remoteBundle)),
+				CAFile:   caFile,
+				Verifier: verifier,
+			})
+			Expect(err).To(BeNil())
+			Expect(b.SignatureVerified).To(BeTrue())
+		})
+
+		It("returns a VerificationError when signed with another key", func() {
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).To(BeNil())
+			verifier, err := bundle.NewVerifier(publicKeyPEM(otherKey.Public()))
+			Expect(err).To(BeNil())
+
+			_, err = bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:      server.URL + "/bundle.yaml",
+				SHA256:   bundle.Digest([]byte(remoteBundle)),
+				CAFile:   caFile,
+				Verifier: verifier,
+			})
+			var verificationErr *bundle.VerificationError
+			Expect(errors.As(err, &verificationErr)).To(BeTrue())
+		})
+
+		It("returns a VerificationError when the signature is missing", func() {
+			signature = nil
+			verifier, err := bundle.NewVerifier(publicKeyPEM(privateKey.Public()))
+			Expect(err).To(BeNil())
+
+			_, err = bundle.FromURL(context.TODO(), &bundle.URLOptions{
+				URL:      server.URL + "/bundle.yaml",
+				SHA256:   bundle.Digest([]byte(remoteBundle)),
+				CAFile:   caFile,
+				Verifier: verifier,
+			})
+			var verificationErr *bundle.VerificationError
+			Expect(errors.As(err, &verificationErr)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config sets crd-manager flags from sources other than the command
+// line. Explicit command line flags always take precedence.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// EnvPrefix prefixes the environment variables flags are read from
+	EnvPrefix = "CRD_MANAGER_"
+
+	// listSeparator separates the values of list flags set from the environment
+	listSeparator = ","
+)
+
+// EnvVarName returns the environment variable a flag is read from: the flag
+// name upper-cased, with dashes replaced by underscores, prefixed by EnvPrefix
+func EnvVarName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// ApplyEnv sets every flag of fs not explicitly set on the command line from
+// its environment variable, if defined. List flags take comma separated values.
+// It returns the names of the flags set from the environment.
+func ApplyEnv(fs *pflag.FlagSet) ([]string, error) {
+	return applyEnv(fs, os.LookupEnv)
+}
+
+func applyEnv(fs *pflag.FlagSet, lookup func(string) (string, bool)) ([]string, error) {
+	var applied []string
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		envVar := EnvVarName(f.Name)
+		value, ok := lookup(envVar)
+		if !ok {
+			return
+		}
+		if setErr := setFlag(fs, f, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", value, envVar, setErr)
+			return
+		}
+		applied = append(applied, f.Name)
+	})
+	return applied, err
+}
+
+// setFlag sets f to value. List flags values are replaced by the comma
+// separated items of value.
+func setFlag(fs *pflag.FlagSet, f *pflag.Flag, value string) error {
+	if _, ok := f.Value.(pflag.SliceValue); ok {
+		var items []string
+		if value != "" {
+			items = strings.Split(value, listSeparator)
+		}
+		return setList(fs, f, items)
+	}
+	return fs.Set(f.Name, value)
+}
+
+// setList replaces the values of the list flag f with items
+func setList(fs *pflag.FlagSet, f *pflag.Flag, items []string) error {
+	if len(items) == 0 {
+		if err := f.Value.(pflag.SliceValue).Replace(nil); err != nil {
+			return err
+		}
+		f.Changed = true
+		return nil
+	}
+	// The first Set of a list flag replaces its default, the following ones append
+	for _, item := range items {
+		if err := fs.Set(f.Name, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/spf13/pflag"
+
+	"github.com/projectsveltos/crd-manager/pkg/config"
+)
+
+type testFlags struct {
+	output         string
+	forceOwnership bool
+	versions       []string
+	timeout        time.Duration
+}
+
+func newFlagSet(flags *testFlags) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.StringVarP(&flags.output, "output", "o", "text", "")
+	fs.BoolVar(&flags.forceOwnership, "force-ownership", false, "")
+	fs.StringArrayVar(&flags.versions, "disable-version", nil, "")
+	fs.DurationVar(&flags.timeout, "bundle-fetch-timeout", time.Second, "")
+	return fs
+}
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+var _ = Describe("Env", func() {
+	It("derives the environment variable name from the flag name", func() {
+		Expect(config.EnvVarName("force-ownership")).To(Equal("CRD_MANAGER_FORCE_OWNERSHIP"))
+	})
+
+	It("sets flags from the environment", func() {
+		flags := &testFlags{}
+		fs := newFlagSet(flags)
+		Expect(fs.Parse(nil)).To(Succeed())
+
+		applied, err := config.ApplyEnvWithLookup(fs, lookupFrom(map[string]string{
+			"CRD_MANAGER_FORCE_OWNERSHIP":      "true",
+			"CRD_MANAGER_DISABLE_VERSION":      "a.b.c:v1alpha1,d.e.f:v1beta1",
+			"CRD_MANAGER_BUNDLE_FETCH_TIMEOUT": "1m",
+		}))
+		Expect(err).To(BeNil())
+		Expect(applied).To(ConsistOf("force-ownership", "disable-version", "bundle-fetch-timeout"))
+		Expect(flags.forceOwnership).To(BeTrue())
+		Expect(flags.versions).To(Equal([]string{"a.b.c:v1alpha1", "d.e.f:v1beta1"}))
+		Expect(flags.timeout).To(Equal(time.Minute))
+		Expect(flags.output).To(Equal("text"))
+	})
+
+	It("gives precedence to command line flags", func() {
+		flags := &testFlags{}
+		fs := newFlagSet(flags)
+		Expect(fs.Parse([]string{"-o", "json", "--disable-version", "x.y.z:v1"})).To(Succeed())
+
+		applied, err := config.ApplyEnvWithLookup(fs, lookupFrom(map[string]string{
+			"CRD_MANAGER_OUTPUT":          "text",
+			"CRD_MANAGER_DISABLE_VERSION": "a.b.c:v1alpha1",
+		}))
+		Expect(err).To(BeNil())
+		Expect(applied).To(BeEmpty())
+		Expect(flags.output).To(Equal("json"))
+		Expect(flags.versions).To(Equal([]string{"x.y.z:v1"}))
+	})
+
+	It("reports invalid values with the environment variable name", func() {
+		flags := &testFlags{}
+		fs := newFlagSet(flags)
+		Expect(fs.Parse(nil)).To(Succeed())
+
+		_, err := config.ApplyEnvWithLookup(fs, lookupFrom(map[string]string{
+			"CRD_MANAGER_FORCE_OWNERSHIP": "maybe",
+		}))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("CRD_MANAGER_FORCE_OWNERSHIP"))
+	})
+})
@@ -0,0 +1,194 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// redacted replaces the value of secret flags when logged
+	redacted = "<redacted>"
+)
+
+var (
+	// secretMarkers identify, by name, flags whose value must never be logged
+	secretMarkers = []string{"password", "secret", "token"}
+)
+
+// ApplyFile sets every flag of fs not already set (on the command line or
+// from the environment) from the YAML configuration file in path.
+// It returns the names of the flags set from the file.
+//
+// The file is a map whose keys are flag names. Keys can be nested, the flag
+// name being the nested keys joined by a dash, so
+//
+//	bundle:
+//	  url: https://example.com/crds.yaml
+//
+// sets --bundle-url. Lists set list flags and maps set map flags.
+func ApplyFile(fs *pflag.FlagSet, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	applied, err := applyConfig(fs, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return applied, nil
+}
+
+func applyConfig(fs *pflag.FlagSet, data []byte) ([]string, error) {
+	content := map[string]interface{}{}
+	// Numbers are kept as written, instead of being converted to float64
+	if err := yaml.Unmarshal(data, &content, func(d *json.Decoder) *json.Decoder {
+		d.UseNumber()
+		return d
+	}); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err := collectValues(fs, content, "", "", values); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	applied := make([]string, 0, len(names))
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f.Changed {
+			continue
+		}
+		if err := setFromConfig(fs, f, values[name]); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
+
+// collectValues walks content and stores, per flag name, the value to set.
+// Keys matching no flag are reported with their YAML path.
+func collectValues(fs *pflag.FlagSet, content map[string]interface{}, prefix, path string,
+	values map[string]interface{}) error {
+
+	for key, value := range content {
+		name := key
+		keyPath := key
+		if prefix != "" {
+			name = prefix + "-" + key
+			keyPath = path + "." + key
+		}
+
+		if fs.Lookup(name) != nil {
+			if _, duplicated := values[name]; duplicated {
+				return fmt.Errorf("%s: flag %s is set more than once", keyPath, name)
+			}
+			values[name] = value
+			continue
+		}
+
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unknown key", keyPath)
+		}
+		if err := collectValues(fs, nested, name, keyPath, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFromConfig sets f from a YAML value
+func setFromConfig(fs *pflag.FlagSet, f *pflag.Flag, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		if _, ok := f.Value.(pflag.SliceValue); !ok {
+			return fmt.Errorf("a list is not valid for a %s flag", f.Value.Type())
+		}
+		items := make([]string, len(v))
+		for i := range v {
+			items[i] = fmt.Sprint(v[i])
+		}
+		return setList(fs, f, items)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = fmt.Sprintf("%s=%v", key, v[key])
+		}
+		return fs.Set(f.Name, strings.Join(pairs, ","))
+	case nil:
+		return nil
+	default:
+		return setFlag(fs, f, fmt.Sprint(v))
+	}
+}
+
+// Describe returns, sorted by name, the flags set (from any source) with
+// their value. Values of secret flags are redacted, as are the credentials of
+// URLs carrying some, such as proxy URLs.
+func Describe(fs *pflag.FlagSet) []string {
+	var result []string
+	fs.Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if isSecret(f.Name) {
+			value = redacted
+		} else if u, err := url.Parse(value); err == nil && u.User != nil {
+			value = redactedURL(u)
+		}
+		result = append(result, fmt.Sprintf("%s=%s", f.Name, value))
+	})
+	return result
+}
+
+// redactedURL returns u with its password, or its user when it carries no
+// password, as tokens often are, replaced
+func redactedURL(u *url.URL) string {
+	if _, ok := u.User.Password(); !ok {
+		u.User = url.User("xxxxx")
+	}
+	return u.Redacted()
+}
+
+func isSecret(name string) bool {
+	for _, marker := range secretMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
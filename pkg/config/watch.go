@@ -0,0 +1,97 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultWatchInterval is the default interval between two checks of a watched file
+	DefaultWatchInterval = 10 * time.Second
+)
+
+// Watcher periodically reads a file and invokes a callback when its content
+// changes. Reading the content, instead of relying on file system events,
+// also detects changes to files mounted from a ConfigMap, which are replaced
+// through a symlink swap. It implements the controller-runtime manager.Runnable
+// interface.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	onChange func() error
+	logger   logr.Logger
+}
+
+// NewWatcher returns a Watcher checking path every interval
+func NewWatcher(path string, interval time.Duration, onChange func() error, logger logr.Logger) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{path: path, interval: interval, onChange: onChange, logger: logger}
+}
+
+// Start checks the file until ctx is cancelled. A failing callback is logged
+// and invoked again only once the content changes again.
+func (w *Watcher) Start(ctx context.Context) error {
+	last, err := fileDigest(w.path)
+	if err != nil {
+		w.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to read %s: %v", w.path, err))
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := fileDigest(w.path)
+		if err != nil {
+			w.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to read %s: %v", w.path, err))
+			continue
+		}
+		if current == last {
+			continue
+		}
+		last = current
+
+		w.logger.V(logs.LogInfo).Info(fmt.Sprintf("%s changed", w.path))
+		if err := w.onChange(); err != nil {
+			w.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to handle %s change: %v", w.path, err))
+		}
+	}
+}
+
+func fileDigest(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
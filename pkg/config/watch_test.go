@@ -0,0 +1,56 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/klog/v2"
+
+	"github.com/projectsveltos/crd-manager/pkg/config"
+)
+
+var _ = Describe("Watcher", func() {
+	It("invokes the callback once per content change", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte("add-category: a\n"), 0o600)).To(Succeed())
+
+		var changes atomic.Int32
+		watcher := config.NewWatcher(path, 10*time.Millisecond, func() error {
+			changes.Add(1)
+			return errors.New("rejected")
+		}, klog.Background())
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go func() { _ = watcher.Start(ctx) }()
+
+		Consistently(changes.Load, 100*time.Millisecond).Should(BeZero())
+
+		Expect(os.WriteFile(path, []byte("add-category: b\n"), 0o600)).To(Succeed())
+		Eventually(changes.Load, time.Second).Should(Equal(int32(1)))
+		Consistently(changes.Load, 100*time.Millisecond).Should(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,158 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// DefaultBackoffBase is the delay before retrying a CRD which failed once
+	DefaultBackoffBase = 5 * time.Second
+
+	// DefaultBackoffMax caps the delay before retrying a CRD which keeps failing
+	DefaultBackoffMax = 5 * time.Minute
+)
+
+// crdState is what the Runner remembers about a CRD between passes
+type crdState struct {
+	// failures is the number of passes in a row processing the CRD failed
+	failures int
+
+	// nextRetry is when a failing CRD is processed again
+	nextRetry time.Time
+
+	// denied is true if the last failure is an admission webhook denial.
+	// Denials are deterministic: the CRD is not retried before the next full pass.
+	denied bool
+
+	// drift is the drift status reported the last time the CRD was processed
+	drift deploy.DriftStatus
+}
+
+// backoffTracker decides, per CRD, whether a pass processes it. Failing CRDs
+// are retried with exponentially increasing delays, up to max, while the
+// others follow the resync period. It is not safe for concurrent use.
+type backoffTracker struct {
+	base time.Duration
+	max  time.Duration
+
+	states map[string]*crdState
+}
+
+func newBackoffTracker(base, maxDelay time.Duration) *backoffTracker {
+	return &backoffTracker{base: base, max: maxDelay, states: map[string]*crdState{}}
+}
+
+// deferFunc returns the deploy.Options Defer function of a pass starting at
+// now. A full pass processes every CRD not backing off, the others only the
+// failing CRDs whose retry is due.
+func (t *backoffTracker) deferFunc(now time.Time, full bool) func(string) bool {
+	return func(name string) bool {
+		state := t.states[name]
+		if state != nil && state.failures > 0 && !state.denied {
+			return now.Before(state.nextRetry)
+		}
+		return !full
+	}
+}
+
+// delay returns the delay before retrying a CRD which failed failures times in a row
+func (t *backoffTracker) delay(failures int) time.Duration {
+	d := t.base
+	for i := 1; i < failures && d < t.max; i++ {
+		d *= 2
+	}
+	if d > t.max {
+		return t.max
+	}
+	return d
+}
+
+// update records the outcome of a pass started at now, and completes the
+// report with the backoff state of every CRD
+func (t *backoffTracker) update(report *deploy.Report, now time.Time) {
+	// A paused pass does not look at the CRDs
+	if report.Status == deploy.RunStatusPaused {
+		return
+	}
+
+	states := make(map[string]*crdState, len(report.CRDs))
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		state := t.states[result.Name]
+		if state == nil {
+			state = &crdState{}
+		}
+		states[result.Name] = state
+
+		switch result.Action {
+		case deploy.ActionDeferred, deploy.ActionNotAttempted:
+			result.Drift = state.drift
+		case deploy.ActionFailed, deploy.ActionRolledBack:
+			state.failures++
+			state.denied = result.AdmissionDenial != nil
+			state.nextRetry = time.Time{}
+			if !state.denied {
+				state.nextRetry = now.Add(t.delay(state.failures))
+			}
+			state.drift = ""
+		default:
+			// success resets the backoff
+			state.failures = 0
+			state.nextRetry = time.Time{}
+			state.denied = false
+			state.drift = result.Drift
+		}
+
+		if state.failures > 0 {
+			result.ConsecutiveFailures = state.failures
+			if !state.denied {
+				result.NextRetry = &metav1.Time{Time: state.nextRetry}
+			}
+		}
+	}
+	// CRDs no longer part of the bundle are forgotten
+	t.states = states
+}
+
+// reset forgets all failures, so that every CRD is processed by the next pass
+func (t *backoffTracker) reset() {
+	for _, state := range t.states {
+		state.failures = 0
+		state.nextRetry = time.Time{}
+		state.denied = false
+	}
+}
+
+// nextRetry returns the earliest retry of a failing CRD, and false if no CRD is
+// failing. CRDs an admission webhook denied are not retried on their own.
+func (t *backoffTracker) nextRetry() (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, state := range t.states {
+		if state.failures > 0 && !state.denied && (!found || state.nextRetry.Before(earliest)) {
+			earliest = state.nextRetry
+			found = true
+		}
+	}
+	return earliest, found
+}
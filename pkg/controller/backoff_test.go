@@ -0,0 +1,216 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	failingCRD = "sveltosclusters.lib.projectsveltos.io"
+)
+
+// newFailingClient returns a fake client rejecting the creation of failingCRD
+// as long as failing is true
+func newFailingClient(failing *atomic.Bool) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == failingCRD && failing.Load() {
+				return errors.New("rejected by webhook")
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+// newUnavailableClient returns a fake client whose first failures creations
+// of failingCRD fail because the API server is unavailable
+func newUnavailableClient(failures int32, creates *atomic.Int32) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == failingCRD && creates.Add(1) <= failures {
+				return apierrors.NewServiceUnavailable("overloaded")
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+// newDenyingClient returns a fake client whose admission webhook denies the
+// creation of failingCRD
+func newDenyingClient() client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == failingCRD {
+				return &apierrors.StatusError{ErrStatus: metav1.Status{
+					Status:  metav1.StatusFailure,
+					Code:    http.StatusBadRequest,
+					Message: `admission webhook "validate.kyverno.svc-fail" denied the request: not allowed`,
+				}}
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+func findResult(report *deploy.Report, name string) *deploy.CRDResult {
+	for i := range report.CRDs {
+		if report.CRDs[i].Name == name {
+			return &report.CRDs[i]
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Backoff", func() {
+	It("delays grow exponentially up to the cap", func() {
+		Expect(controller.BackoffDelay(time.Second, time.Minute, 1)).To(Equal(time.Second))
+		Expect(controller.BackoffDelay(time.Second, time.Minute, 2)).To(Equal(2 * time.Second))
+		Expect(controller.BackoffDelay(time.Second, time.Minute, 4)).To(Equal(8 * time.Second))
+		Expect(controller.BackoffDelay(time.Second, time.Minute, 10)).To(Equal(time.Minute))
+		Expect(controller.BackoffDelay(time.Second, time.Minute, 1000)).To(Equal(time.Minute))
+	})
+
+	It("retries a failing CRD on its own until it succeeds", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		failing := &atomic.Bool{}
+		failing.Store(true)
+		reports := make(chan *deploy.Report, 10)
+		runner := controller.NewRunner(newFailingClient(failing), &deploy.Options{}, time.Hour,
+			func(report *deploy.Report, _ error) { reports <- report }, logger)
+		runner.SetBackoff(50*time.Millisecond, 200*time.Millisecond)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs) - 1))
+		result := findResult(report, failingCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.ConsecutiveFailures).To(Equal(1))
+		Expect(result.NextRetry).ToNot(BeNil())
+
+		// the retry only processes the failing CRD, the others wait for the resync
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionDeferred)).To(Equal(len(report.CRDs) - 1))
+		result = findResult(report, failingCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.ConsecutiveFailures).To(Equal(2))
+
+		failing.Store(false)
+		Eventually(func() int {
+			Eventually(reports, 5*time.Second).Should(Receive(&report))
+			return findResult(report, failingCRD).ConsecutiveFailures
+		}, 5*time.Second).Should(BeZero())
+		Expect(findResult(report, failingCRD).Action).To(Equal(deploy.ActionCreated))
+		Expect(findResult(report, failingCRD).NextRetry).To(BeNil())
+
+		// no failing CRD left: nothing happens before the resync period
+		Consistently(reports, 500*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("does not back off a CRD which succeeds within its retries", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		creates := &atomic.Int32{}
+		reports := make(chan *deploy.Report, 10)
+		runner := controller.NewRunner(newUnavailableClient(2, creates),
+			&deploy.Options{Retries: 2, RetryInterval: time.Millisecond}, time.Hour,
+			func(report *deploy.Report, _ error) { reports <- report }, logger)
+		runner.SetBackoff(50*time.Millisecond, 200*time.Millisecond)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		result := findResult(report, failingCRD)
+		Expect(result.Action).To(Equal(deploy.ActionCreated))
+		Expect(result.Attempts).To(Equal(3))
+		Expect(result.ConsecutiveFailures).To(BeZero())
+		Expect(result.NextRetry).To(BeNil())
+
+		Consistently(reports, 500*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("backs off a CRD exhausting its retries as having failed once per pass", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		creates := &atomic.Int32{}
+		reports := make(chan *deploy.Report, 10)
+		runner := controller.NewRunner(newUnavailableClient(1000, creates),
+			&deploy.Options{Retries: 2, RetryInterval: time.Millisecond}, time.Hour,
+			func(report *deploy.Report, _ error) { reports <- report }, logger)
+		runner.SetBackoff(50*time.Millisecond, 200*time.Millisecond)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		result := findResult(report, failingCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.Attempts).To(Equal(3))
+		Expect(result.ConsecutiveFailures).To(Equal(1))
+		Expect(result.NextRetry).ToNot(BeNil())
+
+		// the backoff retry attempts the CRD, with its retries, on its own
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionDeferred)).To(Equal(len(report.CRDs) - 1))
+		result = findResult(report, failingCRD)
+		Expect(result.Attempts).To(Equal(3))
+		Expect(result.ConsecutiveFailures).To(Equal(2))
+		Expect(creates.Load()).To(Equal(int32(6)))
+	})
+
+	It("does not retry a CRD an admission webhook denied before the resync", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		reports := make(chan *deploy.Report, 10)
+		runner := controller.NewRunner(newDenyingClient(), &deploy.Options{}, time.Hour,
+			func(report *deploy.Report, _ error) { reports <- report }, logger)
+		runner.SetBackoff(50*time.Millisecond, 200*time.Millisecond)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		result := findResult(report, failingCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.AdmissionDenial).ToNot(BeNil())
+		Expect(result.ConsecutiveFailures).To(Equal(1))
+		Expect(result.NextRetry).To(BeNil())
+
+		// denials are deterministic: no retry before the resync period
+		Consistently(reports, 500*time.Millisecond).ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// CacheOptions returns the manager cache options. Only the CRDs carrying the
+// crd-manager ownership label are cached: the bundle CRDs, once deployed,
+// without the memory cost of caching every CRD of the cluster.
+func CacheOptions() cache.Options {
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&apiextensionsv1.CustomResourceDefinition{}: {
+				Label: labels.SelectorFromSet(labels.Set{deploy.ManagedByLabel: deploy.ManagedByValue}),
+			},
+		},
+	}
+}
+
+// CachedClient is a client reading CRDs from an informer cache, so that a
+// pass finding nothing to change issues no request. Until the cache is
+// synced, and for the CRDs it does not contain (not managed by crd-manager
+// yet), CRDs are read from the API server. All other reads, and all writes,
+// go to the API server.
+type CachedClient struct {
+	client.Client
+
+	informers cache.Informers
+	reader    client.Reader
+	synced    atomic.Bool
+	logger    logr.Logger
+}
+
+// NewCachedClient returns a client reading CRDs from c, once synced, and
+// otherwise using live. It must be added to the manager starting c.
+func NewCachedClient(live client.Client, c cache.Cache, logger logr.Logger) *CachedClient {
+	return &CachedClient{Client: live, informers: c, reader: c, logger: logger}
+}
+
+// Start waits for the CRD informer to sync, then serves CRD reads from the
+// cache. It implements manager.Runnable.
+func (c *CachedClient) Start(ctx context.Context) error {
+	// Getting the informer, on a started cache, blocks until it is synced
+	if _, err := c.informers.GetInformer(ctx, &apiextensionsv1.CustomResourceDefinition{}); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to start the CRD informer: %w", err)
+	}
+	c.synced.Store(true)
+	c.logger.V(logs.LogInfo).Info("CRD cache synced, CRDs are now read from the cache")
+	return nil
+}
+
+// Synced returns true once CRDs are read from the cache
+func (c *CachedClient) Synced() bool {
+	return c.synced.Load()
+}
+
+// Get reads CRDs from the cache once synced, falling back to the API server
+// for the ones not cached
+func (c *CachedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object,
+	opts ...client.GetOption) error {
+
+	if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok && c.synced.Load() {
+		err := c.reader.Get(ctx, key, obj, opts...)
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// newCountingClient returns a client over c counting, in gets, the CRD Gets
+// reaching the API server
+func newCountingClient(c client.WithWatch, gets *atomic.Int32) client.Client {
+	return interceptor.NewClient(c, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+
+			if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+				gets.Add(1)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	})
+}
+
+var _ = Describe("CachedClient", func() {
+	var base client.WithWatch
+	var gets atomic.Int32
+
+	BeforeEach(func() {
+		base = newFakeClient().(client.WithWatch)
+		gets.Store(0)
+
+		// The first pass creates the CRDs
+		_, err := deploy.Deploy(context.TODO(), base, nil, logger)
+		Expect(err).To(BeNil())
+	})
+
+	It("reads no CRD from the API server once synced", func() {
+		live := newCountingClient(base, &gets)
+		report, err := deploy.Deploy(context.TODO(), live, nil, logger)
+		Expect(err).To(BeNil())
+		uncached := gets.Load()
+		Expect(uncached).To(BeNumerically(">=", len(report.CRDs)))
+
+		gets.Store(0)
+		cached := controller.NewSyncedCachedClient(live, base, logger)
+		report, err = deploy.Deploy(context.TODO(), cached, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+		Expect(gets.Load()).To(BeZero())
+	})
+
+	It("reads the CRDs missing from the cache from the API server", func() {
+		cached := controller.NewSyncedCachedClient(newCountingClient(base, &gets), newFakeClient(), logger)
+		report, err := deploy.Deploy(context.TODO(), cached, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+		Expect(gets.Load()).To(BeNumerically(">=", len(report.CRDs)))
+	})
+
+	It("reads CRDs from the API server until the cache is synced", func() {
+		cached := controller.NewCachedClient(newCountingClient(base, &gets), nil, logger)
+		Expect(cached.Synced()).To(BeFalse())
+
+		report, err := deploy.Deploy(context.TODO(), cached, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+		Expect(gets.Load()).To(BeNumerically(">=", len(report.CRDs)))
+	})
+
+	It("caches only the CRDs managed by crd-manager", func() {
+		opts := controller.CacheOptions()
+		Expect(opts.ByObject).To(HaveLen(1))
+		for obj, byObject := range opts.ByObject {
+			Expect(obj).To(BeAssignableToTypeOf(&apiextensionsv1.CustomResourceDefinition{}))
+			Expect(byObject.Label.String()).To(Equal(deploy.ManagedByLabel + "=" + deploy.ManagedByValue))
+		}
+	})
+})
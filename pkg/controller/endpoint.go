@@ -0,0 +1,172 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// ReconcileEndpointPath is the path of the endpoint requesting manual
+	// passes. The result of a pass is served under ReconcileEndpointPath/<id>.
+	ReconcileEndpointPath = "/reconcile"
+
+	// DefaultReconcileMinInterval is the default minimum interval between two
+	// manual passes requests
+	DefaultReconcileMinInterval = 10 * time.Second
+)
+
+// ReconcileEndpointOptions configures the reconcile endpoint
+type ReconcileEndpointOptions struct {
+	// TokenFile contains the bearer token requests must present. It is read
+	// on every request, so that the token can be rotated.
+	TokenFile string
+
+	// MinInterval is the minimum interval between two accepted requests for
+	// a manual pass. Requests arriving earlier are rejected with 429.
+	MinInterval time.Duration
+}
+
+// Validate returns an error if the token cannot be read
+func (o *ReconcileEndpointOptions) Validate() error {
+	if o.TokenFile == "" {
+		return errors.New("a token file is required")
+	}
+	_, err := readToken(o.TokenFile)
+	return err
+}
+
+// ReconcileHandler serves the reconcile endpoint.
+// POST ReconcileEndpointPath requests a manual pass and answers 202 with it.
+// GET ReconcileEndpointPath/<id> returns the pass with that id.
+type ReconcileHandler struct {
+	runner    *Runner
+	tokenFile string
+	limiter   *rate.Limiter
+	logger    logr.Logger
+}
+
+// NewReconcileHandler returns the handler of the reconcile endpoint
+// requesting passes from runner. It must be registered on both
+// ReconcileEndpointPath and ReconcileEndpointPath/.
+func NewReconcileHandler(runner *Runner, opts ReconcileEndpointOptions, logger logr.Logger) *ReconcileHandler {
+	minInterval := opts.MinInterval
+	if minInterval <= 0 {
+		minInterval = DefaultReconcileMinInterval
+	}
+	return &ReconcileHandler{
+		runner:    runner,
+		tokenFile: opts.TokenFile,
+		limiter:   rate.NewLimiter(rate.Every(minInterval), 1),
+		logger:    logger,
+	}
+}
+
+func (h *ReconcileHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !h.authenticate(w, req) {
+		return
+	}
+
+	id := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, ReconcileEndpointPath), "/")
+	switch {
+	case id == "" && req.Method == http.MethodPost:
+		h.requestPass(w)
+	case id == "":
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	case req.Method == http.MethodGet:
+		pass, ok := h.runner.ManualPass(id)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown run %s", id), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, &pass, h.logger)
+	default:
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authenticate checks the bearer token of req, answering 401 when it is not
+// the expected one
+func (h *ReconcileHandler) authenticate(w http.ResponseWriter, req *http.Request) bool {
+	token, err := readToken(h.tokenFile)
+	if err != nil {
+		h.logger.Error(err, "failed to read the reconcile endpoint token")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return false
+	}
+
+	presented, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(presented), token) != 1 {
+		h.logger.V(logs.LogDebug).Info(fmt.Sprintf("rejected unauthenticated %s %s from %s",
+			req.Method, req.URL.Path, req.RemoteAddr))
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (h *ReconcileHandler) requestPass(w http.ResponseWriter) {
+	reservation := h.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	pass := h.runner.RequestPass()
+	h.logger.V(logs.LogInfo).Info(fmt.Sprintf("manual reconciliation pass %s requested", pass.ID))
+	w.Header().Set("Location", ReconcileEndpointPath+"/"+pass.ID)
+	writeJSON(w, http.StatusAccepted, &pass, h.logger)
+}
+
+func readToken(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	token := []byte(strings.TrimSpace(string(content)))
+	if len(token) == 0 {
+		return nil, fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any, logger logr.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to write response: %v", err))
+	}
+}
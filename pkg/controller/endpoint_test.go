@@ -0,0 +1,152 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("ReconcileHandler", func() {
+	const token = "s3cr3t"
+
+	var tokenFile string
+	var cancel context.CancelFunc
+	var ctx context.Context
+
+	BeforeEach(func() {
+		tokenFile = filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte(token+"\n"), 0o600)).To(Succeed())
+		ctx, cancel = context.WithCancel(context.TODO())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	serve := func(handler http.Handler, method, path, presented string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		if presented != "" {
+			req.Header.Set("Authorization", "Bearer "+presented)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	decode := func(recorder *httptest.ResponseRecorder) controller.ManualPass {
+		var pass controller.ManualPass
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &pass)).To(Succeed())
+		return pass
+	}
+
+	It("rejects requests without the expected bearer token", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, nil, logger)
+		handler := controller.NewReconcileHandler(runner, controller.ReconcileEndpointOptions{TokenFile: tokenFile}, logger)
+
+		Expect(serve(handler, http.MethodPost, controller.ReconcileEndpointPath, "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(serve(handler, http.MethodPost, controller.ReconcileEndpointPath, "wrong").Code).To(
+			Equal(http.StatusUnauthorized))
+
+		// the token is read on every request
+		Expect(os.WriteFile(tokenFile, []byte("rotated"), 0o600)).To(Succeed())
+		Expect(serve(handler, http.MethodPost, controller.ReconcileEndpointPath, token).Code).To(
+			Equal(http.StatusUnauthorized))
+		Expect(serve(handler, http.MethodPost, controller.ReconcileEndpointPath, "rotated").Code).To(
+			Equal(http.StatusAccepted))
+	})
+
+	It("runs a full pass on request and serves its result", func() {
+		reports := make(chan *deploy.Report, 10)
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, func(report *deploy.Report, _ error) {
+			reports <- report
+		}, logger)
+		handler := controller.NewReconcileHandler(runner, controller.ReconcileEndpointOptions{TokenFile: tokenFile}, logger)
+		go func() { _ = runner.Start(ctx) }()
+
+		var scheduled *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&scheduled))
+		Expect(scheduled.Trigger).To(BeEmpty())
+
+		recorder := serve(handler, http.MethodPost, controller.ReconcileEndpointPath, token)
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		requested := decode(recorder)
+		Expect(requested.ID).ToNot(BeEmpty())
+		Expect(recorder.Header().Get("Location")).To(Equal(controller.ReconcileEndpointPath + "/" + requested.ID))
+
+		var manual *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&manual))
+		Expect(manual.Trigger).To(Equal(controller.TriggerManual))
+		Expect(manual.Count(deploy.ActionUnchanged)).To(Equal(len(manual.CRDs)))
+
+		var pass controller.ManualPass
+		Eventually(func() controller.ManualPassState {
+			recorder = serve(handler, http.MethodGet, controller.ReconcileEndpointPath+"/"+requested.ID, token)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			pass = decode(recorder)
+			return pass.State
+		}, 5*time.Second).Should(Equal(controller.ManualPassCompleted))
+		Expect(pass.ID).To(Equal(requested.ID))
+		Expect(pass.CompletedAt).ToNot(BeNil())
+		Expect(pass.Error).To(BeEmpty())
+		Expect(pass.Report).ToNot(BeNil())
+		Expect(pass.Report.Status).To(Equal(deploy.RunStatusSuccess))
+		Expect(pass.Report.Trigger).To(Equal(controller.TriggerManual))
+	})
+
+	It("rate limits the requests for a pass", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, nil, logger)
+		handler := controller.NewReconcileHandler(runner,
+			controller.ReconcileEndpointOptions{TokenFile: tokenFile, MinInterval: time.Minute}, logger)
+
+		Expect(serve(handler, http.MethodPost, controller.ReconcileEndpointPath, token).Code).To(Equal(http.StatusAccepted))
+		recorder := serve(handler, http.MethodPost, controller.ReconcileEndpointPath, token)
+		Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(recorder.Header().Get("Retry-After")).To(Equal("60"))
+	})
+
+	It("answers 404 for unknown runs and 405 for other methods", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, nil, logger)
+		handler := controller.NewReconcileHandler(runner, controller.ReconcileEndpointOptions{TokenFile: tokenFile}, logger)
+
+		Expect(serve(handler, http.MethodGet, controller.ReconcileEndpointPath+"/unknown", token).Code).To(
+			Equal(http.StatusNotFound))
+		Expect(serve(handler, http.MethodGet, controller.ReconcileEndpointPath, token).Code).To(
+			Equal(http.StatusMethodNotAllowed))
+		Expect(serve(handler, http.MethodDelete, controller.ReconcileEndpointPath+"/unknown", token).Code).To(
+			Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("requires a readable, non-empty token file", func() {
+		Expect((&controller.ReconcileEndpointOptions{}).Validate()).ToNot(Succeed())
+		Expect((&controller.ReconcileEndpointOptions{TokenFile: filepath.Join(GinkgoT().TempDir(), "missing")}).
+			Validate()).ToNot(Succeed())
+		Expect(os.WriteFile(tokenFile, []byte(" \n"), 0o600)).To(Succeed())
+		Expect((&controller.ReconcileEndpointOptions{TokenFile: tokenFile}).Validate()).ToNot(Succeed())
+	})
+})
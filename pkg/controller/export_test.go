@@ -0,0 +1,46 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	RecordPass = recordPass
+)
+
+// SetBackoff replaces the backoff of a Runner not started yet
+func (r *Runner) SetBackoff(base, maxDelay time.Duration) {
+	r.backoff = newBackoffTracker(base, maxDelay)
+}
+
+// BackoffDelay returns the delay before retrying a CRD which failed failures times in a row
+func BackoffDelay(base, maxDelay time.Duration, failures int) time.Duration {
+	return newBackoffTracker(base, maxDelay).delay(failures)
+}
+
+// NewSyncedCachedClient returns a CachedClient whose cache, already synced,
+// is read through reader
+func NewSyncedCachedClient(live client.Client, reader client.Reader, logger logr.Logger) *CachedClient {
+	c := &CachedClient{Client: live, reader: reader, logger: logger}
+	c.synced.Store(true)
+	return c
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// TriggerScheduled is the trigger of the passes run on every resync
+	// period, configuration reload or backoff expiry
+	TriggerScheduled = "scheduled"
+
+	// TriggerManual is the trigger of the passes requested through RequestPass
+	TriggerManual = "manual"
+
+	// maxManualPasses is the number of manual passes whose result is kept
+	maxManualPasses = 100
+)
+
+// ManualPassState is the state of a pass requested through RequestPass
+type ManualPassState string
+
+const (
+	// ManualPassPending is the state of a pass waiting for the pass in flight, if any, to complete
+	ManualPassPending ManualPassState = "pending"
+
+	// ManualPassRunning is the state of a pass in flight
+	ManualPassRunning ManualPassState = "running"
+
+	// ManualPassCompleted is the state of a pass which completed, successfully or not
+	ManualPassCompleted ManualPassState = "completed"
+)
+
+// ManualPass is a full reconciliation pass requested through RequestPass
+type ManualPass struct {
+	// ID identifies the pass
+	ID string `json:"id"`
+
+	// State is the state of the pass
+	State ManualPassState `json:"state"`
+
+	// RequestedAt is when the pass was first requested
+	RequestedAt metav1.Time `json:"requestedAt"`
+
+	// CompletedAt is, once completed, when the pass completed
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// Error is, once completed, the error which stopped the pass, if any
+	Error string `json:"error,omitempty"`
+
+	// Report is, once completed, the report of the pass
+	Report *deploy.Report `json:"report,omitempty"`
+}
+
+// manualPasses tracks the manual passes, keeping the results of the last
+// maxManualPasses ones
+type manualPasses struct {
+	mu sync.Mutex
+
+	// pending is the pass requested and not started yet, if any
+	pending *ManualPass
+
+	// passes are indexed by ID, order lists their IDs by request time
+	passes map[string]*ManualPass
+	order  []string
+}
+
+// RequestPass requests an immediate full pass, returning it. Requests received
+// while a manual pass is pending are coalesced: they return the same pass.
+func (r *Runner) RequestPass() ManualPass {
+	r.manual.mu.Lock()
+	pass := r.manual.pending
+	if pass == nil {
+		pass = &ManualPass{
+			ID:          string(uuid.NewUUID()),
+			State:       ManualPassPending,
+			RequestedAt: metav1.Now(),
+		}
+		r.manual.add(pass)
+	}
+	requested := *pass
+	r.manual.mu.Unlock()
+
+	r.Trigger()
+	return requested
+}
+
+// ManualPass returns the pass with id requested through RequestPass, if its
+// result is still known
+func (r *Runner) ManualPass(id string) (ManualPass, bool) {
+	r.manual.mu.Lock()
+	defer r.manual.mu.Unlock()
+
+	pass, ok := r.manual.passes[id]
+	if !ok {
+		return ManualPass{}, false
+	}
+	return *pass, true
+}
+
+// add records the new pending pass, forgetting the oldest ones
+// beyond maxManualPasses. m.mu must be held.
+func (m *manualPasses) add(pass *ManualPass) {
+	m.pending = pass
+	m.passes[pass.ID] = pass
+	m.order = append(m.order, pass.ID)
+	for len(m.order) > maxManualPasses {
+		delete(m.passes, m.order[0])
+		m.order = m.order[1:]
+	}
+}
+
+// start returns the pending pass, if any, now running
+func (m *manualPasses) start() *ManualPass {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pass := m.pending
+	m.pending = nil
+	if pass != nil {
+		pass.State = ManualPassRunning
+	}
+	return pass
+}
+
+// complete records the outcome of pass
+func (m *manualPasses) complete(pass *ManualPass, report *deploy.Report, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pass.State = ManualPassCompleted
+	pass.CompletedAt = &metav1.Time{Time: time.Now()}
+	pass.Report = report
+	if err != nil {
+		pass.Error = err.Error()
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/version"
+)
+
+const (
+	reloadResultSuccess  = "success"
+	reloadResultRejected = "rejected"
+)
+
+var (
+	passesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "crd_manager_reconciliation_passes_total",
+			Help: "Number of reconciliation passes, by status and trigger (scheduled or manual)",
+		},
+		[]string{"status", "trigger"},
+	)
+
+	passDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "crd_manager_reconciliation_pass_duration_seconds",
+			Help:    "Duration of reconciliation passes",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	crdDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_drift",
+			Help: "1 if the live CRD spec differs from the bundle, the CRD is missing or it could not be " +
+				"processed, 0 if it is in sync",
+		},
+		[]string{"crd"},
+	)
+
+	crdPaused = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_paused",
+			Help: "1 if the live CRD carries the projectsveltos.io/paused annotation and is left untouched",
+		},
+		[]string{"crd"},
+	)
+
+	crdQuarantined = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_quarantined",
+			Help: "1 if the CRD kept failing with the same error and is quarantined: it is not attempted",
+		},
+		[]string{"crd"},
+	)
+
+	crdConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_consecutive_failures",
+			Help: "Number of reconciliation passes in a row processing the CRD failed",
+		},
+		[]string{"crd"},
+	)
+
+	crdNextRetry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_next_retry_timestamp_seconds",
+			Help: "Unix time a failing CRD is processed again, after its backoff",
+		},
+		[]string{"crd"},
+	)
+
+	crdAdmissionDenials = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "crd_manager_crd_admission_denials_total",
+			Help: "Number of reconciliation passes an admission webhook denied the CRD write, by CRD and webhook",
+		},
+		[]string{"crd", "webhook"},
+	)
+
+	crdWarnings = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "crd_manager_crd_warnings_total",
+			Help: "Number of warnings the API server returned while the CRD was processed",
+		},
+		[]string{"crd"},
+	)
+
+	crdSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_crd_size_bytes",
+			Help: "Serialized size, in bytes, of the CRD applied",
+		},
+		[]string{"crd"},
+	)
+
+	circuitBreakerOpen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_circuit_breaker_open",
+			Help: "1 if the last reconciliation pass was stopped by the open circuit breaker",
+		},
+	)
+
+	extraCRDs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_extra_crds",
+			Help: "Number of CRDs managed by crd-manager which are not part of the bundle (observe-only mode)",
+		},
+	)
+
+	bundleInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_bundle_info",
+			Help: "Always 1, labelled with the Sveltos version and the digest of the bundle being deployed",
+		},
+		[]string{"version", "digest"},
+	)
+
+	buildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_build_info",
+			Help: "Always 1, labelled with the crd-manager version, git SHA and build date",
+		},
+		[]string{"version", "git_sha", "build_date"},
+	)
+
+	lastSuccessfulPass = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_last_successful_reconciliation_timestamp_seconds",
+			Help: "Unix time of the last reconciliation pass which processed every CRD successfully",
+		},
+	)
+
+	maintenanceWindowOpen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_maintenance_window_open",
+			Help: "1 inside a maintenance window, where changes are applied, 0 outside, where passes only observe the CRDs",
+		},
+	)
+
+	maintenanceWindowNextStart = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_maintenance_window_next_start_timestamp_seconds",
+			Help: "Unix time of the start of the next maintenance window, 0 if none starts within a year",
+		},
+	)
+
+	configReloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "crd_manager_config_reloads_total",
+			Help: "Number of configuration reloads, by result (success or rejected)",
+		},
+		[]string{"result"},
+	)
+
+	configReloadRejected = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "crd_manager_config_reload_rejected",
+			Help: "1 if the last configuration reload was rejected and the previous configuration is still active",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(passesTotal, passDuration, crdDrift, crdPaused, crdQuarantined, crdConsecutiveFailures,
+		crdNextRetry, crdAdmissionDenials, crdWarnings, crdSize, circuitBreakerOpen, extraCRDs, bundleInfo, buildInfo,
+		lastSuccessfulPass, maintenanceWindowOpen, maintenanceWindowNextStart, configReloadsTotal, configReloadRejected)
+
+	info := version.Get()
+	buildInfo.WithLabelValues(info.Version, info.GitSHA, info.BuildDate).Set(1)
+}
+
+// RecordRun records the metrics of a one-shot run, the same ones a
+// controller pass records, so that they can be pushed once the run ends
+func RecordRun(report *deploy.Report, duration time.Duration) {
+	recordPass(report, duration)
+}
+
+func recordPass(report *deploy.Report, duration time.Duration) {
+	trigger := report.Trigger
+	if trigger == "" {
+		trigger = TriggerScheduled
+	}
+	passesTotal.WithLabelValues(string(report.Status), trigger).Inc()
+	passDuration.Observe(duration.Seconds())
+
+	bundleInfo.Reset()
+	bundleInfo.WithLabelValues(report.BundleVersion, report.BundleDigest).Set(1)
+	recordMaintenanceWindow(report.MaintenanceWindow)
+
+	// A paused pass does not look at the CRDs: the last known drift is kept
+	if report.Status == deploy.RunStatusPaused {
+		return
+	}
+
+	// The bundle may have changed: CRDs no longer part of it must not be reported
+	crdDrift.Reset()
+	crdPaused.Reset()
+	crdQuarantined.Reset()
+	crdConsecutiveFailures.Reset()
+	crdNextRetry.Reset()
+	crdSize.Reset()
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		drift := 1.0
+		if result.Drift == deploy.DriftStatusInSync {
+			drift = 0
+		}
+		crdDrift.WithLabelValues(result.Name).Set(drift)
+		paused := 0.0
+		if result.Action == deploy.ActionPaused {
+			paused = 1
+		}
+		crdPaused.WithLabelValues(result.Name).Set(paused)
+		quarantined := 0.0
+		if result.Action == deploy.ActionQuarantined {
+			quarantined = 1
+		}
+		crdQuarantined.WithLabelValues(result.Name).Set(quarantined)
+		crdConsecutiveFailures.WithLabelValues(result.Name).Set(float64(result.ConsecutiveFailures))
+		if result.NextRetry != nil {
+			crdNextRetry.WithLabelValues(result.Name).Set(float64(result.NextRetry.Unix()))
+		}
+		if result.AdmissionDenial != nil {
+			crdAdmissionDenials.WithLabelValues(result.Name, result.AdmissionDenial.Webhook).Inc()
+		}
+		if result.Size > 0 {
+			crdSize.WithLabelValues(result.Name).Set(float64(result.Size))
+		}
+		if len(result.Warnings) > 0 {
+			crdWarnings.WithLabelValues(result.Name).Add(float64(len(result.Warnings)))
+		}
+	}
+	extraCRDs.Set(float64(len(report.ExtraCRDs)))
+	breakerOpen := 0.0
+	if report.CircuitBreaker != nil {
+		breakerOpen = 1
+	}
+	circuitBreakerOpen.Set(breakerOpen)
+
+	// Only a pass processing every CRD is a full reconciliation
+	if report.Status == deploy.RunStatusSuccess && report.Count(deploy.ActionDeferred) == 0 {
+		lastSuccessfulPass.SetToCurrentTime()
+	}
+}
+
+func recordMaintenanceWindow(window *deploy.MaintenanceWindowStatus) {
+	if window == nil {
+		return
+	}
+	open := 0.0
+	if window.Open {
+		open = 1
+	}
+	maintenanceWindowOpen.Set(open)
+	nextStart := 0.0
+	if window.NextStart != nil {
+		nextStart = float64(window.NextStart.Unix())
+	}
+	maintenanceWindowNextStart.Set(nextStart)
+}
+
+// RecordConfigReload records the outcome of a configuration reload. err is
+// nil when the new configuration was accepted.
+func RecordConfigReload(err error) {
+	if err != nil {
+		configReloadsTotal.WithLabelValues(reloadResultRejected).Inc()
+		configReloadRejected.Set(1)
+		return
+	}
+	configReloadsTotal.WithLabelValues(reloadResultSuccess).Inc()
+	configReloadRejected.Set(0)
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/version"
+)
+
+// crdCounter returns the value of the counter named name for the CRD crd
+func crdCounter(name, crd string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).To(BeNil())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "crd" && label.GetValue() == crd {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// passCount returns the number of passes recorded with status and trigger
+func passCount(status deploy.RunStatus, trigger string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).To(BeNil())
+	for _, family := range families {
+		if family.GetName() != "crd_manager_reconciliation_passes_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["status"] == string(status) && labels["trigger"] == trigger {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func passReport(status deploy.RunStatus, crds ...deploy.CRDResult) *deploy.Report {
+	return &deploy.Report{
+		Status:        status,
+		BundleDigest:  "sha256:abc",
+		BundleVersion: "v1.10.0",
+		CRDs:          crds,
+	}
+}
+
+var _ = Describe("Metrics", func() {
+	It("reports per CRD drift from the pass report", func() {
+		controller.RecordPass(passReport(deploy.RunStatusFailed,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionSkippedHelm, Drift: deploy.DriftStatusDrifted},
+			deploy.CRDResult{Name: "c.projectsveltos.io", Action: deploy.ActionFailed},
+		), time.Second)
+
+		expected := `
+# HELP crd_manager_crd_drift 1 if the live CRD spec differs from the bundle, the CRD is missing or it could not be processed, 0 if it is in sync
+# TYPE crd_manager_crd_drift gauge
+crd_manager_crd_drift{crd="a.projectsveltos.io"} 0
+crd_manager_crd_drift{crd="b.projectsveltos.io"} 1
+crd_manager_crd_drift{crd="c.projectsveltos.io"} 1
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_crd_drift")).To(Succeed())
+	})
+
+	It("forgets CRDs no longer part of the bundle", func() {
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+
+		Expect(testutil.GatherAndCount(metrics.Registry, "crd_manager_crd_drift")).To(Equal(1))
+	})
+
+	It("counts passes by trigger", func() {
+		scheduled := passCount(deploy.RunStatusSuccess, controller.TriggerScheduled)
+		manual := passCount(deploy.RunStatusSuccess, controller.TriggerManual)
+
+		controller.RecordPass(passReport(deploy.RunStatusSuccess), time.Second)
+		report := passReport(deploy.RunStatusSuccess)
+		report.Trigger = controller.TriggerManual
+		controller.RecordPass(report, time.Second)
+
+		Expect(passCount(deploy.RunStatusSuccess, controller.TriggerScheduled)).To(Equal(scheduled + 1))
+		Expect(passCount(deploy.RunStatusSuccess, controller.TriggerManual)).To(Equal(manual + 1))
+	})
+
+	It("keeps the last known drift while paused", func() {
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+		controller.RecordPass(passReport(deploy.RunStatusPaused), time.Second)
+
+		Expect(testutil.GatherAndCount(metrics.Registry, "crd_manager_crd_drift")).To(Equal(1))
+	})
+
+	It("exposes the bundle info and the last successful pass time", func() {
+		before := time.Now().Unix()
+		controller.RecordPass(passReport(deploy.RunStatusSuccess), time.Second)
+
+		expected := `
+# HELP crd_manager_bundle_info Always 1, labelled with the Sveltos version and the digest of the bundle being deployed
+# TYPE crd_manager_bundle_info gauge
+crd_manager_bundle_info{digest="sha256:abc",version="v1.10.0"} 1
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_bundle_info")).To(Succeed())
+
+		families, err := metrics.Registry.Gather()
+		Expect(err).To(BeNil())
+		var timestamp float64
+		for _, family := range families {
+			if family.GetName() == "crd_manager_last_successful_reconciliation_timestamp_seconds" {
+				timestamp = family.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		Expect(timestamp).To(BeNumerically(">=", before))
+	})
+
+	It("exposes the crd-manager build info", func() {
+		info := version.Get()
+		expected := fmt.Sprintf(`
+# HELP crd_manager_build_info Always 1, labelled with the crd-manager version, git SHA and build date
+# TYPE crd_manager_build_info gauge
+crd_manager_build_info{build_date=%q,git_sha=%q,version=%q} 1
+`, info.BuildDate, info.GitSHA, info.Version)
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_build_info")).To(Succeed())
+	})
+
+	It("reports paused CRDs", func() {
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionPaused, Drift: deploy.DriftStatusDrifted},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+
+		expected := `
+# HELP crd_manager_crd_paused 1 if the live CRD carries the projectsveltos.io/paused annotation and is left untouched
+# TYPE crd_manager_crd_paused gauge
+crd_manager_crd_paused{crd="a.projectsveltos.io"} 1
+crd_manager_crd_paused{crd="b.projectsveltos.io"} 0
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_crd_paused")).To(Succeed())
+	})
+
+	It("reports quarantined CRDs", func() {
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionQuarantined, ErrorClass: "Forbidden"},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+
+		expected := `
+# HELP crd_manager_crd_quarantined 1 if the CRD kept failing with the same error and is quarantined: it is not attempted
+# TYPE crd_manager_crd_quarantined gauge
+crd_manager_crd_quarantined{crd="a.projectsveltos.io"} 1
+crd_manager_crd_quarantined{crd="b.projectsveltos.io"} 0
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_crd_quarantined")).To(Succeed())
+	})
+
+	It("reports the circuit breaker stopping the pass", func() {
+		report := passReport(deploy.RunStatusFailed,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionFailed},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionNotAttempted},
+		)
+		report.CircuitBreaker = &deploy.CircuitBreakerStatus{State: deploy.CircuitBreakerOpen,
+			Failing: []string{"a.projectsveltos.io"}}
+		controller.RecordPass(report, time.Second)
+
+		expected := `
+# HELP crd_manager_circuit_breaker_open 1 if the last reconciliation pass was stopped by the open circuit breaker
+# TYPE crd_manager_circuit_breaker_open gauge
+crd_manager_circuit_breaker_open 1
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_circuit_breaker_open")).To(Succeed())
+
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUnchanged, Drift: deploy.DriftStatusInSync},
+		), time.Second)
+		expected = strings.Replace(expected, "\ncrd_manager_circuit_breaker_open 1", "\ncrd_manager_circuit_breaker_open 0", 1)
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_circuit_breaker_open")).To(Succeed())
+	})
+
+	It("reports the maintenance windows", func() {
+		nextStart := time.Date(2026, time.October, 17, 2, 0, 0, 0, time.UTC)
+		report := passReport(deploy.RunStatusSuccess)
+		report.MaintenanceWindow = &deploy.MaintenanceWindowStatus{NextStart: &metav1.Time{Time: nextStart}}
+		controller.RecordPass(report, time.Second)
+
+		expected := fmt.Sprintf(`
+# HELP crd_manager_maintenance_window_next_start_timestamp_seconds Unix time of the start of the next maintenance window, 0 if none starts within a year
+# TYPE crd_manager_maintenance_window_next_start_timestamp_seconds gauge
+crd_manager_maintenance_window_next_start_timestamp_seconds %d
+# HELP crd_manager_maintenance_window_open 1 inside a maintenance window, where changes are applied, 0 outside, where passes only observe the CRDs
+# TYPE crd_manager_maintenance_window_open gauge
+crd_manager_maintenance_window_open 0
+`, nextStart.Unix())
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_maintenance_window_open", "crd_manager_maintenance_window_next_start_timestamp_seconds")).To(Succeed())
+
+		report.MaintenanceWindow = &deploy.MaintenanceWindowStatus{Open: true}
+		controller.RecordPass(report, time.Second)
+		expected = `
+# HELP crd_manager_maintenance_window_open 1 inside a maintenance window, where changes are applied, 0 outside, where passes only observe the CRDs
+# TYPE crd_manager_maintenance_window_open gauge
+crd_manager_maintenance_window_open 1
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_maintenance_window_open")).To(Succeed())
+	})
+
+	It("counts admission webhook denials", func() {
+		// the counter is shared with Runner tests, only the CRDs of this test are checked
+		denials := func(crd string) float64 {
+			return crdCounter("crd_manager_crd_admission_denials_total", crd)
+		}
+
+		report := passReport(deploy.RunStatusFailed,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionFailed,
+				AdmissionDenial: &deploy.AdmissionDenial{Webhook: "validate.kyverno.svc-fail"}},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionFailed},
+		)
+		controller.RecordPass(report, time.Second)
+		controller.RecordPass(report, time.Second)
+
+		Expect(denials("a.projectsveltos.io")).To(Equal(float64(2)))
+		Expect(denials("b.projectsveltos.io")).To(BeZero())
+	})
+
+	It("counts API server warnings", func() {
+		report := passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "warned.projectsveltos.io", Action: deploy.ActionUpdated,
+				Warnings: []string{"deprecated", "unknown field"}},
+			deploy.CRDResult{Name: "quiet.projectsveltos.io", Action: deploy.ActionUpdated},
+		)
+		controller.RecordPass(report, time.Second)
+
+		Expect(crdCounter("crd_manager_crd_warnings_total", "warned.projectsveltos.io")).To(Equal(float64(2)))
+		Expect(crdCounter("crd_manager_crd_warnings_total", "quiet.projectsveltos.io")).To(BeZero())
+	})
+
+	It("reports CRD sizes", func() {
+		controller.RecordPass(passReport(deploy.RunStatusSuccess,
+			deploy.CRDResult{Name: "a.projectsveltos.io", Action: deploy.ActionUpdated, Size: 123456},
+			deploy.CRDResult{Name: "b.projectsveltos.io", Action: deploy.ActionFailed},
+		), time.Second)
+
+		expected := `
+# HELP crd_manager_crd_size_bytes Serialized size, in bytes, of the CRD applied
+# TYPE crd_manager_crd_size_bytes gauge
+crd_manager_crd_size_bytes{crd="a.projectsveltos.io"} 123456
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+			"crd_manager_crd_size_bytes")).To(Succeed())
+	})
+})
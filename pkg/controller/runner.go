@@ -0,0 +1,318 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller runs crd-manager as a long-lived controller, deploying
+// the Sveltos CRDs on every resync period and whenever a resync is triggered,
+// such as when a managed CRD is edited or deleted. CRDs which fail are
+// retried on their own, with an exponential backoff.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultResyncPeriod is the default interval between two reconciliation passes
+	DefaultResyncPeriod = 10 * time.Minute
+
+	// DefaultShutdownTimeout is the default time allowed to delete, on
+	// shutdown, the CRDs created by a Runner
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// PassFunc is invoked at the end of every reconciliation pass with its outcome
+type PassFunc func(report *deploy.Report, err error)
+
+// Runner runs reconciliation passes. It implements the controller-runtime
+// manager.Runnable interface.
+type Runner struct {
+	client       client.Client
+	resyncPeriod time.Duration
+	onPass       PassFunc
+	logger       logr.Logger
+
+	// opts are the options used by the next pass. A pass reads them once, so
+	// that swapping them never affects a pass in flight.
+	opts atomic.Pointer[deploy.Options]
+
+	trigger chan struct{}
+
+	// backoff is only accessed by the Start goroutine
+	backoff *backoffTracker
+
+	// resetBackoff asks the Start goroutine to forget all failures
+	resetBackoff atomic.Bool
+
+	// deleteOnShutdown is, when positive, the time allowed to delete the
+	// created CRDs once Start's context is cancelled
+	deleteOnShutdown time.Duration
+
+	// created lists the CRDs created by this Runner. It is only accessed by
+	// the Start goroutine.
+	created map[string]struct{}
+
+	// windows, when set, restrict the passes applying changes. Outside
+	// them, passes only observe the CRDs, creating the missing ones with
+	// alwaysCreateMissing.
+	windows             *MaintenanceWindows
+	alwaysCreateMissing bool
+
+	// window is the state of the maintenance windows at the last pass. It
+	// is only accessed by the Start goroutine.
+	window *deploy.MaintenanceWindowStatus
+
+	// manual tracks the passes requested through RequestPass
+	manual manualPasses
+}
+
+// NewRunner returns a Runner deploying, with opts, the Sveltos CRDs to the
+// cluster c points to every resyncPeriod. onPass can be nil.
+func NewRunner(c client.Client, opts *deploy.Options, resyncPeriod time.Duration, onPass PassFunc,
+	logger logr.Logger) *Runner {
+
+	if resyncPeriod <= 0 {
+		resyncPeriod = DefaultResyncPeriod
+	}
+	r := &Runner{
+		client:       c,
+		resyncPeriod: resyncPeriod,
+		onPass:       onPass,
+		logger:       logger,
+		trigger:      make(chan struct{}, 1),
+		backoff:      newBackoffTracker(DefaultBackoffBase, DefaultBackoffMax),
+		created:      map[string]struct{}{},
+		manual:       manualPasses{passes: map[string]*ManualPass{}},
+	}
+	r.opts.Store(opts)
+	return r
+}
+
+// Options returns the options used by the next pass
+func (r *Runner) Options() *deploy.Options {
+	return r.opts.Load()
+}
+
+// SetOptions atomically replaces the options and triggers an immediate resync
+// of every CRD, including the ones backing off after failures, as the new
+// options may fix them. A pass in flight completes with the previous options.
+func (r *Runner) SetOptions(opts *deploy.Options) {
+	r.opts.Store(opts)
+	r.resetBackoff.Store(true)
+	r.Trigger()
+}
+
+// DeleteOnShutdown makes Start delete, once its context is cancelled, the
+// CRDs the Runner created, spending at most timeout. CRDs which existed
+// before, or are managed by another tool by then, are left in place. It must
+// be called before Start.
+func (r *Runner) DeleteOnShutdown(timeout time.Duration) {
+	r.deleteOnShutdown = timeout
+}
+
+// SetMaintenanceWindows restricts the passes applying changes to windows.
+// Outside them, passes run in observe-only mode, reporting the drift, and
+// still create the missing CRDs with alwaysCreateMissing. A full pass runs
+// as soon as a window opens. It must be called before Start.
+func (r *Runner) SetMaintenanceWindows(windows *MaintenanceWindows, alwaysCreateMissing bool) {
+	r.windows = windows
+	r.alwaysCreateMissing = alwaysCreateMissing
+}
+
+// Trigger requests an immediate pass. Requests received while a pass is
+// already pending are coalesced.
+func (r *Runner) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs a pass immediately, then on every resync period or trigger,
+// until ctx is cancelled. In between, passes retry the failing CRDs whose
+// backoff expired, and a full pass follows the cool-down of an open
+// circuit breaker.
+func (r *Runner) Start(ctx context.Context) error {
+	timer := time.NewTimer(r.resyncPeriod)
+	defer timer.Stop()
+	if r.deleteOnShutdown > 0 {
+		defer r.deleteCreated()
+	}
+
+	nextResync := time.Now()
+	for {
+		// select picks randomly among ready cases: never start a pass once cancelled
+		if ctx.Err() != nil {
+			return nil
+		}
+		if r.resetBackoff.Swap(false) {
+			r.backoff.reset()
+		}
+
+		now := time.Now()
+		window, opened := r.windowStatus(now)
+		manual := r.manual.start()
+		full := opened || manual != nil || !now.Before(nextResync)
+		report := r.pass(ctx, now, full, window, manual)
+		if full {
+			nextResync = now.Add(r.resyncPeriod)
+		}
+		// the CRDs an open circuit breaker did not attempt are all processed
+		// once its cool-down expires
+		if report != nil && report.CircuitBreaker != nil && report.CircuitBreaker.OpenUntil.Time.Before(nextResync) {
+			nextResync = report.CircuitBreaker.OpenUntil.Time
+		}
+
+		wakeUp := r.wakeUp(nextResync, window)
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(wakeUp))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		case <-r.trigger:
+			nextResync = time.Now()
+		}
+	}
+}
+
+// wakeUp returns when the next pass is due: at the next resync, unless a
+// failing CRD is retried or a maintenance window opens before
+func (r *Runner) wakeUp(nextResync time.Time, window *deploy.MaintenanceWindowStatus) time.Time {
+	wakeUp := nextResync
+	if retry, ok := r.backoff.nextRetry(); ok && retry.Before(wakeUp) {
+		wakeUp = retry
+	}
+	if window != nil && !window.Open && window.NextStart != nil && window.NextStart.Time.Before(wakeUp) {
+		wakeUp = window.NextStart.Time
+	}
+	return wakeUp
+}
+
+// windowStatus returns the state of the maintenance windows at now, nil
+// without windows, and whether a window opened since the last pass
+func (r *Runner) windowStatus(now time.Time) (*deploy.MaintenanceWindowStatus, bool) {
+	if r.windows == nil {
+		return nil, false
+	}
+	window := r.windows.Status(now)
+	previous := r.window
+	r.window = window
+	if previous != nil && previous.Open == window.Open {
+		return window, false
+	}
+
+	switch {
+	case window.Open && window.ClosesAt != nil:
+		r.logger.V(logs.LogInfo).Info(fmt.Sprintf("inside maintenance window until %s: applying changes",
+			window.ClosesAt.Format(time.RFC3339)))
+	case window.Open:
+		r.logger.V(logs.LogInfo).Info("inside maintenance window: applying changes")
+	case window.NextStart != nil:
+		r.logger.V(logs.LogInfo).Info(fmt.Sprintf("outside maintenance windows until %s: only observing the CRDs",
+			window.NextStart.Format(time.RFC3339)))
+	default:
+		r.logger.V(logs.LogInfo).Info("outside maintenance windows, none starting within a year: only observing the CRDs")
+	}
+	return window, window.Open && previous != nil
+}
+
+// pass runs a reconciliation pass. manual is, for a pass requested through
+// RequestPass, the request it fulfills: the pass then only differs from a
+// scheduled one by its trigger attribute, in logs, report and metrics.
+func (r *Runner) pass(ctx context.Context, now time.Time, full bool, window *deploy.MaintenanceWindowStatus,
+	manual *ManualPass) *deploy.Report {
+
+	start := time.Now()
+	logger := r.logger
+	if manual != nil {
+		logger = logger.WithValues("trigger", TriggerManual, "run", manual.ID)
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("starting reconciliation pass (full: %t)", full))
+
+	opts := *r.opts.Load()
+	opts.Defer = r.backoff.deferFunc(now, full)
+	if window != nil && !window.Open && !opts.ObserveOnly {
+		opts.ObserveOnly = true
+		opts.CreateMissing = r.alwaysCreateMissing
+	}
+	report, err := deploy.Deploy(ctx, r.client, &opts, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("reconciliation pass failed: %v", err))
+	}
+	report.MaintenanceWindow = window
+	if manual != nil {
+		report.Trigger = TriggerManual
+	}
+	r.backoff.update(report, now)
+	r.trackCreated(report)
+	recordPass(report, time.Since(start))
+
+	if r.onPass != nil {
+		r.onPass(report, err)
+	}
+	if manual != nil {
+		r.manual.complete(manual, report, err)
+	}
+	return report
+}
+
+func (r *Runner) trackCreated(report *deploy.Report) {
+	if report == nil {
+		return
+	}
+	for i := range report.CRDs {
+		if report.CRDs[i].Action == deploy.ActionCreated {
+			r.created[report.CRDs[i].Name] = struct{}{}
+		}
+	}
+}
+
+// deleteCreated deletes the CRDs created by the Runner. It runs once Start's
+// context is cancelled, hence on a fresh one bounded by deleteOnShutdown.
+func (r *Runner) deleteCreated() {
+	if len(r.created) == 0 {
+		return
+	}
+	names := make([]string, 0, len(r.created))
+	for name := range r.created {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.deleteOnShutdown)
+	defer cancel()
+
+	r.logger.V(logs.LogInfo).Info(fmt.Sprintf("shutting down: deleting %d created CRDs", len(names)))
+	if err := deploy.DeleteCreatedCRDs(ctx, r.client, names, r.opts.Load(), r.logger); err != nil {
+		r.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to delete created CRDs: %v", err))
+	}
+}
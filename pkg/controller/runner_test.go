@@ -0,0 +1,274 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Runner", func() {
+	var reports chan *deploy.Report
+	var cancel context.CancelFunc
+	var ctx context.Context
+
+	BeforeEach(func() {
+		reports = make(chan *deploy.Report, 10)
+		ctx, cancel = context.WithCancel(context.TODO())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	// onPass returns a PassFunc sending reports to the channel of the current spec
+	onPass := func() controller.PassFunc {
+		ch := reports
+		return func(report *deploy.Report, _ error) {
+			ch <- report
+		}
+	}
+
+	It("runs a pass at startup and on every resync period", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, 100*time.Millisecond, onPass(), logger)
+		go func() { _ = runner.Start(ctx) }()
+
+		var first *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&first))
+		Expect(first.Count(deploy.ActionCreated)).To(Equal(len(first.CRDs)))
+
+		var second *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&second))
+		Expect(second.Count(deploy.ActionUnchanged)).To(Equal(len(second.CRDs)))
+	})
+
+	It("uses new options on an immediate pass once they are swapped", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, onPass(), logger)
+		go func() { _ = runner.Start(ctx) }()
+
+		Eventually(reports, 5*time.Second).Should(Receive())
+
+		runner.SetOptions(&deploy.Options{Category: "sveltos"})
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionUpdated)).To(Equal(len(report.CRDs)))
+		Expect(runner.Options().Category).To(Equal("sveltos"))
+	})
+
+	It("coalesces triggers received while a pass is pending", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, onPass(), logger)
+		runner.Trigger()
+		runner.Trigger()
+		runner.Trigger()
+		go func() { _ = runner.Start(ctx) }()
+
+		Eventually(reports, 5*time.Second).Should(Receive())
+		Eventually(reports, 5*time.Second).Should(Receive())
+		Consistently(reports, 300*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("coalesces manual passes requested while one is pending", func() {
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, onPass(), logger)
+
+		first := runner.RequestPass()
+		Expect(first.State).To(Equal(controller.ManualPassPending))
+		Expect(runner.RequestPass().ID).To(Equal(first.ID))
+
+		go func() { _ = runner.Start(ctx) }()
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Trigger).To(Equal(controller.TriggerManual))
+		Eventually(func() controller.ManualPassState {
+			pass, ok := runner.ManualPass(first.ID)
+			Expect(ok).To(BeTrue())
+			return pass.State
+		}, 5*time.Second).Should(Equal(controller.ManualPassCompleted))
+
+		Expect(runner.RequestPass().ID).ToNot(Equal(first.ID))
+	})
+
+	It("runs a full pass once the circuit breaker cool-down expires", func() {
+		var failures atomic.Int32
+		failures.Store(2)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if failures.Add(-1) >= 0 {
+					return apierrors.NewServiceUnavailable("overloaded")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+		opts := &deploy.Options{CircuitBreaker: &deploy.CircuitBreaker{Threshold: 2, CoolDown: 200 * time.Millisecond}}
+		runner := controller.NewRunner(c, opts, time.Hour, onPass(), logger)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.CircuitBreaker).ToNot(BeNil())
+		Expect(report.Count(deploy.ActionNotAttempted)).To(Equal(len(report.CRDs) - 2))
+
+		// well before the backoff of the failing CRDs expires
+		Eventually(reports, 2*time.Second).Should(Receive(&report))
+		Expect(report.CircuitBreaker).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs) - 2))
+		Expect(report.Count(deploy.ActionDeferred)).To(Equal(2))
+	})
+
+	It("deletes on shutdown only the CRDs it created", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(ctx, c, &deploy.Options{}, logger)
+		Expect(err).To(BeNil())
+
+		crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(ctx, crdList)).To(Succeed())
+		Expect(len(crdList.Items)).To(BeNumerically(">", 2))
+		created := crdList.Items[0].Name
+		foreign := crdList.Items[1].Name
+		kept := crdList.Items[2].Name
+		for _, name := range []string{created, foreign} {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: name}, crd)).To(Succeed())
+			Expect(c.Delete(ctx, crd)).To(Succeed())
+		}
+
+		runner := controller.NewRunner(c, &deploy.Options{}, time.Hour, onPass(), logger)
+		runner.DeleteOnShutdown(5 * time.Second)
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = runner.Start(runCtx)
+		}()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(2))
+
+		// another tool takes over one of the created CRDs
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: foreign}, crd)).To(Succeed())
+		crd.Labels["app.kubernetes.io/managed-by"] = "Helm"
+		Expect(c.Update(ctx, crd)).To(Succeed())
+
+		stop()
+		Eventually(done, 5*time.Second).Should(BeClosed())
+
+		err = c.Get(ctx, client.ObjectKey{Name: created}, &apiextensionsv1.CustomResourceDefinition{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		for _, name := range []string{foreign, kept} {
+			Expect(c.Get(ctx, client.ObjectKey{Name: name}, &apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+		}
+	})
+
+	It("keeps the CRDs it created on shutdown unless asked to delete them", func() {
+		c := newFakeClient()
+		runner := controller.NewRunner(c, &deploy.Options{}, time.Hour, onPass(), logger)
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = runner.Start(runCtx)
+		}()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		stop()
+		Eventually(done, 5*time.Second).Should(BeClosed())
+
+		crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(ctx, crdList)).To(Succeed())
+		Expect(crdList.Items).To(HaveLen(report.Count(deploy.ActionCreated)))
+	})
+
+	It("only observes the CRDs outside maintenance windows", func() {
+		// a window starting in two hours
+		start := time.Now().UTC().Add(2 * time.Hour)
+		windows, err := controller.ParseMaintenanceWindows(
+			[]string{fmt.Sprintf("%d %d * * * 1h", start.Minute(), start.Hour())}, "")
+		Expect(err).To(BeNil())
+
+		c := newFakeClient()
+		runner := controller.NewRunner(c, &deploy.Options{}, time.Hour, onPass(), logger)
+		runner.SetMaintenanceWindows(windows, false)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionObserved)).To(Equal(len(report.CRDs)))
+		Expect(report.MaintenanceWindow).ToNot(BeNil())
+		Expect(report.MaintenanceWindow.Open).To(BeFalse())
+		Expect(report.MaintenanceWindow.NextStart.Time).To(BeTemporally("~", start, time.Minute))
+
+		crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(ctx, crdList)).To(Succeed())
+		Expect(crdList.Items).To(BeEmpty())
+	})
+
+	It("creates the missing CRDs outside maintenance windows when asked to", func() {
+		start := time.Now().UTC().Add(2 * time.Hour)
+		windows, err := controller.ParseMaintenanceWindows(
+			[]string{fmt.Sprintf("%d %d * * * 1h", start.Minute(), start.Hour())}, "")
+		Expect(err).To(BeNil())
+
+		c := newFakeClient()
+		_, err = deploy.Deploy(ctx, c, &deploy.Options{}, logger)
+		Expect(err).To(BeNil())
+		crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(ctx, crdList)).To(Succeed())
+		missing := crdList.Items[0].Name
+		Expect(c.Delete(ctx, &crdList.Items[0])).To(Succeed())
+
+		// drift on the existing CRDs is not fixed
+		runner := controller.NewRunner(c, &deploy.Options{Category: "sveltos"}, time.Hour, onPass(), logger)
+		runner.SetMaintenanceWindows(windows, true)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(1))
+		Expect(report.Count(deploy.ActionObserved)).To(Equal(len(report.CRDs) - 1))
+		Expect(report.CountDrift(deploy.ActionObserved, deploy.DriftStatusDrifted)).To(Equal(len(report.CRDs) - 1))
+		Expect(c.Get(ctx, client.ObjectKey{Name: missing}, &apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("applies changes inside maintenance windows", func() {
+		windows, err := controller.ParseMaintenanceWindows([]string{"00:00-24:00"}, "")
+		Expect(err).To(BeNil())
+
+		runner := controller.NewRunner(newFakeClient(), &deploy.Options{}, time.Hour, onPass(), logger)
+		runner.SetMaintenanceWindows(windows, false)
+		go func() { _ = runner.Start(ctx) }()
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+		Expect(report.MaintenanceWindow.Open).To(BeTrue())
+	})
+})
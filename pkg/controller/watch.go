@@ -0,0 +1,101 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// CRDWatcher triggers a pass of a Runner whenever a CRD managed by
+// crd-manager is edited or deleted, so that the bundle definition is
+// restored without waiting for the next resync period.
+type CRDWatcher struct {
+	informers cache.Informers
+	runner    *Runner
+	logger    logr.Logger
+}
+
+// NewCRDWatcher returns a CRDWatcher watching the CRDs of the informers c,
+// such as the manager cache restricted by CacheOptions, and triggering
+// runner. It must be added to the manager starting c.
+func NewCRDWatcher(c cache.Informers, runner *Runner, logger logr.Logger) *CRDWatcher {
+	return &CRDWatcher{informers: c, runner: runner, logger: logger}
+}
+
+// Start registers the CRD event handlers. It implements manager.Runnable.
+func (w *CRDWatcher) Start(ctx context.Context) error {
+	informer, err := w.informers.GetInformer(ctx, &apiextensionsv1.CustomResourceDefinition{})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to start the CRD informer: %w", err)
+	}
+
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: w.onUpdate,
+		DeleteFunc: w.onDelete,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch CRDs: %w", err)
+	}
+	w.logger.V(logs.LogInfo).Info("watching managed CRDs: edits and deletions trigger a pass")
+	return nil
+}
+
+// onUpdate triggers a pass when the spec, labels or annotations of a CRD
+// changed. Status updates, such as the API server ones, are ignored. The
+// writes of the Runner itself trigger a pass too, which finds nothing to do.
+func (w *CRDWatcher) onUpdate(oldObj, newObj any) {
+	previous, ok := oldObj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return
+	}
+	current, ok := newObj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return
+	}
+	if previous.Generation == current.Generation && maps.Equal(previous.Labels, current.Labels) &&
+		maps.Equal(previous.Annotations, current.Annotations) {
+		return
+	}
+	w.logger.V(logs.LogDebug).Info(fmt.Sprintf("CRD %s changed: triggering a pass", current.Name))
+	w.runner.Trigger()
+}
+
+// onDelete triggers a pass when a CRD is deleted or loses the crd-manager
+// ownership label, hence leaves the cache
+func (w *CRDWatcher) onDelete(obj any) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	name := "unknown"
+	if crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+		name = crd.Name
+	}
+	w.logger.V(logs.LogInfo).Info(fmt.Sprintf("CRD %s deleted or no longer managed: triggering a pass", name))
+	w.runner.Trigger()
+}
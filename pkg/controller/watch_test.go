@@ -0,0 +1,111 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("CRDWatcher", func() {
+	var reports chan *deploy.Report
+	var cancel context.CancelFunc
+	var ctx context.Context
+	var c client.Client
+	var informer *controllertest.FakeInformer
+
+	BeforeEach(func() {
+		reports = make(chan *deploy.Report, 10)
+		ctx, cancel = context.WithCancel(context.TODO())
+		c = newFakeClient()
+
+		informers := &informertest.FakeInformers{Scheme: scheme}
+		var err error
+		informer, err = informers.FakeInformerFor(ctx, &apiextensionsv1.CustomResourceDefinition{})
+		Expect(err).To(BeNil())
+
+		ch := reports
+		runner := controller.NewRunner(c, &deploy.Options{}, time.Hour, func(report *deploy.Report, _ error) {
+			ch <- report
+		}, logger)
+		Expect(controller.NewCRDWatcher(informers, runner, logger).Start(ctx)).To(Succeed())
+		go func() { _ = runner.Start(ctx) }()
+
+		// The first pass creates the CRDs
+		Eventually(reports, 5*time.Second).Should(Receive())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	// liveCRD returns the first CRD of the bundle, as deployed
+	liveCRD := func() *apiextensionsv1.CustomResourceDefinition {
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(ctx, crds)).To(Succeed())
+		Expect(crds.Items).ToNot(BeEmpty())
+		return &crds.Items[0]
+	}
+
+	It("restores a deleted CRD", func() {
+		crd := liveCRD()
+		Expect(c.Delete(ctx, crd)).To(Succeed())
+		informer.Delete(crd)
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(1))
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(crd), &apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("restores an edited CRD", func() {
+		crd := liveCRD()
+		edited := crd.DeepCopy()
+		edited.Spec.Names.ShortNames = append(edited.Spec.Names.ShortNames, "edited")
+		edited.Generation++
+		Expect(c.Update(ctx, edited)).To(Succeed())
+		informer.Update(crd, edited)
+
+		var report *deploy.Report
+		Eventually(reports, 5*time.Second).Should(Receive(&report))
+		Expect(report.Count(deploy.ActionUpdated)).To(Equal(1))
+
+		restored := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(crd), restored)).To(Succeed())
+		Expect(restored.Spec.Names.ShortNames).ToNot(ContainElement("edited"))
+	})
+
+	It("ignores status updates", func() {
+		crd := liveCRD()
+		updated := crd.DeepCopy()
+		updated.Status.StoredVersions = append(updated.Status.StoredVersions, "v1alpha1")
+		informer.Update(crd, updated)
+
+		Consistently(reports, time.Second).ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,413 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// windowHorizon is how far ahead maintenance windows are looked at
+	windowHorizon = 366 * 24 * time.Hour
+
+	// maxWindowDuration is the maximum duration of cron maintenance windows
+	maxWindowDuration = 7 * 24 * time.Hour
+)
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// MaintenanceWindows are the recurring periods in which a Runner applies
+// changes. Outside them, its passes only observe the CRDs.
+type MaintenanceWindows struct {
+	// Windows are the maintenance windows
+	Windows []*MaintenanceWindow
+
+	// Location is the time zone of the windows. Defaults to UTC.
+	Location *time.Location
+}
+
+// ParseMaintenanceWindows parses specs, maintenance windows in the time zone
+// named timezone (an IANA name, UTC when empty). It returns nil for no specs.
+func ParseMaintenanceWindows(specs []string, timezone string) (*MaintenanceWindows, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window time zone: %w", err)
+		}
+	}
+
+	windows := &MaintenanceWindows{Location: location}
+	for _, spec := range specs {
+		w, err := ParseMaintenanceWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows.Windows = append(windows.Windows, w)
+	}
+	return windows, nil
+}
+
+// Status returns whether now is inside a maintenance window, when it closes
+// and when the next one starts. Overlapping and back to back windows are one.
+func (m *MaintenanceWindows) Status(now time.Time) *deploy.MaintenanceWindowStatus {
+	location := m.Location
+	if location == nil {
+		location = time.UTC
+	}
+	now = now.In(location)
+
+	status := &deploy.MaintenanceWindowStatus{}
+	after := now
+	if end, open := m.closesAt(now); open {
+		status.Open = true
+		if end.Sub(now) > windowHorizon {
+			// always open
+			return status
+		}
+		status.ClosesAt = &metav1.Time{Time: end}
+		after = end
+	}
+
+	var next time.Time
+	for _, w := range m.Windows {
+		if start, ok := w.nextStart(after); ok && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	if !next.IsZero() {
+		status.NextStart = &metav1.Time{Time: next}
+	}
+	return status
+}
+
+// closesAt returns, if now is inside a window, when the windows containing
+// now, or following it back to back, close
+func (m *MaintenanceWindows) closesAt(now time.Time) (time.Time, bool) {
+	end, open := now, false
+	for extended := true; extended && end.Sub(now) <= windowHorizon; {
+		extended = false
+		for _, w := range m.Windows {
+			if spanEnd, ok := w.span(end); ok && spanEnd.After(end) {
+				end, open, extended = spanEnd, true, true
+			}
+		}
+	}
+	return end, open
+}
+
+// MaintenanceWindow is a recurring period in which a Runner applies changes.
+// It is either a weekday/time range, such as "Mon-Fri 22:00-06:00" (the
+// weekdays, all of them when omitted, are the ones the window starts on and
+// a range ending before its start ends the next day), or a cron schedule of
+// the window starts followed by the window duration, such as "0 2 * * 6 4h".
+type MaintenanceWindow struct {
+	spec string
+
+	// days, start and end, in minutes since midnight, define weekday/time
+	// range windows
+	days       [7]bool
+	start, end int
+
+	// schedule and duration define cron windows
+	schedule *cronSchedule
+	duration time.Duration
+}
+
+// ParseMaintenanceWindow parses a weekday/time range or cron maintenance window
+func ParseMaintenanceWindow(spec string) (*MaintenanceWindow, error) {
+	fields := strings.Fields(spec)
+	w := &MaintenanceWindow{spec: strings.Join(fields, " ")}
+	var err error
+	switch len(fields) {
+	case 1, 2:
+		err = w.parseTimeRange(fields)
+	case len(cronFields) + 1:
+		err = w.parseCron(fields)
+	default:
+		err = errors.New("expected [weekdays] HH:MM-HH:MM, or a 5 fields cron schedule followed by a duration")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+	}
+	return w, nil
+}
+
+func (w *MaintenanceWindow) String() string {
+	return w.spec
+}
+
+func (w *MaintenanceWindow) parseTimeRange(fields []string) error {
+	if len(fields) == 1 {
+		for i := range w.days {
+			w.days[i] = true
+		}
+	} else if err := parseWeekdays(fields[0], &w.days); err != nil {
+		return err
+	}
+
+	timeRange := fields[len(fields)-1]
+	from, to, ok := strings.Cut(timeRange, "-")
+	if !ok {
+		return fmt.Errorf("invalid time range %s, expected HH:MM-HH:MM", timeRange)
+	}
+	var err error
+	if w.start, err = parseClock(from, false); err != nil {
+		return err
+	}
+	if w.end, err = parseClock(to, true); err != nil {
+		return err
+	}
+	if w.start == w.end {
+		return errors.New("the time range is empty")
+	}
+	return nil
+}
+
+func (w *MaintenanceWindow) parseCron(fields []string) error {
+	var err error
+	if w.schedule, err = parseCronSchedule(fields[:len(cronFields)]); err != nil {
+		return err
+	}
+	if w.duration, err = time.ParseDuration(fields[len(cronFields)]); err != nil {
+		return fmt.Errorf("invalid duration: %w", err)
+	}
+	if w.duration < time.Minute || w.duration > maxWindowDuration {
+		return fmt.Errorf("the duration must be between 1m and %s", maxWindowDuration)
+	}
+	return nil
+}
+
+// span returns, if t is inside w, when the occurrence of w containing t ends
+func (w *MaintenanceWindow) span(t time.Time) (time.Time, bool) {
+	if w.schedule != nil {
+		// the latest start ends last
+		for start := t.Truncate(time.Minute); t.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+			if local := start.In(t.Location()); w.schedule.matchesDay(local) && w.schedule.matchesTime(local) {
+				return start.Add(w.duration), true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	day := int(t.Weekday())
+	y, m, d := t.Date()
+	switch {
+	case w.start < w.end && w.days[day] && minute >= w.start && minute < w.end:
+		return clockTime(y, m, d, w.end, t.Location()), true
+	case w.end < w.start && w.days[day] && minute >= w.start:
+		return clockTime(y, m, d+1, w.end, t.Location()), true
+	case w.end < w.start && w.days[(day+6)%7] && minute < w.end:
+		return clockTime(y, m, d, w.end, t.Location()), true
+	}
+	return time.Time{}, false
+}
+
+// nextStart returns the first start of w after t, if any within windowHorizon
+func (w *MaintenanceWindow) nextStart(t time.Time) (time.Time, bool) {
+	if w.schedule == nil {
+		y, m, d := t.Date()
+		for offset := 0; offset <= len(w.days); offset++ {
+			start := clockTime(y, m, d+offset, w.start, t.Location())
+			if w.days[start.Weekday()] && start.After(t) {
+				return start, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	limit := t.Add(windowHorizon)
+	for start := t.Truncate(time.Minute).Add(time.Minute); !start.After(limit); {
+		local := start.In(t.Location())
+		if !w.schedule.matchesDay(local) {
+			y, m, d := local.Date()
+			start = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if w.schedule.matchesTime(local) {
+			return start, true
+		}
+		start = start.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
+
+// clockTime returns the time minutes after the midnight starting the given day
+func clockTime(y int, m time.Month, d, minutes int, location *time.Location) time.Time {
+	return time.Date(y, m, d, minutes/60, minutes%60, 0, 0, location)
+}
+
+// parseClock parses HH:MM into minutes since midnight. 24:00 is only valid
+// as the end of a range.
+func parseClock(value string, end bool) (int, error) {
+	if end && value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseWeekdays parses a comma-separated list of weekdays (Mon, Tue...) and
+// weekday ranges (Mon-Fri, Fri-Mon) into days
+func parseWeekdays(value string, days *[7]bool) error {
+	for part := range strings.SplitSeq(value, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := parseWeekday(first)
+		if err != nil {
+			return err
+		}
+		to := from
+		if isRange {
+			if to, err = parseWeekday(last); err != nil {
+				return err
+			}
+		}
+		for day := from; ; day = (day + 1) % len(days) {
+			days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseWeekday(name string) (int, error) {
+	day := slices.Index(weekdayNames, strings.ToLower(name))
+	if day < 0 {
+		return 0, fmt.Errorf("invalid weekday %q, expected Mon, Tue, Wed, Thu, Fri, Sat or Sun", name)
+	}
+	return day, nil
+}
+
+// cronFields are the fields of a cron schedule, with their bounds
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59}, {"hour", 0, 23}, {"day of month", 1, 31}, {"month", 1, 12}, {"day of week", 0, 7},
+}
+
+// cronSchedule is a standard cron schedule: minute, hour, day of month, month
+// and day of week (0 or 7 is Sunday), each a set of values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny are true when the field is *: when both are
+	// restricted, either one matching is enough
+	domAny, dowAny bool
+}
+
+func parseCronSchedule(fields []string) (*cronSchedule, error) {
+	values := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		bits, err := parseCronField(fields[i], field.min, field.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %s %q: %w", field.name, fields[i], err)
+		}
+		values[i] = bits
+	}
+
+	s := &cronSchedule{
+		minute: values[0], hour: values[1], dom: values[2], month: values[3], dow: values[4],
+		domAny: strings.HasPrefix(fields[2], "*"), dowAny: strings.HasPrefix(fields[4], "*"),
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b) and
+// steps (*/n, a-b/n, a/n) into the set of values they match
+func parseCronField(field string, minValue, maxValue int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		values, stepValue, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %s", stepValue)
+			}
+		}
+
+		from, to := minValue, maxValue
+		if values != "*" {
+			first, last, isRange := strings.Cut(values, "-")
+			var err error
+			if from, err = parseCronValue(first, minValue, maxValue); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = parseCronValue(last, from, maxValue); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = maxValue
+			}
+		}
+		for value := from; value <= to; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, minValue, maxValue int) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < minValue || v > maxValue {
+		return 0, fmt.Errorf("%s is not between %d and %d", value, minValue, maxValue)
+	}
+	return v, nil
+}
+
+// matchesDay returns true if the schedule has starts on the day of t
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	if s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// matchesTime returns true if the schedule has a start at the hour and minute of t
+func (s *cronSchedule) matchesTime(t time.Time) bool {
+	return s.minute&(1<<t.Minute()) != 0 && s.hour&(1<<t.Hour()) != 0
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/controller"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// windowStatus returns the state at now of the maintenance windows specs, in UTC
+func windowStatus(now time.Time, specs ...string) *deploy.MaintenanceWindowStatus {
+	windows, err := controller.ParseMaintenanceWindows(specs, "")
+	Expect(err).To(BeNil())
+	return windows.Status(now)
+}
+
+func utc(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+// expectOpen verifies status is inside a maintenance window closing at closesAt
+func expectOpen(status *deploy.MaintenanceWindowStatus, closesAt time.Time) {
+	Expect(status.Open).To(BeTrue())
+	Expect(status.ClosesAt).ToNot(BeNil())
+	Expect(status.ClosesAt.Time).To(BeTemporally("==", closesAt))
+}
+
+// expectClosed verifies status is outside maintenance windows until nextStart
+func expectClosed(status *deploy.MaintenanceWindowStatus, nextStart time.Time) {
+	Expect(status.Open).To(BeFalse())
+	Expect(status.ClosesAt).To(BeNil())
+	Expect(status.NextStart).ToNot(BeNil())
+	Expect(status.NextStart.Time).To(BeTemporally("==", nextStart))
+}
+
+var _ = Describe("Maintenance windows", func() {
+	It("open weekday/time ranges on the listed weekdays, ending the next day when crossing midnight", func() {
+		spec := "Mon-Fri 22:00-06:00"
+
+		// Friday evening, then Saturday morning
+		expectOpen(windowStatus(utc(time.October, 16, 23, 0), spec), utc(time.October, 17, 6, 0))
+		expectOpen(windowStatus(utc(time.October, 17, 5, 59), spec), utc(time.October, 17, 6, 0))
+		expectClosed(windowStatus(utc(time.October, 17, 6, 0), spec), utc(time.October, 19, 22, 0))
+		// Monday morning: no window starts on Sunday
+		expectClosed(windowStatus(utc(time.October, 19, 3, 0), spec), utc(time.October, 19, 22, 0))
+	})
+
+	It("open every day without weekdays", func() {
+		status := windowStatus(utc(time.October, 14, 12, 0), "02:00-04:00")
+		expectClosed(status, utc(time.October, 15, 2, 0))
+		expectOpen(windowStatus(utc(time.October, 15, 2, 0), "02:00-04:00"), utc(time.October, 15, 4, 0))
+	})
+
+	It("merge overlapping and back to back windows", func() {
+		status := windowStatus(utc(time.October, 14, 23, 0), "Wed 22:00-24:00", "Thu 00:00-02:00", "Thu 01:00-03:00")
+		expectOpen(status, utc(time.October, 15, 3, 0))
+		// the next start is the one of a window not overlapping the current one
+		Expect(status.NextStart.Time).To(BeTemporally("==", utc(time.October, 21, 22, 0)))
+	})
+
+	It("report windows which never close", func() {
+		status := windowStatus(utc(time.October, 14, 12, 0), "00:00-24:00")
+		Expect(status.Open).To(BeTrue())
+		Expect(status.ClosesAt).To(BeNil())
+		Expect(status.NextStart).To(BeNil())
+	})
+
+	It("open cron windows at the scheduled starts for the given duration", func() {
+		spec := "0 2 * * 6 4h"
+		expectClosed(windowStatus(utc(time.October, 14, 12, 0), spec), utc(time.October, 17, 2, 0))
+		expectOpen(windowStatus(utc(time.October, 17, 3, 30), spec), utc(time.October, 17, 6, 0))
+
+		// cron steps, ranges, lists and names
+		expectClosed(windowStatus(utc(time.October, 14, 12, 5), "*/30 9-11,13 * * 1-5 10m"), utc(time.October, 14, 13, 0))
+	})
+
+	It("match cron windows on either the day of month or the day of week when both are restricted", func() {
+		spec := "0 0 1 * 1 1h"
+		// the next Monday
+		expectClosed(windowStatus(utc(time.October, 14, 12, 0), spec), utc(time.October, 19, 0, 0))
+		// the first of November, a Sunday
+		expectClosed(windowStatus(utc(time.October, 27, 12, 0), spec), utc(time.November, 1, 0, 0))
+	})
+
+	It("only look a year ahead for the next window", func() {
+		// next February 29th is in 2028
+		status := windowStatus(utc(time.October, 14, 12, 0), "0 0 29 2 * 1h")
+		Expect(status.Open).To(BeFalse())
+		Expect(status.NextStart).To(BeNil())
+	})
+
+	It("are evaluated in their time zone", func() {
+		windows, err := controller.ParseMaintenanceWindows([]string{"Mon-Fri 22:00-06:00"}, "Europe/Paris")
+		Expect(err).To(BeNil())
+
+		// 23:00 in Paris (CEST)
+		status := windows.Status(utc(time.October, 16, 21, 0))
+		expectOpen(status, utc(time.October, 17, 4, 0))
+		Expect(status.NextStart.Time).To(BeTemporally("==", utc(time.October, 19, 20, 0)))
+		// after the end of daylight saving time, on October 25th (CET)
+		expectClosed(windows.Status(utc(time.October, 26, 12, 0)), utc(time.October, 26, 21, 0))
+
+		_, err = controller.ParseMaintenanceWindows([]string{"22:00-06:00"}, "Mars/Olympus_Mons")
+		Expect(err).ToNot(BeNil())
+	})
+
+	DescribeTable("invalid maintenance windows are rejected",
+		func(spec, reason string) {
+			_, err := controller.ParseMaintenanceWindow(spec)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(reason))
+		},
+		Entry("empty", "", "expected [weekdays] HH:MM-HH:MM"),
+		Entry("no time range", "Mon-Fri", "invalid time Mon"),
+		Entry("invalid time range", "Mon 22:00", "invalid time range"),
+		Entry("invalid weekday", "Funday 22:00-23:00", "invalid weekday"),
+		Entry("invalid time", "22:00-25:00", "invalid time 25:00"),
+		Entry("24:00 start", "24:00-02:00", "invalid time 24:00"),
+		Entry("empty time range", "Mon 06:00-06:00", "empty"),
+		Entry("cron without duration", "0 2 * * * *", "invalid duration"),
+		Entry("cron with too short a duration", "0 2 * * 6 30s", "between 1m and"),
+		Entry("cron with too long a duration", "0 2 * * 6 200h", "between 1m and"),
+		Entry("cron value out of bounds", "61 2 * * 6 1h", "invalid cron minute"),
+		Entry("cron zero step", "*/0 2 * * 6 1h", "invalid step"),
+		Entry("cron reversed range", "0 2 * * 5-1 1h", "invalid cron day of week"),
+	)
+})
@@ -0,0 +1,154 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ComponentCore groups the CRDs every Sveltos subsystem relies on:
+	// cluster registration, cluster sets, licensing and debugging
+	ComponentCore = "core"
+
+	// ComponentAddons groups the CRDs of add-on deployment
+	ComponentAddons = "addons"
+
+	// ComponentEvents groups the CRDs of the event framework
+	ComponentEvents = "events"
+
+	// ComponentClassification groups the CRDs of cluster classification
+	ComponentClassification = "classification"
+
+	// ComponentHealthChecks groups the CRDs of health checks and notifications
+	ComponentHealthChecks = "healthchecks"
+
+	// ComponentReloader groups the CRDs of rolling upgrades on configuration changes
+	ComponentReloader = "reloader"
+
+	// ComponentRoleRequests groups the CRDs of multi-tenancy role requests
+	ComponentRoleRequests = "rolerequests"
+
+	// ComponentTechSupport groups the CRDs of tech support collection
+	ComponentTechSupport = "techsupport"
+)
+
+// components lists, per component, the bundle CRDs it is made of. Every
+// bundle CRD must belong to exactly one component: it must be updated
+// whenever the bundle gains a CRD.
+var components = map[string][]string{
+	ComponentCore: {
+		"accessrequests.lib.projectsveltos.io",
+		"clustersets.lib.projectsveltos.io",
+		"debuggingconfigurations.lib.projectsveltos.io",
+		"sets.lib.projectsveltos.io",
+		"sveltosclusters.lib.projectsveltos.io",
+		"sveltoslicenses.lib.projectsveltos.io",
+	},
+	ComponentAddons: {
+		"clusterconfigurations.config.projectsveltos.io",
+		"clusterprofiles.config.projectsveltos.io",
+		"clusterpromotions.config.projectsveltos.io",
+		"clusterreports.config.projectsveltos.io",
+		"clustersummaries.config.projectsveltos.io",
+		"configurationbundles.lib.projectsveltos.io",
+		"configurationgroups.lib.projectsveltos.io",
+		"profiles.config.projectsveltos.io",
+		"resourcesummaries.lib.projectsveltos.io",
+	},
+	ComponentEvents: {
+		"eventreports.lib.projectsveltos.io",
+		"eventsources.lib.projectsveltos.io",
+		"eventtriggers.lib.projectsveltos.io",
+	},
+	ComponentClassification: {
+		"classifierreports.lib.projectsveltos.io",
+		"classifiers.lib.projectsveltos.io",
+	},
+	ComponentHealthChecks: {
+		"clusterhealthchecks.lib.projectsveltos.io",
+		"healthcheckreports.lib.projectsveltos.io",
+		"healthchecks.lib.projectsveltos.io",
+	},
+	ComponentReloader: {
+		"reloaderreports.lib.projectsveltos.io",
+		"reloaders.lib.projectsveltos.io",
+	},
+	ComponentRoleRequests: {
+		"rolerequests.lib.projectsveltos.io",
+	},
+	ComponentTechSupport: {
+		"techsupports.lib.projectsveltos.io",
+	},
+}
+
+// Components returns, sorted, the names of the components the bundle CRDs
+// belong to
+func Components() []string {
+	result := make([]string, 0, len(components))
+	for name := range components {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ComponentOf returns the component the CRD named crdName belongs to, or an
+// empty string if it is not part of any
+func ComponentOf(crdName string) string {
+	for component, names := range components {
+		for _, name := range names {
+			if name == crdName {
+				return component
+			}
+		}
+	}
+	return ""
+}
+
+// ValidateComponents returns an error, listing the valid components, if any
+// of names is not a component
+func ValidateComponents(names []string) error {
+	for _, name := range names {
+		if _, ok := components[name]; !ok {
+			return fmt.Errorf("unknown component %q, valid components are: %s",
+				name, strings.Join(Components(), ", "))
+		}
+	}
+	return nil
+}
+
+// GetCRDsForComponent returns, as a multi-document YAML in bundle order, the
+// bundle CRDs of the component
+func GetCRDsForComponent(name string) ([]byte, error) {
+	if err := ValidateComponents([]string{name}); err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	for _, doc := range bundleDocuments() {
+		if ComponentOf(doc.name) != name {
+			continue
+		}
+		content.WriteString("---\n")
+		content.Write(doc.content)
+	}
+	return content.Bytes(), nil
+}
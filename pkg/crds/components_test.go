@@ -0,0 +1,84 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+// crdNames returns the names of the CRDs of a multi-document YAML
+func crdNames(content []byte) []string {
+	objs, err := deployer.CustomSplit(string(content))
+	Expect(err).To(BeNil())
+
+	names := make([]string, len(objs))
+	for i := range objs {
+		u, err := k8s_utils.GetUnstructured([]byte(objs[i]))
+		Expect(err).To(BeNil())
+		names[i] = u.GetName()
+	}
+	return names
+}
+
+var _ = Describe("Components", func() {
+	It("maps every bundle CRD to exactly one component", func() {
+		bundleNames := crdNames(crds.GetSveltosCRDYAML())
+
+		var componentNames []string
+		for _, component := range crds.Components() {
+			content, err := crds.GetCRDsForComponent(component)
+			Expect(err).To(BeNil())
+			names := crdNames(content)
+			Expect(names).ToNot(BeEmpty(), component)
+			for _, name := range names {
+				Expect(crds.ComponentOf(name)).To(Equal(component))
+			}
+			componentNames = append(componentNames, names...)
+		}
+		Expect(componentNames).To(ConsistOf(bundleNames))
+
+		for _, name := range bundleNames {
+			Expect(crds.ComponentOf(name)).ToNot(BeEmpty(), name)
+		}
+	})
+
+	It("returns the components sorted", func() {
+		components := crds.Components()
+		Expect(components).To(ContainElements(crds.ComponentAddons, crds.ComponentEvents))
+		for i := 1; i < len(components); i++ {
+			Expect(components[i-1] < components[i]).To(BeTrue())
+		}
+	})
+
+	It("rejects unknown components, listing the valid ones", func() {
+		Expect(crds.ValidateComponents([]string{crds.ComponentAddons, crds.ComponentEvents})).To(Succeed())
+
+		err := crds.ValidateComponents([]string{crds.ComponentAddons, "unknown"})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(`unknown component "unknown"`))
+		Expect(err.Error()).To(ContainSubstring(crds.ComponentTechSupport))
+
+		_, err = crds.GetCRDsForComponent("unknown")
+		Expect(err).ToNot(BeNil())
+		Expect(crds.ComponentOf("unknown.lib.projectsveltos.io")).To(BeEmpty())
+	})
+})
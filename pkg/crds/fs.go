@@ -0,0 +1,219 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// BundleFileName is the name, in FS, of the file holding the whole bundle
+const BundleFileName = "crds.yaml"
+
+// crdDocument is the YAML document of a bundle CRD
+type crdDocument struct {
+	name    string
+	content []byte
+}
+
+// bundleDocuments splits the bundle once. The bundle being embedded, failing
+// to split it is a build defect: it panics.
+var bundleDocuments = sync.OnceValue(func() []crdDocument {
+	result, err := splitCRDs(crdsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded CRD bundle: %v", err))
+	}
+	return result
+})
+
+// splitCRDs returns, in bundle order, the documents of the CRDs of a
+// multi-document YAML bundle
+func splitCRDs(data []byte) ([]crdDocument, error) {
+	var result []crdDocument
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var obj metav1.PartialObjectMetadata
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, err
+		}
+		if obj.Name == "" {
+			continue
+		}
+		content := append(bytes.TrimRight(bytes.Clone(doc), "\n"), '\n')
+		result = append(result, crdDocument{name: obj.Name, content: content})
+	}
+}
+
+// CRDNames returns, in bundle order, the names of the bundle CRDs
+func CRDNames() []string {
+	docs := bundleDocuments()
+	result := make([]string, len(docs))
+	for i := range docs {
+		result[i] = docs[i].name
+	}
+	return result
+}
+
+// GetCRDByName returns the YAML of the bundle CRD named name, or nil if it is
+// not part of the bundle
+func GetCRDByName(name string) []byte {
+	for _, doc := range bundleDocuments() {
+		if doc.name == name {
+			return bytes.Clone(doc.content)
+		}
+	}
+	return nil
+}
+
+// FS returns a read-only file system holding, at its root, one file per
+// bundle CRD, named <crd name>.yaml, with the content GetCRDByName returns,
+// and BundleFileName, with the content GetSveltosCRDYAML returns
+func FS() fs.FS {
+	files := map[string][]byte{BundleFileName: crdsYAML}
+	for _, doc := range bundleDocuments() {
+		files[doc.name+".yaml"] = doc.content
+	}
+	return &bundleFS{files: files}
+}
+
+// bundleFS is an in-memory file system with a single directory, its root
+type bundleFS struct {
+	files map[string][]byte
+}
+
+func (f *bundleFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &bundleDir{info: dirInfo{}, entries: f.entries()}, nil
+	}
+	content, ok := f.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &bundleFile{Reader: bytes.NewReader(content), info: fileInfo{name: name, size: int64(len(content))}}, nil
+}
+
+// ReadFile implements fs.ReadFileFS. The content returned is a copy.
+func (f *bundleFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	content, ok := f.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(content), nil
+}
+
+// entries returns the files, sorted by name
+func (f *bundleFS) entries() []fs.DirEntry {
+	result := make([]fs.DirEntry, 0, len(f.files))
+	for name, content := range f.files {
+		result = append(result, fileInfo{name: name, size: int64(len(content))})
+	}
+	slices.SortFunc(result, func(a, b fs.DirEntry) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return result
+}
+
+// bundleFile is an open bundleFS file. It implements io.Seeker and
+// io.ReaderAt, as http.FileServer needs.
+type bundleFile struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *bundleFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *bundleFile) Close() error { return nil }
+
+// bundleDir is the open root directory of a bundleFS
+type bundleDir struct {
+	info    dirInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *bundleDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *bundleDir) Close() error { return nil }
+
+func (d *bundleDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *bundleDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// fileInfo describes a bundleFS file, as fs.FileInfo and fs.DirEntry
+type fileInfo struct {
+	name string
+	size int64
+}
+
+func (i fileInfo) Name() string               { return i.name }
+func (i fileInfo) Size() int64                { return i.size }
+func (i fileInfo) Mode() fs.FileMode          { return 0o444 }
+func (i fileInfo) ModTime() time.Time         { return time.Time{} }
+func (i fileInfo) IsDir() bool                { return false }
+func (i fileInfo) Sys() any                   { return nil }
+func (i fileInfo) Type() fs.FileMode          { return 0 }
+func (i fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// dirInfo describes the root directory of a bundleFS
+type dirInfo struct{}
+
+func (dirInfo) Name() string       { return "." }
+func (dirInfo) Size() int64        { return 0 }
+func (dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (dirInfo) ModTime() time.Time { return time.Time{} }
+func (dirInfo) IsDir() bool        { return true }
+func (dirInfo) Sys() any           { return nil }
@@ -0,0 +1,90 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+var _ = Describe("FS", func() {
+	It("holds one file per bundle CRD, with the content GetCRDByName returns, and the bundle", func() {
+		bundleFS := crds.FS()
+
+		var files []string
+		Expect(fs.WalkDir(bundleFS, ".", func(path string, entry fs.DirEntry, err error) error {
+			Expect(err).To(BeNil())
+			if entry.IsDir() {
+				return nil
+			}
+			files = append(files, path)
+
+			content, err := fs.ReadFile(bundleFS, path)
+			Expect(err).To(BeNil())
+			if path == crds.BundleFileName {
+				Expect(content).To(Equal(crds.GetSveltosCRDYAML()))
+			} else {
+				Expect(content).To(Equal(crds.GetCRDByName(path[:len(path)-len(".yaml")])), path)
+			}
+			return nil
+		})).To(Succeed())
+
+		expected := []string{crds.BundleFileName}
+		for _, name := range crds.CRDNames() {
+			expected = append(expected, name+".yaml")
+		}
+		Expect(files).To(ConsistOf(expected))
+		Expect(fstest.TestFS(bundleFS, expected...)).To(Succeed())
+	})
+
+	It("lists the CRDs the bundle holds, in bundle order", func() {
+		Expect(crds.CRDNames()).To(Equal(crdNames(crds.GetSveltosCRDYAML())))
+		for _, name := range crds.CRDNames() {
+			Expect(crdNames(crds.GetCRDByName(name))).To(Equal([]string{name}))
+			Expect(bytes.Contains(crds.GetSveltosCRDYAML(), crds.GetCRDByName(name))).To(BeTrue(), name)
+		}
+		Expect(crds.GetCRDByName("unknown.projectsveltos.io")).To(BeNil())
+	})
+
+	It("does not let callers modify the embedded bundle", func() {
+		name := crds.CRDNames()[0]
+		content := crds.GetCRDByName(name)
+		content[0] = 'x'
+		Expect(crds.GetCRDByName(name)).ToNot(Equal(content))
+
+		content, err := fs.ReadFile(crds.FS(), name+".yaml")
+		Expect(err).To(BeNil())
+		content[0] = 'x'
+		Expect(crds.GetCRDByName(name)).ToNot(Equal(content))
+	})
+
+	It("can be served over HTTP", func() {
+		name := crds.CRDNames()[0]
+		recorder := httptest.NewRecorder()
+		http.FileServerFS(crds.FS()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/"+name+".yaml", http.NoBody))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.Bytes()).To(Equal(crds.GetCRDByName(name)))
+	})
+})
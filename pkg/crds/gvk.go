@@ -0,0 +1,115 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crds
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// bundleCRDs parses the bundle once. The bundle being embedded, failing to
+// parse it is a build defect: it panics.
+var bundleCRDs = sync.OnceValue(func() []apiextensionsv1.CustomResourceDefinition {
+	result, err := parseCRDs(crdsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded CRD bundle: %v", err))
+	}
+	return result
+})
+
+// parseCRDs parses the CustomResourceDefinitions of a multi-document YAML bundle
+func parseCRDs(data []byte) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	var result []apiextensionsv1.CustomResourceDefinition
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		crd := apiextensionsv1.CustomResourceDefinition{}
+		err := decoder.Decode(&crd)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if crd.Name != "" {
+			result = append(result, crd)
+		}
+	}
+}
+
+// GroupVersionKinds returns, in bundle order, the GroupVersionKinds of every
+// served version of every bundle CRD
+func GroupVersionKinds() []schema.GroupVersionKind {
+	var result []schema.GroupVersionKind
+	for _, crd := range bundleCRDs() {
+		for i := range crd.Spec.Versions {
+			if crd.Spec.Versions[i].Served {
+				result = append(result, schema.GroupVersionKind{
+					Group:   crd.Spec.Group,
+					Version: crd.Spec.Versions[i].Name,
+					Kind:    crd.Spec.Names.Kind,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// GroupVersionResources returns, in bundle order, the GroupVersionResources
+// of every served version of every bundle CRD
+func GroupVersionResources() []schema.GroupVersionResource {
+	var result []schema.GroupVersionResource
+	for _, crd := range bundleCRDs() {
+		for i := range crd.Spec.Versions {
+			if crd.Spec.Versions[i].Served {
+				result = append(result, schema.GroupVersionResource{
+					Group:    crd.Spec.Group,
+					Version:  crd.Spec.Versions[i].Name,
+					Resource: crd.Spec.Names.Plural,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// AddUnstructuredToScheme registers, for every served version of every bundle
+// CRD, the kind and its list kind as unstructured objects, so that clients and
+// informers built from s can get, list and watch Sveltos resources without
+// importing their Go types. Its signature allows using it in a runtime.SchemeBuilder.
+func AddUnstructuredToScheme(s *runtime.Scheme) error {
+	for _, crd := range bundleCRDs() {
+		for i := range crd.Spec.Versions {
+			if !crd.Spec.Versions[i].Served {
+				continue
+			}
+			gv := schema.GroupVersion{Group: crd.Spec.Group, Version: crd.Spec.Versions[i].Name}
+			s.AddKnownTypeWithName(gv.WithKind(crd.Spec.Names.Kind), &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(gv.WithKind(crd.Spec.Names.ListKind), &unstructured.UnstructuredList{})
+			metav1.AddToGroupVersion(s, gv)
+		}
+	}
+	return nil
+}
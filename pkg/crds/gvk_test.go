@@ -0,0 +1,102 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crds_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+// servedVersions returns the GroupVersionKinds and GroupVersionResources of
+// the served versions of the bundle CRDs, read from the raw YAML
+func servedVersions() ([]schema.GroupVersionKind, []schema.GroupVersionResource) {
+	objs, err := deployer.CustomSplit(string(crds.GetSveltosCRDYAML()))
+	Expect(err).To(BeNil())
+
+	var gvks []schema.GroupVersionKind
+	var gvrs []schema.GroupVersionResource
+	for i := range objs {
+		u, err := k8s_utils.GetUnstructured([]byte(objs[i]))
+		Expect(err).To(BeNil())
+		group, _, _ := unstructured.NestedString(u.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(u.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(u.Object, "spec", "names", "plural")
+		versions, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+		Expect(versions).ToNot(BeEmpty(), u.GetName())
+		for _, v := range versions {
+			version := v.(map[string]interface{})
+			if version["served"] != true {
+				continue
+			}
+			name := version["name"].(string)
+			gvks = append(gvks, schema.GroupVersionKind{Group: group, Version: name, Kind: kind})
+			gvrs = append(gvrs, schema.GroupVersionResource{Group: group, Version: name, Resource: plural})
+		}
+	}
+	return gvks, gvrs
+}
+
+var _ = Describe("GroupVersionKinds", func() {
+	It("covers every served version of every bundle CRD", func() {
+		gvks, gvrs := servedVersions()
+		Expect(gvks).ToNot(BeEmpty())
+		Expect(crds.GroupVersionKinds()).To(Equal(gvks))
+		Expect(crds.GroupVersionResources()).To(Equal(gvrs))
+	})
+
+	It("registers unstructured kinds and list kinds", func() {
+		scheme := runtime.NewScheme()
+		Expect(crds.AddUnstructuredToScheme(scheme)).To(Succeed())
+
+		for _, gvk := range crds.GroupVersionKinds() {
+			Expect(scheme.Recognizes(gvk)).To(BeTrue(), gvk.String())
+			obj, err := scheme.New(gvk)
+			Expect(err).To(BeNil())
+			Expect(obj).To(BeAssignableToTypeOf(&unstructured.Unstructured{}))
+
+			list := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+			Expect(scheme.Recognizes(list)).To(BeTrue(), list.String())
+		}
+	})
+
+	It("lets clients list Sveltos resources as unstructured objects", func() {
+		scheme := runtime.NewScheme()
+		Expect(crds.AddUnstructuredToScheme(scheme)).To(Succeed())
+
+		gvk := crds.GroupVersionKinds()[0]
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetName("sample")
+		obj.SetNamespace("default")
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj).Build()
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// BundleHashAnnotation records, on the CRDs crd-manager creates or updates,
+// the SpecHash of the bundle CRD they were applied from. CRDs written by
+// other tools do not carry it.
+const BundleHashAnnotation = "projectsveltos.io/bundle-hash"
+
+// SpecHash returns the digest, "sha256:<hex>", of the spec of a CRD decoded
+// from YAML or JSON, as found in the bundle: mutations crd-manager applies
+// before writing it do not change its BundleHashAnnotation
+func SpecHash(crd map[string]any) (string, error) {
+	data, err := json.Marshal(crd["spec"])
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// bundleSpecHashes returns, per bundle CRD, its SpecHash. Failing to compute
+// them is, as for bundleDocuments, a build defect: it panics.
+var bundleSpecHashes = sync.OnceValue(func() map[string]string {
+	result := map[string]string{}
+	for _, doc := range bundleDocuments() {
+		hash, err := documentSpecHash(doc.content)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded CRD %s: %v", doc.name, err))
+		}
+		result[doc.name] = hash
+	}
+	return result
+})
+
+// documentSpecHash returns the SpecHash of a CRD YAML document. It is decoded
+// as the API machinery does, integers staying integers, for the hash to match
+// the one of the same CRD read from any other bundle.
+func documentSpecHash(doc []byte) (string, error) {
+	data, err := yaml.YAMLToJSON(doc)
+	if err != nil {
+		return "", err
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(data); err != nil {
+		return "", err
+	}
+	return SpecHash(u.Object)
+}
+
+// CheckInstalled returns, sorted by name, the bundle CRDs missing from
+// the cluster and the ones installed but outdated: not carrying the
+// BundleHashAnnotation of the embedded bundle, either because another bundle
+// was applied or because they were not written by crd-manager. A nil names
+// checks every bundle CRD. Only the CRDs metadata is read, which requires
+// get, and not list, on customresourcedefinitions.
+func CheckInstalled(ctx context.Context, c client.Reader, names []string) (missing, outdated []string, err error) {
+	if names == nil {
+		names = CRDNames()
+	}
+	hashes := bundleSpecHashes()
+	expected := make(map[string]string, len(names))
+	for _, name := range names {
+		hash, ok := hashes[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("CRD %s is not part of the bundle", name)
+		}
+		expected[name] = hash
+	}
+	return CheckInstalledHashes(ctx, c, expected)
+}
+
+// CheckInstalledHashes is CheckInstalled for the CRDs of another bundle:
+// expected holds, per CRD name, its BundleHashAnnotation. Results are sorted
+// by name.
+func CheckInstalledHashes(ctx context.Context, c client.Reader,
+	expected map[string]string) (missing, outdated []string, err error) {
+
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		live := &metav1.PartialObjectMetadata{}
+		live.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, nil, err
+		}
+		if live.GetAnnotations()[BundleHashAnnotation] != expected[name] {
+			outdated = append(outdated, name)
+		}
+	}
+	return missing, outdated, nil
+}
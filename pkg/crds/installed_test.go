@@ -0,0 +1,150 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// bundleHash returns the SpecHash of the bundle CRD named name
+func bundleHash(name string) string {
+	data, err := yaml.YAMLToJSON(crds.GetCRDByName(name))
+	Expect(err).To(BeNil())
+	u := &unstructured.Unstructured{}
+	Expect(u.UnmarshalJSON(data)).To(Succeed())
+	hash, err := crds.SpecHash(u.Object)
+	Expect(err).To(BeNil())
+	return hash
+}
+
+// installedCRD returns the CRD named name carrying hash as BundleHashAnnotation,
+// none if hash is empty
+func installedCRD(name, hash string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if hash != "" {
+		crd.Annotations = map[string]string{crds.BundleHashAnnotation: hash}
+	}
+	return crd
+}
+
+var _ = Describe("CheckInstalled", func() {
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("reports nothing when every bundle CRD carries its bundle hash", func() {
+		names := crds.CRDNames()
+		objs := make([]client.Object, len(names))
+		for i := range names {
+			objs[i] = installedCRD(names[i], bundleHash(names[i]))
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+		missing, outdated, err := crds.CheckInstalled(context.TODO(), c, nil)
+		Expect(err).To(BeNil())
+		Expect(missing).To(BeEmpty())
+		Expect(outdated).To(BeEmpty())
+	})
+
+	It("classifies CRDs as missing or outdated, sorted by name", func() {
+		names := crds.CRDNames()[:4]
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			installedCRD(names[0], bundleHash(names[0])),
+			installedCRD(names[1], ""),
+			installedCRD(names[2], "sha256:0000"),
+		).Build()
+
+		missing, outdated, err := crds.CheckInstalled(context.TODO(), c, []string{names[3], names[2], names[1], names[0]})
+		Expect(err).To(BeNil())
+		Expect(missing).To(Equal([]string{names[3]}))
+		Expect(outdated).To(ConsistOf(names[1], names[2]))
+		Expect(outdated[0] < outdated[1]).To(BeTrue())
+	})
+
+	It("reads the CRDs metadata only", func() {
+		name := crds.CRDNames()[0]
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(installedCRD(name, bundleHash(name))).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+					opts ...client.GetOption) error {
+					if _, ok := obj.(*metav1.PartialObjectMetadata); !ok {
+						return errors.New("full CRD read")
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+				List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+					return errors.New("list is not allowed")
+				},
+			}).Build()
+
+		missing, outdated, err := crds.CheckInstalled(context.TODO(), c, []string{name})
+		Expect(err).To(BeNil())
+		Expect(missing).To(BeEmpty())
+		Expect(outdated).To(BeEmpty())
+	})
+
+	It("returns the read errors", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+				return errors.New("forbidden")
+			},
+		}).Build()
+
+		_, _, err := crds.CheckInstalled(context.TODO(), c, nil)
+		Expect(err).To(MatchError("forbidden"))
+	})
+
+	It("rejects CRDs not part of the bundle", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		_, _, err := crds.CheckInstalled(context.TODO(), c, []string{"gadgets.example.com"})
+		Expect(err).To(MatchError(ContainSubstring("gadgets.example.com is not part of the bundle")))
+	})
+
+	It("hashes the spec only", func() {
+		crd := map[string]any{
+			"metadata": map[string]any{"name": "gadgets.example.com"},
+			"spec":     map[string]any{"group": "example.com", "scope": "Cluster"},
+		}
+		hash, err := crds.SpecHash(crd)
+		Expect(err).To(BeNil())
+		Expect(hash).To(HavePrefix("sha256:"))
+
+		crd["metadata"] = map[string]any{"name": "gadgets.example.com", "labels": map[string]any{"a": "b"}}
+		Expect(crds.SpecHash(crd)).To(Equal(hash))
+
+		crd["spec"] = map[string]any{"group": "example.com", "scope": "Namespaced"}
+		Expect(crds.SpecHash(crd)).ToNot(Equal(hash))
+	})
+})
@@ -0,0 +1,99 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// VersionMarkerVersionKey is the version marker ConfigMap key holding the
+	// Sveltos version of the bundle fully applied, empty when not known
+	VersionMarkerVersionKey = "version"
+
+	// VersionMarkerDigestKey is the version marker ConfigMap key holding the
+	// digest of the bundle fully applied
+	VersionMarkerDigestKey = "digest"
+
+	// VersionMarkerCompletedAtKey is the version marker ConfigMap key holding,
+	// in RFC 3339 format, when the bundle was fully applied
+	VersionMarkerCompletedAtKey = "completedAt"
+)
+
+// AppliedVersion is the content of a version marker ConfigMap, which
+// crd-manager advances only once every bundle CRD is applied
+type AppliedVersion struct {
+	// Version is the Sveltos version of the bundle, empty when not known
+	Version string
+
+	// Digest is the digest of the bundle
+	Digest string
+
+	// CompletedAt is when the bundle was fully applied
+	CompletedAt time.Time
+}
+
+// ReadVersionMarker returns the content of the version marker ConfigMap key
+// designates, or nil if it does not exist yet: no bundle was fully applied.
+// It requires get on that ConfigMap.
+func ReadVersionMarker(ctx context.Context, c client.Reader, key types.NamespacedName) (*AppliedVersion, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := &AppliedVersion{
+		Version: configMap.Data[VersionMarkerVersionKey],
+		Digest:  configMap.Data[VersionMarkerDigestKey],
+	}
+	if completedAt := configMap.Data[VersionMarkerCompletedAtKey]; completedAt != "" {
+		var err error
+		if result.CompletedAt, err = time.Parse(time.RFC3339, completedAt); err != nil {
+			return nil, fmt.Errorf("invalid version marker %s: %s: %w", key, VersionMarkerCompletedAtKey, err)
+		}
+	}
+	return result, nil
+}
+
+// AtLeast returns true if the bundle applied is at least minVersion, for
+// instance v0.40. A nil AppliedVersion, or one whose version is not known, is
+// not.
+func (v *AppliedVersion) AtLeast(minVersion string) (bool, error) {
+	minimum, err := utilversion.ParseGeneric(minVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid minimum version %q: %w", minVersion, err)
+	}
+	if v == nil || v.Version == "" {
+		return false, nil
+	}
+	applied, err := utilversion.ParseGeneric(v.Version)
+	if err != nil {
+		return false, fmt.Errorf("invalid applied version %q: %w", v.Version, err)
+	}
+	return applied.AtLeast(minimum), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+var _ = Describe("ReadVersionMarker", func() {
+	key := types.NamespacedName{Namespace: "projectsveltos", Name: "crd-bundle"}
+
+	marker := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}, Data: data}
+	}
+
+	read := func(data map[string]string) (*crds.AppliedVersion, error) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		if data != nil {
+			builder = builder.WithObjects(marker(data))
+		}
+		return crds.ReadVersionMarker(context.TODO(), builder.Build(), key)
+	}
+
+	It("returns nil when no bundle was fully applied yet", func() {
+		applied, err := read(nil)
+		Expect(err).To(BeNil())
+		Expect(applied).To(BeNil())
+		Expect(applied.AtLeast("v0.40")).To(BeFalse())
+	})
+
+	It("returns the bundle fully applied", func() {
+		applied, err := read(map[string]string{
+			crds.VersionMarkerVersionKey:     "v0.40.1",
+			crds.VersionMarkerDigestKey:      "sha256:abcd",
+			crds.VersionMarkerCompletedAtKey: "2026-01-02T03:04:05Z",
+		})
+		Expect(err).To(BeNil())
+		Expect(*applied).To(Equal(crds.AppliedVersion{
+			Version: "v0.40.1", Digest: "sha256:abcd", CompletedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		}))
+	})
+
+	It("rejects an invalid completion time", func() {
+		_, err := read(map[string]string{crds.VersionMarkerCompletedAtKey: "yesterday"})
+		Expect(err).To(MatchError(ContainSubstring("invalid version marker projectsveltos/crd-bundle")))
+	})
+})
+
+var _ = Describe("AppliedVersion", func() {
+	It("compares the applied version", func() {
+		applied := &crds.AppliedVersion{Version: "v0.40.1"}
+		for minimum, expected := range map[string]bool{
+			"v0.40":   true,
+			"v0.40.1": true,
+			"0.39.9":  true,
+			"v0.40.2": false,
+			"v1.0":    false,
+		} {
+			Expect(applied.AtLeast(minimum)).To(Equal(expected), minimum)
+		}
+	})
+
+	It("is not at least any version when the applied one is not known", func() {
+		Expect((&crds.AppliedVersion{Digest: "sha256:abcd"}).AtLeast("v0.1")).To(BeFalse())
+	})
+
+	It("rejects invalid versions", func() {
+		_, err := (&crds.AppliedVersion{Version: "v0.40.1"}).AtLeast("latest")
+		Expect(err).To(MatchError(ContainSubstring(`invalid minimum version "latest"`)))
+		_, err = (&crds.AppliedVersion{Version: "main"}).AtLeast("v0.40")
+		Expect(err).To(MatchError(ContainSubstring(`invalid applied version "main"`)))
+	})
+})
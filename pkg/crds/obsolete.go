@@ -0,0 +1,51 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+// ObsoleteCRD is a CRD Sveltos used to ship and has since retired
+type ObsoleteCRD struct {
+	// Name is the CustomResourceDefinition name
+	Name string
+
+	// RemovedIn is the first Sveltos release not shipping the CRD anymore
+	RemovedIn string
+
+	// ReplacedBy, when set, is the name of the CRD replacing it
+	ReplacedBy string
+}
+
+// obsoleteCRDs must never contain a CRD of the current bundle
+var obsoleteCRDs = []ObsoleteCRD{
+	{
+		Name:       "addoncompliances.lib.projectsveltos.io",
+		RemovedIn:  "v0.18.0",
+		ReplacedBy: "addonconstraints.lib.projectsveltos.io",
+	},
+	{
+		Name:       "eventbasedaddons.lib.projectsveltos.io",
+		RemovedIn:  "v0.24.0",
+		ReplacedBy: "eventtriggers.lib.projectsveltos.io",
+	},
+}
+
+// ObsoleteCRDs returns the CRDs retired across Sveltos releases, which
+// upgraded clusters may still contain
+func ObsoleteCRDs() []ObsoleteCRD {
+	result := make([]ObsoleteCRD, len(obsoleteCRDs))
+	copy(result, obsoleteCRDs)
+	return result
+}
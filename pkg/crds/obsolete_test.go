@@ -0,0 +1,54 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+var _ = Describe("ObsoleteCRDs", func() {
+	It("never contains a CRD of the current bundle", func() {
+		objs, err := deployer.CustomSplit(string(crds.GetSveltosCRDYAML()))
+		Expect(err).To(BeNil())
+		Expect(objs).ToNot(BeEmpty())
+
+		obsolete := map[string]bool{}
+		for _, crd := range crds.ObsoleteCRDs() {
+			obsolete[crd.Name] = true
+		}
+		for i := range objs {
+			u, err := k8s_utils.GetUnstructured([]byte(objs[i]))
+			Expect(err).To(BeNil())
+			Expect(obsolete).ToNot(HaveKey(u.GetName()), "bundle CRD %s is listed as obsolete", u.GetName())
+		}
+	})
+
+	It("entries are complete and unique", func() {
+		seen := map[string]bool{}
+		for _, crd := range crds.ObsoleteCRDs() {
+			Expect(crd.Name).ToNot(BeEmpty())
+			Expect(crd.RemovedIn).To(HavePrefix("v"))
+			Expect(seen).ToNot(HaveKey(crd.Name))
+			seen[crd.Name] = true
+		}
+	})
+})
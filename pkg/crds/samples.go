@@ -0,0 +1,37 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed samples
+var samples embed.FS
+
+// GetSample returns the sample manifest, used by smoke tests, of the given
+// version of a CRD, or nil if none is shipped. Samples live in the samples
+// directory, named <crdName>_<version>.yaml.
+func GetSample(crdName, version string) []byte {
+	data, err := fs.ReadFile(samples, fmt.Sprintf("samples/%s_%s.yaml", crdName, version))
+	if err != nil {
+		return nil
+	}
+	return data
+}
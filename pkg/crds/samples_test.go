@@ -0,0 +1,74 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+var _ = Describe("GetSample", func() {
+	It("every sample matches a served version of a bundle CRD", func() {
+		objs, err := deployer.CustomSplit(string(crds.GetSveltosCRDYAML()))
+		Expect(err).To(BeNil())
+
+		// apiVersion/kind => CRD name
+		served := map[string]string{}
+		for i := range objs {
+			u, err := k8s_utils.GetUnstructured([]byte(objs[i]))
+			Expect(err).To(BeNil())
+			group := u.Object["spec"].(map[string]interface{})["group"].(string)
+			kind := u.Object["spec"].(map[string]interface{})["names"].(map[string]interface{})["kind"].(string)
+			versions := u.Object["spec"].(map[string]interface{})["versions"].([]interface{})
+			for _, v := range versions {
+				version := v.(map[string]interface{})
+				if version["served"] == true {
+					served[group+"/"+version["name"].(string)+"/"+kind] = u.GetName()
+				}
+			}
+		}
+
+		files, err := filepath.Glob("samples/*.yaml")
+		Expect(err).To(BeNil())
+		Expect(files).ToNot(BeEmpty())
+		for _, file := range files {
+			crdName, version, found := strings.Cut(strings.TrimSuffix(filepath.Base(file), ".yaml"), "_")
+			Expect(found).To(BeTrue(), "sample %s is not named <crdName>_<version>.yaml", file)
+
+			data, err := os.ReadFile(file)
+			Expect(err).To(BeNil())
+			Expect(crds.GetSample(crdName, version)).To(Equal(data))
+
+			sample, err := k8s_utils.GetUnstructured(data)
+			Expect(err).To(BeNil())
+			Expect(served).To(HaveKeyWithValue(sample.GetAPIVersion()+"/"+sample.GetKind(), crdName), file)
+			Expect(sample.GroupVersionKind().Version).To(Equal(version), file)
+		}
+	})
+
+	It("returns nil when no sample is shipped", func() {
+		Expect(crds.GetSample("clusterprofiles.config.projectsveltos.io", "v1alpha0")).To(BeNil())
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crds
+
+import (
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemaNotFoundError is returned by GetOpenAPISchema when no served version
+// of a bundle CRD matches the GroupVersionKind
+type SchemaNotFoundError struct {
+	// GVK is the GroupVersionKind requested
+	GVK schema.GroupVersionKind
+
+	// UnknownKind is true when no bundle CRD defines the group and kind. It is
+	// false when one does, but does not serve the version.
+	UnknownKind bool
+
+	// Versions are the served versions of the kind, when it is known
+	Versions []string
+}
+
+func (e *SchemaNotFoundError) Error() string {
+	if e.UnknownKind {
+		return fmt.Sprintf("kind %s is not defined by the CRD bundle", e.GVK.GroupKind())
+	}
+	return fmt.Sprintf("version %s of kind %s is not served, served versions are: %s",
+		e.GVK.Version, e.GVK.GroupKind(), strings.Join(e.Versions, ", "))
+}
+
+// GetOpenAPISchema returns a copy of the openAPIV3Schema of the bundle CRD
+// version matching gvk, so that it can be used, for instance to validate
+// resources, without installing anything in a cluster. It returns a
+// *SchemaNotFoundError when the kind, or the version, is unknown.
+func GetOpenAPISchema(gvk schema.GroupVersionKind) (*apiextensionsv1.JSONSchemaProps, error) {
+	crds := bundleCRDs()
+	for i := range crds {
+		crd := &crds[i]
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+
+		var served []string
+		for j := range crd.Spec.Versions {
+			version := &crd.Spec.Versions[j]
+			if !version.Served {
+				continue
+			}
+			if version.Name != gvk.Version {
+				served = append(served, version.Name)
+				continue
+			}
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				return nil, fmt.Errorf("version %s of CRD %s has no OpenAPI v3 schema", version.Name, crd.Name)
+			}
+			return version.Schema.OpenAPIV3Schema.DeepCopy(), nil
+		}
+		return nil, &SchemaNotFoundError{GVK: gvk, Versions: served}
+	}
+	return nil, &SchemaNotFoundError{GVK: gvk, UnknownKind: true}
+}
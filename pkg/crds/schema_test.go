@@ -0,0 +1,68 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crds_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+var _ = Describe("GetOpenAPISchema", func() {
+	It("resolves every served version of every bundle CRD", func() {
+		gvks, _ := servedVersions()
+		Expect(gvks).ToNot(BeEmpty())
+		for _, gvk := range gvks {
+			s, err := crds.GetOpenAPISchema(gvk)
+			Expect(err).To(BeNil(), gvk.String())
+			Expect(s).ToNot(BeNil(), gvk.String())
+			Expect(s.Type).To(Equal("object"), gvk.String())
+		}
+	})
+
+	It("returns a copy of the schema", func() {
+		gvk := crds.GroupVersionKinds()[0]
+		s, err := crds.GetOpenAPISchema(gvk)
+		Expect(err).To(BeNil())
+		s.Description = "modified"
+		s.Properties = nil
+
+		again, err := crds.GetOpenAPISchema(gvk)
+		Expect(err).To(BeNil())
+		Expect(again.Description).ToNot(Equal("modified"))
+		Expect(again.Properties).ToNot(BeEmpty())
+	})
+
+	It("distinguishes an unknown kind from an unknown version", func() {
+		gvk := crds.GroupVersionKinds()[0]
+
+		_, err := crds.GetOpenAPISchema(schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: "Unknown"})
+		var notFound *crds.SchemaNotFoundError
+		Expect(errors.As(err, &notFound)).To(BeTrue())
+		Expect(notFound.UnknownKind).To(BeTrue())
+
+		_, err = crds.GetOpenAPISchema(schema.GroupVersionKind{Group: gvk.Group, Version: "v0", Kind: gvk.Kind})
+		Expect(errors.As(err, &notFound)).To(BeTrue())
+		Expect(notFound.UnknownKind).To(BeFalse())
+		Expect(notFound.Versions).To(ContainElement(gvk.Version))
+		Expect(err).To(MatchError(ContainSubstring("version v0")))
+	})
+})
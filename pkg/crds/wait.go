@@ -0,0 +1,190 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultWaitInterval is the default delay before checking the CRDs again
+	// the first time. It doubles after every check, up to DefaultWaitMaxInterval.
+	DefaultWaitInterval = time.Second
+
+	// DefaultWaitMaxInterval is the default maximum delay between two checks
+	// of the CRDs
+	DefaultWaitMaxInterval = 10 * time.Second
+)
+
+// WaitError is returned by WaitForCRDs when some CRDs are still missing,
+// not established or with names not accepted once the timeout expires
+type WaitError struct {
+	// Missing are the CRDs not found in the cluster
+	Missing []string
+
+	// NotEstablished are the CRDs found but not reporting Established=True
+	NotEstablished []string
+
+	// NamesNotAccepted are the CRDs reporting NamesAccepted=False, typically
+	// because another CRD already uses their names
+	NamesNotAccepted []string
+}
+
+func (e *WaitError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.NotEstablished) > 0 {
+		parts = append(parts, "not established: "+strings.Join(e.NotEstablished, ", "))
+	}
+	if len(e.NamesNotAccepted) > 0 {
+		parts = append(parts, "names not accepted: "+strings.Join(e.NamesNotAccepted, ", "))
+	}
+	return "CRDs not ready: " + strings.Join(parts, "; ")
+}
+
+// WaitOptions configures WaitForCRDsWithOptions
+type WaitOptions struct {
+	// Timeout is how long to wait for the CRDs
+	Timeout time.Duration
+
+	// Interval is the delay before the second check. Defaults to
+	// DefaultWaitInterval.
+	Interval time.Duration
+
+	// MaxInterval caps the delay, doubling after every check, between two
+	// checks. Defaults to DefaultWaitMaxInterval.
+	MaxInterval time.Duration
+
+	// OnCheck, when set, is called after every check with the CRDs not
+	// ready, nil once all of them are, or with the error failing the check
+	OnCheck func(notReady *WaitError, err error)
+}
+
+// WaitForCRDs blocks until every CRD named names, by default every bundle
+// CRD, exists in the cluster c points to, reports Established=True and does
+// not report NamesAccepted=False. Only get permission on
+// customresourcedefinitions is needed. Failing checks are retried, with
+// backoff, until timeout expires. A *WaitError then lists the CRDs not ready.
+func WaitForCRDs(ctx context.Context, c client.Reader, names []string, timeout time.Duration) error {
+	return WaitForCRDsWithOptions(ctx, c, names, &WaitOptions{Timeout: timeout})
+}
+
+// WaitForCRDsWithOptions is WaitForCRDs, configured by opts. Nil opts, as the
+// zero WaitOptions, check the CRDs once.
+func WaitForCRDsWithOptions(ctx context.Context, c client.Reader, names []string, opts *WaitOptions) error {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	if names == nil {
+		names = CRDNames()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultWaitMaxInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	var notReady *WaitError
+	var checkErr error
+	for {
+		var result *WaitError
+		result, checkErr = checkCRDs(ctx, c, names)
+		if checkErr == nil {
+			notReady = result
+		}
+		if opts.OnCheck != nil {
+			opts.OnCheck(result, checkErr)
+		}
+		if checkErr == nil && notReady == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if notReady != nil {
+				return notReady
+			}
+			return fmt.Errorf("timed out waiting for CRDs: %w", checkErr)
+		case <-time.After(interval):
+		}
+		interval = min(2*interval, maxInterval)
+	}
+}
+
+// checkCRDs returns a WaitError listing the CRDs among names which are
+// missing, not established or with names not accepted, or nil if all of them
+// are established
+func checkCRDs(ctx context.Context, c client.Reader, names []string) (*WaitError, error) {
+	result := &WaitError{}
+	for _, name := range names {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		err := c.Get(ctx, types.NamespacedName{Name: name}, crd)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				result.Missing = append(result.Missing, name)
+				continue
+			}
+			return nil, err
+		}
+		switch {
+		case namesRejected(crd):
+			result.NamesNotAccepted = append(result.NamesNotAccepted, name)
+		case !IsEstablished(crd):
+			result.NotEstablished = append(result.NotEstablished, name)
+		}
+	}
+
+	if len(result.Missing) == 0 && len(result.NotEstablished) == 0 && len(result.NamesNotAccepted) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// IsEstablished returns true if crd reports the Established condition as True
+func IsEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for i := range crd.Status.Conditions {
+		if crd.Status.Conditions[i].Type == apiextensionsv1.Established {
+			return crd.Status.Conditions[i].Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// namesRejected returns true if crd reports the NamesAccepted condition as False
+func namesRejected(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for i := range crd.Status.Conditions {
+		if crd.Status.Conditions[i].Type == apiextensionsv1.NamesAccepted {
+			return crd.Status.Conditions[i].Status == apiextensionsv1.ConditionFalse
+		}
+	}
+	return false
+}
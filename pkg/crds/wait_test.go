@@ -0,0 +1,194 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// liveCRD returns the CRD named name, reporting Established with status
+func liveCRD(name string, status apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.Established, Status: status},
+			},
+		},
+	}
+}
+
+var _ = Describe("WaitForCRDs", func() {
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	newClient := func(objs ...client.Object) client.WithWatch {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	fastWait := func(timeout time.Duration) *crds.WaitOptions {
+		return &crds.WaitOptions{Timeout: timeout, Interval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+	}
+
+	It("waits for every bundle CRD by default", func() {
+		names := crds.CRDNames()
+		objs := make([]client.Object, len(names))
+		for i := range names {
+			objs[i] = liveCRD(names[i], apiextensionsv1.ConditionTrue)
+		}
+		Expect(crds.WaitForCRDs(context.TODO(), newClient(objs...), nil, time.Second)).To(Succeed())
+
+		err := crds.WaitForCRDsWithOptions(context.TODO(), newClient(objs[1:]...), nil, fastWait(50*time.Millisecond))
+		waitErr := &crds.WaitError{}
+		Expect(errors.As(err, &waitErr)).To(BeTrue())
+		Expect(waitErr.Missing).To(Equal([]string{names[0]}))
+		Expect(waitErr.NotEstablished).To(BeEmpty())
+	})
+
+	It("lists the CRDs still missing or not established on timeout", func() {
+		c := newClient(liveCRD("a.projectsveltos.io", apiextensionsv1.ConditionTrue),
+			liveCRD("b.projectsveltos.io", apiextensionsv1.ConditionFalse))
+
+		err := crds.WaitForCRDsWithOptions(context.TODO(), c,
+			[]string{"a.projectsveltos.io", "b.projectsveltos.io", "c.projectsveltos.io"}, fastWait(50*time.Millisecond))
+		Expect(err).To(MatchError(&crds.WaitError{
+			Missing:        []string{"c.projectsveltos.io"},
+			NotEstablished: []string{"b.projectsveltos.io"},
+		}))
+		Expect(err.Error()).To(Equal("CRDs not ready: missing: c.projectsveltos.io; not established: b.projectsveltos.io"))
+	})
+
+	It("lists the CRDs whose names are not accepted on timeout", func() {
+		rejected := liveCRD("a.projectsveltos.io", apiextensionsv1.ConditionFalse)
+		rejected.Status.Conditions = append(rejected.Status.Conditions, apiextensionsv1.CustomResourceDefinitionCondition{
+			Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionFalse,
+		})
+
+		err := crds.WaitForCRDsWithOptions(context.TODO(), newClient(rejected), []string{rejected.Name},
+			fastWait(50*time.Millisecond))
+		Expect(err).To(MatchError(&crds.WaitError{NamesNotAccepted: []string{rejected.Name}}))
+		Expect(err.Error()).To(Equal("CRDs not ready: names not accepted: a.projectsveltos.io"))
+	})
+
+	It("returns once the CRDs not ready yet become established", func() {
+		c := newClient(liveCRD("a.projectsveltos.io", apiextensionsv1.ConditionFalse))
+
+		checks := 0
+		opts := fastWait(5 * time.Second)
+		opts.OnCheck = func(notReady *crds.WaitError, err error) {
+			Expect(err).To(BeNil())
+			checks++
+			switch checks {
+			case 1:
+				Expect(notReady.NotEstablished).To(ConsistOf("a.projectsveltos.io"))
+				Expect(notReady.Missing).To(ConsistOf("b.projectsveltos.io"))
+				crd := &apiextensionsv1.CustomResourceDefinition{}
+				Expect(c.Get(context.TODO(), client.ObjectKey{Name: "a.projectsveltos.io"}, crd)).To(Succeed())
+				crd.Status.Conditions[0].Status = apiextensionsv1.ConditionTrue
+				Expect(c.Status().Update(context.TODO(), crd)).To(Succeed())
+			case 2:
+				Expect(notReady.NotEstablished).To(BeEmpty())
+				Expect(notReady.Missing).To(ConsistOf("b.projectsveltos.io"))
+				Expect(c.Create(context.TODO(), liveCRD("b.projectsveltos.io", apiextensionsv1.ConditionTrue))).
+					To(Succeed())
+			default:
+				Expect(notReady).To(BeNil())
+			}
+		}
+		Expect(crds.WaitForCRDsWithOptions(context.TODO(), c, []string{"a.projectsveltos.io", "b.projectsveltos.io"},
+			opts)).To(Succeed())
+		Expect(checks).To(Equal(3))
+	})
+
+	It("retries failing checks, reporting the CRDs found not ready last", func() {
+		failures := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+				opts ...client.GetOption) error {
+
+				if failures > 0 {
+					failures--
+					return apierrors.NewServiceUnavailable("unavailable")
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+
+		err := crds.WaitForCRDsWithOptions(context.TODO(), c, []string{"a.projectsveltos.io"},
+			fastWait(50*time.Millisecond))
+		Expect(err).To(MatchError(&crds.WaitError{Missing: []string{"a.projectsveltos.io"}}))
+
+		failures = 1000
+		err = crds.WaitForCRDsWithOptions(context.TODO(), c, []string{"a.projectsveltos.io"},
+			fastWait(50*time.Millisecond))
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+	})
+
+	It("backs off between checks", func() {
+		checks := 0
+		opts := &crds.WaitOptions{Timeout: 300 * time.Millisecond, Interval: 20 * time.Millisecond,
+			MaxInterval: 80 * time.Millisecond, OnCheck: func(*crds.WaitError, error) {
+				checks++
+			}}
+		Expect(crds.WaitForCRDsWithOptions(context.TODO(), newClient(), []string{"a.projectsveltos.io"},
+			opts)).ToNot(Succeed())
+
+		// checks at about 0, 20, 60, 140 and 220ms, instead of every 20ms
+		Expect(checks).To(BeNumerically(">=", 2))
+		Expect(checks).To(BeNumerically("<=", 6))
+	})
+
+	It("checks the CRDs once without options", func() {
+		Expect(crds.WaitForCRDsWithOptions(context.TODO(),
+			newClient(liveCRD("a.projectsveltos.io", apiextensionsv1.ConditionTrue)), []string{"a.projectsveltos.io"},
+			nil)).To(Succeed())
+
+		err := crds.WaitForCRDsWithOptions(context.TODO(), newClient(), []string{"a.projectsveltos.io"}, nil)
+		var waitErr *crds.WaitError
+		Expect(errors.As(err, &waitErr)).To(BeTrue())
+		Expect(waitErr.Missing).To(ConsistOf("a.projectsveltos.io"))
+	})
+
+	It("never writes to the cluster", func() {
+		c := newClient()
+		Expect(crds.WaitForCRDsWithOptions(context.TODO(), c, []string{"a.projectsveltos.io"},
+			fastWait(20*time.Millisecond))).ToNot(Succeed())
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+})
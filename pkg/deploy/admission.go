@@ -0,0 +1,87 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// webhookDeniedMessage matches the message of the API server rejecting a
+// request an admission webhook denied
+var webhookDeniedMessage = regexp.MustCompile(`admission webhook "([^"]+)" denied the request(?:: (?s)(.*)| without explanation)`)
+
+// AdmissionDenial describes an admission webhook (for instance a Kyverno or
+// OPA Gatekeeper policy) denying a CRD write
+type AdmissionDenial struct {
+	// Webhook is the name of the denying webhook
+	Webhook string `json:"webhook"`
+
+	// Message is why the webhook denied the write
+	Message string `json:"message,omitempty"`
+}
+
+// AdmissionDeniedError is returned when an admission webhook denies a CRD
+// write. Denials are deterministic: retrying the write without changing the
+// cluster admission policies fails again.
+type AdmissionDeniedError struct {
+	// CRD is the name of the CRD whose write was denied
+	CRD string
+
+	// Denial describes the denying webhook
+	Denial AdmissionDenial
+
+	err error
+}
+
+func (e *AdmissionDeniedError) Error() string {
+	return fmt.Sprintf("write of CRD %s denied by admission webhook %q: %s. This is not a crd-manager failure: "+
+		"check the cluster admission policies (for instance Kyverno or OPA Gatekeeper)", e.CRD, e.Denial.Webhook,
+		e.Denial.Message)
+}
+
+func (e *AdmissionDeniedError) Unwrap() error {
+	return e.err
+}
+
+// wrapAdmissionDenial returns an AdmissionDeniedError when err is the API
+// server rejecting the write of the CRD named name because an admission
+// webhook denied it, err otherwise
+func wrapAdmissionDenial(name string, err error) error {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	match := webhookDeniedMessage.FindStringSubmatch(statusErr.ErrStatus.Message)
+	if match == nil {
+		return err
+	}
+	return &AdmissionDeniedError{CRD: name, Denial: AdmissionDenial{Webhook: match[1], Message: match[2]}, err: err}
+}
+
+// admissionDenial returns the admission denial err carries, nil if err is
+// not an AdmissionDeniedError
+func admissionDenial(err error) *AdmissionDenial {
+	var deniedErr *AdmissionDeniedError
+	if !errors.As(err, &deniedErr) {
+		return nil
+	}
+	denial := deniedErr.Denial
+	return &denial
+}
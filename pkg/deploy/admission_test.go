@@ -0,0 +1,116 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// newDenyingClient returns a fake client rejecting the creation of the CRD
+// named name with createErr
+func newDenyingClient(name string, createErr error) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == name {
+				return createErr
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+// webhookDenial is the error the API server returns when the webhook denies a request with message
+func webhookDenial(webhook, message string) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusBadRequest,
+		Message: `admission webhook "` + webhook + `" denied the request: ` + message,
+	}}
+}
+
+var _ = Describe("Admission webhook denials", func() {
+	It("are reported with the denying webhook and message", func() {
+		c := newDenyingClient(sveltosClusterCRD, webhookDenial("validate.kyverno.svc-fail",
+			"policy disallow-crds: CRD creation is not allowed"))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.CountAdmissionDenied()).To(Equal(1))
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.AdmissionDenial).To(Equal(&deploy.AdmissionDenial{
+			Webhook: "validate.kyverno.svc-fail",
+			Message: "policy disallow-crds: CRD creation is not allowed",
+		}))
+		Expect(result.Error).To(ContainSubstring("check the cluster admission policies"))
+	})
+
+	It("are returned as AdmissionDeniedError", func() {
+		c := newDenyingClient(sveltosClusterCRD, webhookDenial("validation.gatekeeper.sh", "denied by policy"))
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{FailFast: true}, logger)
+		var deniedErr *deploy.AdmissionDeniedError
+		Expect(errors.As(err, &deniedErr)).To(BeTrue())
+		Expect(deniedErr.CRD).To(Equal(sveltosClusterCRD))
+		Expect(deniedErr.Denial.Webhook).To(Equal("validation.gatekeeper.sh"))
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	It("are denials without explanation as well", func() {
+		c := newDenyingClient(sveltosClusterCRD, &apierrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Message: `admission webhook "deny.example.com" denied the request without explanation`,
+		}})
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).ToNot(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.AdmissionDenial).To(Equal(&deploy.AdmissionDenial{Webhook: "deny.example.com"}))
+	})
+
+	It("are not confused with other write errors", func() {
+		c := newDenyingClient(sveltosClusterCRD, apierrors.NewForbidden(
+			schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, sveltosClusterCRD, errors.New("RBAC denied")))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.CountAdmissionDenied()).To(BeZero())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.AdmissionDenial).To(BeNil())
+	})
+})
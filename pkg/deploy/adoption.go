@@ -0,0 +1,107 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// ManagedByLabel marks the CRDs crd-manager owns
+	ManagedByLabel = "projectsveltos.io/managed-by"
+
+	// ManagedByValue is the ManagedByLabel value set by crd-manager
+	ManagedByValue = "crd-manager"
+
+	// AdoptedAtAnnotation records when crd-manager adopted a pre-existing CRD
+	AdoptedAtAnnotation = "projectsveltos.io/adopted-at"
+)
+
+// AdoptionMode tells how pre-existing CRDs without the ManagedByLabel are handled
+type AdoptionMode string
+
+const (
+	// AdoptionDisabled updates pre-existing CRDs without adding the ManagedByLabel
+	AdoptionDisabled = AdoptionMode("")
+
+	// AdoptionTwoPhase only adds the ownership markers to pre-existing CRDs,
+	// without any spec change. They are managed normally from the next run on,
+	// which lets operators review the adoption first.
+	AdoptionTwoPhase = AdoptionMode("two-phase")
+
+	// AdoptionAuto adds the ownership markers and updates pre-existing CRDs in
+	// the same run
+	AdoptionAuto = AdoptionMode("auto")
+)
+
+func validateAdoptionMode(mode AdoptionMode) error {
+	switch mode {
+	case AdoptionDisabled, AdoptionTwoPhase, AdoptionAuto:
+		return nil
+	default:
+		return fmt.Errorf("invalid adoption mode %q: expected %s or %s", mode, AdoptionTwoPhase, AdoptionAuto)
+	}
+}
+
+// isManagedByCRDManager returns true if crd carries the crd-manager ownership label
+func isManagedByCRDManager(crd client.Object) bool {
+	return crd.GetLabels()[ManagedByLabel] == ManagedByValue
+}
+
+// setManagedBy adds the crd-manager ownership label to u
+func setManagedBy(u *unstructured.Unstructured) {
+	lbls := u.GetLabels()
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	lbls[ManagedByLabel] = ManagedByValue
+	u.SetLabels(lbls)
+}
+
+// adopt adds the crd-manager ownership markers to live, leaving its spec untouched
+func adopt(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	logger logr.Logger) error {
+
+	patch := client.MergeFrom(live.DeepCopy())
+
+	lbls := live.GetLabels()
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	lbls[ManagedByLabel] = ManagedByValue
+	live.SetLabels(lbls)
+
+	annotations := live.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AdoptedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	live.SetAnnotations(annotations)
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("adopting Sveltos CRD %s: ownership markers added, "+
+		"spec left untouched until the next run", live.GetName()))
+	return c.Patch(ctx, live, patch)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+func getCRD(c client.Client, name string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	Expect(c.Get(context.TODO(), types.NamespacedName{Name: name}, crd)).To(Succeed())
+	return crd
+}
+
+// outdatedCRD returns a bundle CRD, without ownership markers, differing from the bundle
+func outdatedCRD() *apiextensionsv1.CustomResourceDefinition {
+	crd := getBundleCRD(sveltosClusterCRD)
+	crd.Spec.Names.ShortNames = []string{"sc"}
+	return crd
+}
+
+var _ = Describe("Adoption", func() {
+	It("CRDs created by crd-manager carry the ownership label", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(getCRD(c, sveltosClusterCRD).Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+	})
+
+	It("pre-existing CRDs are updated without ownership label by default", func() {
+		c := newFakeClient(outdatedCRD())
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		current := getCRD(c, sveltosClusterCRD)
+		Expect(current.Labels).ToNot(HaveKey(deploy.ManagedByLabel))
+		Expect(current.Spec.Names.ShortNames).ToNot(Equal([]string{"sc"}))
+	})
+
+	It("two-phase adoption first adds ownership markers only, then manages the CRD", func() {
+		c := newFakeClient(outdatedCRD())
+		opts := &deploy.Options{AdoptExisting: deploy.AdoptionTwoPhase}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionAdopted))
+		Expect(findResult(report, sveltosClusterCRD).Drift).To(Equal(deploy.DriftStatusDrifted))
+
+		current := getCRD(c, sveltosClusterCRD)
+		Expect(current.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(current.Annotations).To(HaveKey(deploy.AdoptedAtAnnotation))
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"sc"}))
+
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		current = getCRD(c, sveltosClusterCRD)
+		Expect(current.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(current.Spec.Names.ShortNames).ToNot(Equal([]string{"sc"}))
+
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUnchanged))
+	})
+
+	It("auto adoption adds ownership markers and updates in the same run", func() {
+		c := newFakeClient(outdatedCRD())
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AdoptExisting: deploy.AdoptionAuto}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		current := getCRD(c, sveltosClusterCRD)
+		Expect(current.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(current.Spec.Names.ShortNames).ToNot(Equal([]string{"sc"}))
+	})
+
+	It("Helm managed CRDs are not adopted", func() {
+		crd := outdatedCRD()
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AdoptExisting: deploy.AdoptionTwoPhase}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionSkippedHelm))
+		Expect(getCRD(c, sveltosClusterCRD).Labels).ToNot(HaveKey(deploy.ManagedByLabel))
+	})
+})
@@ -0,0 +1,260 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ApplySet labels and annotations, as defined by the Kubernetes ApplySet
+// specification (KEP-3659) and understood by kubectl
+const (
+	// ApplySetParentIDLabel identifies an ApplySet parent object
+	ApplySetParentIDLabel = "applyset.kubernetes.io/id"
+
+	// ApplySetPartOfLabel marks the members of an ApplySet
+	ApplySetPartOfLabel = "applyset.kubernetes.io/part-of"
+
+	// ApplySetToolingAnnotation names the tool managing an ApplySet
+	ApplySetToolingAnnotation = "applyset.kubernetes.io/tooling"
+
+	// ApplySetGKsAnnotation lists the group kinds of the ApplySet members
+	ApplySetGKsAnnotation = "applyset.kubernetes.io/contains-group-kinds"
+
+	// ApplySetTooling is the ApplySetToolingAnnotation value of the ApplySets
+	// crd-manager manages
+	ApplySetTooling = applySetToolName + "/v1"
+
+	applySetToolName = "crd-manager"
+
+	// applySetCRDGroupKind is the group kind of all crd-manager ApplySet members
+	applySetCRDGroupKind = "CustomResourceDefinition.apiextensions.k8s.io"
+)
+
+// ApplySetParentKind is the kind of an ApplySet parent object
+type ApplySetParentKind string
+
+const (
+	// ApplySetParentSecret uses a Secret as ApplySet parent
+	ApplySetParentSecret = ApplySetParentKind("Secret")
+
+	// ApplySetParentConfigMap uses a ConfigMap as ApplySet parent
+	ApplySetParentConfigMap = ApplySetParentKind("ConfigMap")
+)
+
+// ApplySet identifies the ApplySet parent object of the deployed CRDs. CRDs
+// crd-manager applies are labelled as its members, and members no longer part
+// of the bundle are pruned.
+type ApplySet struct {
+	// Kind of the parent object
+	Kind ApplySetParentKind
+
+	// Namespace of the parent object
+	Namespace string
+
+	// Name of the parent object
+	Name string
+}
+
+// ParseApplySet parses a value in the <secret|configmap>/<name> format.
+// A value without kind designates a Secret.
+func ParseApplySet(value, namespace string) (*ApplySet, error) {
+	kind, name, found := strings.Cut(value, "/")
+	if !found {
+		kind, name = "secret", value
+	}
+
+	result := &ApplySet{Namespace: namespace, Name: name}
+	switch strings.ToLower(kind) {
+	case "secret", "secrets":
+		result.Kind = ApplySetParentSecret
+	case "configmap", "configmaps", "cm":
+		result.Kind = ApplySetParentConfigMap
+	default:
+		return nil, fmt.Errorf("invalid ApplySet %q: unsupported parent kind %s, expected secret or configmap",
+			value, kind)
+	}
+	if name == "" || namespace == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid ApplySet %q: expected <secret|configmap>/<name> format and a namespace", value)
+	}
+	return result, nil
+}
+
+// ID returns the ApplySet ID, derived from the parent object identity as the
+// ApplySet specification mandates
+func (a *ApplySet) ID() string {
+	unencoded := strings.Join([]string{a.Name, a.Namespace, string(a.Kind), ""}, ".")
+	hash := sha256.Sum256([]byte(unencoded))
+	return fmt.Sprintf("applyset-%s-v1", base64.RawURLEncoding.EncodeToString(hash[:]))
+}
+
+// parent returns an empty object of the parent kind, identifying the parent
+func (a *ApplySet) parent() client.Object {
+	meta := metav1.ObjectMeta{Namespace: a.Namespace, Name: a.Name}
+	if a.Kind == ApplySetParentConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: meta}
+	}
+	return &corev1.Secret{ObjectMeta: meta}
+}
+
+// String returns the parent object in the kind namespace/name format
+func (a *ApplySet) String() string {
+	return fmt.Sprintf("%s %s/%s", a.Kind, a.Namespace, a.Name)
+}
+
+// ensureApplySetParent creates the ApplySet parent object, or updates it so
+// that it lists the CRD group kind. This must happen before any member is
+// applied. An object already used by another tool or ApplySet is left untouched.
+func ensureApplySetParent(ctx context.Context, c client.Client, applySet *ApplySet, logger logr.Logger) error {
+	parent := applySet.parent()
+	err := c.Get(ctx, types.NamespacedName{Namespace: applySet.Namespace, Name: applySet.Name}, parent)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		parent.SetLabels(map[string]string{ApplySetParentIDLabel: applySet.ID()})
+		parent.SetAnnotations(map[string]string{
+			ApplySetToolingAnnotation: ApplySetTooling,
+			ApplySetGKsAnnotation:     applySetCRDGroupKind,
+		})
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("creating ApplySet parent %s", applySet))
+		return c.Create(ctx, parent)
+	}
+
+	if id, ok := parent.GetLabels()[ApplySetParentIDLabel]; ok && id != applySet.ID() {
+		return fmt.Errorf("%s is the parent of another ApplySet (%s)", applySet, id)
+	}
+	tooling := parent.GetAnnotations()[ApplySetToolingAnnotation]
+	if tooling != "" && !strings.HasPrefix(tooling, applySetToolName+"/") {
+		return fmt.Errorf("%s ApplySet is managed by %s", applySet, tooling)
+	}
+
+	lbls := parent.GetLabels()
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	lbls[ApplySetParentIDLabel] = applySet.ID()
+	parent.SetLabels(lbls)
+
+	annotations := parent.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ApplySetToolingAnnotation] = ApplySetTooling
+	annotations[ApplySetGKsAnnotation] = addGroupKind(annotations[ApplySetGKsAnnotation], applySetCRDGroupKind)
+	parent.SetAnnotations(annotations)
+
+	return c.Update(ctx, parent)
+}
+
+// addGroupKind adds groupKind to the sorted, comma separated, list of group kinds
+func addGroupKind(groupKinds, groupKind string) string {
+	var list []string
+	for _, gk := range strings.Split(groupKinds, ",") {
+		if gk != "" && gk != groupKind {
+			list = append(list, gk)
+		}
+	}
+	list = append(list, groupKind)
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+// setApplySetMember labels u as a member of the ApplySet, if one is configured
+func setApplySetMember(u *unstructured.Unstructured, opts *Options) {
+	if opts.ApplySet == nil {
+		return
+	}
+	lbls := u.GetLabels()
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	lbls[ApplySetPartOfLabel] = opts.ApplySet.ID()
+	u.SetLabels(lbls)
+}
+
+// pruneMember deletes an ApplySet member CRD, first deleting its instances
+// with Cascade
+func pruneMember(ctx context.Context, c client.Client, member *metav1.PartialObjectMetadata, opts *Options,
+	result *CRDResult, logger logr.Logger) error {
+
+	if opts.Cascade {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Get(ctx, types.NamespacedName{Name: member.Name}, crd); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if err := cascadeDeleteInstances(ctx, c, crd, opts, result, logger); err != nil {
+			return err
+		}
+	}
+
+	err := c.Delete(ctx, member)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return auditWrite(opts, &AuditEntry{CRD: member.Name, Action: AuditActionDelete}, err, logger)
+}
+
+// pruneApplySet deletes the ApplySet members which are not part of crds, and
+// are selected by the options, and adds them to the report removed CRDs
+func pruneApplySet(ctx context.Context, c client.Client, opts *Options, crds []*bundleCRD,
+	report *Report, logger logr.Logger) error {
+
+	applySet := opts.ApplySet
+
+	expected := make(map[string]bool, len(crds))
+	for _, crd := range crds {
+		expected[crd.desired.GetName()] = true
+	}
+
+	failed := &DeployError{}
+	err := forEachCRDMetadata(ctx, c, client.MatchingLabels{ApplySetPartOfLabel: applySet.ID()},
+		func(member *metav1.PartialObjectMetadata) error {
+			if expected[member.Name] || !opts.selectsCRD(member.Name) {
+				return nil
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("pruning Sveltos CRD %s, no longer part of the bundle", member.Name))
+			result := CRDResult{Name: member.Name, Action: ActionPruned}
+			if err := pruneMember(ctx, c, member, opts, &result, logger); err != nil {
+				result.Action = ActionFailed
+				result.Error = err.Error()
+				failed.add(member.Name, err)
+			}
+			report.Removed = append(report.Removed, result)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	return failed.err()
+}
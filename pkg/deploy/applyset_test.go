@@ -0,0 +1,159 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// subsetBundle returns a bundle with the first n CRDs of the embedded one
+func subsetBundle(n int) *bundle.Bundle {
+	var docs []string
+	for _, u := range getBundleCRDs()[:n] {
+		data, err := yaml.Marshal(u.Object)
+		Expect(err).To(BeNil())
+		docs = append(docs, string(data))
+	}
+	return &bundle.Bundle{Content: []byte(strings.Join(docs, "---\n")), Source: "https://example.com/crds.yaml"}
+}
+
+func applySetOptions(b *bundle.Bundle) *deploy.Options {
+	applySet, err := deploy.ParseApplySet("secret/crd-manager", "projectsveltos")
+	Expect(err).To(BeNil())
+	return &deploy.Options{Bundle: b, ApplySet: applySet}
+}
+
+var _ = Describe("ApplySet", func() {
+	It("ParseApplySet accepts Secret and ConfigMap parents", func() {
+		applySet, err := deploy.ParseApplySet("configmaps/crds", "projectsveltos")
+		Expect(err).To(BeNil())
+		Expect(applySet.Kind).To(Equal(deploy.ApplySetParentConfigMap))
+		Expect(applySet.Name).To(Equal("crds"))
+
+		applySet, err = deploy.ParseApplySet("crds", "projectsveltos")
+		Expect(err).To(BeNil())
+		Expect(applySet.Kind).To(Equal(deploy.ApplySetParentSecret))
+		Expect(applySet.ID()).To(HavePrefix("applyset-"))
+		Expect(applySet.ID()).To(HaveSuffix("-v1"))
+
+		_, err = deploy.ParseApplySet("deployment/crds", "projectsveltos")
+		Expect(err).ToNot(BeNil())
+		_, err = deploy.ParseApplySet("secret/", "projectsveltos")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("creates the parent and labels the deployed CRDs as members", func() {
+		c := newFakeClient()
+		opts := applySetOptions(nil)
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+
+		parent := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "projectsveltos", Name: "crd-manager"},
+			parent)).To(Succeed())
+		Expect(parent.Labels).To(HaveKeyWithValue(deploy.ApplySetParentIDLabel, opts.ApplySet.ID()))
+		Expect(parent.Annotations).To(HaveKeyWithValue(deploy.ApplySetToolingAnnotation, deploy.ApplySetTooling))
+		Expect(parent.Annotations).To(HaveKeyWithValue(deploy.ApplySetGKsAnnotation,
+			"CustomResourceDefinition.apiextensions.k8s.io"))
+
+		members := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), members,
+			client.MatchingLabels{deploy.ApplySetPartOfLabel: opts.ApplySet.ID()})).To(Succeed())
+		Expect(members.Items).To(HaveLen(len(getBundleCRDs())))
+	})
+
+	It("prunes members no longer part of the bundle", func() {
+		foreign := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "foreigns.example.com"},
+		}
+		c := newFakeClient(foreign)
+
+		_, err := deploy.Deploy(context.TODO(), c, applySetOptions(nil), logger)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), c, applySetOptions(subsetBundle(2)), logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(2))
+		Expect(report.Count(deploy.ActionPruned)).To(Equal(len(getBundleCRDs()) - 2))
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(HaveLen(3))
+
+		// members added back when the bundle contains them again
+		report, err = deploy.Deploy(context.TODO(), c, applySetOptions(nil), logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(getBundleCRDs()) - 2))
+		Expect(report.Count(deploy.ActionPruned)).To(BeZero())
+	})
+
+	It("does not prune after a failure", func() {
+		c := newRejectingClient(getBundleCRDs()[1].GetName())
+		opts := applySetOptions(subsetBundle(2))
+		orphan := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "orphans.lib.projectsveltos.io",
+				Labels: map[string]string{deploy.ApplySetPartOfLabel: opts.ApplySet.ID()},
+			},
+		}
+		Expect(c.Create(context.TODO(), orphan)).To(Succeed())
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Count(deploy.ActionPruned)).To(BeZero())
+
+		report, err = deploy.Deploy(context.TODO(), c, applySetOptions(subsetBundle(1)), logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionPruned)).To(Equal(1))
+		Expect(report.Removed).To(ConsistOf(HaveField("Name", orphan.Name)))
+	})
+
+	It("refuses a parent managed by another tool", func() {
+		parent := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "projectsveltos",
+				Name:        "crd-manager",
+				Annotations: map[string]string{deploy.ApplySetToolingAnnotation: "kubectl/v1.30.0"},
+			},
+		}
+		c := newFakeClient(parent)
+
+		_, err := deploy.Deploy(context.TODO(), c, applySetOptions(nil), logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("kubectl"))
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(BeEmpty())
+	})
+})
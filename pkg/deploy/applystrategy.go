@@ -0,0 +1,232 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ApplyStrategy tells how outdated CRDs are written
+type ApplyStrategy string
+
+const (
+	// ApplyStrategyUpdate replaces the whole live CRD with the desired one
+	ApplyStrategyUpdate = ApplyStrategy("update")
+
+	// ApplyStrategyPatch sends a merge patch restricted to the spec and the
+	// labels/annotations crd-manager sets. Conflicts are retried.
+	ApplyStrategyPatch = ApplyStrategy("patch")
+
+	// ApplyStrategyServerSide creates and updates CRDs with server-side
+	// apply: the API server tracks the ownership of every field, fields set
+	// by other field managers only are left untouched and the ones they set
+	// to different values fail the CRD, unless ForceConflicts is set. Spec
+	// fields other managers add, the bundle not setting them, are kept: the
+	// CRD is then reported as drifted and applied on every run. The fields
+	// previous crd-manager updates own are handed over to the field manager
+	// before a CRD is first applied.
+	ApplyStrategyServerSide = ApplyStrategy("server-side")
+)
+
+// DefaultFieldManager is the default field manager of the CRD writes
+const DefaultFieldManager = "crd-manager"
+
+// legacyFieldManager is the field manager the API server named the CRD writes
+// after, from the crd-manager binary, before crd-manager set one
+const legacyFieldManager = "manager"
+
+// maxFieldManagerLength is the longest field manager the API server accepts
+const maxFieldManagerLength = 128
+
+func (o *Options) validateApplyStrategy() error {
+	switch o.ApplyStrategy {
+	case "", ApplyStrategyUpdate, ApplyStrategyPatch, ApplyStrategyServerSide:
+	default:
+		return fmt.Errorf("invalid apply strategy %q: expected %s, %s or %s",
+			o.ApplyStrategy, ApplyStrategyUpdate, ApplyStrategyPatch, ApplyStrategyServerSide)
+	}
+	if o.ForceConflicts && o.ApplyStrategy != ApplyStrategyServerSide {
+		return fmt.Errorf("forcing conflicts requires the %s apply strategy", ApplyStrategyServerSide)
+	}
+	if len(o.FieldManager) > maxFieldManagerLength {
+		return fmt.Errorf("invalid field manager %q: must be at most %d characters long",
+			o.FieldManager, maxFieldManagerLength)
+	}
+	if strings.IndexFunc(o.FieldManager, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return fmt.Errorf("invalid field manager %q: only printable characters are allowed", o.FieldManager)
+	}
+	return nil
+}
+
+// fieldManager returns the field manager of the CRD writes
+func (o *Options) fieldManager() client.FieldOwner {
+	if o.FieldManager == "" {
+		return DefaultFieldManager
+	}
+	return client.FieldOwner(o.FieldManager)
+}
+
+// applyCRD creates or updates the CRD u with a server-side apply request.
+// The request carries no resourceVersion: the API server resolves concurrent
+// writes through the field ownership instead.
+func applyCRD(ctx context.Context, c client.Client, u *unstructured.Unstructured, validation string,
+	opts *Options) error {
+
+	applied := withoutServerFields(u)
+	data, err := json.Marshal(applied.Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal CRD %s: %w", u.GetName(), err)
+	}
+
+	patchOpts := []client.PatchOption{opts.fieldManager(), client.FieldValidation(validation)}
+	if opts.ForceConflicts {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+	err = traceStep(ctx, "Write", func(ctx context.Context) error {
+		return c.Patch(ctx, applied, client.RawPatch(types.ApplyPatchType, data), patchOpts...)
+	}, attribute.String(AttributeOperation, "apply"))
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("server-side apply of CRD %s conflicts with other field managers, "+
+			"run crd-manager with --force-conflicts to take their fields over: %w", u.GetName(), err)
+	}
+	return err
+}
+
+// upgradeManagedFields hands the fields of live owned by the crd-manager
+// updates, as written before ApplyStrategyServerSide was used, over to its
+// server-side apply field manager. Otherwise the first apply conflicts on
+// every field the bundle changes and leaves the fields the bundle removes.
+// Nothing is written when there is nothing to hand over.
+func upgradeManagedFields(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	opts *Options, logger logr.Logger) error {
+
+	fieldManager := string(opts.fieldManager())
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, sets.New(fieldManager, legacyFieldManager), fieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("handing the fields of Sveltos CRD %s over to server-side apply "+
+		"field manager %s", live.GetName(), fieldManager))
+	return traceStep(ctx, "Write", func(ctx context.Context) error {
+		return c.Patch(ctx, live, client.RawPatch(types.JSONPatchType, patch))
+	}, attribute.String(AttributeOperation, "upgrade-managed-fields"))
+}
+
+// patchCRD patches live with the spec and the labels/annotations of u,
+// recording the update in the audit log. Nothing is written when the patch
+// is empty.
+func patchCRD(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	u *unstructured.Unstructured, validation string, entry *AuditEntry, opts *Options, result *CRDResult,
+	logger logr.Logger) error {
+
+	desired, err := toCustomResourceDefinition(u)
+	if err != nil {
+		return err
+	}
+
+	written := false
+	refresh := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		written = false
+		if refresh {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("conflict patching Sveltos CRD %s, retrying", u.GetName()))
+			if err := getCRD(ctx, c, u.GetName(), live); err != nil {
+				return err
+			}
+		}
+		refresh = true
+
+		patched := withOwnedFields(live, desired)
+		data, err := client.MergeFrom(live).Data(patched)
+		if err != nil {
+			return err
+		}
+		if string(data) == "{}" {
+			return nil
+		}
+
+		written = true
+		// The resourceVersion makes a concurrent write fail with a conflict
+		// instead of being overwritten
+		patch := client.MergeFromWithOptions(live, client.MergeFromWithOptimisticLock{})
+		return traceStep(ctx, "Write", func(ctx context.Context) error {
+			return c.Patch(ctx, patched, patch, opts.fieldManager(), client.FieldValidation(validation))
+		}, attribute.String(AttributeOperation, "patch"))
+	})
+	if err == nil && !written {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is up to date", u.GetName()))
+		result.Action = ActionUnchanged
+		return nil
+	}
+	return auditWrite(opts, entry, wrapWriteError(u.GetName(), err), logger)
+}
+
+// withOwnedFields returns a copy of live with the spec and the labels/annotations
+// of desired. Other labels/annotations of live are left untouched, but for a
+// stale audit or last-applied-configuration annotation and the stale ones
+// crd-manager injected.
+func withOwnedFields(live, desired *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceDefinition {
+	patched := live.DeepCopy()
+	patched.Spec = *desired.Spec.DeepCopy()
+
+	if len(desired.Labels) > 0 && patched.Labels == nil {
+		patched.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		patched.Labels[k] = v
+	}
+
+	if len(desired.Annotations) > 0 && patched.Annotations == nil {
+		patched.Annotations = map[string]string{}
+	}
+	for k, v := range desired.Annotations {
+		patched.Annotations[k] = v
+	}
+	for _, k := range []string{auditAnnotation, corev1.LastAppliedConfigAnnotation} {
+		if _, ok := desired.Annotations[k]; !ok {
+			delete(patched.Annotations, k)
+		}
+	}
+
+	labels, annotations := staleInjectedMetadata(live, desired)
+	for _, k := range labels {
+		delete(patched.Labels, k)
+	}
+	for _, k := range annotations {
+		delete(patched.Annotations, k)
+	}
+	return patched
+}
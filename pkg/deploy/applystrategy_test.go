@@ -0,0 +1,204 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// writeCounts counts the updates and patches of CRDs
+type writeCounts struct {
+	updates int
+	patches int
+}
+
+// newWriteCountingClient returns a client counting the CRD writes, the first
+// conflicts patches failing with a conflict
+func newWriteCountingClient(counts *writeCounts, conflicts int, objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			counts.updates++
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+			opts ...client.PatchOption) error {
+
+			counts.patches++
+			if counts.patches <= conflicts {
+				return apierrors.NewConflict(schema.GroupResource{Group: "apiextensions.k8s.io",
+					Resource: "customresourcedefinitions"}, obj.GetName(), nil)
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("ApplyStrategy", func() {
+	outdatedCRD := func() *apiextensionsv1.CustomResourceDefinition {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue, "team": "platform"}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		return crd
+	}
+
+	It("patch writes outdated CRDs with a patch, keeping foreign labels", func() {
+		counts := &writeCounts{}
+		c := newWriteCountingClient(counts, 0, outdatedCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ApplyStrategy: deploy.ApplyStrategyPatch}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+		Expect(counts.updates).To(BeZero())
+		Expect(counts.patches).To(Equal(1))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(Equal(getBundleCRD(sveltosClusterCRD).Spec.Names.ShortNames))
+		Expect(current.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(current.Annotations).To(HaveKey(deploy.AppliedByAnnotation))
+	})
+
+	It("patch retries conflicts", func() {
+		counts := &writeCounts{}
+		c := newWriteCountingClient(counts, 2, outdatedCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ApplyStrategy: deploy.ApplyStrategyPatch}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+		Expect(counts.patches).To(Equal(3))
+	})
+
+	It("patch does not write up to date CRDs", func() {
+		counts := &writeCounts{}
+		c := newWriteCountingClient(counts, 0)
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		counts.updates, counts.patches = 0, 0
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ApplyStrategy: deploy.ApplyStrategyPatch}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+		Expect(counts.updates + counts.patches).To(BeZero())
+	})
+
+	It("update replaces the whole CRD", func() {
+		counts := &writeCounts{}
+		c := newWriteCountingClient(counts, 0, outdatedCRD())
+
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(counts.updates).To(Equal(1))
+		Expect(counts.patches).To(BeZero())
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Labels).ToNot(HaveKey("team"))
+	})
+
+	It("server-side applies CRDs under the field manager, surfacing conflicts unless forced", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithReturnManagedFields().Build()
+		opts := &deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide, FieldManager: "sveltos-crds"}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.ManagedFields).To(ContainElement(And(
+			HaveField("Manager", "sveltos-crds"), HaveField("Operation", metav1.ManagedFieldsOperationApply))))
+
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+
+		// Another tool changes a field crd-manager owns and adds a label
+		current.Spec.Names.Singular = "cluster"
+		current.Labels["team"] = "platform"
+		current.ManagedFields = nil
+		Expect(c.Update(context.TODO(), current, client.FieldOwner("helm"))).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.Error).To(ContainSubstring("conflicts with other field managers"))
+		Expect(result.Error).To(ContainSubstring("--force-conflicts"))
+
+		opts.ForceConflicts = true
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.Singular).To(Equal(getBundleCRD(sveltosClusterCRD).Spec.Names.Singular))
+		Expect(current.Labels).To(HaveKeyWithValue("team", "platform"))
+	})
+
+	DescribeTable("server-side applies CRDs created and updated by previous crd-manager updates",
+		func(previousFieldManager string) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithReturnManagedFields().Build()
+			// The previous bundle has a short name the current one removes
+			Expect(c.Create(context.TODO(), outdatedCRD(), client.FieldOwner(previousFieldManager))).To(Succeed())
+
+			report, err := deploy.Deploy(context.TODO(), c,
+				&deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide}, logger)
+			Expect(err).To(BeNil())
+			Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+			current := getCRD(c, sveltosClusterCRD)
+			Expect(current.Spec.Names.ShortNames).To(Equal(getBundleCRD(sveltosClusterCRD).Spec.Names.ShortNames))
+			Expect(current.ManagedFields).To(ConsistOf(And(
+				HaveField("Manager", deploy.DefaultFieldManager),
+				HaveField("Operation", metav1.ManagedFieldsOperationApply))))
+
+			report, err = deploy.Deploy(context.TODO(), c,
+				&deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide}, logger)
+			Expect(err).To(BeNil())
+			Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUnchanged))
+		},
+		Entry("previous releases, setting no field manager", "manager"),
+		Entry("the update strategy", deploy.DefaultFieldManager),
+	)
+
+	It("invalid apply strategies are rejected", func() {
+		opts := &deploy.Options{ApplyStrategy: "replace"}
+		Expect(opts.Validate()).To(MatchError(ContainSubstring("invalid apply strategy")))
+
+		opts = &deploy.Options{ForceConflicts: true}
+		Expect(opts.Validate()).To(MatchError(ContainSubstring("requires the server-side apply strategy")))
+
+		opts = &deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide, FieldManager: "crd-manager/v1"}
+		Expect(opts.Validate()).To(Succeed())
+		opts.FieldManager = "crd\tmanager"
+		Expect(opts.Validate()).To(MatchError(ContainSubstring("invalid field manager")))
+	})
+})
@@ -0,0 +1,75 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	// auditAnnotation records, on each CRD, how its content deviates from the
+	// stock bundle
+	auditAnnotation = "projectsveltos.io/crd-manager-audit"
+)
+
+// audit is the content of the auditAnnotation
+type audit struct {
+	// Modifications lists, in the order they were applied, the changes made by
+	// crd-manager to the bundle CRD
+	Modifications []string `json:"modifications,omitempty"`
+
+	// Source is, when the bundle is merged from several sources, the one the
+	// CRD is taken from
+	Source *bundle.Provenance `json:"source,omitempty"`
+}
+
+func (a *audit) addModification(format string, args ...any) {
+	a.Modifications = append(a.Modifications, fmt.Sprintf(format, args...))
+}
+
+func (a *audit) isEmpty() bool {
+	return len(a.Modifications) == 0 && a.Source == nil
+}
+
+// setOn stores the audit in the auditAnnotation of u. The annotation is removed
+// when there is nothing to record.
+func (a *audit) setOn(u *unstructured.Unstructured) error {
+	annotations := u.GetAnnotations()
+	if a.isEmpty() {
+		if _, ok := annotations[auditAnnotation]; ok {
+			delete(annotations, auditAnnotation)
+			u.SetAnnotations(annotations)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit annotation: %w", err)
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[auditAnnotation] = string(data)
+	u.SetAnnotations(annotations)
+	return nil
+}
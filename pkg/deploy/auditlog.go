@@ -0,0 +1,286 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuditFailurePolicy tells what happens when an audit log entry cannot be written
+type AuditFailurePolicy string
+
+const (
+	// AuditFailureFatal fails the run
+	AuditFailureFatal AuditFailurePolicy = "fatal"
+
+	// AuditFailureWarn logs a warning and carries on
+	AuditFailureWarn AuditFailurePolicy = "warn"
+)
+
+// AuditAction is the write operation an audit log entry records
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditOutcome is the outcome of the write operation an audit log entry records
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+
+	// AuditOutcomeObserved marks, in observe-only mode, writes which were not
+	// performed but would have been
+	AuditOutcomeObserved AuditOutcome = "observed"
+)
+
+// AuditEntry is a line of the audit log
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// CRD is the CustomResourceDefinition name
+	CRD string `json:"crd"`
+
+	Action AuditAction `json:"action"`
+
+	// BundleDigest is the digest of the bundle being deployed
+	BundleDigest string `json:"bundleDigest"`
+
+	// ChangedPaths lists the metadata and spec paths an update changes. It is
+	// empty for creations and deletions, which change the whole object.
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+
+	Outcome AuditOutcome `json:"outcome"`
+
+	// Error is set when the write failed
+	Error string `json:"error,omitempty"`
+
+	// WouldHave is set on the writes which, in observe-only mode, were not
+	// performed
+	WouldHave bool `json:"wouldHave,omitempty"`
+}
+
+// AuditLog is an append-only JSON-lines record of every write to CRDs. Each
+// entry is synced to disk as soon as it is written.
+type AuditLog struct {
+	// FailurePolicy tells what happens when an entry cannot be written
+	FailurePolicy AuditFailurePolicy
+
+	// RecordObserved makes observe-only runs record the writes they would
+	// have performed
+	RecordObserved bool
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog opens, creating it if needed, the audit log file at path in
+// append-only mode
+func OpenAuditLog(path string, policy AuditFailurePolicy, recordObserved bool) (*AuditLog, error) {
+	if policy != AuditFailureFatal && policy != AuditFailureWarn {
+		return nil, fmt.Errorf("unsupported audit log failure policy %q (supported: %s, %s)",
+			policy, AuditFailureFatal, AuditFailureWarn)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{FailurePolicy: policy, RecordObserved: recordObserved, file: file}, nil
+}
+
+// Close closes the audit log file
+func (a *AuditLog) Close() error {
+	return a.file.Close()
+}
+
+// Record appends entry to the audit log, as a single write, and syncs it to disk
+func (a *AuditLog) Record(entry *AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(data); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// auditWrite records in the audit log, if any, entry: a write whose outcome
+// is err. The returned error is err, joined, with the fatal failure policy,
+// with the failure to record entry.
+func auditWrite(opts *Options, entry *AuditEntry, err error, logger logr.Logger) error {
+	if opts.AuditLog == nil {
+		return err
+	}
+
+	entry.Timestamp = time.Now().UTC()
+	entry.BundleDigest = opts.getBundle().Digest()
+	entry.Outcome = AuditOutcomeSuccess
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Error = err.Error()
+	}
+
+	recordErr := opts.AuditLog.Record(entry)
+	if recordErr == nil {
+		return err
+	}
+	if opts.AuditLog.FailurePolicy == AuditFailureWarn {
+		logWarning(logger, "failed to record %s of CRD %s in the audit log: %v", entry.Action, entry.CRD, recordErr)
+		return err
+	}
+	return errors.Join(err, fmt.Errorf("failed to record %s of CRD %s in the audit log: %w",
+		entry.Action, entry.CRD, recordErr))
+}
+
+// auditObserved records in the audit log, if any and if configured to, a write
+// observe-only mode did not perform
+func auditObserved(opts *Options, entry *AuditEntry, logger logr.Logger) error {
+	if opts.AuditLog == nil || !opts.AuditLog.RecordObserved {
+		return nil
+	}
+
+	entry.Timestamp = time.Now().UTC()
+	entry.BundleDigest = opts.getBundle().Digest()
+	entry.Outcome = AuditOutcomeObserved
+	entry.WouldHave = true
+	if err := opts.AuditLog.Record(entry); err != nil {
+		if opts.AuditLog.FailurePolicy == AuditFailureWarn {
+			logWarning(logger, "failed to record %s of CRD %s in the audit log: %v", entry.Action, entry.CRD, err)
+			return nil
+		}
+		return fmt.Errorf("failed to record %s of CRD %s in the audit log: %w", entry.Action, entry.CRD, err)
+	}
+	return nil
+}
+
+// changedPaths returns, sorted, the labels, annotations and spec paths which
+// differ between before and after. The API server defaults are applied to
+// both specs first.
+func changedPaths(before, after *apiextensionsv1.CustomResourceDefinition) ([]string, error) {
+	changes, err := fieldChanges(before, after)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(changes))
+	for i := range changes {
+		paths[i] = changes[i].Path
+	}
+	return paths, nil
+}
+
+// fieldChanges returns, sorted by path, the labels, annotations and spec
+// fields which differ between live and desired, with their values
+func fieldChanges(live, desired *apiextensionsv1.CustomResourceDefinition) ([]FieldChange, error) {
+	liveMap, err := auditedContent(live)
+	if err != nil {
+		return nil, err
+	}
+	desiredMap, err := auditedContent(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	diffFields("", liveMap, desiredMap, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// auditedContent returns the part of crd an audit log entry describes
+func auditedContent(crd *apiextensionsv1.CustomResourceDefinition) (map[string]interface{}, error) {
+	normalized := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Labels: crd.Labels, Annotations: crd.Annotations},
+		Spec:       *crd.Spec.DeepCopy(),
+	}
+	apiextensionsv1.SetObjectDefaults_CustomResourceDefinition(normalized)
+
+	content, err := toUnstructuredMap(normalized)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	return content, nil
+}
+
+// diffFields appends to changes the fields, below prefix, where before and after differ
+func diffFields(prefix string, before, after interface{}, changes *[]FieldChange) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	// a missing map (like absent labels) lists the keys set on the other side
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) {
+		for key := range beforeMap {
+			diffFields(joinPath(prefix, key), beforeMap[key], afterMap[key], changes)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				diffFields(joinPath(prefix, key), nil, afterMap[key], changes)
+			}
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		for i := range beforeList {
+			diffFields(fmt.Sprintf("%s[%d]", prefix, i), beforeList[i], afterList[i], changes)
+		}
+		return
+	}
+
+	*changes = append(*changes, FieldChange{Path: prefix, Live: before, Desired: after})
+}
+
+var simplePathKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// joinPath appends key to path, quoting keys containing dots or slashes
+// (like label keys)
+func joinPath(path, key string) string {
+	if !simplePathKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
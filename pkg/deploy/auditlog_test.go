@@ -0,0 +1,226 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// openAuditLog opens an audit log in a temporary directory, returning it
+// along with its path
+func openAuditLog(policy deploy.AuditFailurePolicy, recordObserved bool) (*deploy.AuditLog, string) {
+	path := filepath.Join(GinkgoT().TempDir(), "audit.jsonl")
+	auditLog, err := deploy.OpenAuditLog(path, policy, recordObserved)
+	Expect(err).To(BeNil())
+	DeferCleanup(func() { _ = auditLog.Close() })
+	return auditLog, path
+}
+
+// readAuditLog returns the entries of the audit log at path
+func readAuditLog(path string) []deploy.AuditEntry {
+	file, err := os.Open(path)
+	Expect(err).To(BeNil())
+	defer file.Close()
+
+	var entries []deploy.AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry deploy.AuditEntry
+		Expect(json.Unmarshal(scanner.Bytes(), &entry)).To(Succeed())
+		entries = append(entries, entry)
+	}
+	Expect(scanner.Err()).To(BeNil())
+	return entries
+}
+
+var _ = Describe("AuditLog", func() {
+	It("records one entry per CRD created", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newFakeClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).To(BeNil())
+
+		entries := readAuditLog(path)
+		Expect(entries).To(HaveLen(len(report.CRDs)))
+		for _, entry := range entries {
+			Expect(entry.Action).To(Equal(deploy.AuditActionCreate))
+			Expect(entry.Outcome).To(Equal(deploy.AuditOutcomeSuccess))
+			Expect(entry.BundleDigest).To(Equal(report.BundleDigest))
+			Expect(entry.Timestamp.IsZero()).To(BeFalse())
+			Expect(entry.WouldHave).To(BeFalse())
+		}
+
+		// A run changing nothing records nothing, and entries are appended
+		_, err = deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).To(BeNil())
+		Expect(readAuditLog(path)).To(Equal(entries))
+	})
+
+	It("records the paths an update changes", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newFakeClient(outdatedCRD())
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).To(BeNil())
+		Expect(readAuditLog(path)).To(ContainElement(And(
+			HaveField("CRD", sveltosClusterCRD),
+			HaveField("Action", deploy.AuditActionUpdate),
+			HaveField("ChangedPaths", ContainElement("spec.names.shortNames")))))
+	})
+
+	It("records the ownership markers two-phase adoption adds", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newFakeClient(outdatedCRD())
+		opts := &deploy.Options{AuditLog: auditLog, AdoptExisting: deploy.AdoptionTwoPhase}
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(readAuditLog(path)).To(ContainElement(And(
+			HaveField("CRD", sveltosClusterCRD),
+			HaveField("Action", deploy.AuditActionUpdate),
+			HaveField("ChangedPaths", ConsistOf(
+				`metadata.labels["`+deploy.ManagedByLabel+`"]`,
+				`metadata.annotations["`+deploy.AdoptedAtAnnotation+`"]`)))))
+	})
+
+	It("records failed writes", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newRejectingClient(sveltosClusterCRD)
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(readAuditLog(path)).To(ContainElement(And(
+			HaveField("CRD", sveltosClusterCRD),
+			HaveField("Outcome", deploy.AuditOutcomeFailure),
+			HaveField("Error", ContainSubstring("rejected by webhook")))))
+	})
+
+	It("records the deletion of obsolete CRDs", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newFakeClient(obsoleteCRD())
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog, RemoveObsolete: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(readAuditLog(path)).To(ContainElement(And(
+			HaveField("CRD", obsoleteCRD().Name),
+			HaveField("Action", deploy.AuditActionDelete),
+			HaveField("Outcome", deploy.AuditOutcomeSuccess))))
+	})
+
+	It("records in observe-only mode the writes which would have been performed, if configured to", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, true)
+		c := newReadOnlyClient(outdatedCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog, ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+
+		entries := readAuditLog(path)
+		Expect(entries).To(HaveLen(len(report.CRDs)))
+		Expect(entries).To(HaveEach(And(
+			HaveField("WouldHave", BeTrue()),
+			HaveField("Outcome", deploy.AuditOutcomeObserved))))
+		Expect(entries).To(ContainElement(And(
+			HaveField("CRD", sveltosClusterCRD),
+			HaveField("Action", deploy.AuditActionUpdate),
+			HaveField("ChangedPaths", ContainElement("spec.names.shortNames")))))
+	})
+
+	It("records nothing in observe-only mode by default", func() {
+		auditLog, path := openAuditLog(deploy.AuditFailureFatal, false)
+		c := newReadOnlyClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog, ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(readAuditLog(path)).To(BeEmpty())
+	})
+
+	It("fails the run when an entry cannot be written with the fatal policy", func() {
+		auditLog, _ := openAuditLog(deploy.AuditFailureFatal, false)
+		Expect(auditLog.Close()).To(Succeed())
+		c := newFakeClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).To(MatchError(ContainSubstring("audit log")))
+	})
+
+	It("only warns when an entry cannot be written with the warn policy", func() {
+		auditLog, _ := openAuditLog(deploy.AuditFailureWarn, false)
+		Expect(auditLog.Close()).To(Succeed())
+		c := newFakeClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{AuditLog: auditLog}, logger)
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects unknown failure policies", func() {
+		_, err := deploy.OpenAuditLog(filepath.Join(GinkgoT().TempDir(), "audit.jsonl"), "ignore", false)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("opens the file in append mode", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "audit.jsonl")
+		Expect(os.WriteFile(path, []byte(`{"crd":"previous"}`+"\n"), 0o600)).To(Succeed())
+
+		auditLog, err := deploy.OpenAuditLog(path, deploy.AuditFailureFatal, false)
+		Expect(err).To(BeNil())
+		Expect(auditLog.Record(&deploy.AuditEntry{CRD: "next"})).To(Succeed())
+		Expect(auditLog.Close()).To(Succeed())
+
+		entries := readAuditLog(path)
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].CRD).To(Equal("previous"))
+		Expect(entries[1].CRD).To(Equal("next"))
+	})
+})
+
+var _ = Describe("ChangedPaths", func() {
+	It("is empty for identical CRDs", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		paths, err := deploy.ChangedPaths(crd, crd.DeepCopy())
+		Expect(err).To(BeNil())
+		Expect(paths).To(BeEmpty())
+	})
+
+	It("lists changed, added and removed fields", func() {
+		before := getBundleCRD(sveltosClusterCRD)
+		before.Labels = map[string]string{"app.kubernetes.io/name": "sveltos", "team": "a"}
+		after := before.DeepCopy()
+		after.Labels = map[string]string{"team": "b"}
+		after.Spec.Names.ShortNames = []string{"sc"}
+		after.Spec.Versions[0].Served = !after.Spec.Versions[0].Served
+
+		paths, err := deploy.ChangedPaths(before, after)
+		Expect(err).To(BeNil())
+		Expect(paths).To(ConsistOf(
+			`metadata.labels["app.kubernetes.io/name"]`,
+			"metadata.labels.team",
+			"spec.names.shortNames",
+			"spec.versions[0].served",
+		))
+	})
+})
@@ -0,0 +1,244 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultCircuitBreakerThreshold is the default number of distinct CRDs
+	// which must fail in a row, with a server-side unavailability error, to
+	// open the CircuitBreaker
+	DefaultCircuitBreakerThreshold = 5
+
+	// DefaultCircuitBreakerCoolDown is the default time an open
+	// CircuitBreaker stops the CRD requests for
+	DefaultCircuitBreakerCoolDown = 30 * time.Second
+
+	// EventReasonCircuitBreakerOpen is the reason of the Event recorded, for
+	// the CRD whose failure opened it, when the CircuitBreaker opens
+	EventReasonCircuitBreakerOpen = "CircuitBreakerOpen"
+
+	eventActionDeploy = "Deploy"
+)
+
+// CircuitBreakerState is the state of a CircuitBreaker
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerClosed means CRDs are processed
+	CircuitBreakerClosed = CircuitBreakerState("closed")
+
+	// CircuitBreakerOpen means CRDs are not processed until the cool-down
+	// expires
+	CircuitBreakerOpen = CircuitBreakerState("open")
+
+	// CircuitBreakerHalfOpen means the cool-down expired: the next CRD is
+	// processed as a probe, closing the CircuitBreaker unless it fails with a
+	// server-side unavailability error, which opens it again
+	CircuitBreakerHalfOpen = CircuitBreakerState("half-open")
+)
+
+// CircuitBreaker stops processing CRDs while the API server looks unavailable.
+// Once Threshold distinct CRDs failed in a row with a server-side
+// unavailability error (5xx, 429 Too Many Requests or a timeout), it opens:
+// the run stops, reporting the remaining CRDs as ActionNotAttempted, and so do
+// the runs starting within CoolDown. The first run after it processes a
+// single CRD as a probe before resuming. Other errors, such as validation or
+// forbidden ones, are deterministic: they neither count nor reset the failures.
+// A CircuitBreaker keeps its state across the runs sharing it, and is safe for
+// concurrent use.
+type CircuitBreaker struct {
+	// Threshold is the number of distinct CRDs which must fail in a row to
+	// open the CircuitBreaker. Defaults to DefaultCircuitBreakerThreshold.
+	Threshold int
+
+	// CoolDown is the time an open CircuitBreaker stops the CRD requests for.
+	// Defaults to DefaultCircuitBreakerCoolDown.
+	CoolDown time.Duration
+
+	mu        sync.Mutex
+	state     CircuitBreakerState
+	failing   []string
+	lastError string
+	openUntil time.Time
+
+	// now is replaced by tests
+	now func() time.Time
+}
+
+// CircuitBreakerStatus is the state of the CircuitBreaker reported by the runs
+// it stopped
+type CircuitBreakerStatus struct {
+	// State is the CircuitBreaker state
+	State CircuitBreakerState `json:"state"`
+
+	// OpenUntil is when a probe is allowed
+	OpenUntil metav1.Time `json:"openUntil"`
+
+	// Failing are the CRDs whose failures in a row opened the CircuitBreaker
+	Failing []string `json:"failing"`
+
+	// LastError is the error of the last of those failures
+	LastError string `json:"lastError"`
+}
+
+// CircuitOpenError is returned by runs the CircuitBreaker stopped
+type CircuitOpenError struct {
+	Status CircuitBreakerStatus
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open until %s after server-side failures of CRDs %s: %s",
+		e.Status.OpenUntil.UTC().Format(time.RFC3339), strings.Join(e.Status.Failing, ", "), e.Status.LastError)
+}
+
+func (b *CircuitBreaker) validate() error {
+	if b == nil {
+		return nil
+	}
+	if b.Threshold < 0 {
+		return fmt.Errorf("invalid circuit breaker threshold %d: must not be negative", b.Threshold)
+	}
+	if b.CoolDown < 0 {
+		return fmt.Errorf("invalid circuit breaker cool-down %s: must not be negative", b.CoolDown)
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.Threshold > 0 {
+		return b.Threshold
+	}
+	return DefaultCircuitBreakerThreshold
+}
+
+func (b *CircuitBreaker) coolDown() time.Duration {
+	if b.CoolDown > 0 {
+		return b.CoolDown
+	}
+	return DefaultCircuitBreakerCoolDown
+}
+
+func (b *CircuitBreaker) currentTime() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// allow returns an error if the next CRD must not be processed. Once the
+// cool-down expired, it lets one probe through.
+func (b *CircuitBreaker) allow(logger logr.Logger) *CircuitOpenError {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitBreakerOpen {
+		return nil
+	}
+	if b.currentTime().Before(b.openUntil) {
+		return &CircuitOpenError{Status: b.status()}
+	}
+	b.state = CircuitBreakerHalfOpen
+	logWarning(logger, "circuit breaker half-open: processing one Sveltos CRD as a probe")
+	return nil
+}
+
+// record records the outcome of processing crd, opening the CircuitBreaker
+// if it is the failure reaching the threshold, or that of the probe
+func (b *CircuitBreaker) record(crd *unstructured.Unstructured, err error, opts *Options, logger logr.Logger) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isServerUnavailable(err) {
+		if err == nil || b.state == CircuitBreakerHalfOpen {
+			// the API server answered
+			if b.state == CircuitBreakerHalfOpen {
+				logger.V(logs.LogInfo).Info("circuit breaker closed: the probe reached the API server")
+			}
+			b.state = CircuitBreakerClosed
+			b.failing = nil
+		}
+		return
+	}
+
+	if !slices.Contains(b.failing, crd.GetName()) {
+		b.failing = append(b.failing, crd.GetName())
+	}
+	b.lastError = err.Error()
+	if b.state != CircuitBreakerHalfOpen && len(b.failing) < b.threshold() {
+		return
+	}
+
+	b.state = CircuitBreakerOpen
+	b.openUntil = b.currentTime().Add(b.coolDown())
+	logWarning(logger, "circuit breaker open until %s: Sveltos CRDs %s failed in a row with server-side errors, last: %v",
+		b.openUntil.UTC().Format(time.RFC3339), strings.Join(b.failing, ", "), err)
+	recordEvent(opts, crd, EventReasonCircuitBreakerOpen, eventActionDeploy,
+		"circuit breaker open for %s after server-side failures of CRDs %s: %v",
+		b.coolDown(), strings.Join(b.failing, ", "), err)
+}
+
+// status returns the CircuitBreaker status. The caller holds b.mu.
+func (b *CircuitBreaker) status() CircuitBreakerStatus {
+	return CircuitBreakerStatus{
+		State:     b.state,
+		OpenUntil: metav1.NewTime(b.openUntil),
+		Failing:   slices.Clone(b.failing),
+		LastError: b.lastError,
+	}
+}
+
+// isServerUnavailable returns true if err looks like the API server, rather
+// than the request, is the problem: a 5xx or 429 status, or a timeout
+func isServerUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		code := status.Status().Code
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
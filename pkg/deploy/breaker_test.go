@@ -0,0 +1,213 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		creates   atomic.Int32
+		createErr atomic.Pointer[error]
+		now       time.Time
+		breaker   *deploy.CircuitBreaker
+	)
+
+	BeforeEach(func() {
+		creates.Store(0)
+		createErr.Store(nil)
+		now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		breaker = &deploy.CircuitBreaker{Threshold: 3, CoolDown: time.Minute}
+		deploy.SetCircuitBreakerClock(breaker, func() time.Time { return now })
+	})
+
+	// newFailingClient returns a fake client whose CRD creations count, and
+	// fail with createErr if set
+	newFailingClient := func() client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				creates.Add(1)
+				if err := createErr.Load(); err != nil {
+					return *err
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+	}
+
+	failWith := func(err error) {
+		createErr.Store(&err)
+	}
+
+	unavailable := apierrors.NewServiceUnavailable("overloaded")
+
+	It("classifies server-side unavailability errors", func() {
+		gr := schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}
+		for _, err := range []error{
+			unavailable,
+			apierrors.NewInternalError(errors.New("boom")),
+			apierrors.NewTooManyRequests("slow down", 1),
+			apierrors.NewServerTimeout(gr, "create", 1),
+			apierrors.NewTimeoutError("timeout", 1),
+			fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			&net.OpError{Op: "dial", Err: timeoutError{}},
+		} {
+			Expect(deploy.IsServerUnavailable(err)).To(BeTrue(), err.Error())
+		}
+		for _, err := range []error{
+			nil,
+			apierrors.NewInvalid(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+				"gadgets.example.com", nil),
+			apierrors.NewForbidden(gr, "gadgets.example.com", errors.New("denied")),
+			apierrors.NewNotFound(gr, "gadgets.example.com"),
+			errors.New("rejected by webhook"),
+			context.Canceled,
+		} {
+			Expect(deploy.IsServerUnavailable(err)).To(BeFalse(), fmt.Sprint(err))
+		}
+	})
+
+	It("stops the run once distinct CRDs failed in a row with unavailability errors", func() {
+		c := newFailingClient()
+		failWith(unavailable)
+		recorder := events.NewFakeRecorder(10)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{CircuitBreaker: breaker, EventRecorder: recorder}, logger)
+		openErr := &deploy.CircuitOpenError{}
+		Expect(errors.As(err, &openErr)).To(BeTrue())
+		Expect(creates.Load()).To(Equal(int32(3)))
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(3))
+		Expect(report.Count(deploy.ActionNotAttempted)).To(Equal(len(report.CRDs) - 3))
+		Expect(report.CircuitBreaker).ToNot(BeNil())
+		Expect(report.CircuitBreaker.State).To(Equal(deploy.CircuitBreakerOpen))
+		Expect(report.CircuitBreaker.OpenUntil.Time).To(Equal(now.Add(time.Minute)))
+		Expect(report.CircuitBreaker.Failing).To(Equal([]string{
+			report.CRDs[0].Name, report.CRDs[1].Name, report.CRDs[2].Name}))
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(ContainSubstring(deploy.EventReasonCircuitBreakerOpen))
+
+		// runs starting within the cool-down send no CRD request
+		now = now.Add(30 * time.Second)
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		Expect(errors.As(err, &openErr)).To(BeTrue())
+		Expect(creates.Load()).To(Equal(int32(3)))
+		Expect(report.Count(deploy.ActionNotAttempted)).To(Equal(len(report.CRDs)))
+	})
+
+	It("counts a CRD exhausting its retries as a single failure", func() {
+		c := newFailingClient()
+		failWith(unavailable)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{CircuitBreaker: breaker, Retries: 2, RetryInterval: time.Millisecond}, logger)
+		openErr := &deploy.CircuitOpenError{}
+		Expect(errors.As(err, &openErr)).To(BeTrue())
+		Expect(creates.Load()).To(Equal(int32(9)))
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(3))
+		Expect(report.CRDs[:3]).To(HaveEach(HaveField("Attempts", 3)))
+		Expect(report.Count(deploy.ActionNotAttempted)).To(Equal(len(report.CRDs) - 3))
+	})
+
+	It("probes with a single CRD once the cool-down expired", func() {
+		c := newFailingClient()
+		failWith(unavailable)
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		Expect(err).ToNot(BeNil())
+
+		// the probe failing opens it again at once
+		now = now.Add(time.Minute)
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		openErr := &deploy.CircuitOpenError{}
+		Expect(errors.As(err, &openErr)).To(BeTrue())
+		Expect(creates.Load()).To(Equal(int32(4)))
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(1))
+		Expect(report.CircuitBreaker.OpenUntil.Time).To(Equal(now.Add(time.Minute)))
+
+		// the probe succeeding closes it
+		now = now.Add(time.Minute)
+		createErr.Store(nil)
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CircuitBreaker).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+	})
+
+	It("is not tripped by deterministic errors", func() {
+		c := newFailingClient()
+		failWith(apierrors.NewForbidden(schema.GroupResource{Resource: "customresourcedefinitions"}, "", errors.New("denied")))
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(errors.As(err, new(*deploy.CircuitOpenError))).To(BeFalse())
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(len(report.CRDs)))
+		Expect(report.CircuitBreaker).To(BeNil())
+	})
+
+	It("resets the failures on success", func() {
+		attempts := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				// every other CRD fails
+				attempts++
+				if attempts%2 == 0 {
+					return unavailable
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{CircuitBreaker: breaker}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(len(report.CRDs) / 2))
+		Expect(report.Count(deploy.ActionNotAttempted)).To(BeZero())
+		Expect(report.CircuitBreaker).To(BeNil())
+	})
+
+	It("rejects negative settings", func() {
+		Expect((&deploy.Options{CircuitBreaker: &deploy.CircuitBreaker{Threshold: -1}}).Validate()).ToNot(Succeed())
+		Expect((&deploy.Options{CircuitBreaker: &deploy.CircuitBreaker{CoolDown: -time.Second}}).Validate()).ToNot(Succeed())
+		Expect((&deploy.Options{CircuitBreaker: &deploy.CircuitBreaker{}}).Validate()).To(Succeed())
+	})
+})
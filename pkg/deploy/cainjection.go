@@ -0,0 +1,95 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// injectCAFromAnnotation makes cert-manager cainjector write the CA of the
+	// referenced Certificate into the CRD conversion webhook caBundle
+	injectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// injectCAFrom adds the cert-manager CA injection annotation to CRDs using a
+// conversion webhook. The per CRD value takes precedence over the global one.
+func injectCAFrom(u *unstructured.Unstructured, opts *Options, logger logr.Logger) {
+	if !usesWebhookConversion(u) {
+		return
+	}
+
+	certificate, ok := opts.InjectCAFromPerCRD[u.GetName()]
+	if !ok {
+		certificate = opts.InjectCAFrom
+	}
+	if certificate == "" {
+		return
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[injectCAFromAnnotation] = certificate
+	u.SetAnnotations(annotations)
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s CA injected from certificate %s",
+		u.GetName(), certificate))
+}
+
+// preserveCABundle copies the conversion webhook caBundle of the live CRD into
+// the desired one, so that updates never strip a caBundle written at runtime
+// (for instance by cert-manager cainjector).
+// When the desired CRD asks for CA injection, the live caBundle always wins.
+// Otherwise it is only used if the desired CRD does not define one.
+func preserveCABundle(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured) error {
+	if !usesWebhookConversion(u) {
+		return nil
+	}
+
+	if live.Spec.Conversion == nil || live.Spec.Conversion.Webhook == nil ||
+		live.Spec.Conversion.Webhook.ClientConfig == nil ||
+		len(live.Spec.Conversion.Webhook.ClientConfig.CABundle) == 0 {
+
+		return nil
+	}
+
+	caBundlePath := []string{"spec", "conversion", "webhook", "clientConfig", "caBundle"}
+	current, _, err := unstructured.NestedString(u.Object, caBundlePath...)
+	if err != nil {
+		return fmt.Errorf("failed to parse conversion webhook caBundle: %w", err)
+	}
+
+	_, injected := u.GetAnnotations()[injectCAFromAnnotation]
+	if current != "" && !injected {
+		return nil
+	}
+
+	// caBundle is []byte in the typed API, which is base64 encoded in unstructured form
+	liveClientConfig, err := toUnstructuredMap(live.Spec.Conversion.Webhook.ClientConfig)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(u.Object, liveClientConfig["caBundle"], caBundlePath...)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	injectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+	injectedCABundle       = "aW5qZWN0ZWQ="
+)
+
+var _ = Describe("CA injection", func() {
+	var opts *deploy.Options
+
+	BeforeEach(func() {
+		opts = &deploy.Options{
+			InjectCAFrom: "projectsveltos/sveltos-webhook-certificate",
+		}
+	})
+
+	// liveCRD creates in the cluster the webhook converting CRD, as applied with
+	// CA injection, and then has its caBundle written by cainjector
+	liveCRD := func() *unstructured.Unstructured {
+		u := parse(crdWithWebhook)
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(unstructured.SetNestedField(u.Object, injectedCABundle,
+			"spec", "conversion", "webhook", "clientConfig", "caBundle")).To(Succeed())
+		return u
+	}
+
+	It("adds the annotation only to CRDs using webhook conversion", func() {
+		u := parse(crdWithWebhook)
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u.GetAnnotations()).To(HaveKeyWithValue(injectCAFromAnnotation, opts.InjectCAFrom))
+
+		u = parse(crdWithoutWebhook)
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u.GetAnnotations()).ToNot(HaveKey(injectCAFromAnnotation))
+	})
+
+	It("per CRD value takes precedence", func() {
+		u := parse(crdWithWebhook)
+		opts.InjectCAFromPerCRD = map[string]string{u.GetName(): "other/certificate"}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u.GetAnnotations()).To(HaveKeyWithValue(injectCAFromAnnotation, "other/certificate"))
+	})
+
+	It("invalid certificate references are rejected", func() {
+		opts.InjectCAFrom = "certificate"
+		Expect(opts.Validate()).ToNot(Succeed())
+
+		opts.InjectCAFrom = ""
+		opts.InjectCAFromPerCRD = map[string]string{"widgets.lib.projectsveltos.io": "a/b/c"}
+		Expect(opts.Validate()).ToNot(Succeed())
+	})
+
+	It("injected caBundle is preserved and does not cause an update", func() {
+		live := liveCRD()
+		c := newFakeClient(live)
+
+		desired := parse(crdWithWebhook)
+		Expect(deploy.ApplyMutations(desired, opts, logger)).To(Succeed())
+
+		result := &deploy.CRDResult{}
+		Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhook), desired, opts, result, logger)).To(Succeed())
+		Expect(result.Action).To(Equal(deploy.ActionUnchanged))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: live.GetName()}, current)).To(Succeed())
+		Expect(current.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal([]byte("injected")))
+	})
+
+	It("injected caBundle is preserved when the CRD is updated", func() {
+		live := liveCRD()
+		c := newFakeClient(live)
+
+		desired := parse(crdWithWebhook)
+		Expect(unstructured.SetNestedStringSlice(desired.Object, []string{"wg"},
+			"spec", "names", "shortNames")).To(Succeed())
+		Expect(deploy.ApplyMutations(desired, opts, logger)).To(Succeed())
+
+		result := &deploy.CRDResult{}
+		Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhook), desired, opts, result, logger)).To(Succeed())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: live.GetName()}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"wg"}))
+		Expect(current.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal([]byte("injected")))
+		Expect(current.Annotations).To(HaveKeyWithValue(injectCAFromAnnotation, opts.InjectCAFrom))
+	})
+
+	It("a missing annotation causes an update even if the spec is unchanged", func() {
+		live := parse(crdWithWebhook)
+		c := newFakeClient(live)
+
+		desired := parse(crdWithWebhook)
+		Expect(deploy.ApplyMutations(desired, opts, logger)).To(Succeed())
+
+		result := &deploy.CRDResult{}
+		Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhook), desired, opts, result, logger)).To(Succeed())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: live.GetName()}, current)).To(Succeed())
+		Expect(current.Annotations).To(HaveKeyWithValue(injectCAFromAnnotation, opts.InjectCAFrom))
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultCascadeTimeout is how long, by default, Cascade waits for the
+	// instances of a CRD to be gone
+	DefaultCascadeTimeout = 5 * time.Minute
+
+	// cascadeDeleteQPS and cascadeDeleteBurst rate limit instance deletions
+	cascadeDeleteQPS   = 20
+	cascadeDeleteBurst = 50
+
+	// cascadePollInterval is the interval between two checks of the
+	// instances being deleted
+	cascadePollInterval = time.Second
+
+	// maxRemainingInstances bounds how many remaining instances are reported
+	maxRemainingInstances = 20
+)
+
+// CascadeError is returned when instances of a CRD are still present once
+// CascadeTimeout expires. The CRD is not deleted.
+type CascadeError struct {
+	// CRD is the CustomResourceDefinition name
+	CRD string
+
+	// Remaining is how many instances were still present
+	Remaining int
+}
+
+func (e *CascadeError) Error() string {
+	return fmt.Sprintf("%d instances of CRD %s still exist after the cascade timeout, not deleting it "+
+		"(check their finalizers)", e.Remaining, e.CRD)
+}
+
+// cascadeDeleteInstances deletes all the instances of crd, namespaced or
+// cluster-scoped, and waits, up to CascadeTimeout, for them to be gone, so
+// that finalizers run while the controllers owning them still can. Instances
+// still present are recorded in result.
+func cascadeDeleteInstances(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition,
+	opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	gvk, ok := instanceGroupVersionKind(crd)
+	if !ok {
+		// a CRD serving no version has no instance reachable through the API
+		return nil
+	}
+
+	limiter := flowcontrol.NewTokenBucketRateLimiter(cascadeDeleteQPS, cascadeDeleteBurst)
+	deleted := 0
+	err := forEachInstance(ctx, c, gvk, func(obj *unstructured.Unstructured) error {
+		if obj.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s %s: %w", gvk.Kind, objectName(obj), err)
+		}
+		deleted++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("cascade: deleted %d %s instances of CRD %s", deleted, gvk.Kind, crd.Name))
+
+	timeout := opts.CascadeTimeout
+	if timeout <= 0 {
+		timeout = DefaultCascadeTimeout
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("cascade: waiting up to %s for the %s instances of CRD %s to be gone",
+		timeout, gvk.Kind, crd.Name))
+	var remaining []string
+	total := 0
+	err = wait.PollUntilContextTimeout(ctx, cascadePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		remaining, total = nil, 0
+		listErr := forEachInstance(ctx, c, gvk, func(obj *unstructured.Unstructured) error {
+			if len(remaining) < maxRemainingInstances {
+				remaining = append(remaining, objectName(obj))
+			}
+			total++
+			return nil
+		})
+		if listErr != nil {
+			// Transient API errors are retried until the timeout
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("cascade: failed to list %s instances: %v", gvk.Kind, listErr))
+			return false, nil
+		}
+		if total > 0 {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("cascade: waiting for %d %s instances to be gone", total, gvk.Kind))
+		}
+		return total == 0, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if total == 0 {
+		return err
+	}
+	result.RemainingInstances = remaining
+	return &CascadeError{CRD: crd.Name, Remaining: total}
+}
+
+// forEachInstance calls fn with every instance of gvk, listed in pages
+func forEachInstance(ctx context.Context, c client.Client, gvk schema.GroupVersionKind,
+	fn func(*unstructured.Unstructured) error) error {
+
+	continueToken := ""
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.Limit(listPageSize), client.Continue(continueToken)); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			if err := fn(&list.Items[i]); err != nil {
+				return err
+			}
+		}
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+
+// instanceGroupVersionKind returns the GroupVersionKind instances of crd are
+// reached through: its storage version if served, its first served one otherwise
+func instanceGroupVersionKind(crd *apiextensionsv1.CustomResourceDefinition) (schema.GroupVersionKind, bool) {
+	version := ""
+	for i := range crd.Spec.Versions {
+		v := &crd.Spec.Versions[i]
+		if v.Served && (version == "" || v.Storage) {
+			version = v.Name
+		}
+	}
+	if version == "" {
+		return schema.GroupVersionKind{}, false
+	}
+	return schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.Kind}, true
+}
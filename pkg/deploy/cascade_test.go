@@ -0,0 +1,127 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// newDeleteRecordingClient returns a fake client recording, in order, the
+// kind and name of every object deleted
+func newDeleteRecordingClient(deleted *[]string, initObjects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+				kind = "CustomResourceDefinition"
+			}
+			*deleted = append(*deleted, kind+"/"+obj.GetName())
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+// obsoleteInstances returns count instances of obsoleteCRD
+func obsoleteInstances(count int) []client.Object {
+	result := make([]client.Object, count)
+	for i := range result {
+		u := obsoleteInstance()
+		u.SetName(fmt.Sprintf("leftover-%d", i))
+		result[i] = u
+	}
+	return result
+}
+
+func expectCRDGone(c client.Client, name string) {
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name}, &apiextensionsv1.CustomResourceDefinition{})
+	Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
+
+var _ = Describe("Cascade", func() {
+	It("deletes the instances of an obsolete CRD before the CRD", func() {
+		var deleted []string
+		c := newDeleteRecordingClient(&deleted, append(obsoleteInstances(3), obsoleteCRD())...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{RemoveObsolete: true, Cascade: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionRemovedObsolete)))
+
+		Expect(deleted).To(Equal([]string{
+			"AddonCompliance/leftover-0", "AddonCompliance/leftover-1", "AddonCompliance/leftover-2",
+			"CustomResourceDefinition/" + obsoleteCRD().Name,
+		}))
+		expectCRDGone(c, obsoleteCRD().Name)
+	})
+
+	It("keeps the CRD when instances remain once the timeout expires", func() {
+		instance := obsoleteInstance()
+		instance.SetFinalizers([]string{"projectsveltos.io/cleanup"})
+		c := newFakeClient(obsoleteCRD(), instance)
+
+		opts := &deploy.Options{RemoveObsolete: true, Cascade: true, CascadeTimeout: 100 * time.Millisecond}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		var cascadeErr *deploy.CascadeError
+		Expect(errors.As(err, &cascadeErr)).To(BeTrue())
+		Expect(cascadeErr.Remaining).To(Equal(1))
+
+		Expect(report.Removed).To(HaveLen(1))
+		Expect(report.Removed[0].Action).To(Equal(deploy.ActionFailed))
+		Expect(report.Removed[0].RemainingInstances).To(Equal([]string{"leftover"}))
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: obsoleteCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(instance.GroupVersionKind())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: "leftover"}, current)).To(Succeed())
+		Expect(current.GetDeletionTimestamp()).ToNot(BeNil())
+	})
+
+	It("deletes the instances of pruned ApplySet members before them", func() {
+		applySet, err := deploy.ParseApplySet("secret/crd-manager", "projectsveltos")
+		Expect(err).To(BeNil())
+		member := obsoleteCRD()
+		member.Labels = map[string]string{deploy.ApplySetPartOfLabel: applySet.ID()}
+
+		var deleted []string
+		c := newDeleteRecordingClient(&deleted, append(obsoleteInstances(2), member)...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ApplySet: applySet, Cascade: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionPruned)))
+		Expect(deleted).To(Equal([]string{
+			"AddonCompliance/leftover-0", "AddonCompliance/leftover-1", "CustomResourceDefinition/" + member.Name,
+		}))
+		expectCRDGone(c, member.Name)
+	})
+})
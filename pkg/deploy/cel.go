@@ -0,0 +1,143 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	celValidationsKey = "x-kubernetes-validations"
+)
+
+var (
+	// celMinVersion is the first Kubernetes version accepting CEL validation rules
+	// (x-kubernetes-validations) without enabling any feature gate
+	celMinVersion = utilversion.MustParseGeneric("v1.25.0")
+)
+
+// isServerVersionBelow returns true if serverVersion is known and lower than minVersion
+func isServerVersionBelow(serverVersion string, minVersion *utilversion.Version) (bool, error) {
+	if serverVersion == "" {
+		return false, nil
+	}
+
+	v, err := utilversion.ParseGeneric(serverVersion)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse server version %q: %w", serverVersion, err)
+	}
+	return v.LessThan(minVersion), nil
+}
+
+// shouldStripCEL returns true if CEL validation rules must be removed from the CRDs
+func shouldStripCEL(opts *Options) (bool, error) {
+	if opts.StripCEL {
+		return true, nil
+	}
+	return isServerVersionBelow(opts.ServerVersion, celMinVersion)
+}
+
+// stripCEL removes all CEL validation rules from all versions of a CRD
+func stripCEL(u *unstructured.Unstructured, a *audit, logger logr.Logger) error {
+	versions, found, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("failed to parse versions: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	removed := 0
+	for i := range versions {
+		version, ok := versions[i].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("failed to parse version %d", i)
+		}
+		schema, found, err := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if err != nil {
+			return fmt.Errorf("failed to parse version %d schema: %w", i, err)
+		}
+		if !found {
+			continue
+		}
+		removed += stripSchemaCEL(schema)
+		if err := unstructured.SetNestedMap(version, schema, "schema", "openAPIV3Schema"); err != nil {
+			return fmt.Errorf("failed to set version %d schema: %w", i, err)
+		}
+	}
+
+	if removed == 0 {
+		return nil
+	}
+
+	if err := unstructured.SetNestedSlice(u.Object, versions, "spec", "versions"); err != nil {
+		return fmt.Errorf("failed to set versions: %w", err)
+	}
+	a.addModification("%d CEL validation rule sets removed", removed)
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s degraded: %d CEL validation rule sets removed",
+		u.GetName(), removed))
+	return nil
+}
+
+// stripSchemaCEL walks a JSONSchemaProps, following its structure, and removes
+// x-kubernetes-validations from it and from every nested schema. Only schema
+// keywords are considered, so a property named x-kubernetes-validations is kept.
+// It returns the number of x-kubernetes-validations removed.
+func stripSchemaCEL(schema map[string]interface{}) int {
+	removed := 0
+	if _, ok := schema[celValidationsKey]; ok {
+		delete(schema, celValidationsKey)
+		removed++
+	}
+
+	// keywords whose value is a map of schemas
+	for _, key := range []string{"properties", "patternProperties", "definitions", "dependencies"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			for name := range nested {
+				if s, ok := nested[name].(map[string]interface{}); ok {
+					removed += stripSchemaCEL(s)
+				}
+			}
+		}
+	}
+
+	// keywords whose value is a schema (or a boolean)
+	for _, key := range []string{"items", "additionalProperties", "additionalItems", "not"} {
+		if s, ok := schema[key].(map[string]interface{}); ok {
+			removed += stripSchemaCEL(s)
+		}
+	}
+
+	// keywords whose value is a list of schemas. items can also be a list.
+	for _, key := range []string{"allOf", "anyOf", "oneOf", "items"} {
+		if list, ok := schema[key].([]interface{}); ok {
+			for i := range list {
+				if s, ok := list[i].(map[string]interface{}); ok {
+					removed += stripSchemaCEL(s)
+				}
+			}
+		}
+	}
+
+	return removed
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	crdWithCEL = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-validations:
+        - rule: self.metadata.name.size() < 64
+        properties:
+          spec:
+            type: object
+            x-kubernetes-validations:
+            - rule: has(self.mode)
+            properties:
+              mode:
+                type: string
+                enum:
+                - OneTime
+                - Continuous
+                x-kubernetes-validations:
+                - rule: self == oldSelf
+                  message: mode is immutable
+              x-kubernetes-validations:
+                type: string
+                description: a field which happens to be named as the CEL keyword
+              refs:
+                type: array
+                items:
+                  type: object
+                  required:
+                  - name
+                  x-kubernetes-validations:
+                  - rule: self.name != ''
+                  properties:
+                    name:
+                      type: string
+              labels:
+                type: object
+                additionalProperties:
+                  type: string
+                  x-kubernetes-validations:
+                  - rule: self.size() < 63
+`
+
+	// expectedWithoutCEL is crdWithCEL once all CEL rules are removed
+	expectedWithoutCEL = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              mode:
+                type: string
+                enum:
+                - OneTime
+                - Continuous
+              x-kubernetes-validations:
+                type: string
+                description: a field which happens to be named as the CEL keyword
+              refs:
+                type: array
+                items:
+                  type: object
+                  required:
+                  - name
+                  properties:
+                    name:
+                      type: string
+              labels:
+                type: object
+                additionalProperties:
+                  type: string
+`
+)
+
+var _ = Describe("CEL", func() {
+	It("stripCEL removes nested rules leaving everything else untouched", func() {
+		u := parse(crdWithCEL)
+		Expect(deploy.ApplyMutations(u, &deploy.Options{StripCEL: true}, logger)).To(Succeed())
+		Expect(u.Object["spec"]).To(Equal(parse(expectedWithoutCEL).Object["spec"]))
+		Expect(getAudit(u)).To(Equal([]string{"5 CEL validation rule sets removed"}))
+	})
+
+	It("CEL rules are removed when server version does not support them", func() {
+		u := parse(crdWithCEL)
+		Expect(deploy.ApplyMutations(u, &deploy.Options{ServerVersion: "v1.24.17"}, logger)).To(Succeed())
+		Expect(u.Object["spec"]).To(Equal(parse(expectedWithoutCEL).Object["spec"]))
+	})
+
+	It("CEL rules are kept when server version supports them", func() {
+		for _, version := range []string{"v1.25.0", "v1.35.0+k3s1", ""} {
+			u := parse(crdWithCEL)
+			Expect(deploy.ApplyMutations(u, &deploy.Options{ServerVersion: version}, logger)).To(Succeed())
+			Expect(u).To(Equal(parse(crdWithCEL)))
+		}
+	})
+
+	It("stripCEL removes all rules from bundle CRDs", func() {
+		for _, u := range getBundleCRDs() {
+			Expect(deploy.ApplyMutations(u, &deploy.Options{StripCEL: true}, logger)).To(Succeed())
+			versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+			Expect(err).To(BeNil())
+			data, err := json.Marshal(versions)
+			Expect(err).To(BeNil())
+			Expect(string(data)).ToNot(ContainSubstring(`"x-kubernetes-validations":[`))
+		}
+	})
+})
@@ -0,0 +1,272 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// bundleRecordKey is the history ConfigMap key holding the record of the
+// bundle the last successful run applied
+const bundleRecordKey = "bundle.json"
+
+// BundleRecord describes the bundle CRDs a run applied
+type BundleRecord struct {
+	// BundleVersion is the Sveltos version the bundle CRDs come from, when known
+	BundleVersion string `json:"bundleVersion"`
+
+	// BundleDigest is the sha256 digest of the CRD bundle
+	BundleDigest string `json:"bundleDigest"`
+
+	// CRDs are, per name, the CRDs applied
+	CRDs map[string]CRDRecord `json:"crds"`
+}
+
+// CRDRecord identifies the definition of a bundle CRD, as found in the bundle
+type CRDRecord struct {
+	// SpecHash is the hash of the whole CRD spec
+	SpecHash string `json:"specHash"`
+
+	// Versions are, per version name, the hash of the version definition
+	Versions map[string]string `json:"versions"`
+}
+
+// Changelog lists the differences between the bundle the last successful run
+// applied and the current one
+type Changelog struct {
+	// FirstRun is true when no previous run was recorded: every CRD is then
+	// listed as added
+	FirstRun bool `json:"firstRun"`
+
+	// PreviousBundleVersion and PreviousBundleDigest identify the bundle the
+	// last successful run applied
+	PreviousBundleVersion string `json:"previousBundleVersion,omitempty"`
+	PreviousBundleDigest  string `json:"previousBundleDigest,omitempty"`
+
+	// BundleVersion and BundleDigest identify the current bundle
+	BundleVersion string `json:"bundleVersion"`
+	BundleDigest  string `json:"bundleDigest"`
+
+	// Added lists the CRDs not applied by the last successful run
+	Added []string `json:"added,omitempty"`
+
+	// Removed lists the CRDs applied by the last successful run and no
+	// longer part of the current bundle, or of its selected components
+	Removed []string `json:"removed,omitempty"`
+
+	// Modified lists the CRDs whose definition changed
+	Modified []CRDChange `json:"modified,omitempty"`
+}
+
+// CRDChange describes how the definition of a CRD changed
+type CRDChange struct {
+	// Name of the CRD
+	Name string `json:"name"`
+
+	// AddedVersions, RemovedVersions and ChangedVersions list the versions
+	// new in the current bundle, dropped from it and whose definition
+	// (schema, served or storage flags...) changed. All are empty when only
+	// other spec fields, such as names, changed.
+	AddedVersions   []string `json:"addedVersions,omitempty"`
+	RemovedVersions []string `json:"removedVersions,omitempty"`
+	ChangedVersions []string `json:"changedVersions,omitempty"`
+}
+
+// HasChanges returns true if any CRD was added, removed or modified
+func (c *Changelog) HasChanges() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || len(c.Modified) > 0
+}
+
+// BundleChangelog compares the bundle the last successful run recorded in
+// the history applied with the one opts deploy. Nothing is written.
+func BundleChangelog(ctx context.Context, c client.Client, opts *Options) (*Changelog, error) {
+	if opts == nil || opts.History == nil {
+		return nil, errors.New("the changelog requires the run history")
+	}
+
+	current, err := newBundleRecord(opts.getBundle(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = c.Get(ctx, opts.History.key(), configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	previous, err := parseBundleRecord(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return diffBundleRecords(previous, current), nil
+}
+
+// newBundleRecord returns the record of the CRDs of b opts select
+func newBundleRecord(b *bundle.Bundle, opts *Options) (*BundleRecord, error) {
+	objs, err := deployer.CustomSplit(string(b.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &BundleRecord{BundleVersion: b.Version(), BundleDigest: b.Digest(), CRDs: map[string]CRDRecord{}}
+	for _, obj := range objs {
+		u, err := k8s_utils.GetUnstructured([]byte(obj))
+		if err != nil {
+			return nil, err
+		}
+		if !opts.selectsCRD(u.GetName()) {
+			continue
+		}
+		crd, err := toCustomResourceDefinition(u)
+		if err != nil {
+			return nil, err
+		}
+
+		crdRecord := CRDRecord{Versions: map[string]string{}}
+		if crdRecord.SpecHash, err = specHash(crd); err != nil {
+			return nil, err
+		}
+		for i := range crd.Spec.Versions {
+			data, err := json.Marshal(crd.Spec.Versions[i])
+			if err != nil {
+				return nil, err
+			}
+			crdRecord.Versions[crd.Spec.Versions[i].Name] = bundle.Digest(data)
+		}
+		record.CRDs[crd.Name] = crdRecord
+	}
+	return record, nil
+}
+
+// parseBundleRecord returns the bundle record kept in the history ConfigMap,
+// nil if there is none
+func parseBundleRecord(configMap *corev1.ConfigMap) (*BundleRecord, error) {
+	data, ok := configMap.Data[bundleRecordKey]
+	if !ok {
+		return nil, nil
+	}
+	record := &BundleRecord{}
+	if err := json.Unmarshal([]byte(data), record); err != nil {
+		return nil, fmt.Errorf("invalid bundle record in ConfigMap %s/%s: %w", configMap.Namespace,
+			configMap.Name, err)
+	}
+	return record, nil
+}
+
+// diffBundleRecords returns the changelog from previous, nil on first runs, to current
+func diffBundleRecords(previous, current *BundleRecord) *Changelog {
+	changelog := &Changelog{BundleVersion: current.BundleVersion, BundleDigest: current.BundleDigest}
+	if previous == nil {
+		changelog.FirstRun = true
+		previous = &BundleRecord{}
+	}
+	changelog.PreviousBundleVersion = previous.BundleVersion
+	changelog.PreviousBundleDigest = previous.BundleDigest
+
+	for _, name := range slices.Sorted(maps.Keys(current.CRDs)) {
+		before, ok := previous.CRDs[name]
+		switch {
+		case !ok:
+			changelog.Added = append(changelog.Added, name)
+		case before.SpecHash != current.CRDs[name].SpecHash:
+			changelog.Modified = append(changelog.Modified, diffCRDRecords(name, &before, current.CRDs[name]))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(previous.CRDs)) {
+		if _, ok := current.CRDs[name]; !ok {
+			changelog.Removed = append(changelog.Removed, name)
+		}
+	}
+	return changelog
+}
+
+func diffCRDRecords(name string, before *CRDRecord, after CRDRecord) CRDChange {
+	change := CRDChange{Name: name}
+	for _, version := range slices.Sorted(maps.Keys(after.Versions)) {
+		hash, ok := before.Versions[version]
+		switch {
+		case !ok:
+			change.AddedVersions = append(change.AddedVersions, version)
+		case hash != after.Versions[version]:
+			change.ChangedVersions = append(change.ChangedVersions, version)
+		}
+	}
+	for _, version := range slices.Sorted(maps.Keys(before.Versions)) {
+		if _, ok := after.Versions[version]; !ok {
+			change.RemovedVersions = append(change.RemovedVersions, version)
+		}
+	}
+	return change
+}
+
+// appliedBundleRecord returns the record of the bundle CRDs the run report
+// describes applied, nil if the run did not succeed
+func appliedBundleRecord(b *bundle.Bundle, opts *Options, report *Report, logger logr.Logger) *BundleRecord {
+	if report.Status != RunStatusSuccess {
+		return nil
+	}
+	record, err := newBundleRecord(b, opts)
+	if err != nil {
+		logWarning(logger, "failed to record the applied bundle: %v", err)
+		return nil
+	}
+	return record
+}
+
+// replaceBundleRecord stores record in the history configMap and returns the
+// changelog from the record it replaces
+func replaceBundleRecord(configMap *corev1.ConfigMap, record *BundleRecord) (*Changelog, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	// A corrupted record is reported, by the changelog, as a first run
+	previous, _ := parseBundleRecord(configMap)
+	configMap.Data[bundleRecordKey] = string(data)
+	return diffBundleRecords(previous, record), nil
+}
+
+// logChangelog logs a summary of changelog
+func logChangelog(changelog *Changelog, logger logr.Logger) {
+	switch {
+	case changelog.FirstRun:
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("bundle changelog: no previous run recorded, %d CRDs applied for the first time",
+			len(changelog.Added)))
+	case !changelog.HasChanges():
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("bundle changelog: no CRD changed since bundle %s",
+			changelog.PreviousBundleDigest))
+	default:
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("bundle changelog since bundle %s (version %q): "+
+			"%d CRDs added, %d removed, %d modified", changelog.PreviousBundleDigest,
+			changelog.PreviousBundleVersion, len(changelog.Added), len(changelog.Removed), len(changelog.Modified)))
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Changelog", func() {
+	const (
+		widgets = "widgets.lib.projectsveltos.io"
+		gadgets = "gadgets.lib.projectsveltos.io"
+	)
+
+	history := &deploy.History{Namespace: historyNamespace}
+
+	// upgradedGadgets is crdWithoutWebhook with a changed v1beta1 schema and a new v1 version
+	upgradedGadgets := strings.Replace(crdWithoutWebhook, "        type: object\n",
+		"        type: object\n        x-kubernetes-preserve-unknown-fields: true\n"+
+			"  - name: v1\n    served: true\n    storage: false\n    schema:\n      openAPIV3Schema:\n        type: object\n", 1)
+
+	changelog := func(c client.Client, b *bundle.Bundle) *deploy.Changelog {
+		changelog, err := deploy.BundleChangelog(context.TODO(), c, &deploy.Options{Bundle: b, History: history})
+		Expect(err).To(BeNil())
+		return changelog
+	}
+
+	It("states that no previous run was recorded", func() {
+		c := newFakeClient()
+		result := changelog(c, testBundle())
+		Expect(result.FirstRun).To(BeTrue())
+		Expect(result.Added).To(ConsistOf(widgets, gadgets))
+		Expect(result.PreviousBundleDigest).To(BeEmpty())
+	})
+
+	It("lists the CRDs added, removed and modified since the last successful run", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: testBundle(), History: history}, logger)
+		Expect(err).To(BeNil())
+
+		result := changelog(c, testBundle())
+		Expect(result.FirstRun).To(BeFalse())
+		Expect(result.HasChanges()).To(BeFalse())
+		Expect(result.PreviousBundleDigest).To(Equal(testBundle().Digest()))
+
+		upgraded := &bundle.Bundle{Content: []byte(upgradedGadgets)}
+		result = changelog(c, upgraded)
+		Expect(result.Added).To(BeEmpty())
+		Expect(result.Removed).To(ConsistOf(widgets))
+		Expect(result.Modified).To(ConsistOf(deploy.CRDChange{
+			Name: gadgets, AddedVersions: []string{"v1"}, ChangedVersions: []string{"v1beta1"},
+		}))
+		Expect(result.BundleDigest).To(Equal(upgraded.Digest()))
+	})
+
+	It("keeps the previous record when a run fails", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: testBundle(), History: history}, logger)
+		Expect(err).To(BeNil())
+
+		failing := fake.NewClientBuilder().WithScheme(scheme).WithObjects(getHistoryConfigMap(c)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+					return errors.New("create failed")
+				},
+			}).Build()
+		upgraded := &bundle.Bundle{Content: []byte(upgradedGadgets + "---\n" + crdWithoutWebhook)}
+		_, err = deploy.Deploy(context.TODO(), failing, &deploy.Options{Bundle: upgraded, History: history}, logger)
+		Expect(err).ToNot(BeNil())
+
+		Expect(changelog(failing, testBundle()).HasChanges()).To(BeFalse())
+	})
+
+	It("requires the run history", func() {
+		_, err := deploy.BundleChangelog(context.TODO(), newFakeClient(), &deploy.Options{})
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+func getHistoryConfigMap(c client.Client) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{}
+	Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: historyNamespace, Name: deploy.HistoryConfigMapName},
+		configMap)).To(Succeed())
+	configMap.ResourceVersion = ""
+	return configMap
+}
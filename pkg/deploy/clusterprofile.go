@@ -0,0 +1,252 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// BundleDigestAnnotation carries, on the generated ClusterProfile and
+	// ConfigMaps, the digest of the bundle they were rendered from
+	BundleDigestAnnotation = "projectsveltos.io/bundle-digest"
+
+	// DefaultClusterProfileName is the default name of the generated ClusterProfile
+	DefaultClusterProfileName = "crd-manager"
+
+	// ClusterProfileLabel is set, to the ClusterProfile name, on the
+	// ConfigMaps it references
+	ClusterProfileLabel = "projectsveltos.io/crd-manager-clusterprofile"
+)
+
+// ClusterProfileGVK is the GroupVersionKind of the generated ClusterProfile
+var ClusterProfileGVK = schema.GroupVersionKind{Group: "config.projectsveltos.io", Version: "v1beta1",
+	Kind: "ClusterProfile"}
+
+// ClusterProfileOptions configures the ClusterProfile handing the bundle CRDs
+// over to Sveltos, which deploys them to the matching managed clusters
+type ClusterProfileOptions struct {
+	// Name of the ClusterProfile. Defaults to DefaultClusterProfileName.
+	Name string
+
+	// ClusterSelector is the label selector of the managed clusters
+	ClusterSelector string
+
+	// Namespace of the ConfigMaps, one per CRD, the ClusterProfile references.
+	// Defaults to ConfigMapNamespace.
+	Namespace string
+}
+
+// Validate returns an error if o is not consistent
+func (o *ClusterProfileOptions) Validate() error {
+	if o.ClusterSelector == "" {
+		return errors.New("a cluster selector is required")
+	}
+	if _, err := metav1.ParseToLabelSelector(o.ClusterSelector); err != nil {
+		return fmt.Errorf("invalid cluster selector: %w", err)
+	}
+	return nil
+}
+
+func (o *ClusterProfileOptions) name() string {
+	if o.Name == "" {
+		return DefaultClusterProfileName
+	}
+	return o.Name
+}
+
+func (o *ClusterProfileOptions) namespace() string {
+	if o.Namespace == "" {
+		return ConfigMapNamespace
+	}
+	return o.Namespace
+}
+
+// RenderClusterProfile returns the ConfigMaps, one per bundle CRD rendered as
+// Template does, and, last, the ClusterProfile referencing them in bundle
+// order. The objects only depend on the bundle and options: rendering again
+// returns the same objects, with the same names.
+func RenderClusterProfile(opts *Options, profile *ClusterProfileOptions, logger logr.Logger) (
+	[]*unstructured.Unstructured, error) {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	selector, err := metav1.ParseToLabelSelector(profile.ClusterSelector)
+	if err != nil {
+		return nil, err
+	}
+	clusterSelector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	crds, err := renderCRDs(opts, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := opts.getBundle().Digest()
+	result := make([]*unstructured.Unstructured, 0, len(crds)+1)
+	policyRefs := make([]any, 0, len(crds))
+	for _, crd := range crds {
+		configMap, err := clusterProfileConfigMap(crd, profile, digest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, configMap)
+		policyRefs = append(policyRefs, map[string]any{
+			"kind":      "ConfigMap",
+			"namespace": configMap.GetNamespace(),
+			"name":      configMap.GetName(),
+		})
+	}
+
+	clusterProfile := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"clusterSelector": clusterSelector,
+			"syncMode":        "Continuous",
+			"policyRefs":      policyRefs,
+		},
+	}}
+	clusterProfile.SetGroupVersionKind(ClusterProfileGVK)
+	clusterProfile.SetName(profile.name())
+	clusterProfile.SetLabels(map[string]string{ManagedByLabel: ManagedByValue})
+	clusterProfile.SetAnnotations(map[string]string{BundleDigestAnnotation: digest})
+	return append(result, clusterProfile), nil
+}
+
+// clusterProfileConfigMap returns the ConfigMap containing crd
+func clusterProfileConfigMap(crd *unstructured.Unstructured, profile *ClusterProfileOptions, digest string) (
+	*unstructured.Unstructured, error) {
+
+	data, err := yaml.Marshal(crd.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CRD %s: %w", crd.GetName(), err)
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   profile.namespace(),
+			Name:        profile.name() + "-" + crd.GetName(),
+			Labels:      map[string]string{ManagedByLabel: ManagedByValue, ClusterProfileLabel: profile.name()},
+			Annotations: map[string]string{BundleDigestAnnotation: digest},
+		},
+		Data: map[string]string{crd.GetName() + ".yaml": string(data)},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// WriteClusterProfile writes to w, as a multi-document YAML, the objects
+// RenderClusterProfile returns
+func WriteClusterProfile(w io.Writer, opts *Options, profile *ClusterProfileOptions, logger logr.Logger) error {
+	objects, err := RenderClusterProfile(opts, profile, logger)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := writeDocument(w, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyClusterProfile creates, or updates in place, in the management cluster
+// c points to, the objects RenderClusterProfile returns. The ConfigMaps of
+// CRDs no longer part of the bundle are then deleted.
+func ApplyClusterProfile(ctx context.Context, c client.Client, opts *Options, profile *ClusterProfileOptions,
+	logger logr.Logger) error {
+
+	objects, err := RenderClusterProfile(opts, profile, logger)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		if err := applyObject(ctx, c, obj, logger); err != nil {
+			return err
+		}
+		keep[obj.GetName()] = true
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMaps, client.InNamespace(profile.namespace()),
+		client.MatchingLabels{ClusterProfileLabel: profile.name()}); err != nil {
+		return fmt.Errorf("failed to list the ConfigMaps of ClusterProfile %s: %w", profile.name(), err)
+	}
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if keep[configMap.Name] {
+			continue
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("deleting ConfigMap %s/%s, its CRD is no longer part of the bundle",
+			configMap.Namespace, configMap.Name))
+		if err := c.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+	}
+	return nil
+}
+
+// applyObject creates desired or replaces the existing object with it
+func applyObject(ctx context.Context, c client.Client, desired *unstructured.Unstructured, logger logr.Logger) error {
+	kind := desired.GetKind()
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desired.GroupVersionKind())
+	err := c.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if apierrors.IsNotFound(err) {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("creating %s %s", kind, desired.GetName()))
+		if err := c.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create %s %s: %w", kind, desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", kind, desired.GetName(), err)
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("updating %s %s", kind, desired.GetName()))
+	desired.SetResourceVersion(existing.GetResourceVersion())
+	if err := c.Update(ctx, desired); err != nil {
+		return fmt.Errorf("failed to update %s %s: %w", kind, desired.GetName(), err)
+	}
+	return nil
+}
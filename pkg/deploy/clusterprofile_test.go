@@ -0,0 +1,142 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("ClusterProfile", func() {
+	profile := &deploy.ClusterProfileOptions{ClusterSelector: "env=prod"}
+
+	getClusterProfile := func(c client.Client) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(deploy.ClusterProfileGVK)
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: deploy.DefaultClusterProfileName}, u)).To(Succeed())
+		return u
+	}
+
+	listConfigMaps := func(c client.Client) []corev1.ConfigMap {
+		configMaps := &corev1.ConfigMapList{}
+		Expect(c.List(context.TODO(), configMaps, client.InNamespace(deploy.ConfigMapNamespace))).To(Succeed())
+		return configMaps.Items
+	}
+
+	It("references one ConfigMap per CRD in bundle order", func() {
+		opts := &deploy.Options{Bundle: testBundle()}
+		objects, err := deploy.RenderClusterProfile(opts, profile, logger)
+		Expect(err).To(BeNil())
+		Expect(objects).To(HaveLen(3))
+
+		digest := testBundle().Digest()
+		clusterProfile := objects[2]
+		Expect(clusterProfile.GroupVersionKind()).To(Equal(deploy.ClusterProfileGVK))
+		Expect(clusterProfile.GetAnnotations()).To(HaveKeyWithValue(deploy.BundleDigestAnnotation, digest))
+
+		labels, _, err := unstructured.NestedStringMap(clusterProfile.Object, "spec", "clusterSelector", "matchLabels")
+		Expect(err).To(BeNil())
+		Expect(labels).To(Equal(map[string]string{"env": "prod"}))
+
+		policyRefs, _, err := unstructured.NestedSlice(clusterProfile.Object, "spec", "policyRefs")
+		Expect(err).To(BeNil())
+		Expect(policyRefs).To(HaveLen(2))
+		for i, name := range []string{"widgets.lib.projectsveltos.io", "gadgets.lib.projectsveltos.io"} {
+			configMap := objects[i]
+			Expect(configMap.GetName()).To(Equal(deploy.DefaultClusterProfileName + "-" + name))
+			Expect(configMap.GetAnnotations()).To(HaveKeyWithValue(deploy.BundleDigestAnnotation, digest))
+			Expect(policyRefs[i]).To(Equal(map[string]any{
+				"kind": "ConfigMap", "namespace": deploy.ConfigMapNamespace, "name": configMap.GetName(),
+			}))
+
+			data, _, err := unstructured.NestedString(configMap.Object, "data", name+".yaml")
+			Expect(err).To(BeNil())
+			crd, err := k8s_utils.GetUnstructured([]byte(data))
+			Expect(err).To(BeNil())
+			Expect(crd.GetName()).To(Equal(name))
+		}
+	})
+
+	It("is deterministic", func() {
+		opts := &deploy.Options{Bundle: testBundle(), Category: "sveltos"}
+
+		var first, second bytes.Buffer
+		Expect(deploy.WriteClusterProfile(&first, opts, profile, logger)).To(Succeed())
+		Expect(deploy.WriteClusterProfile(&second, opts, profile, logger)).To(Succeed())
+		Expect(first.String()).To(Equal(second.String()))
+
+		docs, err := deployer.CustomSplit(first.String())
+		Expect(err).To(BeNil())
+		Expect(docs).To(HaveLen(3))
+	})
+
+	It("requires a valid cluster selector", func() {
+		for _, selector := range []string{"", "env in prod"} {
+			_, err := deploy.RenderClusterProfile(&deploy.Options{Bundle: testBundle()},
+				&deploy.ClusterProfileOptions{ClusterSelector: selector}, logger)
+			Expect(err).ToNot(BeNil())
+		}
+	})
+
+	It("updates the ClusterProfile in place and deletes the ConfigMaps of removed CRDs", func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(crds.AddUnstructuredToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).Build()
+
+		Expect(deploy.ApplyClusterProfile(context.TODO(), c, &deploy.Options{Bundle: testBundle()}, profile,
+			logger)).To(Succeed())
+		Expect(listConfigMaps(c)).To(HaveLen(2))
+
+		single := &bundle.Bundle{Content: []byte(crdWithoutWebhook)}
+		Expect(deploy.ApplyClusterProfile(context.TODO(), c, &deploy.Options{Bundle: single}, profile,
+			logger)).To(Succeed())
+
+		configMaps := listConfigMaps(c)
+		Expect(configMaps).To(HaveLen(1))
+		Expect(configMaps[0].Annotations).To(HaveKeyWithValue(deploy.BundleDigestAnnotation, single.Digest()))
+
+		clusterProfile := getClusterProfile(c)
+		Expect(clusterProfile.GetAnnotations()).To(HaveKeyWithValue(deploy.BundleDigestAnnotation, single.Digest()))
+		policyRefs, _, err := unstructured.NestedSlice(clusterProfile.Object, "spec", "policyRefs")
+		Expect(err).To(BeNil())
+		Expect(policyRefs).To(HaveLen(1))
+		Expect(policyRefs[0]).To(HaveKeyWithValue("name", configMaps[0].Name))
+
+		clusterProfiles := &unstructured.UnstructuredList{}
+		clusterProfiles.SetGroupVersionKind(deploy.ClusterProfileGVK.GroupVersion().WithKind("ClusterProfileList"))
+		Expect(c.List(context.TODO(), clusterProfiles)).To(Succeed())
+		Expect(clusterProfiles.Items).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// selectsCRD returns true unless the CRD named name matches one of the
+// ExcludeCRDs patterns, matches none of the IncludeCRDs ones or, with
+// Components set, belongs to none of them
+func (o *Options) selectsCRD(name string) bool {
+	if matchesCRD(name, o.ExcludeCRDs) {
+		return false
+	}
+	if len(o.IncludeCRDs) > 0 && !matchesCRD(name, o.IncludeCRDs) {
+		return false
+	}
+	if len(o.Components) == 0 {
+		return true
+	}
+	component := crds.ComponentOf(name)
+	return component != "" && slices.Contains(o.Components, component)
+}
+
+// selectsAll returns true if no option restricts the CRDs deployed
+func (o *Options) selectsAll() bool {
+	return len(o.Components) == 0 && len(o.IncludeCRDs) == 0 && len(o.ExcludeCRDs) == 0
+}
+
+// describeSelection describes the options restricting the CRDs deployed
+func (o *Options) describeSelection() string {
+	var parts []string
+	if len(o.Components) > 0 {
+		parts = append(parts, "components "+strings.Join(o.Components, ", "))
+	}
+	if len(o.IncludeCRDs) > 0 {
+		parts = append(parts, "including "+strings.Join(o.IncludeCRDs, ", "))
+	}
+	if len(o.ExcludeCRDs) > 0 {
+		parts = append(parts, "excluding "+strings.Join(o.ExcludeCRDs, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// matchesCRD returns true if name matches one of patterns
+func matchesCRD(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatchedPatterns returns the IncludeCRDs and ExcludeCRDs patterns matching
+// none of bundleCRDs, most likely mistyped
+func unmatchedPatterns(bundleCRDs []*bundleCRD, opts *Options) []string {
+	var unmatched []string
+	for _, patterns := range [][]string{opts.IncludeCRDs, opts.ExcludeCRDs} {
+		for _, pattern := range patterns {
+			matches := func(crd *bundleCRD) bool { return matchesCRD(crd.desired.GetName(), []string{pattern}) }
+			if !slices.ContainsFunc(bundleCRDs, matches) {
+				unmatched = append(unmatched, pattern)
+			}
+		}
+	}
+	return unmatched
+}
+
+// validateCRDPatterns returns an error if a pattern is not a valid
+// path.Match pattern, such as "*.lib.projectsveltos.io"
+func validateCRDPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid CRD name pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// selectComponents returns the CRDs, among bundleCRDs, selected by the
+// Components, IncludeCRDs and ExcludeCRDs options
+func selectComponents(bundleCRDs []*bundleCRD, opts *Options) []*bundleCRD {
+	if opts.selectsAll() {
+		return bundleCRDs
+	}
+	selected := make([]*bundleCRD, 0, len(bundleCRDs))
+	for _, crd := range bundleCRDs {
+		if opts.selectsCRD(crd.desired.GetName()) {
+			selected = append(selected, crd)
+		}
+	}
+	return selected
+}
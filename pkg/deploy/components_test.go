@@ -0,0 +1,179 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+// componentCRDs returns the names of the bundle CRDs of components
+func componentCRDs(components ...string) []string {
+	var names []string
+	for _, crd := range getBundleCRDs() {
+		for _, component := range components {
+			if crds.ComponentOf(crd.GetName()) == component {
+				names = append(names, crd.GetName())
+			}
+		}
+	}
+	return names
+}
+
+var _ = Describe("Components", func() {
+	It("deploys only the CRDs of the selected components", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{Components: []string{crds.ComponentEvents, crds.ComponentClassification}}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		expected := componentCRDs(crds.ComponentEvents, crds.ComponentClassification)
+		Expect(report.CRDs).To(HaveLen(len(expected)))
+		for i := range report.CRDs {
+			Expect(report.CRDs[i].Name).To(Equal(expected[i]))
+			Expect(report.CRDs[i].Action).To(Equal(deploy.ActionCreated))
+			Expect(report.CRDs[i].Component).To(BeElementOf(crds.ComponentEvents, crds.ComponentClassification))
+		}
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(len(expected)))
+	})
+
+	It("reports the component of every CRD", func() {
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), nil, logger)
+		Expect(err).To(BeNil())
+		for i := range report.CRDs {
+			Expect(report.CRDs[i].Component).To(Equal(crds.ComponentOf(report.CRDs[i].Name)))
+			Expect(report.CRDs[i].Component).ToNot(BeEmpty())
+		}
+	})
+
+	It("does not prune the ApplySet members of other components", func() {
+		c := newFakeClient()
+		applySet, err := deploy.ParseApplySet("secret/crd-manager", "projectsveltos")
+		Expect(err).To(BeNil())
+
+		_, err = deploy.Deploy(context.TODO(), c, &deploy.Options{ApplySet: applySet}, logger)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ApplySet: applySet, Components: []string{crds.ComponentEvents}}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(len(getBundleCRDs())))
+	})
+
+	It("renders only the CRDs of the selected components", func() {
+		var out bytes.Buffer
+		Expect(deploy.Template(&out, &deploy.Options{Components: []string{crds.ComponentAddons}}, logger)).To(Succeed())
+
+		docs, err := deployer.CustomSplit(out.String())
+		Expect(err).To(BeNil())
+		names := make([]string, len(docs))
+		for i := range docs {
+			u, err := k8s_utils.GetUnstructured([]byte(docs[i]))
+			Expect(err).To(BeNil())
+			names[i] = u.GetName()
+		}
+		Expect(names).To(Equal(componentCRDs(crds.ComponentAddons)))
+	})
+
+	It("deploys only the CRDs matching IncludeCRDs and not ExcludeCRDs", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{
+			IncludeCRDs: []string{"*.lib.projectsveltos.io"},
+			ExcludeCRDs: []string{"event*.lib.projectsveltos.io", "healthchecks.lib.projectsveltos.io"},
+		}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		var expected []string
+		for _, crd := range getBundleCRDs() {
+			name := crd.GetName()
+			if strings.HasSuffix(name, ".lib.projectsveltos.io") && !strings.HasPrefix(name, "event") &&
+				name != "healthchecks.lib.projectsveltos.io" {
+				expected = append(expected, name)
+			}
+		}
+		Expect(expected).ToNot(BeEmpty())
+		Expect(report.CRDs).To(HaveLen(len(expected)))
+		for i := range report.CRDs {
+			Expect(report.CRDs[i].Name).To(Equal(expected[i]))
+		}
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(len(expected)))
+	})
+
+	It("leaves excluded CRDs untouched, even when pruning", func() {
+		c := newFakeClient(droppedCRD())
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		opts := &deploy.Options{ExcludeCRDs: []string{droppedCRD().Name}, Prune: true, PruneForce: true}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: droppedCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("leaves excluded ApplySet members untouched", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, applySetOptions(subsetBundle(3)), logger)
+		Expect(err).To(BeNil())
+
+		excluded := getBundleCRDs()[2].GetName()
+		opts := applySetOptions(subsetBundle(2))
+		opts.ExcludeCRDs = []string{excluded}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: excluded},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("rejects invalid CRD name patterns", func() {
+		err := (&deploy.Options{ExcludeCRDs: []string{"[event*"}}).Validate()
+		Expect(err).To(MatchError(ContainSubstring(`invalid CRD name pattern "[event*"`)))
+	})
+
+	It("rejects unknown components", func() {
+		err := (&deploy.Options{Components: []string{crds.ComponentAddons, "addon"}}).Validate()
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(`unknown component "addon"`))
+	})
+})
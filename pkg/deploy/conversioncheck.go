@@ -0,0 +1,159 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ConversionWebhookError is returned when the storage version of a CRD using
+// webhook conversion would change while its conversion webhook looks
+// unhealthy: reads and writes of the CRD resources would then fail
+type ConversionWebhookError struct {
+	// CRD is the name of the CRD
+	CRD string
+
+	// From and To are the current and the new storage versions
+	From string
+	To   string
+
+	// Reason tells why the webhook looks unhealthy
+	Reason string
+}
+
+func (e *ConversionWebhookError) Error() string {
+	return fmt.Sprintf("refusing to change the storage version of CRD %s from %s to %s: its conversion webhook "+
+		"looks unhealthy: %s. Fix the webhook or skip the conversion check", e.CRD, e.From, e.To, e.Reason)
+}
+
+// liveStorageVersion returns the name of the storage version of crd
+func liveStorageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			return crd.Spec.Versions[i].Name
+		}
+	}
+	return ""
+}
+
+// checkConversionWebhook verifies, before u changes the storage version of
+// live, that the conversion webhook of u is healthy: its Service exists and
+// has ready endpoints and, with ConversionCheckRead, a read of an existing
+// instance in the new storage version, which requires a conversion, succeeds.
+// Updates not changing the storage version are not checked.
+func checkConversionWebhook(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	u *unstructured.Unstructured, opts *Options, logger logr.Logger) error {
+
+	from, to := liveStorageVersion(live), storageVersion(u)
+	if opts.SkipConversionCheck || !usesWebhookConversion(u) || from == "" || from == to {
+		return nil
+	}
+
+	desired, err := toCustomResourceDefinition(u)
+	if err != nil {
+		return err
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s storage version changes from %s to %s, "+
+		"checking its conversion webhook", u.GetName(), from, to))
+
+	reason, err := conversionWebhookProblem(ctx, c, desired.Spec.Conversion.Webhook, logger)
+	if err == nil && reason == "" && opts.ConversionCheckRead {
+		reason, err = conversionReadProblem(ctx, c, live, to)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check the conversion webhook of CRD %s: %w", u.GetName(), err)
+	}
+	if reason != "" {
+		return &ConversionWebhookError{CRD: u.GetName(), From: from, To: to, Reason: reason}
+	}
+	return nil
+}
+
+// conversionWebhookProblem returns why the conversion webhook Service looks
+// unhealthy, or an empty string. Webhooks reached through an URL cannot be
+// resolved and are not checked.
+func conversionWebhookProblem(ctx context.Context, c client.Client, webhook *apiextensionsv1.WebhookConversion,
+	logger logr.Logger) (string, error) {
+
+	if webhook == nil || webhook.ClientConfig == nil || webhook.ClientConfig.Service == nil {
+		logger.V(logs.LogInfo).Info("conversion webhook is reached through an URL, its Service is not checked")
+		return "", nil
+	}
+
+	ref := webhook.ClientConfig.Service
+	service := &corev1.Service{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, service)
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("Service %s/%s not found", ref.Namespace, ref.Name), nil
+	}
+	if err != nil || service.Spec.Type == corev1.ServiceTypeExternalName {
+		return "", err
+	}
+
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := c.List(ctx, endpointSlices, client.InNamespace(ref.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: ref.Name}); err != nil {
+		return "", err
+	}
+	for i := range endpointSlices.Items {
+		for j := range endpointSlices.Items[i].Endpoints {
+			ready := endpointSlices.Items[i].Endpoints[j].Conditions.Ready
+			if ready == nil || *ready {
+				return "", nil
+			}
+		}
+	}
+	return fmt.Sprintf("Service %s/%s has no ready endpoints", ref.Namespace, ref.Name), nil
+}
+
+// conversionReadProblem reads an instance of live in version, still stored in
+// the current storage version: the read requires a conversion. It returns why
+// the read failed, or an empty string.
+func conversionReadProblem(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	version string) (string, error) {
+
+	served := false
+	for i := range live.Spec.Versions {
+		served = served || (live.Spec.Versions[i].Name == version && live.Spec.Versions[i].Served)
+	}
+	if !served {
+		// The version is new: no instance can be read in it yet
+		return "", nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: live.Spec.Group, Version: version,
+		Kind: live.Spec.Names.ListKind})
+	err := c.List(ctx, list, client.Limit(1))
+	if err != nil && !apierrors.IsForbidden(err) {
+		return fmt.Sprintf("reading %s %s failed: %v", version, live.Spec.Names.Plural, err), nil
+	}
+	return "", nil
+}
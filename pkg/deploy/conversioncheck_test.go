@@ -0,0 +1,155 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Conversion webhook check", func() {
+	// liveCRD returns crdWithWebhookMultipleVersions storing v1alpha1: the
+	// bundle makes v1beta1 the storage version
+	liveCRD := func() *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+			parse(crdWithWebhookMultipleVersions).Object, crd)).To(Succeed())
+		crd.Spec.Versions[0].Storage = true
+		crd.Spec.Versions[1].Storage = false
+		crd.Status.StoredVersions = []string{"v1alpha1"}
+		return crd
+	}
+
+	webhookService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook-service"}}
+
+	endpointSlice := func(ready bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook-service-abcde",
+				Labels: map[string]string{discoveryv1.LabelServiceName: "webhook-service"}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)}},
+			},
+		}
+	}
+
+	process := func(c client.Client, opts *deploy.Options) (*deploy.CRDResult, error) {
+		result := &deploy.CRDResult{}
+		desired := parse(crdWithWebhookMultipleVersions)
+		return result, deploy.ProcessCustomResourceDefinition(context.TODO(), c,
+			parse(crdWithWebhookMultipleVersions), desired, opts, result, logger)
+	}
+
+	storedVersion := func(c client.Client) string {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: "widgets.lib.projectsveltos.io"}, crd)).To(Succeed())
+		for i := range crd.Spec.Versions {
+			if crd.Spec.Versions[i].Storage {
+				return crd.Spec.Versions[i].Name
+			}
+		}
+		return ""
+	}
+
+	expectRefused := func(err error, reason string) {
+		var webhookErr *deploy.ConversionWebhookError
+		Expect(errors.As(err, &webhookErr)).To(BeTrue())
+		Expect(webhookErr.From).To(Equal("v1alpha1"))
+		Expect(webhookErr.To).To(Equal("v1beta1"))
+		Expect(webhookErr.Reason).To(ContainSubstring(reason))
+	}
+
+	It("flips the storage version when the webhook has ready endpoints", func() {
+		c := newFakeClient(liveCRD(), webhookService, endpointSlice(true))
+
+		result, err := process(c, &deploy.Options{})
+		Expect(err).To(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+		Expect(storedVersion(c)).To(Equal("v1beta1"))
+	})
+
+	It("refuses the flip when the webhook Service is missing", func() {
+		c := newFakeClient(liveCRD())
+
+		_, err := process(c, &deploy.Options{})
+		expectRefused(err, "Service system/webhook-service not found")
+		Expect(storedVersion(c)).To(Equal("v1alpha1"))
+	})
+
+	It("refuses the flip when the webhook Service has no ready endpoints", func() {
+		c := newFakeClient(liveCRD(), webhookService, endpointSlice(false))
+
+		_, err := process(c, &deploy.Options{})
+		expectRefused(err, "no ready endpoints")
+		Expect(storedVersion(c)).To(Equal("v1alpha1"))
+	})
+
+	It("does not check with SkipConversionCheck", func() {
+		c := newFakeClient(liveCRD())
+
+		_, err := process(c, &deploy.Options{SkipConversionCheck: true})
+		Expect(err).To(BeNil())
+		Expect(storedVersion(c)).To(Equal("v1beta1"))
+	})
+
+	It("does not check updates keeping the storage version", func() {
+		live := liveCRD()
+		live.Spec.Versions[0].Storage = false
+		live.Spec.Versions[1].Storage = true
+		live.Spec.Names.ShortNames = []string{"wg"}
+		c := newFakeClient(live)
+
+		result, err := process(c, &deploy.Options{})
+		Expect(err).To(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+	})
+
+	It("refuses the flip, with ConversionCheckRead, when reading in the new version fails", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(liveCRD(), webhookService, endpointSlice(true)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*unstructured.UnstructuredList); ok {
+						return apierrors.NewInternalError(errors.New("conversion webhook for widgets failed"))
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}).Build()
+
+		_, err := process(c, &deploy.Options{ConversionCheckRead: true})
+		expectRefused(err, "conversion webhook for widgets failed")
+		Expect(storedVersion(c)).To(Equal("v1alpha1"))
+	})
+})
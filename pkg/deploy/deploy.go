@@ -0,0 +1,686 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// Deploy creates or updates, in the cluster c points to, all the Sveltos CRDs
+// contained in the bundle (by default the embedded one). Nothing is written
+// while the well-known ConfigMap carries the PausedAnnotation.
+// A Report describing what happened to each CRD is always returned, even when
+// an error is.
+func Deploy(ctx context.Context, c client.Client, opts *Options, logger logr.Logger) (*Report, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	ctx, span := tracer.Start(ctx, "Deploy")
+	defer span.End()
+
+	report, err := runDeploy(ctx, c, opts, logger)
+	opts.progress(logger, &ProgressEvent{Phase: PhaseRunCompleted, Report: report, Err: err})
+	span.SetAttributes(
+		attribute.String(AttributeBundleDigest, report.BundleDigest),
+		attribute.String(AttributeBundleVersion, report.BundleVersion),
+		attribute.String(AttributeRunStatus, string(report.Status)),
+	)
+	setSpanError(span, err)
+	return report, err
+}
+
+// runDeploy implements Deploy
+func runDeploy(ctx context.Context, c client.Client, opts *Options, logger logr.Logger) (*Report, error) {
+	start := time.Now()
+
+	b := opts.getBundle()
+	report := newReport(b)
+	report.ObserveOnly = opts.ObserveOnly
+
+	if err := opts.Validate(); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("invalid options: %v", err))
+		report.Status = RunStatusFailed
+		return report, err
+	}
+
+	paused, err := isPaused(ctx, c, opts)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get %s/%s ConfigMap: %v",
+			ConfigMapNamespace, ConfigMapName, err))
+		report.Status = RunStatusFailed
+		report.Duration = metav1.Duration{Duration: time.Since(start)}
+		return report, err
+	}
+	if paused {
+		logWarning(logger, "crd-manager is PAUSED by the %s annotation on ConfigMap %s/%s, no CRD is written",
+			PausedAnnotation, ConfigMapNamespace, ConfigMapName)
+		report.Status = RunStatusPaused
+		report.Duration = metav1.Duration{Duration: time.Since(start)}
+		return report, nil
+	}
+
+	if opts.Lock != nil && opts.writes() {
+		release, err := opts.Lock.acquire(ctx, c, logger)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(err.Error())
+			report.Status = RunStatusFailed
+			report.Duration = metav1.Duration{Duration: time.Since(start)}
+			return report, err
+		}
+		defer release()
+	}
+
+	if opts, err = opts.withOwnerReference(ctx, c, logger); err != nil {
+		logger.V(logs.LogInfo).Info(err.Error())
+		report.Status = RunStatusFailed
+		report.Duration = metav1.Duration{Duration: time.Since(start)}
+		return report, err
+	}
+
+	if opts, err = opts.withQuarantine(ctx, c, logger); err != nil {
+		logger.V(logs.LogInfo).Info(err.Error())
+		report.Status = RunStatusFailed
+		report.Duration = metav1.Duration{Duration: time.Since(start)}
+		return report, err
+	}
+
+	err = deploySveltosCRDs(ctx, c, b.Content, opts, report, logger)
+	if err != nil {
+		report.Status = RunStatusFailed
+	}
+	setOrigins(report, b)
+
+	report.Duration = metav1.Duration{Duration: time.Since(start)}
+	if opts.History != nil && !opts.ObserveOnly {
+		// The history is informational: failing to record it does not fail the run
+		record := appliedBundleRecord(b, opts, report, logger)
+		if historyErr := recordHistory(ctx, c, opts, report, record, logger); historyErr != nil {
+			logWarning(logger, "%v", historyErr)
+		}
+	}
+	return report, err
+}
+
+// setOrigins sets the component of every CRD result and, when b is merged
+// from several sources, the source it comes from
+func setOrigins(report *Report, b *bundle.Bundle) {
+	for i := range report.CRDs {
+		report.CRDs[i].Component = crds.ComponentOf(report.CRDs[i].Name)
+		if len(b.Origins) > 0 {
+			report.CRDs[i].Source = b.SourceOf(report.CRDs[i].Name)
+			provenance := b.ProvenanceOf(report.CRDs[i].Name)
+			report.CRDs[i].Provenance = &provenance
+		}
+	}
+}
+
+// bundleCRD is a CRD of the bundle being deployed
+type bundleCRD struct {
+	// original is the CRD as found in the bundle
+	original *unstructured.Unstructured
+
+	// desired is the CRD to apply: original with all mutations applied
+	desired *unstructured.Unstructured
+
+	// err is set when the CRD could not be prepared for deployment
+	err error
+
+	// backup is, with RollbackOnFailure, the live CRD before the run wrote it
+	backup *apiextensionsv1.CustomResourceDefinition
+}
+
+// prepareBundleCRDs parses the bundle and applies all mutations to its CRDs.
+// Malformed documents are skipped, and reported by a *ParseError, unless
+// opts.StrictParse is set: none is then returned.
+func prepareBundleCRDs(ctx context.Context, bundle []byte, opts *Options, logger logr.Logger) ([]*bundleCRD, error) {
+	ctx, span := tracer.Start(ctx, "ParseBundle")
+	defer span.End()
+
+	objs, failures, err := parseBundle(bundle)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get Sveltos CRD instances: %v", err))
+		setSpanError(span, err)
+		return nil, err
+	}
+
+	var detectedErrors error
+	if len(failures) > 0 {
+		parseErr := &ParseError{Failures: failures}
+		logger.V(logs.LogInfo).Info(parseErr.Error())
+		if opts.StrictParse {
+			setSpanError(span, parseErr)
+			return nil, parseErr
+		}
+		detectedErrors = parseErr
+	}
+
+	result := make([]*bundleCRD, 0, len(objs))
+	for _, u := range objs {
+		crd := &bundleCRD{original: u.DeepCopy(), desired: u}
+		crd.err = traceStep(ctx, "Mutate", func(context.Context) error {
+			if err := setBundleHash(u); err != nil {
+				return err
+			}
+			return applyMutations(u, opts, logger)
+		}, attribute.String(AttributeCRDName, u.GetName()))
+		result = append(result, crd)
+		opts.progress(logger, &ProgressEvent{Phase: PhaseParsed, CRD: u.GetName()})
+	}
+
+	if err := validatePatchTargets(opts.Patches, result); err != nil {
+		logger.V(logs.LogInfo).Info(err.Error())
+		setSpanError(span, err)
+		return nil, err
+	}
+
+	setSpanError(span, detectedErrors)
+	return result, detectedErrors
+}
+
+func deploySveltosCRDs(ctx context.Context, c client.Client, bundle []byte, opts *Options,
+	report *Report, logger logr.Logger) error {
+
+	crds, detectedErrors := prepareBundleCRDs(ctx, bundle, opts, logger)
+	var parseErr *ParseError
+	if errors.As(detectedErrors, &parseErr) {
+		report.ParseFailures = parseErr.Failures
+	}
+	if crds == nil {
+		return detectedErrors
+	}
+
+	selected := selectComponents(crds, opts)
+	reportNotSelected(crds, selected, opts, logger)
+
+	conflicts, err := checkNameConflicts(ctx, c, selected, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to check CRD name conflicts: %v", err))
+		return err
+	}
+	report.NameConflicts = conflicts
+	if len(conflicts) > 0 && opts.FailOnNameConflicts {
+		return fmt.Errorf("%d CRD name conflicts detected", len(conflicts))
+	}
+
+	if opts.ApplySet != nil && !opts.ObserveOnly {
+		if err := ensureApplySetParent(ctx, c, opts.ApplySet, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to prepare ApplySet parent %s: %v", opts.ApplySet, err))
+			return err
+		}
+	}
+
+	if err := deploySelected(ctx, c, selected, opts, report, logger); err != nil {
+		if detectedErrors != nil {
+			return errors.Join(detectedErrors, err)
+		}
+		return err
+	}
+	if detectedErrors != nil {
+		return detectedErrors
+	}
+	return completeRun(ctx, c, crds, opts, report, logger)
+}
+
+// deploySelected deploys the selected crds, adding their results to the
+// report. Failures do not stop the other CRDs, unless FailFast is set or the
+// CircuitBreaker opens: a *DeployError lists all of them.
+func deploySelected(ctx context.Context, c client.Client, selected []*bundleCRD, opts *Options,
+	report *Report, logger logr.Logger) error {
+
+	failed := &DeployError{}
+	for i, crd := range selected {
+		if opts.Defer != nil && opts.Defer(crd.desired.GetName()) {
+			result := CRDResult{Name: crd.desired.GetName(), Action: ActionDeferred}
+			report.CRDs = append(report.CRDs, result)
+			opts.progress(logger, resultEvent(&result, nil))
+			continue
+		}
+		if result := opts.quarantined(crd, logger); result != nil {
+			report.CRDs = append(report.CRDs, *result)
+			opts.progress(logger, resultEvent(result, nil))
+			continue
+		}
+
+		if err := opts.CircuitBreaker.allow(logger); err != nil {
+			report.CircuitBreaker = &err.Status
+			reportNotAttempted(selected[i:], "circuit breaker open", opts, report, logger)
+			return failed.joinTo(err)
+		}
+
+		result, err := deployCRD(ctx, c, crd, opts, logger)
+		opts.CircuitBreaker.record(crd.desired, err, opts, logger)
+		report.CRDs = append(report.CRDs, result)
+		if err == nil {
+			continue
+		}
+		failed.add(crd.desired.GetName(), err)
+		if opts.FailFast {
+			reportNotAttempted(selected[i+1:], "fail fast", opts, report, logger)
+			break
+		}
+	}
+
+	if len(failed.Failures) == 0 {
+		return nil
+	}
+	logger.V(logs.LogInfo).Info(failed.Error())
+	return failed
+}
+
+// deployCRD processes a single bundle CRD and returns its result
+func deployCRD(ctx context.Context, c client.Client, crd *bundleCRD, opts *Options,
+	logger logr.Logger) (CRDResult, error) {
+
+	u := crd.desired
+	ctx, span := tracer.Start(ctx, "DeployCRD", trace.WithAttributes(attribute.String(AttributeCRDName, u.GetName())))
+	defer span.End()
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("considering Sveltos CRD %s", u.GetName()))
+	start := time.Now()
+	result := CRDResult{Name: u.GetName()}
+	ctx, warnings := withWarningCollector(ctx)
+	err := crd.err
+	if err == nil {
+		err = attemptCRD(ctx, c, crd, opts, &result, logger)
+	}
+	result.Duration = metav1.Duration{Duration: time.Since(start)}
+	result.Warnings = warnings.get()
+	for _, warning := range result.Warnings {
+		logWarning(logger, "API server warning for Sveltos CRD %s: %s", u.GetName(), warning)
+	}
+	if err == nil && opts.FailOnWarnings && len(result.Warnings) > 0 {
+		err = &WarningsError{CRD: u.GetName(), Warnings: result.Warnings}
+	}
+	if err != nil {
+		result.Action = ActionFailed
+		result.Drift = ""
+		result.Error = err.Error()
+		result.ErrorClass = errorClass(err)
+		if result.AdmissionDenial = admissionDenial(err); result.AdmissionDenial != nil {
+			logWarning(logger, "admission denied: Sveltos CRD %s write denied by admission webhook %q: %s",
+				u.GetName(), result.AdmissionDenial.Webhook, result.AdmissionDenial.Message)
+		}
+	}
+	span.SetAttributes(
+		attribute.String(AttributeCRDAction, string(result.Action)),
+		attribute.String(AttributeCRDDrift, string(result.Drift)),
+		spanResult(err),
+	)
+	setSpanError(span, err)
+	opts.progress(logger, resultEvent(&result, err))
+	return result, err
+}
+
+// completeRun runs the steps following a run in which every CRD was processed
+// successfully: the post-apply verification, waiting for the CRDs to be
+// established, removing obsolete CRDs, pruning the managed CRDs and the
+// ApplySet and advancing the version marker or, in observe-only mode, looking
+// for extra managed CRDs
+func completeRun(ctx context.Context, c client.Client, crds []*bundleCRD, opts *Options,
+	report *Report, logger logr.Logger) error {
+
+	if opts.ObserveOnly {
+		var err error
+		report.ExtraCRDs, err = extraManagedCRDs(ctx, c, crds)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list CRDs managed by crd-manager: %v", err))
+			return err
+		}
+		for _, name := range report.ExtraCRDs {
+			logWarning(logger, "CRD %s is managed by crd-manager but not part of the bundle", name)
+		}
+		return nil
+	}
+
+	if err := verifyRun(ctx, c, crds, opts, report, logger); err != nil {
+		return err
+	}
+
+	if opts.WaitTimeout > 0 {
+		if err := waitForApplied(ctx, c, opts, report, logger); err != nil {
+			return err
+		}
+	}
+
+	if opts.ProtectCRDs {
+		if err := protectCRDs(ctx, c, opts, logger); err != nil {
+			return err
+		}
+	}
+
+	if opts.RemoveObsolete {
+		if err := removeObsoleteCRDs(ctx, c, opts, report, logger); err != nil {
+			return err
+		}
+	}
+
+	// Pruning is never done after a failure, which could delete CRDs still needed
+	if opts.Prune {
+		if err := pruneManagedCRDs(ctx, c, opts, crds, report, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to prune CRDs managed by crd-manager: %v", err))
+			return err
+		}
+	}
+
+	if opts.ApplySet != nil {
+		if err := pruneApplySet(ctx, c, opts, crds, report, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to prune ApplySet %s: %v", opts.ApplySet, err))
+			return err
+		}
+	}
+
+	if opts.VersionMarker != nil {
+		return advanceVersionMarker(ctx, c, opts.VersionMarker, report, logger)
+	}
+	return nil
+}
+
+// reportNotSelected reports the crds not part of selected as skipped, and warns
+// about the IncludeCRDs and ExcludeCRDs patterns matching none of crds
+func reportNotSelected(crds, selected []*bundleCRD, opts *Options, logger logr.Logger) {
+	for _, pattern := range unmatchedPatterns(crds, opts) {
+		logWarning(logger, "CRD name pattern %q matches no bundle CRD", pattern)
+	}
+	if len(selected) == len(crds) {
+		return
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("deploying the %d Sveltos CRDs selected (%s), %d left untouched",
+		len(selected), opts.describeSelection(), len(crds)-len(selected)))
+	for _, crd := range crds {
+		if !opts.selectsCRD(crd.desired.GetName()) {
+			opts.progress(logger, &ProgressEvent{Phase: PhaseSkipped, CRD: crd.desired.GetName(), Reason: ReasonNotSelected})
+		}
+	}
+}
+
+// reportNotAttempted adds crds, which the run gave up on for reason, to the report
+func reportNotAttempted(crds []*bundleCRD, reason string, opts *Options, report *Report, logger logr.Logger) {
+	names := make([]string, len(crds))
+	for i, crd := range crds {
+		names[i] = crd.desired.GetName()
+		result := CRDResult{Name: names[i], Action: ActionNotAttempted}
+		report.CRDs = append(report.CRDs, result)
+		opts.progress(logger, resultEvent(&result, nil))
+	}
+	if len(names) > 0 {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("%s: Sveltos CRDs not attempted: %s",
+			reason, strings.Join(names, ", ")))
+	}
+}
+
+// processCustomResourceDefinition creates or updates a CRD. original is the CRD
+// as found in the bundle, u is the CRD to apply (original with all mutations applied).
+func processCustomResourceDefinition(ctx context.Context, c client.Client, original, u *unstructured.Unstructured,
+	opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	validation, err := fieldValidation(opts)
+	if err != nil {
+		return err
+	}
+
+	customResourceDefinition := &apiextensionsv1.CustomResourceDefinition{}
+	err = getCRD(ctx, c, u.GetName(), customResourceDefinition)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return createMissingCRD(ctx, c, u, validation, opts, result, logger)
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get default Sveltos CRD instance: %v", err))
+		return err
+	}
+
+	if customResourceDefinition.DeletionTimestamp != nil {
+		return processTerminatingCRD(ctx, c, customResourceDefinition, u, validation, opts, result, logger)
+	}
+
+	if held, err := holdCRD(customResourceDefinition, u, opts, result, logger); held {
+		return err
+	}
+
+	if manager := resolveOwnership(customResourceDefinition, opts, logger); manager != nil {
+		result.Action = manager.action
+		return reportExternalDrift(customResourceDefinition, original, manager, result, logger)
+	}
+
+	setApplySetMember(u, opts)
+	if isManagedByCRDManager(customResourceDefinition) {
+		setManagedBy(u)
+	} else {
+		switch opts.AdoptExisting {
+		case AdoptionTwoPhase:
+			result.Action = ActionAdopted
+			if err := setDrift(customResourceDefinition, u, result); err != nil {
+				return err
+			}
+			return adoptCRD(ctx, c, customResourceDefinition, opts, logger)
+		case AdoptionAuto:
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("adopting Sveltos CRD %s", u.GetName()))
+			setManagedBy(u)
+		}
+	}
+
+	if err := preserveCABundle(customResourceDefinition, u); err != nil {
+		return err
+	}
+	if err := checkStorageVersionOverride(customResourceDefinition, original, u, opts); err != nil {
+		return err
+	}
+	if err := mergeLive(customResourceDefinition, u, opts, logger); err != nil {
+		return err
+	}
+
+	upToDate, err := isUpToDate(customResourceDefinition, u)
+	if err != nil {
+		return err
+	}
+	if upToDate {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is up to date", u.GetName()))
+		result.Action = ActionUnchanged
+		result.Drift = DriftStatusInSync
+		return nil
+	}
+
+	if opts.CheckExistingCRs {
+		if err := checkExistingCRs(ctx, c, customResourceDefinition, u, opts, result, logger); err != nil {
+			return err
+		}
+	}
+
+	u.SetResourceVersion(customResourceDefinition.GetResourceVersion())
+	opts.progress(logger, &ProgressEvent{Phase: PhaseUpdating, CRD: u.GetName()})
+	result.Action = ActionUpdated
+	result.Drift = DriftStatusInSync
+	return updateCRD(ctx, c, customResourceDefinition, u, validation, opts, result, logger)
+}
+
+// holdCRD returns true if live, paused or pinned at another version, must be
+// left untouched
+func holdCRD(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured, opts *Options,
+	result *CRDResult, logger logr.Logger) (bool, error) {
+
+	if isCRDPaused(live) {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is paused by the %s annotation, skipping",
+			u.GetName(), PausedAnnotation))
+		result.Action = ActionPaused
+		return true, setDrift(live, u, result)
+	}
+
+	result.PinnedVersion = pinnedVersion(live)
+	if isPinnedAway(live, opts) {
+		return true, skipPinnedCRD(live, u, opts, result, logger)
+	}
+	return false, nil
+}
+
+// createMissingCRD creates u, a bundle CRD missing from the cluster
+func createMissingCRD(ctx context.Context, c client.Client, u *unstructured.Unstructured, validation string,
+	opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	opts.progress(logger, &ProgressEvent{Phase: PhaseCreating, CRD: u.GetName()})
+	result.Action = ActionCreated
+	result.Drift = DriftStatusInSync
+	setManagedBy(u)
+	setApplySetMember(u, opts)
+	return createCRD(ctx, c, u, validation, opts, logger)
+}
+
+// createCRD creates u, recording the creation in the audit log
+func createCRD(ctx context.Context, c client.Client, u *unstructured.Unstructured, validation string,
+	opts *Options, logger logr.Logger) error {
+
+	if err := setAppliedBy(u); err != nil {
+		return err
+	}
+	setOwnerReference(u, opts)
+	if err := setLastApplied(u, opts, logger); err != nil {
+		return err
+	}
+	var err error
+	if opts.ApplyStrategy == ApplyStrategyServerSide {
+		err = applyCRD(ctx, c, u, validation, opts)
+	} else {
+		err = traceStep(ctx, "Write", func(ctx context.Context) error {
+			return c.Create(ctx, u, opts.fieldManager(), client.FieldValidation(validation))
+		}, attribute.String(AttributeOperation, "create"))
+	}
+	return auditWrite(opts, &AuditEntry{CRD: u.GetName(), Action: AuditActionCreate},
+		wrapWriteError(u.GetName(), err), logger)
+}
+
+// updateCRD replaces, patches with ApplyStrategyPatch or applies with
+// ApplyStrategyServerSide, live with u, recording the update in the audit log
+func updateCRD(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	u *unstructured.Unstructured, validation string, opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	if err := checkConversionWebhook(ctx, c, live, u, opts, logger); err != nil {
+		return err
+	}
+	if err := setAppliedBy(u); err != nil {
+		return err
+	}
+	// Owner references, such as the one set at creation, are not part of the bundle
+	u.SetOwnerReferences(live.GetOwnerReferences())
+	if err := setLastApplied(u, opts, logger); err != nil {
+		return err
+	}
+	entry := &AuditEntry{CRD: u.GetName(), Action: AuditActionUpdate}
+	if opts.AuditLog != nil {
+		desired, err := toCustomResourceDefinition(u)
+		if err != nil {
+			return err
+		}
+		if entry.ChangedPaths, err = changedPaths(live, desired); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch opts.ApplyStrategy {
+	case ApplyStrategyPatch:
+		return patchCRD(ctx, c, live, u, validation, entry, opts, result, logger)
+	case ApplyStrategyServerSide:
+		if err = upgradeManagedFields(ctx, c, live, opts, logger); err == nil {
+			err = applyCRD(ctx, c, u, validation, opts)
+		}
+	default:
+		err = traceStep(ctx, "Write", func(ctx context.Context) error {
+			return c.Update(ctx, u, opts.fieldManager(), client.FieldValidation(validation))
+		}, attribute.String(AttributeOperation, "update"))
+	}
+	return auditWrite(opts, entry, wrapWriteError(u.GetName(), err), logger)
+}
+
+// adoptCRD adds the crd-manager ownership markers to live, recording the
+// update in the audit log
+func adoptCRD(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	opts *Options, logger logr.Logger) error {
+
+	opts.progress(logger, &ProgressEvent{Phase: PhaseUpdating, CRD: live.GetName()})
+	before := live.DeepCopy()
+	err := traceStep(ctx, "Write", func(ctx context.Context) error {
+		return adopt(ctx, c, live, logger)
+	}, attribute.String(AttributeOperation, "adopt"))
+
+	entry := &AuditEntry{CRD: live.GetName(), Action: AuditActionUpdate}
+	if opts.AuditLog != nil {
+		var pathsErr error
+		if entry.ChangedPaths, pathsErr = changedPaths(before, live); pathsErr != nil {
+			return errors.Join(err, pathsErr)
+		}
+	}
+	return auditWrite(opts, entry, wrapAdmissionDenial(live.GetName(), err), logger)
+}
+
+// wrapWriteError adds to err, returned by the create or update of the CRD
+// named name, what the API server rejection means
+func wrapWriteError(name string, err error) error {
+	return wrapAdmissionDenial(name, wrapFieldValidationError(name, err))
+}
+
+// getCRD gets the live CRD named name. Not finding it is not an error of the
+// Get span: the CRD is then created.
+func getCRD(ctx context.Context, c client.Client, name string, crd *apiextensionsv1.CustomResourceDefinition) error {
+	ctx, span := tracer.Start(ctx, "Get")
+	defer span.End()
+
+	err := c.Get(ctx, types.NamespacedName{Name: name}, crd)
+	if !apierrors.IsNotFound(err) {
+		setSpanError(span, err)
+	}
+	return err
+}
+
+// setDrift records in result whether the live CRD spec matches the desired one.
+// It relies on the same comparison deciding whether a CRD needs an update.
+func setDrift(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	result *CRDResult) error {
+
+	inSync, err := isInSync(live, u)
+	if err != nil {
+		return err
+	}
+	result.Drift = DriftStatusInSync
+	if !inSync {
+		result.Drift = DriftStatusDrifted
+	}
+	return nil
+}
+
+// logWarning logs, regardless of the verbosity, a message operators must act upon
+func logWarning(logger logr.Logger, format string, args ...any) {
+	logger.Info("WARNING: " + fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sveltoscrds "github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+var (
+	scheme *runtime.Scheme
+	logger = klog.Background()
+
+	// spans records the spans of all runs
+	spans = tracetest.NewInMemoryExporter()
+)
+
+func TestDeploy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Deploy Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+})
+
+func newFakeClient(initObjects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+}
+
+// getBundleCRDs returns all the CRDs contained in the embedded bundle
+func getBundleCRDs() []*unstructured.Unstructured {
+	objs, err := deployer.CustomSplit(string(sveltoscrds.GetSveltosCRDYAML()))
+	Expect(err).To(BeNil())
+
+	result := make([]*unstructured.Unstructured, len(objs))
+	for i := range objs {
+		result[i], err = k8s_utils.GetUnstructured([]byte(objs[i]))
+		Expect(err).To(BeNil())
+	}
+	return result
+}
+
+// getBundleHash returns the BundleHashAnnotation crd-manager sets on the CRD
+// with the given name from the embedded bundle
+func getBundleHash(name string) string {
+	for _, u := range getBundleCRDs() {
+		if u.GetName() == name {
+			return bundleHash(u)
+		}
+	}
+	Fail("CRD " + name + " not found in bundle")
+	return ""
+}
+
+// bundleHash returns the BundleHashAnnotation crd-manager sets on u
+func bundleHash(u *unstructured.Unstructured) string {
+	hash, err := sveltoscrds.SpecHash(u.Object)
+	Expect(err).To(BeNil())
+	return hash
+}
+
+// getBundleCRD returns the CRD with the given name from the embedded bundle
+func getBundleCRD(name string) *apiextensionsv1.CustomResourceDefinition {
+	for _, u := range getBundleCRDs() {
+		if u.GetName() == name {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), crd)).To(Succeed())
+			return crd
+		}
+	}
+	Fail("CRD " + name + " not found in bundle")
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	sveltosClusterCRD = "sveltosclusters.lib.projectsveltos.io"
+)
+
+func findResult(report *deploy.Report, name string) *deploy.CRDResult {
+	for i := range report.CRDs {
+		if report.CRDs[i].Name == name {
+			return &report.CRDs[i]
+		}
+	}
+	return nil
+}
+
+// newRejectingClient returns a fake client failing the creation of the CRD named name
+func newRejectingClient(name string) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == name {
+				return errors.New("rejected by webhook")
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("Deploy", func() {
+	It("Helm managed CRDs in sync with the bundle are skipped and reported in sync", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionSkippedHelm))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusInSync))
+		Expect(report.CountDrift(deploy.ActionSkippedHelm, deploy.DriftStatusInSync)).To(Equal(1))
+	})
+
+	It("Helm managed CRDs differing from the bundle are skipped and reported drifted", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionSkippedHelm))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"sc"}))
+	})
+
+	It("Helm managed CRDs are compared with the bundle without mutations", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Category: "sveltos"}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Drift).To(Equal(deploy.DriftStatusInSync))
+	})
+
+	It("Helm managed CRDs are updated with ForceOwnership", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ForceOwnership: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(BeEmpty())
+	})
+
+	It("FailFast stops at the first CRD failure", func() {
+		failing := getBundleCRDs()[1].GetName()
+		c := newRejectingClient(failing)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{FailFast: true}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(report.CRDs).To(HaveLen(len(getBundleCRDs())))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(1))
+		Expect(findResult(report, failing).Action).To(Equal(deploy.ActionFailed))
+		Expect(report.Count(deploy.ActionNotAttempted)).To(Equal(len(report.CRDs) - 2))
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(HaveLen(1))
+	})
+
+	It("without FailFast all CRDs are processed despite failures", func() {
+		failing := getBundleCRDs()[1].GetName()
+		c := newRejectingClient(failing)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(1))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs) - 1))
+	})
+})
@@ -0,0 +1,317 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Severity is how serious a doctor finding is
+type Severity string
+
+const (
+	// SeverityWarning means the CRD works but needs attention
+	SeverityWarning = Severity("warning")
+
+	// SeverityError means the CRD, or its resources, cannot be used as expected
+	SeverityError = Severity("error")
+)
+
+// DoctorCheck is one of the checks Diagnose runs
+type DoctorCheck string
+
+const (
+	// CheckEstablished reports bundle CRDs which are missing, not established
+	// or whose names are not accepted
+	CheckEstablished = DoctorCheck("established")
+
+	// CheckStoredVersions reports CRDs whose resources may still be stored in
+	// other versions than the storage one
+	CheckStoredVersions = DoctorCheck("stored-versions")
+
+	// CheckConversionWebhook reports CRDs whose conversion webhook Service is
+	// missing or has no ready endpoints
+	CheckConversionWebhook = DoctorCheck("conversion-webhook")
+
+	// CheckTerminating reports CRDs stuck in deletion, their instances left
+	// behind with finalizers
+	CheckTerminating = DoctorCheck("terminating")
+
+	// CheckOrphaned reports CRDs managed by crd-manager which are no longer
+	// part of the bundle
+	CheckOrphaned = DoctorCheck("orphaned")
+
+	// CheckDrift reports CRDs whose spec differs from the bundle
+	CheckDrift = DoctorCheck("drift")
+)
+
+// DoctorChecks are all the checks Diagnose runs, in order
+var DoctorChecks = []DoctorCheck{CheckEstablished, CheckStoredVersions, CheckConversionWebhook,
+	CheckTerminating, CheckOrphaned, CheckDrift}
+
+// Finding is a problem Diagnose found
+type Finding struct {
+	// Check is the check which found the problem
+	Check DoctorCheck `json:"check"`
+
+	// Severity is how serious the problem is
+	Severity Severity `json:"severity"`
+
+	// CRD is the name of the CRD affected
+	CRD string `json:"crd"`
+
+	// Explanation describes the problem
+	Explanation string `json:"explanation"`
+
+	// Remediation suggests how to fix the problem
+	Remediation string `json:"remediation"`
+}
+
+// DoctorReport lists what Diagnose found
+type DoctorReport struct {
+	// Checks are the checks run
+	Checks []DoctorCheck `json:"checks"`
+
+	// Findings are the problems found, errors first
+	Findings []Finding `json:"findings"`
+}
+
+// ParseSeverity returns the Severity value names
+func ParseSeverity(value string) (Severity, error) {
+	switch severity := Severity(value); severity {
+	case SeverityWarning, SeverityError:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q: expected %s or %s", value, SeverityWarning, SeverityError)
+	}
+}
+
+// ParseDoctorChecks returns the checks values name
+func ParseDoctorChecks(values []string) ([]DoctorCheck, error) {
+	checks := make([]DoctorCheck, 0, len(values))
+	for _, value := range values {
+		check := DoctorCheck(value)
+		if !slices.Contains(DoctorChecks, check) {
+			names := make([]string, len(DoctorChecks))
+			for i := range DoctorChecks {
+				names[i] = string(DoctorChecks[i])
+			}
+			return nil, fmt.Errorf("unknown doctor check %q: expected one of %s", value, strings.Join(names, ", "))
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// Reaches returns true if at least one finding is as serious as threshold
+func (r *DoctorReport) Reaches(threshold Severity) bool {
+	for i := range r.Findings {
+		if threshold == SeverityWarning || r.Findings[i].Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DoctorReport) add(check DoctorCheck, severity Severity, crd, remediation, explanation string,
+	args ...any) {
+
+	r.Findings = append(r.Findings, Finding{Check: check, Severity: severity, CRD: crd,
+		Explanation: fmt.Sprintf(explanation, args...), Remediation: remediation})
+}
+
+// Diagnose runs, against the bundle CRDs selected by opts, the doctor checks
+// but the skipped ones. Nothing is ever written: get and list on
+// customresourcedefinitions, get on services and list on endpointslices are
+// needed.
+func Diagnose(ctx context.Context, c client.Client, opts *Options, skip []DoctorCheck,
+	logger logr.Logger) (*DoctorReport, error) {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	report := &DoctorReport{}
+	for _, check := range DoctorChecks {
+		if !slices.Contains(skip, check) {
+			report.Checks = append(report.Checks, check)
+		}
+	}
+
+	crds, err := prepareBundleCRDs(ctx, opts.getBundle().Content, opts, logger)
+	if crds == nil {
+		return nil, err
+	}
+	for _, crd := range selectComponents(crds, opts) {
+		if err := diagnoseCRD(ctx, c, crd, opts, report, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(report.Checks, CheckOrphaned) {
+		orphaned, err := extraManagedCRDs(ctx, c, crds)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range orphaned {
+			report.add(CheckOrphaned, SeverityWarning, name,
+				"Delete it, if none of its resources is needed anymore, or run crd-manager with --remove-obsolete",
+				"CRD is managed by crd-manager but no longer part of the bundle")
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		if report.Findings[i].Severity != report.Findings[j].Severity {
+			return report.Findings[i].Severity == SeverityError
+		}
+		return report.Findings[i].CRD < report.Findings[j].CRD
+	})
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("doctor ran %d checks, %d findings", len(report.Checks),
+		len(report.Findings)))
+	return report, nil
+}
+
+// diagnoseCRD runs the per CRD checks against crd
+func diagnoseCRD(ctx context.Context, c client.Client, crd *bundleCRD, opts *Options, report *DoctorReport,
+	logger logr.Logger) error {
+
+	name := crd.desired.GetName()
+	live := &apiextensionsv1.CustomResourceDefinition{}
+	if err := getCRD(ctx, c, name, live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if slices.Contains(report.Checks, CheckEstablished) {
+			report.add(CheckEstablished, SeverityError, name, "Run crd-manager to create it",
+				"CRD is part of the bundle but missing")
+		}
+		return nil
+	}
+
+	for _, check := range report.Checks {
+		var err error
+		switch check {
+		case CheckEstablished:
+			diagnoseEstablished(live, report)
+		case CheckStoredVersions:
+			diagnoseStoredVersions(live, report)
+		case CheckConversionWebhook:
+			err = diagnoseConversionWebhook(ctx, c, live, report, logger)
+		case CheckTerminating:
+			diagnoseTerminating(live, report)
+		case CheckDrift:
+			err = diagnoseDrift(live, crd, opts, report, logger)
+		}
+		if err != nil {
+			return fmt.Errorf("doctor check %s failed for CRD %s: %w", check, name, err)
+		}
+	}
+	return nil
+}
+
+func diagnoseEstablished(live *apiextensionsv1.CustomResourceDefinition, report *DoctorReport) {
+	for i := range live.Status.Conditions {
+		condition := &live.Status.Conditions[i]
+		if condition.Type == apiextensionsv1.NamesAccepted && condition.Status == apiextensionsv1.ConditionFalse {
+			report.add(CheckEstablished, SeverityError, live.Name,
+				"Rename or delete the CRD claiming the same names in the group",
+				"CRD names are not accepted: %s", condition.Message)
+		}
+	}
+	if !isEstablished(live) {
+		report.add(CheckEstablished, SeverityError, live.Name,
+			"Check the API server logs and the CRD status conditions",
+			"CRD is not established: its resources are not served")
+	}
+}
+
+func diagnoseStoredVersions(live *apiextensionsv1.CustomResourceDefinition, report *DoctorReport) {
+	storage := liveStorageVersion(live)
+	for _, stored := range live.Status.StoredVersions {
+		switch {
+		case findCRDVersion(live, stored) == nil:
+			report.add(CheckStoredVersions, SeverityError, live.Name,
+				fmt.Sprintf("Migrate the resources to %s, then remove %s from status.storedVersions", storage, stored),
+				"status.storedVersions lists %s, which is no longer defined: resources stored in it cannot be read",
+				stored)
+		case stored != storage:
+			report.add(CheckStoredVersions, SeverityWarning, live.Name,
+				fmt.Sprintf("Migrate the resources to %s, for instance by rewriting them, then remove %s from "+
+					"status.storedVersions", storage, stored),
+				"resources may still be stored in %s while the storage version is %s: %s cannot be removed yet",
+				stored, storage, stored)
+		}
+	}
+}
+
+func diagnoseConversionWebhook(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	report *DoctorReport, logger logr.Logger) error {
+
+	if live.Spec.Conversion == nil || live.Spec.Conversion.Strategy != apiextensionsv1.WebhookConverter {
+		return nil
+	}
+	reason, err := conversionWebhookProblem(ctx, c, live.Spec.Conversion.Webhook, logger)
+	if err != nil || reason == "" {
+		return err
+	}
+	report.add(CheckConversionWebhook, SeverityError, live.Name,
+		"Make sure the Sveltos component serving the conversion webhook is deployed and running",
+		"conversion webhook looks unhealthy, reads and writes of resources in other versions fail: %s", reason)
+	return nil
+}
+
+func diagnoseTerminating(live *apiextensionsv1.CustomResourceDefinition, report *DoctorReport) {
+	if live.DeletionTimestamp == nil {
+		return
+	}
+	report.add(CheckTerminating, SeverityError, live.Name,
+		"Remove the finalizers of the remaining resources, or restart the controller owning them, so the "+
+			"deletion completes. crd-manager then recreates the CRD",
+		"CRD is being deleted since %s, its remaining resources blocking the deletion",
+		live.DeletionTimestamp.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+func diagnoseDrift(live *apiextensionsv1.CustomResourceDefinition, crd *bundleCRD, opts *Options,
+	report *DoctorReport, logger logr.Logger) error {
+
+	if crd.err != nil {
+		report.add(CheckDrift, SeverityError, live.Name, "Fix the crd-manager configuration",
+			"CRD cannot be prepared for deployment: %v", crd.err)
+		return nil
+	}
+	drifted, manager, err := liveDrifts(live, crd, opts, logger)
+	if err != nil || !drifted {
+		return err
+	}
+	remediation := "Run crd-manager to bring it in sync"
+	if manager != nil {
+		remediation = manager.remediation
+	}
+	report.add(CheckDrift, SeverityWarning, live.Name, remediation,
+		"CRD spec differs from the bundle %s", opts.getBundle().Digest())
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// establishedCRD returns the CRD yaml describes as the API server reports it
+// once established
+func establishedCRD(yaml string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(parse(yaml).Object, crd)).To(Succeed())
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+		{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+	}
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			crd.Status.StoredVersions = []string{crd.Spec.Versions[i].Name}
+		}
+	}
+	return crd
+}
+
+// testBundle returns a bundle made of crdWithWebhookMultipleVersions and
+// crdWithoutWebhook
+func testBundle() *bundle.Bundle {
+	content := strings.Join([]string{crdWithWebhookMultipleVersions, crdWithoutWebhook}, "---\n")
+	return &bundle.Bundle{Content: []byte(content)}
+}
+
+var _ = Describe("Doctor", func() {
+	webhookObjects := func() []client.Object {
+		return []client.Object{
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook-service"}},
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook-service-abcde",
+					Labels: map[string]string{discoveryv1.LabelServiceName: "webhook-service"}},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				},
+			},
+		}
+	}
+
+	diagnose := func(skip []deploy.DoctorCheck, objs ...client.Object) *deploy.DoctorReport {
+		report, err := deploy.Diagnose(context.TODO(), newFakeClient(objs...), &deploy.Options{Bundle: testBundle()}, skip, logger)
+		Expect(err).To(BeNil())
+		return report
+	}
+
+	findings := func(report *deploy.DoctorReport, check deploy.DoctorCheck) []deploy.Finding {
+		var result []deploy.Finding
+		for i := range report.Findings {
+			if report.Findings[i].Check == check {
+				result = append(result, report.Findings[i])
+			}
+		}
+		return result
+	}
+
+	It("finds no problem in a healthy cluster", func() {
+		objs := append(webhookObjects(), establishedCRD(crdWithWebhookMultipleVersions), establishedCRD(crdWithoutWebhook))
+
+		report := diagnose(nil, objs...)
+		Expect(report.Checks).To(Equal(deploy.DoctorChecks))
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Reaches(deploy.SeverityWarning)).To(BeFalse())
+	})
+
+	It("reports missing and not established CRDs", func() {
+		widgets := establishedCRD(crdWithWebhookMultipleVersions)
+		widgets.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionFalse, Message: "kind Widget in use"},
+		}
+
+		report := diagnose(nil, append(webhookObjects(), widgets)...)
+		established := findings(report, deploy.CheckEstablished)
+		Expect(established).To(HaveLen(3))
+		Expect(established[0].CRD).To(Equal("gadgets.lib.projectsveltos.io"))
+		Expect(established[0].Explanation).To(ContainSubstring("missing"))
+		Expect(established[1].Explanation).To(ContainSubstring("kind Widget in use"))
+		Expect(established[2].Explanation).To(ContainSubstring("not established"))
+		Expect(report.Reaches(deploy.SeverityError)).To(BeTrue())
+	})
+
+	It("reports resources possibly stored in older versions", func() {
+		widgets := establishedCRD(crdWithWebhookMultipleVersions)
+		widgets.Status.StoredVersions = []string{"v1alpha1", "v1beta1"}
+
+		report := diagnose(nil, append(webhookObjects(), widgets, establishedCRD(crdWithoutWebhook))...)
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Check).To(Equal(deploy.CheckStoredVersions))
+		Expect(report.Findings[0].Severity).To(Equal(deploy.SeverityWarning))
+		Expect(report.Findings[0].Remediation).To(ContainSubstring("remove v1alpha1 from status.storedVersions"))
+		Expect(report.Reaches(deploy.SeverityError)).To(BeFalse())
+		Expect(report.Reaches(deploy.SeverityWarning)).To(BeTrue())
+	})
+
+	It("reports conversion webhooks without ready endpoints", func() {
+		report := diagnose(nil, establishedCRD(crdWithWebhookMultipleVersions), establishedCRD(crdWithoutWebhook))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Check).To(Equal(deploy.CheckConversionWebhook))
+		Expect(report.Findings[0].CRD).To(Equal("widgets.lib.projectsveltos.io"))
+		Expect(report.Findings[0].Explanation).To(ContainSubstring("Service system/webhook-service not found"))
+	})
+
+	It("reports CRDs stuck in deletion", func() {
+		gadgets := establishedCRD(crdWithoutWebhook)
+		gadgets.Finalizers = []string{"customresourcecleanup.apiextensions.k8s.io"}
+		gadgets.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+
+		report := diagnose(nil, append(webhookObjects(), establishedCRD(crdWithWebhookMultipleVersions), gadgets)...)
+		terminating := findings(report, deploy.CheckTerminating)
+		Expect(terminating).To(HaveLen(1))
+		Expect(terminating[0].CRD).To(Equal("gadgets.lib.projectsveltos.io"))
+		Expect(terminating[0].Severity).To(Equal(deploy.SeverityError))
+	})
+
+	It("reports orphaned and drifted CRDs", func() {
+		gadgets := establishedCRD(crdWithoutWebhook)
+		gadgets.Spec.Names.ShortNames = []string{"gd"}
+		orphaned := establishedCRD(crdWithoutWebhook)
+		orphaned.Name = "retired.lib.projectsveltos.io"
+		orphaned.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+
+		report := diagnose(nil, append(webhookObjects(), establishedCRD(crdWithWebhookMultipleVersions),
+			gadgets, orphaned)...)
+		Expect(findings(report, deploy.CheckDrift)).To(ConsistOf(
+			HaveField("CRD", "gadgets.lib.projectsveltos.io")))
+		Expect(findings(report, deploy.CheckOrphaned)).To(ConsistOf(
+			HaveField("CRD", "retired.lib.projectsveltos.io")))
+	})
+
+	It("does not run skipped checks", func() {
+		gadgets := establishedCRD(crdWithoutWebhook)
+		gadgets.Spec.Names.ShortNames = []string{"gd"}
+
+		report := diagnose([]deploy.DoctorCheck{deploy.CheckDrift, deploy.CheckConversionWebhook},
+			establishedCRD(crdWithWebhookMultipleVersions), gadgets)
+		Expect(report.Checks).ToNot(ContainElements(deploy.CheckDrift, deploy.CheckConversionWebhook))
+		Expect(report.Findings).To(BeEmpty())
+	})
+
+	It("rejects unknown checks and severities", func() {
+		checks, err := deploy.ParseDoctorChecks([]string{"drift", "orphaned"})
+		Expect(err).To(BeNil())
+		Expect(checks).To(Equal([]deploy.DoctorCheck{deploy.CheckDrift, deploy.CheckOrphaned}))
+
+		_, err = deploy.ParseDoctorChecks([]string{"dns"})
+		Expect(err).To(MatchError(ContainSubstring(`unknown doctor check "dns"`)))
+
+		_, err = deploy.ParseSeverity("info")
+		Expect(err).To(HaveOccurred())
+	})
+})
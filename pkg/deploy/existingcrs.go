@@ -0,0 +1,177 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultCheckExistingCRsLimit is the default maximum number of objects
+	// checked per CRD version
+	DefaultCheckExistingCRsLimit = 500
+
+	// incompatibleExamples is the maximum number of object names reported per
+	// CRD version failing the new schema
+	incompatibleExamples = 5
+)
+
+// IncompatibleCRs reports the existing objects of a CRD version which are not
+// valid against the schema about to be applied
+type IncompatibleCRs struct {
+	// Version is the CRD version the objects were read and validated at
+	Version string `json:"version"`
+
+	// Checked is the number of objects validated
+	Checked int `json:"checked"`
+
+	// Failed is the number of objects not valid against the new schema
+	Failed int `json:"failed"`
+
+	// Examples contains the names (namespace/name for namespaced objects) of
+	// some of the objects failing validation, with the first error found
+	Examples []string `json:"examples,omitempty"`
+}
+
+// checkExistingCRs validates, before live is updated to u, the existing
+// objects of every served version whose schema changes against the new
+// schema. Validation is done client-side, so CEL rules are not evaluated.
+// Objects failing it are reported in result and logged as a warning, or make
+// the update fail with FailOnIncompatibleCRs.
+func checkExistingCRs(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	u *unstructured.Unstructured, opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	desired, err := toCustomResourceDefinition(u)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range desired.Spec.Versions {
+		version := &desired.Spec.Versions[i]
+		liveVersion := findCRDVersion(live, version.Name)
+		// objects can only be read at versions the live CRD serves
+		if liveVersion == nil || !liveVersion.Served || version.Schema == nil ||
+			reflect.DeepEqual(liveVersion.Schema, version.Schema) {
+
+			continue
+		}
+
+		finding, err := checkVersionCRs(ctx, c, desired, version, opts.CheckExistingCRsLimit)
+		if err != nil {
+			err = fmt.Errorf("failed to check existing objects of CRD %s version %s: %w",
+				desired.Name, version.Name, err)
+			if opts.FailOnIncompatibleCRs {
+				return err
+			}
+			logWarning(logger, "%v", err)
+			continue
+		}
+		if finding.Failed > 0 {
+			failed += finding.Failed
+			result.IncompatibleCRs = append(result.IncompatibleCRs, *finding)
+			logWarning(logger, "%d of %d existing objects of CRD %s version %s are not valid against the new schema: %s",
+				finding.Failed, finding.Checked, desired.Name, version.Name, strings.Join(finding.Examples, "; "))
+		}
+	}
+
+	if failed > 0 && opts.FailOnIncompatibleCRs {
+		return fmt.Errorf("%d existing objects are not valid against the new schema of CRD %s", failed, desired.Name)
+	}
+	return nil
+}
+
+// checkVersionCRs lists, up to limit (0 means no limit), the objects of crd at
+// version and validates them against the version schema
+func checkVersionCRs(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition,
+	version *apiextensionsv1.CustomResourceDefinitionVersion, limit int64) (*IncompatibleCRs, error) {
+
+	validator, err := newSchemaValidator(version.Schema.OpenAPIV3Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: version.Name,
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	var listOptions []client.ListOption
+	if limit > 0 {
+		listOptions = append(listOptions, client.Limit(limit))
+	}
+	if err := c.List(ctx, list, listOptions...); err != nil {
+		return nil, err
+	}
+
+	items := list.Items
+	if limit > 0 && int64(len(items)) > limit {
+		items = items[:limit]
+	}
+
+	finding := &IncompatibleCRs{Version: version.Name, Checked: len(items)}
+	for i := range items {
+		errs := validation.ValidateCustomResource(nil, items[i].UnstructuredContent(), validator)
+		if len(errs) == 0 {
+			continue
+		}
+		finding.Failed++
+		if len(finding.Examples) < incompatibleExamples {
+			finding.Examples = append(finding.Examples, fmt.Sprintf("%s: %v", objectName(&items[i]), errs[0]))
+		}
+	}
+	return finding, nil
+}
+
+// newSchemaValidator returns the validator the API server uses for schema
+func newSchemaValidator(schema *apiextensionsv1.JSONSchemaProps) (validation.SchemaValidator, error) {
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internal, nil); err != nil {
+		return nil, err
+	}
+	validator, _, err := validation.NewSchemaValidator(internal)
+	return validator, err
+}
+
+// findCRDVersion returns the version of crd named name, nil if there is none
+func findCRDVersion(crd *apiextensionsv1.CustomResourceDefinition, name string) *apiextensionsv1.CustomResourceDefinitionVersion {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == name {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+// objectName returns namespace/name for namespaced objects, name otherwise
+func objectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
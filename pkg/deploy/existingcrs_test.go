@@ -0,0 +1,166 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	gadgetCRD = "gadgets.lib.projectsveltos.io"
+
+	// crdWithStricterSchema is crdWithoutWebhook requiring spec.replicas to be at least 1
+	crdWithStricterSchema = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Gadget
+    listKind: GadgetList
+    plural: gadgets
+    singular: gadget
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - replicas
+            properties:
+              replicas:
+                type: integer
+                minimum: 1
+`
+)
+
+var _ = Describe("Existing CRs check", func() {
+	gadget := func(name string, spec map[string]interface{}) client.Object {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("lib.projectsveltos.io/v1beta1")
+		u.SetKind("Gadget")
+		u.SetNamespace("default")
+		u.SetName(name)
+		Expect(unstructured.SetNestedMap(u.Object, spec, "spec")).To(Succeed())
+		return u
+	}
+
+	newClient := func() client.Client {
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(parse(crdWithoutWebhook).Object, live)).To(Succeed())
+		live.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+		return newFakeClient(live,
+			gadget("valid", map[string]interface{}{"replicas": int64(3)}),
+			gadget("zero", map[string]interface{}{"replicas": int64(0)}),
+			gadget("missing", map[string]interface{}{}),
+		)
+	}
+
+	newOptions := func() *deploy.Options {
+		return &deploy.Options{
+			Bundle:                &bundle.Bundle{Content: []byte(crdWithStricterSchema)},
+			CheckExistingCRs:      true,
+			CheckExistingCRsLimit: deploy.DefaultCheckExistingCRsLimit,
+		}
+	}
+
+	It("reports existing objects not valid against the new schema and updates the CRD", func() {
+		c := newClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, newOptions(), logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, gadgetCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+		Expect(result.IncompatibleCRs).To(HaveLen(1))
+		Expect(result.IncompatibleCRs[0].Version).To(Equal("v1beta1"))
+		Expect(result.IncompatibleCRs[0].Checked).To(Equal(3))
+		Expect(result.IncompatibleCRs[0].Failed).To(Equal(2))
+		Expect(result.IncompatibleCRs[0].Examples).To(ConsistOf(
+			HavePrefix("default/missing: "), HavePrefix("default/zero: ")))
+	})
+
+	It("fails the update with FailOnIncompatibleCRs", func() {
+		c := newClient()
+		opts := newOptions()
+		opts.FailOnIncompatibleCRs = true
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+
+		result := findResult(report, gadgetCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: gadgetCRD}, current)).To(Succeed())
+		Expect(current.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties).To(BeEmpty())
+	})
+
+	It("validates at most the limit of objects", func() {
+		c := newClient()
+		opts := newOptions()
+		opts.CheckExistingCRsLimit = 1
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, gadgetCRD)
+		Expect(result).ToNot(BeNil())
+		// objects are listed in name order, the first one, missing, fails validation
+		Expect(result.IncompatibleCRs).To(HaveLen(1))
+		Expect(result.IncompatibleCRs[0].Checked).To(Equal(1))
+	})
+
+	It("does not list objects when the schema is unchanged", func() {
+		c := newClient()
+		opts := newOptions()
+		opts.Bundle = &bundle.Bundle{Content: []byte(crdWithoutWebhook)}
+		opts.FailOnIncompatibleCRs = true
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, gadgetCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.IncompatibleCRs).To(BeEmpty())
+	})
+
+	It("refuses a negative limit", func() {
+		opts := &deploy.Options{CheckExistingCRsLimit: -1}
+		Expect(opts.Validate()).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import "time"
+
+var (
+	ApplyMutations                  = applyMutations
+	ProcessCustomResourceDefinition = processCustomResourceDefinition
+	WaitForCRDsWithInterval         = waitForCRDs
+)
+
+var (
+	ChangedPaths   = changedPaths
+	PreserveFields = preserveFields
+)
+
+var (
+	ProtectionPolicy = protectionPolicy
+)
+
+var (
+	IsServerUnavailable = isServerUnavailable
+)
+
+// SetCircuitBreakerClock makes b read the time from now
+func SetCircuitBreakerClock(b *CircuitBreaker, now func() time.Time) {
+	b.now = now
+}
+
+const (
+	HistoryKey     = historyKey
+	HistoryMaxSize = historyMaxSize
+)
+
+// RBACDeclaredOptions returns the Options fields an RBAC requirement lists,
+// or declared as needing no permission
+func RBACDeclaredOptions() []string {
+	declared := append([]string{}, optionsWithoutRBAC...)
+	for i := range rbacRequirements {
+		declared = append(declared, rbacRequirements[i].options...)
+	}
+	return declared
+}
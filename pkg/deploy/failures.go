@@ -0,0 +1,84 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CRDError is the error a bundle CRD failed with
+type CRDError struct {
+	CRD string
+	Err error
+}
+
+func (e *CRDError) Error() string {
+	return fmt.Sprintf("%s: %v", e.CRD, e.Err)
+}
+
+func (e *CRDError) Unwrap() error {
+	return e.Err
+}
+
+// DeployError is returned when CRDs failed to be deployed or removed. It
+// lists every one of them, in processing order, and errors.Is and errors.As
+// look into all of their errors.
+type DeployError struct {
+	Failures []*CRDError
+}
+
+func (e *DeployError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i := range e.Failures {
+		failures[i] = e.Failures[i].Error()
+	}
+	return fmt.Sprintf("%d Sveltos CRDs failed: %s", len(e.Failures), strings.Join(failures, "; "))
+}
+
+func (e *DeployError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i := range e.Failures {
+		errs[i] = e.Failures[i]
+	}
+	return errs
+}
+
+// add records the failure of the CRD named name
+func (e *DeployError) add(name string, err error) {
+	e.Failures = append(e.Failures, &CRDError{CRD: name, Err: err})
+}
+
+// err returns e, or nil if it lists no failure
+func (e *DeployError) err() error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	return e
+}
+
+// joinTo returns err joined with e, unless e lists no failure
+func (e *DeployError) joinTo(err error) error {
+	if len(e.Failures) == 0 {
+		return err
+	}
+	if err == nil {
+		return e
+	}
+	return errors.Join(err, e)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	// strictDecodingError prefixes errors returned by the API server when a
+	// Strict field validation fails
+	strictDecodingError = "strict decoding error"
+)
+
+var (
+	// fieldValidationMinVersion is the first Kubernetes version where server side
+	// field validation is enabled by default
+	fieldValidationMinVersion = utilversion.MustParseGeneric("v1.25.0")
+)
+
+// validateFieldValidation verifies value is a supported field validation directive
+func validateFieldValidation(value string) error {
+	switch value {
+	case "", metav1.FieldValidationStrict, metav1.FieldValidationWarn, metav1.FieldValidationIgnore:
+		return nil
+	default:
+		return fmt.Errorf("invalid field validation %q: expected %s, %s or %s", value,
+			metav1.FieldValidationStrict, metav1.FieldValidationWarn, metav1.FieldValidationIgnore)
+	}
+}
+
+// fieldValidation returns the field validation directive to send with writes
+func fieldValidation(opts *Options) (string, error) {
+	if opts.FieldValidation != "" {
+		return opts.FieldValidation, nil
+	}
+	below, err := isServerVersionBelow(opts.ServerVersion, fieldValidationMinVersion)
+	if err != nil {
+		return "", err
+	}
+	if below {
+		return metav1.FieldValidationWarn, nil
+	}
+	return metav1.FieldValidationStrict, nil
+}
+
+// wrapFieldValidationError adds the CRD name to strict field validation errors.
+// The API server message already lists the offending field paths.
+func wrapFieldValidationError(name string, err error) error {
+	if err == nil || !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), strictDecodingError) {
+		return err
+	}
+	return fmt.Errorf("CRD %s failed strict field validation: %w", name, err)
+}
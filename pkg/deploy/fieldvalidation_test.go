@@ -0,0 +1,88 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// newRecordingClient returns a fake client recording the field validation
+// directive of every create request. When createErr is set, creates fail with it.
+func newRecordingClient(recorded *[]string, createErr error) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			createOpts := &client.CreateOptions{}
+			createOpts.ApplyOptions(opts)
+			*recorded = append(*recorded, createOpts.FieldValidation)
+			if createErr != nil {
+				return createErr
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("Field validation", func() {
+	DescribeTable("sends the expected directive",
+		func(opts *deploy.Options, expected string) {
+			var recorded []string
+			c := newRecordingClient(&recorded, nil)
+
+			result := &deploy.CRDResult{}
+			Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithoutWebhook),
+				parse(crdWithoutWebhook), opts, result, logger)).To(Succeed())
+			Expect(result.Action).To(Equal(deploy.ActionCreated))
+			Expect(recorded).To(Equal([]string{expected}))
+		},
+		Entry("Strict by default", &deploy.Options{}, metav1.FieldValidationStrict),
+		Entry("Strict on recent servers", &deploy.Options{ServerVersion: "v1.30.2"}, metav1.FieldValidationStrict),
+		Entry("Warn on older servers", &deploy.Options{ServerVersion: "v1.24.9"}, metav1.FieldValidationWarn),
+		Entry("explicit value", &deploy.Options{ServerVersion: "v1.30.2", FieldValidation: metav1.FieldValidationIgnore},
+			metav1.FieldValidationIgnore),
+	)
+
+	It("reports the CRD name on strict validation errors", func() {
+		var recorded []string
+		strictErr := apierrors.NewBadRequest(`strict decoding error: unknown field "spec.versions[0].servd"`)
+		c := newRecordingClient(&recorded, strictErr)
+
+		u := parse(crdWithoutWebhook)
+		err := deploy.ProcessCustomResourceDefinition(context.TODO(), c, u, u, &deploy.Options{},
+			&deploy.CRDResult{}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("CRD %s failed strict field validation", u.GetName())))
+		Expect(err.Error()).To(ContainSubstring("spec.versions[0].servd"))
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	It("rejects unsupported values", func() {
+		opts := &deploy.Options{FieldValidation: "Lenient"}
+		Expect(opts.Validate()).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+// toCustomResourceDefinition converts an unstructured CRD to its typed representation
+func toCustomResourceDefinition(u *unstructured.Unstructured) (*apiextensionsv1.CustomResourceDefinition, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), crd); err != nil {
+		return nil, err
+	}
+	return crd, nil
+}
+
+// toUnstructuredMap converts a typed object to its unstructured representation
+func toUnstructuredMap(obj interface{}) (map[string]interface{}, error) {
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// specHash returns a digest of the CRD spec. The API server defaults are applied
+// to a copy of the spec first, so that an object read back from the cluster and
+// the same object coming from the bundle hash to the same value. The conversion
+// webhook caBundle is not part of the digest.
+func specHash(crd *apiextensionsv1.CustomResourceDefinition) (string, error) {
+	normalized := crd.DeepCopy()
+	apiextensionsv1.SetObjectDefaults_CustomResourceDefinition(normalized)
+	// caBundle is usually injected at runtime and never part of the bundle
+	if normalized.Spec.Conversion != nil && normalized.Spec.Conversion.Webhook != nil &&
+		normalized.Spec.Conversion.Webhook.ClientConfig != nil {
+
+		normalized.Spec.Conversion.Webhook.ClientConfig.CABundle = nil
+	}
+
+	data, err := json.Marshal(normalized.Spec)
+	if err != nil {
+		return "", err
+	}
+	return bundle.Digest(data), nil
+}
+
+// unstructuredSpecHash returns the specHash of an unstructured CRD
+func unstructuredSpecHash(u *unstructured.Unstructured) (string, error) {
+	crd, err := toCustomResourceDefinition(u)
+	if err != nil {
+		return "", err
+	}
+	return specHash(crd)
+}
+
+// isInSync returns true if the live CRD spec matches the desired one
+func isInSync(live *apiextensionsv1.CustomResourceDefinition, desired *unstructured.Unstructured) (bool, error) {
+	liveHash, err := specHash(live)
+	if err != nil {
+		return false, err
+	}
+	desiredHash, err := unstructuredSpecHash(desired)
+	if err != nil {
+		return false, err
+	}
+	return liveHash == desiredHash, nil
+}
+
+// isUpToDate returns true if updating the live CRD with the desired one would not
+// change anything crd-manager cares about: the spec and the labels/annotations
+// set on the desired object.
+func isUpToDate(live *apiextensionsv1.CustomResourceDefinition, desired *unstructured.Unstructured) (bool, error) {
+	if !containsAll(live.GetLabels(), desired.GetLabels()) ||
+		!containsAll(live.GetAnnotations(), desired.GetAnnotations()) {
+
+		return false, nil
+	}
+
+	// a stale audit annotation must be removed
+	if _, ok := desired.GetAnnotations()[auditAnnotation]; !ok {
+		if _, ok := live.GetAnnotations()[auditAnnotation]; ok {
+			return false, nil
+		}
+	}
+
+	// so must labels and annotations crd-manager injected and no longer sets
+	if labels, annotations := staleInjectedMetadata(live, desired); len(labels) > 0 || len(annotations) > 0 {
+		return false, nil
+	}
+
+	return isInSync(live, desired)
+}
+
+// containsAll returns true if current contains all keys of expected with the same values
+func containsAll(current, expected map[string]string) bool {
+	for k, v := range expected {
+		if currentValue, ok := current[k]; !ok || currentValue != v {
+			return false
+		}
+	}
+	return true
+}
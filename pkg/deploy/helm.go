@@ -0,0 +1,110 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy
+
+import (
+	"errors"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	helmManagedByValue             = "Helm"
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	helmResourcePolicyAnnotation   = "helm.sh/resource-policy"
+	helmResourcePolicyKeep         = "keep"
+)
+
+// HelmReleaseOptions makes crd-manager, typically run as a Helm hook, stamp
+// the Helm release metadata on the CRDs it applies, so that Helm operations
+// on the release recognize them
+type HelmReleaseOptions struct {
+	// Name of the Helm release
+	Name string
+
+	// Namespace of the Helm release
+	Namespace string
+
+	// ResourcePolicyKeep adds the helm.sh/resource-policy: keep annotation, so
+	// that uninstalling the release leaves the CRDs in place
+	ResourcePolicyKeep bool
+}
+
+func (o *HelmReleaseOptions) isSet() bool {
+	return o.Name != "" || o.Namespace != ""
+}
+
+func (o *HelmReleaseOptions) validate() error {
+	if !o.isSet() {
+		if o.ResourcePolicyKeep {
+			return errors.New("helm resource policy keep requires a Helm release name and namespace")
+		}
+		return nil
+	}
+	if o.Name == "" || o.Namespace == "" {
+		return errors.New("both Helm release name and namespace must be set")
+	}
+	return nil
+}
+
+// setHelmReleaseMetadata stamps the Helm release metadata on u. The
+// crd-manager ownership label is added as well: it is what tells the CRDs
+// carrying Helm markers crd-manager wrote apart from the ones Helm owns.
+func setHelmReleaseMetadata(u *unstructured.Unstructured, release *HelmReleaseOptions) {
+	if !release.isSet() {
+		return
+	}
+
+	setManagedBy(u)
+	lbls := u.GetLabels()
+	lbls[appManagedByLabel] = helmManagedByValue
+	u.SetLabels(lbls)
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[helmReleaseNameAnnotation] = release.Name
+	annotations[helmReleaseNamespaceAnnotation] = release.Namespace
+	if release.ResourcePolicyKeep {
+		annotations[helmResourcePolicyAnnotation] = helmResourcePolicyKeep
+	}
+	u.SetAnnotations(annotations)
+}
+
+// withoutOwnHelmMarkers returns the CRD ownership heuristics must look at. In
+// Helm release mode, the Helm markers of a CRD carrying the crd-manager
+// ownership label were written by crd-manager and are ignored.
+func withoutOwnHelmMarkers(live *apiextensionsv1.CustomResourceDefinition,
+	release *HelmReleaseOptions) *apiextensionsv1.CustomResourceDefinition {
+
+	if !release.isSet() || !isManagedByCRDManager(live) {
+		return live
+	}
+
+	crd := live.DeepCopy()
+	if crd.Labels[appManagedByLabel] == helmManagedByValue {
+		delete(crd.Labels, appManagedByLabel)
+	}
+	for _, key := range []string{helmReleaseNameAnnotation, helmReleaseNamespaceAnnotation,
+		helmResourcePolicyAnnotation} {
+
+		delete(crd.Annotations, key)
+	}
+	return crd
+}
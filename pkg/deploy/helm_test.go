@@ -0,0 +1,103 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Helm release mode", func() {
+	release := deploy.HelmReleaseOptions{Name: "sveltos", Namespace: "projectsveltos"}
+
+	It("stamps the Helm release metadata on applied CRDs", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{HelmRelease: deploy.HelmReleaseOptions{
+			Name: release.Name, Namespace: release.Namespace, ResourcePolicyKeep: true,
+		}}
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "Helm"))
+		Expect(current.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(current.Annotations).To(HaveKeyWithValue("meta.helm.sh/release-name", "sveltos"))
+		Expect(current.Annotations).To(HaveKeyWithValue("meta.helm.sh/release-namespace", "projectsveltos"))
+		Expect(current.Annotations).To(HaveKeyWithValue("helm.sh/resource-policy", "keep"))
+	})
+
+	It("does not skip the CRDs it stamped on the next run", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{HelmRelease: release}
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionSkippedHelm)).To(BeZero())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+	})
+
+	It("still skips CRDs owned by Helm", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{HelmRelease: release}, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionSkippedHelm))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"sc"}))
+	})
+
+	It("leaves the Helm metadata out when not set", func() {
+		c := newFakeClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Labels).ToNot(HaveKey("app.kubernetes.io/managed-by"))
+		Expect(current.Annotations).ToNot(HaveKey("meta.helm.sh/release-name"))
+	})
+
+	DescribeTable("refuses incomplete Helm release options",
+		func(helmRelease deploy.HelmReleaseOptions) {
+			opts := &deploy.Options{HelmRelease: helmRelease}
+			Expect(opts.Validate()).ToNot(Succeed())
+		},
+		Entry("name only", deploy.HelmReleaseOptions{Name: "sveltos"}),
+		Entry("namespace only", deploy.HelmReleaseOptions{Namespace: "projectsveltos"}),
+		Entry("resource policy without release", deploy.HelmReleaseOptions{ResourcePolicyKeep: true}),
+	)
+})
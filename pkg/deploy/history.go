@@ -0,0 +1,223 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// HistoryConfigMapName is the name of the ConfigMap the run history is
+	// kept in
+	HistoryConfigMapName = "crd-manager-history"
+
+	// DefaultHistoryRuns is the number of runs kept, by default, in the history
+	DefaultHistoryRuns = 10
+
+	// historyKey is the ConfigMap key holding the history
+	historyKey = "history.json"
+
+	// historyMaxSize bounds the history size, well below the 1MiB a ConfigMap
+	// can hold. The oldest runs are trimmed first.
+	historyMaxSize = 256 * 1024
+)
+
+// History keeps, in the HistoryConfigMapName ConfigMap, what the last runs did
+type History struct {
+	// Namespace of the history ConfigMap
+	Namespace string
+
+	// Runs is the number of runs kept. Defaults to DefaultHistoryRuns.
+	Runs int
+}
+
+// HistoryEntry describes what a run did
+type HistoryEntry struct {
+	// Time the run ended at
+	Time metav1.Time `json:"time"`
+
+	// Status is the overall outcome of the run
+	Status RunStatus `json:"status"`
+
+	// BundleVersion is the Sveltos version the bundle CRDs come from, when known
+	BundleVersion string `json:"bundleVersion"`
+
+	// BundleDigest is the sha256 digest of the CRD bundle applied
+	BundleDigest string `json:"bundleDigest"`
+
+	// CRDs is, per CRD, the action taken, obsolete and pruned CRDs included
+	CRDs map[string]Action `json:"crds,omitempty"`
+}
+
+func (h *History) runs() int {
+	if h.Runs <= 0 {
+		return DefaultHistoryRuns
+	}
+	return h.Runs
+}
+
+func (h *History) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: h.Namespace, Name: HistoryConfigMapName}
+}
+
+func (h *History) validate() error {
+	if h != nil && h.Namespace == "" {
+		return errors.New("invalid history: namespace is required")
+	}
+	return nil
+}
+
+// newHistoryEntry returns the history entry of the run report describes
+func newHistoryEntry(report *Report) HistoryEntry {
+	entry := HistoryEntry{
+		Time:          metav1.NewTime(time.Now().Truncate(time.Second)),
+		Status:        report.Status,
+		BundleVersion: report.BundleVersion,
+		BundleDigest:  report.BundleDigest,
+		CRDs:          map[string]Action{},
+	}
+	for i := range report.CRDs {
+		entry.CRDs[report.CRDs[i].Name] = report.CRDs[i].Action
+	}
+	for i := range report.Removed {
+		entry.CRDs[report.Removed[i].Name] = report.Removed[i].Action
+	}
+	return entry
+}
+
+// ReadHistory returns the runs kept in the history, newest first
+func ReadHistory(ctx context.Context, c client.Client, history *History) ([]HistoryEntry, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, history.key(), configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseHistory(configMap)
+}
+
+func parseHistory(configMap *corev1.ConfigMap) ([]HistoryEntry, error) {
+	data, ok := configMap.Data[historyKey]
+	if !ok {
+		return nil, nil
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("invalid history in ConfigMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+	return entries, nil
+}
+
+// recordHistory adds the run report describes to the history, updates the
+// quarantine, if any, and, when set, replaces the record of the applied
+// bundle with record, logging the changelog from the previous one.
+// Concurrent writers are handled by retrying on conflicts.
+func recordHistory(ctx context.Context, c client.Client, opts *Options, report *Report,
+	record *BundleRecord, logger logr.Logger) error {
+
+	history := opts.History
+	entry := newHistoryEntry(report)
+	var changelog *Changelog
+	retriable := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	err := retry.OnError(retry.DefaultRetry, retriable, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.Get(ctx, history.key(), configMap)
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return err
+		}
+
+		entries, err := parseHistory(configMap)
+		if err != nil {
+			// A corrupted history is replaced rather than blocking every run
+			logWarning(logger, "%v, discarding it", err)
+		}
+		data, err := marshalHistory(append([]HistoryEntry{entry}, entries...), history.runs())
+		if err != nil {
+			return err
+		}
+
+		if create {
+			configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: history.Namespace, Name: HistoryConfigMapName,
+				Labels: map[string]string{ManagedByLabel: ManagedByValue},
+			}}
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[historyKey] = data
+		if opts.quarantine != nil {
+			if err := updateQuarantine(configMap, opts.Quarantine, opts.quarantine, report, logger); err != nil {
+				return err
+			}
+		}
+		if record != nil {
+			if changelog, err = replaceBundleRecord(configMap, record); err != nil {
+				return err
+			}
+		}
+		if create {
+			return c.Create(ctx, configMap)
+		}
+		return c.Update(ctx, configMap)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record run history in ConfigMap %s: %w", history.key(), err)
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("run recorded in history ConfigMap %s", history.key()))
+	if changelog != nil {
+		logChangelog(changelog, logger)
+	}
+	return nil
+}
+
+// marshalHistory keeps, of entries, the runs newest ones fitting in
+// historyMaxSize. The newest entry is always kept.
+func marshalHistory(entries []HistoryEntry, runs int) (string, error) {
+	if len(entries) > runs {
+		entries = entries[:runs]
+	}
+	for {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return "", err
+		}
+		if len(data) <= historyMaxSize || len(entries) == 1 {
+			return string(data), nil
+		}
+		entries = entries[:len(entries)-1]
+	}
+}
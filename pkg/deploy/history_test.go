@@ -0,0 +1,140 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const historyNamespace = "projectsveltos"
+
+func historyConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: historyNamespace, Name: deploy.HistoryConfigMapName},
+		Data:       map[string]string{deploy.HistoryKey: data},
+	}
+}
+
+func readHistory(c client.Client) []deploy.HistoryEntry {
+	entries, err := deploy.ReadHistory(context.TODO(), c, &deploy.History{Namespace: historyNamespace})
+	Expect(err).To(BeNil())
+	return entries
+}
+
+var _ = Describe("History", func() {
+	It("records per CRD actions of the last runs, newest first", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{History: &deploy.History{Namespace: historyNamespace, Runs: 2}}
+
+		for range 3 {
+			_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+			Expect(err).To(BeNil())
+		}
+
+		entries := readHistory(c)
+		Expect(entries).To(HaveLen(2))
+		for i := range entries {
+			Expect(entries[i].Status).To(Equal(deploy.RunStatusSuccess))
+			Expect(entries[i].BundleDigest).ToNot(BeEmpty())
+			Expect(entries[i].CRDs).To(HaveKeyWithValue(sveltosClusterCRD, deploy.ActionUnchanged))
+		}
+		Expect(entries[0].Time.Before(&entries[1].Time)).To(BeFalse())
+	})
+
+	It("retries conflicts with concurrent writers", func() {
+		conflicts := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(historyConfigMap("[]")).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object,
+					opts ...client.UpdateOption) error {
+
+					if _, ok := obj.(*corev1.ConfigMap); ok && conflicts < 2 {
+						conflicts++
+						return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(), nil)
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()
+
+		_, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{History: &deploy.History{Namespace: historyNamespace}}, logger)
+		Expect(err).To(BeNil())
+		Expect(conflicts).To(Equal(2))
+		Expect(readHistory(c)).To(HaveLen(1))
+	})
+
+	It("trims the oldest runs to stay under the size limit", func() {
+		crds := map[string]deploy.Action{}
+		for i := range 200 {
+			crds[fmt.Sprintf("%s-%03d.projectsveltos.io", strings.Repeat("x", 40), i)] = deploy.ActionUpdated
+		}
+		old := make([]deploy.HistoryEntry, 100)
+		for i := range old {
+			old[i] = deploy.HistoryEntry{Status: deploy.RunStatusSuccess, CRDs: crds}
+		}
+		data, err := json.Marshal(old)
+		Expect(err).To(BeNil())
+		Expect(len(data)).To(BeNumerically(">", deploy.HistoryMaxSize))
+
+		c := newFakeClient(historyConfigMap(string(data)))
+		_, err = deploy.Deploy(context.TODO(), c,
+			&deploy.Options{History: &deploy.History{Namespace: historyNamespace, Runs: 1000}}, logger)
+		Expect(err).To(BeNil())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: historyNamespace, Name: deploy.HistoryConfigMapName},
+			configMap)).To(Succeed())
+		Expect(len(configMap.Data[deploy.HistoryKey])).To(BeNumerically("<=", deploy.HistoryMaxSize))
+
+		entries := readHistory(c)
+		Expect(len(entries)).To(BeNumerically("<", 100))
+		Expect(entries[0].CRDs).To(HaveKey(sveltosClusterCRD))
+	})
+
+	It("replaces a corrupted history", func() {
+		c := newFakeClient(historyConfigMap("not json"))
+		_, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{History: &deploy.History{Namespace: historyNamespace}}, logger)
+		Expect(err).To(BeNil())
+		Expect(readHistory(c)).To(HaveLen(1))
+	})
+
+	It("does not record observe-only runs", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ObserveOnly: true, History: &deploy.History{Namespace: historyNamespace}}, logger)
+		Expect(err).To(BeNil())
+		Expect(readHistory(c)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,72 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// setLastApplied records, with SetLastApplied, u in the annotation kubectl
+// apply and kubectl diff compare against. Like setAppliedBy it is set right
+// before the write. When the annotations would exceed the API server limit,
+// the annotation is left out with a warning instead of failing the write.
+func setLastApplied(u *unstructured.Unstructured, opts *Options, logger logr.Logger) error {
+	if !opts.SetLastApplied {
+		return nil
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+
+	applied := withoutServerFields(u)
+	applied.SetAnnotations(annotations)
+
+	data, err := json.Marshal(applied.Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s annotation: %w", corev1.LastAppliedConfigAnnotation, err)
+	}
+
+	// kubectl terminates the annotation value with a new line
+	annotations[corev1.LastAppliedConfigAnnotation] = string(data) + "\n"
+	if err := apimachineryvalidation.ValidateAnnotationsSize(annotations); err != nil {
+		logWarning(logger, "CRD %s is too large for the %s annotation (%v), not setting it",
+			u.GetName(), corev1.LastAppliedConfigAnnotation, err)
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+	}
+	u.SetAnnotations(annotations)
+	return nil
+}
+
+// withoutServerFields returns a copy of u without the status and the metadata
+// fields the API server sets
+func withoutServerFields(u *unstructured.Unstructured) *unstructured.Unstructured {
+	stripped := u.DeepCopy()
+	for _, field := range []string{"resourceVersion", "uid", "generation", "creationTimestamp", "managedFields"} {
+		unstructured.RemoveNestedField(stripped.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(stripped.Object, "status")
+	return stripped
+}
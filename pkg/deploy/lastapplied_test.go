@@ -0,0 +1,103 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Last applied configuration", func() {
+	const name = "clusterprofiles.config.projectsveltos.io"
+
+	lastApplied := func(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceDefinition {
+		value, ok := crd.Annotations[corev1.LastAppliedConfigAnnotation]
+		Expect(ok).To(BeTrue())
+		applied := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal([]byte(value), applied)).To(Succeed())
+		return applied
+	}
+
+	It("is not set by default", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{}, logger)
+		Expect(err).To(BeNil())
+		Expect(getCRD(c, name).Annotations).ToNot(HaveKey(corev1.LastAppliedConfigAnnotation))
+	})
+
+	It("records the applied CRD on create and update", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{SetLastApplied: true}, logger)
+		Expect(err).To(BeNil())
+
+		applied := lastApplied(getCRD(c, name))
+		Expect(applied.Name).To(Equal(name))
+		Expect(applied.ResourceVersion).To(BeEmpty())
+		Expect(applied.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(applied.Annotations).ToNot(HaveKey(corev1.LastAppliedConfigAnnotation))
+		Expect(applied.Spec).To(Equal(getCRD(c, name).Spec))
+
+		opts := &deploy.Options{SetLastApplied: true, Category: "sveltos"}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionUpdated))
+		Expect(lastApplied(getCRD(c, name)).Spec.Names.Categories).To(ContainElement("sveltos"))
+
+		// the annotation alone does not make CRDs outdated
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+	})
+
+	It("is kept in sync with the patch apply strategy, and removed once disabled", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{SetLastApplied: true, ApplyStrategy: deploy.ApplyStrategyPatch}
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		opts.Category = "sveltos"
+		_, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(lastApplied(getCRD(c, name)).Spec.Names.Categories).To(ContainElement("sveltos"))
+
+		opts = &deploy.Options{ApplyStrategy: deploy.ApplyStrategyPatch}
+		_, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(getCRD(c, name).Annotations).ToNot(HaveKey(corev1.LastAppliedConfigAnnotation))
+	})
+
+	It("is left out of CRDs too large for it", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{
+			SetLastApplied: true,
+			Annotations:    map[string]string{"example.com/large": strings.Repeat("x", 200*1024)},
+		}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionCreated))
+		Expect(getCRD(c, name).Annotations).ToNot(HaveKey(corev1.LastAppliedConfigAnnotation))
+	})
+})
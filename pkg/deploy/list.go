@@ -0,0 +1,61 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy
+
+import (
+	"context"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// listPageSize is the maximum number of CRDs returned by each page of
+	// the List calls
+	listPageSize = 250
+)
+
+// forEachCRDMetadata lists, page by page, the metadata of the CRDs matching
+// lbls and calls fn for each of them. Only object metadata is transferred and
+// at most one page is held in memory, which matters on clusters with
+// thousands of CRDs.
+func forEachCRDMetadata(ctx context.Context, c client.Client, lbls client.MatchingLabels,
+	fn func(crd *metav1.PartialObjectMetadata) error) error {
+
+	gvk := apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+	continueToken := ""
+	for {
+		page := &metav1.PartialObjectMetadataList{}
+		page.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinitionList"))
+		if err := c.List(ctx, page, lbls, client.Limit(listPageSize), client.Continue(continueToken)); err != nil {
+			return err
+		}
+
+		for i := range page.Items {
+			crd := &page.Items[i]
+			crd.SetGroupVersionKind(gvk)
+			if err := fn(crd); err != nil {
+				return err
+			}
+		}
+
+		continueToken = page.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// testPageSize is the number of CRDs per page served by newPaginatingClient,
+// regardless of the requested limit
+const testPageSize = 2
+
+// newPaginatingClient returns a fake client serving CRD metadata lists in
+// pages of testPageSize, the continue token being the name of the last CRD returned.
+// The options of every such List call are appended to calls. Full CRD lists
+// are not paginated. failPage, when positive, is the page whose List fails.
+func newPaginatingClient(calls *[]*client.ListOptions, failPage int, initObjects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			page, ok := list.(*metav1.PartialObjectMetadataList)
+			if !ok {
+				return c.List(ctx, list, opts...)
+			}
+
+			listOpts := &client.ListOptions{}
+			listOpts.ApplyOptions(opts)
+			*calls = append(*calls, listOpts)
+			if len(*calls) == failPage {
+				return errors.New("connection reset")
+			}
+
+			if err := c.List(ctx, page, &client.ListOptions{LabelSelector: listOpts.LabelSelector}); err != nil {
+				return err
+			}
+			sort.Slice(page.Items, func(i, j int) bool { return page.Items[i].Name < page.Items[j].Name })
+
+			// like the API server, the page starts after the last key returned,
+			// so that deleting listed objects does not shift the next page
+			offset := sort.Search(len(page.Items), func(i int) bool { return page.Items[i].Name > listOpts.Continue })
+			end := min(offset+testPageSize, len(page.Items))
+			if end < len(page.Items) {
+				page.SetContinue(page.Items[end-1].Name)
+			}
+			page.Items = page.Items[offset:end]
+			return nil
+		},
+	}).Build()
+}
+
+// labelledCRDs returns count CRDs, not part of the bundle, carrying lbls
+func labelledCRDs(count int, lbls map[string]string) []client.Object {
+	objs := make([]client.Object, count)
+	for i := range objs {
+		objs[i] = &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("leftover%d.lib.projectsveltos.io", i), Labels: lbls},
+		}
+	}
+	return objs
+}
+
+var _ = Describe("Paginated CRD listing", func() {
+	It("prunes the ApplySet members of every page", func() {
+		opts := applySetOptions(subsetBundle(1))
+		members := labelledCRDs(5, map[string]string{deploy.ApplySetPartOfLabel: opts.ApplySet.ID()})
+		unrelated := labelledCRDs(1, nil)[0]
+		unrelated.SetName("unrelated.example.com")
+		var calls []*client.ListOptions
+		c := newPaginatingClient(&calls, 0, append(members, unrelated)...)
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionPruned)).To(Equal(len(members)))
+
+		// the bundle CRD and the 5 members to prune, in pages of 2
+		Expect(calls).To(HaveLen(3))
+		for i, call := range calls {
+			Expect(call.Limit).To(BeNumerically(">", 0))
+			Expect(call.LabelSelector.String()).To(Equal(deploy.ApplySetPartOfLabel + "=" + opts.ApplySet.ID()))
+			Expect(call.Continue == "").To(Equal(i == 0))
+		}
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(HaveLen(2))
+	})
+
+	It("reports, in observe-only mode, the extra managed CRDs of every page", func() {
+		extra := labelledCRDs(3, map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue})
+		var calls []*client.ListOptions
+		c := newPaginatingClient(&calls, 0, extra...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.ExtraCRDs).To(ConsistOf(extra[0].GetName(), extra[1].GetName(), extra[2].GetName()))
+		Expect(calls).To(HaveLen(2))
+		for _, call := range calls {
+			Expect(call.LabelSelector.String()).To(Equal(deploy.ManagedByLabel + "=" + deploy.ManagedByValue))
+		}
+	})
+
+	It("fails when a page cannot be listed", func() {
+		extra := labelledCRDs(3, map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue})
+		var calls []*client.ListOptions
+		c := newPaginatingClient(&calls, 2, extra...)
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).ToNot(BeNil())
+	})
+})
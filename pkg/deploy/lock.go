@@ -0,0 +1,284 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultLockWait is how long, by default, a run waits for the Lock
+	// another run holds
+	DefaultLockWait = 5 * time.Minute
+
+	// DefaultLockDuration is how long, by default, a Lock stays held without
+	// being renewed
+	DefaultLockDuration = time.Minute
+
+	// LockHolderPodAnnotation records, on the Lease, the namespace/name of
+	// the pod holding the lock. A lock whose pod is gone is stale.
+	LockHolderPodAnnotation = "projectsveltos.io/holder-pod"
+
+	// lockRetryInterval is the interval between two attempts to acquire a Lock
+	lockRetryInterval = time.Second
+)
+
+// Lock is a coordination.k8s.io Lease making sure a single run deploys the
+// CRDs at a time
+type Lock struct {
+	// Name and Namespace of the Lease
+	Name      string
+	Namespace string
+
+	// Identity of the run, recorded as the Lease holder
+	Identity string
+
+	// HolderPod, in the namespace/name format, is the pod the run executes
+	// in, if any. Runs take the lock over when the pod of its holder is gone.
+	HolderPod string
+
+	// Wait is how long a run waits for the lock held by another run.
+	// Defaults to DefaultLockWait.
+	Wait time.Duration
+
+	// Duration is how long the lock stays held without being renewed. Runs
+	// take expired locks over. Defaults to DefaultLockDuration.
+	Duration time.Duration
+}
+
+// LockError is returned when the Lock could not be acquired in time
+type LockError struct {
+	// Lock is the namespace/name of the Lease
+	Lock string
+
+	// Holder is the identity of the run holding the lock
+	Holder string
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("lock %s is held by %s", e.Lock, e.Holder)
+}
+
+// validate returns an error if l, when set, is not consistent
+func (l *Lock) validate() error {
+	if l != nil && (l.Name == "" || l.Namespace == "" || l.Identity == "") {
+		return errors.New("invalid lock: name, namespace and identity are required")
+	}
+	return nil
+}
+
+func (l *Lock) String() string {
+	return l.Namespace + "/" + l.Name
+}
+
+func (l *Lock) duration() time.Duration {
+	if l.Duration <= 0 {
+		return DefaultLockDuration
+	}
+	return l.Duration
+}
+
+// acquire waits, up to Wait, to hold the lock. The returned function renews
+// the lock until it is called, then releases it.
+func (l *Lock) acquire(ctx context.Context, c client.Client, logger logr.Logger) (func(), error) {
+	timeout := l.Wait
+	if timeout <= 0 {
+		timeout = DefaultLockWait
+	}
+
+	holder := ""
+	err := wait.PollUntilContextTimeout(ctx, lockRetryInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		holder, err = l.tryAcquire(ctx, c, logger)
+		if err != nil {
+			// Conflicts with other runs and transient API errors are retried
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to acquire lock %s: %v", l, err))
+			return false, nil
+		}
+		if holder != l.Identity {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("lock %s is held by %s, waiting", l, holder))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) && holder != "" && holder != l.Identity {
+			return nil, &LockError{Lock: l.String(), Holder: holder}
+		}
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", l, err)
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("acquired lock %s as %s", l, l.Identity))
+
+	renewCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.renew(renewCtx, c, logger)
+	}()
+	return func() {
+		cancel()
+		<-done
+		l.release(context.WithoutCancel(ctx), c, logger)
+	}, nil
+}
+
+// tryAcquire takes the lock if free, expired, stale or already held by this
+// run, and returns its holder
+func (l *Lock) tryAcquire(ctx context.Context, c client.Client, logger logr.Logger) (string, error) {
+	lease := &coordinationv1.Lease{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: l.Namespace, Name: l.Name}, lease)
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: l.Namespace, Name: l.Name}}
+		l.hold(lease)
+		return l.Identity, c.Create(ctx, lease)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	free, err := l.isFree(ctx, c, lease, logger)
+	if err != nil {
+		return "", err
+	}
+	if !free {
+		return holder, nil
+	}
+	l.hold(lease)
+	return l.Identity, c.Update(ctx, lease)
+}
+
+// isFree returns true if lease has no holder, is held by this run, is
+// expired or is held by a pod which is gone
+func (l *Lock) isFree(ctx context.Context, c client.Client, lease *coordinationv1.Lease,
+	logger logr.Logger) (bool, error) {
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	if holder == "" || holder == l.Identity {
+		return true, nil
+	}
+
+	if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+		expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if time.Now().After(expiry) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("lock %s held by %s expired at %s, taking it over",
+				l, holder, expiry.UTC().Format(time.RFC3339)))
+			return true, nil
+		}
+	}
+
+	holderPod := lease.Annotations[LockHolderPodAnnotation]
+	if validateNamespacedName(holderPod) != nil {
+		return false, nil
+	}
+	namespace, name, _ := strings.Cut(holderPod, "/")
+	pod := &corev1.Pod{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod)
+	if apierrors.IsNotFound(err) || (err == nil && isPodFinished(pod)) {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("lock %s held by %s is stale, pod %s is gone, taking it over",
+			l, holder, holderPod))
+		return true, nil
+	}
+	return false, err
+}
+
+func isPodFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// hold makes this run the holder of lease
+func (l *Lock) hold(lease *coordinationv1.Lease) {
+	now := metav1.NewMicroTime(time.Now())
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != l.Identity {
+		lease.Spec.AcquireTime = &now
+		lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	}
+	lease.Spec.HolderIdentity = ptr.To(l.Identity)
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(l.duration().Seconds()))
+
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	if l.HolderPod != "" {
+		lease.Annotations[LockHolderPodAnnotation] = l.HolderPod
+	} else {
+		delete(lease.Annotations, LockHolderPodAnnotation)
+	}
+}
+
+// renew renews the lock, until ctx is done, well before it expires
+func (l *Lock) renew(ctx context.Context, c client.Client, logger logr.Logger) {
+	ticker := time.NewTicker(l.duration() / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lease := &coordinationv1.Lease{}
+			err := c.Get(ctx, types.NamespacedName{Namespace: l.Namespace, Name: l.Name}, lease)
+			if err == nil {
+				if ptr.Deref(lease.Spec.HolderIdentity, "") != l.Identity {
+					logWarning(logger, "lock %s has been taken over by %s", l, ptr.Deref(lease.Spec.HolderIdentity, ""))
+					return
+				}
+				lease.Spec.RenewTime = ptr.To(metav1.NewMicroTime(time.Now()))
+				err = c.Update(ctx, lease)
+			}
+			if err != nil && !errors.Is(err, context.Canceled) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to renew lock %s: %v", l, err))
+			}
+		}
+	}
+}
+
+// release gives the lock up, if still held by this run
+func (l *Lock) release(ctx context.Context, c client.Client, logger logr.Logger) {
+	lease := &coordinationv1.Lease{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: l.Namespace, Name: l.Name}, lease)
+	if err == nil {
+		if ptr.Deref(lease.Spec.HolderIdentity, "") != l.Identity {
+			return
+		}
+		lease.Spec.HolderIdentity = nil
+		lease.Spec.RenewTime = nil
+		delete(lease.Annotations, LockHolderPodAnnotation)
+		err = c.Update(ctx, lease)
+	}
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to release lock %s: %v", l, err))
+		return
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("released lock %s", l))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	lockName      = "crd-manager"
+	lockNamespace = "projectsveltos"
+)
+
+// heldLease returns the lock Lease, held by holder and renewed at renewTime
+func heldLease(holder string, renewTime time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: lockNamespace, Name: lockName},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(holder),
+			RenewTime:            ptr.To(metav1.NewMicroTime(renewTime)),
+			LeaseDurationSeconds: ptr.To(int32(60)),
+		},
+	}
+}
+
+func getLease(c client.Client) *coordinationv1.Lease {
+	lease := &coordinationv1.Lease{}
+	Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: lockNamespace, Name: lockName}, lease)).To(Succeed())
+	return lease
+}
+
+func lockOptions() *deploy.Options {
+	return &deploy.Options{Lock: &deploy.Lock{
+		Name: lockName, Namespace: lockNamespace, Identity: "run-b", Wait: 100 * time.Millisecond,
+	}}
+}
+
+// expectLocked verifies the run failed, without writing any CRD, since the
+// lock is held by holder
+func expectLocked(c client.Client, report *deploy.Report, err error, holder string) {
+	var lockErr *deploy.LockError
+	Expect(errors.As(err, &lockErr)).To(BeTrue())
+	Expect(lockErr.Holder).To(Equal(holder))
+	Expect(report.Status).To(Equal(deploy.RunStatusFailed))
+
+	list := &apiextensionsv1.CustomResourceDefinitionList{}
+	Expect(c.List(context.TODO(), list)).To(Succeed())
+	Expect(list.Items).To(BeEmpty())
+}
+
+var _ = Describe("Lock", func() {
+	It("is acquired while the CRDs are written and released afterwards", func() {
+		// Records the Lease holder while the CRDs are written
+		var holder string
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*coordinationv1.Lease); !ok {
+					lease := &coordinationv1.Lease{}
+					Expect(c.Get(ctx, types.NamespacedName{Namespace: lockNamespace, Name: lockName}, lease)).To(Succeed())
+					holder = *lease.Spec.HolderIdentity
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+		Expect(holder).To(Equal("run-b"))
+
+		lease := getLease(c)
+		Expect(lease.Spec.HolderIdentity).To(BeNil())
+		Expect(*lease.Spec.LeaseTransitions).To(Equal(int32(1)))
+	})
+
+	It("fails with a LockError when another run holds it", func() {
+		c := newFakeClient(heldLease("run-a", time.Now()))
+
+		report, err := deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		expectLocked(c, report, err, "run-a")
+		Expect(*getLease(c).Spec.HolderIdentity).To(Equal("run-a"))
+	})
+
+	It("takes expired locks over", func() {
+		c := newFakeClient(heldLease("run-a", time.Now().Add(-2*time.Minute)))
+
+		_, err := deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		Expect(err).To(BeNil())
+		Expect(getLease(c).Spec.HolderIdentity).To(BeNil())
+	})
+
+	It("takes locks whose holder pod is gone over", func() {
+		lease := heldLease("run-a", time.Now())
+		lease.Annotations = map[string]string{deploy.LockHolderPodAnnotation: "projectsveltos/run-a"}
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "projectsveltos", Name: "run-a"}}
+		c := newFakeClient(lease, pod)
+		report, err := deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		expectLocked(c, report, err, "run-a")
+
+		pod.Status.Phase = corev1.PodSucceeded
+		Expect(c.Status().Update(context.TODO(), pod)).To(Succeed())
+		_, err = deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		Expect(err).To(BeNil())
+
+		c = newFakeClient(lease)
+		_, err = deploy.Deploy(context.TODO(), c, lockOptions(), logger)
+		Expect(err).To(BeNil())
+	})
+
+	It("is not taken by observe-only runs", func() {
+		c := newFakeClient(heldLease("run-a", time.Now()))
+		opts := lockOptions()
+		opts.ObserveOnly = true
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// VersionMarker is the ConfigMap recording the bundle last fully applied, for
+// other components to gate on without parsing a Report. It is only advanced by
+// runs which processed every CRD successfully, each of them being in sync
+// with the bundle. Read it with crds.ReadVersionMarker.
+type VersionMarker struct {
+	// Namespace of the ConfigMap
+	Namespace string
+
+	// Name of the ConfigMap
+	Name string
+}
+
+// ParseVersionMarker parses a value in the namespace/name format
+func ParseVersionMarker(value string) (*VersionMarker, error) {
+	if err := validateNamespacedName(value); err != nil {
+		return nil, fmt.Errorf("invalid version marker %q: %w", value, err)
+	}
+	namespace, name, _ := strings.Cut(value, "/")
+	return &VersionMarker{Namespace: namespace, Name: name}, nil
+}
+
+func (m *VersionMarker) validate() error {
+	if m != nil && (m.Namespace == "" || m.Name == "") {
+		return fmt.Errorf("invalid version marker %s: namespace and name are required", m)
+	}
+	return nil
+}
+
+func (m *VersionMarker) String() string {
+	return m.Namespace + "/" + m.Name
+}
+
+func (m *VersionMarker) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+}
+
+// notInSync returns the CRDs of report which are not in sync with the bundle:
+// failed, deferred, quarantined, paused, pinned or drifted ones
+func notInSync(report *Report) []string {
+	var result []string
+	for i := range report.CRDs {
+		if report.CRDs[i].Drift != DriftStatusInSync {
+			result = append(result, report.CRDs[i].Name)
+		}
+	}
+	return result
+}
+
+// advanceVersionMarker records, in the version marker ConfigMap, the bundle
+// report describes the run of, unless some CRDs are not in sync with it
+func advanceVersionMarker(ctx context.Context, c client.Client, marker *VersionMarker, report *Report,
+	logger logr.Logger) error {
+
+	if names := notInSync(report); len(names) > 0 {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("version marker %s not advanced: Sveltos CRDs not in sync: %s",
+			marker, strings.Join(names, ", ")))
+		return nil
+	}
+
+	data := map[string]string{
+		crds.VersionMarkerVersionKey:     report.BundleVersion,
+		crds.VersionMarkerDigestKey:      report.BundleDigest,
+		crds.VersionMarkerCompletedAtKey: time.Now().UTC().Format(time.RFC3339),
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.Get(ctx, marker.key(), configMap)
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: marker.Namespace, Name: marker.Name,
+					Labels: map[string]string{ManagedByLabel: ManagedByValue},
+				},
+				Data: data,
+			}
+			return c.Create(ctx, configMap)
+		}
+		if err != nil {
+			return err
+		}
+		configMap.Data = data
+		return c.Update(ctx, configMap)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to advance version marker %s: %w", marker, err)
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("version marker %s advanced to bundle %s (%s)",
+		marker, report.BundleVersion, report.BundleDigest))
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("VersionMarker", func() {
+	marker := &deploy.VersionMarker{Namespace: "projectsveltos", Name: "crd-bundle"}
+	key := types.NamespacedName{Namespace: marker.Namespace, Name: marker.Name}
+
+	It("is advanced by runs applying every CRD", func() {
+		c := newFakeClient()
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{VersionMarker: marker}, logger)
+		Expect(err).To(BeNil())
+
+		applied, err := crds.ReadVersionMarker(context.TODO(), c, key)
+		Expect(err).To(BeNil())
+		Expect(applied).ToNot(BeNil())
+		Expect(applied.Version).To(Equal(report.BundleVersion))
+		Expect(applied.Digest).To(Equal(report.BundleDigest))
+		Expect(applied.CompletedAt).To(BeTemporally("~", time.Now(), time.Minute))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), key, configMap)).To(Succeed())
+		Expect(configMap.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+
+		// a run changing nothing advances it too
+		_, err = deploy.Deploy(context.TODO(), c, &deploy.Options{VersionMarker: marker}, logger)
+		Expect(err).To(BeNil())
+		again, err := crds.ReadVersionMarker(context.TODO(), c, key)
+		Expect(err).To(BeNil())
+		Expect(again.Digest).To(Equal(applied.Digest))
+	})
+
+	It("is not advanced when a CRD fails", func() {
+		c := newRejectingClient(sveltosClusterCRD)
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{VersionMarker: marker}, logger)
+		Expect(err).ToNot(BeNil())
+
+		Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, &corev1.ConfigMap{}))).To(BeTrue())
+	})
+
+	It("is not advanced by runs leaving CRDs out", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{
+			VersionMarker: marker,
+			Defer:         func(name string) bool { return name == sveltosClusterCRD },
+		}
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, &corev1.ConfigMap{}))).To(BeTrue())
+
+		opts.ObserveOnly = true
+		opts.Defer = nil
+		_, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(apierrors.IsNotFound(c.Get(context.TODO(), key, &corev1.ConfigMap{}))).To(BeTrue())
+	})
+
+	It("parses namespace/name values", func() {
+		parsed, err := deploy.ParseVersionMarker("projectsveltos/crd-bundle")
+		Expect(err).To(BeNil())
+		Expect(parsed).To(Equal(marker))
+
+		for _, value := range []string{"crd-bundle", "/crd-bundle", "projectsveltos/", "a/b/c"} {
+			_, err := deploy.ParseVersionMarker(value)
+			Expect(err).ToNot(BeNil(), value)
+		}
+	})
+})
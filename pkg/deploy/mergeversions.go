@@ -0,0 +1,83 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// mergeVersions adds to u the versions live defines but the bundle dropped,
+// unchanged but for storage: the bundle storage version stays the only one.
+// Versions defined by both keep the bundle definition; a schema differing
+// from the live one is reported.
+func mergeVersions(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	logger logr.Logger) error {
+
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("failed to parse versions: %w", err)
+	}
+
+	desired, err := toCustomResourceDefinition(u)
+	if err != nil {
+		return err
+	}
+	desiredVersions := map[string]*apiextensionsv1.CustomResourceDefinitionVersion{}
+	for _, version := range defaultedVersions(desired) {
+		desiredVersions[version.Name] = &version
+	}
+
+	for i, liveVersion := range defaultedVersions(live) {
+		desiredVersion, ok := desiredVersions[liveVersion.Name]
+		if ok {
+			if !reflect.DeepEqual(liveVersion.Schema, desiredVersion.Schema) {
+				logWarning(logger, "Sveltos CRD %s version %s schema differs from the live one, using the bundle schema",
+					u.GetName(), liveVersion.Name)
+			}
+			continue
+		}
+
+		retained, err := toUnstructuredMap(&live.Spec.Versions[i])
+		if err != nil {
+			return err
+		}
+		retained["storage"] = false
+		versions = append(versions, retained)
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s version %s is not in the bundle, retained (served: %t)",
+			u.GetName(), liveVersion.Name, liveVersion.Served))
+	}
+
+	if err := unstructured.SetNestedSlice(u.Object, versions, "spec", "versions"); err != nil {
+		return fmt.Errorf("failed to set versions: %w", err)
+	}
+	return nil
+}
+
+// defaultedVersions returns the versions of crd with the API server defaults
+// applied, so that live and bundle versions compare
+func defaultedVersions(crd *apiextensionsv1.CustomResourceDefinition) []apiextensionsv1.CustomResourceDefinitionVersion {
+	normalized := crd.DeepCopy()
+	apiextensionsv1.SetObjectDefaults_CustomResourceDefinition(normalized)
+	return normalized.Spec.Versions
+}
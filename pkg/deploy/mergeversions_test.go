@@ -0,0 +1,157 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const droppedVersion = "v1alpha0"
+
+// withDroppedVersion returns crd with an additional version, the bundle does
+// not define, as storage version
+func withDroppedVersion(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceDefinition {
+	dropped := *crd.Spec.Versions[0].DeepCopy()
+	dropped.Name = droppedVersion
+	dropped.Served = true
+	dropped.Storage = true
+	for i := range crd.Spec.Versions {
+		crd.Spec.Versions[i].Storage = false
+	}
+	crd.Spec.Versions = append(crd.Spec.Versions, dropped)
+	return crd
+}
+
+// storageVersions returns the names of the storage versions of crd
+func storageVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var result []string
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			result = append(result, version.Name)
+		}
+	}
+	return result
+}
+
+// findVersion returns the version of crd named name
+func findVersion(crd *apiextensionsv1.CustomResourceDefinition,
+	name string) *apiextensionsv1.CustomResourceDefinitionVersion {
+
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == name {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+var _ = Describe("MergeVersions", func() {
+	It("retains the versions the bundle dropped, keeping the bundle storage version the only one", func() {
+		var initObjects []client.Object
+		for _, u := range getBundleCRDs() {
+			initObjects = append(initObjects, withDroppedVersion(getBundleCRD(u.GetName())))
+		}
+		c := newFakeClient(initObjects...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{MergeVersions: true}, logger)
+		Expect(err).To(BeNil())
+
+		for _, result := range report.CRDs {
+			Expect(result.Action).To(Equal(deploy.ActionUpdated), result.Name)
+
+			current := getCRD(c, result.Name)
+			Expect(storageVersions(current)).To(Equal(storageVersions(getBundleCRD(result.Name))), result.Name)
+
+			retained := findVersion(current, droppedVersion)
+			Expect(retained).ToNot(BeNil(), result.Name)
+			Expect(retained.Served).To(BeTrue())
+			Expect(retained.Storage).To(BeFalse())
+		}
+
+		// Once merged, the CRDs are up to date
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{MergeVersions: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CRDs).To(HaveEach(HaveField("Action", deploy.ActionUnchanged)))
+	})
+
+	It("preserves the served state of retained versions", func() {
+		crd := withDroppedVersion(getBundleCRD(sveltosClusterCRD))
+		findVersion(crd, droppedVersion).Served = false
+		c := newFakeClient(crd)
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{MergeVersions: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findVersion(getCRD(c, sveltosClusterCRD), droppedVersion).Served).To(BeFalse())
+	})
+
+	It("removes the versions the bundle dropped by default", func() {
+		c := newFakeClient(withDroppedVersion(getBundleCRD(sveltosClusterCRD)))
+
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		current := getCRD(c, sveltosClusterCRD)
+		Expect(findVersion(current, droppedVersion)).To(BeNil())
+		Expect(storageVersions(current)).To(HaveLen(1))
+	})
+
+	It("prefers the bundle schema for versions defined by both", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Description = "live schema"
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{MergeVersions: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		current := getCRD(c, sveltosClusterCRD)
+		bundleCRD := getBundleCRD(sveltosClusterCRD)
+		Expect(current.Spec.Versions).To(HaveLen(len(bundleCRD.Spec.Versions)))
+		Expect(current.Spec.Versions[0].Schema.OpenAPIV3Schema.Description).To(
+			Equal(bundleCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Description))
+	})
+
+	It("does not report retained versions as drift in observe-only mode", func() {
+		c := newReadOnlyClient(withDroppedVersion(getBundleCRD(sveltosClusterCRD)))
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{MergeVersions: true, ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		// the storage version still differs from the bundle one
+		Expect(findResult(report, sveltosClusterCRD).Drift).To(Equal(deploy.DriftStatusDrifted))
+
+		merged := getBundleCRD(sveltosClusterCRD)
+		retained := *merged.Spec.Versions[0].DeepCopy()
+		retained.Name = droppedVersion
+		retained.Storage = false
+		merged.Spec.Versions = append(merged.Spec.Versions, retained)
+		c = newReadOnlyClient(merged)
+
+		report, err = deploy.Deploy(context.TODO(), c,
+			&deploy.Options{MergeVersions: true, ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Drift).To(Equal(deploy.DriftStatusInSync))
+	})
+})
@@ -0,0 +1,142 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// InjectedMetadataAnnotation lists, on each CRD, the labels and
+	// annotations crd-manager injected. Keys listed there and no longer
+	// configured are removed; keys set by other actors are never touched.
+	InjectedMetadataAnnotation = "projectsveltos.io/crd-manager-injected"
+)
+
+// injectedMetadata is the content of the InjectedMetadataAnnotation
+type injectedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// reservedMetadata are the keys crd-manager itself sets, which cannot be injected
+var reservedMetadata = []string{ManagedByLabel, ApplySetPartOfLabel, AppliedByAnnotation, InjectedMetadataAnnotation,
+	auditAnnotation}
+
+func validateMetadata(labels, annotations map[string]string) error {
+	for k, v := range labels {
+		if err := validateMetadataKey(k); err != nil {
+			return fmt.Errorf("invalid label %q: %w", k, err)
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid label %q value %q: %s", k, v, strings.Join(errs, ", "))
+		}
+	}
+	for k := range annotations {
+		if err := validateMetadataKey(k); err != nil {
+			return fmt.Errorf("invalid annotation %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func validateMetadataKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	if slices.Contains(reservedMetadata, key) {
+		return errors.New("key is reserved to crd-manager")
+	}
+	return nil
+}
+
+// injectMetadata adds labels and annotations to u, listing their keys in the
+// InjectedMetadataAnnotation
+func injectMetadata(u *unstructured.Unstructured, labels, annotations map[string]string) error {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+
+	injected := injectedMetadata{}
+	if len(labels) > 0 {
+		lbls := u.GetLabels()
+		if lbls == nil {
+			lbls = map[string]string{}
+		}
+		for k, v := range labels {
+			lbls[k] = v
+			injected.Labels = append(injected.Labels, k)
+		}
+		u.SetLabels(lbls)
+		slices.Sort(injected.Labels)
+	}
+
+	current := u.GetAnnotations()
+	if current == nil {
+		current = map[string]string{}
+	}
+	for k, v := range annotations {
+		current[k] = v
+		injected.Annotations = append(injected.Annotations, k)
+	}
+	slices.Sort(injected.Annotations)
+
+	data, err := json.Marshal(injected)
+	if err != nil {
+		return fmt.Errorf("failed to marshal injected metadata annotation: %w", err)
+	}
+	current[InjectedMetadataAnnotation] = string(data)
+	u.SetAnnotations(current)
+	return nil
+}
+
+// staleInjectedMetadata returns the labels and annotations of live which
+// crd-manager injected but desired no longer sets, the
+// InjectedMetadataAnnotation included
+func staleInjectedMetadata(live, desired metav1.Object) (labels, annotations []string) {
+	value, ok := live.GetAnnotations()[InjectedMetadataAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	// An annotation crd-manager cannot parse lists no key it owns
+	injected := injectedMetadata{}
+	_ = json.Unmarshal([]byte(value), &injected)
+
+	for _, k := range injected.Labels {
+		_, isLive := live.GetLabels()[k]
+		if _, isDesired := desired.GetLabels()[k]; isLive && !isDesired {
+			labels = append(labels, k)
+		}
+	}
+	injected.Annotations = append(injected.Annotations, InjectedMetadataAnnotation)
+	for _, k := range injected.Annotations {
+		_, isLive := live.GetAnnotations()[k]
+		if _, isDesired := desired.GetAnnotations()[k]; isLive && !isDesired {
+			annotations = append(annotations, k)
+		}
+	}
+	return labels, annotations
+}
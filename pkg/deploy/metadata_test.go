@@ -0,0 +1,89 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Injected metadata", func() {
+	liveCRD := func(c client.Client) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, crd)).To(Succeed())
+		return crd
+	}
+
+	deployWith := func(c client.Client, labels, annotations map[string]string) {
+		opts := &deploy.Options{ApplyStrategy: deploy.ApplyStrategyPatch, Labels: labels, Annotations: annotations}
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+	}
+
+	It("stale injected labels are removed, labels set by other actors are kept", func() {
+		c := newFakeClient()
+
+		By("adding the env label")
+		deployWith(c, map[string]string{"env": "prod"}, nil)
+		crd := liveCRD(c)
+		Expect(crd.Labels).To(HaveKeyWithValue("env", "prod"))
+		Expect(crd.Annotations).To(HaveKeyWithValue(deploy.InjectedMetadataAnnotation, `{"labels":["env"]}`))
+
+		crd.Labels["team"] = "platform"
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+
+		By("renaming it to environment")
+		deployWith(c, map[string]string{"environment": "prod"}, nil)
+		crd = liveCRD(c)
+		Expect(crd.Labels).ToNot(HaveKey("env"))
+		Expect(crd.Labels).To(HaveKeyWithValue("environment", "prod"))
+		Expect(crd.Labels).To(HaveKeyWithValue("team", "platform"))
+
+		By("removing it")
+		deployWith(c, nil, nil)
+		crd = liveCRD(c)
+		Expect(crd.Labels).ToNot(HaveKey("environment"))
+		Expect(crd.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(crd.Annotations).ToNot(HaveKey(deploy.InjectedMetadataAnnotation))
+	})
+
+	It("stale injected annotations are removed", func() {
+		c := newFakeClient()
+
+		deployWith(c, nil, map[string]string{"example.com/owner": "platform"})
+		Expect(liveCRD(c).Annotations).To(HaveKeyWithValue("example.com/owner", "platform"))
+
+		deployWith(c, nil, nil)
+		Expect(liveCRD(c).Annotations).ToNot(HaveKey("example.com/owner"))
+	})
+
+	It("keys crd-manager reserves cannot be injected", func() {
+		opts := &deploy.Options{Labels: map[string]string{deploy.ManagedByLabel: "someone"}}
+		Expect(opts.Validate()).To(MatchError(ContainSubstring("reserved")))
+
+		opts = &deploy.Options{Annotations: map[string]string{"not a key": ""}}
+		Expect(opts.Validate()).To(MatchError(ContainSubstring("invalid annotation")))
+	})
+})
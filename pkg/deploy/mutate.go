@@ -0,0 +1,346 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// applyMutations modifies, according to opts, a CRD parsed from the bundle.
+// It is invoked before any decision about creating or updating the CRD, so that
+// every consumer of the desired object sees the same, fully mutated, content.
+// Every change to the spec, and the source of merged bundles the CRD is taken
+// from, is recorded in the CRD audit annotation.
+func applyMutations(u *unstructured.Unstructured, opts *Options, logger logr.Logger) error {
+	a := &audit{}
+
+	if opts.DisableConversionWebhooks {
+		if err := disableConversionWebhook(u, opts.AllowUnsafeConversionDowngrade, a, logger); err != nil {
+			return err
+		}
+	}
+
+	if err := rewriteConversionWebhook(u, &opts.ConversionWebhook, a, logger); err != nil {
+		return err
+	}
+
+	injectCAFrom(u, opts, logger)
+
+	strip, err := shouldStripCEL(opts)
+	if err != nil {
+		return err
+	}
+	if strip {
+		if err := stripCEL(u, a, logger); err != nil {
+			return err
+		}
+	}
+
+	if err := disableVersions(u, opts.DisabledVersions, a, logger); err != nil {
+		return err
+	}
+
+	if err := overrideStorageVersion(u, opts.StorageVersions, a, logger); err != nil {
+		return err
+	}
+
+	if err := addCategory(u, opts.Category, a, logger); err != nil {
+		return err
+	}
+
+	if err := addPrinterColumns(u, opts.PrinterColumns, a, logger); err != nil {
+		return err
+	}
+
+	if err := applyPatches(u, opts.Patches, a); err != nil {
+		return err
+	}
+
+	setHelmReleaseMetadata(u, &opts.HelmRelease)
+
+	if err := injectMetadata(u, opts.Labels, opts.Annotations); err != nil {
+		return err
+	}
+
+	recordSource(u, opts.getBundle(), a)
+	return a.setOn(u)
+}
+
+// usesWebhookConversion returns true if the CRD converts between versions using a webhook
+func usesWebhookConversion(u *unstructured.Unstructured) bool {
+	strategy, _, _ := unstructured.NestedString(u.Object, "spec", "conversion", "strategy")
+	return strategy == string(apiextensionsv1.WebhookConverter)
+}
+
+// rewriteConversionWebhook points the conversion webhook service of a CRD using
+// a Webhook conversion strategy to the configured namespace/name/port.
+// CRDs not using a conversion webhook are left untouched.
+func rewriteConversionWebhook(u *unstructured.Unstructured, webhook *ConversionWebhookOptions,
+	a *audit, logger logr.Logger) error {
+
+	if !webhook.isSet() || !usesWebhookConversion(u) {
+		return nil
+	}
+
+	servicePath := []string{"spec", "conversion", "webhook", "clientConfig", "service"}
+	urlPath := []string{"spec", "conversion", "webhook", "clientConfig", "url"}
+
+	service, found, err := unstructured.NestedMap(u.Object, servicePath...)
+	if err != nil {
+		return fmt.Errorf("failed to parse conversion webhook service: %w", err)
+	}
+	if !found {
+		// Webhook is reached via URL. Switching to a service requires both its namespace and name
+		if webhook.Namespace == "" || webhook.Service == "" {
+			return fmt.Errorf("conversion webhook is configured with an URL: both conversion webhook " +
+				"namespace and service are required to rewrite it")
+		}
+		unstructured.RemoveNestedField(u.Object, urlPath...)
+		service = map[string]interface{}{}
+	}
+
+	if webhook.Namespace != "" {
+		service["namespace"] = webhook.Namespace
+	}
+	if webhook.Service != "" {
+		service["name"] = webhook.Service
+	}
+	if webhook.Port != 0 {
+		service["port"] = int64(webhook.Port)
+	}
+
+	if err := unstructured.SetNestedMap(u.Object, service, servicePath...); err != nil {
+		return fmt.Errorf("failed to set conversion webhook service: %w", err)
+	}
+
+	a.addModification("conversion webhook service set to %s/%s:%v",
+		service["namespace"], service["name"], service["port"])
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s conversion webhook service set to %s/%s:%v",
+		u.GetName(), service["namespace"], service["name"], service["port"]))
+	return nil
+}
+
+// disableConversionWebhook replaces a Webhook conversion strategy with None.
+// With strategy None the API server only changes the apiVersion when converting,
+// which is unsafe when served versions have different schemas. In such a case,
+// unless allowUnsafe is set, an error is returned.
+func disableConversionWebhook(u *unstructured.Unstructured, allowUnsafe bool, a *audit, logger logr.Logger) error {
+	if !usesWebhookConversion(u) {
+		return nil
+	}
+
+	differ, err := servedSchemasDiffer(u)
+	if err != nil {
+		return err
+	}
+	if differ {
+		if !allowUnsafe {
+			return fmt.Errorf("CRD %s serves multiple versions with different schemas: "+
+				"refusing to disable its conversion webhook", u.GetName())
+		}
+		logWarning(logger, "Sveltos CRD %s serves multiple versions with different schemas: "+
+			"disabling conversion webhook anyway", u.GetName())
+	}
+
+	err = unstructured.SetNestedMap(u.Object,
+		map[string]interface{}{"strategy": string(apiextensionsv1.NoneConverter)},
+		"spec", "conversion")
+	if err != nil {
+		return fmt.Errorf("failed to set conversion strategy: %w", err)
+	}
+
+	a.addModification("conversion strategy downgraded from Webhook to None")
+	logWarning(logger, "Sveltos CRD %s conversion downgraded from Webhook to None: "+
+		"reading resources across versions relies on all versions having identical schemas", u.GetName())
+	return nil
+}
+
+// servedSchemasDiffer returns true if the CRD serves more than one version and
+// not all served versions share the same schema
+func servedSchemasDiffer(u *unstructured.Unstructured) (bool, error) {
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return false, fmt.Errorf("failed to parse versions: %w", err)
+	}
+
+	var reference interface{}
+	referenceSet := false
+	for i := range versions {
+		version, ok := versions[i].(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("failed to parse version %d", i)
+		}
+		if served, _ := version["served"].(bool); !served {
+			continue
+		}
+		if !referenceSet {
+			reference = version["schema"]
+			referenceSet = true
+			continue
+		}
+		if !reflect.DeepEqual(reference, version["schema"]) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// disableVersions stops serving the versions listed in disabled for this CRD.
+// Disabling the storage version, or all served versions, is refused.
+func disableVersions(u *unstructured.Unstructured, disabled []CRDVersion, a *audit, logger logr.Logger) error {
+	toDisable := versionsFor(u.GetName(), disabled)
+	if len(toDisable) == 0 {
+		return nil
+	}
+
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("failed to parse versions: %w", err)
+	}
+
+	for _, name := range toDisable {
+		version := findVersion(versions, name)
+		if version == nil {
+			return fmt.Errorf("cannot disable version %s: CRD %s has no such version", name, u.GetName())
+		}
+		if storage, _ := version["storage"].(bool); storage {
+			return fmt.Errorf("cannot disable version %s: it is the storage version of CRD %s", name, u.GetName())
+		}
+		version["served"] = false
+	}
+
+	served := 0
+	for i := range versions {
+		if version, ok := versions[i].(map[string]interface{}); ok {
+			if isServed, _ := version["served"].(bool); isServed {
+				served++
+			}
+		}
+	}
+	if served == 0 {
+		return fmt.Errorf("cannot disable versions %v: CRD %s would have no served version", toDisable, u.GetName())
+	}
+
+	if err := unstructured.SetNestedSlice(u.Object, versions, "spec", "versions"); err != nil {
+		return fmt.Errorf("failed to set versions: %w", err)
+	}
+
+	for _, name := range toDisable {
+		a.addModification("version %s not served", name)
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s version %s is not served", u.GetName(), name))
+	}
+	return nil
+}
+
+// overrideStorageVersion makes the version storageVersions names for this CRD
+// its only storage version. The version must exist and be served.
+func overrideStorageVersion(u *unstructured.Unstructured, storageVersions []CRDVersion, a *audit,
+	logger logr.Logger) error {
+
+	overrides := versionsFor(u.GetName(), storageVersions)
+	if len(overrides) == 0 {
+		return nil
+	}
+	name := overrides[0]
+
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("failed to parse versions: %w", err)
+	}
+
+	version := findVersion(versions, name)
+	if version == nil {
+		return fmt.Errorf("cannot make version %s the storage version: CRD %s has no such version", name, u.GetName())
+	}
+	if served, _ := version["served"].(bool); !served {
+		return fmt.Errorf("cannot make version %s the storage version: it is not served by CRD %s", name, u.GetName())
+	}
+	if storage, _ := version["storage"].(bool); storage {
+		return nil
+	}
+
+	for i := range versions {
+		if v, ok := versions[i].(map[string]interface{}); ok {
+			v["storage"] = v["name"] == name
+		}
+	}
+	if err := unstructured.SetNestedSlice(u.Object, versions, "spec", "versions"); err != nil {
+		return fmt.Errorf("failed to set versions: %w", err)
+	}
+
+	a.addModification("storage version set to %s", name)
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s storage version set to %s", u.GetName(), name))
+	return nil
+}
+
+// storageVersion returns the name of the storage version of u
+func storageVersion(u *unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+	for i := range versions {
+		if version, ok := versions[i].(map[string]interface{}); ok {
+			if storage, _ := version["storage"].(bool); storage {
+				name, _ := version["name"].(string)
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// checkStorageVersionOverride refuses, unless allowed, a storage version
+// override making the storage version of live a version it has never stored:
+// objects would then be stored in a version older controllers, and a
+// rollback, may not read. original is the CRD as found in the bundle, u the
+// CRD to apply.
+func checkStorageVersionOverride(live *apiextensionsv1.CustomResourceDefinition, original, u *unstructured.Unstructured,
+	opts *Options) error {
+
+	name := storageVersion(u)
+	if opts.AllowUnstoredStorageVersion || name == storageVersion(original) {
+		return nil
+	}
+
+	for _, stored := range live.Status.StoredVersions {
+		if stored == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to make version %s the storage version of CRD %s: it has never stored it "+
+		"(stored versions: %v), explicit confirmation is required", name, u.GetName(), live.Status.StoredVersions)
+}
+
+// findVersion returns the entry of versions (spec.versions) with the given name
+func findVersion(versions []interface{}, name string) map[string]interface{} {
+	for i := range versions {
+		version, ok := versions[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if version["name"] == name {
+			return version
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+const (
+	crdWithWebhook = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions:
+      - v1
+      clientConfig:
+        caBundle: Cg==
+        service:
+          namespace: system
+          name: webhook-service
+          path: /convert
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+
+	crdWithWebhookMultipleVersions = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions:
+      - v1
+      clientConfig:
+        service:
+          namespace: system
+          name: webhook-service
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: string
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: integer
+`
+
+	crdWithoutWebhook = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.lib.projectsveltos.io
+spec:
+  group: lib.projectsveltos.io
+  names:
+    kind: Gadget
+    listKind: GadgetList
+    plural: gadgets
+    singular: gadget
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+)
+
+func parse(content string) *unstructured.Unstructured {
+	u, err := k8s_utils.GetUnstructured([]byte(content))
+	Expect(err).To(BeNil())
+	return u
+}
+
+var _ = Describe("Mutations", func() {
+	It("rewriteConversionWebhook rewrites the conversion webhook service", func() {
+		u := parse(crdWithWebhook)
+		opts := &deploy.Options{
+			ConversionWebhook: deploy.ConversionWebhookOptions{
+				Namespace: "projectsveltos",
+				Service:   "sveltos-webhook",
+				Port:      9443,
+			},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+
+		service, found, err := unstructured.NestedMap(u.Object, "spec", "conversion", "webhook", "clientConfig", "service")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(service["namespace"]).To(Equal("projectsveltos"))
+		Expect(service["name"]).To(Equal("sveltos-webhook"))
+		Expect(service["port"]).To(Equal(int64(9443)))
+		Expect(service["path"]).To(Equal("/convert"))
+
+		caBundle, _, err := unstructured.NestedString(u.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		Expect(err).To(BeNil())
+		Expect(caBundle).To(Equal("Cg=="))
+	})
+
+	It("rewriteConversionWebhook only overrides the fields which are set", func() {
+		u := parse(crdWithWebhook)
+		opts := &deploy.Options{
+			ConversionWebhook: deploy.ConversionWebhookOptions{Namespace: "projectsveltos"},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+
+		service, _, err := unstructured.NestedMap(u.Object, "spec", "conversion", "webhook", "clientConfig", "service")
+		Expect(err).To(BeNil())
+		Expect(service["namespace"]).To(Equal("projectsveltos"))
+		Expect(service["name"]).To(Equal("webhook-service"))
+		Expect(service).ToNot(HaveKey("port"))
+	})
+
+	It("rewriteConversionWebhook leaves CRDs without conversion webhook untouched", func() {
+		u := parse(crdWithoutWebhook)
+		original := u.DeepCopy()
+		opts := &deploy.Options{
+			ConversionWebhook: deploy.ConversionWebhookOptions{
+				Namespace: "projectsveltos",
+				Service:   "sveltos-webhook",
+				Port:      9443,
+			},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u).To(Equal(original))
+	})
+
+	It("disableConversionWebhook switches conversion strategy to None", func() {
+		u := parse(crdWithWebhook)
+		opts := &deploy.Options{DisableConversionWebhooks: true}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+
+		conversion, found, err := unstructured.NestedMap(u.Object, "spec", "conversion")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(conversion).To(Equal(map[string]interface{}{"strategy": "None"}))
+	})
+
+	It("disableConversionWebhook refuses CRDs serving versions with different schemas", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{DisableConversionWebhooks: true}
+		Expect(deploy.ApplyMutations(u, opts, logger)).ToNot(Succeed())
+
+		strategy, _, err := unstructured.NestedString(u.Object, "spec", "conversion", "strategy")
+		Expect(err).To(BeNil())
+		Expect(strategy).To(Equal("Webhook"))
+
+		opts.AllowUnsafeConversionDowngrade = true
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		strategy, _, err = unstructured.NestedString(u.Object, "spec", "conversion", "strategy")
+		Expect(err).To(BeNil())
+		Expect(strategy).To(Equal("None"))
+	})
+})
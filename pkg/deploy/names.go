@@ -0,0 +1,163 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// PrinterColumn is an additional printer column added to a CRD
+type PrinterColumn struct {
+	// CRD is the CustomResourceDefinition name. "*" targets all CRDs.
+	CRD string
+
+	// Name of the column
+	Name string
+
+	// Type of the column (string, integer, number, boolean or date)
+	Type string
+
+	// JSONPath is the path, within each resource, of the value displayed
+	JSONPath string
+}
+
+const (
+	allCRDs = "*"
+
+	// printerColumnParts is the number of parts of a printer column flag value
+	printerColumnParts = 4
+)
+
+// ParsePrinterColumns parses values in the <crdName>:<name>:<type>:<jsonPath> format
+func ParsePrinterColumns(values []string) ([]PrinterColumn, error) {
+	result := make([]PrinterColumn, len(values))
+	for i := range values {
+		parts := strings.SplitN(values[i], ":", printerColumnParts)
+		if len(parts) != printerColumnParts {
+			return nil, fmt.Errorf("invalid value %q: expected <crdName>:<name>:<type>:<jsonPath> format", values[i])
+		}
+		for _, p := range parts {
+			if p == "" {
+				return nil, fmt.Errorf("invalid value %q: expected <crdName>:<name>:<type>:<jsonPath> format", values[i])
+			}
+		}
+		switch parts[2] {
+		case "string", "integer", "number", "boolean", "date":
+		default:
+			return nil, fmt.Errorf("invalid value %q: unsupported column type %s", values[i], parts[2])
+		}
+		result[i] = PrinterColumn{CRD: parts[0], Name: parts[1], Type: parts[2], JSONPath: parts[3]}
+	}
+	return result, nil
+}
+
+// addCategory appends category to the CRD categories, unless already present
+func addCategory(u *unstructured.Unstructured, category string, a *audit, logger logr.Logger) error {
+	if category == "" {
+		return nil
+	}
+
+	categories, _, err := unstructured.NestedStringSlice(u.Object, "spec", "names", "categories")
+	if err != nil {
+		return fmt.Errorf("failed to parse categories: %w", err)
+	}
+	for i := range categories {
+		if categories[i] == category {
+			return nil
+		}
+	}
+
+	categories = append(categories, category)
+	if err := unstructured.SetNestedStringSlice(u.Object, categories, "spec", "names", "categories"); err != nil {
+		return fmt.Errorf("failed to set categories: %w", err)
+	}
+
+	a.addModification("category %s added", category)
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("Sveltos CRD %s category %s added", u.GetName(), category))
+	return nil
+}
+
+// addPrinterColumns adds, to every version of the CRD, the printer columns
+// targeting it. Columns with the same name as an existing column are ignored.
+func addPrinterColumns(u *unstructured.Unstructured, columns []PrinterColumn, a *audit, logger logr.Logger) error {
+	var toAdd []PrinterColumn
+	for i := range columns {
+		if columns[i].CRD == allCRDs || columns[i].CRD == u.GetName() {
+			toAdd = append(toAdd, columns[i])
+		}
+	}
+	if len(toAdd) == 0 {
+		return nil
+	}
+
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("failed to parse versions: %w", err)
+	}
+
+	added := false
+	for i := range versions {
+		version, ok := versions[i].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("failed to parse version %d", i)
+		}
+		current, _, err := unstructured.NestedSlice(version, "additionalPrinterColumns")
+		if err != nil {
+			return fmt.Errorf("failed to parse version %d printer columns: %w", i, err)
+		}
+		for j := range toAdd {
+			if hasPrinterColumn(current, toAdd[j].Name) {
+				continue
+			}
+			current = append(current, map[string]interface{}{
+				"name":     toAdd[j].Name,
+				"type":     toAdd[j].Type,
+				"jsonPath": toAdd[j].JSONPath,
+			})
+			added = true
+		}
+		version["additionalPrinterColumns"] = current
+	}
+
+	if !added {
+		return nil
+	}
+
+	if err := unstructured.SetNestedSlice(u.Object, versions, "spec", "versions"); err != nil {
+		return fmt.Errorf("failed to set versions: %w", err)
+	}
+	for i := range toAdd {
+		a.addModification("printer column %s added", toAdd[i].Name)
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("Sveltos CRD %s printer columns added", u.GetName()))
+	return nil
+}
+
+func hasPrinterColumn(columns []interface{}, name string) bool {
+	for i := range columns {
+		if column, ok := columns[i].(map[string]interface{}); ok && column["name"] == name {
+			return true
+		}
+	}
+	return false
+}
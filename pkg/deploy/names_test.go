@@ -0,0 +1,101 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Names", func() {
+	It("addCategory appends the category preserving existing ones", func() {
+		u := parse(crdWithoutWebhook)
+		Expect(unstructured.SetNestedStringSlice(u.Object, []string{"all"}, "spec", "names", "categories")).To(Succeed())
+
+		Expect(deploy.ApplyMutations(u, &deploy.Options{Category: "sveltos"}, logger)).To(Succeed())
+		categories, _, err := unstructured.NestedStringSlice(u.Object, "spec", "names", "categories")
+		Expect(err).To(BeNil())
+		Expect(categories).To(Equal([]string{"all", "sveltos"}))
+	})
+
+	It("addCategory does not duplicate categories", func() {
+		u := parse(crdWithoutWebhook)
+		Expect(unstructured.SetNestedStringSlice(u.Object, []string{"sveltos"}, "spec", "names", "categories")).To(Succeed())
+
+		Expect(deploy.ApplyMutations(u, &deploy.Options{Category: "sveltos"}, logger)).To(Succeed())
+		categories, _, err := unstructured.NestedStringSlice(u.Object, "spec", "names", "categories")
+		Expect(err).To(BeNil())
+		Expect(categories).To(Equal([]string{"sveltos"}))
+		Expect(u.GetAnnotations()).ToNot(HaveKey(auditAnnotation))
+	})
+
+	It("ParsePrinterColumns parses <crdName>:<name>:<type>:<jsonPath> values", func() {
+		columns, err := deploy.ParsePrinterColumns([]string{"*:Age:date:.metadata.creationTimestamp"})
+		Expect(err).To(BeNil())
+		Expect(columns).To(Equal([]deploy.PrinterColumn{
+			{CRD: "*", Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+		}))
+
+		_, err = deploy.ParsePrinterColumns([]string{"*:Age:date"})
+		Expect(err).ToNot(BeNil())
+		_, err = deploy.ParsePrinterColumns([]string{"*:Age:time:.metadata.creationTimestamp"})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("addPrinterColumns adds columns to every version of the targeted CRDs only", func() {
+		opts := &deploy.Options{
+			PrinterColumns: []deploy.PrinterColumn{
+				{CRD: "widgets.lib.projectsveltos.io", Name: "Ready", Type: "boolean", JSONPath: ".status.ready"},
+			},
+		}
+
+		u := parse(crdWithWebhookMultipleVersions)
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		for _, name := range []string{"v1alpha1", "v1beta1"} {
+			Expect(getVersion(u, name)["additionalPrinterColumns"]).To(Equal([]interface{}{
+				map[string]interface{}{"name": "Ready", "type": "boolean", "jsonPath": ".status.ready"},
+			}))
+		}
+
+		// Applying twice does not duplicate the column
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1beta1")["additionalPrinterColumns"]).To(HaveLen(1))
+
+		u = parse(crdWithoutWebhook)
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1beta1")).ToNot(HaveKey("additionalPrinterColumns"))
+	})
+
+	It("Injected category does not cause updates on subsequent runs", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{Category: "sveltos"}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+	})
+})
@@ -0,0 +1,173 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// EventReasonDrifted is the reason of the Events recorded, in observe-only
+	// mode, for CRDs whose spec differs from the bundle
+	EventReasonDrifted = "CRDDrifted"
+
+	// EventReasonMissing is the reason of the Events recorded, in observe-only
+	// mode, for bundle CRDs not found in the cluster
+	EventReasonMissing = "CRDMissing"
+
+	eventActionObserve = "Observe"
+)
+
+// observeCustomResourceDefinition compares a live CRD with the one Deploy
+// would apply, without any write but, with CreateMissing, the creation of a
+// missing CRD. original is the CRD as found in the bundle, u is the CRD
+// Deploy would apply.
+func observeCustomResourceDefinition(ctx context.Context, c client.Client, original, u *unstructured.Unstructured,
+	opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	live := &apiextensionsv1.CustomResourceDefinition{}
+	err := getCRD(ctx, c, u.GetName(), live)
+	if err != nil {
+		if apierrors.IsNotFound(err) && opts.CreateMissing {
+			validation, err := fieldValidation(opts)
+			if err != nil {
+				return err
+			}
+			return createMissingCRD(ctx, c, u, validation, opts, result, logger)
+		}
+		if apierrors.IsNotFound(err) {
+			result.Action = ActionObserved
+			result.Drift = DriftStatusMissing
+			logWarning(logger, "Sveltos CRD %s is missing", u.GetName())
+			recordEvent(opts, u, EventReasonMissing, eventActionObserve, "CRD %s is part of the bundle but missing", u.GetName())
+			return auditObserved(opts, &AuditEntry{CRD: u.GetName(), Action: AuditActionCreate}, logger)
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get default Sveltos CRD instance: %v", err))
+		return err
+	}
+
+	// Terminating, paused and pinned CRDs are reported as a run would leave them
+	if live.DeletionTimestamp != nil {
+		return observeTerminatingCRD(live, u, opts, result, logger)
+	}
+	if held, err := holdCRD(live, u, opts, result, logger); held {
+		return err
+	}
+
+	if manager := resolveOwnership(live, opts, logger); manager != nil {
+		result.Action = manager.action
+		return reportExternalDrift(live, original, manager, result, logger)
+	}
+
+	result.Action = ActionObserved
+	if err := mergeLive(live, u, opts, logger); err != nil {
+		return err
+	}
+	if err := setDrift(live, u, result); err != nil {
+		return err
+	}
+	if result.Drift == DriftStatusInSync {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is in sync with the bundle", u.GetName()))
+		return nil
+	}
+
+	logWarning(logger, "Sveltos CRD %s differs from the bundle", u.GetName())
+	recordEvent(opts, live, EventReasonDrifted, eventActionObserve, "CRD %s spec differs from the bundle %s",
+		u.GetName(), opts.getBundle().Digest())
+	desired, err := updatedCRD(live, u, opts)
+	if err != nil {
+		return err
+	}
+	changes, err := fieldChanges(live, desired)
+	if err != nil {
+		return err
+	}
+	for i := range changes {
+		result.ChangedPaths = append(result.ChangedPaths, changes[i].Path)
+	}
+	if opts.Diff {
+		result.Changes = changes
+	}
+	return auditObserved(opts, &AuditEntry{CRD: u.GetName(), Action: AuditActionUpdate,
+		ChangedPaths: result.ChangedPaths}, logger)
+}
+
+// updatedCRD returns the CRD an update of live with u would write: u with the
+// crd-manager markers and, with the patch and server-side apply strategies,
+// the labels and annotations of live it does not set
+func updatedCRD(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	opts *Options) (*apiextensionsv1.CustomResourceDefinition, error) {
+
+	updated := u.DeepCopy()
+	if isManagedByCRDManager(live) || opts.AdoptExisting == AdoptionAuto {
+		setManagedBy(updated)
+	}
+	setApplySetMember(updated, opts)
+	if err := setAppliedBy(updated); err != nil {
+		return nil, err
+	}
+	desired, err := toCustomResourceDefinition(updated)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ApplyStrategy == ApplyStrategyPatch || opts.ApplyStrategy == ApplyStrategyServerSide {
+		desired = withOwnedFields(live, desired)
+	}
+	return desired, nil
+}
+
+// extraManagedCRDs returns the names of the CRDs carrying the crd-manager
+// ownership label which are not part of crds
+func extraManagedCRDs(ctx context.Context, c client.Client, crds []*bundleCRD) ([]string, error) {
+	expected := make(map[string]bool, len(crds))
+	for _, crd := range crds {
+		expected[crd.desired.GetName()] = true
+	}
+
+	var extra []string
+	err := forEachCRDMetadata(ctx, c, client.MatchingLabels{ManagedByLabel: ManagedByValue},
+		func(crd *metav1.PartialObjectMetadata) error {
+			if !expected[crd.Name] {
+				extra = append(extra, crd.Name)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
+
+// recordEvent records a warning Event regarding obj, if an EventRecorder is set
+func recordEvent(opts *Options, obj runtime.Object, reason, action, note string, args ...any) {
+	if opts.EventRecorder == nil {
+		return
+	}
+	opts.EventRecorder.Eventf(obj, nil, corev1.EventTypeWarning, reason, action, note, args...)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var errWrite = errors.New("write attempted in observe-only mode")
+
+// newReadOnlyClient returns a fake client failing every write
+func newReadOnlyClient(initObjects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+			return errWrite
+		},
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			return errWrite
+		},
+		Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+			return errWrite
+		},
+		Delete: func(context.Context, client.WithWatch, client.Object, ...client.DeleteOption) error {
+			return errWrite
+		},
+	}).Build()
+}
+
+// inSyncBundleCRDs returns all the bundle CRDs, as found in the bundle
+func inSyncBundleCRDs() []client.Object {
+	var result []client.Object
+	for _, u := range getBundleCRDs() {
+		result = append(result, getBundleCRD(u.GetName()))
+	}
+	return result
+}
+
+var _ = Describe("ObserveOnly", func() {
+	It("reports everything in sync without any write", func() {
+		c := newReadOnlyClient(inSyncBundleCRDs()...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.ObserveOnly).To(BeTrue())
+		Expect(report.HasDrift()).To(BeFalse())
+		Expect(report.CountDrift(deploy.ActionObserved, deploy.DriftStatusInSync)).To(Equal(len(report.CRDs)))
+	})
+
+	It("reports missing, drifted and extra managed CRDs without any write", func() {
+		crds := inSyncBundleCRDs()
+		missing := crds[0].GetName()
+		var initObjects []client.Object
+		for _, crd := range crds[1:] {
+			if crd.GetName() != sveltosClusterCRD {
+				initObjects = append(initObjects, crd)
+			}
+		}
+		extra := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "leftovers.lib.projectsveltos.io",
+				Labels: map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue},
+			},
+		}
+		initObjects = append(initObjects, outdatedCRD(), extra)
+		c := newReadOnlyClient(initObjects...)
+		recorder := events.NewFakeRecorder(10)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ObserveOnly: true, EventRecorder: recorder}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.HasDrift()).To(BeTrue())
+		Expect(report.Drifted(deploy.DriftStatusMissing)).To(ConsistOf(missing))
+		Expect(report.Drifted(deploy.DriftStatusDrifted)).To(ConsistOf(sveltosClusterCRD))
+		Expect(report.ExtraCRDs).To(ConsistOf(extra.Name))
+		for i := range report.CRDs {
+			if report.CRDs[i].Name == sveltosClusterCRD {
+				Expect(report.CRDs[i].ChangedPaths).ToNot(BeEmpty())
+			} else {
+				Expect(report.CRDs[i].ChangedPaths).To(BeEmpty())
+			}
+		}
+
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(ContainSubstring(deploy.EventReasonMissing))
+		Expect(<-recorder.Events).To(ContainSubstring(deploy.EventReasonDrifted))
+	})
+
+	It("reports the live and bundle values of the fields an update would change with Diff", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		edited := getCRD(c, sveltosClusterCRD)
+		edited.Spec.Names.ShortNames = []string{"sc"}
+		edited.Labels["team"] = "platform"
+		Expect(c.Update(context.TODO(), edited)).To(Succeed())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.ChangedPaths).To(ConsistOf("metadata.labels.team", "spec.names.shortNames"))
+		Expect(result.Changes).To(BeEmpty())
+
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true, Diff: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Changes).To(ConsistOf(
+			deploy.FieldChange{Path: "metadata.labels.team", Live: "platform"},
+			deploy.FieldChange{Path: "spec.names.shortNames", Live: []interface{}{"sc"}}))
+
+		// the patch strategy keeps the labels crd-manager does not set
+		report, err = deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ObserveOnly: true, Diff: true, ApplyStrategy: deploy.ApplyStrategyPatch}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Changes).To(ConsistOf(
+			deploy.FieldChange{Path: "spec.names.shortNames", Live: []interface{}{"sc"}}))
+	})
+
+	It("is paused by the pause ConfigMap, as a run would be", func() {
+		c := newReadOnlyClient(pauseConfigMap("true"))
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusPaused))
+		Expect(report.CRDs).To(BeEmpty())
+	})
+
+	It("does not need access to the pause ConfigMap", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+				opts ...client.GetOption) error {
+
+				if _, ok := obj.(*corev1.ConfigMap); ok {
+					return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, key.Name,
+						errors.New("denied"))
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+		Expect(report.Drifted(deploy.DriftStatusMissing)).To(HaveLen(len(report.CRDs)))
+	})
+
+	DescribeTable("reports held and terminating CRDs as a run does",
+		func(live func() *apiextensionsv1.CustomResourceDefinition, opts deploy.Options, action deploy.Action) {
+			observeOpts := opts
+			observeOpts.ObserveOnly = true
+			observed, err := deploy.Deploy(context.TODO(), newReadOnlyClient(live()), &observeOpts, logger)
+			Expect(err).To(BeNil())
+			Expect(findResult(observed, sveltosClusterCRD).Action).To(Equal(action))
+
+			applied, err := deploy.Deploy(context.TODO(), newFakeClient(live()), &opts, logger)
+			Expect(err).To(BeNil())
+			Expect(findResult(applied, sveltosClusterCRD).Action).To(Equal(action))
+			Expect(findResult(observed, sveltosClusterCRD).Drift).To(Equal(findResult(applied, sveltosClusterCRD).Drift))
+		},
+		Entry("paused CRD", func() *apiextensionsv1.CustomResourceDefinition {
+			crd := outdatedCRD()
+			crd.Annotations = map[string]string{deploy.PausedAnnotation: "true"}
+			return crd
+		}, deploy.Options{}, deploy.ActionPaused),
+		Entry("pinned CRD", func() *apiextensionsv1.CustomResourceDefinition {
+			crd := outdatedCRD()
+			crd.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+			crd.Annotations = map[string]string{deploy.PinBundleVersionAnnotation: "v0.38.0"}
+			return crd
+		}, deploy.Options{}, deploy.ActionPinned),
+		Entry("terminating CRD", terminatingCRD, deploy.Options{}, deploy.ActionTerminating),
+	)
+
+	It("reports terminating CRDs recreated with TerminatingRecreate as missing", func() {
+		c := newReadOnlyClient(terminatingCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ObserveOnly: true, Terminating: deploy.TerminatingRecreate}, logger)
+		Expect(err).To(BeNil())
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionObserved))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusMissing))
+	})
+
+	It("creates the missing CRDs with CreateMissing, leaving the drifted ones", func() {
+		crds := inSyncBundleCRDs()
+		missing := crds[0].GetName()
+		initObjects := []client.Object{outdatedCRD()}
+		for _, crd := range crds[1:] {
+			if crd.GetName() != sveltosClusterCRD {
+				initObjects = append(initObjects, crd)
+			}
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+				return errWrite
+			},
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				return errWrite
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true, CreateMissing: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(1))
+		Expect(report.Drifted(deploy.DriftStatusDrifted)).To(ConsistOf(sveltosClusterCRD))
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: missing}, &apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+})
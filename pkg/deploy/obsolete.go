@@ -0,0 +1,117 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// removeObsoleteCRDs deletes the retired Sveltos CRDs found in the cluster and
+// adds them to the report removed CRDs. CRDs still having instances are only
+// deleted with ForceRemoveObsolete, as deleting a CRD deletes all its instances.
+func removeObsoleteCRDs(ctx context.Context, c client.Client, opts *Options, report *Report,
+	logger logr.Logger) error {
+
+	failed := &DeployError{}
+	for _, obsolete := range crds.ObsoleteCRDs() {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		err := c.Get(ctx, types.NamespacedName{Name: obsolete.Name}, crd)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		result := CRDResult{Name: obsolete.Name, Action: ActionRemovedObsolete}
+		if err := deleteUnusedCRD(ctx, c, crd, opts.ForceRemoveObsolete, opts, &result, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to remove obsolete CRD %s: %v", obsolete.Name, err))
+			result.Action = ActionFailed
+			result.Error = err.Error()
+			failed.add(obsolete.Name, err)
+		} else {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("removed obsolete CRD %s (retired in Sveltos %s)",
+				obsolete.Name, obsolete.RemovedIn))
+		}
+		report.Removed = append(report.Removed, result)
+	}
+	return failed.err()
+}
+
+// deleteUnusedCRD deletes crd, first deleting its instances with Cascade.
+// Otherwise, unless force is set, a CRD still having instances is not deleted.
+func deleteUnusedCRD(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition,
+	force bool, opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	if opts.Cascade {
+		if err := cascadeDeleteInstances(ctx, c, crd, opts, result, logger); err != nil {
+			return err
+		}
+	} else if !force {
+		inUse, err := hasInstances(ctx, c, crd)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return errors.New("instances still exist, refusing to delete it (use force to delete them as well)")
+		}
+	}
+
+	err := c.Delete(ctx, crd)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return auditWrite(opts, &AuditEntry{CRD: crd.Name, Action: AuditActionDelete}, err, logger)
+}
+
+// hasInstances returns true if at least one resource of the kind crd defines exists
+func hasInstances(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition) (bool, error) {
+	version := ""
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			version = crd.Spec.Versions[i].Name
+		}
+	}
+	if version == "" {
+		// an unserved CRD has no instance reachable through the API
+		return false, nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: version,
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	if err := c.List(ctx, list, client.Limit(1)); err != nil {
+		return false, err
+	}
+	return len(list.Items) > 0, nil
+}
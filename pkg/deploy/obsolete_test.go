@@ -0,0 +1,100 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// obsoleteCRD returns the first obsolete CRD as it was deployed
+func obsoleteCRD() *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: crds.ObsoleteCRDs()[0].Name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "lib.projectsveltos.io",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Kind:     "AddonCompliance",
+				ListKind: "AddonComplianceList",
+				Plural:   "addoncompliances",
+			},
+			Scope: apiextensionsv1.ClusterScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+			},
+		},
+	}
+}
+
+// obsoleteInstance returns an instance of obsoleteCRD
+func obsoleteInstance() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("lib.projectsveltos.io/v1alpha1")
+	u.SetKind("AddonCompliance")
+	u.SetName("leftover")
+	return u
+}
+
+var _ = Describe("RemoveObsolete", func() {
+	It("deletes obsolete CRDs without instances", func() {
+		c := newFakeClient(obsoleteCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{RemoveObsolete: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionRemovedObsolete)))
+
+		err = c.Get(context.TODO(), types.NamespacedName{Name: obsoleteCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("keeps obsolete CRDs by default", func() {
+		c := newFakeClient(obsoleteCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: obsoleteCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("refuses to delete obsolete CRDs with instances unless forced", func() {
+		c := newFakeClient(obsoleteCRD(), obsoleteInstance())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{RemoveObsolete: true}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionFailed)))
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: obsoleteCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c,
+			&deploy.Options{RemoveObsolete: true, ForceRemoveObsolete: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionRemovedObsolete)))
+	})
+})
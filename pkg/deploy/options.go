@@ -0,0 +1,517 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// Options configures how Deploy manages the Sveltos CRDs
+type Options struct {
+	// Bundle contains the CRDs to deploy. When nil, the embedded bundle is used.
+	Bundle *bundle.Bundle
+
+	// ForceOwnership makes Deploy update CRDs even when they are managed by
+	// another tool (for instance Helm or Argo CD). By default those CRDs are left untouched.
+	ForceOwnership bool
+
+	// OwnershipPolicy, when set, decides per CRD whether it is managed, skipped
+	// or adopted, in place of the built-in ownership heuristics. ForceOwnership
+	// still adopts CRDs the policy manages but does not override skip rules.
+	OwnershipPolicy *OwnershipPolicy
+
+	// HelmRelease, when set, stamps the Helm release metadata on the applied
+	// CRDs. CRDs carrying the Helm markers are then skipped only when they
+	// lack the crd-manager ownership label.
+	HelmRelease HelmReleaseOptions
+
+	// AdoptExisting tells how CRDs already present without the crd-manager
+	// ownership label (for instance created with kubectl) are handled.
+	// CRDs created by crd-manager always carry the label.
+	AdoptExisting AdoptionMode
+
+	// ConversionWebhook rewrites the conversion webhook service of every CRD
+	// using a Webhook conversion strategy
+	ConversionWebhook ConversionWebhookOptions
+
+	// DisableConversionWebhooks switches every CRD using a Webhook conversion
+	// strategy to the None strategy
+	DisableConversionWebhooks bool
+
+	// AllowUnsafeConversionDowngrade allows DisableConversionWebhooks to proceed
+	// for CRDs serving multiple versions with different schemas
+	AllowUnsafeConversionDowngrade bool
+
+	// InjectCAFrom, in the namespace/certificate-name format, is the cert-manager
+	// Certificate whose CA is injected into CRDs using a conversion webhook
+	InjectCAFrom string
+
+	// InjectCAFromPerCRD overrides InjectCAFrom for specific CRDs. Keys are CRD names.
+	InjectCAFromPerCRD map[string]string
+
+	// StripCEL removes CEL validation rules (x-kubernetes-validations) from the CRDs.
+	// Rules are always removed when ServerVersion does not support them.
+	StripCEL bool
+
+	// ServerVersion is the version of the Kubernetes API server CRDs are deployed
+	// to. When empty, no decision is based on it.
+	ServerVersion string
+
+	// DisabledVersions lists the CRD versions which must not be served
+	DisabledVersions []CRDVersion
+
+	// StorageVersions overrides, per CRD, the bundle storage version. At most
+	// one version per CRD.
+	StorageVersions []CRDVersion
+
+	// AllowUnstoredStorageVersion allows StorageVersions to make storage
+	// version of a live CRD a version it has never stored
+	AllowUnstoredStorageVersion bool
+
+	// Category, when set, is added to the categories of every CRD
+	// (so that for instance kubectl get <category> lists all Sveltos resources)
+	Category string
+
+	// PrinterColumns are additional printer columns added to CRDs
+	PrinterColumns []PrinterColumn
+
+	// Labels and Annotations are added to every CRD. Keys injected by a
+	// previous run and no longer configured are removed.
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// FailOnNameConflicts aborts, before any write, when a name bundle CRDs want
+	// is already claimed by another CRD. By default conflicts are only reported.
+	FailOnNameConflicts bool
+
+	// FieldValidation is the field validation directive (Strict, Warn or Ignore)
+	// sent with create and update requests. When empty, it is Strict unless
+	// ServerVersion is known not to support server side field validation.
+	FieldValidation string
+
+	// Patches are applied, in order, to the bundle CRDs they target, after all
+	// other mutations. Every patch must target a CRD of the bundle.
+	Patches []Patch
+
+	// ObserveOnly makes Deploy compare the live CRDs with the ones it would
+	// apply, and report missing, drifted and extra managed CRDs, without any
+	// write. CRDs are reported as a run would handle them: paused, pinned and
+	// terminating ones as such, and nothing while the pause ConfigMap pauses
+	// crd-manager.
+	ObserveOnly bool
+
+	// CreateMissing makes ObserveOnly runs still create the bundle CRDs
+	// missing from the cluster, as other runs do. No other write is made.
+	CreateMissing bool
+
+	// Diff makes ObserveOnly runs report, for every drifted CRD, the live and
+	// bundle values of the fields an update would change
+	Diff bool
+
+	// EventRecorder, when set, records an Event for every drift ObserveOnly detects
+	EventRecorder events.EventRecorder
+
+	// AuditLog, when set, records every CRD create, update and delete and,
+	// if configured to, the ones ObserveOnly would have performed
+	AuditLog *AuditLog
+
+	// ApplySet, when set, makes the deployed CRDs members of this ApplySet.
+	// Members no longer part of the bundle are deleted at the end of a run
+	// without failures.
+	ApplySet *ApplySet
+
+	// RemoveObsolete deletes the retired Sveltos CRDs (crds.ObsoleteCRDs) found
+	// in the cluster, unless instances still exist
+	RemoveObsolete bool
+
+	// ForceRemoveObsolete makes RemoveObsolete delete retired CRDs even when
+	// instances still exist, deleting them as well
+	ForceRemoveObsolete bool
+
+	// Retries is how many times, within a run, a CRD failing because the API
+	// server is unavailable (5xx or 429 status, timeout) is attempted again
+	// before giving up. The other CRDs are processed either way.
+	Retries int
+
+	// RetryInterval is the delay before the first retry of a CRD, doubling
+	// after every attempt. Defaults to DefaultRetryInterval.
+	RetryInterval time.Duration
+
+	// WaitTimeout, when set, makes a run without failures wait, once the CRDs
+	// are applied, for them to be established and their names accepted. The
+	// run fails with a *WaitError listing the CRDs not ready when it expires.
+	WaitTimeout time.Duration
+
+	// Prune deletes, at the end of a run without failures, the CRDs carrying
+	// the ManagedByLabel which are part of neither the bundle nor the embedded
+	// bundle, such as the ones a Sveltos release dropped, unless instances
+	// still exist. CRDs not selected by Components, IncludeCRDs and
+	// ExcludeCRDs are never pruned.
+	Prune bool
+
+	// PruneForce makes Prune delete CRDs even when instances still exist,
+	// deleting them as well
+	PruneForce bool
+
+	// Cascade makes the removal of a CRD, by RemoveObsolete, Prune or ApplySet
+	// pruning, first delete its instances and wait for them to be gone, so
+	// that their finalizers run. The CRD is not deleted if instances remain
+	// once CascadeTimeout expires. RemoveObsolete and Prune then delete CRDs
+	// still having instances, as with ForceRemoveObsolete and PruneForce.
+	Cascade bool
+
+	// CascadeTimeout is how long Cascade waits for the instances of a CRD
+	// to be gone. Defaults to DefaultCascadeTimeout.
+	CascadeTimeout time.Duration
+
+	// Terminating tells how bundle CRDs found terminating are handled.
+	// Defaults to TerminatingReport.
+	Terminating TerminatingPolicy
+
+	// TerminatingTimeout is how long TerminatingRecreate waits for the
+	// deletion of a CRD to complete. Defaults to DefaultTerminatingTimeout.
+	TerminatingTimeout time.Duration
+
+	// SkipConversionCheck skips checking, before an update changes the
+	// storage version of a CRD using webhook conversion, that its conversion
+	// webhook is healthy
+	SkipConversionCheck bool
+
+	// ConversionCheckRead adds to the conversion webhook check the read of an
+	// existing instance in the new storage version, which requires a conversion
+	ConversionCheckRead bool
+
+	// ApplyStrategy tells how outdated CRDs are written. Left empty, CRDs are
+	// written with ApplyStrategyUpdate. The crd-manager command defaults to
+	// ApplyStrategyServerSide instead, with --apply-strategy.
+	ApplyStrategy ApplyStrategy
+
+	// FieldManager is the field manager of the CRD creates, updates, patches
+	// and server-side applies. Defaults to DefaultFieldManager.
+	FieldManager string
+
+	// ForceConflicts makes, with ApplyStrategyServerSide, the applies take
+	// over the fields other field managers set to different values, instead
+	// of failing the CRD
+	ForceConflicts bool
+
+	// OwnerRef, when set, is set as non-controller owner of every CRD Deploy
+	// creates: deleting it garbage collects them. It must exist and be
+	// cluster-scoped. Owner references of existing CRDs are preserved.
+	OwnerRef *OwnerRef
+
+	// ownerReference is OwnerRef once resolved
+	ownerReference *metav1.OwnerReference
+
+	// MaxObjectSize is the size, in bytes, CRD serialized sizes are compared
+	// with. Defaults to DefaultMaxObjectSize.
+	MaxObjectSize int64
+
+	// SizeWarningPercent is the percentage of MaxObjectSize above which a
+	// warning is logged for a CRD. Defaults to DefaultSizeWarningPercent.
+	SizeWarningPercent int
+
+	// FailOnWarnings fails CRDs for which the API server returned warnings.
+	// The CRDs have been written by then.
+	FailOnWarnings bool
+
+	// MergeVersions makes updates additive for spec.versions: versions the
+	// live CRD defines but the bundle dropped are retained, with their served
+	// state, so that older controllers keep working during rolling upgrades.
+	// The bundle storage version stays the only storage version.
+	MergeVersions bool
+
+	// SetLastApplied records, on create and update, the applied CRD in the
+	// kubectl.kubernetes.io/last-applied-configuration annotation, for kubectl
+	// apply and kubectl diff. CRDs too large for it are written without.
+	SetLastApplied bool
+
+	// Preserve are the paths, within the CRD spec, whose live values are
+	// merged with the bundle ones instead of being replaced, so that cluster
+	// admin customizations such as additional short names survive updates
+	Preserve []PreservePath
+
+	// CheckExistingCRs makes Deploy, before updating a CRD whose schema changes,
+	// validate the existing objects of every changed version against the new
+	// schema. Objects failing it are reported and logged as a warning.
+	CheckExistingCRs bool
+
+	// CheckExistingCRsLimit is the maximum number of objects CheckExistingCRs
+	// validates per CRD version. Zero means all objects are listed.
+	CheckExistingCRsLimit int64
+
+	// FailOnIncompatibleCRs makes CheckExistingCRs findings fail the CRD
+	// update in place of a warning
+	FailOnIncompatibleCRs bool
+
+	// SmokeTest makes Deploy, once every CRD is applied, submit with
+	// server-side dry-run a sample object for every served CRD version, to
+	// verify admission, conversion and defaulting work. Nothing is persisted.
+	SmokeTest bool
+
+	// RollbackOnFailure makes Deploy, once every CRD is applied, verify that
+	// each one establishes, has its names accepted and, with SmokeTest, accepts
+	// its samples. CRDs the run updated which fail it are restored to their
+	// spec before the update; CRDs the run created are deleted, unless they no
+	// longer belong to crd-manager or have instances.
+	RollbackOnFailure bool
+
+	// RollbackTimeout is how long RollbackOnFailure waits for the CRDs
+	// applied, then for the ones restored, to be established. Defaults to
+	// DefaultRollbackTimeout.
+	RollbackTimeout time.Duration
+
+	// ProtectCRDs installs a ValidatingAdmissionPolicy denying the deletion,
+	// and the destructive updates, of the CRDs carrying the crd-manager
+	// ownership label by anyone but ProtectServiceAccount
+	ProtectCRDs bool
+
+	// ProtectServiceAccount, in the namespace/name format, is the
+	// ServiceAccount crd-manager runs as, exempted by ProtectCRDs
+	ProtectServiceAccount string
+
+	// Lock, when set, is held while the CRDs are written, so that concurrent
+	// runs do not race each other. Observe-only runs do not take it.
+	Lock *Lock
+
+	// History, when set, records what each run did. Observe-only runs are not
+	// recorded.
+	History *History
+
+	// Quarantine, when set, stops attempting the CRDs which keep failing the
+	// same way. It requires History.
+	Quarantine *Quarantine
+
+	// quarantine is the Quarantine state read from the history
+	quarantine *quarantineState
+
+	// VersionMarker, when set, is the ConfigMap recording the bundle last
+	// fully applied. Observe-only runs do not advance it.
+	VersionMarker *VersionMarker
+
+	// CircuitBreaker, when set, stops processing CRDs while the API server
+	// looks unavailable. Runs sharing it share its state.
+	CircuitBreaker *CircuitBreaker
+
+	// FailFast stops the run at the first CRD which fails. The CRDs after it
+	// are reported as not attempted. By default all CRDs are processed.
+	FailFast bool
+
+	// Components, when set, restricts the CRDs deployed to the ones of these
+	// components (see crds.Components). The CRDs of other components, and those
+	// not part of any, are left untouched: they are neither pruned nor
+	// reported as extra.
+	Components []string
+
+	// IncludeCRDs, when set, restricts the CRDs deployed to the ones whose
+	// name matches one of these path.Match patterns, such as
+	// "*.lib.projectsveltos.io"
+	IncludeCRDs []string
+
+	// ExcludeCRDs are path.Match patterns of the names of CRDs not to
+	// deploy, taking precedence over IncludeCRDs. As with Components, the
+	// CRDs not selected are left untouched.
+	ExcludeCRDs []string
+
+	// Defer, when set, is called with the name of every bundle CRD. The CRDs
+	// it returns true for are not processed and are reported as deferred.
+	Defer func(name string) bool
+
+	// Progress, when set, is called, synchronously and in order, with every
+	// step of a run: every bundle CRD being parsed, skipped, created, updated
+	// or failing and, last, the completion of the run. WaitForCRDs reports
+	// the CRDs waited for and established. It must not block.
+	Progress func(event ProgressEvent)
+
+	// StrictParse aborts the run, before any write, when a bundle document
+	// cannot be parsed. By default malformed documents are skipped, reported,
+	// and fail the run once the valid ones are applied.
+	StrictParse bool
+}
+
+// getBundle returns the bundle to deploy
+func (o *Options) getBundle() *bundle.Bundle {
+	if o.Bundle == nil {
+		return bundle.Embedded()
+	}
+	return o.Bundle
+}
+
+// CRDVersion identifies a version of a CRD
+type CRDVersion struct {
+	// CRD is the CustomResourceDefinition name
+	CRD string
+
+	// Version is the version name (e.g. v1beta1)
+	Version string
+}
+
+// ParseCRDVersions parses values in the <crdName>:<version> format
+func ParseCRDVersions(values []string) ([]CRDVersion, error) {
+	result := make([]CRDVersion, len(values))
+	for i := range values {
+		crd, version, found := strings.Cut(values[i], ":")
+		if !found || crd == "" || version == "" {
+			return nil, fmt.Errorf("invalid value %q: expected <crdName>:<version> format", values[i])
+		}
+		result[i] = CRDVersion{CRD: crd, Version: version}
+	}
+	return result, nil
+}
+
+// versionsFor returns the versions in crdVersions targeting crd
+func versionsFor(crd string, crdVersions []CRDVersion) []string {
+	var result []string
+	for i := range crdVersions {
+		if crdVersions[i].CRD == crd {
+			result = append(result, crdVersions[i].Version)
+		}
+	}
+	return result
+}
+
+// Validate returns an error if opts is not consistent
+func (o *Options) Validate() error {
+	if err := o.validateCAInjection(); err != nil {
+		return err
+	}
+	if err := validateFieldValidation(o.FieldValidation); err != nil {
+		return err
+	}
+	if err := validateAdoptionMode(o.AdoptExisting); err != nil {
+		return err
+	}
+	if err := validateTerminatingPolicy(o.Terminating); err != nil {
+		return err
+	}
+	if err := o.validateApplyStrategy(); err != nil {
+		return err
+	}
+	if err := o.validateRunState(); err != nil {
+		return err
+	}
+	if err := o.HelmRelease.validate(); err != nil {
+		return err
+	}
+	if err := validateMetadata(o.Labels, o.Annotations); err != nil {
+		return err
+	}
+	if o.ProtectCRDs {
+		if err := validateNamespacedName(o.ProtectServiceAccount); err != nil {
+			return fmt.Errorf("invalid protect service account %q: %w", o.ProtectServiceAccount, err)
+		}
+	}
+	if err := crds.ValidateComponents(o.Components); err != nil {
+		return err
+	}
+	if err := validateCRDPatterns(o.IncludeCRDs); err != nil {
+		return err
+	}
+	if err := validateCRDPatterns(o.ExcludeCRDs); err != nil {
+		return err
+	}
+	if err := o.validateObjectSize(); err != nil {
+		return err
+	}
+	if o.Retries < 0 {
+		return fmt.Errorf("invalid retries %d: must not be negative", o.Retries)
+	}
+	if o.CheckExistingCRsLimit < 0 {
+		return fmt.Errorf("invalid check existing CRs limit %d: must not be negative", o.CheckExistingCRsLimit)
+	}
+	seen := map[string]bool{}
+	for _, override := range o.StorageVersions {
+		if seen[override.CRD] {
+			return fmt.Errorf("more than one storage version given for CRD %s", override.CRD)
+		}
+		seen[override.CRD] = true
+	}
+
+	return nil
+}
+
+// validateRunState validates the options of the state kept across runs
+func (o *Options) validateRunState() error {
+	if err := o.Lock.validate(); err != nil {
+		return err
+	}
+	if err := o.History.validate(); err != nil {
+		return err
+	}
+	if err := o.Quarantine.validate(o.History); err != nil {
+		return err
+	}
+	if err := o.CircuitBreaker.validate(); err != nil {
+		return err
+	}
+	return o.VersionMarker.validate()
+}
+
+func (o *Options) validateCAInjection() error {
+	if o.InjectCAFrom != "" {
+		if err := validateNamespacedName(o.InjectCAFrom); err != nil {
+			return fmt.Errorf("invalid inject CA from %q: %w", o.InjectCAFrom, err)
+		}
+	}
+	for crd, certificate := range o.InjectCAFromPerCRD {
+		if err := validateNamespacedName(certificate); err != nil {
+			return fmt.Errorf("invalid inject CA from %q for CRD %s: %w", certificate, crd, err)
+		}
+	}
+	return nil
+}
+
+// validateNamespacedName verifies value is in the namespace/name format
+func validateNamespacedName(value string) error {
+	namespace, name, found := strings.Cut(value, "/")
+	if !found || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return errors.New("expected namespace/name format")
+	}
+	return nil
+}
+
+// ConversionWebhookOptions overrides the service conversion requests are sent to.
+// Empty/zero fields leave the corresponding bundle value unchanged.
+type ConversionWebhookOptions struct {
+	// Namespace of the conversion webhook service
+	Namespace string
+
+	// Service is the name of the conversion webhook service
+	Service string
+
+	// Port of the conversion webhook service
+	Port int32
+}
+
+func (o *ConversionWebhookOptions) isSet() bool {
+	return o.Namespace != "" || o.Service != "" || o.Port != 0
+}
+
+// writes returns true if Deploy may write CRDs: unless ObserveOnly or, with
+// CreateMissing, to create the missing ones
+func (o *Options) writes() bool {
+	return !o.ObserveOnly || o.CreateMissing
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// OwnerRef identifies the cluster-scoped object set as owner of the CRDs
+// crd-manager creates, so that deleting it garbage collects them
+type OwnerRef struct {
+	// APIVersion of the owner, e.g. v1 or lib.projectsveltos.io/v1beta1
+	APIVersion string
+
+	// Kind of the owner
+	Kind string
+
+	// Name of the owner
+	Name string
+}
+
+// ParseOwnerRef parses value, in the form <apiVersion>/<kind>/<name>, the
+// apiVersion being either <version> or <group>/<version>
+func ParseOwnerRef(value string) (*OwnerRef, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, fmt.Errorf("invalid owner reference %q: expected <apiVersion>/<kind>/<name>", value)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid owner reference %q: expected <apiVersion>/<kind>/<name>", value)
+		}
+	}
+
+	ref := &OwnerRef{
+		APIVersion: strings.Join(parts[:len(parts)-2], "/"),
+		Kind:       parts[len(parts)-2],
+		Name:       parts[len(parts)-1],
+	}
+	if _, err := schema.ParseGroupVersion(ref.APIVersion); err != nil {
+		return nil, fmt.Errorf("invalid owner reference %q: %w", value, err)
+	}
+	return ref, nil
+}
+
+func (r *OwnerRef) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.APIVersion, r.Kind, r.Name)
+}
+
+// resolve verifies the owner exists and is cluster-scoped, then returns the
+// non-controller owner reference pointing at it
+func (r *OwnerRef) resolve(ctx context.Context, c client.Client) (*metav1.OwnerReference, error) {
+	owner := &metav1.PartialObjectMetadata{}
+	owner.SetGroupVersionKind(schema.FromAPIVersionAndKind(r.APIVersion, r.Kind))
+
+	namespaced, err := c.IsObjectNamespaced(owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve owner %s: %w", r, err)
+	}
+	if namespaced {
+		return nil, fmt.Errorf("owner %s is namespaced: cluster-scoped CRDs can only be owned by "+
+			"cluster-scoped objects", r)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: r.Name}, owner); err != nil {
+		return nil, fmt.Errorf("failed to get owner %s: %w", r, err)
+	}
+	return &metav1.OwnerReference{APIVersion: r.APIVersion, Kind: r.Kind, Name: r.Name, UID: owner.GetUID()}, nil
+}
+
+// withOwnerReference returns, when OwnerRef is set, a copy of opts carrying
+// the resolved owner reference. Observe-only runs, unless they create missing
+// CRDs, create nothing and do not resolve it.
+func (o *Options) withOwnerReference(ctx context.Context, c client.Client, logger logr.Logger) (*Options, error) {
+	if o.OwnerRef == nil || !o.writes() {
+		return o, nil
+	}
+	ownerReference, err := o.OwnerRef.resolve(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("CRDs created are owned by %s (uid %s)", o.OwnerRef, ownerReference.UID))
+
+	resolved := *o
+	resolved.ownerReference = ownerReference
+	return &resolved, nil
+}
+
+// setOwnerReference adds to u, a CRD being created, the resolved owner
+// reference, if any
+func setOwnerReference(u *unstructured.Unstructured, opts *Options) {
+	if opts.ownerReference == nil {
+		return
+	}
+	u.SetOwnerReferences(append(u.GetOwnerReferences(), *opts.ownerReference))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Owner reference", func() {
+	const name = "clusterprofiles.config.projectsveltos.io"
+
+	anchor := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "preview-42", UID: "1234"}}
+
+	// newOwnerClient returns a fake client able to tell cluster-scoped from
+	// namespaced core kinds
+	newOwnerClient := func(objs ...client.Object) client.Client {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+		return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(objs...).Build()
+	}
+
+	ownerOptions := func(value string) *deploy.Options {
+		ref, err := deploy.ParseOwnerRef(value)
+		Expect(err).To(BeNil())
+		return &deploy.Options{OwnerRef: ref}
+	}
+
+	getOwnerReferences := func(c client.Client) []metav1.OwnerReference {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name}, crd)).To(Succeed())
+		return crd.OwnerReferences
+	}
+
+	It("ParseOwnerRef accepts core and grouped API versions", func() {
+		ref, err := deploy.ParseOwnerRef("v1/Namespace/preview-42")
+		Expect(err).To(BeNil())
+		Expect(*ref).To(Equal(deploy.OwnerRef{APIVersion: "v1", Kind: "Namespace", Name: "preview-42"}))
+
+		ref, err = deploy.ParseOwnerRef("lib.projectsveltos.io/v1beta1/Anchor/preview")
+		Expect(err).To(BeNil())
+		Expect(ref.APIVersion).To(Equal("lib.projectsveltos.io/v1beta1"))
+		Expect(ref.String()).To(Equal("lib.projectsveltos.io/v1beta1/Anchor/preview"))
+
+		for _, value := range []string{"Namespace/preview-42", "v1//preview-42", "a/b/c/d/e"} {
+			_, err := deploy.ParseOwnerRef(value)
+			Expect(err).To(HaveOccurred(), value)
+		}
+	})
+
+	It("sets the owner reference on created CRDs and preserves it on updates", func() {
+		c := newOwnerClient(anchor)
+		opts := ownerOptions("v1/Namespace/preview-42")
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionCreated))
+		expected := metav1.OwnerReference{APIVersion: "v1", Kind: "Namespace", Name: "preview-42", UID: "1234"}
+		Expect(getOwnerReferences(c)).To(ConsistOf(expected))
+
+		// Force an update, without the owner reference
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name}, crd)).To(Succeed())
+		crd.Spec.Names.ShortNames = append(crd.Spec.Names.ShortNames, "extra")
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionUpdated))
+		Expect(getOwnerReferences(c)).To(ConsistOf(expected))
+	})
+
+	It("refuses namespaced owners", func() {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "projectsveltos", Name: "anchor"}}
+		c := newOwnerClient(configMap)
+
+		_, err := deploy.Deploy(context.TODO(), c, ownerOptions("v1/ConfigMap/anchor"), logger)
+		Expect(err).To(MatchError(ContainSubstring("is namespaced")))
+	})
+
+	It("fails before writing anything when the owner does not exist", func() {
+		c := newOwnerClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, ownerOptions("v1/Namespace/preview-42"), logger)
+		Expect(err).To(MatchError(ContainSubstring("failed to get owner")))
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(BeEmpty())
+	})
+})
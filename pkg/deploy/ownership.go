@@ -0,0 +1,120 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	appManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// argoCDTrackingIDAnnotation is set by Argo CD when using annotation based
+	// resource tracking. Its value is in the <app>:<group>/<kind>:<namespace>/<name> format.
+	argoCDTrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+
+	// argoCDInstanceLabel is set by Argo CD when using label based resource
+	// tracking. Its value is the application name.
+	argoCDInstanceLabel = "app.kubernetes.io/instance"
+)
+
+// externalManager is a tool, other than crd-manager, owning a CRD
+type externalManager struct {
+	// description is how the owner is named in messages
+	description string
+
+	// action is the action reported for CRDs left to this owner
+	action Action
+
+	// remediation tells operators how to bring an outdated CRD in sync
+	remediation string
+}
+
+// externalManagerOf returns the tool owning crd, or nil if crd is not owned
+// by any known tool
+func externalManagerOf(crd *apiextensionsv1.CustomResourceDefinition) *externalManager {
+	if isManagedByHelm(crd) {
+		return &externalManager{
+			description: "helm",
+			action:      ActionSkippedHelm,
+			remediation: "Either upgrade the Helm release owning it or run crd-manager with --force-ownership",
+		}
+	}
+
+	if app := argoCDApplication(crd); app != "" {
+		return &externalManager{
+			description: fmt.Sprintf("Argo CD (app %s)", app),
+			action:      ActionSkippedArgoCD,
+			remediation: fmt.Sprintf("Either sync the Argo CD application %s or run crd-manager with --force-ownership", app),
+		}
+	}
+
+	return nil
+}
+
+func isManagedByHelm(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	lbls := crd.GetLabels()
+	if lbls == nil {
+		return false
+	}
+
+	_, ok := lbls[appManagedByLabel]
+	return ok
+}
+
+// argoCDApplication returns the Argo CD application tracking crd, if any
+func argoCDApplication(crd *apiextensionsv1.CustomResourceDefinition) string {
+	if trackingID, ok := crd.GetAnnotations()[argoCDTrackingIDAnnotation]; ok {
+		app, _, _ := strings.Cut(trackingID, ":")
+		return app
+	}
+	return crd.GetLabels()[argoCDInstanceLabel]
+}
+
+// reportExternalDrift compares a CRD owned by another tool, which is left
+// untouched, with the bundle and warns when the live CRD is outdated. As
+// crd-manager does not own the CRD, the comparison is done against the bundle
+// CRD, without mutations.
+func reportExternalDrift(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	manager *externalManager, result *CRDResult, logger logr.Logger) error {
+
+	inSync, err := isInSync(live, u)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to compare Sveltos CRD %s with bundle: %v",
+			u.GetName(), err))
+		return err
+	}
+
+	if inSync {
+		result.Drift = DriftStatusInSync
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is managed by %s, skipping (in sync with bundle)",
+			u.GetName(), manager.description))
+		return nil
+	}
+
+	result.Drift = DriftStatusDrifted
+	logWarning(logger, "Sveltos CRD %s is managed by %s, skipping, but it is outdated compared to the bundle. %s",
+		u.GetName(), manager.description, manager.remediation)
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Argo CD ownership", func() {
+	DescribeTable("Argo CD tracked CRDs are skipped",
+		func(setTracking func(crd *apiextensionsv1.CustomResourceDefinition)) {
+			crd := getBundleCRD(sveltosClusterCRD)
+			setTracking(crd)
+			crd.Spec.Names.ShortNames = []string{"sc"}
+			c := newFakeClient(crd)
+
+			report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+			Expect(err).To(BeNil())
+
+			result := findResult(report, sveltosClusterCRD)
+			Expect(result).ToNot(BeNil())
+			Expect(result.Action).To(Equal(deploy.ActionSkippedArgoCD))
+			Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+
+			current := &apiextensionsv1.CustomResourceDefinition{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+			Expect(current.Spec.Names.ShortNames).To(Equal([]string{"sc"}))
+		},
+		Entry("annotation based tracking", func(crd *apiextensionsv1.CustomResourceDefinition) {
+			crd.Annotations = map[string]string{
+				"argocd.argoproj.io/tracking-id": "sveltos:apiextensions.k8s.io/CustomResourceDefinition:/" + sveltosClusterCRD,
+			}
+		}),
+		Entry("label based tracking", func(crd *apiextensionsv1.CustomResourceDefinition) {
+			crd.Labels = map[string]string{"app.kubernetes.io/instance": "sveltos"}
+		}),
+	)
+
+	It("Argo CD tracked CRDs in sync with the bundle are reported in sync", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/instance": "sveltos"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CountDrift(deploy.ActionSkippedArgoCD, deploy.DriftStatusInSync)).To(Equal(1))
+	})
+
+	It("Argo CD tracked CRDs are updated with ForceOwnership", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Annotations = map[string]string{
+			"argocd.argoproj.io/tracking-id": "sveltos:apiextensions.k8s.io/CustomResourceDefinition:/" + sveltosClusterCRD,
+		}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ForceOwnership: true}, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).ToNot(Equal([]string{"sc"}))
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+)
+
+// parseSnippetLength is the maximum length of the document content a
+// ParseFailure quotes
+const parseSnippetLength = 80
+
+// ParseFailure is a bundle document which could not be parsed
+type ParseFailure struct {
+	// Document is the position, starting at 1, of the document in the bundle.
+	// Empty documents, and those only made of comments, are not counted.
+	Document int `json:"document"`
+
+	// Snippet is the beginning of the document content
+	Snippet string `json:"snippet"`
+
+	// Error is the parse error
+	Error string `json:"error"`
+}
+
+// ParseError is returned when some bundle documents could not be parsed. The
+// other documents are still applied, unless Options.StrictParse is set.
+type ParseError struct {
+	Failures []ParseFailure
+}
+
+func (e *ParseError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i := range e.Failures {
+		failures[i] = fmt.Sprintf("document %d (%q): %s", e.Failures[i].Document, e.Failures[i].Snippet,
+			e.Failures[i].Error)
+	}
+	return fmt.Sprintf("%d bundle documents could not be parsed: %s", len(e.Failures), strings.Join(failures, "; "))
+}
+
+// parseBundle parses the documents of content one by one, so that a malformed
+// document does not prevent using the others. It returns the objects of the
+// valid documents and the failures.
+func parseBundle(content []byte) ([]*unstructured.Unstructured, []ParseFailure, error) {
+	var objects []*unstructured.Unstructured
+	var failures []ParseFailure
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	document := 0
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objects, failures, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if isEmptyDocument(doc) {
+			continue
+		}
+		document++
+
+		docObjects, err := parseDocument(doc)
+		if err != nil {
+			failures = append(failures, ParseFailure{Document: document, Snippet: snippet(doc), Error: err.Error()})
+			continue
+		}
+		objects = append(objects, docObjects...)
+	}
+}
+
+// parseDocument returns the objects of a bundle document, which can be a
+// YAML list
+func parseDocument(doc []byte) ([]*unstructured.Unstructured, error) {
+	objs, err := deployer.CustomSplit(string(doc))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		u, err := k8s_utils.GetUnstructured([]byte(obj))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// isEmptyDocument returns true if doc only contains blank lines and comments
+func isEmptyDocument(doc []byte) bool {
+	for line := range bytes.Lines(doc) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// snippet returns the beginning of doc, on a single line
+func snippet(doc []byte) string {
+	text := []rune(strings.Join(strings.Fields(string(doc)), " "))
+	if len(text) <= parseSnippetLength {
+		return string(text)
+	}
+	return string(text[:parseSnippetLength]) + "..."
+}
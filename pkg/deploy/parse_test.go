@@ -0,0 +1,95 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// corruptedDocument is a CRD whose metadata is not valid YAML
+	corruptedDocument = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata: {name: broken.lib.projectsveltos.io
+`
+)
+
+// corruptedBundle returns a bundle with a corrupted document between the
+// first two CRDs of the embedded one
+func corruptedBundle() *bundle.Bundle {
+	var docs []string
+	for _, u := range getBundleCRDs()[:2] {
+		data, err := yaml.Marshal(u.Object)
+		Expect(err).To(BeNil())
+		docs = append(docs, string(data))
+	}
+	content := "# Sveltos CRDs\n---\n" + docs[0] + "---\n" + corruptedDocument + "---\n" + docs[1]
+	return &bundle.Bundle{Content: []byte(content), Source: "https://example.com/crds.yaml"}
+}
+
+var _ = Describe("Bundle parsing", func() {
+	It("applies the valid documents around a corrupted one and reports it", func() {
+		c := newFakeClient()
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: corruptedBundle()}, logger)
+
+		var parseErr *deploy.ParseError
+		Expect(errors.As(err, &parseErr)).To(BeTrue())
+		Expect(parseErr.Failures).To(HaveLen(1))
+		failure := parseErr.Failures[0]
+		Expect(failure.Document).To(Equal(2))
+		Expect(failure.Snippet).To(HavePrefix("apiVersion: apiextensions.k8s.io/v1 kind: CustomResourceDefinition"))
+		Expect(failure.Error).ToNot(BeEmpty())
+		Expect(err).To(MatchError(ContainSubstring("document 2")))
+
+		Expect(report.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(report.ParseFailures).To(Equal(parseErr.Failures))
+		Expect(report.CRDs).To(HaveLen(2))
+		for i := range report.CRDs {
+			Expect(report.CRDs[i].Action).To(Equal(deploy.ActionCreated))
+		}
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(2))
+	})
+
+	It("writes nothing with strict parsing", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{Bundle: corruptedBundle(), StrictParse: true}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+
+		var parseErr *deploy.ParseError
+		Expect(errors.As(err, &parseErr)).To(BeTrue())
+		Expect(report.ParseFailures).To(HaveLen(1))
+		Expect(report.CRDs).To(BeEmpty())
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+})
@@ -0,0 +1,218 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// PatchType is the kind of patch applied to a bundle CRD
+type PatchType string
+
+const (
+	// PatchTypeStrategicMerge is a strategic-merge patch
+	PatchTypeStrategicMerge = PatchType("strategic-merge")
+
+	// PatchTypeJSON6902 is a RFC 6902 JSON patch
+	PatchTypeJSON6902 = PatchType("json6902")
+)
+
+// Patch is an environment specific change applied to a bundle CRD before it
+// is deployed
+type Patch struct {
+	// CRD is the name of the CRD to patch. It must be part of the bundle.
+	CRD string `json:"crd"`
+
+	// Type of the patch. Defaults to strategic-merge.
+	Type PatchType `json:"type,omitempty"`
+
+	// Patch is the strategic-merge patch body
+	Patch json.RawMessage `json:"patch,omitempty"`
+
+	// Ops is the list of RFC 6902 operations of a json6902 patch
+	Ops json.RawMessage `json:"ops,omitempty"`
+}
+
+// LoadPatches reads and validates the list of patches in path
+func LoadPatches(path string) ([]Patch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch file: %w", err)
+	}
+	patches, err := ParsePatches(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch file %s: %w", path, err)
+	}
+	return patches, nil
+}
+
+// ParsePatches parses and validates a YAML list of patches
+func ParsePatches(data []byte) ([]Patch, error) {
+	var patches []Patch
+	if err := yaml.UnmarshalStrict(data, &patches); err != nil {
+		return nil, err
+	}
+	for i := range patches {
+		if err := patches[i].validate(); err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+	return patches, nil
+}
+
+func (p *Patch) validate() error {
+	if p.CRD == "" {
+		return errors.New("crd: required")
+	}
+	switch p.Type {
+	case "", PatchTypeStrategicMerge:
+		if len(p.Patch) == 0 {
+			return errors.New("patch: required")
+		}
+		if len(p.Ops) != 0 {
+			return errors.New("ops: only supported by json6902 patches")
+		}
+	case PatchTypeJSON6902:
+		if len(p.Patch) != 0 {
+			return errors.New("patch: not supported by json6902 patches, use ops")
+		}
+		return validateOps(p.Ops)
+	default:
+		return fmt.Errorf("type: unsupported value %q, expected %s or %s", p.Type,
+			PatchTypeStrategicMerge, PatchTypeJSON6902)
+	}
+	return nil
+}
+
+// validateOps verifies ops is a list of well formed RFC 6902 operations
+func validateOps(ops json.RawMessage) error {
+	if len(ops) == 0 {
+		return errors.New("ops: required")
+	}
+	var patch jsonpatch.Patch
+	if err := json.Unmarshal(ops, &patch); err != nil {
+		return fmt.Errorf("ops: %w", err)
+	}
+	for i := range patch {
+		switch patch[i].Kind() {
+		case "add", "remove", "replace", "move", "copy", "test":
+		default:
+			return fmt.Errorf("ops[%d].op: unsupported value %q", i, patch[i].Kind())
+		}
+		if _, err := patch[i].Path(); err != nil {
+			return fmt.Errorf("ops[%d].path: required", i)
+		}
+	}
+	return nil
+}
+
+// applyPatches applies, in order, all the patches targeting the CRD
+func applyPatches(u *unstructured.Unstructured, patches []Patch, a *audit) error {
+	for i := range patches {
+		if patches[i].CRD != u.GetName() {
+			continue
+		}
+		if err := applyPatch(u, &patches[i]); err != nil {
+			return fmt.Errorf("failed to apply patch %d to CRD %s: %w", i, u.GetName(), err)
+		}
+		a.addModification("patch %d applied", i)
+	}
+	return nil
+}
+
+func applyPatch(u *unstructured.Unstructured, patch *Patch) error {
+	original, err := json.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if patch.Type == PatchTypeJSON6902 {
+		patched, err = applyOps(original, patch.Ops)
+	} else {
+		patched, err = strategicpatch.StrategicMergePatch(original, patch.Patch, &apiextensionsv1.CustomResourceDefinition{})
+	}
+	if err != nil {
+		return err
+	}
+
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return err
+	}
+	if err := validatePatchedCRD(u, result); err != nil {
+		return err
+	}
+
+	u.Object = result.Object
+	return nil
+}
+
+// applyOps applies, one at a time, RFC 6902 operations to doc so that a
+// failure can be reported with the operation index and path
+func applyOps(doc []byte, ops json.RawMessage) ([]byte, error) {
+	var patch jsonpatch.Patch
+	if err := json.Unmarshal(ops, &patch); err != nil {
+		return nil, err
+	}
+	var err error
+	for i := range patch {
+		doc, err = jsonpatch.Patch{patch[i]}.Apply(doc)
+		if err != nil {
+			path, _ := patch[i].Path()
+			return nil, fmt.Errorf("op %d (%s %s): %w", i, patch[i].Kind(), path, err)
+		}
+	}
+	return doc, nil
+}
+
+// validatePatchedCRD verifies a patch did not turn the CRD into something else
+func validatePatchedCRD(original, patched *unstructured.Unstructured) error {
+	if patched.GroupVersionKind() != original.GroupVersionKind() {
+		return fmt.Errorf("patch changes apiVersion/kind to %s", patched.GroupVersionKind())
+	}
+	if patched.GetName() != original.GetName() {
+		return fmt.Errorf("patch changes name to %s", patched.GetName())
+	}
+	if _, err := toCustomResourceDefinition(patched); err != nil {
+		return fmt.Errorf("patched object is not a valid CustomResourceDefinition: %w", err)
+	}
+	return nil
+}
+
+// validatePatchTargets verifies every patch targets a CRD of the bundle
+func validatePatchTargets(patches []Patch, crds []*bundleCRD) error {
+	names := make(map[string]bool, len(crds))
+	for _, b := range crds {
+		names[b.desired.GetName()] = true
+	}
+	for i := range patches {
+		if !names[patches[i].CRD] {
+			return fmt.Errorf("patch %d targets CRD %s which is not in the bundle", i, patches[i].CRD)
+		}
+	}
+	return nil
+}
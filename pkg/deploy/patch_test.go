@@ -0,0 +1,219 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+func parsePatches(content string) []deploy.Patch {
+	patches, err := deploy.ParsePatches([]byte(content))
+	Expect(err).To(BeNil())
+	return patches
+}
+
+var _ = Describe("Patches", func() {
+	DescribeTable("invalid patch files are rejected",
+		func(content, location string) {
+			_, err := deploy.ParsePatches([]byte(content))
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(location))
+		},
+		Entry("missing crd", "- patch:\n    metadata:\n      labels:\n        a: b\n", "[0]: crd"),
+		Entry("missing patch", "- crd: widgets.lib.projectsveltos.io\n", "[0]: patch"),
+		Entry("unsupported type", "- crd: widgets.lib.projectsveltos.io\n  type: merge\n  patch: {}\n", "[0]: type"),
+		Entry("json6902 without ops", "- crd: widgets.lib.projectsveltos.io\n  type: json6902\n", "[0]: ops"),
+		Entry("json6902 with unsupported op",
+			"- crd: widgets.lib.projectsveltos.io\n  type: json6902\n  ops:\n  - op: delete\n    path: /spec\n",
+			"[0]: ops[0].op"),
+		Entry("json6902 op without path",
+			"- crd: widgets.lib.projectsveltos.io\n  type: json6902\n  ops:\n  - op: add\n    path: /a\n    value: 1\n  - op: remove\n",
+			"[0]: ops[1].path"),
+	)
+
+	It("applies strategic-merge patches to the targeted CRD", func() {
+		u := parse(crdWithoutWebhook)
+		opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  patch:
+    metadata:
+      annotations:
+        environment: staging
+    spec:
+      names:
+        shortNames:
+        - wdg
+`)}
+
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u.GetAnnotations()).To(HaveKeyWithValue("environment", "staging"))
+		shortNames, _, err := unstructured.NestedStringSlice(u.Object, "spec", "names", "shortNames")
+		Expect(err).To(BeNil())
+		Expect(shortNames).To(Equal([]string{"wdg"}))
+		Expect(getAudit(u)).To(ContainElement("patch 0 applied"))
+	})
+
+	It("ignores patches targeting other CRDs", func() {
+		u := parse(crdWithoutWebhook)
+		original := u.DeepCopy()
+		opts := &deploy.Options{Patches: parsePatches(`- crd: other.lib.projectsveltos.io
+  patch:
+    metadata:
+      annotations:
+        environment: staging
+`)}
+
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(u).To(Equal(original))
+	})
+
+	It("rejects patches changing the kind", func() {
+		u := parse(crdWithoutWebhook)
+		opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  patch:
+    kind: ConfigMap
+`)}
+
+		err := deploy.ApplyMutations(u, opts, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(u.GetName()))
+	})
+
+	It("fails, before any write, when a patch targets a CRD not in the bundle", func() {
+		opts := &deploy.Options{Patches: parsePatches(`- crd: widgets.lib.projectsveltos.io
+  patch:
+    metadata:
+      annotations:
+        environment: staging
+`)}
+
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("not in the bundle"))
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(BeEmpty())
+
+		var out bytes.Buffer
+		Expect(deploy.Template(&out, opts, logger)).ToNot(Succeed())
+	})
+
+	It("patched CRDs are up to date on the next run", func() {
+		opts := &deploy.Options{Patches: parsePatches(`- crd: ` + sveltosClusterCRD + `
+  patch:
+    metadata:
+      annotations:
+        environment: staging
+`)}
+
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Annotations).To(HaveKeyWithValue("environment", "staging"))
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUnchanged))
+	})
+
+	Context("json6902", func() {
+		It("removes a version entry", func() {
+			u := parse(crdWithWebhookMultipleVersions)
+			opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  type: json6902
+  ops:
+  - op: remove
+    path: /spec/versions/0
+`)}
+
+			Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+			versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+			Expect(err).To(BeNil())
+			Expect(versions).To(HaveLen(1))
+			Expect(versions[0]).To(HaveKeyWithValue("name", "v1beta1"))
+		})
+
+		It("replaces and adds nested schema fields", func() {
+			u := parse(crdWithWebhookMultipleVersions)
+			opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  type: json6902
+  ops:
+  - op: replace
+    path: /spec/versions/1/schema/openAPIV3Schema/properties/spec/properties/replicas/type
+    value: string
+  - op: add
+    path: /spec/versions/1/schema/openAPIV3Schema/properties/spec/properties/paused
+    value:
+      type: boolean
+`)}
+
+			Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+			properties, _, err := unstructured.NestedMap(getVersion(u, "v1beta1"),
+				"schema", "openAPIV3Schema", "properties", "spec", "properties")
+			Expect(err).To(BeNil())
+			Expect(properties).To(HaveKeyWithValue("replicas", map[string]interface{}{"type": "string"}))
+			Expect(properties).To(HaveKeyWithValue("paused", map[string]interface{}{"type": "boolean"}))
+			Expect(getAudit(u)).To(ContainElement("patch 0 applied"))
+		})
+
+		It("reports the failing op index and path", func() {
+			u := parse(crdWithWebhookMultipleVersions)
+			opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  type: json6902
+  ops:
+  - op: add
+    path: /metadata/labels
+    value: {}
+  - op: replace
+    path: /spec/versions/5/served
+    value: false
+`)}
+
+			err := deploy.ApplyMutations(u, opts, logger)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("op 1 (replace /spec/versions/5/served)"))
+			Expect(err.Error()).To(ContainSubstring(u.GetName()))
+		})
+
+		It("rejects patches leaving an invalid CRD", func() {
+			u := parse(crdWithWebhookMultipleVersions)
+			opts := &deploy.Options{Patches: parsePatches(`- crd: ` + u.GetName() + `
+  type: json6902
+  ops:
+  - op: replace
+    path: /spec/versions
+    value: not-a-list
+`)}
+
+			Expect(deploy.ApplyMutations(u, opts, logger)).ToNot(Succeed())
+		})
+	})
+})
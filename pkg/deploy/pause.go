@@ -0,0 +1,60 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapNamespace is the namespace of the well-known crd-manager ConfigMap
+	ConfigMapNamespace = "projectsveltos"
+
+	// ConfigMapName is the name of the well-known crd-manager ConfigMap
+	ConfigMapName = "crd-manager-config"
+
+	// PausedAnnotation, set to "true" on the well-known ConfigMap, makes
+	// crd-manager skip all writes until it is removed. Set on a live CRD, it
+	// only freezes that CRD.
+	PausedAnnotation = "projectsveltos.io/paused"
+)
+
+// isPaused returns true if the well-known ConfigMap asks crd-manager to stand down.
+// A missing ConfigMap means crd-manager is not paused. Observe-only runs are
+// paused as well, so that they report what a run would do, but only need read
+// access to CRDs: not being allowed to read the ConfigMap means not paused.
+func isPaused(ctx context.Context, c client.Client, opts *Options) (bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ConfigMapNamespace, Name: ConfigMapName}, configMap)
+	if err != nil {
+		if apierrors.IsNotFound(err) || (!opts.writes() && apierrors.IsForbidden(err)) {
+			return false, nil
+		}
+		return false, err
+	}
+	return configMap.GetAnnotations()[PausedAnnotation] == "true", nil
+}
+
+// isCRDPaused returns true if the live CRD carries the PausedAnnotation
+func isCRDPaused(crd client.Object) bool {
+	return crd.GetAnnotations()[PausedAnnotation] == "true"
+}
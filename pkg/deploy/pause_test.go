@@ -0,0 +1,97 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+func pauseConfigMap(paused string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   deploy.ConfigMapNamespace,
+			Name:        deploy.ConfigMapName,
+			Annotations: map[string]string{deploy.PausedAnnotation: paused},
+		},
+	}
+}
+
+var _ = Describe("Pause", func() {
+	It("skips all writes while paused", func() {
+		c := newFakeClient(pauseConfigMap("true"))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusPaused))
+		Expect(report.CRDs).To(BeEmpty())
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(BeEmpty())
+	})
+
+	It("resumes on the next run once unpaused", func() {
+		configMap := pauseConfigMap("true")
+		c := newFakeClient(configMap)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusPaused))
+
+		configMap.Annotations[deploy.PausedAnnotation] = "false"
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(getBundleCRDs())))
+	})
+
+	It("leaves a paused CRD untouched and resumes once the annotation is removed", func() {
+		crd := outdatedCRD()
+		crd.Annotations = map[string]string{deploy.PausedAnnotation: "true"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionPaused))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs) - 1))
+
+		current := getCRD(c, sveltosClusterCRD)
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"sc"}))
+
+		delete(current.Annotations, deploy.PausedAnnotation)
+		Expect(c.Update(context.TODO(), current)).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+		Expect(getCRD(c, sveltosClusterCRD).Spec.Names.ShortNames).ToNot(Equal([]string{"sc"}))
+	})
+})
@@ -0,0 +1,63 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+const (
+	// PinBundleVersionAnnotation, set on a live CRD to a Sveltos version
+	// (e.g. v0.38.0), holds that CRD at the version: it is only updated by
+	// runs deploying a bundle of that version
+	PinBundleVersionAnnotation = "projectsveltos.io/pin-bundle-version"
+)
+
+// pinnedVersion returns the version the live CRD is pinned at, if any
+func pinnedVersion(crd client.Object) string {
+	return crd.GetAnnotations()[PinBundleVersionAnnotation]
+}
+
+// isPinnedAway returns true if the live CRD is pinned at a version other than
+// the one of the bundle being deployed. An unknown bundle version does not
+// match any pin.
+func isPinnedAway(crd client.Object, opts *Options) bool {
+	pin := pinnedVersion(crd)
+	version := opts.getBundle().Version()
+	return pin != "" && (pin != version || version == bundle.UnknownVersion)
+}
+
+// skipPinnedCRD leaves live, pinned at another version than the bundle one,
+// untouched
+func skipPinnedCRD(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured, opts *Options,
+	result *CRDResult, logger logr.Logger) error {
+
+	logWarning(logger, "Sveltos CRD %s is pinned at version %s by the %s annotation, bundle version is %s: "+
+		"skipping", u.GetName(), result.PinnedVersion, PinBundleVersionAnnotation, opts.getBundle().Version())
+	result.Action = ActionPinned
+	if err := setDrift(live, u, result); err != nil {
+		return fmt.Errorf("failed to compare pinned CRD %s with the bundle: %w", u.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Pin bundle version", func() {
+	// pinnedCRD returns the outdated CRD named name, pinned at version
+	pinnedCRD := func(name, version string) *apiextensionsv1.CustomResourceDefinition {
+		crd := getBundleCRD(name)
+		crd.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+		crd.Annotations = map[string]string{deploy.PinBundleVersionAnnotation: version}
+		crd.Spec.Names.ShortNames = []string{"pinned"}
+		return crd
+	}
+
+	It("CRDs pinned at another version are left untouched and reported pinned", func() {
+		c := newFakeClient(pinnedCRD(sveltosClusterCRD, "v0.38.0"))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionPinned))
+		Expect(result.PinnedVersion).To(Equal("v0.38.0"))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+		Expect(report.Count(deploy.ActionPinned)).To(Equal(1))
+
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, current)).To(Succeed())
+		Expect(current.Spec.Names.ShortNames).To(Equal([]string{"pinned"}))
+	})
+
+	It("CRDs pinned at the bundle version are managed, the pin being reported", func() {
+		version := bundle.Embedded().Version()
+		Expect(version).ToNot(Equal(bundle.UnknownVersion))
+		c := newFakeClient(pinnedCRD(sveltosClusterCRD, version))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+		Expect(result.PinnedVersion).To(Equal(version))
+	})
+
+	It("pinned CRDs are not updated from bundles of unknown version", func() {
+		name := getBundleCRDs()[0].GetName()
+		c := newFakeClient(pinnedCRD(name, bundle.UnknownVersion))
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: subsetBundle(1)}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionPinned))
+	})
+
+	It("pins are reported in observe-only mode", func() {
+		c := newFakeClient(pinnedCRD(sveltosClusterCRD, "v0.38.0"))
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{ObserveOnly: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).PinnedVersion).To(Equal("v0.38.0"))
+	})
+})
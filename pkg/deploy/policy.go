@@ -0,0 +1,242 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// OwnershipAction is what an ownership policy tells crd-manager to do with a CRD
+type OwnershipAction string
+
+const (
+	// OwnershipManage updates the CRD unless foreign ownership is detected
+	OwnershipManage = OwnershipAction("manage")
+
+	// OwnershipSkip never touches the CRD
+	OwnershipSkip = OwnershipAction("skip")
+
+	// OwnershipAdopt updates the CRD even when foreign ownership is detected
+	OwnershipAdopt = OwnershipAction("adopt")
+)
+
+// OwnershipMarkers lists the labels and annotations indicating a CRD is owned
+// by another tool. Each entry is either a key, matching any value, or key=value.
+type OwnershipMarkers struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// OwnershipRule decides whether crd-manager manages a CRD
+type OwnershipRule struct {
+	// Name of the CRD the rule applies to. Not set for the default rule.
+	Name string `json:"name,omitempty"`
+
+	// Action to take. When empty, the default rule action is used.
+	Action OwnershipAction `json:"action,omitempty"`
+
+	// ForeignMarkers, when set, replace the built-in heuristics (Helm, Argo CD)
+	// to detect foreign ownership. When nil, the default rule markers are used.
+	ForeignMarkers *OwnershipMarkers `json:"foreignMarkers,omitempty"`
+}
+
+// OwnershipPolicy decides, per CRD, whether crd-manager manages, skips or
+// adopts it. It takes precedence over the built-in ownership heuristics.
+type OwnershipPolicy struct {
+	// Default applies to CRDs without a specific rule
+	Default OwnershipRule `json:"default"`
+
+	// CRDs contains per CRD rules
+	CRDs []OwnershipRule `json:"crds,omitempty"`
+}
+
+// LoadOwnershipPolicy reads and validates the ownership policy in path
+func LoadOwnershipPolicy(path string) (*OwnershipPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ownership policy: %w", err)
+	}
+	policy, err := ParseOwnershipPolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ownership policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ParseOwnershipPolicy parses and validates a YAML ownership policy
+func ParseOwnershipPolicy(data []byte) (*OwnershipPolicy, error) {
+	policy := &OwnershipPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, err
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *OwnershipPolicy) validate() error {
+	if p.Default.Name != "" {
+		return errors.New("default.name: must not be set")
+	}
+	if err := p.Default.validate("default"); err != nil {
+		return err
+	}
+
+	names := make(map[string]int, len(p.CRDs))
+	for i := range p.CRDs {
+		location := fmt.Sprintf("crds[%d]", i)
+		if p.CRDs[i].Name == "" {
+			return fmt.Errorf("%s.name: required", location)
+		}
+		if previous, ok := names[p.CRDs[i].Name]; ok {
+			return fmt.Errorf("%s.name: %s already has a rule at crds[%d]", location, p.CRDs[i].Name, previous)
+		}
+		names[p.CRDs[i].Name] = i
+		if err := p.CRDs[i].validate(location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OwnershipRule) validate(location string) error {
+	switch r.Action {
+	case "", OwnershipManage, OwnershipSkip, OwnershipAdopt:
+	default:
+		return fmt.Errorf("%s.action: unsupported value %q, expected %s, %s or %s", location, r.Action,
+			OwnershipManage, OwnershipSkip, OwnershipAdopt)
+	}
+
+	if r.ForeignMarkers == nil {
+		return nil
+	}
+	for i, marker := range r.ForeignMarkers.Labels {
+		if key, _, _ := strings.Cut(marker, "="); key == "" {
+			return fmt.Errorf("%s.foreignMarkers.labels[%d]: empty key", location, i)
+		}
+	}
+	for i, marker := range r.ForeignMarkers.Annotations {
+		if key, _, _ := strings.Cut(marker, "="); key == "" {
+			return fmt.Errorf("%s.foreignMarkers.annotations[%d]: empty key", location, i)
+		}
+	}
+	return nil
+}
+
+// ruleFor returns the effective rule for the CRD named name: its specific rule,
+// with unset fields taken from the default rule
+func (p *OwnershipPolicy) ruleFor(name string) OwnershipRule {
+	rule := OwnershipRule{Name: name, Action: p.Default.Action, ForeignMarkers: p.Default.ForeignMarkers}
+	for i := range p.CRDs {
+		if p.CRDs[i].Name != name {
+			continue
+		}
+		if p.CRDs[i].Action != "" {
+			rule.Action = p.CRDs[i].Action
+		}
+		if p.CRDs[i].ForeignMarkers != nil {
+			rule.ForeignMarkers = p.CRDs[i].ForeignMarkers
+		}
+	}
+	if rule.Action == "" {
+		rule.Action = OwnershipManage
+	}
+	return rule
+}
+
+// matchMarkers returns the first marker in markers found in values, if any
+func matchMarkers(markers []string, values map[string]string) string {
+	for _, marker := range markers {
+		key, value, hasValue := strings.Cut(marker, "=")
+		current, ok := values[key]
+		if ok && (!hasValue || current == value) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// foreignManager returns the tool owning crd according to the rule markers
+func (m *OwnershipMarkers) foreignManager(crd *apiextensionsv1.CustomResourceDefinition) *externalManager {
+	marker := matchMarkers(m.Labels, crd.GetLabels())
+	if marker == "" {
+		marker = matchMarkers(m.Annotations, crd.GetAnnotations())
+	}
+	if marker == "" {
+		return nil
+	}
+	return &externalManager{
+		description: fmt.Sprintf("another tool (marker %s)", marker),
+		action:      ActionSkippedPolicy,
+		remediation: "Either update it with the tool owning it, adopt it in the ownership policy " +
+			"or run crd-manager with --force-ownership",
+	}
+}
+
+// resolveOwnership returns the tool owning live, or nil when crd-manager must
+// update it
+func resolveOwnership(live *apiextensionsv1.CustomResourceDefinition, opts *Options,
+	logger logr.Logger) *externalManager {
+
+	live = withoutOwnHelmMarkers(live, &opts.HelmRelease)
+	if opts.OwnershipPolicy == nil {
+		if opts.ForceOwnership {
+			return nil
+		}
+		return externalManagerOf(live)
+	}
+
+	rule := opts.OwnershipPolicy.ruleFor(live.GetName())
+	var manager *externalManager
+	switch rule.Action {
+	case OwnershipSkip:
+		manager = &externalManager{
+			description: "the ownership policy",
+			action:      ActionSkippedPolicy,
+			remediation: "Change its ownership policy action to manage or adopt",
+		}
+	case OwnershipAdopt:
+		// updated regardless of any foreign ownership marker
+	default:
+		if rule.ForeignMarkers != nil {
+			manager = rule.ForeignMarkers.foreignManager(live)
+		} else {
+			manager = externalManagerOf(live)
+		}
+		if opts.ForceOwnership {
+			manager = nil
+		}
+	}
+
+	decision := "update"
+	if manager != nil {
+		decision = "skip, managed by " + manager.description
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("ownership policy: CRD %s, action %s: %s",
+		live.GetName(), rule.Action, decision))
+	return manager
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Ownership policy", func() {
+	DescribeTable("invalid policies are rejected with their location",
+		func(policy, location string) {
+			_, err := deploy.ParseOwnershipPolicy([]byte(policy))
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(location))
+		},
+		Entry("unsupported action", "default:\n  action: delete\n", "default.action"),
+		Entry("rule without name", "crds:\n- action: skip\n", "crds[0].name"),
+		Entry("duplicated rule", "crds:\n- name: a\n- name: a\n", "crds[1].name"),
+		Entry("empty marker key", "crds:\n- name: a\n  foreignMarkers:\n    labels: [\"=x\"]\n",
+			"crds[0].foreignMarkers.labels[0]"),
+		Entry("unknown field", "crds:\n- name: a\n  actoin: skip\n", "actoin"),
+	)
+
+	It("skip rules leave CRDs untouched, even with ForceOwnership", func() {
+		policy, err := deploy.ParseOwnershipPolicy([]byte("crds:\n- name: " + sveltosClusterCRD + "\n  action: skip\n"))
+		Expect(err).To(BeNil())
+
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{OwnershipPolicy: policy, ForceOwnership: true}, logger)
+		Expect(err).To(BeNil())
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionSkippedPolicy))
+		Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+	})
+
+	It("adopt rules update Helm managed CRDs", func() {
+		policy, err := deploy.ParseOwnershipPolicy([]byte("crds:\n- name: " + sveltosClusterCRD + "\n  action: adopt\n"))
+		Expect(err).To(BeNil())
+
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		crd.Spec.Names.ShortNames = []string{"sc"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{OwnershipPolicy: policy}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+	})
+
+	It("foreign markers replace the built-in heuristics", func() {
+		policy, err := deploy.ParseOwnershipPolicy([]byte(`default:
+  action: manage
+  foreignMarkers:
+    labels:
+    - olm.managed=true
+`))
+		Expect(err).To(BeNil())
+
+		olmCRD := getBundleCRD(sveltosClusterCRD)
+		olmCRD.Labels = map[string]string{"olm.managed": "true"}
+		c := newFakeClient(olmCRD)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{OwnershipPolicy: policy}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionSkippedPolicy))
+
+		helmCRD := getBundleCRD(sveltosClusterCRD)
+		helmCRD.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		helmCRD.Spec.Names.ShortNames = []string{"sc"}
+		c = newFakeClient(helmCRD)
+
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{OwnershipPolicy: policy}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+	})
+
+	It("CRDs without a rule follow the built-in heuristics by default", func() {
+		policy, err := deploy.ParseOwnershipPolicy([]byte("crds:\n- name: other.lib.projectsveltos.io\n  action: skip\n"))
+		Expect(err).To(BeNil())
+
+		crd := getBundleCRD(sveltosClusterCRD)
+		crd.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{OwnershipPolicy: policy}, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionSkippedHelm))
+	})
+})
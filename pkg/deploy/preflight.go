@@ -0,0 +1,109 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NameConflict describes a name a bundle CRD wants but another CRD, already
+// present in the cluster, claims
+type NameConflict struct {
+	// CRD is the name of the bundle CRD
+	CRD string `json:"crd"`
+
+	// ConflictingCRD is the name of the CRD, already in the cluster, claiming the name
+	ConflictingCRD string `json:"conflictingCRD"`
+
+	// Group the names belong to
+	Group string `json:"group"`
+
+	// Name is the conflicting name (plural, singular, shortName, kind or listKind)
+	Name string `json:"name"`
+}
+
+// crdNames returns all the names (plural, singular, short names, kind and list
+// kind) a CRD claims within its group
+func crdNames(names *apiextensionsv1.CustomResourceDefinitionNames) []string {
+	result := []string{names.Plural, names.Kind}
+	if names.Singular != "" {
+		result = append(result, names.Singular)
+	}
+	if names.ListKind != "" {
+		result = append(result, names.ListKind)
+	}
+	return append(result, names.ShortNames...)
+}
+
+// checkNameConflicts lists the CRDs in the cluster and reports any name claimed
+// by one of them that a different bundle CRD also claims. The API server would
+// otherwise only set NamesAccepted=False on the bundle CRD after it is written.
+func checkNameConflicts(ctx context.Context, c client.Client, crds []*bundleCRD,
+	logger logr.Logger) ([]NameConflict, error) {
+
+	existing := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.List(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	// index, per group, which existing CRD claims each name
+	claimed := map[string]map[string]string{}
+	for i := range existing.Items {
+		crd := &existing.Items[i]
+		if claimed[crd.Spec.Group] == nil {
+			claimed[crd.Spec.Group] = map[string]string{}
+		}
+		for _, name := range crdNames(&crd.Spec.Names) {
+			claimed[crd.Spec.Group][name] = crd.Name
+		}
+	}
+
+	var conflicts []NameConflict
+	for _, b := range crds {
+		if b.err != nil {
+			continue
+		}
+		crd, err := toCustomResourceDefinition(b.desired)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range crdNames(&crd.Spec.Names) {
+			owner, ok := claimed[crd.Spec.Group][name]
+			if !ok || owner == crd.Name {
+				continue
+			}
+			conflicts = append(conflicts, NameConflict{
+				CRD: crd.Name, ConflictingCRD: owner, Group: crd.Spec.Group, Name: name,
+			})
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].CRD < conflicts[j].CRD
+	})
+	for i := range conflicts {
+		logWarning(logger, "Sveltos CRD %s name %q (group %s) is already claimed by CRD %s",
+			conflicts[i].CRD, conflicts[i].Name, conflicts[i].Group, conflicts[i].ConflictingCRD)
+	}
+
+	return conflicts, nil
+}
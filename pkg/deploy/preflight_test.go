@@ -0,0 +1,88 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Name conflicts", func() {
+	var conflicting *apiextensionsv1.CustomResourceDefinition
+
+	BeforeEach(func() {
+		bundleCRD := getBundleCRD(sveltosClusterCRD)
+		conflicting = &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "otherclusters.lib.projectsveltos.io"},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: bundleCRD.Spec.Group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural:     "otherclusters",
+					Kind:       "OtherCluster",
+					ShortNames: []string{bundleCRD.Spec.Names.Singular},
+				},
+				Scope: apiextensionsv1.NamespaceScoped,
+			},
+		}
+	})
+
+	It("conflicts are reported but do not block by default", func() {
+		c := newFakeClient(conflicting)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.NameConflicts).To(Equal([]deploy.NameConflict{
+			{
+				CRD:            sveltosClusterCRD,
+				ConflictingCRD: conflicting.Name,
+				Group:          conflicting.Spec.Group,
+				Name:           conflicting.Spec.Names.ShortNames[0],
+			},
+		}))
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionCreated))
+	})
+
+	It("conflicts block all writes with FailOnNameConflicts", func() {
+		c := newFakeClient(conflicting)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{FailOnNameConflicts: true}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(report.NameConflicts).To(HaveLen(1))
+		Expect(report.CRDs).To(BeEmpty())
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: sveltosClusterCRD}, crd)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("CRDs already deployed do not conflict with themselves", func() {
+		c := newFakeClient(getBundleCRD(sveltosClusterCRD))
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{FailOnNameConflicts: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.NameConflicts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,256 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// eachSuffix marks a path segment whose list elements are each descended
+// into, live and bundle elements being matched by name
+const eachSuffix = "[*]"
+
+// unpreservable are the paths identifying a CRD or its resources: merging
+// live values into them would rename resources or make storage ambiguous
+var unpreservable = []string{
+	"spec", "spec.group", "spec.scope", "spec.names", "spec.names.plural", "spec.names.singular",
+	"spec.names.kind", "spec.names.listKind", "spec.versions", "spec.versions[*]", "spec.versions[*].name",
+	"spec.versions[*].storage",
+}
+
+// PreservePath is a path, within the CRD spec, whose live values are merged
+// with the bundle ones instead of being replaced by them
+type PreservePath struct {
+	path     string
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	field string
+
+	// each is set when every element of the field, a list, is descended into
+	each bool
+}
+
+// ParsePreservePaths parses values, each in the form spec.names.shortNames or,
+// to descend into every element of a list of named objects,
+// spec.versions[*].additionalPrinterColumns
+func ParsePreservePaths(values []string) ([]PreservePath, error) {
+	paths := make([]PreservePath, 0, len(values))
+	for _, value := range values {
+		if slices.Contains(unpreservable, value) {
+			return nil, fmt.Errorf("path %s cannot be preserved: it identifies the CRD or its resources", value)
+		}
+		fields := strings.Split(value, ".")
+		if len(fields) < 2 || fields[0] != "spec" {
+			return nil, fmt.Errorf("invalid path %q: expected a path within the CRD spec, e.g. spec.names.shortNames",
+				value)
+		}
+
+		path := PreservePath{path: value}
+		for _, field := range fields[1:] {
+			segment := pathSegment{field: strings.TrimSuffix(field, eachSuffix)}
+			segment.each = segment.field != field
+			if segment.field == "" || strings.ContainsAny(segment.field, "[]*") {
+				return nil, fmt.Errorf("invalid path %q: invalid segment %q", value, field)
+			}
+			path.segments = append(path.segments, segment)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (p *PreservePath) String() string {
+	return p.path
+}
+
+// mergeLive merges into u what opts retains from live: with MergeVersions,
+// the versions the bundle dropped and the Preserve paths
+func mergeLive(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured, opts *Options,
+	logger logr.Logger) error {
+
+	if opts.MergeVersions {
+		if err := mergeVersions(live, u, logger); err != nil {
+			return err
+		}
+	}
+	if len(opts.Preserve) == 0 {
+		return nil
+	}
+	return preserveFields(live, u, opts.Preserve, logger)
+}
+
+// preserveFields merges, at every path, the values of live into u: lists are
+// the union of the bundle and the live elements (named objects being matched
+// by name, the bundle definition winning), objects get the live keys the
+// bundle does not set and scalars keep the live value
+func preserveFields(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	paths []PreservePath, logger logr.Logger) error {
+
+	liveSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&live.Spec)
+	if err != nil {
+		return err
+	}
+	desiredSpec, _, err := unstructured.NestedMap(u.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	for i := range paths {
+		before := runtime.DeepCopyJSONValue(desiredSpec)
+		desiredSpec = preserveAt(liveSpec, desiredSpec, paths[i].segments).(map[string]interface{})
+		if !reflect.DeepEqual(before, desiredSpec) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s: live %s preserved", u.GetName(), paths[i].String()))
+		}
+	}
+	return unstructured.SetNestedMap(u.Object, desiredSpec, "spec")
+}
+
+// preserveAt returns desired with the live values at segments merged in
+func preserveAt(live, desired interface{}, segments []pathSegment) interface{} {
+	if len(segments) == 0 {
+		return mergePreserved(live, desired)
+	}
+
+	liveMap, _ := live.(map[string]interface{})
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok || liveMap == nil {
+		// The parent is missing, or not an object, on either side: nothing to merge
+		return desired
+	}
+
+	segment := segments[0]
+	var merged interface{}
+	if segment.each {
+		merged = preserveEach(liveMap[segment.field], desiredMap[segment.field], segments[1:])
+	} else {
+		merged = preserveAt(liveMap[segment.field], desiredMap[segment.field], segments[1:])
+	}
+	if merged != nil {
+		desiredMap[segment.field] = merged
+	}
+	return desiredMap
+}
+
+// preserveEach merges the live values at segments into every element of the
+// desired list having a same-named live element
+func preserveEach(live, desired interface{}, segments []pathSegment) interface{} {
+	liveList, _ := live.([]interface{})
+	desiredList, ok := desired.([]interface{})
+	if !ok {
+		return desired
+	}
+	for i := range desiredList {
+		name, ok := elementName(desiredList[i])
+		if !ok {
+			continue
+		}
+		for j := range liveList {
+			if liveName, ok := elementName(liveList[j]); ok && liveName == name {
+				desiredList[i] = preserveAt(liveList[j], desiredList[i], segments)
+			}
+		}
+	}
+	return desiredList
+}
+
+// mergePreserved merges a live value into the desired one
+func mergePreserved(live, desired interface{}) interface{} {
+	if live == nil {
+		return desired
+	}
+	if desired == nil {
+		return runtime.DeepCopyJSONValue(live)
+	}
+
+	switch desiredValue := desired.(type) {
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			return desired
+		}
+		return unionList(liveList, desiredValue)
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return desired
+		}
+		for key, value := range liveMap {
+			if _, ok := desiredValue[key]; !ok {
+				desiredValue[key] = runtime.DeepCopyJSONValue(value)
+			}
+		}
+		return desiredValue
+	default:
+		return runtime.DeepCopyJSONValue(live)
+	}
+}
+
+// unionList returns the desired elements followed by the live ones missing
+// from desired. Named objects are matched by name, other elements by value.
+// When the union holds the same elements as live, live is returned so that
+// its order is kept and no update is needed.
+func unionList(live, desired []interface{}) []interface{} {
+	union := slices.Clone(desired)
+	for i := range live {
+		if !slices.ContainsFunc(desired, func(element interface{}) bool { return sameElement(live[i], element) }) {
+			union = append(union, runtime.DeepCopyJSONValue(live[i]))
+		}
+	}
+
+	if len(union) != len(live) {
+		return union
+	}
+	for i := range union {
+		if !slices.ContainsFunc(live, func(element interface{}) bool { return reflect.DeepEqual(union[i], element) }) {
+			return union
+		}
+	}
+	return runtime.DeepCopyJSONValue(live).([]interface{})
+}
+
+// sameElement returns true if a and b are the same list element: objects with
+// the same name or equal values
+func sameElement(a, b interface{}) bool {
+	aName, aNamed := elementName(a)
+	bName, bNamed := elementName(b)
+	if aNamed && bNamed {
+		return aName == bName
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// elementName returns the name of element, if it is a named object
+func elementName(element interface{}) (string, bool) {
+	object, ok := element.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	name, ok := object["name"].(string)
+	return name, ok
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Preserve", func() {
+	toCRD := func(u *unstructured.Unstructured) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd)).To(Succeed())
+		return crd
+	}
+
+	preservePaths := func(values ...string) []deploy.PreservePath {
+		paths, err := deploy.ParsePreservePaths(values)
+		Expect(err).To(BeNil())
+		return paths
+	}
+
+	column := func(name, jsonPath string) apiextensionsv1.CustomResourceColumnDefinition {
+		return apiextensionsv1.CustomResourceColumnDefinition{Name: name, Type: "string", JSONPath: jsonPath}
+	}
+
+	It("ParsePreservePaths rejects paths outside the spec or identifying the CRD", func() {
+		paths := preservePaths("spec.names.shortNames", "spec.versions[*].additionalPrinterColumns")
+		Expect(paths).To(HaveLen(2))
+		Expect(paths[1].String()).To(Equal("spec.versions[*].additionalPrinterColumns"))
+
+		for _, value := range []string{"metadata.labels", "spec", "spec.group", "spec.versions[*].storage",
+			"spec.names.", "spec.versions[0].additionalPrinterColumns"} {
+
+			_, err := deploy.ParsePreservePaths([]string{value})
+			Expect(err).To(HaveOccurred(), value)
+		}
+	})
+
+	It("lists get the union of the bundle and the live elements", func() {
+		live := toCRD(parse(crdWithoutWebhook))
+		live.Spec.Names.ShortNames = []string{"gd", "gdg"}
+		u := parse(crdWithoutWebhook)
+		Expect(unstructured.SetNestedStringSlice(u.Object, []string{"gd", "gadg"}, "spec", "names", "shortNames")).
+			To(Succeed())
+
+		Expect(deploy.PreserveFields(live, u, preservePaths("spec.names.shortNames"), logger)).To(Succeed())
+		Expect(toCRD(u).Spec.Names.ShortNames).To(Equal([]string{"gd", "gadg", "gdg"}))
+	})
+
+	It("lists keep their live order when live already holds every bundle element", func() {
+		live := toCRD(parse(crdWithoutWebhook))
+		live.Spec.Names.Categories = []string{"admin", "sveltos"}
+		u := parse(crdWithoutWebhook)
+		Expect(unstructured.SetNestedStringSlice(u.Object, []string{"sveltos"}, "spec", "names", "categories")).
+			To(Succeed())
+
+		Expect(deploy.PreserveFields(live, u, preservePaths("spec.names.categories"), logger)).To(Succeed())
+		Expect(toCRD(u).Spec.Names.Categories).To(Equal([]string{"admin", "sveltos"}))
+	})
+
+	It("named objects are matched by name, the bundle definition winning", func() {
+		live := toCRD(parse(crdWithWebhookMultipleVersions))
+		live.Spec.Versions[0].AdditionalPrinterColumns = []apiextensionsv1.CustomResourceColumnDefinition{
+			column("Owner", ".metadata.labels.owner")}
+		live.Spec.Versions[1].AdditionalPrinterColumns = []apiextensionsv1.CustomResourceColumnDefinition{
+			column("Replicas", ".status.replicas"), column("Owner", ".metadata.labels.owner")}
+
+		u := parse(crdWithWebhookMultipleVersions)
+		desired := toCRD(u)
+		desired.Spec.Versions[1].AdditionalPrinterColumns = []apiextensionsv1.CustomResourceColumnDefinition{
+			column("Replicas", ".spec.replicas")}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+		Expect(err).To(BeNil())
+		u.Object = content
+
+		Expect(deploy.PreserveFields(live, u, preservePaths("spec.versions[*].additionalPrinterColumns"),
+			logger)).To(Succeed())
+		merged := toCRD(u)
+		Expect(merged.Spec.Versions[0].AdditionalPrinterColumns).To(Equal(
+			[]apiextensionsv1.CustomResourceColumnDefinition{column("Owner", ".metadata.labels.owner")}))
+		Expect(merged.Spec.Versions[1].AdditionalPrinterColumns).To(Equal(
+			[]apiextensionsv1.CustomResourceColumnDefinition{
+				column("Replicas", ".spec.replicas"), column("Owner", ".metadata.labels.owner")}))
+	})
+
+	It("objects get the live keys the bundle does not set and scalars keep the live value", func() {
+		live := toCRD(parse(crdWithWebhookMultipleVersions))
+		live.Spec.Versions[1].Subresources = &apiextensionsv1.CustomResourceSubresources{
+			Status: &apiextensionsv1.CustomResourceSubresourceStatus{}}
+		live.Spec.Versions[1].DeprecationWarning = ptr.To("use v1")
+
+		u := parse(crdWithWebhookMultipleVersions)
+		desired := toCRD(u)
+		desired.Spec.Versions[1].Subresources = &apiextensionsv1.CustomResourceSubresources{
+			Scale: &apiextensionsv1.CustomResourceSubresourceScale{
+				SpecReplicasPath: ".spec.replicas", StatusReplicasPath: ".status.replicas"}}
+		desired.Spec.Versions[1].DeprecationWarning = ptr.To("deprecated")
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+		Expect(err).To(BeNil())
+		u.Object = content
+
+		Expect(deploy.PreserveFields(live, u, preservePaths("spec.versions[*].subresources",
+			"spec.versions[*].deprecationWarning"), logger)).To(Succeed())
+		merged := toCRD(u)
+		Expect(merged.Spec.Versions[1].Subresources.Status).ToNot(BeNil())
+		Expect(merged.Spec.Versions[1].Subresources.Scale).ToNot(BeNil())
+		Expect(*merged.Spec.Versions[1].DeprecationWarning).To(Equal("use v1"))
+		Expect(merged.Spec.Versions[0].Subresources).To(BeNil())
+	})
+
+	It("keeps admin customizations across runs without reporting drift", func() {
+		live := toCRD(parse(crdWithoutWebhook))
+		live.Spec.Names.ShortNames = []string{"gd"}
+		live.Annotations = map[string]string{deploy.BundleHashAnnotation: bundleHash(parse(crdWithoutWebhook))}
+		c := newFakeClient(live)
+		opts := &deploy.Options{
+			Bundle:   &bundle.Bundle{Content: []byte(strings.TrimSpace(crdWithoutWebhook))},
+			Preserve: preservePaths("spec.names.shortNames"),
+		}
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, "gadgets.lib.projectsveltos.io").Action).To(Equal(deploy.ActionUnchanged))
+
+		opts.ObserveOnly = true
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, "gadgets.lib.projectsveltos.io").Drift).To(Equal(deploy.DriftStatusInSync))
+
+		shortNames := func() []string {
+			current := &apiextensionsv1.CustomResourceDefinition{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: "gadgets.lib.projectsveltos.io"}, current)).
+				To(Succeed())
+			return current.Spec.Names.ShortNames
+		}
+		Expect(shortNames()).To(Equal([]string{"gd"}))
+
+		// Without Preserve, the customization is wiped
+		opts.ObserveOnly = false
+		opts.Preserve = nil
+		report, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, "gadgets.lib.projectsveltos.io").Action).To(Equal(deploy.ActionUpdated))
+		Expect(shortNames()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,150 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ProgressPhase is the step of a run a ProgressEvent reports
+type ProgressPhase string
+
+const (
+	// PhaseParsed means a bundle CRD was parsed and mutated, and is about to
+	// be processed
+	PhaseParsed = ProgressPhase("Parsed")
+
+	// PhaseSkipped means a bundle CRD was not written. Reason tells why: it
+	// is the Action reported for the CRD or, for CRDs of components not
+	// selected, ReasonNotSelected.
+	PhaseSkipped = ProgressPhase("Skipped")
+
+	// PhaseCreating means a CRD is about to be created
+	PhaseCreating = ProgressPhase("Creating")
+
+	// PhaseCreated means a CRD was created
+	PhaseCreated = ProgressPhase("Created")
+
+	// PhaseUpdating means a CRD is about to be updated
+	PhaseUpdating = ProgressPhase("Updating")
+
+	// PhaseUpdated means a CRD was updated
+	PhaseUpdated = ProgressPhase("Updated")
+
+	// PhaseFailed means processing a CRD failed. Err is the failure.
+	PhaseFailed = ProgressPhase("Failed")
+
+	// PhaseWaiting means WaitForCRDs started waiting for a CRD to be established
+	PhaseWaiting = ProgressPhase("Waiting")
+
+	// PhaseEstablished means WaitForCRDs found a CRD established
+	PhaseEstablished = ProgressPhase("Established")
+
+	// PhaseRunCompleted is the last event of a Deploy run. Report is the run
+	// report, Err the error Deploy returns.
+	PhaseRunCompleted = ProgressPhase("RunCompleted")
+)
+
+const (
+	// ReasonNotSelected is the PhaseSkipped reason of the CRDs not selected
+	// by Options.Components, IncludeCRDs or ExcludeCRDs
+	ReasonNotSelected = "not-selected"
+
+	// ReasonRecreate is the PhaseCreating reason of a CRD created again once
+	// its pending deletion completed
+	ReasonRecreate = "recreate"
+)
+
+// ProgressEvent describes a step of a run
+type ProgressEvent struct {
+	// Phase is the step reported
+	Phase ProgressPhase
+
+	// CRD is the name of the CRD the event is about. It is empty for
+	// PhaseRunCompleted.
+	CRD string
+
+	// Reason details PhaseSkipped and PhaseCreating events
+	Reason string
+
+	// Err is set for PhaseFailed and, when the run failed, PhaseRunCompleted
+	Err error
+
+	// Result is the CRD result, as reported, of PhaseCreated, PhaseUpdated,
+	// PhaseSkipped and PhaseFailed events. It must not be modified.
+	Result *CRDResult
+
+	// Report is the run report of PhaseRunCompleted
+	Report *Report
+}
+
+// progress logs event and passes it to the Progress callback. The run log is
+// built from the same events embedders get, so both always agree.
+func (o *Options) progress(logger logr.Logger, event *ProgressEvent) {
+	logProgress(logger, event)
+	if o.Progress != nil {
+		o.Progress(*event)
+	}
+}
+
+// logProgress logs event
+func logProgress(logger logr.Logger, event *ProgressEvent) {
+	switch event.Phase {
+	case PhaseParsed, PhaseWaiting:
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("Sveltos CRD %s: %s", event.CRD, event.Phase))
+	case PhaseSkipped:
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("Sveltos CRD %s skipped: %s", event.CRD, event.Reason))
+	case PhaseCreating:
+		verb := "creating"
+		if event.Reason == ReasonRecreate {
+			verb = "recreating"
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("%s Sveltos CRD %s", verb, event.CRD))
+	case PhaseUpdating:
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("updating Sveltos CRD %s", event.CRD))
+	case PhaseCreated, PhaseUpdated, PhaseEstablished:
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("Sveltos CRD %s %s", event.CRD, event.Phase))
+	case PhaseFailed:
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update Sveltos CRD %s instance: %v",
+			event.CRD, event.Err))
+	case PhaseRunCompleted:
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("run %s", event.Report.Status))
+	}
+}
+
+// resultEvent returns the event reporting result, the outcome of a CRD
+// processed with error err
+func resultEvent(result *CRDResult, err error) *ProgressEvent {
+	event := &ProgressEvent{CRD: result.Name, Result: result}
+	switch {
+	case err != nil:
+		event.Phase = PhaseFailed
+		event.Err = err
+	case result.Action == ActionCreated || result.Action == ActionRecreated:
+		event.Phase = PhaseCreated
+	case result.Action == ActionUpdated || result.Action == ActionAdopted:
+		event.Phase = PhaseUpdated
+	default:
+		event.Phase = PhaseSkipped
+		event.Reason = string(result.Action)
+	}
+	return event
+}
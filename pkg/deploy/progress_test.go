@@ -0,0 +1,143 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// progressRecorder records the progress events of a run
+type progressRecorder struct {
+	events []deploy.ProgressEvent
+}
+
+func (r *progressRecorder) record(event deploy.ProgressEvent) {
+	r.events = append(r.events, event)
+}
+
+// phases returns the phases reported for the CRD named name
+func (r *progressRecorder) phases(name string) []deploy.ProgressPhase {
+	var result []deploy.ProgressPhase
+	for i := range r.events {
+		if r.events[i].CRD == name {
+			result = append(result, r.events[i].Phase)
+		}
+	}
+	return result
+}
+
+// last returns the last event recorded
+func (r *progressRecorder) last() deploy.ProgressEvent {
+	Expect(r.events).ToNot(BeEmpty())
+	return r.events[len(r.events)-1]
+}
+
+var _ = Describe("Progress", func() {
+	It("reports every CRD created and completes with the report", func() {
+		recorder := &progressRecorder{}
+		opts := &deploy.Options{Progress: recorder.record}
+
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), opts, logger)
+		Expect(err).To(BeNil())
+
+		for _, u := range getBundleCRDs() {
+			Expect(recorder.phases(u.GetName())).To(Equal([]deploy.ProgressPhase{
+				deploy.PhaseParsed, deploy.PhaseCreating, deploy.PhaseCreated,
+			}), u.GetName())
+		}
+
+		completed := recorder.last()
+		Expect(completed.Phase).To(Equal(deploy.PhaseRunCompleted))
+		Expect(completed.Report).To(BeIdenticalTo(report))
+		Expect(completed.Err).To(BeNil())
+	})
+
+	It("reports the CRDs up to date as skipped", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		recorder := &progressRecorder{}
+		_, err = deploy.Deploy(context.TODO(), c, &deploy.Options{Progress: recorder.record}, logger)
+		Expect(err).To(BeNil())
+
+		for i := range recorder.events {
+			event := &recorder.events[i]
+			if event.Phase != deploy.PhaseSkipped {
+				Expect(event.Phase).To(BeElementOf(deploy.PhaseParsed, deploy.PhaseRunCompleted))
+				continue
+			}
+			Expect(event.Reason).To(Equal(string(deploy.ActionUnchanged)))
+			Expect(event.Result.Action).To(Equal(deploy.ActionUnchanged))
+		}
+	})
+
+	It("reports failures and the CRDs of components not selected", func() {
+		createErr := errors.New("etcd unavailable")
+		c := newDenyingClient(sveltosClusterCRD, createErr)
+		recorder := &progressRecorder{}
+		opts := &deploy.Options{
+			Components: []string{crds.ComponentOf(sveltosClusterCRD)},
+			Progress:   recorder.record,
+		}
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+
+		phases := recorder.phases(sveltosClusterCRD)
+		Expect(phases).To(Equal([]deploy.ProgressPhase{deploy.PhaseParsed, deploy.PhaseCreating, deploy.PhaseFailed}))
+		for i := range recorder.events {
+			event := &recorder.events[i]
+			if event.CRD == sveltosClusterCRD && event.Phase == deploy.PhaseFailed {
+				Expect(event.Err).To(MatchError(createErr))
+				Expect(event.Result.Action).To(Equal(deploy.ActionFailed))
+			}
+		}
+
+		for _, u := range getBundleCRDs() {
+			if crds.ComponentOf(u.GetName()) != crds.ComponentOf(sveltosClusterCRD) {
+				Expect(recorder.phases(u.GetName())).To(Equal([]deploy.ProgressPhase{
+					deploy.PhaseParsed, deploy.PhaseSkipped,
+				}), u.GetName())
+			}
+		}
+
+		Expect(recorder.last().Phase).To(Equal(deploy.PhaseRunCompleted))
+		Expect(recorder.last().Err).To(Equal(err))
+	})
+
+	It("reports the CRDs waited for and established", func() {
+		recorder := &progressRecorder{}
+		c := newFakeClient(establishedBundleCRDs()...)
+
+		Expect(deploy.WaitForCRDs(context.TODO(), c, &deploy.Options{Progress: recorder.record},
+			time.Second, logger)).To(Succeed())
+		for _, u := range getBundleCRDs() {
+			Expect(recorder.phases(u.GetName())).To(Equal([]deploy.ProgressPhase{
+				deploy.PhaseWaiting, deploy.PhaseEstablished,
+			}), u.GetName())
+		}
+	})
+})
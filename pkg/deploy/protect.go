@@ -0,0 +1,174 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// ProtectionPolicyName is the name of the ValidatingAdmissionPolicy, and
+	// of its binding, protecting the CRDs crd-manager manages
+	ProtectionPolicyName = "crd-manager-protect-crds"
+
+	// DefaultProtectionServiceAccount is the ServiceAccount, in the
+	// namespace/name format, crd-manager runs as in the shipped manifest
+	DefaultProtectionServiceAccount = "projectsveltos/crd-manager"
+)
+
+// protectionPolicy returns the ValidatingAdmissionPolicy and binding denying
+// the deletion, and the updates removing or unserving versions or changing
+// the kind, of the CRDs carrying the crd-manager ownership label, unless
+// requested by serviceAccount (namespace/name). Removing the ownership
+// label, a deliberate step, lifts the protection.
+func protectionPolicy(serviceAccount string) (*admissionregistrationv1.ValidatingAdmissionPolicy,
+	*admissionregistrationv1.ValidatingAdmissionPolicyBinding) {
+
+	namespace, name, _ := strings.Cut(serviceAccount, "/")
+	username := fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+	lbls := map[string]string{ManagedByLabel: ManagedByValue}
+
+	policy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: ProtectionPolicyName, Labels: lbls},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			FailurePolicy: ptr.To(admissionregistrationv1.Fail),
+			// defaulted fields are set, so that the live policy matches it
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				MatchPolicy:       ptr.To(admissionregistrationv1.Equivalent),
+				NamespaceSelector: &metav1.LabelSelector{},
+				ObjectSelector:    &metav1.LabelSelector{MatchLabels: lbls},
+				ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+						Operations: []admissionregistrationv1.OperationType{
+							admissionregistrationv1.Delete, admissionregistrationv1.Update,
+						},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{"apiextensions.k8s.io"},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"customresourcedefinitions"},
+							Scope:       ptr.To(admissionregistrationv1.AllScopes),
+						},
+					},
+				}},
+			},
+			MatchConditions: []admissionregistrationv1.MatchCondition{{
+				Name:       "not-crd-manager",
+				Expression: fmt.Sprintf("request.userInfo.username != %q", username),
+			}},
+			Validations: []admissionregistrationv1.Validation{
+				{
+					Expression: "request.operation != 'DELETE'",
+					Message: "Sveltos CRDs managed by crd-manager cannot be deleted: deleting a CRD deletes all its " +
+						"objects. Remove the " + ManagedByLabel + " label first if this is intended.",
+				},
+				{
+					Expression: "request.operation != 'UPDATE' || " +
+						"(object.spec.names.kind == oldObject.spec.names.kind && " +
+						"oldObject.spec.versions.all(o, !o.served || " +
+						"object.spec.versions.exists(n, n.name == o.name && n.served)))",
+					Message: "Sveltos CRDs managed by crd-manager cannot have their kind changed or a served version " +
+						"removed or unserved. Remove the " + ManagedByLabel + " label first if this is intended.",
+				},
+			},
+		},
+	}
+
+	binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: ProtectionPolicyName, Labels: lbls},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        ProtectionPolicyName,
+			ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+		},
+	}
+	return policy, binding
+}
+
+// protectCRDs creates, or brings up to date, the admission policy protecting
+// the managed CRDs. On clusters without the admissionregistration v1 policy
+// API (Kubernetes older than v1.30), it only logs a warning.
+func protectCRDs(ctx context.Context, c client.Client, opts *Options, logger logr.Logger) error {
+	policy, binding := protectionPolicy(opts.ProtectServiceAccount)
+
+	for _, obj := range []client.Object{policy, binding} {
+		err := ensureProtectionObject(ctx, c, obj, logger)
+		if isPolicyAPIUnavailable(err) {
+			logWarning(logger, "Sveltos CRDs are not protected: the cluster does not serve the "+
+				"admissionregistration.k8s.io/v1 ValidatingAdmissionPolicy API (Kubernetes v1.30 or newer)")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to install CRD protection policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureProtectionObject creates desired, or updates its spec and labels
+// when they changed
+func ensureProtectionObject(ctx context.Context, c client.Client, desired client.Object, logger logr.Logger) error {
+	kind := reflect.TypeOf(desired).Elem().Name()
+	current := desired.DeepCopyObject().(client.Object)
+	err := c.Get(ctx, types.NamespacedName{Name: desired.GetName()}, current)
+	if apierrors.IsNotFound(err) {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("creating %s %s", kind, desired.GetName()))
+		return c.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(protectionSpec(current), protectionSpec(desired)) &&
+		containsAll(current.GetLabels(), desired.GetLabels()) {
+
+		return nil
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("updating %s %s", kind, desired.GetName()))
+	desired.SetResourceVersion(current.GetResourceVersion())
+	return c.Update(ctx, desired)
+}
+
+// protectionSpec returns the spec of a protection policy or binding
+func protectionSpec(obj client.Object) any {
+	switch o := obj.(type) {
+	case *admissionregistrationv1.ValidatingAdmissionPolicy:
+		return o.Spec
+	case *admissionregistrationv1.ValidatingAdmissionPolicyBinding:
+		return o.Spec
+	default:
+		return nil
+	}
+}
+
+// isPolicyAPIUnavailable returns true if err means the ValidatingAdmissionPolicy
+// API is not served (or not known to the client)
+func isPolicyAPIUnavailable(err error) bool {
+	return err != nil && (meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err))
+}
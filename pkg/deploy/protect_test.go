@@ -0,0 +1,174 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package deploy_test
+
+import (
+	"context"
+
+	"github.com/google/cel-go/cel"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// evaluate evaluates a policy CEL expression against an admission request.
+// Objects are given in their JSON representation.
+func evaluate(expression string, request, object, oldObject map[string]interface{}) bool {
+	env, err := cel.NewEnv(
+		cel.Variable("request", cel.DynType),
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+	)
+	Expect(err).To(BeNil())
+	ast, issues := env.Compile(expression)
+	Expect(issues.Err()).To(BeNil())
+	program, err := env.Program(ast)
+	Expect(err).To(BeNil())
+
+	out, _, err := program.Eval(map[string]interface{}{"request": request, "object": object, "oldObject": oldObject})
+	Expect(err).To(BeNil())
+	return out.Value().(bool)
+}
+
+// crdVersions returns a CRD, in its JSON representation, of kind serving versions
+func crdVersions(kind string, served map[string]bool) map[string]interface{} {
+	versions := []interface{}{}
+	for name, isServed := range served {
+		versions = append(versions, map[string]interface{}{"name": name, "served": isServed})
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"names":    map[string]interface{}{"kind": kind},
+			"versions": versions,
+		},
+	}
+}
+
+// allowed returns true if every policy validation accepts the request
+func allowed(policy *admissionregistrationv1.ValidatingAdmissionPolicy, operation string,
+	object, oldObject map[string]interface{}) bool {
+
+	request := map[string]interface{}{"operation": operation}
+	for _, validation := range policy.Spec.Validations {
+		if !evaluate(validation.Expression, request, object, oldObject) {
+			return false
+		}
+	}
+	return true
+}
+
+var _ = Describe("CRD protection", func() {
+	It("generates a policy matching managed CRDs and exempting crd-manager", func() {
+		policy, binding := deploy.ProtectionPolicy("projectsveltos/crd-manager")
+
+		Expect(policy.Name).To(Equal(deploy.ProtectionPolicyName))
+		Expect(policy.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+		Expect(*policy.Spec.FailurePolicy).To(Equal(admissionregistrationv1.Fail))
+		Expect(policy.Spec.MatchConstraints.ObjectSelector.MatchLabels).To(Equal(
+			map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(HaveLen(1))
+		rule := policy.Spec.MatchConstraints.ResourceRules[0]
+		Expect(rule.Operations).To(ConsistOf(admissionregistrationv1.Delete, admissionregistrationv1.Update))
+		Expect(rule.Resources).To(ConsistOf("customresourcedefinitions"))
+
+		Expect(policy.Spec.MatchConditions).To(HaveLen(1))
+		condition := policy.Spec.MatchConditions[0].Expression
+		Expect(evaluate(condition, map[string]interface{}{
+			"userInfo": map[string]interface{}{"username": "system:serviceaccount:projectsveltos:crd-manager"},
+		}, nil, nil)).To(BeFalse())
+		Expect(evaluate(condition, map[string]interface{}{
+			"userInfo": map[string]interface{}{"username": "kubernetes-admin"},
+		}, nil, nil)).To(BeTrue())
+
+		Expect(binding.Name).To(Equal(deploy.ProtectionPolicyName))
+		Expect(binding.Spec.PolicyName).To(Equal(policy.Name))
+		Expect(binding.Spec.ValidationActions).To(ConsistOf(admissionregistrationv1.Deny))
+	})
+
+	It("denies deletions and destructive updates only", func() {
+		policy, _ := deploy.ProtectionPolicy("projectsveltos/crd-manager")
+		current := crdVersions("Widget", map[string]bool{"v1alpha1": true, "v1beta1": true})
+
+		Expect(allowed(policy, "DELETE", nil, current)).To(BeFalse())
+		Expect(allowed(policy, "UPDATE", current, current)).To(BeTrue())
+		Expect(allowed(policy, "UPDATE", crdVersions("Widget",
+			map[string]bool{"v1alpha1": true, "v1beta1": true, "v1": true}), current)).To(BeTrue())
+		Expect(allowed(policy, "UPDATE", crdVersions("Widget",
+			map[string]bool{"v1beta1": true}), current)).To(BeFalse())
+		Expect(allowed(policy, "UPDATE", crdVersions("Widget",
+			map[string]bool{"v1alpha1": false, "v1beta1": true}), current)).To(BeFalse())
+		Expect(allowed(policy, "UPDATE", crdVersions("Gadget",
+			map[string]bool{"v1alpha1": true, "v1beta1": true}), current)).To(BeFalse())
+
+		// versions already not served can be removed
+		unserved := crdVersions("Widget", map[string]bool{"v1alpha1": false, "v1beta1": true})
+		Expect(allowed(policy, "UPDATE", crdVersions("Widget",
+			map[string]bool{"v1beta1": true}), unserved)).To(BeTrue())
+	})
+
+	It("installs the policy and keeps it up to date", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{ProtectCRDs: true, ProtectServiceAccount: "projectsveltos/crd-manager"}
+
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		policy := &admissionregistrationv1.ValidatingAdmissionPolicy{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: deploy.ProtectionPolicyName}, policy)).To(Succeed())
+		binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: deploy.ProtectionPolicyName}, binding)).To(Succeed())
+
+		// unchanged policies are not updated
+		resourceVersion := policy.ResourceVersion
+		_, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: deploy.ProtectionPolicyName}, policy)).To(Succeed())
+		Expect(policy.ResourceVersion).To(Equal(resourceVersion))
+
+		// a modified policy is restored
+		policy.Spec.Validations = nil
+		Expect(c.Update(context.TODO(), policy)).To(Succeed())
+		_, err = deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: deploy.ProtectionPolicyName}, policy)).To(Succeed())
+		Expect(policy.Spec.Validations).To(HaveLen(2))
+	})
+
+	It("only warns when the policy API is not available", func() {
+		noPolicyScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(noPolicyScheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(noPolicyScheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(noPolicyScheme).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{ProtectCRDs: true, ProtectServiceAccount: "projectsveltos/crd-manager"}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+	})
+
+	It("refuses an invalid service account", func() {
+		opts := &deploy.Options{ProtectCRDs: true, ProtectServiceAccount: "crd-manager"}
+		Expect(opts.Validate()).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,232 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/version"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// AppliedByAnnotation records, as JSON, the crd-manager build (version,
+	// git SHA and build date) which last created or updated a CRD
+	AppliedByAnnotation = "projectsveltos.io/applied-by"
+
+	// BundleHashAnnotation records the crds.SpecHash of the bundle CRD a CRD
+	// was applied from, for CheckInstalled to tell outdated CRDs apart
+	// reading their metadata only
+	BundleHashAnnotation = crds.BundleHashAnnotation
+)
+
+// ProvenanceStatus tells whether a live CRD is the one of the source it records
+type ProvenanceStatus string
+
+const (
+	// ProvenanceMatched means the recorded source defines the CRD with the
+	// bundle hash the live CRD carries
+	ProvenanceMatched = ProvenanceStatus("matched")
+
+	// ProvenanceMismatched means the recorded source is known but defines the
+	// CRD with another bundle hash, or not at all: the content does not come
+	// from it
+	ProvenanceMismatched = ProvenanceStatus("mismatched")
+
+	// ProvenanceUnverified means the recorded source is not one of the known
+	// ones, or the live CRD carries no bundle hash: the content cannot be
+	// checked
+	ProvenanceUnverified = ProvenanceStatus("unverified")
+
+	// ProvenanceNotRecorded means the live CRD records no source, as when
+	// applied from a single source, and its bundle hash is the one of a known
+	// source
+	ProvenanceNotRecorded = ProvenanceStatus("not-recorded")
+
+	// ProvenanceUnknown means the live CRD records no source and its bundle
+	// hash matches no known source
+	ProvenanceUnknown = ProvenanceStatus("unknown")
+)
+
+// CRDProvenance is the provenance of a live CRD managed by crd-manager
+type CRDProvenance struct {
+	// Name is the CustomResourceDefinition name
+	Name string `json:"name"`
+
+	// Source is the source recorded on the CRD, if any
+	Source *bundle.Provenance `json:"source,omitempty"`
+
+	// Status tells whether the CRD is the one of Source
+	Status ProvenanceStatus `json:"status"`
+
+	// Matching lists the known sources defining the CRD with the bundle hash
+	// the live CRD carries
+	Matching []bundle.Provenance `json:"matching,omitempty"`
+}
+
+// IsDrift returns true if the live CRD content does not come from the
+// source it records or, without one, from any known source
+func (p *CRDProvenance) IsDrift() bool {
+	return p.Status == ProvenanceMismatched || p.Status == ProvenanceUnknown
+}
+
+// setAppliedBy records on u the crd-manager build writing it. It is set right
+// before the write, after deciding whether one is needed, so that a rebuild
+// deploying the same bundle changes nothing.
+func setAppliedBy(u *unstructured.Unstructured) error {
+	data, err := json.Marshal(version.Get())
+	if err != nil {
+		return err
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AppliedByAnnotation] = string(data)
+	u.SetAnnotations(annotations)
+	return nil
+}
+
+// setBundleHash records on u the hash of its bundle spec. It is set before any
+// mutation and, unlike AppliedByAnnotation, is part of the desired CRD: CRDs
+// written by builds not setting it are updated once.
+func setBundleHash(u *unstructured.Unstructured) error {
+	hash, err := crds.SpecHash(u.Object)
+	if err != nil {
+		return err
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[BundleHashAnnotation] = hash
+	u.SetAnnotations(annotations)
+	return nil
+}
+
+// recordSource records in a the source of merged bundle b the CRD u is taken
+// from. Only the source type and location are recorded.
+func recordSource(u *unstructured.Unstructured, b *bundle.Bundle, a *audit) {
+	if len(b.Origins) == 0 {
+		return
+	}
+	provenance := b.ProvenanceOf(u.GetName())
+	a.Source = &bundle.Provenance{Type: provenance.Type, Location: provenance.Location}
+}
+
+// knownSource is a bundle source CheckProvenance compares the CRDs with
+type knownSource struct {
+	provenance bundle.Provenance
+
+	// hashes are the bundle hashes of the source CRDs, by name
+	hashes map[string]string
+}
+
+// CheckProvenance returns, sorted by name, the provenance of the live CRDs
+// carrying the crd-manager ownership label, comparing their bundle hash with
+// the one of their recorded source among sources. Only the CRDs metadata is
+// read.
+func CheckProvenance(ctx context.Context, c client.Client, sources []*bundle.Bundle,
+	logger logr.Logger) ([]CRDProvenance, error) {
+
+	known := make([]knownSource, 0, len(sources))
+	for _, source := range sources {
+		hashes, err := bundleHashes(source)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %w", source.Source, err)
+		}
+		known = append(known, knownSource{provenance: source.Provenance(), hashes: hashes})
+	}
+
+	var result []CRDProvenance
+	err := forEachCRDMetadata(ctx, c, client.MatchingLabels{ManagedByLabel: ManagedByValue},
+		func(crd *metav1.PartialObjectMetadata) error {
+			result = append(result, checkCRDProvenance(crd, known, logger))
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// bundleHashes returns, by name, the bundle hash of the CRDs of b
+func bundleHashes(b *bundle.Bundle) (map[string]string, error) {
+	objs, _, err := parseBundle(b.Content)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(objs))
+	for _, u := range objs {
+		if hashes[u.GetName()], err = crds.SpecHash(u.Object); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func checkCRDProvenance(crd *metav1.PartialObjectMetadata, known []knownSource, logger logr.Logger) CRDProvenance {
+	result := CRDProvenance{Name: crd.Name}
+	if data, ok := crd.Annotations[auditAnnotation]; ok {
+		a := &audit{}
+		if err := json.Unmarshal([]byte(data), a); err != nil {
+			logWarning(logger, "CRD %s has an invalid %s annotation: %v", crd.Name, auditAnnotation, err)
+		}
+		result.Source = a.Source
+	}
+
+	hash, hasHash := crd.Annotations[BundleHashAnnotation]
+	var recorded *knownSource
+	for i := range known {
+		if hasHash && known[i].hashes[crd.Name] == hash {
+			result.Matching = append(result.Matching, known[i].provenance)
+		}
+		if result.Source != nil && known[i].provenance.Type == result.Source.Type &&
+			known[i].provenance.Location == result.Source.Location {
+
+			recorded = &known[i]
+		}
+	}
+
+	switch {
+	case !hasHash || (result.Source != nil && recorded == nil):
+		result.Status = ProvenanceUnverified
+	case result.Source != nil && recorded.hashes[crd.Name] == hash:
+		result.Status = ProvenanceMatched
+	case result.Source != nil:
+		result.Status = ProvenanceMismatched
+	case len(result.Matching) > 0:
+		result.Status = ProvenanceNotRecorded
+	default:
+		result.Status = ProvenanceUnknown
+	}
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("CRD %s provenance: %s", crd.Name, result.Status))
+	return result
+}
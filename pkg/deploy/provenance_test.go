@@ -0,0 +1,236 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/version"
+)
+
+var _ = Describe("AppliedBy", func() {
+	It("records the crd-manager build on created and updated CRDs", func() {
+		c := newFakeClient(outdatedCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		for _, result := range report.CRDs {
+			value := getCRD(c, result.Name).Annotations[deploy.AppliedByAnnotation]
+			var info version.BuildInfo
+			Expect(json.Unmarshal([]byte(value), &info)).To(Succeed(), result.Name)
+			Expect(info).To(Equal(version.Get()))
+		}
+	})
+
+	It("a different build alone does not cause an update", func() {
+		crd := getBundleCRD(sveltosClusterCRD)
+		if crd.Annotations == nil {
+			crd.Annotations = map[string]string{}
+		}
+		crd.Annotations[deploy.AppliedByAnnotation] = `{"version":"v0.0.1","gitSHA":"0000000","buildDate":"2020-01-01T00:00:00Z"}`
+		crd.Annotations[deploy.BundleHashAnnotation] = getBundleHash(sveltosClusterCRD)
+		c := newFakeClient(crd)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUnchanged))
+		Expect(getCRD(c, sveltosClusterCRD).Annotations).To(HaveKeyWithValue(deploy.AppliedByAnnotation,
+			crd.Annotations[deploy.AppliedByAnnotation]))
+	})
+
+	It("records the bundle hash, which crds.CheckInstalled checks", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		Expect(getCRD(c, sveltosClusterCRD).Annotations).To(HaveKeyWithValue(deploy.BundleHashAnnotation,
+			getBundleHash(sveltosClusterCRD)))
+		missing, outdated, err := crds.CheckInstalled(context.TODO(), c, nil)
+		Expect(err).To(BeNil())
+		Expect(missing).To(BeEmpty())
+		Expect(outdated).To(BeEmpty())
+	})
+
+	It("updates CRDs not carrying the bundle hash once", func() {
+		c := newFakeClient(getBundleCRD(sveltosClusterCRD))
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUpdated))
+
+		report, err = deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionUnchanged))
+	})
+})
+
+var _ = Describe("CheckInstalled", func() {
+	It("reports the missing CRDs and the ones applied from another bundle", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		missing, outdated, err := deploy.CheckInstalled(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(missing).To(BeEmpty())
+		Expect(outdated).To(BeEmpty())
+
+		names := crds.CRDNames()
+		Expect(c.Delete(context.TODO(), getCRD(c, names[0]))).To(Succeed())
+		crd := getCRD(c, names[1])
+		crd.Annotations[deploy.BundleHashAnnotation] = "sha256:0000"
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+		crd = getCRD(c, names[2])
+		delete(crd.Annotations, deploy.BundleHashAnnotation)
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+
+		missing, outdated, err = deploy.CheckInstalled(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(missing).To(Equal([]string{names[0]}))
+		Expect(outdated).To(ConsistOf(names[1], names[2]))
+	})
+
+	It("does not depend on the mutations applied", func() {
+		c := newFakeClient()
+		opts := &deploy.Options{Category: "sveltos"}
+		_, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+
+		missing, outdated, err := crds.CheckInstalled(context.TODO(), c, nil)
+		Expect(err).To(BeNil())
+		Expect(missing).To(BeEmpty())
+		Expect(outdated).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Provenance", func() {
+	// mergedBundle returns the embedded bundle merged with an archive overriding gadgets
+	mergedBundle := func() (*bundle.Bundle, []*bundle.Bundle) {
+		override := &bundle.Bundle{Content: []byte(crdWithoutWebhook), Source: "override.tar.gz",
+			Type: bundle.SourceTypeArchive, ArchiveDigest: "sha256:1234"}
+		sources := []*bundle.Bundle{bundle.Embedded(), override}
+		merged, _, err := bundle.Merge(sources, false)
+		Expect(err).To(BeNil())
+		return merged, sources
+	}
+
+	// recordedSource returns the source recorded in the audit annotation of the CRD named name
+	recordedSource := func(c client.Client, name string) *bundle.Provenance {
+		value, ok := getCRD(c, name).Annotations[auditAnnotation]
+		if !ok {
+			return nil
+		}
+		var recorded struct {
+			Source *bundle.Provenance `json:"source"`
+		}
+		Expect(json.Unmarshal([]byte(value), &recorded)).To(Succeed())
+		return recorded.Source
+	}
+
+	It("records the source of every CRD of a merged bundle", func() {
+		merged, _ := mergedBundle()
+		c := newFakeClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: merged}, logger)
+		Expect(err).To(BeNil())
+		for i := range report.CRDs {
+			expected := bundle.Provenance{Type: bundle.SourceTypeEmbedded}
+			if report.CRDs[i].Name == "gadgets.lib.projectsveltos.io" {
+				expected = bundle.Provenance{Type: bundle.SourceTypeArchive, Location: "override.tar.gz"}
+				Expect(*report.CRDs[i].Provenance).To(Equal(bundle.Provenance{Type: bundle.SourceTypeArchive,
+					Location: "override.tar.gz", Digest: "sha256:1234"}))
+			} else {
+				Expect(report.CRDs[i].Provenance.Digest).To(Equal(bundle.Embedded().Digest()))
+			}
+			Expect(recordedSource(c, report.CRDs[i].Name)).To(Equal(&expected))
+		}
+
+		// a single source records none
+		c = newFakeClient()
+		report, err = deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CRDs[0].Provenance).To(BeNil())
+		Expect(recordedSource(c, report.CRDs[0].Name)).To(BeNil())
+	})
+
+	It("checks the live CRDs come from the source they record", func() {
+		merged, sources := mergedBundle()
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: merged}, logger)
+		Expect(err).To(BeNil())
+
+		// another bundle hash
+		crd := getCRD(c, sveltosClusterCRD)
+		crd.Annotations[deploy.BundleHashAnnotation] = "sha256:0000"
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+		// a source not configured
+		crd = getCRD(c, "gadgets.lib.projectsveltos.io")
+		crd.Annotations[auditAnnotation] = `{"source":{"type":"url","location":"https://example.com/crds.yaml"}}`
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+
+		provenances, err := deploy.CheckProvenance(context.TODO(), c, sources, logger)
+		Expect(err).To(BeNil())
+		Expect(provenances).To(HaveLen(len(getBundleCRDs()) + 1))
+		for i := range provenances {
+			provenance := &provenances[i]
+			switch provenance.Name {
+			case sveltosClusterCRD:
+				Expect(provenance.Status).To(Equal(deploy.ProvenanceMismatched))
+				Expect(provenance.IsDrift()).To(BeTrue())
+				Expect(provenance.Matching).To(BeEmpty())
+			case "gadgets.lib.projectsveltos.io":
+				Expect(provenance.Status).To(Equal(deploy.ProvenanceUnverified))
+				Expect(provenance.IsDrift()).To(BeFalse())
+				Expect(provenance.Matching).To(ConsistOf(sources[1].Provenance()))
+			default:
+				Expect(provenance.Status).To(Equal(deploy.ProvenanceMatched), provenance.Name)
+				Expect(provenance.Source).To(Equal(&bundle.Provenance{Type: bundle.SourceTypeEmbedded}))
+				Expect(provenance.Matching).To(ConsistOf(sources[0].Provenance()))
+			}
+		}
+	})
+
+	It("flags CRDs recording no source whose content matches no known source", func() {
+		c := newFakeClient()
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		crd := getCRD(c, sveltosClusterCRD)
+		crd.Annotations[deploy.BundleHashAnnotation] = "sha256:0000"
+		Expect(c.Update(context.TODO(), crd)).To(Succeed())
+
+		provenances, err := deploy.CheckProvenance(context.TODO(), c, []*bundle.Bundle{bundle.Embedded()}, logger)
+		Expect(err).To(BeNil())
+		for i := range provenances {
+			expected := deploy.ProvenanceNotRecorded
+			if provenances[i].Name == sveltosClusterCRD {
+				expected = deploy.ProvenanceUnknown
+			}
+			Expect(provenances[i].Status).To(Equal(expected), provenances[i].Name)
+			Expect(provenances[i].IsDrift()).To(Equal(expected == deploy.ProvenanceUnknown))
+		}
+	})
+})
@@ -0,0 +1,97 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// pruneManagedCRDs deletes the CRDs carrying the crd-manager ownership label
+// which are no longer part of the embedded bundle and adds them to the report
+// removed CRDs. CRDs still having instances are only deleted with PruneForce,
+// as deleting a CRD deletes all its instances.
+func pruneManagedCRDs(ctx context.Context, c client.Client, opts *Options, crds []*bundleCRD,
+	report *Report, logger logr.Logger) error {
+
+	extra, err := pruneCandidates(ctx, c, opts, crds)
+	if err != nil {
+		return err
+	}
+
+	failed := &DeployError{}
+	for _, name := range extra {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if crd.DeletionTimestamp != nil {
+			continue
+		}
+
+		result := CRDResult{Name: name, Action: ActionPruned}
+		if err := deleteUnusedCRD(ctx, c, crd, opts.PruneForce, opts, &result, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to prune Sveltos CRD %s: %v", name, err))
+			result.Action = ActionFailed
+			result.Error = err.Error()
+			failed.add(name, err)
+		} else {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("pruned Sveltos CRD %s, no longer part of the embedded bundle", name))
+		}
+		report.Removed = append(report.Removed, result)
+	}
+	return failed.err()
+}
+
+// pruneCandidates returns the managed CRDs part of neither crds nor the
+// embedded bundle. A bundle taken from other sources can hold a subset of the
+// Sveltos CRDs only: the ones it leaves out are not deleted. CRDs the options
+// do not select are never pruned either.
+func pruneCandidates(ctx context.Context, c client.Client, opts *Options, crds []*bundleCRD) ([]string, error) {
+	extra, err := extraManagedCRDs(ctx, c, crds)
+	if err != nil {
+		return nil, err
+	}
+
+	embedded := map[string]string{}
+	if !opts.getBundle().IsEmbedded() {
+		if embedded, err = bundleHashes(bundle.Embedded()); err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []string
+	for _, name := range extra {
+		if _, ok := embedded[name]; ok || !opts.selectsCRD(name) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// droppedCRD returns a CRD crd-manager deployed from a previous bundle
+func droppedCRD() *apiextensionsv1.CustomResourceDefinition {
+	crd := obsoleteCRD()
+	crd.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+	return crd
+}
+
+var _ = Describe("Prune", func() {
+	It("deletes managed CRDs no longer part of the bundle", func() {
+		unmanaged := obsoleteCRD()
+		unmanaged.Name = "addoncompliances.example.com"
+		c := newFakeClient(droppedCRD(), unmanaged)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{Prune: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(And(HaveField("Name", droppedCRD().Name),
+			HaveField("Action", deploy.ActionPruned))))
+
+		err = c.Get(context.TODO(), types.NamespacedName{Name: droppedCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: unmanaged.Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+	})
+
+	It("refuses to delete managed CRDs with instances unless forced", func() {
+		c := newFakeClient(droppedCRD(), obsoleteInstance())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Prune: true}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionFailed)))
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: droppedCRD().Name},
+			&apiextensionsv1.CustomResourceDefinition{})).To(Succeed())
+
+		report, err = deploy.Deploy(context.TODO(), c, &deploy.Options{Prune: true, PruneForce: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Action", deploy.ActionPruned)))
+	})
+
+	It("keeps the embedded bundle CRDs a partial bundle leaves out", func() {
+		c := newFakeClient(droppedCRD())
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Bundle: subsetBundle(2), Prune: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(ConsistOf(HaveField("Name", droppedCRD().Name)))
+
+		list := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), list)).To(Succeed())
+		Expect(list.Items).To(HaveLen(len(getBundleCRDs())))
+	})
+
+	It("keeps bundle CRDs", func() {
+		c := newFakeClient()
+
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Prune: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Removed).To(BeEmpty())
+	})
+})
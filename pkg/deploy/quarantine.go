@@ -0,0 +1,297 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultQuarantineThreshold is the default number of runs in a row a CRD
+	// must fail, with the same error class, to be quarantined
+	DefaultQuarantineThreshold = 3
+
+	// ClearQuarantineAnnotation, set on the history ConfigMap, lists the
+	// comma separated quarantined CRDs, or ClearAllQuarantined, the next run
+	// attempts again. The run recording its history removes it.
+	ClearQuarantineAnnotation = "projectsveltos.io/clear-quarantine"
+
+	// ClearAllQuarantined clears the quarantine of every CRD
+	ClearAllQuarantined = "*"
+
+	// quarantineKey is the history ConfigMap key holding the quarantine
+	quarantineKey = "quarantine.json"
+
+	// errorClassOther is the class of the errors which are neither API
+	// errors nor admission denials
+	errorClassOther = "Error"
+)
+
+// Quarantine stops attempting CRDs which keep failing the same way. A CRD
+// failing Threshold runs in a row with the same error class is quarantined
+// and reported as ActionQuarantined until its quarantine is cleared, or its
+// definition in the bundle changes. The quarantine is kept in the History.
+type Quarantine struct {
+	// Threshold is the number of runs in a row a CRD must fail to be
+	// quarantined. Defaults to DefaultQuarantineThreshold.
+	Threshold int
+
+	// Clear lists the CRDs whose quarantine is cleared by every run, or
+	// ClearAllQuarantined
+	Clear []string
+}
+
+// QuarantineEntry is what the history remembers of a failing CRD
+type QuarantineEntry struct {
+	// ErrorClass is the class of the error of the last failures
+	ErrorClass string `json:"errorClass"`
+
+	// Error is the last error
+	Error string `json:"error"`
+
+	// Failures is the number of runs in a row the CRD failed with ErrorClass
+	Failures int `json:"failures"`
+
+	// SpecHash is the hash of the CRD spec, as found in the bundle, which failed
+	SpecHash string `json:"specHash"`
+
+	// QuarantinedAt is set once the CRD is quarantined
+	QuarantinedAt *metav1.Time `json:"quarantinedAt,omitempty"`
+}
+
+// quarantineState is the quarantine as read from the history when a run starts
+type quarantineState struct {
+	// entries are, per CRD, the failing CRDs whose quarantine is not cleared
+	entries map[string]*QuarantineEntry
+
+	// hashes are, per CRD, the spec hash, as found in the bundle, of the CRDs
+	// the run considered
+	hashes map[string]string
+}
+
+func (q *Quarantine) threshold() int {
+	if q.Threshold <= 0 {
+		return DefaultQuarantineThreshold
+	}
+	return q.Threshold
+}
+
+func (q *Quarantine) validate(history *History) error {
+	if q == nil {
+		return nil
+	}
+	if history == nil {
+		return errors.New("invalid quarantine: the run history is required")
+	}
+	if q.Threshold < 0 {
+		return fmt.Errorf("invalid quarantine threshold %d: must not be negative", q.Threshold)
+	}
+	return nil
+}
+
+// clears returns true when the quarantine of the CRD named name is cleared by
+// the Clear option or by annotations, the ones of the history ConfigMap
+func (q *Quarantine) clears(name string, annotations map[string]string) bool {
+	cleared := slices.Clone(q.Clear)
+	if value, ok := annotations[ClearQuarantineAnnotation]; ok {
+		for _, item := range strings.Split(value, ",") {
+			cleared = append(cleared, strings.TrimSpace(item))
+		}
+	}
+	return slices.Contains(cleared, ClearAllQuarantined) || slices.Contains(cleared, name)
+}
+
+// errorClass returns the class of the error processing a CRD failed with: the
+// admission webhook denying it, or the reason of the API error
+func errorClass(err error) string {
+	if denial := admissionDenial(err); denial != nil {
+		return "AdmissionDenied/" + denial.Webhook
+	}
+	var warningsErr *WarningsError
+	if errors.As(err, &warningsErr) {
+		return "Warnings"
+	}
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return errorClassOther
+}
+
+// parseQuarantine returns the quarantine kept in the history configMap
+func parseQuarantine(configMap *corev1.ConfigMap) (map[string]*QuarantineEntry, error) {
+	entries := map[string]*QuarantineEntry{}
+	data, ok := configMap.Data[quarantineKey]
+	if !ok {
+		return entries, nil
+	}
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return map[string]*QuarantineEntry{}, fmt.Errorf("invalid quarantine in ConfigMap %s/%s: %w",
+			configMap.Namespace, configMap.Name, err)
+	}
+	return entries, nil
+}
+
+// ReadQuarantine returns, per CRD, the failing and quarantined CRDs kept in
+// the history
+func ReadQuarantine(ctx context.Context, c client.Client, history *History) (map[string]*QuarantineEntry, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, history.key(), configMap)
+	if apierrors.IsNotFound(err) {
+		return map[string]*QuarantineEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseQuarantine(configMap)
+}
+
+// withQuarantine returns the options with the quarantine read from the history
+func (o *Options) withQuarantine(ctx context.Context, c client.Client, logger logr.Logger) (*Options, error) {
+	if o.Quarantine == nil || o.ObserveOnly {
+		return o, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, o.History.key(), configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to read the quarantine from ConfigMap %s: %w", o.History.key(), err)
+	}
+	entries, err := parseQuarantine(configMap)
+	if err != nil {
+		logWarning(logger, "%v, discarding it", err)
+	}
+	for name := range entries {
+		if o.Quarantine.clears(name, configMap.Annotations) {
+			delete(entries, name)
+		}
+	}
+
+	resolved := *o
+	resolved.quarantine = &quarantineState{entries: entries, hashes: map[string]string{}}
+	return &resolved, nil
+}
+
+// quarantined returns the result of crd when it is quarantined, and nil
+// otherwise. A CRD whose definition changed in the bundle since it failed is
+// attempted again.
+func (o *Options) quarantined(crd *bundleCRD, logger logr.Logger) *CRDResult {
+	if o.quarantine == nil {
+		return nil
+	}
+	name := crd.original.GetName()
+	hash, err := unstructuredSpecHash(crd.original)
+	if err != nil {
+		// the CRD is attempted, which reports the error
+		return nil
+	}
+	o.quarantine.hashes[name] = hash
+
+	entry := o.quarantine.entries[name]
+	if entry == nil || entry.QuarantinedAt == nil {
+		return nil
+	}
+	if entry.SpecHash != hash {
+		logWarning(logger, "Sveltos CRD %s changed in the bundle since it was quarantined, attempting it again", name)
+		return nil
+	}
+	return &CRDResult{Name: name, Action: ActionQuarantined, Error: entry.Error, ErrorClass: entry.ErrorClass}
+}
+
+// updateQuarantine records, in the history configMap, the failures of the run
+// report describes. It removes the quarantine the options or the
+// ClearQuarantineAnnotation clear, then the ClearQuarantineAnnotation.
+func updateQuarantine(configMap *corev1.ConfigMap, quarantine *Quarantine, state *quarantineState,
+	report *Report, logger logr.Logger) error {
+
+	entries, err := parseQuarantine(configMap)
+	if err != nil {
+		logWarning(logger, "%v, discarding it", err)
+	}
+	for name := range entries {
+		if quarantine.clears(name, configMap.Annotations) {
+			delete(entries, name)
+		}
+	}
+	delete(configMap.Annotations, ClearQuarantineAnnotation)
+
+	// The bundle CRDs are only known when the run processed them
+	if len(report.CRDs) > 0 {
+		updateQuarantineEntries(entries, quarantine.threshold(), state, report, logger)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	configMap.Data[quarantineKey] = string(data)
+	return nil
+}
+
+// updateQuarantineEntries counts the failures of the CRDs report lists, and
+// forgets the CRDs which succeeded or are no longer part of the bundle
+func updateQuarantineEntries(entries map[string]*QuarantineEntry, threshold int, state *quarantineState,
+	report *Report, logger logr.Logger) {
+
+	current := make(map[string]*QuarantineEntry, len(entries))
+	for i := range report.CRDs {
+		result := &report.CRDs[i]
+		switch result.Action {
+		case ActionFailed:
+			current[result.Name] = countFailure(entries[result.Name], result, state.hashes[result.Name],
+				threshold, logger)
+		case ActionQuarantined, ActionDeferred, ActionNotAttempted, ActionPaused:
+			// not processed: the failures counted so far are kept
+			if entry := entries[result.Name]; entry != nil {
+				current[result.Name] = entry
+			}
+		}
+	}
+	clear(entries)
+	maps.Copy(entries, current)
+}
+
+// countFailure returns entry once the failure result describes is counted.
+// Failures with another error class, or of another CRD definition, start
+// counting again.
+func countFailure(entry *QuarantineEntry, result *CRDResult, hash string, threshold int,
+	logger logr.Logger) *QuarantineEntry {
+
+	if entry == nil || entry.ErrorClass != result.ErrorClass || entry.SpecHash != hash {
+		entry = &QuarantineEntry{ErrorClass: result.ErrorClass, SpecHash: hash}
+	}
+	entry.Failures++
+	entry.Error = result.Error
+	if entry.Failures >= threshold && entry.QuarantinedAt == nil {
+		entry.QuarantinedAt = &metav1.Time{Time: time.Now().Truncate(time.Second)}
+		logWarning(logger, "Sveltos CRD %s QUARANTINED after failing %d runs in a row (%s): it is not attempted "+
+			"until its definition changes or the %s annotation clears it", result.Name, entry.Failures,
+			result.ErrorClass, ClearQuarantineAnnotation)
+	}
+	return entry
+}
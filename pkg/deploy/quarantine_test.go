@@ -0,0 +1,178 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Quarantine", func() {
+	const failing = "widgets.lib.projectsveltos.io"
+
+	var (
+		createErr error
+		attempts  int
+		c         client.Client
+	)
+
+	history := &deploy.History{Namespace: historyNamespace}
+
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "apiextensions.k8s.io",
+		Resource: "customresourcedefinitions"}, failing, nil)
+
+	BeforeEach(func() {
+		createErr = forbidden
+		attempts = 0
+		c = fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == failing {
+					attempts++
+					if createErr != nil {
+						return createErr
+					}
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+	})
+
+	run := func(opts *deploy.Options) *deploy.CRDResult {
+		report, _ := deploy.Deploy(context.TODO(), c, opts, logger)
+		return findResult(report, failing)
+	}
+
+	newOptions := func(quarantine *deploy.Quarantine) *deploy.Options {
+		return &deploy.Options{Bundle: testBundle(), History: history, Quarantine: quarantine}
+	}
+
+	setClearAnnotation := func(value string) {
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: historyNamespace,
+			Name: deploy.HistoryConfigMapName}, configMap)).To(Succeed())
+		configMap.Annotations = map[string]string{deploy.ClearQuarantineAnnotation: value}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+	}
+
+	It("stops attempting a CRD failing the same way a number of runs in a row", func() {
+		opts := newOptions(&deploy.Quarantine{Threshold: 2})
+
+		for range 2 {
+			result := run(opts)
+			Expect(result.Action).To(Equal(deploy.ActionFailed))
+			Expect(result.ErrorClass).To(Equal(string(metav1.StatusReasonForbidden)))
+		}
+		Expect(attempts).To(Equal(2))
+
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(attempts).To(Equal(2))
+		result := findResult(report, failing)
+		Expect(result.Action).To(Equal(deploy.ActionQuarantined))
+		Expect(result.Error).To(ContainSubstring("forbidden"))
+		Expect(result.ErrorClass).To(Equal(string(metav1.StatusReasonForbidden)))
+
+		entries, err := deploy.ReadQuarantine(context.TODO(), c, history)
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveKey(failing))
+		Expect(entries[failing].Failures).To(Equal(2))
+		Expect(entries[failing].QuarantinedAt).ToNot(BeNil())
+	})
+
+	It("starts counting again when the error class changes", func() {
+		opts := newOptions(&deploy.Quarantine{Threshold: 2})
+
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		createErr = apierrors.NewInvalid(schema.GroupKind{Group: "apiextensions.k8s.io",
+			Kind: "CustomResourceDefinition"}, failing, nil)
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(run(opts).Action).To(Equal(deploy.ActionQuarantined))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("forgets the failures of a CRD once it succeeds", func() {
+		opts := newOptions(&deploy.Quarantine{Threshold: 2})
+
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		createErr = nil
+		Expect(run(opts).Action).To(Equal(deploy.ActionCreated))
+
+		entries, err := deploy.ReadQuarantine(context.TODO(), c, history)
+		Expect(err).To(BeNil())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("attempts a quarantined CRD again once the annotation clears it, then removes the annotation", func() {
+		opts := newOptions(&deploy.Quarantine{Threshold: 1})
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(run(opts).Action).To(Equal(deploy.ActionQuarantined))
+
+		setClearAnnotation("other.projectsveltos.io, " + failing)
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(attempts).To(Equal(2))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: historyNamespace,
+			Name: deploy.HistoryConfigMapName}, configMap)).To(Succeed())
+		Expect(configMap.Annotations).ToNot(HaveKey(deploy.ClearQuarantineAnnotation))
+		Expect(run(opts).Action).To(Equal(deploy.ActionQuarantined))
+	})
+
+	It("attempts quarantined CRDs the options clear", func() {
+		Expect(run(newOptions(&deploy.Quarantine{Threshold: 1})).Action).To(Equal(deploy.ActionFailed))
+
+		opts := newOptions(&deploy.Quarantine{Threshold: 1, Clear: []string{deploy.ClearAllQuarantined}})
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("attempts a quarantined CRD again once its definition changes in the bundle", func() {
+		Expect(run(newOptions(&deploy.Quarantine{Threshold: 1})).Action).To(Equal(deploy.ActionFailed))
+		Expect(run(newOptions(&deploy.Quarantine{Threshold: 1})).Action).To(Equal(deploy.ActionQuarantined))
+
+		opts := newOptions(&deploy.Quarantine{Threshold: 1})
+		opts.Bundle = &bundle.Bundle{Content: []byte(strings.Replace(string(testBundle().Content),
+			"name: widgets.lib.projectsveltos.io", "name: widgets.lib.projectsveltos.io\n  labels:\n    tier: edge", 1))}
+		Expect(run(opts).Action).To(Equal(deploy.ActionQuarantined))
+
+		opts.Bundle = &bundle.Bundle{Content: []byte(strings.Replace(string(testBundle().Content),
+			"storage: true", "storage: true\n    deprecated: true", 1))}
+		Expect(run(opts).Action).To(Equal(deploy.ActionFailed))
+	})
+
+	It("requires the run history", func() {
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{Quarantine: &deploy.Quarantine{}}, logger)
+		Expect(err).To(MatchError(ContainSubstring("history is required")))
+	})
+})
@@ -0,0 +1,682 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// RunMode is what a crd-manager invocation does
+type RunMode string
+
+const (
+	// RunModeOneShot deploys the CRDs once
+	RunModeOneShot = RunMode("oneshot")
+
+	// RunModeController keeps deploying the CRDs
+	RunModeController = RunMode("controller")
+
+	// RunModeWaitOnly waits for the CRDs to be established
+	RunModeWaitOnly = RunMode("wait-only")
+
+	// RunModeHistory prints the run history
+	RunModeHistory = RunMode("history")
+
+	// RunModeDoctor runs the Diagnose checks
+	RunModeDoctor = RunMode("doctor")
+
+	// RunModeVerifyInstall runs VerifyInstall
+	RunModeVerifyInstall = RunMode("verify-install")
+
+	// RunModeCheck runs CheckInstalled
+	RunModeCheck = RunMode("check")
+
+	// RunModeChangelog prints the BundleChangelog
+	RunModeChangelog = RunMode("changelog")
+
+	// RunModeProvenance runs CheckProvenance
+	RunModeProvenance = RunMode("provenance")
+
+	// RunModeClusterProfile applies the ClusterProfile deploying the CRDs
+	// through Sveltos
+	RunModeClusterProfile = RunMode("clusterprofile")
+)
+
+// RBACName is the name of the ClusterRole, Roles and bindings PrintRBAC generates
+const RBACName = "crd-manager"
+
+// RBACConfig is the crd-manager configuration whose permissions RequiredRBAC computes
+type RBACConfig struct {
+	// Mode is what crd-manager does. Defaults to RunModeOneShot.
+	Mode RunMode
+
+	// Options are the deploy options. Their bundle and components select the
+	// resources the permissions on Sveltos resources are restricted to.
+	Options *Options
+
+	// DeleteOnShutdown is set when, in controller mode, the created CRDs are
+	// deleted on termination
+	DeleteOnShutdown bool
+
+	// ServiceAccount, in the namespace/name format, is the subject of the
+	// bindings. Defaults to DefaultProtectionServiceAccount.
+	ServiceAccount string
+
+	// ClusterProfile is, in RunModeClusterProfile, the applied ClusterProfile
+	ClusterProfile *ClusterProfileOptions
+}
+
+// RBACRules are the permissions a crd-manager configuration needs
+type RBACRules struct {
+	// Cluster are the rules granted cluster-wide
+	Cluster []rbacv1.PolicyRule
+
+	// Namespaced are, per namespace, the rules granted in that namespace only
+	Namespaced map[string][]rbacv1.PolicyRule
+}
+
+// rbacRequirement declares the permissions a feature needs. Every Options
+// field is either listed by a requirement or in optionsWithoutRBAC, so that
+// adding an option requires deciding what it needs.
+type rbacRequirement struct {
+	// feature names what needs the permissions
+	feature string
+
+	// options are the Options fields enabling or shaping the feature
+	options []string
+
+	// enabled returns true when the configuration uses the feature
+	enabled func(in *rbacInput) bool
+
+	// rules returns the permissions the feature needs
+	rules func(in *rbacInput) []rbacRule
+}
+
+// rbacRule is a rule granted in namespace, or cluster-wide when empty
+type rbacRule struct {
+	namespace string
+	rule      rbacv1.PolicyRule
+}
+
+// optionsWithoutRBAC are the Options fields which only change what is
+// written, or how, but never call other APIs
+var optionsWithoutRBAC = []string{
+	"Bundle",
+	"ForceOwnership",
+	"OwnershipPolicy",
+	"HelmRelease",
+	"AdoptExisting",
+	"ConversionWebhook",
+	"DisableConversionWebhooks",
+	"AllowUnsafeConversionDowngrade",
+	"InjectCAFrom",
+	"InjectCAFromPerCRD",
+	"StripCEL",
+	"ServerVersion",
+	"DisabledVersions",
+	"StorageVersions",
+	"AllowUnstoredStorageVersion",
+	"Category",
+	"PrinterColumns",
+	"Labels",
+	"Annotations",
+	"FailOnNameConflicts",
+	"FieldValidation",
+	"Patches",
+	"AuditLog",
+	"ForceRemoveObsolete",
+	"PruneForce",
+	"WaitTimeout",
+	"Retries",
+	"RetryInterval",
+	"CascadeTimeout",
+	"Terminating",
+	"TerminatingTimeout",
+	"MaxObjectSize",
+	"SizeWarningPercent",
+	"FailOnWarnings",
+	"MergeVersions",
+	"SetLastApplied",
+	"Preserve",
+	"CheckExistingCRsLimit",
+	"FailOnIncompatibleCRs",
+	"ProtectServiceAccount",
+	"FailFast",
+	"Components",
+	"IncludeCRDs",
+	"ExcludeCRDs",
+	"Defer",
+	"Progress",
+	"StrictParse",
+	"RollbackTimeout",
+	"FieldManager",
+	"ForceConflicts",
+	"Diff",
+}
+
+var rbacRequirements = []rbacRequirement{
+	{
+		feature: "server version detection",
+		enabled: func(in *rbacInput) bool {
+			return in.Mode != RunModeHistory && in.Mode != RunModeWaitOnly && in.Mode != RunModeClusterProfile &&
+				in.Mode != RunModeCheck && in.Mode != RunModeProvenance
+		},
+		rules: func(*rbacInput) []rbacRule {
+			return []rbacRule{{rule: rbacv1.PolicyRule{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}}}}
+		},
+	},
+	{
+		feature: "reading CRDs",
+		enabled: func(in *rbacInput) bool {
+			return in.Mode != RunModeHistory && in.Mode != RunModeChangelog && in.Mode != RunModeClusterProfile &&
+				in.Mode != RunModeProvenance
+		},
+		rules: crdRules("get"),
+	},
+	{
+		feature: "listing CRDs",
+		enabled: func(in *rbacInput) bool {
+			return in.deploys() || in.Mode == RunModeDoctor || in.Mode == RunModeProvenance
+		},
+		rules: crdRules("list"),
+	},
+	{
+		feature: "CRD cache",
+		enabled: func(in *rbacInput) bool { return in.Mode == RunModeController },
+		rules:   crdRules("list", "watch"),
+	},
+	{
+		feature: "applying CRDs",
+		options: []string{"ObserveOnly"},
+		enabled: (*rbacInput).writes,
+		rules:   crdRules("create", "update"),
+	},
+	{
+		feature: "creating missing CRDs",
+		options: []string{"CreateMissing"},
+		enabled: func(in *rbacInput) bool { return in.creates() && !in.writes() },
+		rules:   crdRules("create"),
+	},
+	{
+		feature: "patch apply strategy",
+		options: []string{"ApplyStrategy"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.ApplyStrategy == ApplyStrategyPatch },
+		rules:   crdRules("patch"),
+	},
+	{
+		// creating a CRD with server-side apply takes both create and patch
+		feature: "server-side apply strategy",
+		options: []string{"ApplyStrategy"},
+		enabled: func(in *rbacInput) bool { return in.creates() && in.opts.ApplyStrategy == ApplyStrategyServerSide },
+		rules:   crdRules("create", "patch"),
+	},
+	{
+		feature: "pause ConfigMap",
+		enabled: (*rbacInput).creates,
+		rules: func(*rbacInput) []rbacRule {
+			return []rbacRule{namedRule(ConfigMapNamespace, "", "configmaps", ConfigMapName, "get")}
+		},
+	},
+	{
+		feature: "deleting CRDs",
+		options: []string{"RemoveObsolete", "Prune", "ApplySet"},
+		enabled: func(in *rbacInput) bool {
+			return in.writes() && (in.opts.RemoveObsolete || in.opts.Prune || in.opts.ApplySet != nil ||
+				(in.Mode == RunModeController && in.DeleteOnShutdown))
+		},
+		rules: crdRules("delete"),
+	},
+	{
+		feature: "owner reference",
+		options: []string{"OwnerRef"},
+		enabled: func(in *rbacInput) bool { return in.creates() && in.opts.OwnerRef != nil },
+		rules:   ownerRules,
+	},
+	{
+		feature: "ApplySet parent",
+		options: []string{"ApplySet"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.ApplySet != nil },
+		rules: func(in *rbacInput) []rbacRule {
+			resource := "secrets"
+			if in.opts.ApplySet.Kind == ApplySetParentConfigMap {
+				resource = "configmaps"
+			}
+			return ownedObjectRules(in.opts.ApplySet.Namespace, "", resource, in.opts.ApplySet.Name)
+		},
+	},
+	{
+		feature: "Lease lock",
+		options: []string{"Lock"},
+		enabled: func(in *rbacInput) bool { return in.creates() && in.opts.Lock != nil },
+		rules: func(in *rbacInput) []rbacRule {
+			rules := ownedObjectRules(in.opts.Lock.Namespace, "coordination.k8s.io", "leases", in.opts.Lock.Name)
+			// the pods of the holders, which run as the same ServiceAccount
+			return append(rules, namespacedRule(in.serviceAccountNamespace, "", "pods", "get"))
+		},
+	},
+	{
+		feature: "run history recording",
+		// the quarantine is kept in the history ConfigMap
+		options: []string{"History", "Quarantine"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.History != nil },
+		rules: func(in *rbacInput) []rbacRule {
+			return ownedObjectRules(in.historyNamespace(), "", "configmaps", HistoryConfigMapName)
+		},
+	},
+	{
+		feature: "version marker",
+		options: []string{"VersionMarker"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.VersionMarker != nil },
+		rules: func(in *rbacInput) []rbacRule {
+			return ownedObjectRules(in.opts.VersionMarker.Namespace, "", "configmaps", in.opts.VersionMarker.Name)
+		},
+	},
+	{
+		feature: "ClusterProfile",
+		enabled: func(in *rbacInput) bool { return in.Mode == RunModeClusterProfile },
+		rules: func(in *rbacInput) []rbacRule {
+			profile := in.clusterProfile()
+			// the ConfigMaps of CRDs no longer in the bundle are listed and deleted
+			rules := []rbacRule{namespacedRule(profile.namespace(), "", "configmaps",
+				"get", "create", "update", "list", "delete")}
+			return append(rules, ownedObjectRules("", ClusterProfileGVK.Group, "clusterprofiles", profile.name())...)
+		},
+	},
+	{
+		feature: "run history",
+		options: []string{"History"},
+		enabled: func(in *rbacInput) bool { return in.Mode == RunModeHistory || in.Mode == RunModeChangelog },
+		rules: func(in *rbacInput) []rbacRule {
+			return []rbacRule{namedRule(in.historyNamespace(), "", "configmaps", HistoryConfigMapName, "get")}
+		},
+	},
+	{
+		feature: "conversion webhook check",
+		options: []string{"SkipConversionCheck"},
+		enabled: func(in *rbacInput) bool {
+			return (in.writes() && !in.opts.SkipConversionCheck) || in.Mode == RunModeDoctor
+		},
+		rules: func(in *rbacInput) []rbacRule {
+			rules := []rbacRule{}
+			for _, namespace := range in.webhookNamespaces {
+				rules = append(rules, namespacedRule(namespace, "", "services", "get"),
+					namespacedRule(namespace, "discovery.k8s.io", "endpointslices", "list"))
+			}
+			return rules
+		},
+	},
+	{
+		feature: "conversion check read",
+		options: []string{"ConversionCheckRead"},
+		enabled: func(in *rbacInput) bool {
+			return in.writes() && !in.opts.SkipConversionCheck && in.opts.ConversionCheckRead
+		},
+		rules: func(in *rbacInput) []rbacRule { return resourceRules(in.bundleResources, "list") },
+	},
+	{
+		feature: "existing CRs check",
+		options: []string{"CheckExistingCRs"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.CheckExistingCRs },
+		rules:   func(in *rbacInput) []rbacRule { return resourceRules(in.bundleResources, "list") },
+	},
+	{
+		feature: "smoke test",
+		options: []string{"SmokeTest"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.SmokeTest },
+		rules:   func(in *rbacInput) []rbacRule { return resourceRules(in.bundleResources, "create") },
+	},
+	{
+		feature: "rollback",
+		options: []string{"RollbackOnFailure"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.RollbackOnFailure },
+		rules: func(in *rbacInput) []rbacRule {
+			// CRDs created by the run are deleted, unless they have instances
+			return append(crdRules("delete")(in), resourceRules(in.bundleResources, "list")...)
+		},
+	},
+	{
+		feature: "obsolete CRDs instances",
+		options: []string{"RemoveObsolete"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.RemoveObsolete },
+		rules:   func(in *rbacInput) []rbacRule { return resourceRules(in.obsoleteResources, "list") },
+	},
+	{
+		feature: "pruned CRDs instances",
+		options: []string{"Prune"},
+		enabled: func(in *rbacInput) bool {
+			return in.writes() && in.opts.Prune && !in.opts.PruneForce && !in.opts.Cascade
+		},
+		rules: prunedRules("list"),
+	},
+	{
+		feature: "cascade",
+		options: []string{"Cascade"},
+		enabled: func(in *rbacInput) bool {
+			return in.writes() && in.opts.Cascade && (in.opts.RemoveObsolete || in.opts.Prune || in.opts.ApplySet != nil)
+		},
+		rules: cascadeRules,
+	},
+	{
+		feature: "CRD protection",
+		options: []string{"ProtectCRDs"},
+		enabled: func(in *rbacInput) bool { return in.writes() && in.opts.ProtectCRDs },
+		rules: func(*rbacInput) []rbacRule {
+			const group = "admissionregistration.k8s.io"
+			return append(ownedObjectRules("", group, "validatingadmissionpolicies", ProtectionPolicyName),
+				ownedObjectRules("", group, "validatingadmissionpolicybindings", ProtectionPolicyName)...)
+		},
+	},
+	{
+		feature: "drift events",
+		options: []string{"EventRecorder"},
+		enabled: func(in *rbacInput) bool { return in.Mode == RunModeController && in.opts.ObserveOnly },
+		rules: func(*rbacInput) []rbacRule {
+			// Events regarding cluster-scoped objects, such as CRDs, go to the default namespace
+			return []rbacRule{namespacedRule(metav1.NamespaceDefault, "events.k8s.io", "events", "create", "patch")}
+		},
+	},
+	{
+		feature: "circuit breaker events",
+		options: []string{"CircuitBreaker"},
+		enabled: func(in *rbacInput) bool { return in.Mode == RunModeController && in.opts.CircuitBreaker != nil },
+		rules: func(*rbacInput) []rbacRule {
+			return []rbacRule{namespacedRule(metav1.NamespaceDefault, "events.k8s.io", "events", "create", "patch")}
+		},
+	},
+}
+
+// rbacInput is an RBACConfig with the resources its rules target
+type rbacInput struct {
+	*RBACConfig
+	opts *Options
+
+	// bundleResources and obsoleteResources are, per API group, the resources
+	// of the selected bundle CRDs and of the retired ones
+	bundleResources   map[string][]string
+	obsoleteResources map[string][]string
+
+	// webhookNamespaces are the namespaces of the conversion webhook services
+	webhookNamespaces []string
+
+	serviceAccountNamespace string
+}
+
+// deploys returns true when CRDs are deployed, or compared in observe-only mode
+func (in *rbacInput) deploys() bool {
+	return in.Mode == RunModeOneShot || in.Mode == RunModeController
+}
+
+// writes returns true when CRDs are deployed
+func (in *rbacInput) writes() bool {
+	return in.deploys() && !in.opts.ObserveOnly
+}
+
+// creates returns true if the run may create CRDs: when it writes or, in
+// observe-only mode, creates the missing ones
+func (in *rbacInput) creates() bool {
+	return in.writes() || (in.deploys() && in.opts.CreateMissing)
+}
+
+func (in *rbacInput) clusterProfile() *ClusterProfileOptions {
+	if in.ClusterProfile != nil {
+		return in.ClusterProfile
+	}
+	return &ClusterProfileOptions{}
+}
+
+func (in *rbacInput) historyNamespace() string {
+	if in.opts.History != nil && in.opts.History.Namespace != "" {
+		return in.opts.History.Namespace
+	}
+	return ConfigMapNamespace
+}
+
+// RequiredRBAC returns the least permissions crd-manager needs to run with config
+func RequiredRBAC(config *RBACConfig) (*RBACRules, error) {
+	in, err := newRBACInput(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []rbacRule
+	for i := range rbacRequirements {
+		if rbacRequirements[i].enabled(in) {
+			rules = append(rules, rbacRequirements[i].rules(in)...)
+		}
+	}
+
+	result := &RBACRules{Namespaced: map[string][]rbacv1.PolicyRule{}}
+	for _, r := range rules {
+		if r.namespace == "" {
+			result.Cluster = mergePolicyRule(result.Cluster, r.rule)
+		} else {
+			result.Namespaced[r.namespace] = mergePolicyRule(result.Namespaced[r.namespace], r.rule)
+		}
+	}
+	return result, nil
+}
+
+func newRBACInput(config *RBACConfig) (*rbacInput, error) {
+	copied := *config
+	in := &rbacInput{RBACConfig: &copied, opts: config.Options}
+	if in.Mode == "" {
+		in.Mode = RunModeOneShot
+	}
+	if in.opts == nil {
+		in.opts = &Options{}
+	}
+	if in.ServiceAccount == "" {
+		in.ServiceAccount = DefaultProtectionServiceAccount
+	}
+	if err := validateNamespacedName(in.ServiceAccount); err != nil {
+		return nil, fmt.Errorf("invalid service account %q: %w", in.ServiceAccount, err)
+	}
+	in.serviceAccountNamespace, _, _ = strings.Cut(in.ServiceAccount, "/")
+	if err := in.opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	bundleCRDs, err := prepareBundleCRDs(context.Background(), in.opts.getBundle().Content, in.opts, logr.Discard())
+	if err != nil {
+		return nil, err
+	}
+	in.bundleResources = map[string][]string{}
+	for _, crd := range selectComponents(bundleCRDs, in.opts) {
+		addCRDResource(in.bundleResources, crd.desired.GetName())
+		namespace, _, _ := unstructured.NestedString(crd.desired.Object,
+			"spec", "conversion", "webhook", "clientConfig", "service", "namespace")
+		if usesWebhookConversion(crd.desired) && namespace != "" && !slices.Contains(in.webhookNamespaces, namespace) {
+			in.webhookNamespaces = append(in.webhookNamespaces, namespace)
+		}
+	}
+	in.obsoleteResources = map[string][]string{}
+	for _, obsolete := range crds.ObsoleteCRDs() {
+		addCRDResource(in.obsoleteResources, obsolete.Name)
+	}
+	return in, nil
+}
+
+// addCRDResource adds to resources the resource of the CRD named name, which
+// is <plural>.<group>
+func addCRDResource(resources map[string][]string, name string) {
+	plural, group, _ := strings.Cut(name, ".")
+	if !slices.Contains(resources[group], plural) {
+		resources[group] = append(resources[group], plural)
+	}
+}
+
+// crdRules returns a rules function granting verbs on CRDs
+func crdRules(verbs ...string) func(*rbacInput) []rbacRule {
+	return func(*rbacInput) []rbacRule {
+		return []rbacRule{namespacedRule("", "apiextensions.k8s.io", "customresourcedefinitions", verbs...)}
+	}
+}
+
+func ownerRules(in *rbacInput) []rbacRule {
+	owner := in.opts.OwnerRef
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return nil
+	}
+	resource, _ := meta.UnsafeGuessKindToResource(gv.WithKind(owner.Kind))
+	return []rbacRule{namedRule("", gv.Group, resource.Resource, owner.Name, "get")}
+}
+
+// cascadeRules lets cascade delete the instances of obsolete CRDs and of
+// pruned CRDs
+func cascadeRules(in *rbacInput) []rbacRule {
+	var rules []rbacRule
+	if in.opts.RemoveObsolete {
+		rules = resourceRules(in.obsoleteResources, "list", "delete")
+	}
+	if in.opts.Prune || in.opts.ApplySet != nil {
+		rules = append(rules, prunedRules("list", "delete")(in)...)
+	}
+	return rules
+}
+
+// prunedRules grants verbs on the instances of pruned CRDs. As their
+// resources are not known beforehand, it covers any resource of the bundle
+// API groups.
+func prunedRules(verbs ...string) func(in *rbacInput) []rbacRule {
+	return func(in *rbacInput) []rbacRule {
+		var rules []rbacRule
+		for _, group := range slices.Sorted(maps.Keys(in.bundleResources)) {
+			rules = append(rules, namespacedRule("", group, "*", verbs...))
+		}
+		return rules
+	}
+}
+
+// resourceRules returns cluster-wide rules granting verbs on resources, per API group
+func resourceRules(resources map[string][]string, verbs ...string) []rbacRule {
+	rules := make([]rbacRule, 0, len(resources))
+	for _, group := range slices.Sorted(maps.Keys(resources)) {
+		names := slices.Sorted(slices.Values(resources[group]))
+		rules = append(rules, rbacRule{rule: rbacv1.PolicyRule{
+			APIGroups: []string{group}, Resources: names, Verbs: verbs,
+		}})
+	}
+	return rules
+}
+
+// namespacedRule grants verbs on resource in namespace, or cluster-wide when empty
+func namespacedRule(namespace, group, resource string, verbs ...string) rbacRule {
+	return rbacRule{namespace: namespace, rule: rbacv1.PolicyRule{
+		APIGroups: []string{group}, Resources: []string{resource}, Verbs: verbs,
+	}}
+}
+
+// namedRule grants verbs on the object name only
+func namedRule(namespace, group, resource, name string, verbs ...string) rbacRule {
+	r := namespacedRule(namespace, group, resource, verbs...)
+	r.rule.ResourceNames = []string{name}
+	return r
+}
+
+// ownedObjectRules grants what creating, then updating, the object name
+// needs. Create requests cannot be restricted to an object name.
+func ownedObjectRules(namespace, group, resource, name string) []rbacRule {
+	return []rbacRule{
+		namedRule(namespace, group, resource, name, "get", "update"),
+		namespacedRule(namespace, group, resource, "create"),
+	}
+}
+
+// mergePolicyRule adds rule to rules, merging its verbs in the rule, if any,
+// targeting the same resources
+func mergePolicyRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	for i := range rules {
+		if slices.Equal(rules[i].APIGroups, rule.APIGroups) && slices.Equal(rules[i].Resources, rule.Resources) &&
+			slices.Equal(rules[i].ResourceNames, rule.ResourceNames) &&
+			slices.Equal(rules[i].NonResourceURLs, rule.NonResourceURLs) {
+
+			for _, verb := range rule.Verbs {
+				if !slices.Contains(rules[i].Verbs, verb) {
+					rules[i].Verbs = append(rules[i].Verbs, verb)
+				}
+			}
+			return rules
+		}
+	}
+	rule.Verbs = slices.Clone(rule.Verbs)
+	return append(rules, rule)
+}
+
+// PrintRBAC writes to w, as a multi-document YAML, a ClusterRole and a Role
+// per namespace granting the permissions config needs, and their bindings to
+// the config ServiceAccount. No cluster is contacted.
+func PrintRBAC(w io.Writer, config *RBACConfig) error {
+	rules, err := RequiredRBAC(config)
+	if err != nil {
+		return err
+	}
+
+	namespace, name, _ := strings.Cut(config.ServiceAccount, "/")
+	if config.ServiceAccount == "" {
+		namespace, name, _ = strings.Cut(DefaultProtectionServiceAccount, "/")
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}}
+
+	objs := []any{
+		&rbacv1.ClusterRole{TypeMeta: rbacTypeMeta("ClusterRole"), ObjectMeta: metav1.ObjectMeta{Name: RBACName},
+			Rules: rules.Cluster},
+		&rbacv1.ClusterRoleBinding{TypeMeta: rbacTypeMeta("ClusterRoleBinding"),
+			ObjectMeta: metav1.ObjectMeta{Name: RBACName}, Subjects: subjects,
+			RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: RBACName}},
+	}
+	for _, ns := range slices.Sorted(maps.Keys(rules.Namespaced)) {
+		objMeta := metav1.ObjectMeta{Namespace: ns, Name: RBACName}
+		objs = append(objs,
+			&rbacv1.Role{TypeMeta: rbacTypeMeta("Role"), ObjectMeta: objMeta, Rules: rules.Namespaced[ns]},
+			&rbacv1.RoleBinding{TypeMeta: rbacTypeMeta("RoleBinding"), ObjectMeta: objMeta, Subjects: subjects,
+				RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: RBACName}})
+	}
+
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal RBAC: %w", err)
+		}
+		if _, err := io.WriteString(w, yamlSeparator); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rbacTypeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: kind}
+}
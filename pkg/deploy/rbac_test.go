@@ -0,0 +1,272 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("RBAC", func() {
+	const crdGroup = "apiextensions.k8s.io"
+
+	required := func(config *deploy.RBACConfig) *deploy.RBACRules {
+		rules, err := deploy.RequiredRBAC(config)
+		Expect(err).To(BeNil())
+		return rules
+	}
+
+	// verbs returns the verbs rules grant on resource of group, for any object names
+	verbs := func(rules []rbacv1.PolicyRule, group, resource string) []string {
+		var result []string
+		for i := range rules {
+			if len(rules[i].ResourceNames) == 0 && slices.Contains(rules[i].APIGroups, group) &&
+				slices.Contains(rules[i].Resources, resource) {
+
+				result = append(result, rules[i].Verbs...)
+			}
+		}
+		return result
+	}
+
+	It("has every Options field declare the permissions it needs", func() {
+		declared := map[string]bool{}
+		for _, name := range deploy.RBACDeclaredOptions() {
+			declared[name] = true
+		}
+		t := reflect.TypeFor[deploy.Options]()
+		for i := range t.NumField() {
+			if field := t.Field(i); field.IsExported() {
+				Expect(declared).To(HaveKey(field.Name),
+					"Options.%s must be listed by an RBAC requirement or as needing no permission", field.Name)
+			}
+		}
+	})
+
+	It("only grants read access to CRDs in observe-only mode", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{ObserveOnly: true}})
+		Expect(verbs(rules.Cluster, crdGroup, "customresourcedefinitions")).To(ConsistOf("get", "list"))
+		Expect(rules.Namespaced).To(BeEmpty())
+
+		rules = required(&deploy.RBACConfig{Mode: deploy.RunModeController, Options: &deploy.Options{ObserveOnly: true}})
+		Expect(verbs(rules.Cluster, crdGroup, "customresourcedefinitions")).To(ConsistOf("get", "list", "watch"))
+		Expect(verbs(rules.Namespaced["default"], "events.k8s.io", "events")).To(ConsistOf("create", "patch"))
+	})
+
+	It("grants the circuit breaker events in controller mode only", func() {
+		breaker := &deploy.CircuitBreaker{}
+		rules := required(&deploy.RBACConfig{Mode: deploy.RunModeController, Options: &deploy.Options{CircuitBreaker: breaker}})
+		Expect(verbs(rules.Namespaced["default"], "events.k8s.io", "events")).To(ConsistOf("create", "patch"))
+
+		rules = required(&deploy.RBACConfig{Mode: deploy.RunModeController,
+			Options: &deploy.Options{CircuitBreaker: breaker, ObserveOnly: true}})
+		Expect(verbs(rules.Namespaced["default"], "events.k8s.io", "events")).To(ConsistOf("create", "patch"))
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{CircuitBreaker: breaker}})
+		Expect(rules.Namespaced).ToNot(HaveKey("default"))
+	})
+
+	It("grants delete on CRDs only when CRDs are removed", func() {
+		rules := required(&deploy.RBACConfig{})
+		Expect(verbs(rules.Cluster, crdGroup, "customresourcedefinitions")).To(ConsistOf("get", "list", "create", "update"))
+		Expect(rules.Namespaced[deploy.ConfigMapNamespace]).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{deploy.ConfigMapName},
+			Verbs: []string{"get"},
+		}))
+
+		for _, config := range []*deploy.RBACConfig{
+			{Options: &deploy.Options{RemoveObsolete: true}},
+			{Options: &deploy.Options{Prune: true}},
+			{Options: &deploy.Options{ApplySet: &deploy.ApplySet{Kind: deploy.ApplySetParentSecret, Namespace: "ns", Name: "set"}}},
+			{Mode: deploy.RunModeController, DeleteOnShutdown: true},
+		} {
+			Expect(verbs(required(config).Cluster, crdGroup, "customresourcedefinitions")).To(ContainElement("delete"))
+		}
+		Expect(verbs(required(&deploy.RBACConfig{DeleteOnShutdown: true}).Cluster, crdGroup,
+			"customresourcedefinitions")).ToNot(ContainElement("delete"))
+	})
+
+	It("grants patch on CRDs to the server-side apply strategy, also when only creating the missing ones", func() {
+		serverSide := &deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide}
+		Expect(verbs(required(&deploy.RBACConfig{Options: serverSide}).Cluster, crdGroup,
+			"customresourcedefinitions")).To(ContainElements("create", "patch"))
+
+		serverSide = &deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide, ObserveOnly: true, CreateMissing: true}
+		Expect(verbs(required(&deploy.RBACConfig{Options: serverSide}).Cluster, crdGroup,
+			"customresourcedefinitions")).To(ContainElements("create", "patch"))
+
+		serverSide = &deploy.Options{ApplyStrategy: deploy.ApplyStrategyServerSide, ObserveOnly: true}
+		Expect(verbs(required(&deploy.RBACConfig{Options: serverSide}).Cluster, crdGroup,
+			"customresourcedefinitions")).ToNot(ContainElement("patch"))
+	})
+
+	It("restricts the objects crd-manager owns to their namespace and name", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{
+			ApplySet: &deploy.ApplySet{Kind: deploy.ApplySetParentConfigMap, Namespace: "sets", Name: "crds"},
+			Lock:     &deploy.Lock{Namespace: "locks", Name: "crd-manager", Identity: "run"},
+			History:  &deploy.History{Namespace: "runs"},
+		}})
+		Expect(rules.Namespaced["sets"]).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"crds"},
+			Verbs: []string{"get", "update"},
+		}))
+		Expect(verbs(rules.Namespaced["sets"], "", "configmaps")).To(ConsistOf("create"))
+		Expect(verbs(rules.Namespaced["locks"], "coordination.k8s.io", "leases")).To(ConsistOf("create"))
+		Expect(verbs(rules.Namespaced["projectsveltos"], "", "pods")).To(ConsistOf("get"))
+		Expect(rules.Namespaced["runs"]).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{deploy.HistoryConfigMapName},
+			Verbs: []string{"get", "update"},
+		}))
+
+		rules = required(&deploy.RBACConfig{Mode: deploy.RunModeHistory, Options: &deploy.Options{
+			History: &deploy.History{Namespace: "runs"},
+		}})
+		Expect(rules.Cluster).To(BeEmpty())
+		Expect(rules.Namespaced).To(Equal(map[string][]rbacv1.PolicyRule{"runs": {{
+			APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{deploy.HistoryConfigMapName},
+			Verbs: []string{"get"},
+		}}}))
+	})
+
+	It("grants access to the Sveltos resources of the selected components only", func() {
+		opts := &deploy.Options{SmokeTest: true, CheckExistingCRs: true, Components: []string{crds.ComponentAddons}}
+		rules := required(&deploy.RBACConfig{Options: opts})
+		Expect(verbs(rules.Cluster, "config.projectsveltos.io", "clusterprofiles")).To(ConsistOf("list", "create"))
+		Expect(verbs(rules.Cluster, "lib.projectsveltos.io", "techsupports")).To(BeEmpty())
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{}})
+		Expect(verbs(rules.Cluster, "config.projectsveltos.io", "clusterprofiles")).To(BeEmpty())
+	})
+
+	It("grants the conversion webhook check access to the webhook service namespace", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{Bundle: testBundle()}})
+		Expect(verbs(rules.Namespaced["system"], "", "services")).To(ConsistOf("get"))
+		Expect(verbs(rules.Namespaced["system"], "discovery.k8s.io", "endpointslices")).To(ConsistOf("list"))
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{Bundle: testBundle(), SkipConversionCheck: true}})
+		Expect(rules.Namespaced).ToNot(HaveKey("system"))
+	})
+
+	It("grants the ClusterProfile mode access to the ClusterProfile and its ConfigMaps only", func() {
+		rules := required(&deploy.RBACConfig{Mode: deploy.RunModeClusterProfile,
+			ClusterProfile: &deploy.ClusterProfileOptions{Name: "crds", Namespace: "profiles"}})
+		Expect(rules.Cluster).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{"config.projectsveltos.io"}, Resources: []string{"clusterprofiles"},
+				ResourceNames: []string{"crds"}, Verbs: []string{"get", "update"}},
+			rbacv1.PolicyRule{APIGroups: []string{"config.projectsveltos.io"}, Resources: []string{"clusterprofiles"},
+				Verbs: []string{"create"}},
+		))
+		Expect(rules.Namespaced).To(HaveLen(1))
+		Expect(verbs(rules.Namespaced["profiles"], "", "configmaps")).To(
+			ConsistOf("get", "create", "update", "list", "delete"))
+	})
+
+	It("grants the check mode get on CRDs only", func() {
+		rules := required(&deploy.RBACConfig{Mode: deploy.RunModeCheck, Options: &deploy.Options{Bundle: testBundle()}})
+		Expect(rules.Namespaced).To(BeEmpty())
+		Expect(rules.Cluster).To(ConsistOf(rbacv1.PolicyRule{
+			APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"},
+			Verbs: []string{"get"},
+		}))
+	})
+
+	It("grants the provenance mode list on CRDs only", func() {
+		rules := required(&deploy.RBACConfig{Mode: deploy.RunModeProvenance})
+		Expect(rules.Namespaced).To(BeEmpty())
+		Expect(rules.Cluster).To(ConsistOf(rbacv1.PolicyRule{
+			APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"},
+			Verbs: []string{"list"},
+		}))
+	})
+
+	It("grants the rollback delete on CRDs and list on the Sveltos resources", func() {
+		opts := &deploy.Options{RollbackOnFailure: true, Components: []string{crds.ComponentAddons}}
+		rules := required(&deploy.RBACConfig{Options: opts})
+		Expect(verbs(rules.Cluster, "apiextensions.k8s.io", "customresourcedefinitions")).To(ContainElement("delete"))
+		Expect(verbs(rules.Cluster, "config.projectsveltos.io", "clusterprofiles")).To(ConsistOf("list"))
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{RollbackOnFailure: true, ObserveOnly: true}})
+		Expect(verbs(rules.Cluster, "apiextensions.k8s.io", "customresourcedefinitions")).ToNot(ContainElement("delete"))
+	})
+
+	It("grants observe-only mode create on CRDs to create the missing ones", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{ObserveOnly: true}})
+		Expect(verbs(rules.Cluster, "apiextensions.k8s.io", "customresourcedefinitions")).ToNot(ContainElement("create"))
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{ObserveOnly: true, CreateMissing: true}})
+		Expect(verbs(rules.Cluster, "apiextensions.k8s.io", "customresourcedefinitions")).To(ContainElement("create"))
+		Expect(verbs(rules.Cluster, "apiextensions.k8s.io", "customresourcedefinitions")).ToNot(ContainElement("update"))
+	})
+
+	It("grants the version marker access to its ConfigMap only", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{
+			VersionMarker: &deploy.VersionMarker{Namespace: "markers", Name: "crd-bundle"},
+		}})
+		Expect(rules.Namespaced["markers"]).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"},
+				ResourceNames: []string{"crd-bundle"}, Verbs: []string{"get", "update"}},
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create"}},
+		))
+
+		rules = required(&deploy.RBACConfig{Options: &deploy.Options{ObserveOnly: true,
+			VersionMarker: &deploy.VersionMarker{Namespace: "markers", Name: "crd-bundle"}}})
+		Expect(rules.Namespaced).ToNot(HaveKey("markers"))
+	})
+
+	It("grants get on the owner", func() {
+		rules := required(&deploy.RBACConfig{Options: &deploy.Options{
+			OwnerRef: &deploy.OwnerRef{APIVersion: "v1", Kind: "Namespace", Name: "sveltos"},
+		}})
+		Expect(rules.Cluster).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"sveltos"},
+			Verbs: []string{"get"},
+		}))
+	})
+
+	It("prints roles and bindings for the service account", func() {
+		var buf bytes.Buffer
+		Expect(deploy.PrintRBAC(&buf, &deploy.RBACConfig{ServiceAccount: "tools/installer"})).To(Succeed())
+
+		docs := strings.Split(strings.TrimPrefix(buf.String(), "---\n"), "---\n")
+		Expect(docs).To(HaveLen(4))
+
+		clusterRole := &rbacv1.ClusterRole{}
+		Expect(yaml.Unmarshal([]byte(docs[0]), clusterRole)).To(Succeed())
+		Expect(clusterRole.Kind).To(Equal("ClusterRole"))
+		Expect(clusterRole.Rules).ToNot(BeEmpty())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(yaml.Unmarshal([]byte(docs[3]), binding)).To(Succeed())
+		Expect(binding.Namespace).To(Equal(deploy.ConfigMapNamespace))
+		Expect(binding.RoleRef.Kind).To(Equal("Role"))
+		Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "tools",
+			Name: "installer"}))
+
+		Expect(deploy.PrintRBAC(&buf, &deploy.RBACConfig{ServiceAccount: "installer"})).ToNot(Succeed())
+	})
+})
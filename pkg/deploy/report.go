@@ -0,0 +1,407 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+)
+
+// ReportSchemaVersion is the version of the Report JSON schema. It must be
+// bumped on any backward incompatible change to Report or CRDResult.
+const ReportSchemaVersion = "v1"
+
+// Action is what crd-manager did (or tried to do) with a CRD
+type Action string
+
+const (
+	// ActionCreated means the CRD was not present and has been created
+	ActionCreated = Action("created")
+
+	// ActionUpdated means the CRD was present and has been updated
+	ActionUpdated = Action("updated")
+
+	// ActionUnchanged means the CRD was present and already matching the bundle
+	ActionUnchanged = Action("unchanged")
+
+	// ActionSkippedHelm means the CRD is managed by Helm and was left untouched
+	ActionSkippedHelm = Action("skipped-helm")
+
+	// ActionSkippedArgoCD means the CRD is tracked by Argo CD and was left untouched
+	ActionSkippedArgoCD = Action("skipped-argocd")
+
+	// ActionSkippedPolicy means the ownership policy excluded the CRD, which was
+	// left untouched
+	ActionSkippedPolicy = Action("skipped-policy")
+
+	// ActionAdopted means the CRD was present without ownership markers, which
+	// have been added. Its spec is updated from the next run on.
+	ActionAdopted = Action("adopted")
+
+	// ActionObserved means the CRD was only compared with the bundle, in
+	// observe-only mode
+	ActionObserved = Action("observed")
+
+	// ActionPaused means the live CRD carries the paused annotation and was
+	// left untouched. It is managed again once the annotation is removed.
+	ActionPaused = Action("paused")
+
+	// ActionPinned means the live CRD is pinned, by the pin bundle version
+	// annotation, at another version than the bundle one and was left
+	// untouched. It is managed again once the versions match or the annotation
+	// is removed.
+	ActionPinned = Action("pinned")
+
+	// ActionTerminating means the CRD is being deleted, its deletion blocked
+	// by the finalizers of its instances, and was left untouched
+	ActionTerminating = Action("terminating")
+
+	// ActionRecreated means the CRD was terminating and has been created
+	// again once its deletion completed
+	ActionRecreated = Action("recreated")
+
+	// ActionDeferred means the CRD was not processed during this run, for
+	// instance because it is backing off after failures
+	ActionDeferred = Action("deferred")
+
+	// ActionQuarantined means the CRD was not processed because it kept
+	// failing with the same error. It is attempted again once its quarantine
+	// is cleared or its definition in the bundle changes.
+	ActionQuarantined = Action("quarantined")
+
+	// ActionRemovedObsolete means the CRD, retired from Sveltos, has been deleted
+	ActionRemovedObsolete = Action("removed-obsolete")
+
+	// ActionPruned means the CRD, managed by crd-manager or a member of the
+	// ApplySet but no longer part of the bundle, has been deleted
+	ActionPruned = Action("pruned")
+
+	// ActionNotAttempted means the run stopped, with FailFast, at
+	// the failure of a previous CRD, or because the CircuitBreaker is open
+	ActionNotAttempted = Action("not-attempted")
+
+	// ActionRolledBack means the CRD failed the post-apply verification of
+	// RollbackOnFailure and was restored to its spec before the run or, created
+	// by the run, deleted. Error is the verification failure.
+	ActionRolledBack = Action("rolled-back")
+
+	// ActionFailed means the CRD could not be processed
+	ActionFailed = Action("failed")
+)
+
+// DriftStatus tells whether a live CRD matches the bundle
+type DriftStatus string
+
+const (
+	// DriftStatusInSync means the live CRD spec matches the bundle
+	DriftStatusInSync = DriftStatus("in-sync")
+
+	// DriftStatusDrifted means the live CRD spec differs from the bundle
+	DriftStatusDrifted = DriftStatus("drifted")
+
+	// DriftStatusMissing means the CRD is not present in the cluster. Only
+	// reported in observe-only mode.
+	DriftStatusMissing = DriftStatus("missing")
+)
+
+// RunStatus is the overall outcome of a run
+type RunStatus string
+
+const (
+	// RunStatusSuccess means every CRD was processed without errors
+	RunStatusSuccess = RunStatus("success")
+
+	// RunStatusFailed means at least one CRD could not be processed
+	RunStatusFailed = RunStatus("failed")
+
+	// RunStatusPaused means crd-manager is paused and no CRD was processed
+	RunStatusPaused = RunStatus("paused")
+)
+
+// SignatureStatus reports whether the bundle signature was verified
+type SignatureStatus string
+
+const (
+	// SignatureVerified means the bundle signature was successfully verified
+	SignatureVerified = SignatureStatus("verified")
+
+	// SignatureNotVerified means the bundle comes from an external source and
+	// no verification key was configured
+	SignatureNotVerified = SignatureStatus("not-verified")
+
+	// SignatureExempt means the bundle is the embedded one, which is trusted
+	SignatureExempt = SignatureStatus("exempt")
+)
+
+// CRDResult describes what happened to a single CRD during a run
+type CRDResult struct {
+	// Name is the CustomResourceDefinition name
+	Name string `json:"name"`
+
+	// Component is the Sveltos component the CRD belongs to, if any
+	Component string `json:"component,omitempty"`
+
+	// Source is, when the bundle is merged from several sources, the source
+	// the CRD is taken from
+	Source string `json:"source,omitempty"`
+
+	// Provenance is, when the bundle is merged from several sources, the
+	// type, location and digest of the source the CRD is taken from
+	Provenance *bundle.Provenance `json:"provenance,omitempty"`
+
+	// Action is the action taken on the CRD
+	Action Action `json:"action"`
+
+	// Drift reports whether, at the end of the run, the live CRD spec matches
+	// the bundle. CRDs left untouched because another tool owns them are
+	// compared with the bundle without mutations. It is not set when processing
+	// the CRD failed.
+	Drift DriftStatus `json:"drift,omitempty"`
+
+	// ChangedPaths lists, in observe-only mode, the labels, annotations and
+	// spec paths of a drifted CRD an update would change
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+
+	// Changes lists, in observe-only mode with Options.Diff, the fields of a
+	// drifted CRD an update would change, with their live and bundle values
+	Changes []FieldChange `json:"changes,omitempty"`
+
+	// PinnedVersion is the version the live CRD is pinned at by the pin
+	// bundle version annotation, if any
+	PinnedVersion string `json:"pinnedVersion,omitempty"`
+
+	// Error is set when processing the CRD failed
+	Error string `json:"error,omitempty"`
+
+	// ErrorClass classifies Error: the reason of the API error or the
+	// denying admission webhook
+	ErrorClass string `json:"errorClass,omitempty"`
+
+	// RollbackError is set when the CRD failed the post-apply verification of
+	// RollbackOnFailure, reported by Error, and could not be rolled back
+	RollbackError string `json:"rollbackError,omitempty"`
+
+	// AdmissionDenial is set when the CRD could not be written because an
+	// admission webhook denied it
+	AdmissionDenial *AdmissionDenial `json:"admissionDenial,omitempty"`
+
+	// Size is the serialized size, in bytes, of the CRD applied
+	Size int64 `json:"size,omitempty"`
+
+	// Warnings are the warnings the API server returned while the CRD was
+	// processed, for instance for deprecated schema constructs
+	Warnings []string `json:"warnings,omitempty"`
+
+	// RemainingInstances lists, with Cascade, instances of the CRD still
+	// present once the cascade timeout expired, which kept it from being
+	// deleted. At most 20 are listed.
+	RemainingInstances []string `json:"remainingInstances,omitempty"`
+
+	// IncompatibleCRs reports, per CRD version, the existing objects not valid
+	// against the new schema. Only set with CheckExistingCRs.
+	IncompatibleCRs []IncompatibleCRs `json:"incompatibleCRs,omitempty"`
+
+	// ConsecutiveFailures is, in controller mode, the number of passes in a
+	// row processing the CRD failed
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	// NextRetry is, in controller mode, when a CRD which failed is processed again
+	NextRetry *metav1.Time `json:"nextRetry,omitempty"`
+
+	// Attempts is, when the CRD was retried within the run, how many times
+	// it was attempted
+	Attempts int `json:"attempts,omitempty"`
+
+	// Duration is the time spent processing the CRD
+	Duration metav1.Duration `json:"duration"`
+}
+
+// Report describes the outcome of a run
+type Report struct {
+	// SchemaVersion is the version of this JSON document schema
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Status is the overall outcome of the run
+	Status RunStatus `json:"status"`
+
+	// BundleDigest is the sha256 digest of the CRD bundle applied
+	BundleDigest string `json:"bundleDigest"`
+
+	// BundleVersion is the Sveltos version the bundle CRDs come from, when known
+	BundleVersion string `json:"bundleVersion"`
+
+	// BundleSource is where the CRD bundle comes from (embedded, its URL or
+	// the path of its archive)
+	BundleSource string `json:"bundleSource"`
+
+	// BundleArchiveDigest is the sha256 digest of the archive the CRD bundle
+	// was extracted from, if any
+	BundleArchiveDigest string `json:"bundleArchiveDigest,omitempty"`
+
+	// BundleSignature reports whether the CRD bundle signature was verified
+	BundleSignature SignatureStatus `json:"bundleSignature"`
+
+	// TargetCluster is the API server the CRDs were deployed to
+	TargetCluster string `json:"targetCluster,omitempty"`
+
+	// Duration is the time spent by the whole run
+	Duration metav1.Duration `json:"duration"`
+
+	// CRDs contains, in processing order, the per-CRD results
+	CRDs []CRDResult `json:"crds"`
+
+	// Removed contains the results of the CRDs not part of the bundle which
+	// were deleted, or could not be: pruned ApplySet members and obsolete CRDs
+	Removed []CRDResult `json:"removed,omitempty"`
+
+	// ObserveOnly is true if the run only compared the CRDs, without any write
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+
+	// ExtraCRDs lists, in observe-only mode, the CRDs carrying the crd-manager
+	// ownership label which are not part of the bundle
+	ExtraCRDs []string `json:"extraCRDs,omitempty"`
+
+	// SmokeTest contains, with the smoke test enabled, the outcome of the
+	// dry-run creation of a sample object for every served CRD version
+	SmokeTest []SmokeTestResult `json:"smokeTest,omitempty"`
+
+	// NameConflicts lists names bundle CRDs want but other CRDs already claim
+	NameConflicts []NameConflict `json:"nameConflicts,omitempty"`
+
+	// ParseFailures lists the bundle documents which could not be parsed
+	ParseFailures []ParseFailure `json:"parseFailures,omitempty"`
+
+	// CircuitBreaker is, when the CircuitBreaker stopped the run, its status
+	CircuitBreaker *CircuitBreakerStatus `json:"circuitBreaker,omitempty"`
+
+	// MaintenanceWindow is, in controller mode with maintenance windows, their
+	// state when the pass started
+	MaintenanceWindow *MaintenanceWindowStatus `json:"maintenanceWindow,omitempty"`
+
+	// Trigger is, in controller mode, manual for the passes requested through
+	// the reconcile endpoint. It is unset for scheduled passes.
+	Trigger string `json:"trigger,omitempty"`
+}
+
+// FieldChange is a field of a live CRD which differs from the bundle
+type FieldChange struct {
+	// Path is the path of the field, such as spec.names.shortNames
+	Path string `json:"path"`
+
+	// Live is the live value, unset when the field is not set
+	Live interface{} `json:"live,omitempty"`
+
+	// Desired is the value the bundle sets, unset when it does not set the field
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+// MaintenanceWindowStatus tells whether a controller pass ran inside a
+// maintenance window, hence applied changes, or only observed the CRDs
+type MaintenanceWindowStatus struct {
+	// Open is true inside a maintenance window
+	Open bool `json:"open"`
+
+	// ClosesAt is, inside a maintenance window, when it closes
+	ClosesAt *metav1.Time `json:"closesAt,omitempty"`
+
+	// NextStart is when the next maintenance window starts, unset when none
+	// starts within a year
+	NextStart *metav1.Time `json:"nextStart,omitempty"`
+}
+
+// Count returns the number of CRDs, bundle or removed ones, for which action was taken
+func (r *Report) Count(action Action) int {
+	count := 0
+	for i := range r.CRDs {
+		if r.CRDs[i].Action == action {
+			count++
+		}
+	}
+	for i := range r.Removed {
+		if r.Removed[i].Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// CountAdmissionDenied returns the number of CRDs whose write an admission
+// webhook denied
+func (r *Report) CountAdmissionDenied() int {
+	count := 0
+	for i := range r.CRDs {
+		if r.CRDs[i].AdmissionDenial != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// CountDrift returns the number of CRDs for which action was taken and whose
+// drift status is drift
+func (r *Report) CountDrift(action Action, drift DriftStatus) int {
+	count := 0
+	for i := range r.CRDs {
+		if r.CRDs[i].Action == action && r.CRDs[i].Drift == drift {
+			count++
+		}
+	}
+	return count
+}
+
+// Drifted returns the names of the CRDs whose drift status is drift
+func (r *Report) Drifted(drift DriftStatus) []string {
+	var names []string
+	for i := range r.CRDs {
+		if r.CRDs[i].Drift == drift {
+			names = append(names, r.CRDs[i].Name)
+		}
+	}
+	return names
+}
+
+// HasDrift returns true if a CRD is missing, drifted or, in observe-only mode,
+// managed by crd-manager while not part of the bundle
+func (r *Report) HasDrift() bool {
+	return len(r.Drifted(DriftStatusDrifted)) > 0 || len(r.Drifted(DriftStatusMissing)) > 0 ||
+		len(r.ExtraCRDs) > 0
+}
+
+func newReport(b *bundle.Bundle) *Report {
+	return &Report{
+		SchemaVersion:       ReportSchemaVersion,
+		Status:              RunStatusSuccess,
+		BundleDigest:        b.Digest(),
+		BundleVersion:       b.Version(),
+		BundleSource:        b.Source,
+		BundleSignature:     signatureStatus(b),
+		BundleArchiveDigest: b.ArchiveDigest,
+		CRDs:                make([]CRDResult, 0),
+	}
+}
+
+func signatureStatus(b *bundle.Bundle) SignatureStatus {
+	switch {
+	case b.IsEmbedded():
+		return SignatureExempt
+	case b.SignatureVerified:
+		return SignatureVerified
+	default:
+		return SignatureNotVerified
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Report", func() {
+	It("Deploy reports one result per bundle CRD", func() {
+		c := newFakeClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Status).To(Equal(deploy.RunStatusSuccess))
+		Expect(report.CRDs).ToNot(BeEmpty())
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(report.CRDs)))
+
+		report, err = deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.Count(deploy.ActionUnchanged)).To(Equal(len(report.CRDs)))
+	})
+
+	It("Deploy reports the drift of every CRD it processed", func() {
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CountDrift(deploy.ActionCreated, deploy.DriftStatusInSync)).To(Equal(len(report.CRDs)))
+	})
+
+	It("Deploy reports the source of every CRD of a merged bundle", func() {
+		override := &bundle.Bundle{Content: []byte(crdWithoutWebhook), Source: "override"}
+		merged, _, err := bundle.Merge([]*bundle.Bundle{bundle.Embedded(), override}, false)
+		Expect(err).To(BeNil())
+
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), &deploy.Options{Bundle: merged}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.BundleSource).To(Equal(bundle.EmbeddedSource + ", override"))
+		Expect(report.BundleSignature).To(Equal(deploy.SignatureNotVerified))
+		for i := range report.CRDs {
+			expected := bundle.EmbeddedSource
+			if report.CRDs[i].Name == "gadgets.lib.projectsveltos.io" {
+				expected = "override"
+			}
+			Expect(report.CRDs[i].Source).To(Equal(expected))
+		}
+
+		report, err = deploy.Deploy(context.TODO(), newFakeClient(), nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.CRDs[0].Source).To(BeEmpty())
+	})
+
+	It("JSON schema round trips into the Report struct", func() {
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), nil, logger)
+		Expect(err).To(BeNil())
+		report.TargetCluster = "https://127.0.0.1:6443"
+
+		data, err := json.Marshal(report)
+		Expect(err).To(BeNil())
+
+		fields := map[string]interface{}{}
+		Expect(json.Unmarshal(data, &fields)).To(Succeed())
+		Expect(fields).To(HaveKey("schemaVersion"))
+		Expect(fields).To(HaveKey("status"))
+		Expect(fields).To(HaveKey("bundleDigest"))
+		Expect(fields).To(HaveKey("bundleVersion"))
+		Expect(fields).To(HaveKey("targetCluster"))
+		Expect(fields).To(HaveKey("duration"))
+		Expect(fields).To(HaveKey("crds"))
+
+		decoded := &deploy.Report{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded).To(Equal(report))
+		Expect(decoded.SchemaVersion).To(Equal(deploy.ReportSchemaVersion))
+		Expect(decoded.BundleDigest).To(HavePrefix("sha256:"))
+		Expect(decoded.BundleSignature).To(Equal(deploy.SignatureExempt))
+	})
+})
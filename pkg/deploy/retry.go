@@ -0,0 +1,107 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultRetryInterval is the default delay before attempting a CRD
+	// again, with Retries
+	DefaultRetryInterval = time.Second
+
+	// maxRetryInterval caps the delay, doubling after every attempt, between
+	// two attempts of a CRD
+	maxRetryInterval = 30 * time.Second
+)
+
+func (o *Options) retryInterval() time.Duration {
+	if o.RetryInterval <= 0 {
+		return DefaultRetryInterval
+	}
+	return o.RetryInterval
+}
+
+// attemptCRD observes or applies crd and, while it fails because the API
+// server is unavailable, attempts it again up to Retries times. Every attempt
+// starts from the bundle object, not from the one a failed attempt mutated.
+func attemptCRD(ctx context.Context, c client.Client, crd *bundleCRD, opts *Options, result *CRDResult,
+	logger logr.Logger) error {
+
+	name := crd.desired.GetName()
+	interval := opts.retryInterval()
+	backedUp := false
+	var desired *unstructured.Unstructured
+	if opts.Retries > 0 {
+		desired = crd.desired.DeepCopy()
+	}
+	for attempt := 1; ; attempt++ {
+		*result = CRDResult{Name: name}
+		if attempt > 1 {
+			result.Attempts = attempt
+			crd.desired = desired.DeepCopy()
+		}
+
+		var err error
+		if !opts.ObserveOnly && !backedUp {
+			// Once written, the live CRD is no longer the one to roll back to
+			err = crd.backUp(ctx, c, opts)
+			backedUp = err == nil
+		}
+		if err == nil {
+			err = processBundleCRD(ctx, c, crd, opts, result, logger)
+		}
+		if err == nil || attempt > opts.Retries || !isServerUnavailable(err) {
+			return err
+		}
+
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s failed (attempt %d of %d), retrying in %s: %v",
+			name, attempt, opts.Retries+1, interval, err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+		interval = min(2*interval, maxRetryInterval)
+	}
+}
+
+// processBundleCRD observes or applies crd, once its size is checked
+func processBundleCRD(ctx context.Context, c client.Client, crd *bundleCRD, opts *Options, result *CRDResult,
+	logger logr.Logger) error {
+
+	u := crd.desired
+	if err := checkObjectSize(u, opts, result, logger); err != nil {
+		return err
+	}
+	var err error
+	if opts.ObserveOnly {
+		err = observeCustomResourceDefinition(ctx, c, crd.original, u, opts, result, logger)
+	} else {
+		err = processCustomResourceDefinition(ctx, c, crd.original, u, opts, result, logger)
+	}
+	return wrapSizeError(u.GetName(), result.Size, opts, err)
+}
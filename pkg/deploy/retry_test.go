@@ -0,0 +1,144 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Failures", func() {
+	var creates map[string]int
+
+	BeforeEach(func() {
+		creates = map[string]int{}
+	})
+
+	// newFailingClient returns a fake client whose creation of the CRDs
+	// named in failures fails, the first times times, with err
+	newFailingClient := func(err error, times int, failures ...string) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				creates[obj.GetName()]++
+				for _, name := range failures {
+					if obj.GetName() == name && creates[name] <= times {
+						return err
+					}
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+	}
+
+	crdResource := schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}
+
+	It("processes every CRD and lists all the failed ones", func() {
+		bundle := getBundleCRDs()
+		first, last := bundle[0].GetName(), bundle[len(bundle)-1].GetName()
+		c := newFailingClient(apierrors.NewForbidden(crdResource, first, errors.New("denied")), 1, first, last)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		var deployErr *deploy.DeployError
+		Expect(errors.As(err, &deployErr)).To(BeTrue())
+		Expect(deployErr.Failures).To(HaveLen(2))
+		Expect(deployErr.Failures[0].CRD).To(Equal(first))
+		Expect(deployErr.Failures[1].CRD).To(Equal(last))
+		Expect(err.Error()).To(HavePrefix("2 Sveltos CRDs failed: " + first + ": "))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(2))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(bundle) - 2))
+	})
+
+	It("attempts a CRD again, with Retries, while the API server is unavailable", func() {
+		name := getBundleCRDs()[0].GetName()
+		c := newFailingClient(apierrors.NewServiceUnavailable("overloaded"), 2, name)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{Retries: 2, RetryInterval: time.Millisecond}, logger)
+		Expect(err).To(BeNil())
+		Expect(creates[name]).To(Equal(3))
+		Expect(report.CRDs).To(ContainElement(And(HaveField("Name", name), HaveField("Action", deploy.ActionCreated),
+			HaveField("Attempts", 3))))
+	})
+
+	It("gives up once the retries are exhausted", func() {
+		name := getBundleCRDs()[0].GetName()
+		c := newFailingClient(apierrors.NewServiceUnavailable("overloaded"), 3, name)
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{Retries: 1, RetryInterval: time.Millisecond}, logger)
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		Expect(creates[name]).To(Equal(2))
+		Expect(report.CRDs).To(ContainElement(And(HaveField("Name", name), HaveField("Action", deploy.ActionFailed),
+			HaveField("Attempts", 2))))
+	})
+
+	It("does not retry errors not caused by the API server availability", func() {
+		name := getBundleCRDs()[0].GetName()
+		c := newFailingClient(apierrors.NewForbidden(crdResource, name, errors.New("denied")), 1, name)
+
+		_, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{Retries: 2, RetryInterval: time.Millisecond}, logger)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(creates[name]).To(Equal(1))
+	})
+
+	It("attempts a CRD again from the bundle object, not from the one the failed attempt mutated", func() {
+		live := getBundleCRD(sveltosClusterCRD)
+		live.Spec.Versions = append(live.Spec.Versions, *findVersion(withDroppedVersion(live.DeepCopy()), droppedVersion))
+		findVersion(live, droppedVersion).Storage = false
+		updates := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(live).WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if obj.GetName() != sveltosClusterCRD {
+					return c.Update(ctx, obj, opts...)
+				}
+				updates++
+				if updates > 1 {
+					return c.Update(ctx, obj, opts...)
+				}
+				// another actor drops the version while the API server is unavailable
+				current := getCRD(c, sveltosClusterCRD)
+				current.Spec.Versions = getBundleCRD(sveltosClusterCRD).Spec.Versions
+				Expect(c.Update(ctx, current)).To(Succeed())
+				return apierrors.NewServiceUnavailable("overloaded")
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c,
+			&deploy.Options{MergeVersions: true, Retries: 1, RetryInterval: time.Millisecond}, logger)
+		Expect(err).To(BeNil())
+		Expect(crdResult(report, sveltosClusterCRD).Attempts).To(Equal(2))
+		Expect(findVersion(getCRD(c, sveltosClusterCRD), droppedVersion)).To(BeNil())
+	})
+
+	It("rejects negative retries", func() {
+		Expect((&deploy.Options{Retries: -1}).Validate()).To(MatchError(ContainSubstring("invalid retries")))
+	})
+})
@@ -0,0 +1,273 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+const (
+	// DefaultRollbackTimeout is how long, by default, RollbackOnFailure waits
+	// for CRDs to be established
+	DefaultRollbackTimeout = time.Minute
+)
+
+// backUp records, with RollbackOnFailure, the live CRD before the run writes
+// it. A missing CRD leaves nothing to back up.
+func (crd *bundleCRD) backUp(ctx context.Context, c client.Client, opts *Options) error {
+	if !opts.RollbackOnFailure {
+		return nil
+	}
+	live := &apiextensionsv1.CustomResourceDefinition{}
+	if err := getCRD(ctx, c, crd.desired.GetName(), live); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	crd.backup = live
+	return nil
+}
+
+// verifyRun runs the post-apply verification of the selected crds: the smoke
+// test and, with RollbackOnFailure, the per CRD verification rolling back
+// the CRDs the run wrote which fail it
+func verifyRun(ctx context.Context, c client.Client, crds []*bundleCRD, opts *Options,
+	report *Report, logger logr.Logger) error {
+
+	selected := selectComponents(crds, opts)
+	if !opts.RollbackOnFailure {
+		if opts.SmokeTest {
+			return smokeTest(ctx, c, selected, opts, report, logger)
+		}
+		return nil
+	}
+
+	failures, err := verifyAppliedCRDs(ctx, c, selected, opts, report, logger)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return rollBack(ctx, c, selected, failures, opts, report, logger)
+}
+
+// verifyAppliedCRDs returns, per name, why CRDs among selected fail the
+// post-apply verification: they are not established once the rollback
+// timeout expires, their names are not accepted or, with SmokeTest, the
+// sample of one of their versions is rejected
+func verifyAppliedCRDs(ctx context.Context, c client.Client, selected []*bundleCRD, opts *Options,
+	report *Report, logger logr.Logger) (map[string]error, error) {
+
+	failures := map[string]error{}
+	timeout := opts.rollbackTimeout()
+	err := waitForCRDs(ctx, c, opts, timeout, waitInterval, logger)
+	var waitErr *WaitError
+	if errors.As(err, &waitErr) {
+		for _, name := range waitErr.Missing {
+			failures[name] = errors.New("not found")
+		}
+		for _, name := range waitErr.NotEstablished {
+			failures[name] = fmt.Errorf("not established within %s", timeout)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, crd := range selected {
+		name := crd.desired.GetName()
+		if failures[name] != nil {
+			continue
+		}
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		if err := getCRD(ctx, c, name, live); err != nil {
+			return nil, err
+		}
+		if problem := conditionProblem(live); problem != "" {
+			failures[name] = errors.New(problem)
+			continue
+		}
+		if opts.SmokeTest {
+			rejected, err := smokeTestCRD(ctx, c, crd, report, logger)
+			if err != nil {
+				return nil, err
+			}
+			if rejected > 0 {
+				failures[name] = fmt.Errorf("smoke test failed for %d CRD versions", rejected)
+			}
+		}
+	}
+	return failures, nil
+}
+
+// rollBack rolls back the CRDs among selected the run wrote and which failed
+// the post-apply verification: updated ones are restored to their spec
+// before the update, created ones are deleted. Their result keeps the
+// verification failure as error. The run fails either way.
+func rollBack(ctx context.Context, c client.Client, selected []*bundleCRD, failures map[string]error,
+	opts *Options, report *Report, logger logr.Logger) error {
+
+	rolledBack := 0
+	for _, crd := range selected {
+		name := crd.desired.GetName()
+		failure := failures[name]
+		if failure == nil {
+			continue
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s failed the post-apply verification: %v", name, failure))
+
+		result := findResult(report, name)
+		if result == nil || (result.Action != ActionUpdated && !isCreation(result.Action)) {
+			logWarning(logger, "Sveltos CRD %s fails the post-apply verification but was not written by this run, "+
+				"nothing to roll back", name)
+			continue
+		}
+
+		result.Error = failure.Error()
+		created := isCreation(result.Action)
+		var err error
+		if created {
+			err = deleteFailedCRD(ctx, c, name, opts, logger)
+		} else {
+			err = restoreCRD(ctx, c, crd.backup, opts, logger)
+		}
+		if err != nil {
+			logWarning(logger, "failed to roll back Sveltos CRD %s: %v", name, err)
+			result.Action = ActionFailed
+			result.Drift = ""
+			result.RollbackError = err.Error()
+			continue
+		}
+
+		rolledBack++
+		result.Action = ActionRolledBack
+		result.Drift = DriftStatusDrifted
+		if created {
+			result.Drift = ""
+		}
+	}
+	return fmt.Errorf("post-apply verification failed for %d CRDs, %d rolled back", len(failures), rolledBack)
+}
+
+// restoreCRD writes back backup, the live CRD before the run updated it, and
+// waits for it to be established again
+func restoreCRD(ctx context.Context, c client.Client, backup *apiextensionsv1.CustomResourceDefinition,
+	opts *Options, logger logr.Logger) error {
+
+	if backup == nil {
+		return errors.New("no backup of the CRD before the update")
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		if err := getCRD(ctx, c, backup.Name, live); err != nil {
+			return err
+		}
+		if live.UID != backup.UID {
+			return errors.New("the CRD was recreated since its backup")
+		}
+		restored := backup.DeepCopy()
+		restored.ResourceVersion = live.ResourceVersion
+		restored.ManagedFields = nil
+		return c.Update(ctx, restored)
+	})
+	err = auditWrite(opts, &AuditEntry{CRD: backup.Name, Action: AuditActionUpdate}, err, logger)
+	if err != nil {
+		return err
+	}
+
+	err = crds.WaitForCRDsWithOptions(ctx, c, []string{backup.Name}, &crds.WaitOptions{
+		Timeout:  opts.rollbackTimeout(),
+		Interval: waitInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("restored CRD: %w", err)
+	}
+	logWarning(logger, "rolled back Sveltos CRD %s to its spec before the update", backup.Name)
+	return nil
+}
+
+// deleteFailedCRD deletes the CRD named name the run created, with the
+// safety checks of DeleteCreatedCRDs. CRDs with instances are not deleted.
+func deleteFailedCRD(ctx context.Context, c client.Client, name string, opts *Options, logger logr.Logger) error {
+	live := &apiextensionsv1.CustomResourceDefinition{}
+	if err := getCRD(ctx, c, name, live); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if reason := deletionBlocker(live, opts); reason != "" {
+		return fmt.Errorf("CRD %s, not deleted", reason)
+	}
+	inUse, err := hasInstances(ctx, c, live)
+	// The resources of a CRD which never established are not served
+	if err != nil && !meta.IsNoMatchError(err) && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if inUse {
+		return errors.New("instances already exist, not deleted")
+	}
+
+	err = c.Delete(ctx, live, client.Preconditions{UID: &live.UID})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err = auditWrite(opts, &AuditEntry{CRD: name, Action: AuditActionDelete}, err, logger); err != nil {
+		return err
+	}
+	logWarning(logger, "rolled back Sveltos CRD %s by deleting it", name)
+	return nil
+}
+
+// isCreation returns true if action means the run created the CRD
+func isCreation(action Action) bool {
+	return action == ActionCreated || action == ActionRecreated
+}
+
+// findResult returns the result of the CRD named name in report, if any
+func findResult(report *Report, name string) *CRDResult {
+	for i := range report.CRDs {
+		if report.CRDs[i].Name == name {
+			return &report.CRDs[i]
+		}
+	}
+	return nil
+}
+
+func (o *Options) rollbackTimeout() time.Duration {
+	if o.RollbackTimeout <= 0 {
+		return DefaultRollbackTimeout
+	}
+	return o.RollbackTimeout
+}
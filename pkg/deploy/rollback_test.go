@@ -0,0 +1,229 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var errRestore = errors.New("restore denied")
+
+// setEstablished sets the Established condition of the CRD named name to status
+func setEstablished(ctx context.Context, c client.Client, name string, status apiextensionsv1.ConditionStatus) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+		return err
+	}
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.Established, Status: status},
+	}
+	return c.Status().Update(ctx, crd)
+}
+
+// rollbackOptions returns the options enabling RollbackOnFailure, with the
+// given timeout, on the CRDs of the events component
+func rollbackOptions(timeout time.Duration) *deploy.Options {
+	return &deploy.Options{RollbackOnFailure: true, RollbackTimeout: timeout, Components: []string{crds.ComponentEvents}}
+}
+
+var _ = Describe("RollbackOnFailure", func() {
+	var target *apiextensionsv1.CustomResourceDefinition
+	var liveCRDs, others []client.Object
+
+	BeforeEach(func() {
+		liveCRDs = establishedBundleCRDs()
+		others = nil
+		for _, obj := range liveCRDs {
+			if obj.GetName() == "eventsources.lib.projectsveltos.io" {
+				target = obj.(*apiextensionsv1.CustomResourceDefinition)
+			} else {
+				others = append(others, obj)
+			}
+		}
+		target.Spec.Names.ShortNames = append(target.Spec.Names.ShortNames, "before")
+	})
+
+	getLive := func(c client.Client, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		return live, c.Get(context.TODO(), types.NamespacedName{Name: name}, live)
+	}
+
+	It("restores the updated CRDs whose smoke test fails", func() {
+		var samples []*unstructured.Unstructured
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(liveCRDs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					u, ok := obj.(*unstructured.Unstructured)
+					if !ok || u.GetKind() == "CustomResourceDefinition" {
+						return c.Create(ctx, obj, opts...)
+					}
+					samples = append(samples, u)
+					if u.GetKind() == target.Spec.Names.Kind {
+						return errRejected
+					}
+					return nil
+				},
+			}).Build()
+
+		opts := rollbackOptions(time.Second)
+		opts.SmokeTest = true
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("1 rolled back"))
+		Expect(report.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(samples).ToNot(BeEmpty())
+
+		for _, result := range report.CRDs {
+			if result.Name != target.Name {
+				Expect(result.Action).To(Equal(deploy.ActionUpdated))
+				continue
+			}
+			Expect(result.Action).To(Equal(deploy.ActionRolledBack))
+			Expect(result.Error).To(ContainSubstring("smoke test failed"))
+			Expect(result.RollbackError).To(BeEmpty())
+			Expect(result.Drift).To(Equal(deploy.DriftStatusDrifted))
+		}
+
+		live, err := getLive(c, target.Name)
+		Expect(err).To(BeNil())
+		Expect(live.Spec).To(Equal(target.Spec))
+		Expect(live.Annotations).ToNot(HaveKey(deploy.BundleHashAnnotation))
+	})
+
+	It("restores the updated CRDs which are not established again", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(liveCRDs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if err := c.Update(ctx, obj, opts...); err != nil || obj.GetName() != target.Name {
+						return err
+					}
+					// The bundle CRD is applied as unstructured, the backup restored as typed
+					status := apiextensionsv1.ConditionTrue
+					if _, ok := obj.(*unstructured.Unstructured); ok {
+						status = apiextensionsv1.ConditionFalse
+					}
+					return setEstablished(ctx, c, target.Name, status)
+				},
+			}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, rollbackOptions(100*time.Millisecond), logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.Count(deploy.ActionRolledBack)).To(Equal(1))
+		Expect(report.Count(deploy.ActionUpdated)).To(Equal(len(report.CRDs) - 1))
+
+		live, err := getLive(c, target.Name)
+		Expect(err).To(BeNil())
+		Expect(live.Spec).To(Equal(target.Spec))
+	})
+
+	It("deletes the created CRDs which are not established", func() {
+		// Created CRDs report no condition until established
+		c := newFakeClient(others...)
+
+		report, err := deploy.Deploy(context.TODO(), c, rollbackOptions(100*time.Millisecond), logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.CRDs).To(ContainElement(And(
+			HaveField("Name", target.Name),
+			HaveField("Action", deploy.ActionRolledBack),
+			HaveField("Error", ContainSubstring("not established")),
+		)))
+
+		_, err = getLive(c, target.Name)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("keeps the created CRDs which already have instances", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(others...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					u, ok := list.(*unstructured.UnstructuredList)
+					if !ok || u.GetKind() != target.Spec.Names.ListKind {
+						return c.List(ctx, list, opts...)
+					}
+					u.Items = []unstructured.Unstructured{{}}
+					return nil
+				},
+			}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, rollbackOptions(100*time.Millisecond), logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("0 rolled back"))
+		Expect(report.CRDs).To(ContainElement(And(
+			HaveField("Name", target.Name),
+			HaveField("Action", deploy.ActionFailed),
+			HaveField("Error", ContainSubstring("not established")),
+			HaveField("RollbackError", ContainSubstring("instances")),
+		)))
+
+		_, err = getLive(c, target.Name)
+		Expect(err).To(BeNil())
+	})
+
+	It("reports the CRDs which cannot be restored", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(liveCRDs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if _, ok := obj.(*unstructured.Unstructured); !ok && obj.GetName() == target.Name {
+						return errRestore
+					}
+					if err := c.Update(ctx, obj, opts...); err != nil || obj.GetName() != target.Name {
+						return err
+					}
+					return setEstablished(ctx, c, target.Name, apiextensionsv1.ConditionFalse)
+				},
+			}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, rollbackOptions(100*time.Millisecond), logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.CRDs).To(ContainElement(And(
+			HaveField("Name", target.Name),
+			HaveField("Action", deploy.ActionFailed),
+			HaveField("Error", ContainSubstring("not established")),
+			HaveField("RollbackError", ContainSubstring(errRestore.Error())),
+		)))
+
+		live, err := getLive(c, target.Name)
+		Expect(err).To(BeNil())
+		Expect(live.Spec).ToNot(Equal(target.Spec))
+	})
+
+	It("rolls nothing back when the verification succeeds", func() {
+		c := newFakeClient(liveCRDs...)
+
+		report, err := deploy.Deploy(context.TODO(), c, rollbackOptions(time.Second), logger)
+		Expect(err).To(BeNil())
+		Expect(report.CRDs).ToNot(BeEmpty())
+		Expect(report.Count(deploy.ActionUpdated)).To(Equal(len(report.CRDs)))
+		Expect(report.Count(deploy.ActionRolledBack)).To(BeZero())
+	})
+})
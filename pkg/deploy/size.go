@@ -0,0 +1,135 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultMaxObjectSize is the default object size threshold, in bytes: the
+	// default etcd request size limit
+	DefaultMaxObjectSize = 1536 * 1024
+
+	// DefaultSizeWarningPercent is the default percentage of the object size
+	// threshold above which a warning is logged
+	DefaultSizeWarningPercent = 80
+)
+
+// ObjectSizeError is returned when the API server rejects a CRD write as too
+// large
+type ObjectSizeError struct {
+	// CRD is the name of the CRD
+	CRD string
+
+	// Size is the serialized size, in bytes, of the CRD written
+	Size int64
+
+	// MaxSize is the object size threshold, in bytes
+	MaxSize int64
+
+	// Err is the error the API server returned
+	Err error
+}
+
+func (e *ObjectSizeError) Error() string {
+	return fmt.Sprintf("CRD %s rejected as too large: its serialized size is %d bytes (%d%% of the %d bytes "+
+		"threshold): %v", e.CRD, e.Size, e.Size*100/e.MaxSize, e.MaxSize, e.Err)
+}
+
+func (e *ObjectSizeError) Unwrap() error {
+	return e.Err
+}
+
+func (o *Options) maxObjectSize() int64 {
+	if o.MaxObjectSize > 0 {
+		return o.MaxObjectSize
+	}
+	return DefaultMaxObjectSize
+}
+
+func (o *Options) sizeWarningPercent() int {
+	if o.SizeWarningPercent > 0 {
+		return o.SizeWarningPercent
+	}
+	return DefaultSizeWarningPercent
+}
+
+func (o *Options) validateObjectSize() error {
+	if o.MaxObjectSize < 0 {
+		return fmt.Errorf("invalid max object size %d: must not be negative", o.MaxObjectSize)
+	}
+	if o.SizeWarningPercent < 0 || o.SizeWarningPercent > 100 {
+		return fmt.Errorf("invalid size warning percentage %d: must be between 0 and 100", o.SizeWarningPercent)
+	}
+	return nil
+}
+
+// objectSize returns the serialized size, in bytes, of u
+func objectSize(u *unstructured.Unstructured) (int64, error) {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// checkObjectSize sets the serialized size of u in result and logs a warning
+// when it exceeds the warning percentage of the object size threshold
+func checkObjectSize(u *unstructured.Unstructured, opts *Options, result *CRDResult, logger logr.Logger) error {
+	size, err := objectSize(u)
+	if err != nil {
+		return fmt.Errorf("failed to serialize CRD %s: %w", u.GetName(), err)
+	}
+	result.Size = size
+
+	maxSize := opts.maxObjectSize()
+	if size*100 >= maxSize*int64(opts.sizeWarningPercent()) {
+		logWarning(logger, "Sveltos CRD %s serialized size is %d bytes, %d%% of the %d bytes threshold: "+
+			"the API server may soon reject it as too large", u.GetName(), size, size*100/maxSize, maxSize)
+	}
+	return nil
+}
+
+// wrapSizeError returns an ObjectSizeError when err rejects the write of a
+// CRD, whose serialized size is size, as too large
+func wrapSizeError(name string, size int64, opts *Options, err error) error {
+	if !isTooLarge(err) {
+		return err
+	}
+	var sizeErr *ObjectSizeError
+	if errors.As(err, &sizeErr) {
+		return err
+	}
+	return &ObjectSizeError{CRD: name, Size: size, MaxSize: opts.maxObjectSize(), Err: err}
+}
+
+// isTooLarge returns true if err is the API server, or etcd, rejecting a
+// request as too large
+func isTooLarge(err error) bool {
+	if err == nil {
+		return false
+	}
+	return apierrors.IsRequestEntityTooLargeError(err) || strings.Contains(err.Error(), "request is too large")
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Object size", func() {
+	const name = "clusterprofiles.config.projectsveltos.io"
+
+	It("reports the serialized size of each CRD", func() {
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), &deploy.Options{}, logger)
+		Expect(err).To(BeNil())
+		for i := range report.CRDs {
+			Expect(report.CRDs[i].Size).To(BeNumerically(">", 0), report.CRDs[i].Name)
+		}
+
+		data, err := json.Marshal(getBundleCRD(name))
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Size).To(BeNumerically("~", len(data), len(data)/10))
+	})
+
+	It("still applies CRDs exceeding the warning percentage", func() {
+		opts := &deploy.Options{MaxObjectSize: 1024, SizeWarningPercent: 50}
+		report, err := deploy.Deploy(context.TODO(), newFakeClient(), opts, logger)
+		Expect(err).To(BeNil())
+		Expect(findResult(report, name).Action).To(Equal(deploy.ActionCreated))
+	})
+
+	It("includes the measured size when the API server rejects a CRD as too large", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == name {
+					return apierrors.NewRequestEntityTooLargeError("limit is 3145728")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{}, logger)
+		Expect(err).ToNot(BeNil())
+
+		var sizeErr *deploy.ObjectSizeError
+		Expect(errors.As(err, &sizeErr)).To(BeTrue())
+		Expect(sizeErr.Size).To(Equal(findResult(report, name).Size))
+		Expect(sizeErr.MaxSize).To(Equal(int64(deploy.DefaultMaxObjectSize)))
+		Expect(apierrors.IsRequestEntityTooLargeError(err)).To(BeTrue())
+		Expect(findResult(report, name).Error).To(ContainSubstring("its serialized size is"))
+	})
+
+	It("rejects invalid thresholds", func() {
+		Expect((&deploy.Options{MaxObjectSize: -1}).Validate()).ToNot(Succeed())
+		Expect((&deploy.Options{SizeWarningPercent: 101}).Validate()).ToNot(Succeed())
+	})
+})
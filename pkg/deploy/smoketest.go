@@ -0,0 +1,249 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// smokeTestName is the name of the objects submitted by smoke tests
+	smokeTestName = "crd-manager-smoke-test"
+
+	// smokeTestNamespace is the namespace of the namespaced objects submitted
+	// by smoke tests
+	smokeTestNamespace = "default"
+
+	// smokeTestWaitTimeout is how long smoke tests wait for the CRDs to be established
+	smokeTestWaitTimeout = time.Minute
+
+	// sampleMinimalString is the value of required strings without constraint
+	sampleMinimalString = "sample"
+)
+
+// SmokeTestResult is the outcome of the dry-run creation of a sample object
+type SmokeTestResult struct {
+	// CRD is the CustomResourceDefinition name
+	CRD string `json:"crd"`
+
+	// Version is the CRD version of the sample object
+	Version string `json:"version"`
+
+	// Error is the API server rejection message, empty when the sample was accepted
+	Error string `json:"error,omitempty"`
+}
+
+// smokeTest submits, with server-side dry-run, a sample object for every
+// served version of crds. Nothing is ever persisted. Samples shipped in
+// pkg/crds are used when present, otherwise the minimal object satisfying
+// the schema required fields is generated.
+func smokeTest(ctx context.Context, c client.Client, crdList []*bundleCRD, opts *Options,
+	report *Report, logger logr.Logger) error {
+
+	// CRDs just created need to be established before their objects are accepted
+	if err := waitForCRDs(ctx, c, opts, smokeTestWaitTimeout, waitInterval, logger); err != nil {
+		return fmt.Errorf("smoke test: %w", err)
+	}
+
+	failures := 0
+	for _, b := range crdList {
+		rejected, err := smokeTestCRD(ctx, c, b, report, logger)
+		if err != nil {
+			return err
+		}
+		failures += rejected
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("smoke test failed for %d CRD versions", failures)
+	}
+	return nil
+}
+
+// smokeTestCRD submits, with server-side dry-run, a sample object for every
+// served version of b, adding the outcomes to report. It returns the number
+// of versions whose sample was rejected.
+func smokeTestCRD(ctx context.Context, c client.Client, b *bundleCRD, report *Report,
+	logger logr.Logger) (int, error) {
+
+	crd, err := toCustomResourceDefinition(b.desired)
+	if err != nil {
+		return 0, err
+	}
+
+	rejected := 0
+	for i := range crd.Spec.Versions {
+		if !crd.Spec.Versions[i].Served {
+			continue
+		}
+		result := SmokeTestResult{CRD: crd.Name, Version: crd.Spec.Versions[i].Name}
+		if err := smokeTestVersion(ctx, c, crd, &crd.Spec.Versions[i]); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("smoke test of CRD %s version %s failed: %v",
+				result.CRD, result.Version, err))
+			result.Error = err.Error()
+			rejected++
+		} else {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("smoke test of CRD %s version %s succeeded",
+				result.CRD, result.Version))
+		}
+		report.SmokeTest = append(report.SmokeTest, result)
+	}
+	return rejected, nil
+}
+
+// smokeTestVersion creates, with server-side dry-run, a sample object of the
+// given version of crd
+func smokeTestVersion(ctx context.Context, c client.Client, crd *apiextensionsv1.CustomResourceDefinition,
+	version *apiextensionsv1.CustomResourceDefinitionVersion) error {
+
+	sample, err := sampleObject(crd, version)
+	if err != nil {
+		return err
+	}
+	return c.Create(ctx, sample, client.DryRunAll)
+}
+
+// sampleObject returns the sample object of the given version of crd
+func sampleObject(crd *apiextensionsv1.CustomResourceDefinition,
+	version *apiextensionsv1.CustomResourceDefinitionVersion) (*unstructured.Unstructured, error) {
+
+	var u *unstructured.Unstructured
+	if data := crds.GetSample(crd.Name, version.Name); data != nil {
+		var err error
+		u, err = k8s_utils.GetUnstructured(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample: %w", err)
+		}
+	} else {
+		u = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+			schema := version.Schema.OpenAPIV3Schema
+			if generated, ok := sampleValue(schema).(map[string]interface{}); ok {
+				u.Object = generated
+			}
+			// spec is seldom required, but its own required fields are what
+			// the smoke test is meant to exercise
+			if spec, ok := schema.Properties["spec"]; ok {
+				u.Object["spec"] = sampleValue(&spec)
+			}
+		}
+		delete(u.Object, "status")
+	}
+
+	u.SetAPIVersion(crd.Spec.Group + "/" + version.Name)
+	u.SetKind(crd.Spec.Names.Kind)
+	u.SetName(smokeTestName)
+	u.SetNamespace("")
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped {
+		u.SetNamespace(smokeTestNamespace)
+	}
+	return u, nil
+}
+
+// sampleValue returns a minimal value valid against schema: its default or
+// first enum value when set, otherwise the smallest value of its type, with
+// only the required properties for objects
+func sampleValue(schema *apiextensionsv1.JSONSchemaProps) interface{} {
+	if schema.Default != nil {
+		if v, ok := unmarshalJSON(schema.Default.Raw); ok {
+			return v
+		}
+	}
+	if len(schema.Enum) > 0 {
+		if v, ok := unmarshalJSON(schema.Enum[0].Raw); ok {
+			return v
+		}
+	}
+
+	switch schema.Type {
+	case "object":
+		obj := map[string]interface{}{}
+		for _, name := range schema.Required {
+			if property, ok := schema.Properties[name]; ok {
+				obj[name] = sampleValue(&property)
+			}
+		}
+		return obj
+	case "array":
+		items := []interface{}{}
+		if schema.MinItems != nil && schema.Items != nil && schema.Items.Schema != nil {
+			for i := int64(0); i < *schema.MinItems; i++ {
+				items = append(items, sampleValue(schema.Items.Schema))
+			}
+		}
+		return items
+	case "string":
+		return sampleString(schema)
+	case "integer":
+		if schema.Minimum != nil {
+			return int64(*schema.Minimum)
+		}
+		return int64(0)
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return float64(0)
+	case "boolean":
+		return false
+	}
+
+	if schema.XIntOrString {
+		return int64(0)
+	}
+	return map[string]interface{}{}
+}
+
+func sampleString(schema *apiextensionsv1.JSONSchemaProps) string {
+	switch schema.Format {
+	case "date-time":
+		return "1970-01-01T00:00:00Z"
+	case "date":
+		return "1970-01-01"
+	case "duration":
+		return "1s"
+	}
+	value := sampleMinimalString
+	if schema.MinLength != nil && int64(len(value)) < *schema.MinLength {
+		value = strings.Repeat("a", int(*schema.MinLength))
+	}
+	if schema.MaxLength != nil && int64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+func unmarshalJSON(raw []byte) (interface{}, bool) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var errRejected = errors.New("sample rejected")
+
+// newSmokeTestClient returns a fake client with all the bundle CRDs
+// established, recording the sample objects created with dry-run. Samples of
+// the kind named reject are rejected; creations without dry-run fail.
+func newSmokeTestClient(samples *[]*unstructured.Unstructured, reject string) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(establishedBundleCRDs()...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				u, ok := obj.(*unstructured.Unstructured)
+				if !ok || u.GetKind() == "CustomResourceDefinition" {
+					return c.Create(ctx, obj, opts...)
+				}
+				createOpts := &client.CreateOptions{}
+				createOpts.ApplyOptions(opts)
+				if len(createOpts.DryRun) == 0 {
+					return errWrite
+				}
+				*samples = append(*samples, u)
+				if u.GetKind() == reject {
+					return errRejected
+				}
+				return nil
+			},
+		}).Build()
+}
+
+// bundleCRDsByKind returns all the bundle CRDs, keyed by kind
+func bundleCRDsByKind() map[string]*apiextensionsv1.CustomResourceDefinition {
+	result := map[string]*apiextensionsv1.CustomResourceDefinition{}
+	for _, u := range getBundleCRDs() {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), crd)).To(Succeed())
+		result[crd.Spec.Names.Kind] = crd
+	}
+	return result
+}
+
+// servedVersions returns the number of served versions across crds
+func servedVersions(crds map[string]*apiextensionsv1.CustomResourceDefinition) int {
+	count := 0
+	for _, crd := range crds {
+		for _, v := range crd.Spec.Versions {
+			if v.Served {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+var _ = Describe("SmokeTest", func() {
+	It("creates with dry-run a sample for every served CRD version", func() {
+		var samples []*unstructured.Unstructured
+		crds := bundleCRDsByKind()
+		c := newSmokeTestClient(&samples, "")
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{SmokeTest: true}, logger)
+		Expect(err).To(BeNil())
+		Expect(report.SmokeTest).To(HaveLen(servedVersions(crds)))
+		Expect(report.SmokeTest).To(HaveEach(HaveField("Error", BeEmpty())))
+		Expect(samples).To(HaveLen(servedVersions(crds)))
+
+		for _, u := range samples {
+			crd := crds[u.GetKind()]
+			Expect(crd).ToNot(BeNil())
+			Expect(u.GetName()).To(Equal("crd-manager-smoke-test"))
+			Expect(u.GetNamespace() != "").To(Equal(crd.Spec.Scope == apiextensionsv1.NamespaceScoped))
+			Expect(u.Object).ToNot(HaveKey("status"))
+			for _, v := range crd.Spec.Versions {
+				if u.GroupVersionKind().Version != v.Name || v.Schema == nil {
+					continue
+				}
+				if spec, ok := v.Schema.OpenAPIV3Schema.Properties["spec"]; ok {
+					for _, field := range spec.Required {
+						Expect(u.Object["spec"]).To(HaveKey(field), "%s %s", crd.Name, v.Name)
+					}
+				}
+			}
+		}
+	})
+
+	It("reports the CRD versions whose sample is rejected", func() {
+		var samples []*unstructured.Unstructured
+		rejected := getBundleCRD(getBundleCRDs()[0].GetName())
+		c := newSmokeTestClient(&samples, rejected.Spec.Names.Kind)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{SmokeTest: true}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(report.SmokeTest).To(ContainElement(And(
+			HaveField("CRD", rejected.Name),
+			HaveField("Error", ContainSubstring(errRejected.Error())))))
+		for _, result := range report.SmokeTest {
+			if result.CRD != rejected.Name {
+				Expect(result.Error).To(BeEmpty())
+			}
+		}
+	})
+
+	It("is disabled by default", func() {
+		var samples []*unstructured.Unstructured
+		c := newSmokeTestClient(&samples, "")
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(report.SmokeTest).To(BeEmpty())
+		Expect(samples).To(BeEmpty())
+	})
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/projectsveltos/crd-manager/pkg/bundle"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// multipleVersionsCRD returns crdWithWebhookMultipleVersions, as live in a
+// cluster having stored the given versions
+func multipleVersionsCRD(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+		parse(crdWithWebhookMultipleVersions).UnstructuredContent(), crd)).To(Succeed())
+	crd.Status.StoredVersions = storedVersions
+	return crd
+}
+
+var _ = Describe("StorageVersions", func() {
+	const crdName = "widgets.lib.projectsveltos.io"
+
+	It("makes the given version the only storage version and records it in the audit annotation", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}}}
+
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1alpha1")["storage"]).To(BeTrue())
+		Expect(getVersion(u, "v1beta1")["storage"]).To(BeFalse())
+		Expect(getAudit(u)).To(ContainElement("storage version set to v1alpha1"))
+	})
+
+	It("leaves the bundle storage version unrecorded", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1beta1"}}}
+
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1beta1")["storage"]).To(BeTrue())
+		Expect(u.GetAnnotations()).ToNot(HaveKey(auditAnnotation))
+	})
+
+	It("refuses unknown and not served versions", func() {
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1"}}}
+		Expect(deploy.ApplyMutations(parse(crdWithWebhookMultipleVersions), opts, logger)).ToNot(Succeed())
+
+		opts = &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}},
+			StorageVersions:  []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}},
+		}
+		Expect(deploy.ApplyMutations(parse(crdWithWebhookMultipleVersions), opts, logger)).ToNot(Succeed())
+	})
+
+	It("refuses more than one storage version per CRD", func() {
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{
+			{CRD: crdName, Version: "v1alpha1"}, {CRD: crdName, Version: "v1beta1"},
+		}}
+		Expect(opts.Validate()).ToNot(Succeed())
+	})
+
+	It("shows in the template output", func() {
+		var buf bytes.Buffer
+		opts := &deploy.Options{
+			Bundle:          &bundle.Bundle{Content: []byte(crdWithWebhookMultipleVersions)},
+			StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}},
+		}
+		Expect(deploy.Template(&buf, opts, logger)).To(Succeed())
+
+		rendered := parse(buf.String())
+		Expect(getVersion(rendered, "v1alpha1")["storage"]).To(BeTrue())
+		Expect(getVersion(rendered, "v1beta1")["storage"]).To(BeFalse())
+		Expect(getAudit(rendered)).To(ContainElement("storage version set to v1alpha1"))
+	})
+
+	It("refuses a version the live CRD never stored unless allowed", func() {
+		// The fake cluster runs no conversion webhook
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}},
+			SkipConversionCheck: true}
+		c := newFakeClient(multipleVersionsCRD("v1beta1"))
+
+		desired := parse(crdWithWebhookMultipleVersions)
+		Expect(deploy.ApplyMutations(desired, opts, logger)).To(Succeed())
+		err := deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhookMultipleVersions),
+			desired, opts, &deploy.CRDResult{}, logger)
+		Expect(err).To(MatchError(ContainSubstring("never stored")))
+
+		opts.AllowUnstoredStorageVersion = true
+		result := &deploy.CRDResult{}
+		Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhookMultipleVersions),
+			desired, opts, result, logger)).To(Succeed())
+		Expect(result.Action).To(Equal(deploy.ActionUpdated))
+		Expect(storageVersions(getCRD(c, crdName))).To(Equal([]string{"v1alpha1"}))
+	})
+
+	It("accepts a version the live CRD already stored", func() {
+		opts := &deploy.Options{StorageVersions: []deploy.CRDVersion{{CRD: crdName, Version: "v1alpha1"}},
+			SkipConversionCheck: true}
+		c := newFakeClient(multipleVersionsCRD("v1alpha1", "v1beta1"))
+
+		desired := parse(crdWithWebhookMultipleVersions)
+		Expect(deploy.ApplyMutations(desired, opts, logger)).To(Succeed())
+		result := &deploy.CRDResult{}
+		Expect(deploy.ProcessCustomResourceDefinition(context.TODO(), c, parse(crdWithWebhookMultipleVersions),
+			desired, opts, result, logger)).To(Succeed())
+		Expect(storageVersions(getCRD(c, crdName))).To(Equal([]string{"v1alpha1"}))
+	})
+})
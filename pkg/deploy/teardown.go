@@ -0,0 +1,78 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// DeleteCreatedCRDs deletes the CRDs named names, which the caller created.
+// CRDs no longer carrying the crd-manager ownership label, or managed by
+// another tool, are left in place; missing ones are ignored. Deleting a CRD
+// deletes all its instances.
+func DeleteCreatedCRDs(ctx context.Context, c client.Client, names []string, opts *Options,
+	logger logr.Logger) error {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var errs []error
+	for _, name := range names {
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		if err := getCRD(ctx, c, name, live); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get CRD %s: %w", name, err))
+			}
+			continue
+		}
+
+		if reason := deletionBlocker(live, opts); reason != "" {
+			logWarning(logger, "CRD %s %s, not deleted", name, reason)
+			continue
+		}
+
+		err := c.Delete(ctx, live, client.Preconditions{UID: &live.UID})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete CRD %s: %w", name, err))
+			continue
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("deleted CRD %s", name))
+	}
+	return errors.Join(errs...)
+}
+
+// deletionBlocker returns why live, which crd-manager created, must not be
+// deleted, or an empty string
+func deletionBlocker(live *apiextensionsv1.CustomResourceDefinition, opts *Options) string {
+	if !isManagedByCRDManager(live) {
+		return fmt.Sprintf("no longer carries the %s label", ManagedByLabel)
+	}
+	if manager := externalManagerOf(withoutOwnHelmMarkers(live, &opts.HelmRelease)); manager != nil {
+		return "is now managed by " + manager.description
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("DeleteCreatedCRDs", func() {
+	const (
+		widgets = "widgets.lib.projectsveltos.io"
+		gadgets = "gadgets.lib.projectsveltos.io"
+	)
+
+	exists := func(c client.Client, name string) bool {
+		err := c.Get(context.TODO(), client.ObjectKey{Name: name}, &apiextensionsv1.CustomResourceDefinition{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).To(BeNil())
+		return true
+	}
+
+	It("deletes the CRDs carrying the ownership label and ignores missing ones", func() {
+		crd := establishedCRD(crdWithoutWebhook)
+		crd.Labels = map[string]string{deploy.ManagedByLabel: deploy.ManagedByValue}
+		c := newFakeClient(crd)
+
+		Expect(deploy.DeleteCreatedCRDs(context.TODO(), c, []string{gadgets, widgets}, nil, logger)).To(Succeed())
+		Expect(exists(c, gadgets)).To(BeFalse())
+	})
+
+	It("keeps the CRDs without the ownership label or managed by another tool", func() {
+		unlabelled := establishedCRD(crdWithoutWebhook)
+		helm := establishedCRD(crdWithWebhookMultipleVersions)
+		helm.Labels = map[string]string{
+			deploy.ManagedByLabel:          deploy.ManagedByValue,
+			"app.kubernetes.io/managed-by": "Helm",
+		}
+		c := newFakeClient(unlabelled, helm)
+
+		Expect(deploy.DeleteCreatedCRDs(context.TODO(), c, []string{gadgets, widgets}, &deploy.Options{},
+			logger)).To(Succeed())
+		Expect(exists(c, gadgets)).To(BeTrue())
+		Expect(exists(c, widgets)).To(BeTrue())
+	})
+})
@@ -0,0 +1,89 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	yamlSeparator = "---\n"
+)
+
+// Template writes to w, as a multi-document YAML, all the Sveltos CRDs
+// contained in the bundle with every configured mutation applied, exactly as
+// Deploy would apply them and in the same order. No cluster is contacted, so
+// mutations depending on live CRDs (like preserving an injected caBundle)
+// are not reflected.
+func Template(w io.Writer, opts *Options, logger logr.Logger) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	crds, err := renderCRDs(opts, logger)
+	if err != nil {
+		return err
+	}
+	for _, crd := range crds {
+		if err := writeDocument(w, crd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCRDs returns the selected bundle CRDs with every configured mutation
+// applied, without contacting any cluster
+func renderCRDs(opts *Options, logger logr.Logger) ([]*unstructured.Unstructured, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	crds, err := prepareBundleCRDs(context.Background(), opts.getBundle().Content, opts, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	crds = selectComponents(crds, opts)
+	result := make([]*unstructured.Unstructured, len(crds))
+	for i, b := range crds {
+		if b.err != nil {
+			return nil, fmt.Errorf("failed to prepare CRD %s: %w", b.desired.GetName(), b.err)
+		}
+		result[i] = b.desired
+	}
+	return result, nil
+}
+
+// writeDocument writes obj to w as a YAML document
+func writeDocument(w io.Writer, obj *unstructured.Unstructured) error {
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if _, err := io.WriteString(w, yamlSeparator); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("Template", func() {
+	It("renders mutated CRDs in bundle order", func() {
+		opts := &deploy.Options{Category: "sveltos"}
+
+		var out bytes.Buffer
+		Expect(deploy.Template(&out, opts, logger)).To(Succeed())
+
+		docs, err := deployer.CustomSplit(out.String())
+		Expect(err).To(BeNil())
+
+		bundleCRDs := getBundleCRDs()
+		Expect(docs).To(HaveLen(len(bundleCRDs)))
+		for i := range docs {
+			u, err := k8s_utils.GetUnstructured([]byte(docs[i]))
+			Expect(err).To(BeNil())
+			Expect(u.GetName()).To(Equal(bundleCRDs[i].GetName()))
+
+			categories, _, err := unstructured.NestedStringSlice(u.Object, "spec", "names", "categories")
+			Expect(err).To(BeNil())
+			Expect(categories).To(ContainElement("sveltos"))
+		}
+	})
+
+	It("is deterministic", func() {
+		opts := &deploy.Options{Category: "sveltos"}
+
+		var first, second bytes.Buffer
+		Expect(deploy.Template(&first, opts, logger)).To(Succeed())
+		Expect(deploy.Template(&second, opts, logger)).To(Succeed())
+		Expect(first.String()).To(Equal(second.String()))
+	})
+
+	It("fails when a mutation cannot be applied", func() {
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: "clusterprofiles.config.projectsveltos.io", Version: "v9"}},
+		}
+
+		var out bytes.Buffer
+		Expect(deploy.Template(&out, opts, logger)).ToNot(Succeed())
+	})
+})
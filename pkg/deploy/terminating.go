@@ -0,0 +1,141 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// DefaultTerminatingTimeout is how long, by default, TerminatingRecreate
+	// waits for the deletion of a terminating CRD to complete
+	DefaultTerminatingTimeout = 2 * time.Minute
+
+	// terminatingPollInterval is the interval between two checks of a
+	// terminating CRD
+	terminatingPollInterval = time.Second
+)
+
+// TerminatingPolicy tells how bundle CRDs found terminating (deleted, their
+// deletion blocked by the finalizers of their instances) are handled
+type TerminatingPolicy string
+
+const (
+	// TerminatingReport leaves terminating CRDs untouched and reports them.
+	// They are created again by the first run after their deletion completed.
+	TerminatingReport = TerminatingPolicy("report")
+
+	// TerminatingRecreate waits, up to TerminatingTimeout, for the deletion of
+	// terminating CRDs to complete, then creates them again
+	TerminatingRecreate = TerminatingPolicy("recreate")
+)
+
+func validateTerminatingPolicy(policy TerminatingPolicy) error {
+	switch policy {
+	case "", TerminatingReport, TerminatingRecreate:
+		return nil
+	default:
+		return fmt.Errorf("invalid terminating CRD policy %q: expected %s or %s",
+			policy, TerminatingReport, TerminatingRecreate)
+	}
+}
+
+// processTerminatingCRD handles u, whose live CRD is terminating, according
+// to the TerminatingPolicy
+func processTerminatingCRD(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	u *unstructured.Unstructured, validation string, opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	if opts.Terminating != TerminatingRecreate {
+		logWarning(logger, "Sveltos CRD %s is terminating since %s, presumably waiting for the finalizers of its "+
+			"instances: leaving it untouched", u.GetName(), live.DeletionTimestamp.UTC().Format(time.RFC3339))
+		result.Action = ActionTerminating
+		return nil
+	}
+
+	timeout := opts.TerminatingTimeout
+	if timeout <= 0 {
+		timeout = DefaultTerminatingTimeout
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s is terminating, waiting up to %s for its deletion "+
+		"to complete", u.GetName(), timeout))
+	if err := waitForDeletion(ctx, c, live, timeout); err != nil {
+		return err
+	}
+
+	opts.progress(logger, &ProgressEvent{Phase: PhaseCreating, CRD: u.GetName(), Reason: ReasonRecreate})
+	result.Action = ActionRecreated
+	result.Drift = DriftStatusInSync
+	setManagedBy(u)
+	setApplySetMember(u, opts)
+	return createCRD(ctx, c, u, validation, opts, logger)
+}
+
+// observeTerminatingCRD reports u, whose live CRD is terminating, as a run
+// would handle it: left untouched or, with TerminatingRecreate, created again
+// once deleted
+func observeTerminatingCRD(live *apiextensionsv1.CustomResourceDefinition, u *unstructured.Unstructured,
+	opts *Options, result *CRDResult, logger logr.Logger) error {
+
+	if opts.Terminating != TerminatingRecreate {
+		logWarning(logger, "Sveltos CRD %s is terminating since %s, presumably waiting for the finalizers of its "+
+			"instances", u.GetName(), live.DeletionTimestamp.UTC().Format(time.RFC3339))
+		result.Action = ActionTerminating
+		return nil
+	}
+
+	logWarning(logger, "Sveltos CRD %s is terminating, it would be created again once deleted", u.GetName())
+	result.Action = ActionObserved
+	result.Drift = DriftStatusMissing
+	return auditObserved(opts, &AuditEntry{CRD: u.GetName(), Action: AuditActionCreate}, logger)
+}
+
+// waitForDeletion waits, up to timeout, for live to be gone
+func waitForDeletion(ctx context.Context, c client.Client, live *apiextensionsv1.CustomResourceDefinition,
+	timeout time.Duration) error {
+
+	err := wait.PollUntilContextTimeout(ctx, terminatingPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		current := &apiextensionsv1.CustomResourceDefinition{}
+		err := getCRD(ctx, c, live.Name, current)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			// Transient API errors are retried until the timeout
+			return false, nil
+		}
+		if current.UID != live.UID {
+			return false, fmt.Errorf("CRD %s was created again by someone else while terminating", live.Name)
+		}
+		return false, nil
+	})
+	if err != nil && wait.Interrupted(err) {
+		return fmt.Errorf("CRD %s is still terminating after %s, check the finalizers of its instances",
+			live.Name, timeout)
+	}
+	return err
+}
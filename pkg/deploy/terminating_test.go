@@ -0,0 +1,124 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// terminatingCRD returns the SveltosCluster bundle CRD, deleted but kept by
+// a finalizer
+func terminatingCRD() *apiextensionsv1.CustomResourceDefinition {
+	crd := getBundleCRD(sveltosClusterCRD)
+	crd.UID = "terminating"
+	crd.Finalizers = []string{"customresourcecleanup.apiextensions.k8s.io"}
+	now := metav1.Now()
+	crd.DeletionTimestamp = &now
+	return crd
+}
+
+// crdResult returns the result of the CRD named name
+func crdResult(report *deploy.Report, name string) *deploy.CRDResult {
+	for i := range report.CRDs {
+		if report.CRDs[i].Name == name {
+			return &report.CRDs[i]
+		}
+	}
+	Fail("no result for CRD " + name)
+	return nil
+}
+
+// newDeletionCompletingClient returns a fake client with crd, whose deletion
+// completes once it has been read getsBeforeDeletion times
+func newDeletionCompletingClient(crd *apiextensionsv1.CustomResourceDefinition, getsBeforeDeletion int) client.Client {
+	gets := 0
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+
+			if key.Name == crd.Name {
+				gets++
+				if gets == getsBeforeDeletion+1 {
+					live := &apiextensionsv1.CustomResourceDefinition{}
+					Expect(c.Get(ctx, key, live)).To(Succeed())
+					live.Finalizers = nil
+					Expect(c.Update(ctx, live)).To(Succeed())
+				}
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("Terminating CRDs", func() {
+	It("are reported and left untouched by default", func() {
+		c := newFakeClient(terminatingCRD())
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(crdResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionTerminating))
+		Expect(report.Count(deploy.ActionTerminating)).To(Equal(1))
+
+		live := getCRD(c, sveltosClusterCRD)
+		Expect(live.DeletionTimestamp).ToNot(BeNil())
+		Expect(live.Labels).ToNot(HaveKey(deploy.ManagedByLabel))
+	})
+
+	It("are recreated once their deletion completes", func() {
+		c := newDeletionCompletingClient(terminatingCRD(), 2)
+
+		opts := &deploy.Options{Terminating: deploy.TerminatingRecreate, TerminatingTimeout: 10 * time.Second}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		Expect(crdResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionRecreated))
+
+		live := getCRD(c, sveltosClusterCRD)
+		Expect(live.DeletionTimestamp).To(BeNil())
+		Expect(live.UID).ToNot(Equal(types.UID("terminating")))
+		Expect(live.Labels).To(HaveKeyWithValue(deploy.ManagedByLabel, deploy.ManagedByValue))
+	})
+
+	It("fail when their deletion does not complete in time", func() {
+		c := newFakeClient(terminatingCRD())
+
+		opts := &deploy.Options{Terminating: deploy.TerminatingRecreate, TerminatingTimeout: 100 * time.Millisecond}
+		report, err := deploy.Deploy(context.TODO(), c, opts, logger)
+		Expect(err).ToNot(BeNil())
+		result := crdResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.Error).To(ContainSubstring("still terminating"))
+		Expect(getCRD(c, sveltosClusterCRD).DeletionTimestamp).ToNot(BeNil())
+	})
+
+	It("rejects invalid policies", func() {
+		Expect((&deploy.Options{Terminating: "wait"}).Validate()).ToNot(Succeed())
+		Expect((&deploy.Options{Terminating: deploy.TerminatingReport}).Validate()).To(Succeed())
+	})
+})
@@ -0,0 +1,76 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attributes
+const (
+	AttributeCRDName       = "crd.name"
+	AttributeCRDAction     = "crd.action"
+	AttributeCRDDrift      = "crd.drift"
+	AttributeCRDResult     = "crd.result"
+	AttributeOperation     = "crd.operation"
+	AttributeBundleDigest  = "bundle.digest"
+	AttributeBundleVersion = "bundle.version"
+	AttributeRunStatus     = "run.status"
+)
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// tracer creates the spans of a run. Unless the tracing package installed a
+// tracer provider it is the global no-op one, and spans cost nothing.
+var tracer = otel.Tracer("github.com/projectsveltos/crd-manager/pkg/deploy")
+
+// traceStep runs step in a child span of ctx named name
+func traceStep(ctx context.Context, name string, step func(context.Context) error,
+	attributes ...attribute.KeyValue) error {
+
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+	defer span.End()
+
+	err := step(ctx)
+	setSpanError(span, err)
+	return err
+}
+
+// setSpanError marks span as failed when err is set
+func setSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// spanResult returns the crd.result attribute matching err
+func spanResult(err error) attribute.KeyValue {
+	if err != nil {
+		return attribute.String(AttributeCRDResult, resultFailure)
+	}
+	return attribute.String(AttributeCRDResult, resultSuccess)
+}
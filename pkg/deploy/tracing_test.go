@@ -0,0 +1,142 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// findSpans returns the recorded spans named name
+func findSpans(name string) []tracetest.SpanStub {
+	var result []tracetest.SpanStub
+	for _, span := range spans.GetSpans() {
+		if span.Name == name {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+// crdSpan returns the recorded DeployCRD span of the CRD named name
+func crdSpan(name string) *tracetest.SpanStub {
+	for _, span := range findSpans("DeployCRD") {
+		if hasAttribute(span, attribute.String(deploy.AttributeCRDName, name)) {
+			return &span
+		}
+	}
+	return nil
+}
+
+func hasAttribute(span tracetest.SpanStub, kv attribute.KeyValue) bool {
+	for _, a := range span.Attributes {
+		if a == kv {
+			return true
+		}
+	}
+	return false
+}
+
+// childrenOf returns the names of the recorded spans whose parent is parent
+func childrenOf(parent tracetest.SpanStub) []string {
+	var result []string
+	for _, span := range spans.GetSpans() {
+		if span.Parent.SpanID() == parent.SpanContext.SpanID() {
+			result = append(result, span.Name)
+		}
+	}
+	return result
+}
+
+var _ = Describe("Tracing", func() {
+	BeforeEach(func() {
+		spans.Reset()
+	})
+
+	It("creates a root span per run and a child span per CRD", func() {
+		c := newFakeClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+
+		runs := findSpans("Deploy")
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].Parent.IsValid()).To(BeFalse())
+		Expect(hasAttribute(runs[0], attribute.String(deploy.AttributeRunStatus, string(report.Status)))).To(BeTrue())
+		Expect(hasAttribute(runs[0], attribute.String(deploy.AttributeBundleDigest, report.BundleDigest))).To(BeTrue())
+		Expect(childrenOf(runs[0])).To(ContainElement("ParseBundle"))
+
+		parse := findSpans("ParseBundle")
+		Expect(parse).To(HaveLen(1))
+		Expect(childrenOf(parse[0])).To(HaveLen(len(report.CRDs)))
+		Expect(childrenOf(parse[0])).To(HaveEach("Mutate"))
+
+		Expect(findSpans("DeployCRD")).To(HaveLen(len(report.CRDs)))
+		for _, result := range report.CRDs {
+			span := crdSpan(result.Name)
+			Expect(span).ToNot(BeNil())
+			Expect(span.Parent.SpanID()).To(Equal(runs[0].SpanContext.SpanID()))
+			Expect(hasAttribute(*span, attribute.String(deploy.AttributeCRDAction, string(result.Action)))).To(BeTrue())
+			Expect(hasAttribute(*span, attribute.String(deploy.AttributeCRDResult, "success"))).To(BeTrue())
+			Expect(childrenOf(*span)).To(Equal([]string{"Get", "Write"}))
+		}
+		for _, span := range findSpans("Get") {
+			// Not finding a CRD about to be created is no failure
+			Expect(span.Status.Code).ToNot(Equal(codes.Error))
+		}
+	})
+
+	It("marks the spans of failed CRDs and runs as errors", func() {
+		c := newRejectingClient(sveltosClusterCRD)
+
+		_, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).ToNot(BeNil())
+
+		span := crdSpan(sveltosClusterCRD)
+		Expect(span).ToNot(BeNil())
+		Expect(span.Status.Code).To(Equal(codes.Error))
+		Expect(hasAttribute(*span, attribute.String(deploy.AttributeCRDResult, "failure"))).To(BeTrue())
+		Expect(findSpans("Deploy")[0].Status.Code).To(Equal(codes.Error))
+	})
+
+	It("nests the run under the trace carried by the context", func() {
+		parent := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{2},
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		})
+		ctx := trace.ContextWithRemoteSpanContext(context.TODO(), parent)
+
+		_, err := deploy.Deploy(ctx, newFakeClient(), nil, logger)
+		Expect(err).To(BeNil())
+
+		runs := findSpans("Deploy")
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].Parent.SpanID()).To(Equal(parent.SpanID()))
+		Expect(runs[0].SpanContext.TraceID()).To(Equal(parent.TraceID()))
+	})
+})
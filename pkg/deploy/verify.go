@@ -0,0 +1,177 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+// InstallStatus lists the bundle CRDs VerifyInstall found not correctly
+// installed, per problem
+type InstallStatus struct {
+	// Missing are the CRDs not found in the cluster
+	Missing []string `json:"missing"`
+
+	// NotEstablished are the CRDs not reporting Established=True, whose names
+	// are not accepted or which are being deleted
+	NotEstablished []string `json:"notEstablished"`
+
+	// Drifted are the CRDs whose spec hash differs from the bundle one
+	Drifted []string `json:"drifted"`
+}
+
+// IsInstalled returns true if every bundle CRD is correctly installed
+func (s *InstallStatus) IsInstalled() bool {
+	return len(s.Missing) == 0 && len(s.NotEstablished) == 0 && len(s.Drifted) == 0
+}
+
+// VerifyInstall checks, once and without any write, that every bundle CRD
+// selected by opts exists, is established, has no problematic condition and
+// has the spec hash of the bundle. CRDs pinned at another bundle version are
+// not checked for drift; CRDs managed by another tool are compared with the
+// bundle as is. Only get on customresourcedefinitions is needed.
+func VerifyInstall(ctx context.Context, c client.Client, opts *Options, logger logr.Logger) (*InstallStatus, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	crds, err := prepareBundleCRDs(ctx, opts.getBundle().Content, opts, logger)
+	if crds == nil {
+		return nil, err
+	}
+
+	selected := selectComponents(crds, opts)
+	status := &InstallStatus{}
+	for _, crd := range selected {
+		if crd.err != nil {
+			return nil, crd.err
+		}
+		name := crd.desired.GetName()
+		live := &apiextensionsv1.CustomResourceDefinition{}
+		if err := getCRD(ctx, c, name, live); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			status.Missing = append(status.Missing, name)
+			continue
+		}
+
+		if problem := conditionProblem(live); problem != "" {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("Sveltos CRD %s %s", name, problem))
+			status.NotEstablished = append(status.NotEstablished, name)
+			continue
+		}
+
+		drifted, _, err := liveDrifts(live, crd, opts, logger)
+		if err != nil {
+			return nil, err
+		}
+		if drifted {
+			status.Drifted = append(status.Drifted, name)
+		}
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("verified %d CRDs: %d missing, %d not established, %d drifted",
+		len(selected), len(status.Missing), len(status.NotEstablished), len(status.Drifted)))
+	return status, nil
+}
+
+// CheckInstalled returns, sorted by name, the bundle CRDs selected by opts
+// missing from the cluster and the ones outdated: not carrying the
+// BundleHashAnnotation of the bundle, because another bundle was applied,
+// they are pinned at another bundle version or another tool wrote them.
+// Unlike VerifyInstall, only the CRDs metadata is read.
+func CheckInstalled(ctx context.Context, c client.Reader, opts *Options,
+	logger logr.Logger) (missing, outdated []string, err error) {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	bundleCRDs, err := prepareBundleCRDs(ctx, opts.getBundle().Content, opts, logger)
+	if bundleCRDs == nil {
+		return nil, nil, err
+	}
+
+	expected := map[string]string{}
+	for _, crd := range selectComponents(bundleCRDs, opts) {
+		if crd.err != nil {
+			return nil, nil, crd.err
+		}
+		expected[crd.desired.GetName()] = crd.desired.GetAnnotations()[BundleHashAnnotation]
+	}
+
+	missing, outdated, err = crds.CheckInstalledHashes(ctx, c, expected)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("checked %d CRDs: %d missing, %d outdated",
+		len(expected), len(missing), len(outdated)))
+	return missing, outdated, nil
+}
+
+// conditionProblem returns why live cannot be used, or an empty string
+func conditionProblem(live *apiextensionsv1.CustomResourceDefinition) string {
+	if live.DeletionTimestamp != nil {
+		return "is being deleted"
+	}
+	for i := range live.Status.Conditions {
+		condition := &live.Status.Conditions[i]
+		if condition.Type == apiextensionsv1.NamesAccepted && condition.Status == apiextensionsv1.ConditionFalse {
+			return "names are not accepted: " + condition.Message
+		}
+	}
+	if !isEstablished(live) {
+		return "is not established"
+	}
+	return ""
+}
+
+// liveDrifts returns true if the spec of live differs from the one crd would
+// get applied, along with the tool managing live instead of crd-manager, if
+// any: live is then compared with the bundle CRD as is. CRDs pinned at
+// another bundle version never drift.
+func liveDrifts(live *apiextensionsv1.CustomResourceDefinition, crd *bundleCRD, opts *Options,
+	logger logr.Logger) (bool, *externalManager, error) {
+
+	if isPinnedAway(live, opts) {
+		return false, nil, nil
+	}
+
+	desired := crd.desired.DeepCopy()
+	manager := resolveOwnership(live, opts, logger)
+	if manager != nil {
+		desired = crd.original
+	} else if err := mergeLive(live, desired, opts, logger); err != nil {
+		return false, nil, err
+	}
+	inSync, err := isInSync(live, desired)
+	if err != nil {
+		return false, nil, err
+	}
+	return !inSync, manager, nil
+}
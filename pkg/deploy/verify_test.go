@@ -0,0 +1,99 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+var _ = Describe("VerifyInstall", func() {
+	const (
+		widgets = "widgets.lib.projectsveltos.io"
+		gadgets = "gadgets.lib.projectsveltos.io"
+	)
+
+	verify := func(opts *deploy.Options, objs ...client.Object) *deploy.InstallStatus {
+		opts.Bundle = testBundle()
+		c := newFakeClient(objs...)
+		status, err := deploy.VerifyInstall(context.TODO(), c, opts, logger)
+		Expect(err).To(BeNil())
+		return status
+	}
+
+	It("reports installed CRDs", func() {
+		status := verify(&deploy.Options{}, establishedCRD(crdWithWebhookMultipleVersions),
+			establishedCRD(crdWithoutWebhook))
+		Expect(status.IsInstalled()).To(BeTrue())
+	})
+
+	It("reports missing CRDs", func() {
+		status := verify(&deploy.Options{}, establishedCRD(crdWithoutWebhook))
+		Expect(status.IsInstalled()).To(BeFalse())
+		Expect(status.Missing).To(ConsistOf(widgets))
+		Expect(status.NotEstablished).To(BeEmpty())
+		Expect(status.Drifted).To(BeEmpty())
+	})
+
+	It("reports CRDs not established, with names not accepted or being deleted", func() {
+		notEstablished := establishedCRD(crdWithWebhookMultipleVersions)
+		notEstablished.Status.Conditions = nil
+		deleted := establishedCRD(crdWithoutWebhook)
+		deleted.Finalizers = []string{"customresourcecleanup.apiextensions.k8s.io"}
+		deleted.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+
+		status := verify(&deploy.Options{}, notEstablished, deleted)
+		Expect(status.NotEstablished).To(ConsistOf(widgets, gadgets))
+
+		rejected := establishedCRD(crdWithoutWebhook)
+		rejected.Status.Conditions[0].Status = apiextensionsv1.ConditionFalse
+		status = verify(&deploy.Options{}, establishedCRD(crdWithWebhookMultipleVersions), rejected)
+		Expect(status.NotEstablished).To(ConsistOf(gadgets))
+	})
+
+	It("reports CRDs whose spec hash differs from the bundle", func() {
+		drifted := establishedCRD(crdWithoutWebhook)
+		drifted.Spec.Names.ShortNames = []string{"gd"}
+
+		status := verify(&deploy.Options{}, establishedCRD(crdWithWebhookMultipleVersions), drifted)
+		Expect(status.Drifted).To(ConsistOf(gadgets))
+		Expect(status.Missing).To(BeEmpty())
+	})
+
+	It("does not report CRDs pinned at another bundle version as drifted", func() {
+		pinned := establishedCRD(crdWithoutWebhook)
+		pinned.Spec.Names.ShortNames = []string{"gd"}
+		pinned.Annotations = map[string]string{deploy.PinBundleVersionAnnotation: "v0.9.0"}
+
+		status := verify(&deploy.Options{}, establishedCRD(crdWithWebhookMultipleVersions), pinned)
+		Expect(status.IsInstalled()).To(BeTrue())
+	})
+
+	It("only verifies the selected components", func() {
+		status := verify(&deploy.Options{Components: []string{crds.ComponentAddons}})
+		Expect(status.IsInstalled()).To(BeTrue())
+	})
+})
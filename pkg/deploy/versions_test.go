@@ -0,0 +1,123 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	auditAnnotation = "projectsveltos.io/crd-manager-audit"
+)
+
+// getVersion returns the spec.versions entry with the given name
+func getVersion(u *unstructured.Unstructured, name string) map[string]interface{} {
+	versions, _, err := unstructured.NestedSlice(u.Object, "spec", "versions")
+	Expect(err).To(BeNil())
+	for i := range versions {
+		version := versions[i].(map[string]interface{})
+		if version["name"] == name {
+			return version
+		}
+	}
+	Fail("version " + name + " not found")
+	return nil
+}
+
+func getAudit(u *unstructured.Unstructured) []string {
+	value, ok := u.GetAnnotations()[auditAnnotation]
+	Expect(ok).To(BeTrue())
+	a := struct {
+		Modifications []string `json:"modifications"`
+	}{}
+	Expect(json.Unmarshal([]byte(value), &a)).To(Succeed())
+	return a.Modifications
+}
+
+var _ = Describe("Versions", func() {
+	It("ParseCRDVersions parses <crdName>:<version> values", func() {
+		result, err := deploy.ParseCRDVersions([]string{"widgets.lib.projectsveltos.io:v1alpha1"})
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal([]deploy.CRDVersion{{CRD: "widgets.lib.projectsveltos.io", Version: "v1alpha1"}}))
+
+		for _, invalid := range []string{"widgets.lib.projectsveltos.io", ":v1alpha1", "widgets.lib.projectsveltos.io:"} {
+			_, err = deploy.ParseCRDVersions([]string{invalid})
+			Expect(err).ToNot(BeNil())
+		}
+	})
+
+	It("disableVersions stops serving a version and records it in the audit annotation", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: u.GetName(), Version: "v1alpha1"}},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1alpha1")["served"]).To(BeFalse())
+		Expect(getVersion(u, "v1beta1")["served"]).To(BeTrue())
+		Expect(getAudit(u)).To(ContainElement("version v1alpha1 not served"))
+	})
+
+	It("disableVersions ignores other CRDs", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: "other.lib.projectsveltos.io", Version: "v1alpha1"}},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).To(Succeed())
+		Expect(getVersion(u, "v1alpha1")["served"]).To(BeTrue())
+		Expect(u.GetAnnotations()).ToNot(HaveKey(auditAnnotation))
+	})
+
+	It("disableVersions refuses to disable the storage version", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: u.GetName(), Version: "v1beta1"}},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).ToNot(Succeed())
+	})
+
+	It("disableVersions refuses unknown versions", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: u.GetName(), Version: "v1"}},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).ToNot(Succeed())
+	})
+
+	It("disableVersions refuses to leave no served version", func() {
+		u := parse(crdWithWebhookMultipleVersions)
+		versions, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+		for i := range versions {
+			version := versions[i].(map[string]interface{})
+			if version["name"] == "v1beta1" {
+				version["served"] = false
+			}
+		}
+		Expect(unstructured.SetNestedSlice(u.Object, versions, "spec", "versions")).To(Succeed())
+
+		opts := &deploy.Options{
+			DisabledVersions: []deploy.CRDVersion{{CRD: u.GetName(), Version: "v1alpha1"}},
+		}
+		Expect(deploy.ApplyMutations(u, opts, logger)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+
+	"github.com/projectsveltos/crd-manager/pkg/crds"
+)
+
+const (
+	// DefaultWaitTimeout is how long WaitForCRDs waits by default
+	DefaultWaitTimeout = 5 * time.Minute
+
+	// waitInterval is the delay before the second check of the CRDs
+	waitInterval = 2 * time.Second
+)
+
+// WaitError is returned by WaitForCRDs when some CRDs are still missing,
+// not established or with names not accepted once the timeout expires
+type WaitError = crds.WaitError
+
+// WaitForCRDs blocks until every CRD of the bundle exists, in the cluster c
+// points to, reports Established=True and does not report
+// NamesAccepted=False. No write is ever made: only get permission on
+// customresourcedefinitions is needed. A *WaitError lists the CRDs still not
+// ready when timeout expires.
+func WaitForCRDs(ctx context.Context, c client.Client, opts *Options, timeout time.Duration,
+	logger logr.Logger) error {
+
+	return waitForCRDs(ctx, c, opts, timeout, waitInterval, logger)
+}
+
+func waitForCRDs(ctx context.Context, c client.Client, opts *Options, timeout, interval time.Duration,
+	logger logr.Logger) error {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	names, err := bundleCRDNames(opts.getBundle().Content)
+	if err != nil {
+		return err
+	}
+	names = slices.DeleteFunc(names, func(name string) bool { return !opts.selectsCRD(name) })
+	return traceStep(ctx, "Wait", func(ctx context.Context) error {
+		return waitUntilEstablished(ctx, c, names, opts, timeout, interval, logger)
+	})
+}
+
+// waitForApplied waits, up to WaitTimeout, for the CRDs the run created,
+// updated, recreated, adopted or found unchanged to be established. The ones
+// it did not write, such as terminating, paused, pinned or skipped CRDs, are
+// not waited for. A *WaitError lists the CRDs still not ready when
+// WaitTimeout expires.
+func waitForApplied(ctx context.Context, c client.Client, opts *Options, report *Report,
+	logger logr.Logger) error {
+
+	var names []string
+	for i := range report.CRDs {
+		switch report.CRDs[i].Action {
+		case ActionCreated, ActionUpdated, ActionRecreated, ActionAdopted, ActionUnchanged:
+			names = append(names, report.CRDs[i].Name)
+		}
+	}
+	if len(names) == 0 {
+		// crds.WaitForCRDs would wait for every bundle CRD
+		return nil
+	}
+	err := traceStep(ctx, "Wait", func(ctx context.Context) error {
+		return waitUntilEstablished(ctx, c, names, opts, opts.WaitTimeout, waitInterval, logger)
+	})
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("CRDs not ready %s after being applied: %v", opts.WaitTimeout, err))
+	}
+	return err
+}
+
+// waitUntilEstablished implements waitForCRDs, for the CRDs named names, on
+// top of crds.WaitForCRDs
+func waitUntilEstablished(ctx context.Context, c client.Client, names []string, opts *Options,
+	timeout, interval time.Duration, logger logr.Logger) error {
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("waiting up to %s for %d CRDs to be established", timeout, len(names)))
+	for _, name := range names {
+		opts.progress(logger, &ProgressEvent{Phase: PhaseWaiting, CRD: name})
+	}
+
+	established := make(map[string]bool, len(names))
+	err := crds.WaitForCRDsWithOptions(ctx, c, names, &crds.WaitOptions{
+		Timeout:  timeout,
+		Interval: interval,
+		OnCheck: func(notReady *WaitError, checkErr error) {
+			if checkErr != nil {
+				// Transient API errors are retried until the timeout
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to check CRDs: %v", checkErr))
+				return
+			}
+			reportEstablished(names, notReady, established, opts, logger)
+			if notReady != nil {
+				logger.V(logs.LogDebug).Info(notReady.Error())
+			}
+		},
+	})
+	if err == nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("all %d CRDs are established", len(names)))
+	}
+	return err
+}
+
+// reportEstablished reports the CRDs among names which are established, unless
+// notReady lists them, and were not reported yet
+func reportEstablished(names []string, notReady *WaitError, established map[string]bool, opts *Options,
+	logger logr.Logger) {
+
+	for _, name := range names {
+		if established[name] {
+			continue
+		}
+		if notReady != nil && (slices.Contains(notReady.Missing, name) || slices.Contains(notReady.NotEstablished, name) ||
+			slices.Contains(notReady.NamesNotAccepted, name)) {
+			continue
+		}
+		established[name] = true
+		opts.progress(logger, &ProgressEvent{Phase: PhaseEstablished, CRD: name})
+	}
+}
+
+// bundleCRDNames returns the names of the CRDs contained in bundle
+func bundleCRDNames(bundle []byte) ([]string, error) {
+	objs, err := deployer.CustomSplit(string(bundle))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		u, err := k8s_utils.GetUnstructured([]byte(obj))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, u.GetName())
+	}
+	return names, nil
+}
+
+// isEstablished returns true if crd reports the Established condition as True
+func isEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	return crds.IsEstablished(crd)
+}
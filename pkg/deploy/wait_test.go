@@ -0,0 +1,151 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+// establishedBundleCRDs returns all the bundle CRDs, reporting Established=True
+func establishedBundleCRDs() []client.Object {
+	var result []client.Object
+	for _, u := range getBundleCRDs() {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), crd)).To(Succeed())
+		crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+		}
+		result = append(result, crd)
+	}
+	return result
+}
+
+var _ = Describe("WaitForCRDs", func() {
+	It("returns once all the CRDs are established", func() {
+		c := newFakeClient(establishedBundleCRDs()...)
+
+		Expect(deploy.WaitForCRDs(context.TODO(), c, nil, time.Second, logger)).To(Succeed())
+	})
+
+	It("lists the missing and not established CRDs on timeout", func() {
+		crds := establishedBundleCRDs()
+		missing := crds[0].GetName()
+		notEstablished := crds[1].(*apiextensionsv1.CustomResourceDefinition)
+		notEstablished.Status.Conditions[0].Status = apiextensionsv1.ConditionFalse
+		c := newFakeClient(crds[1:]...)
+
+		err := deploy.WaitForCRDsWithInterval(context.TODO(), c, nil, 100*time.Millisecond,
+			10*time.Millisecond, logger)
+		Expect(err).ToNot(BeNil())
+		var waitErr *deploy.WaitError
+		Expect(errors.As(err, &waitErr)).To(BeTrue())
+		Expect(waitErr.Missing).To(ConsistOf(missing))
+		Expect(waitErr.NotEstablished).To(ConsistOf(notEstablished.Name))
+	})
+
+	It("keeps waiting until the CRDs are established", func() {
+		crds := establishedBundleCRDs()
+		created := crds[0].(*apiextensionsv1.CustomResourceDefinition)
+		c := newFakeClient(crds[1:]...)
+
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(50 * time.Millisecond)
+			Expect(c.Create(context.TODO(), created)).To(Succeed())
+		}()
+
+		Expect(deploy.WaitForCRDsWithInterval(context.TODO(), c, nil, 5*time.Second,
+			10*time.Millisecond, logger)).To(Succeed())
+	})
+
+	It("never writes to the cluster", func() {
+		c := newFakeClient()
+
+		Expect(deploy.WaitForCRDsWithInterval(context.TODO(), c, nil, 50*time.Millisecond,
+			10*time.Millisecond, logger)).ToNot(Succeed())
+
+		crds := &apiextensionsv1.CustomResourceDefinitionList{}
+		Expect(c.List(context.TODO(), crds)).To(Succeed())
+		Expect(crds.Items).To(BeEmpty())
+	})
+})
+
+var _ = Describe("WaitTimeout", func() {
+	It("fails the run, listing the applied CRDs not established", func() {
+		c := newFakeClient()
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{WaitTimeout: 50 * time.Millisecond}, logger)
+		Expect(err).ToNot(BeNil())
+		var waitErr *deploy.WaitError
+		Expect(errors.As(err, &waitErr)).To(BeTrue())
+		Expect(waitErr.NotEstablished).To(HaveLen(len(getBundleCRDs())))
+		Expect(report.Count(deploy.ActionCreated)).To(Equal(len(getBundleCRDs())))
+	})
+
+	It("lists the applied CRDs whose names are not accepted", func() {
+		crds := establishedBundleCRDs()
+		rejected := crds[0].(*apiextensionsv1.CustomResourceDefinition)
+		rejected.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionFalse, Message: "kind in use"},
+		}
+		c := newFakeClient(crds...)
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{WaitTimeout: 50 * time.Millisecond}, logger)
+		Expect(err).To(MatchError(&deploy.WaitError{NamesNotAccepted: []string{rejected.Name}}))
+	})
+
+	It("does not wait for the terminating and paused CRDs the run left untouched", func() {
+		var objects []client.Object
+		var paused string
+		for _, obj := range establishedBundleCRDs() {
+			crd := obj.(*apiextensionsv1.CustomResourceDefinition)
+			switch {
+			case crd.Name == sveltosClusterCRD:
+				obj = terminatingCRD()
+			case paused == "":
+				paused = crd.Name
+				crd.Annotations = map[string]string{deploy.PausedAnnotation: "true"}
+				crd.Status.Conditions[0].Status = apiextensionsv1.ConditionFalse
+			}
+			objects = append(objects, obj)
+		}
+		c := newFakeClient(objects...)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{WaitTimeout: time.Second}, logger)
+		Expect(err).To(BeNil())
+		Expect(crdResult(report, sveltosClusterCRD).Action).To(Equal(deploy.ActionTerminating))
+		Expect(crdResult(report, paused).Action).To(Equal(deploy.ActionPaused))
+	})
+
+	It("completes the run once the applied CRDs are established", func() {
+		c := newFakeClient(establishedBundleCRDs()...)
+
+		_, err := deploy.Deploy(context.TODO(), c, &deploy.Options{WaitTimeout: time.Second}, logger)
+		Expect(err).To(BeNil())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// warningCode is the code of the Warning headers the API server returns
+// (RFC 7234 "Miscellaneous persistent warning")
+const warningCode = 299
+
+// WarningHandler collects the warnings the API server returns while a CRD is
+// processed (deprecated schema constructs, admission webhook warnings...) in
+// the result of that CRD. Warnings returned outside the processing of a CRD
+// are logged as client-go does by default.
+type WarningHandler struct{}
+
+var _ rest.WarningHandlerWithContext = WarningHandler{}
+
+func (WarningHandler) HandleWarningHeaderWithContext(ctx context.Context, code int, agent, text string) {
+	if code != warningCode || text == "" {
+		return
+	}
+	collector, ok := ctx.Value(warningCollectorKey{}).(*warningCollector)
+	if !ok {
+		rest.WarningLogger{}.HandleWarningHeaderWithContext(ctx, code, agent, text)
+		return
+	}
+	collector.add(text)
+}
+
+// WarningsError is returned, with FailOnWarnings, when the API server
+// returned warnings while a CRD was processed. The CRD has been written.
+type WarningsError struct {
+	// CRD is the name of the CRD
+	CRD string
+
+	// Warnings are the warnings returned by the API server
+	Warnings []string
+}
+
+func (e *WarningsError) Error() string {
+	return fmt.Sprintf("API server returned %d warning(s) for CRD %s: %s", len(e.Warnings), e.CRD,
+		strings.Join(e.Warnings, "; "))
+}
+
+type warningCollectorKey struct{}
+
+// warningCollector gathers the warnings returned for the requests made with
+// the context it is attached to
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// withWarningCollector returns ctx with a warningCollector attached
+func withWarningCollector(ctx context.Context) (context.Context, *warningCollector) {
+	collector := &warningCollector{}
+	return context.WithValue(ctx, warningCollectorKey{}, collector), collector
+}
+
+func (w *warningCollector) add(text string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.warnings {
+		if w.warnings[i] == text {
+			return
+		}
+	}
+	w.warnings = append(w.warnings, text)
+}
+
+func (w *warningCollector) get() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.warnings
+}
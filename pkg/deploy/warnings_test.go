@@ -0,0 +1,79 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const deprecationWarning = "spec.versions[0].schema: x-kubernetes-preserve-unknown-fields is deprecated"
+
+// newWarningClient returns a client for which the API server returns, twice,
+// a warning when the CRD named name is created
+func newWarningClient(name string) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if obj.GetName() == name {
+				deploy.WarningHandler{}.HandleWarningHeaderWithContext(ctx, 299, "-", deprecationWarning)
+				deploy.WarningHandler{}.HandleWarningHeaderWithContext(ctx, 299, "-", deprecationWarning)
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("Warnings", func() {
+	It("are reported for the CRD whose write returned them", func() {
+		c := newWarningClient(sveltosClusterCRD)
+
+		report, err := deploy.Deploy(context.TODO(), c, nil, logger)
+		Expect(err).To(BeNil())
+		for i := range report.CRDs {
+			result := &report.CRDs[i]
+			Expect(result.Action).To(Equal(deploy.ActionCreated))
+			if result.Name == sveltosClusterCRD {
+				Expect(result.Warnings).To(Equal([]string{deprecationWarning}))
+			} else {
+				Expect(result.Warnings).To(BeEmpty())
+			}
+		}
+	})
+
+	It("fail the CRD with FailOnWarnings", func() {
+		c := newWarningClient(sveltosClusterCRD)
+
+		report, err := deploy.Deploy(context.TODO(), c, &deploy.Options{FailOnWarnings: true}, logger)
+		var warningsErr *deploy.WarningsError
+		Expect(errors.As(err, &warningsErr)).To(BeTrue())
+		Expect(warningsErr.CRD).To(Equal(sveltosClusterCRD))
+
+		result := findResult(report, sveltosClusterCRD)
+		Expect(result.Action).To(Equal(deploy.ActionFailed))
+		Expect(result.Warnings).To(Equal([]string{deprecationWarning}))
+		Expect(report.Count(deploy.ActionFailed)).To(Equal(1))
+	})
+})
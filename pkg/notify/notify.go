@@ -0,0 +1,231 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify posts the outcome of a crd-manager run to a webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+)
+
+const (
+	// DefaultTimeout is the default timeout of a notification delivery
+	DefaultTimeout = 10 * time.Second
+
+	// maxResponseSize bounds how much of the webhook response is read
+	maxResponseSize = 4096
+)
+
+// Policy tells which runs are notified
+type Policy string
+
+const (
+	// OnFailure notifies failed runs only
+	OnFailure = Policy("failure")
+
+	// Always notifies every run
+	Always = Policy("always")
+)
+
+// Options configures where and when run results are posted
+type Options struct {
+	// URL of the webhook. Only https is supported. No notification is sent
+	// when empty.
+	URL string
+
+	// TokenFile is an optional file containing the bearer token sent in the
+	// Authorization header. It is read at each delivery, so that the token
+	// can be rotated.
+	TokenFile string
+
+	// CAFile is an optional PEM file with the CAs trusted, in addition to the
+	// system ones, to verify the webhook certificate
+	CAFile string
+
+	// Timeout of a delivery. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// On tells which runs are notified. Defaults to OnFailure.
+	On Policy
+}
+
+// Summary is the JSON document posted to the webhook
+type Summary struct {
+	// Cluster is the API server the CRDs were deployed to
+	Cluster string `json:"cluster"`
+
+	// Status is the overall outcome of the run
+	Status deploy.RunStatus `json:"status"`
+
+	// BundleVersion is the Sveltos version the bundle CRDs come from, when known
+	BundleVersion string `json:"bundleVersion"`
+
+	// BundleDigest is the sha256 digest of the CRD bundle applied
+	BundleDigest string `json:"bundleDigest"`
+
+	// Failures lists the CRDs which could not be processed
+	Failures []Failure `json:"failures,omitempty"`
+
+	// Error is the error the run failed with, if any
+	Error string `json:"error,omitempty"`
+}
+
+// Failure describes a CRD which could not be processed
+type Failure struct {
+	// CRD is the name of the CRD
+	CRD string `json:"crd"`
+
+	// Action is the action which failed
+	Action deploy.Action `json:"action"`
+
+	// Error tells why the CRD could not be processed
+	Error string `json:"error,omitempty"`
+}
+
+// IsEnabled returns true if notifications are configured
+func (o *Options) IsEnabled() bool {
+	return o.URL != ""
+}
+
+// Validate returns an error if o is not consistent
+func (o *Options) Validate() error {
+	if !o.IsEnabled() {
+		return nil
+	}
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return fmt.Errorf("invalid notification URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("invalid notification URL %s: only https is supported", o.URL)
+	}
+	switch o.On {
+	case "", OnFailure, Always:
+		return nil
+	default:
+		return fmt.Errorf("invalid notification policy %q: expected %s or %s", o.On, OnFailure, Always)
+	}
+}
+
+// ShouldNotify returns true if, according to its policy, the run summary
+// describes is notified
+func (o *Options) ShouldNotify(summary *Summary) bool {
+	return o.IsEnabled() && (o.On == Always || summary.Status == deploy.RunStatusFailed)
+}
+
+// NewSummary returns the summary of the run report describes, runErr being
+// the error the run failed with
+func NewSummary(report *deploy.Report, runErr error) *Summary {
+	summary := &Summary{
+		Cluster:       report.TargetCluster,
+		Status:        report.Status,
+		BundleVersion: report.BundleVersion,
+		BundleDigest:  report.BundleDigest,
+	}
+	if runErr != nil {
+		summary.Status = deploy.RunStatusFailed
+		summary.Error = runErr.Error()
+	}
+	for _, results := range [][]deploy.CRDResult{report.CRDs, report.Removed} {
+		for i := range results {
+			if results[i].Action == deploy.ActionFailed || results[i].Error != "" {
+				summary.Failures = append(summary.Failures,
+					Failure{CRD: results[i].Name, Action: results[i].Action, Error: results[i].Error})
+			}
+		}
+	}
+	return summary
+}
+
+// Send posts summary, as JSON, to the webhook
+func Send(ctx context.Context, opts *Options, summary *Summary) error {
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if opts.TokenFile != "" {
+		token, err := os.ReadFile(opts.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read notification token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post run result to %s: %w", opts.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		return fmt.Errorf("failed to post run result to %s: %s: %s", opts.URL, resp.Status,
+			strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func newHTTPClient(opts *Options) (*http.Client, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notification CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in notification CA file " + opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
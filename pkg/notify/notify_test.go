@@ -0,0 +1,165 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/deploy"
+	"github.com/projectsveltos/crd-manager/pkg/notify"
+)
+
+type request struct {
+	authorization string
+	contentType   string
+	summary       notify.Summary
+}
+
+var _ = Describe("Notify", func() {
+	var server *httptest.Server
+	var caFile string
+	var status int
+	var mu sync.Mutex
+	var received []request
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		received = nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var summary notify.Summary
+			if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			received = append(received, request{
+				authorization: r.Header.Get("Authorization"),
+				contentType:   r.Header.Get("Content-Type"),
+				summary:       summary,
+			})
+			mu.Unlock()
+			w.WriteHeader(status)
+		}))
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	failedReport := func() *deploy.Report {
+		return &deploy.Report{
+			TargetCluster: "https://10.0.0.1:6443",
+			Status:        deploy.RunStatusFailed,
+			BundleVersion: "v1.2.0",
+			BundleDigest:  "sha256:abc",
+			CRDs: []deploy.CRDResult{
+				{Name: "clusterprofiles.config.projectsveltos.io", Action: deploy.ActionUnchanged},
+				{Name: "profiles.config.projectsveltos.io", Action: deploy.ActionFailed, Error: "forbidden"},
+			},
+			Removed: []deploy.CRDResult{
+				{Name: "old.lib.projectsveltos.io", Action: deploy.ActionFailed, Error: "conflict"},
+			},
+		}
+	}
+
+	It("summarizes the failed CRDs", func() {
+		summary := notify.NewSummary(failedReport(), nil)
+		Expect(summary.Cluster).To(Equal("https://10.0.0.1:6443"))
+		Expect(summary.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(summary.BundleVersion).To(Equal("v1.2.0"))
+		Expect(summary.BundleDigest).To(Equal("sha256:abc"))
+		Expect(summary.Failures).To(ConsistOf(
+			notify.Failure{CRD: "profiles.config.projectsveltos.io", Action: deploy.ActionFailed, Error: "forbidden"},
+			notify.Failure{CRD: "old.lib.projectsveltos.io", Action: deploy.ActionFailed, Error: "conflict"},
+		))
+	})
+
+	It("marks the run failed when it returned an error", func() {
+		summary := notify.NewSummary(&deploy.Report{Status: deploy.RunStatusSuccess}, errors.New("lock timeout"))
+		Expect(summary.Status).To(Equal(deploy.RunStatusFailed))
+		Expect(summary.Error).To(Equal("lock timeout"))
+	})
+
+	It("notifies failed runs only by default", func() {
+		opts := &notify.Options{URL: server.URL}
+		Expect(opts.ShouldNotify(&notify.Summary{Status: deploy.RunStatusFailed})).To(BeTrue())
+		Expect(opts.ShouldNotify(&notify.Summary{Status: deploy.RunStatusSuccess})).To(BeFalse())
+
+		opts.On = notify.Always
+		Expect(opts.ShouldNotify(&notify.Summary{Status: deploy.RunStatusSuccess})).To(BeTrue())
+
+		Expect((&notify.Options{On: notify.Always}).ShouldNotify(&notify.Summary{})).To(BeFalse())
+	})
+
+	It("posts the summary with the bearer token", func() {
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0o600)).To(Succeed())
+
+		opts := &notify.Options{URL: server.URL, CAFile: caFile, TokenFile: tokenFile}
+		summary := notify.NewSummary(failedReport(), nil)
+		Expect(notify.Send(context.TODO(), opts, summary)).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].authorization).To(Equal("Bearer s3cr3t"))
+		Expect(received[0].contentType).To(Equal("application/json"))
+		Expect(received[0].summary).To(Equal(*summary))
+	})
+
+	It("fails when the webhook rejects the notification", func() {
+		status = http.StatusUnauthorized
+		opts := &notify.Options{URL: server.URL, CAFile: caFile}
+		err := notify.Send(context.TODO(), opts, notify.NewSummary(failedReport(), nil))
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("fails when the webhook certificate is not trusted", func() {
+		opts := &notify.Options{URL: server.URL}
+		Expect(notify.Send(context.TODO(), opts, notify.NewSummary(failedReport(), nil))).ToNot(Succeed())
+		Expect(received).To(BeEmpty())
+	})
+
+	It("fails when the token file cannot be read", func() {
+		opts := &notify.Options{URL: server.URL, CAFile: caFile, TokenFile: filepath.Join(GinkgoT().TempDir(), "missing")}
+		Expect(notify.Send(context.TODO(), opts, notify.NewSummary(failedReport(), nil))).To(
+			MatchError(ContainSubstring("token file")))
+		Expect(received).To(BeEmpty())
+	})
+
+	It("validates the options", func() {
+		Expect((&notify.Options{}).Validate()).To(Succeed())
+		Expect((&notify.Options{URL: "https://hooks.example.com/crd-manager", On: notify.Always}).Validate()).To(Succeed())
+		Expect((&notify.Options{URL: "http://hooks.example.com"}).Validate()).To(
+			MatchError(ContainSubstring("only https")))
+		Expect((&notify.Options{URL: "https://hooks.example.com", On: "never"}).Validate()).To(
+			MatchError(ContainSubstring("invalid notification policy")))
+	})
+})
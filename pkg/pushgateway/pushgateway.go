@@ -0,0 +1,161 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pushgateway pushes the crd-manager metrics to a Prometheus
+// Pushgateway, for runs ending before any scrape.
+package pushgateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const (
+	// DefaultJob is the default job label of the pushed metrics
+	DefaultJob = "crd-manager"
+
+	// DefaultTimeout is the default timeout of a push
+	DefaultTimeout = 10 * time.Second
+)
+
+// Options configures where metrics are pushed
+type Options struct {
+	// URL of the Pushgateway. No metric is pushed when empty.
+	URL string
+
+	// Job is the job label the metrics are grouped by. Defaults to DefaultJob.
+	Job string
+
+	// Instance, when set, is the instance label the metrics are also grouped by
+	Instance string
+
+	// UsernameFile and PasswordFile are optional files containing the basic
+	// authentication credentials. They are read at each push, so that they
+	// can be rotated.
+	UsernameFile string
+	PasswordFile string
+
+	// CAFile is an optional PEM file with the CAs trusted, in addition to the
+	// system ones, to verify the Pushgateway certificate
+	CAFile string
+
+	// Timeout of a push. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// IsEnabled returns true if pushing metrics is configured
+func (o *Options) IsEnabled() bool {
+	return o.URL != ""
+}
+
+// Validate returns an error if o is not consistent
+func (o *Options) Validate() error {
+	if !o.IsEnabled() {
+		return nil
+	}
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return fmt.Errorf("invalid Pushgateway URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid Pushgateway URL %s: expected an http or https URL", o.URL)
+	}
+	if (o.UsernameFile == "") != (o.PasswordFile == "") {
+		return errors.New("invalid Pushgateway basic authentication: both username and password files are required")
+	}
+	return nil
+}
+
+// Push replaces the metrics of the job, and instance, grouping with the
+// ones gatherer collects
+func Push(ctx context.Context, opts *Options, gatherer prometheus.Gatherer) error {
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+
+	job := opts.Job
+	if job == "" {
+		job = DefaultJob
+	}
+	pusher := push.New(opts.URL, job).Gatherer(gatherer).Client(httpClient)
+	if opts.Instance != "" {
+		pusher = pusher.Grouping("instance", opts.Instance)
+	}
+	if opts.UsernameFile != "" {
+		username, err := readCredential(opts.UsernameFile)
+		if err != nil {
+			return err
+		}
+		password, err := readCredential(opts.PasswordFile)
+		if err != nil {
+			return err
+		}
+		pusher = pusher.BasicAuth(username, password)
+	}
+
+	if err := pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", opts.URL, err)
+	}
+	return nil
+}
+
+func readCredential(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Pushgateway credentials: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func newHTTPClient(opts *Options) (*http.Client, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Pushgateway CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in Pushgateway CA file " + opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pushgateway_test
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/projectsveltos/crd-manager/pkg/pushgateway"
+)
+
+type request struct {
+	method   string
+	path     string
+	username string
+	password string
+	body     string
+}
+
+var _ = Describe("Pushgateway", func() {
+	var server *httptest.Server
+	var caFile string
+	var status int
+	var mu sync.Mutex
+	var received []request
+	var registry *prometheus.Registry
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		received = nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			username, password, _ := r.BasicAuth()
+			mu.Lock()
+			received = append(received, request{
+				method:   r.Method,
+				path:     r.URL.Path,
+				username: username,
+				password: password,
+				body:     string(body),
+			})
+			mu.Unlock()
+			w.WriteHeader(status)
+		}))
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+
+		registry = prometheus.NewRegistry()
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "crd_manager_test_gauge", Help: "test gauge"})
+		gauge.Set(3)
+		registry.MustRegister(gauge)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("replaces the metrics of the job grouping", func() {
+		opts := &pushgateway.Options{URL: server.URL, CAFile: caFile}
+		Expect(pushgateway.Push(context.TODO(), opts, registry)).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].method).To(Equal(http.MethodPut))
+		Expect(received[0].path).To(Equal("/metrics/job/" + pushgateway.DefaultJob))
+		Expect(received[0].body).To(ContainSubstring("crd_manager_test_gauge"))
+		Expect(received[0].username).To(BeEmpty())
+	})
+
+	It("groups by instance and authenticates", func() {
+		dir := GinkgoT().TempDir()
+		usernameFile := filepath.Join(dir, "username")
+		passwordFile := filepath.Join(dir, "password")
+		Expect(os.WriteFile(usernameFile, []byte("pusher\n"), 0o600)).To(Succeed())
+		Expect(os.WriteFile(passwordFile, []byte("s3cr3t\n"), 0o600)).To(Succeed())
+
+		opts := &pushgateway.Options{
+			URL:          server.URL,
+			Job:          "crd-manager-upgrade",
+			Instance:     "management",
+			UsernameFile: usernameFile,
+			PasswordFile: passwordFile,
+			CAFile:       caFile,
+		}
+		Expect(pushgateway.Push(context.TODO(), opts, registry)).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].path).To(Equal("/metrics/job/crd-manager-upgrade/instance/management"))
+		Expect(received[0].username).To(Equal("pusher"))
+		Expect(received[0].password).To(Equal("s3cr3t"))
+	})
+
+	It("fails when the Pushgateway rejects the metrics", func() {
+		status = http.StatusUnauthorized
+		opts := &pushgateway.Options{URL: server.URL, CAFile: caFile}
+		Expect(pushgateway.Push(context.TODO(), opts, registry)).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("fails when the Pushgateway certificate is not trusted", func() {
+		opts := &pushgateway.Options{URL: server.URL}
+		Expect(pushgateway.Push(context.TODO(), opts, registry)).ToNot(Succeed())
+		Expect(received).To(BeEmpty())
+	})
+
+	It("fails when the credentials cannot be read", func() {
+		dir := GinkgoT().TempDir()
+		opts := &pushgateway.Options{
+			URL:          server.URL,
+			CAFile:       caFile,
+			UsernameFile: filepath.Join(dir, "username"),
+			PasswordFile: filepath.Join(dir, "password"),
+		}
+		Expect(pushgateway.Push(context.TODO(), opts, registry)).To(MatchError(ContainSubstring("credentials")))
+		Expect(received).To(BeEmpty())
+	})
+
+	It("validates the options", func() {
+		Expect((&pushgateway.Options{}).Validate()).To(Succeed())
+		Expect((&pushgateway.Options{URL: "http://pushgateway.monitoring:9091"}).Validate()).To(Succeed())
+		Expect((&pushgateway.Options{URL: "pushgateway:9091"}).Validate()).To(
+			MatchError(ContainSubstring("http or https")))
+		Expect((&pushgateway.Options{URL: "https://pushgateway", UsernameFile: "/etc/user"}).Validate()).To(
+			MatchError(ContainSubstring("both username and password")))
+	})
+})
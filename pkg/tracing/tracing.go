@@ -0,0 +1,110 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing exports crd-manager OpenTelemetry traces. Tracing is
+// disabled, and the global no-op tracer provider left in place, unless an
+// OTLP endpoint is configured.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// ServiceName is the default service.name resource attribute, overridden
+	// by OTEL_SERVICE_NAME
+	ServiceName = "crd-manager"
+
+	// TraceParentEnvVar and TraceStateEnvVar carry the W3C trace context of
+	// the caller, so that a run nests under the caller's trace
+	TraceParentEnvVar = "TRACEPARENT"
+	TraceStateEnvVar  = "TRACESTATE"
+
+	endpointEnvVar       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	tracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	sdkDisabledEnvVar    = "OTEL_SDK_DISABLED"
+)
+
+// Enabled returns whether traces are exported: an OTLP endpoint is given,
+// either as endpoint or through the standard OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables, and
+// OTEL_SDK_DISABLED is not true
+func Enabled(endpoint string) bool {
+	if strings.EqualFold(os.Getenv(sdkDisabledEnvVar), "true") {
+		return false
+	}
+	return endpoint != "" || os.Getenv(endpointEnvVar) != "" || os.Getenv(tracesEndpointEnvVar) != ""
+}
+
+// Setup installs the global tracer provider, exporting spans over OTLP/HTTP to
+// endpoint or, when empty, to the endpoint the standard OTEL_* environment
+// variables configure. The returned function flushes pending spans and must
+// be called before exiting.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	var exporterOpts []otlptracehttp.Option
+	if endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// Later detectors take precedence: OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES override the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// ContextFromEnvironment returns ctx carrying the trace context found in the
+// TRACEPARENT and TRACESTATE environment variables, if any
+func ContextFromEnvironment(ctx context.Context) context.Context {
+	traceParent := os.Getenv(TraceParentEnvVar)
+	if traceParent == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{
+		"traceparent": traceParent,
+		"tracestate":  os.Getenv(TraceStateEnvVar),
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/projectsveltos/crd-manager/pkg/tracing"
+)
+
+const (
+	traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+// unsetEnv unsets the given environment variables for the current spec
+func unsetEnv(names ...string) {
+	for _, name := range names {
+		GinkgoT().Setenv(name, "")
+		Expect(os.Unsetenv(name)).To(Succeed())
+	}
+}
+
+var _ = Describe("Enabled", func() {
+	BeforeEach(func() {
+		unsetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_SDK_DISABLED")
+	})
+
+	It("is disabled without endpoint", func() {
+		Expect(tracing.Enabled("")).To(BeFalse())
+	})
+
+	It("is enabled by the endpoint flag", func() {
+		Expect(tracing.Enabled("http://collector:4318")).To(BeTrue())
+	})
+
+	It("is enabled by the standard environment variables", func() {
+		GinkgoT().Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+		Expect(tracing.Enabled("")).To(BeTrue())
+	})
+
+	It("is disabled by OTEL_SDK_DISABLED", func() {
+		GinkgoT().Setenv("OTEL_SDK_DISABLED", "true")
+		Expect(tracing.Enabled("http://collector:4318")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Setup", func() {
+	It("installs a provider which shuts down without exporting anything", func() {
+		shutdown, err := tracing.Setup(context.TODO(), "http://127.0.0.1:4318")
+		Expect(err).To(BeNil())
+
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+		defer cancel()
+		Expect(shutdown(ctx)).To(Succeed())
+	})
+})
+
+var _ = Describe("ContextFromEnvironment", func() {
+	It("returns ctx unchanged without TRACEPARENT", func() {
+		unsetEnv(tracing.TraceParentEnvVar)
+		ctx := context.TODO()
+		Expect(tracing.ContextFromEnvironment(ctx)).To(Equal(ctx))
+	})
+
+	It("carries the caller trace context", func() {
+		GinkgoT().Setenv(tracing.TraceParentEnvVar, traceParent)
+		GinkgoT().Setenv(tracing.TraceStateEnvVar, "vendor=value")
+
+		spanContext := trace.SpanContextFromContext(tracing.ContextFromEnvironment(context.TODO()))
+		Expect(spanContext.IsValid()).To(BeTrue())
+		Expect(spanContext.IsRemote()).To(BeTrue())
+		Expect(spanContext.TraceID().String()).To(Equal(traceID))
+		Expect(spanContext.TraceState().Get("vendor")).To(Equal("value"))
+	})
+
+	It("ignores an invalid TRACEPARENT", func() {
+		GinkgoT().Setenv(tracing.TraceParentEnvVar, "not-a-trace-parent")
+
+		spanContext := trace.SpanContextFromContext(tracing.ContextFromEnvironment(context.TODO()))
+		Expect(spanContext.IsValid()).To(BeFalse())
+	})
+})
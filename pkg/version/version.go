@@ -0,0 +1,80 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version describes the crd-manager build. Values are injected at
+// build time, for instance:
+//
+//	go build -ldflags "-X github.com/projectsveltos/crd-manager/pkg/version.version=v1.10.0 \
+//	  -X github.com/projectsveltos/crd-manager/pkg/version.gitSHA=$(git rev-parse HEAD) \
+//	  -X github.com/projectsveltos/crd-manager/pkg/version.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+const (
+	// Unknown is reported for the values not injected at build time
+	Unknown = "unknown"
+)
+
+// Set with -ldflags -X
+var (
+	version   = Unknown
+	gitSHA    = ""
+	buildDate = Unknown
+)
+
+// BuildInfo describes the crd-manager build
+type BuildInfo struct {
+	// Version is the crd-manager image version
+	Version string `json:"version"`
+
+	// GitSHA is the commit the binary was built from
+	GitSHA string `json:"gitSHA"`
+
+	// BuildDate is when the binary was built, in RFC 3339 format
+	BuildDate string `json:"buildDate"`
+}
+
+// Get returns the crd-manager BuildInfo. Without an injected git SHA, the
+// VCS revision Go stamps in the binary, if any, is used.
+func Get() BuildInfo {
+	sha := gitSHA
+	if sha == "" {
+		sha = vcsRevision()
+	}
+	return BuildInfo{Version: version, GitSHA: sha, BuildDate: buildDate}
+}
+
+// String returns the BuildInfo in the format printed by --version
+func (b BuildInfo) String() string {
+	return fmt.Sprintf("crd-manager %s (git %s, built %s)", b.Version, b.GitSHA, b.BuildDate)
+}
+
+func vcsRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Unknown
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectsveltos/crd-manager/pkg/version"
+)
+
+var _ = Describe("Get", func() {
+	It("reports unknown values when nothing is injected at build time", func() {
+		info := version.Get()
+		Expect(info.Version).To(Equal(version.Unknown))
+		Expect(info.BuildDate).To(Equal(version.Unknown))
+		Expect(info.GitSHA).ToNot(BeEmpty())
+	})
+
+	It("formats the build info", func() {
+		info := version.BuildInfo{Version: "v1.10.0", GitSHA: "abc123", BuildDate: "2026-01-02T03:04:05Z"}
+		Expect(info.String()).To(Equal("crd-manager v1.10.0 (git abc123, built 2026-01-02T03:04:05Z)"))
+	})
+})